@@ -1,10 +1,13 @@
 package main
 
 import (
+	"os"
+
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/maxbolgarin/codry/internal/app"
 	"github.com/maxbolgarin/contem"
 	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/lang"
 	"github.com/maxbolgarin/logze/v2"
 )
 
@@ -14,33 +17,106 @@ var (
 
 var (
 	configPath = kingpin.Flag("config", "path to config file").Short('c').String()
+
+	serveCommand = kingpin.Command("serve", "run webhook server and review merge requests on events").Default()
+
+	reviewCommand = kingpin.Command("review", "review open merge requests of the project and exit")
+	reviewProject = reviewCommand.Arg("project", "project ID or path, e.g. owner/repo").Required().String()
+	reviewReplay  = reviewCommand.Flag("replay", "re-run processing and posting of raw findings cached in review.raw_findings.dir instead of generating them").Bool()
+
+	rangeCommand = kingpin.Command("review-range", "review changes between two commits of the project and print findings")
+	rangeProject = rangeCommand.Flag("project", "project ID or path, e.g. owner/repo").Required().String()
+	rangeFrom    = rangeCommand.Flag("from", "base commit SHA of the range").Required().String()
+	rangeTo      = rangeCommand.Flag("to", "head commit SHA of the range").Required().String()
+	rangeFormat  = rangeCommand.Flag("format", "output format of findings").Default(app.FormatJSON).Enum(app.OutputFormats...)
+	rangePost    = rangeCommand.Flag("post", "post findings as a comment of the head commit").Bool()
+
+	commitCommand = kingpin.Command("review-commit", "review a commit of the project against its parent and print findings")
+	commitProject = commitCommand.Flag("project", "project ID or path, e.g. owner/repo").Required().String()
+	commitSHA     = commitCommand.Flag("sha", "SHA of the reviewed commit").Required().String()
+	commitFormat  = commitCommand.Flag("format", "output format of findings").Default(app.FormatJSON).Enum(app.OutputFormats...)
+	commitPost    = commitCommand.Flag("post", "post findings as comments of the commit, inline where possible").Bool()
+
+	reviewFileCommand  = kingpin.Command("review-file", "review a local unified diff file and print findings as JSON")
+	reviewFileDiff     = reviewFileCommand.Flag("diff", "path to the unified diff file").Required().String()
+	reviewFilePath     = reviewFileCommand.Flag("path", "path of the reviewed file in the repository, required for diffs without file headers").String()
+	reviewFileOriginal = reviewFileCommand.Flag("original", "path to the content of the reviewed file before changes").String()
+	reviewFileDir      = reviewFileCommand.Flag("dir", "directory with files before changes").String()
+
+	evalCommand   = kingpin.Command("eval", "review cases of a labeled dataset and report precision and recall of findings")
+	evalDir       = evalCommand.Flag("dir", "root directory of the dataset").Default("testdata/eval").String()
+	evalMock      = evalCommand.Flag("mock", "answer with responses.json of every case instead of calling the model").Bool()
+	evalTolerance = evalCommand.Flag("tolerance", "distance in lines between an expected and a found finding that still matches").Default("3").Int()
 )
 
 func main() {
-	kingpin.Parse()
+	command := kingpin.Parse()
 	//contem.Start(run, logze.DefaultPtr())
 	var err error
 	ctx := contem.New(contem.WithLogger(logze.DefaultPtr()), contem.Exit(&err))
 	defer ctx.Shutdown()
-	err = run(ctx)
+	err = run(ctx, command)
 	if err != nil {
 		logze.DefaultPtr().Error("cannot run", "error", err)
 	}
 }
 
-func run(ctx contem.Context) error {
+func run(ctx contem.Context, command string) error {
 	cfg, err := app.LoadConfig(*configPath)
 	if err != nil {
 		return errm.Wrap(err, "load config")
 	}
-	logze.Init(logze.C().WithConsole().WithLevel(logze.LevelDebug))
+	logze.Init(logze.C().WithConsole().WithLevel(lang.Check(cfg.Log.Level, logze.LevelDebug)))
+
+	if command == reviewFileCommand.FullCommand() {
+		// Offline review doesn't need the provider
+		return app.ReviewDiff(ctx, cfg, app.DiffReview{
+			DiffPath:     *reviewFileDiff,
+			FilePath:     *reviewFilePath,
+			OriginalPath: *reviewFileOriginal,
+			Dir:          *reviewFileDir,
+		}, os.Stdout)
+	}
+
+	if command == evalCommand.FullCommand() {
+		// Evaluation doesn't need the provider
+		return app.RunEvaluation(ctx, cfg, app.Evaluation{
+			Dir:       *evalDir,
+			Mock:      *evalMock,
+			Tolerance: *evalTolerance,
+		}, os.Stdout)
+	}
+
+	if command == reviewCommand.FullCommand() && *reviewReplay {
+		cfg.Reviewer.RawFindings.Replay = true
+	}
 
 	codry, err := app.New(ctx, cfg)
 	if err != nil {
-		return errm.Wrap(err, "new provider")
+		return errm.Wrap(err, "create app")
 	}
 
-	codry.RunReview(ctx, "maxbolgarin/codry")
+	switch command {
+	case reviewCommand.FullCommand():
+		return codry.RunReview(ctx, *reviewProject)
+	case rangeCommand.FullCommand():
+		return codry.ReviewRange(ctx, app.RangeReview{
+			ProjectID: *rangeProject,
+			From:      *rangeFrom,
+			To:        *rangeTo,
+			Format:    *rangeFormat,
+			Post:      *rangePost,
+		}, os.Stdout)
+	case commitCommand.FullCommand():
+		return codry.ReviewCommit(ctx, app.CommitReview{
+			ProjectID: *commitProject,
+			SHA:       *commitSHA,
+			Format:    *commitFormat,
+			Post:      *commitPost,
+		}, os.Stdout)
+	case serveCommand.FullCommand():
+		return codry.Serve(ctx)
+	}
 
-	return nil
+	return errm.Errorf("unknown command %s", command)
 }
@@ -5,6 +5,7 @@ import (
 
 	"fmt"
 	"strings"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/maxbolgarin/cliex"
@@ -12,6 +13,7 @@ import (
 	"github.com/maxbolgarin/codry/internal/agent/gemini"
 	"github.com/maxbolgarin/codry/internal/agent/openai"
 	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/metrics"
 	"github.com/maxbolgarin/codry/internal/model"
 	"github.com/maxbolgarin/codry/internal/model/interfaces"
 	"github.com/maxbolgarin/errm"
@@ -21,66 +23,161 @@ import (
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
+// Review stages used as labels of model call metrics and keys of per-stage models
+const (
+	stageDescription        = "description"
+	stageChangesOverview    = "changes_overview"
+	stageArchitectureReview = "architecture_review"
+	stageCodeReview         = "code_review"
+)
+
 type Agent struct {
-	cfg Config
-	log logze.Logger
-	pb  *prompts.Builder
-	api interfaces.AgentAPI
+	cfg     Config
+	log     logze.Logger
+	pb      *prompts.Builder
+	stages  map[string]stageAPI
+	metrics interfaces.MetricsRecorder
+}
+
+// stageAPI is the API and the model called in a review stage
+type stageAPI struct {
+	api   interfaces.AgentAPI
+	model string
 }
 
 func New(ctx context.Context, cfg Config) (*Agent, error) {
 	if err := cfg.PrepareAndValidate(); err != nil {
 		return nil, errm.Wrap(err, "validate config")
 	}
-	cli, err := cliex.NewWithConfig(cliex.Config{
-		BaseURL:        cfg.BaseURL,
-		UserAgent:      cfg.UserAgent,
-		ProxyAddress:   cfg.ProxyURL,
-		RequestTimeout: cfg.Timeout,
+	api, err := newAPI(ctx, cfg.Type, cfg, model.ModelConfig{
+		APIKey:   cfg.APIKey,
+		Model:    cfg.Model,
+		URL:      cfg.BaseURL,
+		ProxyURL: cfg.ProxyURL,
+		IsTest:   cfg.IsTest,
 	})
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to create HTTP client")
+		return nil, errm.Wrap(err, "failed to create agent")
 	}
 
 	agent := &Agent{
-		cfg: cfg,
-		log: logze.With("llm", cfg.Type, "component", "agent"),
-		pb:  prompts.NewBuilder(cfg.Language),
+		cfg:     cfg,
+		log:     logze.With("llm", cfg.Type, "component", "agent"),
+		pb:      prompts.NewBuilder(cfg.Language),
+		stages:  make(map[string]stageAPI, len(supportedStages)),
+		metrics: metrics.Nop{},
 	}
 
-	modelCfg := model.ModelConfig{
-		APIKey:   cfg.APIKey,
-		Model:    cfg.Model,
-		URL:      cfg.BaseURL,
-		ProxyURL: cfg.ProxyURL,
-		IsTest:   cfg.IsTest,
+	// Stages on the agent backend share its client and pass the model per call,
+	// stages with another backend, key or endpoint get their own client
+	for _, name := range supportedStages {
+		stage := cfg.Stages[name]
+		agentType := lang.Check(stage.Type, cfg.Type)
+		sameType := agentType == cfg.Type
+		stageModel := lang.Check(stage.Model, lang.If(sameType, cfg.Model, ""))
+
+		if sameType && stage.APIKey == "" && stage.BaseURL == "" {
+			agent.stages[name] = stageAPI{api: api, model: stageModel}
+			continue
+		}
+
+		stageClient, err := newAPI(ctx, agentType, cfg, model.ModelConfig{
+			APIKey:   lang.Check(stage.APIKey, lang.If(sameType, cfg.APIKey, "")),
+			Model:    stageModel,
+			URL:      lang.Check(stage.BaseURL, lang.If(sameType, cfg.BaseURL, "")),
+			ProxyURL: cfg.ProxyURL,
+			IsTest:   cfg.IsTest,
+		})
+		if err != nil {
+			return nil, errm.Wrap(err, "failed to create agent", "stage", name)
+		}
+		agent.stages[name] = stageAPI{api: stageClient, model: stageModel}
+	}
+
+	if err := agent.validateModels(ctx); err != nil {
+		return nil, err
+	}
+
+	return agent, nil
+}
+
+// NewWithAPI creates the agent calling the API in every stage, e.g. a mock of the model for offline evaluation;
+// the config is not validated, its type, keys and stages are not used
+func NewWithAPI(cfg Config, api interfaces.AgentAPI) *Agent {
+	cfg.setDefaults()
+	agent := &Agent{
+		cfg:     cfg,
+		log:     logze.With("llm", cfg.Type, "component", "agent"),
+		pb:      prompts.NewBuilder(cfg.Language),
+		stages:  make(map[string]stageAPI, len(supportedStages)),
+		metrics: metrics.Nop{},
 	}
+	for _, name := range supportedStages {
+		agent.stages[name] = stageAPI{api: api, model: cfg.Model}
+	}
+	return agent
+}
 
-	switch cfg.Type {
+// newAPI creates the client of the agent type API
+func newAPI(ctx context.Context, agentType AgentType, cfg Config, modelCfg model.ModelConfig) (interfaces.AgentAPI, error) {
+	cli, err := cliex.NewWithConfig(cliex.Config{
+		BaseURL:        modelCfg.URL,
+		UserAgent:      cfg.UserAgent,
+		ProxyAddress:   cfg.ProxyURL,
+		RequestTimeout: cfg.Timeout,
+	})
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to create HTTP client")
+	}
+
+	switch agentType {
 	case Gemini:
-		agent.api, err = gemini.New(ctx, modelCfg)
+		return gemini.New(ctx, modelCfg)
 	case OpenAI:
-		agent.api, err = openai.New(ctx, cli, modelCfg)
+		return openai.New(ctx, cli, modelCfg)
 	case Claude:
-		agent.api, err = claude.New(ctx, cli, modelCfg)
+		return claude.New(ctx, cli, modelCfg)
 	default:
-		return nil, errm.Errorf("unsupported agent type: %s", cfg.Type)
+		return nil, errm.Errorf("unsupported agent type: %s", agentType)
 	}
-	if err != nil {
-		return nil, errm.Wrap(err, "failed to create agent")
+}
+
+// validateModels checks that the model of every stage is available in its API, each model is checked once
+func (a *Agent) validateModels(ctx context.Context) error {
+	validated := make(map[stageAPI]struct{}, len(a.stages))
+	for _, name := range supportedStages {
+		stage := a.stages[name]
+		if _, ok := validated[stage]; ok {
+			continue
+		}
+		validated[stage] = struct{}{}
+
+		if err := stage.api.ValidateModel(ctx, stage.model); err != nil {
+			return errm.Wrap(err, "invalid model", "stage", name, "model", stage.model)
+		}
+		model.ContextLogger(ctx, a.log).Debug("model validated", "stage", name, "model", stage.model)
 	}
+	return nil
+}
 
-	return agent, nil
+// SetMetrics sets the recorder of model calls
+func (a *Agent) SetMetrics(recorder interfaces.MetricsRecorder) {
+	a.metrics = recorder
+}
+
+// ModelName returns the name of the model used for the code review, it is empty for the default model of the backend
+func (a *Agent) ModelName() string {
+	return a.stages[stageCodeReview].model
 }
 
 // GenerateDescription generates a description for code changes
 func (a *Agent) GenerateDescription(ctx context.Context, diff string) (string, error) {
-	response, err := a.apiCall(ctx, a.pb.BuildDescriptionPrompt(diff), false)
+	response, err := a.apiCall(ctx, stageDescription, a.pb.BuildDescriptionPrompt(diff), false)
 	if err != nil {
 		return "", errm.Wrap(err, "failed to call API for description")
 	}
 
-	a.log.Debug("description generated",
+	model.ContextLogger(ctx, a.log).Debug("description generated",
 		"input_tokens", response.PromptTokens,
 		"output_tokens", response.CompletionTokens,
 		"total_tokens", response.TotalTokens,
@@ -92,12 +189,12 @@ func (a *Agent) GenerateDescription(ctx context.Context, diff string) (string, e
 // GenerateChangesOverview generates an overview of code changes§
 func (a *Agent) GenerateChangesOverview(ctx context.Context, diff string) ([]model.FileChangeInfo, error) {
 	prompt := a.pb.BuildChangesOverviewPrompt(diff)
-	response, err := a.apiCall(ctx, prompt, true)
+	response, err := a.apiCall(ctx, stageChangesOverview, prompt, true)
 	if err != nil {
 		return nil, errm.Wrap(err, "failed to call API for changes overview")
 	}
 
-	a.log.Debug("changes overview generated",
+	model.ContextLogger(ctx, a.log).Debug("changes overview generated",
 		"input_tokens", response.PromptTokens,
 		"output_tokens", response.CompletionTokens,
 		"total_tokens", response.TotalTokens,
@@ -113,14 +210,16 @@ func (a *Agent) GenerateChangesOverview(ctx context.Context, diff string) ([]mod
 	return result, nil
 }
 
-// GenerateArchitectureReview generates an architecture review for all code changes
-func (a *Agent) GenerateArchitectureReview(ctx context.Context, diff string) (string, error) {
-	response, err := a.apiCall(ctx, a.pb.BuildArchitectureReviewPrompt(diff), false)
+// GenerateArchitectureReview generates an architecture review for summaries of all code changes,
+// instructions are custom instructions of the team added to the system prompt, intent is the title
+// and the description of the merge request added to the user prompt, both may be empty
+func (a *Agent) GenerateArchitectureReview(ctx context.Context, changes, instructions, intent string) (string, error) {
+	response, err := a.apiCall(ctx, stageArchitectureReview, a.pb.BuildArchitectureReviewPrompt(changes, instructions, intent), false)
 	if err != nil {
 		return "", errm.Wrap(err, "failed to call API for architecture review")
 	}
 
-	a.log.Debug("architecture review generated",
+	model.ContextLogger(ctx, a.log).Debug("architecture review generated",
 		"input_tokens", response.PromptTokens,
 		"output_tokens", response.CompletionTokens,
 		"total_tokens", response.TotalTokens,
@@ -129,66 +228,68 @@ func (a *Agent) GenerateArchitectureReview(ctx context.Context, diff string) (st
 	return response.Content, nil
 }
 
-// ReviewCode performs a code review on the given file
-func (a *Agent) ReviewCode(ctx context.Context, filename, fullFileContent, cleanDiff string) (*model.FileReviewResult, error) {
-	prompt := a.pb.BuildReviewPrompt(filename, fullFileContent, cleanDiff)
-	response, err := a.apiCall(ctx, prompt, true)
+// ReviewCode performs a code review on the given file, guidance is project rules and custom instructions
+// of the team added to the system prompt, they may be empty
+func (a *Agent) ReviewCode(ctx context.Context, filename, fileContext, cleanDiff string, guidance prompts.ReviewGuidance) (*model.FileReviewResult, error) {
+	prompt := a.pb.BuildReviewPrompt(filename, fileContext, cleanDiff, guidance)
+	response, err := a.apiCall(ctx, stageCodeReview, prompt, true)
 	if err != nil {
 		return nil, errm.Wrap(err, "failed to call API for enhanced structured review")
 	}
 
-	a.log.Debug("simple code review generated",
+	model.ContextLogger(ctx, a.log).Debug("simple code review generated",
 		"input_tokens", response.PromptTokens,
 		"output_tokens", response.CompletionTokens,
 		"total_tokens", response.TotalTokens,
 		"filename", filename,
 	)
 
-	result, err := unmarshal[model.FileReviewResult](response.Content)
+	result, err := a.parseReview(ctx, prompt, response, filename)
 	if err != nil {
 		fmt.Println(response)
 		return nil, errm.Wrap(err, "failed to parse enhanced structured review response as JSON")
 	}
 
-	result.File = filename
-
-	return &result, nil
+	return result, nil
 }
 
 // ReviewCodeWithContext performs enhanced code review using rich context information
-func (a *Agent) ReviewCodeWithContext(ctx context.Context, filename string, enhancedCtx *prompts.EnhancedContext) (*model.FileReviewResult, error) {
-	prompt := a.pb.BuildEnhancedReviewPrompt(filename, enhancedCtx, enhancedCtx.CleanDiff)
-	response, err := a.apiCall(ctx, prompt, true)
+func (a *Agent) ReviewCodeWithContext(ctx context.Context, filename string, enhancedCtx *prompts.EnhancedContext, guidance prompts.ReviewGuidance) (*model.FileReviewResult, error) {
+	prompt := a.pb.BuildEnhancedReviewPrompt(filename, enhancedCtx, enhancedCtx.CleanDiff, guidance)
+	response, err := a.apiCall(ctx, stageCodeReview, prompt, true)
 	if err != nil {
 		return nil, errm.Wrap(err, "failed to call API for enhanced context review")
 	}
 
-	a.log.Debug("enhanced code review generated",
+	model.ContextLogger(ctx, a.log).Debug("enhanced code review generated",
 		"input_tokens", response.PromptTokens,
 		"output_tokens", response.CompletionTokens,
 		"total_tokens", response.TotalTokens,
 		"filename", filename,
 	)
 
-	result, err := unmarshal[model.FileReviewResult](response.Content)
+	result, err := a.parseReview(ctx, prompt, response, filename)
 	if err != nil {
 		fmt.Println(response)
 		return nil, errm.Wrap(err, "failed to parse enhanced context review response as JSON")
 	}
 
-	result.File = filename
-
-	return &result, nil
+	return result, nil
 }
 
-func (a *Agent) apiCall(ctx context.Context, prompt model.Prompt, isJSON bool) (model.APIResponse, error) {
-	response, err := a.api.CallAPI(ctx, model.APIRequest{
+func (a *Agent) apiCall(ctx context.Context, stage string, prompt model.Prompt, isJSON bool) (model.APIResponse, error) {
+	target := a.stages[stage]
+
+	start := time.Now()
+	response, err := target.api.CallAPI(ctx, model.APIRequest{
+		Model:        target.model,
 		Prompt:       prompt.UserPrompt,
 		SystemPrompt: prompt.SystemPrompt,
 		MaxTokens:    a.cfg.MaxTokens,
 		Temperature:  a.cfg.Temperature,
 		ResponseType: lang.If(isJSON, "application/json", "text/plain"),
 	})
+	a.metrics.LLMCall(stage, time.Since(start), response.PromptTokens, response.CompletionTokens, err)
 	if err != nil {
 		return model.APIResponse{}, errm.Wrap(err, "failed to call API")
 	}
@@ -2,6 +2,8 @@ package claude
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -15,6 +17,7 @@ import (
 const (
 	defaultModel   = "claude-3-5-haiku-20241022"
 	defaultBaseURL = "https://api.anthropic.com"
+	apiVersion     = "2023-06-01"
 )
 
 var _ interfaces.AgentAPI = (*Agent)(nil)
@@ -54,7 +57,7 @@ func New(ctx context.Context, cli *cliex.HTTP, cfg model.ModelConfig) (*Agent, e
 func (a *Agent) CallAPI(ctx context.Context, req model.APIRequest) (model.APIResponse, error) {
 	// Prepare request
 	reqBody := messagesRequest{
-		Model:       a.cfg.Model,
+		Model:       lang.Check(req.Model, a.cfg.Model),
 		System:      req.SystemPrompt,
 		MaxTokens:   req.MaxTokens,
 		Temperature: req.Temperature,
@@ -95,11 +98,28 @@ func (a *Agent) CallAPI(ctx context.Context, req model.APIRequest) (model.APIRes
 		PromptTokens:     respBody.Usage.InputTokens,
 		CompletionTokens: respBody.Usage.OutputTokens,
 		TotalTokens:      respBody.Usage.InputTokens + respBody.Usage.OutputTokens,
+		Truncated:        respBody.StopReason == "max_tokens",
 	}
 
 	return out, nil
 }
 
+// ValidateModel checks that the model is available in the API, empty name checks the configured model
+func (a *Agent) ValidateModel(ctx context.Context, name string) error {
+	name = lang.Check(name, a.cfg.Model)
+	modelsURL := strings.TrimSuffix(strings.TrimSuffix(a.cfg.URL, "/"), "/v1/messages") + "/v1/models/" + url.PathEscape(name)
+
+	_, err := a.cli.Request(ctx, modelsURL, cliex.RequestOpts{
+		Method:  http.MethodGet,
+		Headers: map[string]string{"anthropic-version": apiVersion},
+	})
+	if err != nil {
+		return errm.Wrap(err, "failed to get model", "model", name)
+	}
+
+	return nil
+}
+
 // testConnection tests the connection to Claude API
 func (a *Agent) testConnection(ctx context.Context) error {
 	// Simple test prompt
@@ -11,6 +11,7 @@ import (
 
 const (
 	defaultTemperature = 0.5
+	maxTemperature     = 2
 	defaultMaxTokens   = 10000
 	defaultTimeout     = 30 * time.Second
 	defaultMaxRetries  = 5
@@ -30,6 +31,16 @@ const (
 
 var supportedAgentTypes = []AgentType{Gemini, OpenAI, Claude}
 
+var supportedStages = []string{stageDescription, stageChangesOverview, stageCodeReview, stageArchitectureReview}
+
+// StageConfig represents the model of a review stage, empty fields are taken from the agent config
+type StageConfig struct {
+	Type    AgentType `yaml:"type"`
+	APIKey  string    `yaml:"api_key"`
+	Model   string    `yaml:"model"`
+	BaseURL string    `yaml:"base_url"`
+}
+
 // Config represents AI agent configuration
 type Config struct {
 	Type        AgentType `yaml:"type" env:"AGENT_TYPE"` // gemini, openai, claude, etc.
@@ -47,22 +58,65 @@ type Config struct {
 	IsTest     bool          `yaml:"is_test" env:"AGENT_IS_TEST"`
 
 	Language model.Language `yaml:"language" env:"AGENT_LANGUAGE"`
+
+	// DisableContinuation disables the follow-up request for the rest of findings of a code review response
+	// truncated at max_tokens, findings of the truncated response are kept anyway
+	DisableContinuation bool `yaml:"disable_continuation" env:"AGENT_DISABLE_CONTINUATION"`
+
+	// Stages overrides the model for stages like description, changes_overview, code_review or architecture_review
+	Stages map[string]StageConfig `yaml:"stages"`
 }
 
 func (c *Config) PrepareAndValidate() error {
-	if c.APIKey == "" {
-		return errm.New("api key is required")
-	}
-	if c.Type == "" || !slices.Contains(supportedAgentTypes, c.Type) {
-		return errm.New("invalid agent type: %s", c.Type)
+	if err := c.Validate(); err != nil {
+		return err
 	}
+	c.setDefaults()
+	return nil
+}
 
+func (c *Config) setDefaults() {
 	c.Temperature = lang.Check(c.Temperature, defaultTemperature)
 	c.MaxTokens = lang.Check(c.MaxTokens, defaultMaxTokens)
 	c.Timeout = lang.Check(c.Timeout, defaultTimeout)
 	c.MaxRetries = lang.Check(c.MaxRetries, defaultMaxRetries)
 	c.RetryDelay = lang.Check(c.RetryDelay, defaultRetryDelay)
 	c.UserAgent = lang.Check(c.UserAgent, defaultUserAgent)
+}
 
-	return nil
+// Validate checks the config and returns all found problems at once
+func (c Config) Validate() error {
+	errs := errm.NewList()
+	if c.APIKey == "" {
+		errs.New("api key is required")
+	}
+	if c.Type == "" || !slices.Contains(supportedAgentTypes, c.Type) {
+		errs.Errorf("invalid agent type %q, expected one of %v", c.Type, supportedAgentTypes)
+	}
+	if c.BaseURL != "" && c.Model == "" {
+		// default models of agent types are not available at custom endpoints like Azure OpenAI or local models
+		errs.New("model is required with custom base_url")
+	}
+	if c.Temperature < 0 || c.Temperature > maxTemperature {
+		errs.Errorf("temperature must be within [0, %v], got %v", maxTemperature, c.Temperature)
+	}
+	if c.MaxTokens < 0 || c.MaxRetries < 0 || c.Timeout < 0 || c.RetryDelay < 0 {
+		errs.New("max_tokens, max_retries, timeout and retry_delay must not be negative")
+	}
+	for name, stage := range c.Stages {
+		if !slices.Contains(supportedStages, name) {
+			errs.Errorf("unknown stage %q, expected one of %v", name, supportedStages)
+			continue
+		}
+		if stage.Type != "" && !slices.Contains(supportedAgentTypes, stage.Type) {
+			errs.Errorf("invalid agent type %q of stage %q, expected one of %v", stage.Type, name, supportedAgentTypes)
+		}
+		if stage.Type != "" && stage.Type != c.Type && stage.APIKey == "" {
+			errs.Errorf("api key is required for stage %q with agent type %q", name, stage.Type)
+		}
+		if stage.BaseURL != "" && stage.Model == "" {
+			errs.Errorf("model is required for stage %q with custom base_url", name)
+		}
+	}
+	return errs.Err()
 }
@@ -76,7 +76,7 @@ func (a *Agent) CallAPI(ctx context.Context, req model.APIRequest) (model.APIRes
 	}
 
 	result, err := a.client.Models.GenerateContent(ctx,
-		a.config.Model,
+		lang.Check(req.Model, a.config.Model),
 		[]*genai.Content{{Parts: []*genai.Part{{Text: req.Prompt}}}},
 		config,
 	)
@@ -85,11 +85,13 @@ func (a *Agent) CallAPI(ctx context.Context, req model.APIRequest) (model.APIRes
 	}
 
 	var content string
+	var truncated bool
 	if len(result.Candidates) > 0 {
 		candidate := result.Candidates[0]
 		if candidate.Content != nil && len(candidate.Content.Parts) > 0 {
 			content = candidate.Content.Parts[0].Text
 		}
+		truncated = candidate.FinishReason == genai.FinishReasonMaxTokens
 	}
 
 	out := model.APIResponse{
@@ -98,11 +100,21 @@ func (a *Agent) CallAPI(ctx context.Context, req model.APIRequest) (model.APIRes
 		PromptTokens:     int(result.UsageMetadata.PromptTokenCount),
 		CompletionTokens: int(result.UsageMetadata.CandidatesTokenCount),
 		TotalTokens:      int(result.UsageMetadata.TotalTokenCount),
+		Truncated:        truncated,
 	}
 
 	return out, nil
 }
 
+// ValidateModel checks that the model is available in the API, empty name checks the configured model
+func (a *Agent) ValidateModel(ctx context.Context, name string) error {
+	name = lang.Check(name, a.config.Model)
+	if _, err := a.client.Models.Get(ctx, name, nil); err != nil {
+		return errm.Wrap(a.handleAPIError(err), "failed to get model", "model", name)
+	}
+	return nil
+}
+
 // handleAPIError handles various API errors and returns appropriate error types
 func (a *Agent) handleAPIError(err error) error {
 	errStr := err.Error()
@@ -0,0 +1,54 @@
+package mock
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+const (
+	// emptyReview is the code review response for files without a prepared one
+	emptyReview = `{"file": "", "comments": [], "has_issues": false}`
+	// textResponse is the response of stages with text output like the description
+	textResponse = "Mock response."
+)
+
+// reviewedFileRegex matches the reviewed file of the code review prompt
+var reviewedFileRegex = regexp.MustCompile(`(?m)^File name: (.+)$`)
+
+var _ interfaces.AgentAPI = (*Agent)(nil)
+
+// Agent is a deterministic model for offline evaluation without an API key: code review prompts get the prepared
+// response of the reviewed file, other prompts get a fixed text
+type Agent struct {
+	responses map[string]string
+}
+
+// New creates the mock with code review responses by paths of reviewed files, a response is the JSON
+// the model would return; files without a response have no issues
+func New(responses map[string]string) *Agent {
+	return &Agent{responses: responses}
+}
+
+// CallAPI returns the response prepared for the prompt
+func (a *Agent) CallAPI(_ context.Context, req model.APIRequest) (model.APIResponse, error) {
+	content := textResponse
+	if req.ResponseType == "application/json" {
+		content = emptyReview
+		if match := reviewedFileRegex.FindStringSubmatch(req.Prompt); match != nil {
+			if response, ok := a.responses[strings.TrimSpace(match[1])]; ok {
+				content = response
+			}
+		}
+	}
+	return model.APIResponse{CreateTime: time.Now(), Content: content}, nil
+}
+
+// ValidateModel accepts any model
+func (a *Agent) ValidateModel(context.Context, string) error {
+	return nil
+}
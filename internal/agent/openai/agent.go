@@ -2,6 +2,7 @@ package openai
 
 import (
 	"context"
+	"net/url"
 	"strings"
 	"time"
 
@@ -54,7 +55,7 @@ func New(ctx context.Context, cli *cliex.HTTP, config model.ModelConfig) (*Agent
 func (a *Agent) CallAPI(ctx context.Context, req model.APIRequest) (model.APIResponse, error) {
 	// Prepare request
 	reqBody := chatCompletionRequest{
-		Model: a.cfg.Model,
+		Model: lang.Check(req.Model, a.cfg.Model),
 		Messages: []message{
 			{
 				Role:    "system",
@@ -84,8 +85,10 @@ func (a *Agent) CallAPI(ctx context.Context, req model.APIRequest) (model.APIRes
 
 	// Extract response
 	var content string
+	var truncated bool
 	if len(respBody.Choices) > 0 {
 		content = strings.TrimSpace(respBody.Choices[0].Message.Content)
+		truncated = respBody.Choices[0].FinishReason == "length"
 	}
 
 	out := model.APIResponse{
@@ -94,11 +97,27 @@ func (a *Agent) CallAPI(ctx context.Context, req model.APIRequest) (model.APIRes
 		PromptTokens:     respBody.Usage.PromptTokens,
 		CompletionTokens: respBody.Usage.CompletionTokens,
 		TotalTokens:      respBody.Usage.TotalTokens,
+		Truncated:        truncated,
 	}
 
 	return out, nil
 }
 
+// ValidateModel checks that the model is available in the API, empty name checks the configured model
+func (a *Agent) ValidateModel(ctx context.Context, name string) error {
+	name = lang.Check(name, a.cfg.Model)
+	modelsURL := strings.TrimSuffix(strings.TrimSuffix(a.cfg.URL, "/"), "/chat/completions") + "/models/" + url.PathEscape(name)
+
+	var respBody struct {
+		ID string `json:"id"`
+	}
+	if _, err := a.cli.Get(ctx, modelsURL, &respBody); err != nil {
+		return errm.Wrap(err, "failed to get model", "model", name)
+	}
+
+	return nil
+}
+
 // testConnection tests the connection to OpenAI API
 func (a *Agent) testConnection(ctx context.Context) error {
 	// Simple test prompt
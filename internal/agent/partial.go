@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+// parseReview parses the code review response; complete comments of a response truncated at the token limit
+// or of invalid JSON are salvaged, the rest of a truncated review is requested once unless it is disabled
+func (a *Agent) parseReview(ctx context.Context, prompt model.Prompt, response model.APIResponse, filename string) (*model.FileReviewResult, error) {
+	log := model.ContextLogger(ctx, a.log)
+
+	if !response.Truncated {
+		result, err := unmarshal[model.FileReviewResult](response.Content)
+		if err == nil {
+			result.File = filename
+			return &result, nil
+		}
+		comments, lost, salvageErr := salvageComments(response.Content)
+		if salvageErr != nil || len(comments) == 0 {
+			return nil, err
+		}
+		log.Warn("review response is invalid JSON, complete comments are recovered",
+			"filename", filename, "salvaged", len(comments), "lost_incomplete", lost, "error", err)
+		return &model.FileReviewResult{File: filename, Comments: comments, HasIssues: true}, nil
+	}
+
+	comments, lost, err := salvageComments(response.Content)
+	if err != nil {
+		log.Warn("failed to recover comments of truncated review response", "filename", filename, "error", err)
+	}
+	log.Warn("review response is truncated at the token limit, complete comments are recovered",
+		"filename", filename, "salvaged", len(comments), "lost_incomplete", lost, "output_tokens", response.CompletionTokens)
+
+	if !a.cfg.DisableContinuation {
+		rest, err := a.continueReview(ctx, prompt, comments)
+		if err != nil {
+			log.Warn("failed to request the rest of truncated review", "filename", filename, "error", err)
+		} else {
+			log.Info("received the rest of truncated review", "filename", filename, "comments", len(rest))
+			comments = append(comments, rest...)
+		}
+	}
+
+	return &model.FileReviewResult{File: filename, Comments: comments, HasIssues: len(comments) > 0}, nil
+}
+
+// continueReview requests issues that are not in the comments of the truncated response,
+// a truncated continuation is salvaged without more requests
+func (a *Agent) continueReview(ctx context.Context, prompt model.Prompt, reported []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	response, err := a.apiCall(ctx, stageCodeReview, a.pb.BuildReviewContinuationPrompt(prompt, reported), true)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to call API for review continuation")
+	}
+
+	if !response.Truncated {
+		if result, err := unmarshal[model.FileReviewResult](response.Content); err == nil {
+			return result.Comments, nil
+		}
+	}
+	comments, _, err := salvageComments(response.Content)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to parse review continuation")
+	}
+	return comments, nil
+}
+
+// salvageComments decodes complete objects of the "comments" array of a partial JSON response one by one,
+// lost is true if decoding stopped at an incomplete or invalid object, the objects after it are lost too
+func salvageComments(response string) (comments []*model.ReviewAIComment, lost bool, err error) {
+	key := strings.Index(response, `"comments"`)
+	if key == -1 {
+		return nil, false, errm.New("no comments array in response")
+	}
+	start := strings.IndexByte(response[key:], '[')
+	if start == -1 {
+		return nil, false, errm.New("no comments array in response")
+	}
+
+	rest := response[key+start+1:]
+	for {
+		rest = strings.TrimLeft(rest, " \t\r\n,")
+		if rest == "" || rest[0] == ']' {
+			return comments, false, nil
+		}
+		end := objectEnd(rest)
+		if end == -1 {
+			return comments, true, nil
+		}
+		var comment model.ReviewAIComment
+		if err := json.Unmarshal([]byte(rest[:end]), &comment); err != nil {
+			return comments, true, nil
+		}
+		comments = append(comments, &comment)
+		rest = rest[end:]
+	}
+}
+
+// objectEnd returns the index after the JSON object the text starts with, -1 if the object is not complete
+func objectEnd(text string) int {
+	if text[0] != '{' {
+		return -1
+	}
+	depth, inString, escaped := 0, false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			escaped = c == '\\'
+			inString = c != '"'
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
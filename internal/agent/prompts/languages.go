@@ -33,6 +33,12 @@ type DescriptionHeaders struct {
 type ListOfChangesHeaders struct {
 	Title       string `yaml:"general_header"`
 	TableHeader string `yaml:"table_header"`
+	// SkippedHeader precedes files that are not reviewed because of their extension
+	SkippedHeader string `yaml:"skipped_header"`
+	// TooLargeHeader precedes files that are not reviewed because their diff is too large or not available
+	TooLargeHeader string `yaml:"too_large_header"`
+	// ConfigChangesHeader precedes changed keys of JSON, YAML and TOML files
+	ConfigChangesHeader string `yaml:"config_changes_header"`
 
 	FeatureTypeText            string `yaml:"feature_type_text"`
 	BugFixTypeText             string `yaml:"bug_fix_type_text"`
@@ -98,6 +104,11 @@ var DefaultLanguages = map[model.Language]LanguageConfig{
 			Title:       "📝 List of changes",
 			TableHeader: "| File | Change type | Diff | Description |",
 
+			SkippedHeader:  "⏭️ Not reviewed by file extension",
+			TooLargeHeader: "📦 Not reviewed, the diff is too large",
+
+			ConfigChangesHeader: "⚙️ Config changes",
+
 			FeatureTypeText:            "⚡️ New feature",
 			BugFixTypeText:             "🐛 Bug fix",
 			RefactorTypeText:           "🛠️ Refactoring",
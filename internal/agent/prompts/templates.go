@@ -14,11 +14,16 @@ type EnhancedContext struct {
 	CleanDiff          string
 	ImportedPackages   []string
 	RelatedFiles       []RelatedFile
+	Callers            []CallerSnippet
 	FunctionSignatures []FunctionSignature
 	TypeDefinitions    []TypeDefinition
 	UsagePatterns      []UsagePattern
 	SecurityContext    SecurityContext
 	SemanticChanges    []SemanticChange
+	ConcurrencyHints   []ConcurrencyHint
+
+	// ContentAvailable is false if the context is built from the diff only without full file content
+	ContentAvailable bool
 }
 
 // RelatedFile represents a file that has relationships with the target file
@@ -28,13 +33,25 @@ type RelatedFile struct {
 	Snippet      string
 }
 
+// CallerSnippet is the code of an unchanged function calling a changed one
+type CallerSnippet struct {
+	Label     string // e.g. "caller of changed Server.Start"
+	Name      string
+	Path      string
+	CallLines []int
+	Snippet   string
+}
+
 // FunctionSignature represents a function definition
 type FunctionSignature struct {
 	Name       string
+	Receiver   string // receiver of a method, empty for functions
 	Parameters []string
 	Returns    []string
 	IsExported bool
 	LineNumber int
+	// Annotations are facts about the function from analysis like its domain, risk level or number of dependents
+	Annotations []string
 }
 
 // TypeDefinition represents struct, interface, or type definitions
@@ -64,6 +81,26 @@ type SecurityContext struct {
 	HandlesFileOperations   bool
 	NetworkOperations       bool
 	CryptographicOperations bool
+
+	// Findings are issues detected deterministically in added lines, they have high confidence
+	Findings []SecurityFinding
+}
+
+// SecurityFinding represents a security issue found by static pattern matching
+type SecurityFinding struct {
+	Type        string
+	Line        int
+	Severity    string
+	Code        string
+	Description string
+}
+
+// ConcurrencyHint represents a suspicious concurrent pattern found by static analysis, it may be a false positive
+type ConcurrencyHint struct {
+	Type        string
+	Line        int
+	Code        string
+	Description string
 }
 
 // SemanticChange represents a high-level change with business impact
@@ -126,27 +163,28 @@ func (tb *Builder) BuildChangesOverviewPrompt(diff string) model.Prompt {
 	}
 }
 
-// BuildArchitectureReviewPrompt creates a prompt for architecture review
-func (tb *Builder) BuildArchitectureReviewPrompt(diff string) model.Prompt {
-	systemPrompt := fmt.Sprintf(architectureReviewSystemPromptTemplate, tb.language.Instructions)
+// BuildArchitectureReviewPrompt creates a prompt for architecture review, custom instructions of the team
+// and the intent of the merge request may be empty
+func (tb *Builder) BuildArchitectureReviewPrompt(changes, instructions, intent string) model.Prompt {
+	systemPrompt := withCustomInstructions(fmt.Sprintf(architectureReviewSystemPromptTemplate, tb.language.Instructions), instructions)
 	userPrompt := fmt.Sprintf(architectureReviewUserPromptTemplate,
 		tb.language.ArchitectureReviewHeaders.GeneralHeader,
 		tb.language.ArchitectureReviewHeaders.ArchitectureIssuesHeader,
 		tb.language.ArchitectureReviewHeaders.PerformanceIssuesHeader,
 		tb.language.ArchitectureReviewHeaders.SecurityIssuesHeader,
 		tb.language.ArchitectureReviewHeaders.DocsImprovementHeader,
-		diff)
+		changes)
 
 	return model.Prompt{
 		SystemPrompt: systemPrompt,
-		UserPrompt:   userPrompt,
+		UserPrompt:   withMergeRequestIntent(userPrompt, intent),
 		Language:     tb.language.Language,
 	}
 }
 
 // BuildEnhancedStructuredReviewPrompt creates a prompt for structured code review with enhanced context
-func (tb *Builder) BuildEnhancedReviewPrompt(filename string, enhancedCtx *EnhancedContext, cleanDiff string) model.Prompt {
-	systemPrompt := fmt.Sprintf(reviewSystemPromptTemplate, tb.language.Instructions)
+func (tb *Builder) BuildEnhancedReviewPrompt(filename string, enhancedCtx *EnhancedContext, cleanDiff string, guidance ReviewGuidance) model.Prompt {
+	systemPrompt := guidance.apply(fmt.Sprintf(reviewSystemPromptTemplate, tb.language.Instructions))
 
 	// Build enhanced context section
 	contextSection := tb.buildContextSection(enhancedCtx)
@@ -154,7 +192,7 @@ func (tb *Builder) BuildEnhancedReviewPrompt(filename string, enhancedCtx *Enhan
 	fmt.Println(filename, contextSection)
 
 	userPrompt := fmt.Sprintf(structuredReviewUserPromptTemplate,
-		contextSection,
+		contextSection+configChangesSection(guidance.ConfigChanges),
 		filename,
 		enhancedCtx.FileContent,
 		cleanDiff,
@@ -162,7 +200,7 @@ func (tb *Builder) BuildEnhancedReviewPrompt(filename string, enhancedCtx *Enhan
 
 	return model.Prompt{
 		SystemPrompt: systemPrompt,
-		UserPrompt:   userPrompt,
+		UserPrompt:   withMergeRequestIntent(userPrompt, guidance.Intent),
 		Language:     tb.language.Language,
 	}
 }
@@ -173,6 +211,12 @@ func (tb *Builder) buildContextSection(ctx *EnhancedContext) string {
 
 	contextBuilder.WriteString("## 🧠 ENHANCED CONTEXT ANALYSIS\n\n")
 
+	if !ctx.ContentAvailable {
+		contextBuilder.WriteString("### ⚠️ LIMITED CONTEXT:\n")
+		contextBuilder.WriteString("Full file content is not available, the context below is built from the diff only. ")
+		contextBuilder.WriteString("Do not report missing imports, declarations or usages that may exist outside of the shown lines.\n\n")
+	}
+
 	// Imported packages
 	if len(ctx.ImportedPackages) > 0 {
 		contextBuilder.WriteString("### 📦 IMPORTED PACKAGES:\n")
@@ -191,12 +235,18 @@ func (tb *Builder) buildContextSection(ctx *EnhancedContext) string {
 				exported = " (exported)"
 			}
 			contextBuilder.WriteString(fmt.Sprintf("- **%s**%s", fn.Name, exported))
+			if fn.Receiver != "" {
+				contextBuilder.WriteString(fmt.Sprintf(" - receiver: %s", fn.Receiver))
+			}
 			if len(fn.Parameters) > 0 {
 				contextBuilder.WriteString(fmt.Sprintf(" - params: %s", strings.Join(fn.Parameters, ", ")))
 			}
 			if len(fn.Returns) > 0 {
 				contextBuilder.WriteString(fmt.Sprintf(" - returns: %s", strings.Join(fn.Returns, ", ")))
 			}
+			if len(fn.Annotations) > 0 {
+				contextBuilder.WriteString(fmt.Sprintf(" - [%s]", strings.Join(fn.Annotations, ", ")))
+			}
 			contextBuilder.WriteString("\n")
 		}
 		contextBuilder.WriteString("\n")
@@ -245,6 +295,12 @@ func (tb *Builder) buildContextSection(ctx *EnhancedContext) string {
 		contextBuilder.WriteString("\n")
 	}
 
+	// Deterministic security findings
+	contextBuilder.WriteString(securityFindingsSection(secCtx.Findings))
+
+	// Concurrency hints
+	contextBuilder.WriteString(concurrencyHintsSection(ctx.ConcurrencyHints))
+
 	// Usage patterns with real code examples
 	if len(ctx.UsagePatterns) > 0 {
 		contextBuilder.WriteString("### 🎯 USAGE PATTERNS:\n")
@@ -287,6 +343,9 @@ func (tb *Builder) buildContextSection(ctx *EnhancedContext) string {
 		contextBuilder.WriteString("\n")
 	}
 
+	// Unchanged callers, they may break if the signature or behavior of the changed function changes
+	contextBuilder.WriteString(callersSection(ctx.Callers))
+
 	// Semantic changes analysis with enhanced details
 	if len(ctx.SemanticChanges) > 0 {
 		contextBuilder.WriteString("### 🧠 SEMANTIC CHANGES ANALYSIS:\n")
@@ -336,19 +395,158 @@ func (tb *Builder) getRelationshipIcon(relationship string) string {
 	}
 }
 
-// BuildReviewPrompt creates a prompt for structured code review with full file content and clean diff (legacy method)
-func (tb *Builder) BuildReviewPrompt(filename, fullFileContent, cleanDiff string) model.Prompt {
-	systemPrompt := fmt.Sprintf(reviewSystemPromptTemplate, tb.language.Instructions)
+// BuildReviewPrompt creates a prompt for structured code review with changed regions of the original file and clean diff,
+// guidance of the team may be empty
+func (tb *Builder) BuildReviewPrompt(filename, fileContext, cleanDiff string, guidance ReviewGuidance) model.Prompt {
+	systemPrompt := guidance.apply(fmt.Sprintf(reviewSystemPromptTemplate, tb.language.Instructions))
 	userPrompt := fmt.Sprintf(structuredReviewUserPromptTemplate,
-		"", // No additional context
+		securityFindingsSection(guidance.SecurityFindings)+concurrencyHintsSection(guidance.ConcurrencyHints)+
+			callersSection(guidance.Callers)+configChangesSection(guidance.ConfigChanges),
 		filename,
-		fullFileContent,
+		fileContext,
 		cleanDiff,
 	)
 
 	return model.Prompt{
 		SystemPrompt: systemPrompt,
-		UserPrompt:   userPrompt,
+		UserPrompt:   withMergeRequestIntent(userPrompt, guidance.Intent),
 		Language:     tb.language.Language,
 	}
 }
+
+// BuildReviewContinuationPrompt extends the code review prompt with the request for issues that are not reported
+// in the truncated response, the reported ones are listed by line and title
+func (tb *Builder) BuildReviewContinuationPrompt(prompt model.Prompt, reported []*model.ReviewAIComment) model.Prompt {
+	var list strings.Builder
+	for _, comment := range reported {
+		list.WriteString(fmt.Sprintf("- line %d: %s\n", comment.Line, comment.Title))
+	}
+	if len(reported) == 0 {
+		list.WriteString("- none\n")
+	}
+
+	prompt.UserPrompt += fmt.Sprintf(reviewContinuationTemplate, list.String())
+	return prompt
+}
+
+// withCustomInstructions appends custom instructions to the system prompt in a delimited section,
+// output format is defined in the user prompt after it, so the instructions can't replace it
+func withCustomInstructions(systemPrompt, instructions string) string {
+	instructions = strings.TrimSpace(strings.ReplaceAll(instructions, customInstructionsEndTag, ""))
+	if instructions == "" {
+		return systemPrompt
+	}
+	return systemPrompt + fmt.Sprintf(customInstructionsTemplate, instructions)
+}
+
+// withMergeRequestIntent puts the intent of the merge request before the user prompt in a delimited section
+func withMergeRequestIntent(userPrompt, intent string) string {
+	intent = strings.TrimSpace(strings.ReplaceAll(intent, mergeRequestIntentEndTag, ""))
+	if intent == "" {
+		return userPrompt
+	}
+	return fmt.Sprintf(mergeRequestIntentTemplate, intent) + userPrompt
+}
+
+// configChangesSection renders changed keys of the config file, it is empty if the file is not a config
+// or its versions can't be parsed
+func configChangesSection(changes []model.ConfigKeyChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	for _, change := range changes {
+		list.WriteString(fmt.Sprintf("- `%s` %s", change.Key, change.Kind))
+		switch change.Kind {
+		case model.ConfigKeyAdded:
+			list.WriteString(fmt.Sprintf(": %s", change.NewValue))
+		case model.ConfigKeyRemoved:
+			list.WriteString(fmt.Sprintf(": was %s", change.OldValue))
+		case model.ConfigKeyChanged:
+			list.WriteString(fmt.Sprintf(": %s → %s", change.OldValue, change.NewValue))
+		}
+		if change.Line > 0 {
+			list.WriteString(fmt.Sprintf(" (line %d)", change.Line))
+		}
+		if change.Risk != "" {
+			list.WriteString(" — RISK: " + change.Risk)
+		}
+		if len(change.AffectedFiles) > 0 {
+			list.WriteString(fmt.Sprintf(" — read by %d files: %s", len(change.AffectedFiles), strings.Join(change.AffectedFiles[:min(len(change.AffectedFiles), 5)], ", ")))
+		}
+		list.WriteString("\n")
+	}
+	return fmt.Sprintf(configChangesTemplate, strings.TrimSuffix(list.String(), "\n"))
+}
+
+// securityFindingsSection renders findings of the static scanner, the model confirms them on the given lines
+func securityFindingsSection(findings []SecurityFinding) string {
+	if len(findings) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	for _, finding := range findings {
+		list.WriteString(fmt.Sprintf("- **%s** (%s) at line %d: %s\n  `%s`\n", finding.Type, finding.Severity, finding.Line, finding.Description, finding.Code))
+	}
+	return fmt.Sprintf(securityFindingsTemplate, strings.TrimSuffix(list.String(), "\n"))
+}
+
+// concurrencyHintsSection renders suspicious concurrent patterns, the model confirms or dismisses them
+func concurrencyHintsSection(hints []ConcurrencyHint) string {
+	if len(hints) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	for _, hint := range hints {
+		list.WriteString(fmt.Sprintf("- **%s** at line %d: %s\n  `%s`\n", hint.Type, hint.Line, hint.Description, hint.Code))
+	}
+	return fmt.Sprintf(concurrencyHintsTemplate, strings.TrimSuffix(list.String(), "\n"))
+}
+
+// callersSection renders unchanged callers of changed functions with their code
+func callersSection(callers []CallerSnippet) string {
+	if len(callers) == 0 {
+		return ""
+	}
+	var list strings.Builder
+	for _, caller := range callers {
+		list.WriteString(fmt.Sprintf("- **%s**: `%s` in %s", caller.Label, caller.Name, caller.Path))
+		if len(caller.CallLines) > 0 {
+			list.WriteString(fmt.Sprintf(" (calls at lines %v)", caller.CallLines))
+		}
+		list.WriteString(":\n```\n")
+		list.WriteString(caller.Snippet)
+		list.WriteString("\n```\n")
+	}
+	return fmt.Sprintf(callersTemplate, strings.TrimSuffix(list.String(), "\n"))
+}
+
+// ReviewGuidance is guidance of the team added to the system prompt of the code review, the intent
+// of the merge request, changed config keys and static findings added to the user prompt, fields may be empty
+type ReviewGuidance struct {
+	// Rules are project coding standards, findings that violate them cite the heading of the rule
+	Rules string
+	// Instructions are custom instructions of the team, they come after rules and take precedence over them
+	Instructions string
+	// Strictness adds the directive of the persona before rules and instructions, empty and balanced add nothing
+	Strictness Strictness
+	// Intent is the title and the description of the merge request, the model checks the code against it
+	Intent string
+	// ConfigChanges are changed keys of the reviewed JSON, YAML or TOML file, values must be already redacted
+	ConfigChanges []model.ConfigKeyChange
+	// SecurityFindings are issues of added lines found by the static scanner, code must be already redacted
+	SecurityFindings []SecurityFinding
+	// ConcurrencyHints are suspicious concurrent patterns of added Go lines, code must be already redacted
+	ConcurrencyHints []ConcurrencyHint
+	// Callers are unchanged functions calling the changed ones, snippets must be already redacted
+	Callers []CallerSnippet
+}
+
+// apply appends the strictness directive, project rules and custom instructions to the system prompt
+func (g ReviewGuidance) apply(systemPrompt string) string {
+	systemPrompt += strictnessTemplates[g.Strictness]
+	if rules := strings.TrimSpace(strings.ReplaceAll(g.Rules, projectRulesEndTag, "")); rules != "" {
+		systemPrompt += fmt.Sprintf(projectRulesTemplate, rules)
+	}
+	return withCustomInstructions(systemPrompt, g.Instructions)
+}
@@ -2,8 +2,14 @@ package app
 
 import (
 	"context"
+	"time"
 
 	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/metrics"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/notify"
 	"github.com/maxbolgarin/codry/internal/provider"
 	"github.com/maxbolgarin/codry/internal/reviewer"
 	"github.com/maxbolgarin/codry/internal/server"
@@ -12,11 +18,20 @@ import (
 	"github.com/maxbolgarin/logze/v2"
 )
 
+const (
+	credentialsCheckAttempts = 5
+	credentialsCheckDelay    = 3 * time.Second
+)
+
 // Codry is the main service that orchestrates all components
 type Codry struct {
 	reviewer       *reviewer.Reviewer
+	provider       interfaces.CodeProvider // provider of the main config
 	webhookHandler *server.Server
 	fetcher        *provider.Fetcher
+	pollers        []*provider.Poller  // pollers of providers with polled projects
+	metrics        *metrics.Prometheus // nil if metrics are disabled
+	notifier       *notify.Dispatcher  // nil if notifications are disabled
 
 	cfg Config
 	log logze.Logger
@@ -24,10 +39,15 @@ type Codry struct {
 
 // New creates a new code review service
 func New(ctx contem.Context, cfg Config) (*Codry, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errm.Wrap(err, "invalid config")
+	}
+
 	service := &Codry{
 		cfg: cfg,
 		log: logze.With("component", "app"),
 	}
+	service.log.Info("starting codry", "prompt_version", prompts.PromptVersion, "analysis_version", model.AnalysisVersion)
 
 	if err := service.init(ctx, cfg); err != nil {
 		return nil, errm.Wrap(err, "failed to initialize service")
@@ -36,11 +56,26 @@ func New(ctx contem.Context, cfg Config) (*Codry, error) {
 	return service, nil
 }
 
-func (s *Codry) StartWebhook(ctx context.Context) error {
-	if err := s.webhookHandler.Start(ctx); err != nil {
+// Serve starts the webhook server and pollers of configured projects and blocks until ctx is canceled,
+// in-flight reviews are drained on shutdown by the server Stop registered in the context
+func (s *Codry) Serve(ctx context.Context) error {
+	for _, poller := range s.pollers {
+		go poller.Run(ctx)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if err := s.webhookHandler.Start(ctx); err != nil {
+			errCh <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-errCh:
 		return errm.Wrap(err, "failed to start webhook handler")
 	}
-	return nil
 }
 
 func (s *Codry) RunReview(ctx context.Context, projectID string) error {
@@ -58,32 +93,151 @@ func (s *Codry) RunReview(ctx context.Context, projectID string) error {
 }
 
 func (s *Codry) init(ctx contem.Context, cfg Config) (err error) {
+	if cfg.Metrics.Enable {
+		if err := cfg.Metrics.PrepareAndValidate(); err != nil {
+			return errm.Wrap(err, "validate metrics config")
+		}
+		s.metrics = metrics.NewPrometheus()
+	}
+	if cfg.Notify.IsEnabled() {
+		s.notifier = notify.New(cfg.Notify)
+	}
 
 	// Create VCS provider
-	codeProvider, err := provider.NewProvider(cfg.Provider)
+	codeProvider, err := s.newProvider(cfg.Provider)
 	if err != nil {
 		return errm.Wrap(err, "failed to create VCS provider")
 	}
-	s.fetcher = provider.NewFetcher(codeProvider)
+	if err := s.checkCredentials(ctx, codeProvider); err != nil {
+		return err
+	}
+	s.provider = codeProvider
+	s.fetcher = provider.NewFetcher(codeProvider, cfg.Provider.Filter)
 
 	// Create AI agent
 	llmAgent, err := agent.New(ctx, cfg.Agent)
 	if err != nil {
 		return errm.Wrap(err, "failed to create AI agent")
 	}
+	if s.metrics != nil {
+		llmAgent.SetMetrics(s.metrics)
+	}
 
 	// Create review service - this is the central orchestrator
 	s.reviewer, err = reviewer.New(cfg.Reviewer, codeProvider, llmAgent)
 	if err != nil {
 		return errm.Wrap(err, "failed to create review service")
 	}
+	s.setReviewerMetrics(s.reviewer, cfg.Provider)
+	s.setReviewerNotifier(s.reviewer)
+	s.addPoller(s.fetcher, cfg.Provider, s.reviewer)
+
+	webhooks := []server.Webhook{{Name: cfg.Provider.WebhookName(), Provider: codeProvider, Reviewer: s.reviewer}}
+	for _, providerCfg := range cfg.Providers {
+		webhook, err := s.initWebhook(ctx, providerCfg, llmAgent)
+		if err != nil {
+			return errm.Wrap(err, "failed to initialize provider "+providerCfg.WebhookName())
+		}
+		webhooks = append(webhooks, webhook)
+	}
 
 	// Create webhook handler - just an event source
-	s.webhookHandler, err = server.New(cfg.Server, codeProvider, s.reviewer)
+	s.webhookHandler, err = server.New(cfg.Server, webhooks...)
 	if err != nil {
 		return errm.Wrap(err, "failed to create webhook handler")
 	}
+	if s.metrics != nil {
+		s.webhookHandler.SetMetrics(cfg.Metrics.Endpoint, s.metrics)
+	}
 	ctx.Add(s.webhookHandler.Stop)
 
 	return nil
 }
+
+// initWebhook creates provider and reviewer of an additional webhook
+func (s *Codry) initWebhook(ctx context.Context, providerCfg provider.Config, llmAgent *agent.Agent) (server.Webhook, error) {
+	codeProvider, err := s.newProvider(providerCfg)
+	if err != nil {
+		return server.Webhook{}, errm.Wrap(err, "failed to create VCS provider")
+	}
+	if err := s.checkCredentials(ctx, codeProvider); err != nil {
+		return server.Webhook{}, err
+	}
+
+	codeReviewer, err := reviewer.New(s.cfg.Reviewer, codeProvider, llmAgent)
+	if err != nil {
+		return server.Webhook{}, errm.Wrap(err, "failed to create review service")
+	}
+	s.setReviewerMetrics(codeReviewer, providerCfg)
+	s.setReviewerNotifier(codeReviewer)
+	s.addPoller(provider.NewFetcher(codeProvider, providerCfg.Filter), providerCfg, codeReviewer)
+
+	return server.Webhook{Name: providerCfg.WebhookName(), Provider: codeProvider, Reviewer: codeReviewer}, nil
+}
+
+// newProvider creates the provider, its API requests trust the configured CA bundle,
+// are throttled by rate limit headers and are recorded if metrics are enabled
+func (s *Codry) newProvider(providerCfg provider.Config) (interfaces.CodeProvider, error) {
+	transport, err := provider.NewTransport(providerCfg)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to create provider transport")
+	}
+	if transport != nil {
+		providerCfg.Transport = transport
+	}
+	if s.metrics != nil {
+		providerCfg.Transport = metrics.Transport(providerCfg.WebhookName(), s.metrics, providerCfg.Transport)
+	}
+	// Throttling is outside of metrics, so delays are not counted as request latency
+	var recorder interfaces.MetricsRecorder
+	if s.metrics != nil {
+		recorder = s.metrics
+	}
+	providerCfg.Transport = provider.RateLimitTransport(providerCfg.WebhookName(), providerCfg.RateLimit, recorder, providerCfg.Transport)
+	return provider.NewProvider(providerCfg)
+}
+
+// addPoller adds a poller of the provider if it has polled projects
+func (s *Codry) addPoller(fetcher *provider.Fetcher, providerCfg provider.Config, codeReviewer *reviewer.Reviewer) {
+	if len(providerCfg.Polling.Projects) > 0 {
+		s.pollers = append(s.pollers, provider.NewPoller(fetcher, providerCfg.Polling, codeReviewer.ReviewMergeRequest))
+	}
+}
+
+// setReviewerMetrics labels metrics of the reviewer with the provider name if metrics are enabled
+func (s *Codry) setReviewerMetrics(codeReviewer *reviewer.Reviewer, providerCfg provider.Config) {
+	if s.metrics != nil {
+		codeReviewer.SetMetrics(s.metrics, providerCfg.WebhookName())
+	}
+}
+
+// setReviewerNotifier sets the notifier of finished reviews if notifications are enabled
+func (s *Codry) setReviewerNotifier(codeReviewer *reviewer.Reviewer) {
+	if s.notifier != nil {
+		codeReviewer.SetNotifier(s.notifier)
+	}
+}
+
+// checkCredentials fails fast if the provider rejects the token and retries on transient errors
+func (s *Codry) checkCredentials(ctx context.Context, codeProvider interfaces.CodeProvider) error {
+	var err error
+	for attempt := 1; attempt <= credentialsCheckAttempts; attempt++ {
+		err = codeProvider.ValidateCredentials(ctx)
+		if err == nil {
+			return nil
+		}
+		if errm.Is(err, model.ErrInvalidCredentials) {
+			return errm.Wrap(err, "provider token is invalid, check provider.token config")
+		}
+
+		s.log.Warn("failed to validate provider credentials, retrying", "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(credentialsCheckDelay):
+		}
+	}
+
+	return errm.Wrap(err, "provider is unavailable")
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// CommitReview is a review of one commit of the project against its first parent,
+// e.g. of a commit pushed to the trunk without a merge request
+type CommitReview struct {
+	ProjectID string
+	SHA       string
+	// Format is one of OutputFormats, JSON by default
+	Format string
+	// Post creates comments of the commit: inline comments at lines of findings and a summary of the rest
+	Post bool
+}
+
+// ReviewCommit reviews the diff of the commit and writes findings to out in the format, the provider must be able
+// to read commits; if the provider can't comment commits, findings are only written to out
+func (s *Codry) ReviewCommit(ctx context.Context, review CommitReview, out io.Writer) error {
+	format, err := outputFormat(review.Format)
+	if err != nil {
+		return err
+	}
+	reader, ok := s.provider.(interfaces.CommitReader)
+	if !ok {
+		return errm.New("provider does not support reading commits")
+	}
+
+	commit, err := reader.GetCommit(ctx, review.ProjectID, review.SHA)
+	if err != nil {
+		return errm.Wrap(err, "failed to get commit", "sha", review.SHA)
+	}
+	diffs, err := reader.GetCommitDiffs(ctx, review.ProjectID, commit.SHA)
+	if err != nil {
+		return errm.Wrap(err, "failed to get commit diffs", "sha", commit.SHA)
+	}
+	s.log.Info("reviewing commit", "project_id", review.ProjectID, "sha", commit.SHA, "files", len(diffs))
+
+	// The commit is reviewed as a merge request of the commit into its parent, positions of findings are in its diff
+	title, _, _ := strings.Cut(commit.Message, "\n")
+	findings, err := s.reviewer.ReviewChanges(ctx, model.ReviewRequest{
+		ProjectID: review.ProjectID,
+		MergeRequest: &model.MergeRequest{
+			Title:        title,
+			Description:  commit.Message,
+			Author:       commit.Author,
+			SourceBranch: commit.SHA,
+			TargetBranch: commit.ParentSHA,
+			SHA:          commit.SHA,
+		},
+		Changes: diffs,
+	})
+	if err != nil {
+		return errm.Wrap(err, "failed to review commit")
+	}
+
+	if review.Post && len(findings) > 0 {
+		created, err := s.reviewer.PostCommitFindings(ctx, review.ProjectID, commit.SHA, findings)
+		switch {
+		case errm.Is(err, model.ErrNotSupported):
+			s.log.Warn("provider does not support commit comments, findings are only written to the output")
+		case err != nil:
+			return errm.Wrap(err, "failed to post findings", "sha", commit.SHA)
+		default:
+			s.log.Info("posted commit comments", "sha", commit.SHA, "comments", created)
+		}
+	}
+
+	return s.writeFindings(out, format, findings)
+}
@@ -1,12 +1,18 @@
 package app
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/metrics"
+	"github.com/maxbolgarin/codry/internal/notify"
 	"github.com/maxbolgarin/codry/internal/provider"
 	"github.com/maxbolgarin/codry/internal/reviewer"
 	"github.com/maxbolgarin/codry/internal/server"
 	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/logze/v2"
 )
 
 // Config represents the main application configuration
@@ -15,7 +21,57 @@ type Config struct {
 	Agent    agent.Config    `yaml:"agent"`
 	Reviewer reviewer.Config `yaml:"review"`
 
-	Server server.Config `yaml:"server"`
+	// Providers are additional providers served by the webhook server on their own paths
+	Providers []provider.Config `yaml:"providers"`
+
+	Server  server.Config  `yaml:"server"`
+	Metrics metrics.Config `yaml:"metrics"`
+	Notify  notify.Config  `yaml:"notify"`
+	Log     LogConfig      `yaml:"log"`
+}
+
+// LogConfig is the configuration of logs, lines of one review have its correlation ID in the review_id field
+type LogConfig struct {
+	// Level is one of trace, debug, info, warn, error, fatal or disabled, debug by default
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+// Validate checks the whole config and returns all found problems at once,
+// so a misconfigured deployment fails at startup with the list of what to fix
+func (c Config) Validate() error {
+	errs := errm.NewList()
+
+	names := make(map[string]struct{}, len(c.Providers)+1)
+	for i, providerCfg := range append([]provider.Config{c.Provider}, c.Providers...) {
+		section := "provider"
+		if i > 0 {
+			section = fmt.Sprintf("providers[%d]", i-1)
+		}
+		if err := providerCfg.Validate(); err != nil {
+			errs.Wrap(err, section)
+		}
+		if _, ok := names[providerCfg.WebhookName()]; ok {
+			errs.Errorf("%s: duplicate webhook name %q, set unique name", section, providerCfg.WebhookName())
+		}
+		names[providerCfg.WebhookName()] = struct{}{}
+	}
+	if err := c.Agent.Validate(); err != nil {
+		errs.Wrap(err, "agent")
+	}
+	if err := c.Reviewer.Validate(); err != nil {
+		errs.Wrap(err, "review")
+	}
+	if err := c.Server.Validate(); err != nil {
+		errs.Wrap(err, "server")
+	}
+	if err := c.Notify.Validate(); err != nil {
+		errs.Wrap(err, "notify")
+	}
+	if c.Log.Level != "" && !slices.Contains(logze.Levels, c.Log.Level) {
+		errs.Errorf("log: invalid level %q, expected one of %v", c.Log.Level, logze.Levels)
+	}
+
+	return errs.Err()
 }
 
 func LoadConfig(path string) (Config, error) {
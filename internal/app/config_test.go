@@ -0,0 +1,100 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/provider"
+)
+
+// validConfig returns the minimal config that passes validation
+func validConfig() Config {
+	return Config{
+		Provider: provider.Config{Type: provider.GitHub, Token: "token"},
+		Agent:    agent.Config{Type: agent.Gemini, APIKey: "key"},
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			modify: func(*Config) {},
+		},
+		{
+			name:    "unsupported provider",
+			modify:  func(cfg *Config) { cfg.Provider.Type = "svn" },
+			wantErr: []string{`provider: invalid provider type "svn"`},
+		},
+		{
+			name:    "missing provider token",
+			modify:  func(cfg *Config) { cfg.Provider.Token = "" },
+			wantErr: []string{"provider: token is required"},
+		},
+		{
+			name: "custom model endpoint without model",
+			modify: func(cfg *Config) {
+				cfg.Agent.BaseURL = "http://localhost:8080"
+			},
+			wantErr: []string{"agent: model is required with custom base_url"},
+		},
+		{
+			name:    "rate limit threshold out of range",
+			modify:  func(cfg *Config) { cfg.Provider.RateLimit.Threshold = 1.5 },
+			wantErr: []string{"rate_limit.threshold must be in [0, 1)"},
+		},
+		{
+			name:    "invalid excluded path glob",
+			modify:  func(cfg *Config) { cfg.Reviewer.FileFilter.ExcludedPaths = []string{"vendor/["} },
+			wantErr: []string{`review: invalid excluded_paths pattern "vendor/["`},
+		},
+		{
+			name: "duplicate webhook names",
+			modify: func(cfg *Config) {
+				cfg.Providers = []provider.Config{cfg.Provider}
+			},
+			wantErr: []string{"providers[0]: duplicate webhook name"},
+		},
+		{
+			name: "all problems at once",
+			modify: func(cfg *Config) {
+				cfg.Provider = provider.Config{}
+				cfg.Agent.APIKey = ""
+				cfg.Log.Level = "verbose"
+			},
+			wantErr: []string{
+				"provider: token is required",
+				`invalid provider type ""`,
+				"agent: api key is required",
+				`log: invalid level "verbose"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/agent/mock"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/provider/local"
+	"github.com/maxbolgarin/codry/internal/reviewer"
+	"github.com/maxbolgarin/errm"
+)
+
+// Files of an evaluation case
+const (
+	evalDiffFile      = "diff.patch"
+	evalBeforeDir     = "before"
+	evalExpectedFile  = "expected.json"
+	evalResponsesFile = "responses.json" // code review responses of the mock model by paths of reviewed files
+)
+
+// Evaluation is a run of the review pipeline over a labeled dataset, every directory with diff.patch is a case:
+// before/ has files of the repository before changes, expected.json has findings that should be found and
+// responses.json has responses of the mock model; findings are matched by file, issue type and line proximity
+type Evaluation struct {
+	// Dir is the root of the dataset, cases are found recursively
+	Dir string
+	// Mock replaces the configured model with a deterministic one answering with responses.json of the case,
+	// no API key is needed
+	Mock bool
+	// Tolerance is the distance in lines between an expected and a found finding that still matches, 0 is the same line
+	Tolerance int
+}
+
+// expectedFinding is a labeled finding of a case, an empty issue type matches any
+type expectedFinding struct {
+	File      string          `json:"file"`
+	Line      int             `json:"line"`
+	IssueType model.IssueType `json:"issue_type,omitempty"`
+	// Title describes the issue in the report, it is not matched
+	Title string `json:"title,omitempty"`
+}
+
+// evalScore is the number of matched, unexpected and missed findings
+type evalScore struct {
+	matched, unexpected, missed int
+}
+
+func (s evalScore) precision() float64 {
+	if s.matched+s.unexpected == 0 {
+		return 1
+	}
+	return float64(s.matched) / float64(s.matched+s.unexpected)
+}
+
+func (s evalScore) recall() float64 {
+	if s.matched+s.missed == 0 {
+		return 1
+	}
+	return float64(s.matched) / float64(s.matched+s.missed)
+}
+
+func (s evalScore) String() string {
+	return fmt.Sprintf("precision %.2f recall %.2f (matched %d, unexpected %d, missed %d)",
+		s.precision(), s.recall(), s.matched, s.unexpected, s.missed)
+}
+
+// RunEvaluation reviews every case of the dataset and writes its score with missed and unexpected findings to out,
+// then the precision and recall of all cases; nothing is posted and the provider config is not used;
+// it returns error if any case can't be reviewed
+func RunEvaluation(ctx context.Context, cfg Config, eval Evaluation, out io.Writer) error {
+	errs := errm.NewList()
+	if !eval.Mock {
+		if err := cfg.Agent.Validate(); err != nil {
+			errs.Wrap(err, "agent")
+		}
+	}
+	if err := cfg.Reviewer.Validate(); err != nil {
+		errs.Wrap(err, "review")
+	}
+	if err := errs.Err(); err != nil {
+		return errm.Wrap(err, "invalid config")
+	}
+	if eval.Tolerance < 0 {
+		return errm.New("tolerance must not be negative")
+	}
+
+	cases, err := findEvalCases(eval.Dir)
+	if err != nil {
+		return err
+	}
+	if len(cases) == 0 {
+		return errm.Errorf("no cases found in %s", eval.Dir)
+	}
+
+	var llmAgent *agent.Agent
+	if !eval.Mock {
+		if llmAgent, err = agent.New(ctx, cfg.Agent); err != nil {
+			return errm.Wrap(err, "failed to create AI agent")
+		}
+	}
+
+	var (
+		total  evalScore
+		failed int
+	)
+	for _, dir := range cases {
+		name, _ := filepath.Rel(eval.Dir, dir)
+		score, report, err := runEvalCase(ctx, cfg, eval, llmAgent, dir)
+		if err != nil {
+			failed++
+			fmt.Fprintf(out, "FAIL %s: %s\n", name, err)
+			continue
+		}
+		total.matched += score.matched
+		total.unexpected += score.unexpected
+		total.missed += score.missed
+		fmt.Fprintf(out, "%s: %s\n%s", name, score, report)
+	}
+	fmt.Fprintf(out, "\ntotal of %d cases: %s\n", len(cases)-failed, total)
+
+	if failed > 0 {
+		return errm.Errorf("%d of %d cases failed", failed, len(cases))
+	}
+	return nil
+}
+
+// runEvalCase reviews the case with a new reviewer, so caches of previous cases are not used,
+// and scores its findings; the report lists missed and unexpected findings
+func runEvalCase(ctx context.Context, cfg Config, eval Evaluation, llmAgent *agent.Agent, dir string) (evalScore, string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, evalDiffFile))
+	if err != nil {
+		return evalScore{}, "", errm.Wrap(err, "failed to read diff file")
+	}
+	diffs := model.ParseUnifiedDiff(string(content))
+	if len(diffs) == 0 {
+		return evalScore{}, "", errm.Errorf("no file diffs found in %s", evalDiffFile)
+	}
+
+	var expected []expectedFinding
+	if err := readEvalJSON(filepath.Join(dir, evalExpectedFile), &expected); err != nil {
+		return evalScore{}, "", err
+	}
+
+	if eval.Mock {
+		responses := make(map[string]json.RawMessage)
+		if err := readEvalJSON(filepath.Join(dir, evalResponsesFile), &responses); err != nil && !errm.Is(err, fs.ErrNotExist) {
+			return evalScore{}, "", err
+		}
+		mockResponses := make(map[string]string, len(responses))
+		for filePath, response := range responses {
+			mockResponses[filePath] = string(response)
+		}
+		llmAgent = agent.NewWithAPI(cfg.Agent, mock.New(mockResponses))
+	}
+
+	codeProvider := local.New(filepath.Join(dir, evalBeforeDir), diffs, nil)
+	codeReviewer, err := reviewer.New(cfg.Reviewer, codeProvider, llmAgent)
+	if err != nil {
+		return evalScore{}, "", errm.Wrap(err, "failed to create review service")
+	}
+	found, err := codeReviewer.ReviewChanges(ctx, model.ReviewRequest{
+		ProjectID:    localProjectID,
+		MergeRequest: codeProvider.MergeRequest(),
+		Changes:      diffs,
+	})
+	if err != nil {
+		return evalScore{}, "", errm.Wrap(err, "failed to review case")
+	}
+
+	score, report := scoreFindings(expected, found, eval.Tolerance)
+	return score, report, nil
+}
+
+// scoreFindings matches every expected finding with the nearest unmatched found finding of the same file and
+// issue type within the tolerance, lines of a found range are at zero distance
+func scoreFindings(expected []expectedFinding, found []*model.ReviewAIComment, tolerance int) (evalScore, string) {
+	found = slices.Clone(found)
+	slices.SortStableFunc(found, func(a, b *model.ReviewAIComment) int {
+		return cmp.Or(cmp.Compare(a.FilePath, b.FilePath), cmp.Compare(a.Line, b.Line))
+	})
+
+	var (
+		score   evalScore
+		report  string
+		matched = make([]bool, len(found))
+	)
+	for _, want := range expected {
+		best, bestDistance := -1, tolerance+1
+		for i, finding := range found {
+			if matched[i] || finding.FilePath != want.File || (want.IssueType != "" && finding.IssueType != want.IssueType) {
+				continue
+			}
+			if distance := lineDistance(finding, want.Line); distance < bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		if best < 0 {
+			score.missed++
+			report += fmt.Sprintf("  missed     %s:%d %s %s\n", want.File, want.Line, cmp.Or(want.IssueType, "any"), want.Title)
+			continue
+		}
+		matched[best] = true
+		score.matched++
+	}
+	for i, finding := range found {
+		if !matched[i] {
+			score.unexpected++
+			report += fmt.Sprintf("  unexpected %s:%d %s %s\n", finding.FilePath, finding.Line, finding.IssueType, finding.Title)
+		}
+	}
+	return score, report
+}
+
+// lineDistance returns the number of lines between the line and the lines of the finding
+func lineDistance(finding *model.ReviewAIComment, line int) int {
+	end := max(finding.Line, finding.EndLine)
+	switch {
+	case line < finding.Line:
+		return finding.Line - line
+	case line > end:
+		return line - end
+	default:
+		return 0
+	}
+}
+
+// readEvalJSON reads the JSON file of the case
+func readEvalJSON(filePath string, v any) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return errm.Wrap(err, "failed to read file", "file", filepath.Base(filePath))
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return errm.Wrap(err, "failed to parse file", "file", filepath.Base(filePath))
+	}
+	return nil
+}
+
+// findEvalCases returns directories with diff files in the dataset in lexical order
+func findEvalCases(root string) ([]string, error) {
+	var cases []string
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == evalBeforeDir {
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() && entry.Name() == evalDiffFile {
+			cases = append(cases, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to find cases", "dir", root)
+	}
+	return cases, nil
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/provider/local"
+	"github.com/maxbolgarin/codry/internal/reviewer"
+	"github.com/maxbolgarin/errm"
+)
+
+const localProjectID = "local"
+
+// DiffReview is a review of a unified diff file from disk
+type DiffReview struct {
+	// DiffPath is the path to the unified diff file
+	DiffPath string
+	// FilePath is the path of the reviewed file in the repository, it is required for diffs
+	// without file headers and selects one file of a diff of many files
+	FilePath string
+	// OriginalPath is the path to the content of the reviewed file before changes, optional
+	OriginalPath string
+	// Dir is the directory with files before changes, optional
+	Dir string
+}
+
+// ReviewDiff reviews the diff file with the configured agent and writes findings to out as JSON,
+// nothing is posted and the provider config is not used
+func ReviewDiff(ctx context.Context, cfg Config, review DiffReview, out io.Writer) error {
+	errs := errm.NewList()
+	if err := cfg.Agent.Validate(); err != nil {
+		errs.Wrap(err, "agent")
+	}
+	if err := cfg.Reviewer.Validate(); err != nil {
+		errs.Wrap(err, "review")
+	}
+	if err := errs.Err(); err != nil {
+		return errm.Wrap(err, "invalid config")
+	}
+
+	diffs, originals, err := review.load()
+	if err != nil {
+		return err
+	}
+	codeProvider := local.New(review.Dir, diffs, originals)
+
+	llmAgent, err := agent.New(ctx, cfg.Agent)
+	if err != nil {
+		return errm.Wrap(err, "failed to create AI agent")
+	}
+	codeReviewer, err := reviewer.New(cfg.Reviewer, codeProvider, llmAgent)
+	if err != nil {
+		return errm.Wrap(err, "failed to create review service")
+	}
+
+	findings, err := codeReviewer.ReviewChanges(ctx, model.ReviewRequest{
+		ProjectID:    localProjectID,
+		MergeRequest: codeProvider.MergeRequest(),
+		Changes:      diffs,
+	})
+	if err != nil {
+		return errm.Wrap(err, "failed to review diff")
+	}
+	if findings == nil {
+		findings = []*model.ReviewAIComment{}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(findings); err != nil {
+		return errm.Wrap(err, "failed to write findings")
+	}
+	return nil
+}
+
+// load reads the diff and the original content of the reviewed file
+func (r DiffReview) load() ([]*model.FileDiff, map[string]string, error) {
+	content, err := os.ReadFile(r.DiffPath)
+	if err != nil {
+		return nil, nil, errm.Wrap(err, "failed to read diff file")
+	}
+
+	diffs := model.ParseUnifiedDiff(string(content))
+	if r.FilePath != "" {
+		diffs, err = selectFileDiff(diffs, r.FilePath, string(content))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(diffs) == 0 {
+		return nil, nil, errm.New("no file diffs found, set path of the file for diffs without file headers")
+	}
+
+	originals := make(map[string]string)
+	if r.OriginalPath != "" {
+		if len(diffs) != 1 {
+			return nil, nil, errm.Errorf("original content is set for a diff of %d files, set path of the reviewed file", len(diffs))
+		}
+		original, err := os.ReadFile(r.OriginalPath)
+		if err != nil {
+			return nil, nil, errm.Wrap(err, "failed to read original file")
+		}
+		originals[diffs[0].OldPath] = string(original)
+	}
+
+	return diffs, originals, nil
+}
+
+// selectFileDiff returns the diff of the file, a diff without file headers and a diff of one file
+// with other paths (e.g. of diff -u of a copy) are used as the diff of the file
+func selectFileDiff(diffs []*model.FileDiff, filePath, content string) ([]*model.FileDiff, error) {
+	switch {
+	case len(diffs) == 0:
+		return []*model.FileDiff{{OldPath: filePath, NewPath: filePath, Diff: content, Hunks: model.ParseHunks(content)}}, nil
+
+	case len(diffs) == 1 && diffs[0].NewPath != filePath && diffs[0].OldPath != filePath:
+		diffs[0].OldPath, diffs[0].NewPath, diffs[0].IsRenamed = filePath, filePath, false
+		return diffs, nil
+	}
+
+	for _, diff := range diffs {
+		if diff.NewPath == filePath || diff.OldPath == filePath {
+			return []*model.FileDiff{diff}, nil
+		}
+	}
+	return nil, errm.Errorf("file %s is not found in the diff", filePath)
+}
@@ -0,0 +1,223 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// Output formats of findings of the range review
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+	FormatSARIF    = "sarif"
+)
+
+// OutputFormats are supported formats of findings of the range review
+var OutputFormats = []string{FormatJSON, FormatMarkdown, FormatSARIF}
+
+// RangeReview is a review of changes between two commits of the project outside of merge requests
+type RangeReview struct {
+	ProjectID string
+	// From is the base commit, changes are taken from its merge base with To
+	From string
+	// To is the head commit
+	To string
+	// Format is one of OutputFormats, JSON by default
+	Format string
+	// Post creates a comment of the head commit with findings in markdown
+	Post bool
+}
+
+// ReviewRange reviews the diff between commits of the range and writes findings to out in the format,
+// the provider must be able to compare commits and to comment commits for posting
+func (s *Codry) ReviewRange(ctx context.Context, review RangeReview, out io.Writer) error {
+	format, err := outputFormat(review.Format)
+	if err != nil {
+		return err
+	}
+	comparer, ok := s.provider.(interfaces.CommitComparer)
+	if !ok {
+		return errm.New("provider does not support comparing commits")
+	}
+	commenter, ok := s.provider.(interfaces.CommitCommenter)
+	if review.Post && !ok {
+		return errm.New("provider does not support commit comments")
+	}
+
+	diffs, err := comparer.CompareCommits(ctx, review.ProjectID, review.From, review.To)
+	if err != nil {
+		return errm.Wrap(err, "failed to compare commits", "from", review.From, "to", review.To)
+	}
+	s.log.Info("reviewing commit range", "project_id", review.ProjectID, "from", review.From, "to", review.To, "files", len(diffs))
+
+	// The range is reviewed as a merge request of the head commit into the base one
+	findings, err := s.reviewer.ReviewChanges(ctx, model.ReviewRequest{
+		ProjectID: review.ProjectID,
+		MergeRequest: &model.MergeRequest{
+			Title:        fmt.Sprintf("Changes %s..%s", shortSHA(review.From), shortSHA(review.To)),
+			SourceBranch: review.To,
+			TargetBranch: review.From,
+			SHA:          review.To,
+		},
+		Changes: diffs,
+	})
+	if err != nil {
+		return errm.Wrap(err, "failed to review commit range")
+	}
+
+	if review.Post && len(findings) > 0 {
+		// Positions of findings are in the diff of the range, not of the head commit, so they are posted as one comment
+		comment := &model.Comment{Body: s.reviewer.RenderFindings(findings), Type: model.CommentTypeGeneral}
+		if err := commenter.CreateCommitComment(ctx, review.ProjectID, review.To, comment); err != nil {
+			return errm.Wrap(err, "failed to post findings", "sha", review.To)
+		}
+	}
+
+	return s.writeFindings(out, format, findings)
+}
+
+// outputFormat returns the format of findings, JSON if it is empty
+func outputFormat(format string) (string, error) {
+	if format == "" {
+		return FormatJSON, nil
+	}
+	if !slices.Contains(OutputFormats, format) {
+		return "", errm.Errorf("unsupported format %q, expected one of %v", format, OutputFormats)
+	}
+	return format, nil
+}
+
+// writeFindings writes findings to out in the format
+func (s *Codry) writeFindings(out io.Writer, format string, findings []*model.ReviewAIComment) error {
+	if findings == nil {
+		findings = []*model.ReviewAIComment{}
+	}
+
+	var err error
+	switch format {
+	case FormatMarkdown:
+		_, err = io.WriteString(out, s.reviewer.RenderFindings(findings)+"\n")
+	case FormatSARIF:
+		err = writeJSON(out, newSARIFLog(findings))
+	default:
+		err = writeJSON(out, findings)
+	}
+	if err != nil {
+		return errm.Wrap(err, "failed to write findings")
+	}
+	return nil
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// sarifLog is a SARIF 2.1.0 log with the minimal set of properties read by code scanning tools
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name string `json:"name"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// newSARIFLog converts findings to SARIF results, issue types are rule IDs and priorities are levels
+func newSARIFLog(findings []*model.ReviewAIComment) sarifLog {
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		result := sarifResult{
+			RuleID:  string(finding.IssueType),
+			Level:   sarifLevel(finding.Priority),
+			Message: sarifMessage{Text: finding.Title + "\n\n" + finding.Description},
+		}
+		if finding.FilePath != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: finding.FilePath},
+			}}
+			if finding.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+				if finding.IsRangeComment() {
+					location.PhysicalLocation.Region.EndLine = finding.EndLine
+				}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		results = append(results, result)
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "codry"}},
+			Results: results,
+		}},
+	}
+}
+
+func sarifLevel(priority model.ReviewPriority) string {
+	switch priority {
+	case model.ReviewPriorityCritical, model.ReviewPriorityHigh:
+		return "error"
+	case model.ReviewPriorityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import "github.com/maxbolgarin/lang"
+
+const defaultEndpoint = "/metrics"
+
+// Config represents Prometheus metrics configuration
+type Config struct {
+	Enable bool `yaml:"enable" env:"METRICS_ENABLE"`
+	// Endpoint is the path of metrics in the webhook server
+	Endpoint string `yaml:"endpoint" env:"METRICS_ENDPOINT"`
+}
+
+func (cfg *Config) PrepareAndValidate() error {
+	cfg.Endpoint = lang.Check(cfg.Endpoint, defaultEndpoint)
+	return nil
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+var _ interfaces.MetricsRecorder = Nop{}
+
+// Nop is a recorder that drops all metrics, it is used when metrics are disabled
+type Nop struct{}
+
+func (Nop) ReviewStarted(string)                               {}
+func (Nop) ReviewFinished(string, bool, time.Duration)         {}
+func (Nop) ReviewStage(string, string, time.Duration, bool)    {}
+func (Nop) FindingsReported(string, model.ReviewPriority, int) {}
+func (Nop) LLMCall(string, time.Duration, int, int, error)     {}
+func (Nop) ProviderRequest(string, string, int, time.Duration) {}
+func (Nop) ProviderRateLimitRemaining(string, int)             {}
+func (Nop) QueueDepth(int)                                     {}
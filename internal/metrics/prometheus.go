@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+var _ interfaces.MetricsRecorder = (*Prometheus)(nil)
+
+var (
+	reviewBuckets   = []float64{5, 15, 30, 60, 120, 300, 600, 1200, 1800}
+	llmBuckets      = []float64{0.5, 1, 2, 5, 10, 20, 40, 60, 120}
+	providerBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10}
+)
+
+// Prometheus records metrics in memory and serves them in the Prometheus text format,
+// labels are limited to provider, review stage, priority, HTTP method and status class
+type Prometheus struct {
+	registry registry
+
+	reviewsStarted   *metric
+	reviewsFinished  *metric
+	reviewDuration   *metric
+	stageDuration    *metric
+	stageErrors      *metric
+	findings         *metric
+	llmRequests      *metric
+	llmDuration      *metric
+	llmTokens        *metric
+	providerRequests *metric
+	providerDuration *metric
+	providerQuota    *metric
+	queueDepth       *metric
+}
+
+// NewPrometheus creates a new Prometheus recorder
+func NewPrometheus() *Prometheus {
+	p := &Prometheus{}
+	r := &p.registry
+
+	p.reviewsStarted = r.register("codry_reviews_started_total", "Number of started merge request reviews.", kindCounter, nil, "provider")
+	p.reviewsFinished = r.register("codry_reviews_finished_total", "Number of finished merge request reviews by status.", kindCounter, nil, "provider", "status")
+	p.reviewDuration = r.register("codry_review_duration_seconds", "Duration of merge request reviews.", kindHistogram, reviewBuckets, "provider")
+	p.stageDuration = r.register("codry_review_stage_duration_seconds", "Duration of review stages.", kindHistogram, reviewBuckets, "provider", "stage")
+	p.stageErrors = r.register("codry_review_stage_errors_total", "Number of review stages finished with errors.", kindCounter, nil, "provider", "stage")
+	p.findings = r.register("codry_findings_total", "Number of reported findings by priority.", kindCounter, nil, "provider", "priority")
+	p.llmRequests = r.register("codry_llm_requests_total", "Number of model calls by review stage and status.", kindCounter, nil, "stage", "status")
+	p.llmDuration = r.register("codry_llm_request_duration_seconds", "Latency of model calls.", kindHistogram, llmBuckets, "stage")
+	p.llmTokens = r.register("codry_llm_tokens_total", "Number of model tokens by direction.", kindCounter, nil, "stage", "direction")
+	p.providerRequests = r.register("codry_provider_requests_total", "Number of provider API requests by status class.", kindCounter, nil, "provider", "method", "status")
+	p.providerDuration = r.register("codry_provider_request_duration_seconds", "Latency of provider API requests.", kindHistogram, providerBuckets, "provider", "method")
+	p.providerQuota = r.register("codry_provider_rate_limit_remaining", "Remaining API quota of the provider from rate limit headers.", kindGauge, nil, "provider")
+	p.queueDepth = r.register("codry_queue_depth", "Number of events waiting for review.", kindGauge, nil)
+
+	return p
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := p.registry.write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (p *Prometheus) ReviewStarted(provider string) {
+	p.registry.add(p.reviewsStarted, 1, provider)
+}
+
+func (p *Prometheus) ReviewFinished(provider string, success bool, duration time.Duration) {
+	status := "completed"
+	if !success {
+		status = "failed"
+	}
+	p.registry.add(p.reviewsFinished, 1, provider, status)
+	p.registry.observe(p.reviewDuration, duration.Seconds(), provider)
+}
+
+func (p *Prometheus) ReviewStage(provider, stage string, duration time.Duration, failed bool) {
+	p.registry.observe(p.stageDuration, duration.Seconds(), provider, stage)
+	if failed {
+		p.registry.add(p.stageErrors, 1, provider, stage)
+	}
+}
+
+func (p *Prometheus) FindingsReported(provider string, priority model.ReviewPriority, count int) {
+	p.registry.add(p.findings, float64(count), provider, string(priority))
+}
+
+func (p *Prometheus) LLMCall(stage string, duration time.Duration, inputTokens, outputTokens int, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	p.registry.add(p.llmRequests, 1, stage, status)
+	p.registry.observe(p.llmDuration, duration.Seconds(), stage)
+	p.registry.add(p.llmTokens, float64(inputTokens), stage, "input")
+	p.registry.add(p.llmTokens, float64(outputTokens), stage, "output")
+}
+
+func (p *Prometheus) ProviderRequest(provider, method string, status int, duration time.Duration) {
+	p.registry.add(p.providerRequests, 1, provider, method, statusClass(status))
+	p.registry.observe(p.providerDuration, duration.Seconds(), provider, method)
+}
+
+func (p *Prometheus) ProviderRateLimitRemaining(provider string, remaining int) {
+	p.registry.set(p.providerQuota, float64(remaining), provider)
+}
+
+func (p *Prometheus) QueueDepth(depth int) {
+	p.registry.set(p.queueDepth, float64(depth))
+}
+
+// statusClass returns the class of HTTP status like 2xx, zero status is an error without response
+func statusClass(status int) string {
+	if status <= 0 {
+		return "error"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
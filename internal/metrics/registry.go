@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type metricKind string
+
+const (
+	kindCounter   metricKind = "counter"
+	kindGauge     metricKind = "gauge"
+	kindHistogram metricKind = "histogram"
+)
+
+// registry keeps metrics in memory and writes them in the Prometheus text exposition format
+type registry struct {
+	mu      sync.Mutex
+	metrics []*metric
+}
+
+// metric is a family of series with the same name and label names
+type metric struct {
+	name    string
+	help    string
+	kind    metricKind
+	labels  []string
+	buckets []float64
+	series  map[string]*series
+}
+
+// series is a metric value for one combination of label values
+type series struct {
+	labelValues []string
+	value       float64  // counter and gauge value
+	counts      []uint64 // histogram bucket counts, not cumulative
+	sum         float64
+	count       uint64
+}
+
+func (r *registry) register(name, help string, kind metricKind, buckets []float64, labels ...string) *metric {
+	m := &metric{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	r.metrics = append(r.metrics, m)
+	return m
+}
+
+// add increases the counter or gauge
+func (r *registry) add(m *metric, delta float64, labelValues ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m.get(labelValues).value += delta
+}
+
+// set sets the gauge value
+func (r *registry) set(m *metric, value float64, labelValues ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m.get(labelValues).value = value
+}
+
+// observe adds the value to the histogram
+func (r *registry) observe(m *metric, value float64, labelValues ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := m.get(labelValues)
+	if i, _ := slices.BinarySearch(m.buckets, value); i < len(m.buckets) {
+		s.counts[i]++ // values above the last bound are counted only in +Inf
+	}
+	s.sum += value
+	s.count++
+}
+
+func (m *metric) get(labelValues []string) *series {
+	key := strings.Join(labelValues, "\x00")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labelValues: slices.Clone(labelValues)}
+		if m.kind == kindHistogram {
+			s.counts = make([]uint64, len(m.buckets))
+		}
+		m.series[key] = s
+	}
+	return s
+}
+
+// write writes all metrics in the text exposition format, series are sorted by label values
+func (r *registry) write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sb strings.Builder
+	for _, m := range r.metrics {
+		sb.WriteString(fmt.Sprintf("# HELP %s %s\n", m.name, m.help))
+		sb.WriteString(fmt.Sprintf("# TYPE %s %s\n", m.name, m.kind))
+
+		keys := make([]string, 0, len(m.series))
+		for key := range m.series {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			s := m.series[key]
+			if m.kind != kindHistogram {
+				sb.WriteString(m.name + formatLabels(m.labels, s.labelValues) + " " + formatValue(s.value) + "\n")
+				continue
+			}
+
+			var cumulative uint64
+			for i, bound := range m.buckets {
+				cumulative += s.counts[i]
+				sb.WriteString(m.name + "_bucket" + formatLabels(append(slices.Clone(m.labels), "le"), append(slices.Clone(s.labelValues), formatValue(bound))))
+				sb.WriteString(" " + strconv.FormatUint(cumulative, 10) + "\n")
+			}
+			sb.WriteString(m.name + "_bucket" + formatLabels(append(slices.Clone(m.labels), "le"), append(slices.Clone(s.labelValues), "+Inf")))
+			sb.WriteString(" " + strconv.FormatUint(s.count, 10) + "\n")
+			sb.WriteString(m.name + "_sum" + formatLabels(m.labels, s.labelValues) + " " + formatValue(s.sum) + "\n")
+			sb.WriteString(m.name + "_count" + formatLabels(m.labels, s.labelValues) + " " + strconv.FormatUint(s.count, 10) + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelValueReplacer.Replace(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+// transport records provider API requests
+type transport struct {
+	provider string
+	recorder interfaces.MetricsRecorder
+	base     http.RoundTripper
+}
+
+// Transport returns a round tripper that records requests of the provider API, nil base uses the default transport
+func Transport(provider string, recorder interfaces.MetricsRecorder, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{provider: provider, recorder: recorder, base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	var status int
+	if err == nil {
+		status = resp.StatusCode
+	}
+	t.recorder.ProviderRequest(t.provider, req.Method, status, time.Since(start))
+
+	return resp, err
+}
@@ -33,6 +33,7 @@ type ModelConfig struct {
 
 // APIRequest represents a request to an LLM API
 type APIRequest struct {
+	Model        string // model of the call, the configured one is used if empty
 	Prompt       string
 	SystemPrompt string
 	MaxTokens    int
@@ -48,6 +49,8 @@ type APIResponse struct {
 	PromptTokens     int
 	CompletionTokens int
 	TotalTokens      int
+	// Truncated is true if the output stopped at the token limit, so the content may be incomplete
+	Truncated bool
 }
 
 // Prompt represents a structured prompt for LLM
@@ -79,6 +82,10 @@ type ReviewAIComment struct {
 	Description  string           `json:"description"`
 	Suggestion   string           `json:"suggestion,omitempty"`
 	CodeSnippet  string           `json:"code_snippet,omitempty"`
+	// Rule is the heading of the violated project rule, if the finding cites one
+	Rule string `json:"rule,omitempty"`
+	// CommitSHA is the reviewed commit if the merge request is reviewed commit by commit
+	CommitSHA string `json:"-"`
 }
 
 // IsRangeComment returns true if this comment spans mul	tiple lines
@@ -108,6 +115,23 @@ const (
 	ConfidenceLow      ReviewConfidence = "low"
 )
 
+var reviewConfidenceLevel = abstract.NewSafeMap[ReviewConfidence, int](map[ReviewConfidence]int{
+	ConfidenceLow:      1,
+	ConfidenceMedium:   2,
+	ConfidenceHigh:     3,
+	ConfidenceVeryHigh: 4,
+})
+
+// IsValid checks if the confidence is one of the known levels
+func (rc ReviewConfidence) IsValid() bool {
+	return reviewConfidenceLevel.Get(rc) > 0
+}
+
+// IsAtLeast returns true if the confidence is as high as the threshold or higher
+func (rc ReviewConfidence) IsAtLeast(threshold ReviewConfidence) bool {
+	return reviewConfidenceLevel.Get(rc) >= reviewConfidenceLevel.Get(threshold)
+}
+
 // ReviewPriority defines the priority level of review issues by AI
 type ReviewPriority string
 
@@ -118,6 +142,23 @@ const (
 	ReviewPriorityBacklog  ReviewPriority = "backlog"
 )
 
+var reviewPrioritySeverity = abstract.NewSafeMap[ReviewPriority, int](map[ReviewPriority]int{
+	ReviewPriorityBacklog:  1,
+	ReviewPriorityMedium:   2,
+	ReviewPriorityHigh:     3,
+	ReviewPriorityCritical: 4,
+})
+
+// IsValid checks if the priority is one of the known priorities
+func (rp ReviewPriority) IsValid() bool {
+	return reviewPrioritySeverity.Get(rp) > 0
+}
+
+// IsAtLeast returns true if the priority is as severe as the threshold or more
+func (rp ReviewPriority) IsAtLeast(threshold ReviewPriority) bool {
+	return reviewPrioritySeverity.Get(rp) >= reviewPrioritySeverity.Get(threshold)
+}
+
 // FileChangeType represents the type of change in a file
 type FileChangeType string
 
@@ -156,3 +197,35 @@ type FileChangeInfo struct {
 	Type        FileChangeType `json:"type"`
 	Description string         `json:"description"`
 }
+
+// ConfigChangeKind defines how a key of a structured config file changed
+type ConfigChangeKind string
+
+const (
+	ConfigKeyAdded   ConfigChangeKind = "added"
+	ConfigKeyRemoved ConfigChangeKind = "removed"
+	ConfigKeyChanged ConfigChangeKind = "changed"
+)
+
+// ConfigKeyChange represents a changed key of a structured config file, nested keys are joined with dots
+// and elements of lists of objects are indexed like servers[0].host
+type ConfigKeyChange struct {
+	Key      string           `json:"key"`
+	Kind     ConfigChangeKind `json:"kind"`
+	OldValue string           `json:"old_value,omitempty"`
+	NewValue string           `json:"new_value,omitempty"`
+	// Line is the line of the key in the new version, 0 for removed keys and keys whose line is unknown
+	Line int `json:"line,omitempty"`
+	// Risk describes the risky value matched by a config risk rule, it is empty for regular changes
+	Risk string `json:"risk,omitempty"`
+	// AffectedFiles are code files reading the changed or removed key
+	AffectedFiles []string `json:"affected_files,omitempty"`
+}
+
+// ConfigChangeInfo represents key-level changes of a JSON, YAML or TOML file of the merge request
+type ConfigChangeInfo struct {
+	FilePath string            `json:"file"`
+	Changes  []ConfigKeyChange `json:"changes"`
+	// AffectedFiles are code files reading any of the changed or removed keys
+	AffectedFiles []string `json:"affected_files,omitempty"`
+}
@@ -1,15 +1,22 @@
 package model
 
 import (
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 )
 
 // ProviderConfig represents provider-specific configuration
 type ProviderConfig struct {
 	BaseURL       string
+	UploadURL     string // upload API URL of GitHub Enterprise, base URL is used if it is empty
 	Token         string
 	WebhookSecret string
 	BotUsername   string
+
+	// Transport wraps requests to the provider API (e.g. for metrics), default transport is used if nil
+	Transport http.RoundTripper
 }
 
 // User represents a user across different providers
@@ -19,6 +26,21 @@ type User struct {
 	Name     string
 }
 
+// IsBot checks if the user is the bot: users are compared by stable IDs if both are known,
+// otherwise by username with the resolved bot account or the configured bot username
+func (u *User) IsBot(bot *User, botUsername string) bool {
+	if u == nil {
+		return false
+	}
+	if bot != nil && bot.ID != "" && u.ID != "" {
+		return u.ID == bot.ID
+	}
+	if u.Username == "" {
+		return false
+	}
+	return (bot != nil && u.Username == bot.Username) || u.Username == botUsername
+}
+
 // MergeRequest represents a merge/pull request across different providers
 type MergeRequest struct {
 	ID           string
@@ -29,6 +51,7 @@ type MergeRequest struct {
 	TargetBranch string
 	Author       User
 	Reviewers    []User
+	Labels       []string
 	URL          string
 	State        string
 	SHA          string
@@ -38,14 +61,28 @@ type MergeRequest struct {
 
 // FileDiff represents changes in a single file
 type FileDiff struct {
-	OldPath     string
-	NewPath     string
-	Diff        string
-	IsNew       bool
-	IsDeleted   bool
-	IsRenamed   bool
-	IsBinary    bool
+	OldPath   string
+	NewPath   string
+	Diff      string
+	IsNew     bool
+	IsDeleted bool
+	IsRenamed bool
+	IsBinary  bool
+	// IsTooLarge is set when the provider returned no patch of a textual file, e.g. because it is too large to diff,
+	// the reviewer builds the diff from contents of the file when they are available
+	IsTooLarge  bool
 	ContentType string
+	// Hunks are parsed hunks of Diff set with it by providers and SetDiff, the raw Diff is kept for prompts
+	Hunks []DiffHunk
+}
+
+// Commit represents a commit of a merge request
+type Commit struct {
+	SHA       string
+	ParentSHA string // first parent, the commit diff is made against it
+	Message   string
+	Author    User
+	CreatedAt time.Time
 }
 
 // Comment represents a code review comment
@@ -55,8 +92,15 @@ type Comment struct {
 	FilePath  string
 	Line      int         // Line number in the new file (for line-specific comments)
 	OldLine   int         // Line number in the old file (for context)
+	StartLine int         // First line of a multi-line comment in the new file, 0 for a single line
+	EndLine   int         // Last line of a multi-line comment in the new file, 0 for a single line
 	Position  int         // Position in the diff (provider-specific)
 	Type      CommentType // Type of comment
+	Footer    string      // Footer appended to the body on creation, it is not a part of Body for comparison
+	ThreadID  string      // Thread of the comment if the provider groups comments in threads
+	CommitSHA string      // Commit the inline comment refers to, empty if unknown
+	Resolved  bool        // The thread of the comment is resolved
+	Outdated  bool        // The commented line was changed after the comment, set by providers that track it
 	Author    User
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -72,14 +116,122 @@ const (
 	CommentTypeSummary CommentType = "summary" // Summary comment
 )
 
+// CommentFooterMarker separates comment body from footer with run metadata
+const CommentFooterMarker = "<!-- Codry: footer -->"
+
+// FullBody returns comment body with footer that should be sent to provider
+func (c *Comment) FullBody() string {
+	if c.Footer == "" {
+		return c.Body
+	}
+	return c.Body + "\n\n" + CommentFooterMarker + "\n" + c.Footer
+}
+
+// SplitCommentFooter separates footer from comment body,
+// so comments with different run metadata have the same body
+func SplitCommentFooter(body string) (string, string) {
+	before, after, found := strings.Cut(body, CommentFooterMarker)
+	if !found {
+		return body, ""
+	}
+	return strings.TrimRight(before, "\n"), strings.TrimSpace(after)
+}
+
 // MergeRequestFilter represents criteria for filtering merge requests
 type MergeRequestFilter struct {
-	State        []string   // e.g., "open", "closed", "merged"
-	AuthorID     string     // Filter by author
-	TargetBranch string     // Filter by target branch
-	SourceBranch string     // Filter by source branch
-	UpdatedAfter *time.Time // Filter by last update time
-	CreatedAfter *time.Time // Filter by creation time
-	Limit        int        // Maximum number of results (0 = no limit)
-	Page         int        // Page number for pagination (0-based)
+	State          []string   // e.g., "open", "closed", "merged"
+	AuthorID       string     // Filter by author
+	Authors        []string   // Filter by any of the authors (username or ID)
+	TargetBranch   string     // Filter by target branch
+	TargetBranches []string   // Filter by any of the target branches
+	SourceBranch   string     // Filter by source branch
+	Labels         []string   // Filter by labels, all of them must be set
+	UpdatedAfter   *time.Time // Filter by last update time
+	CreatedAfter   *time.Time // Filter by creation time
+	Limit          int        // Maximum number of results (0 = no limit)
+	Page           int        // Page number for pagination (0-based)
+}
+
+// Matches checks merge request against author, branch, label and time criteria of the filter,
+// providers support different subsets of them server-side, so the filter is applied again after fetch
+func (f *MergeRequestFilter) Matches(mr *MergeRequest) bool {
+	if f.AuthorID != "" && mr.Author.ID != f.AuthorID {
+		return false
+	}
+	if len(f.Authors) > 0 && !slices.Contains(f.Authors, mr.Author.Username) && !slices.Contains(f.Authors, mr.Author.ID) {
+		return false
+	}
+	if f.TargetBranch != "" && mr.TargetBranch != f.TargetBranch {
+		return false
+	}
+	if len(f.TargetBranches) > 0 && !slices.Contains(f.TargetBranches, mr.TargetBranch) {
+		return false
+	}
+	if f.SourceBranch != "" && mr.SourceBranch != f.SourceBranch {
+		return false
+	}
+	for _, label := range f.Labels {
+		if !mr.HasLabel(label) {
+			return false
+		}
+	}
+	if f.UpdatedAfter != nil && mr.UpdatedAt.Before(*f.UpdatedAfter) {
+		return false
+	}
+	if f.CreatedAfter != nil && mr.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	return true
+}
+
+// HasLabel checks if the merge request has the label, case-insensitive
+func (mr *MergeRequest) HasLabel(label string) bool {
+	return slices.ContainsFunc(mr.Labels, func(l string) bool {
+		return strings.EqualFold(l, label)
+	})
 }
+
+// CheckRun represents a status check attached to a commit (e.g. GitHub check run)
+type CheckRun struct {
+	ID         int64 // Provider ID of the check run (0 = not created yet)
+	Name       string
+	HeadSHA    string
+	Status     CheckRunStatus
+	Conclusion CheckRunConclusion // Required when status is completed
+	Title      string
+	Summary    string
+	DetailsURL string
+}
+
+// CheckRunStatus defines the state of a check run
+type CheckRunStatus string
+
+const (
+	CheckRunStatusQueued     CheckRunStatus = "queued"
+	CheckRunStatusInProgress CheckRunStatus = "in_progress"
+	CheckRunStatusCompleted  CheckRunStatus = "completed"
+)
+
+// CheckRunConclusion defines the final result of a completed check run
+type CheckRunConclusion string
+
+const (
+	CheckRunConclusionSuccess CheckRunConclusion = "success"
+	CheckRunConclusionNeutral CheckRunConclusion = "neutral"
+	CheckRunConclusionFailure CheckRunConclusion = "failure"
+)
+
+// ReviewSubmission represents the overall verdict of a review (e.g. GitHub pull request review)
+type ReviewSubmission struct {
+	CommitSHA string
+	Event     ReviewEvent
+	Body      string
+}
+
+// ReviewEvent defines the verdict of a submitted review
+type ReviewEvent string
+
+const (
+	ReviewEventComment        ReviewEvent = "COMMENT"
+	ReviewEventRequestChanges ReviewEvent = "REQUEST_CHANGES"
+)
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxbolgarin/errm"
+)
+
+// ErrBinaryContent is returned for file content that is not UTF-8 text, such files are skipped by analysis
+var ErrBinaryContent = errm.New("file content is binary or not UTF-8")
+
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 files
+const utf8BOM = "\ufeff"
+
+// FileContent is content of a file prepared for analysis, the raw content is kept for display
+type FileContent struct {
+	Raw string
+	// Text is the content without BOM and with LF line endings, line numbers are the same as in Raw
+	Text   string
+	BOM    bool // the raw content starts with UTF-8 BOM
+	CRLF   bool // the raw content uses CRLF line endings
+	Binary bool // the raw content has NUL bytes or is not valid UTF-8, Text is empty
+}
+
+// NormalizeContent prepares content returned by a provider for parsing and line matching:
+// BOM breaks go/parser and CR at line ends breaks comparison with diff lines
+func NormalizeContent(raw string) FileContent {
+	content := FileContent{Raw: raw}
+	if strings.IndexByte(raw, 0) >= 0 || !utf8.ValidString(raw) {
+		content.Binary = true
+		return content
+	}
+
+	text, bom := strings.CutPrefix(raw, utf8BOM)
+	content.BOM = bom
+	if strings.Contains(text, "\r\n") {
+		content.CRLF = true
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	content.Text = text
+
+	return content
+}
+
+// AnalysisText returns normalized text of the content, it returns ErrBinaryContent for binary content
+func (c FileContent) AnalysisText() (string, error) {
+	if c.Binary {
+		return "", ErrBinaryContent
+	}
+	return c.Text, nil
+}
@@ -0,0 +1,263 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ParseUnifiedDiff splits unified diff of many files like output of git diff or diff -u into FileDiff objects,
+// diff of every file includes its headers; new, deleted and renamed files are detected by paths
+func ParseUnifiedDiff(diffContent string) []*FileDiff {
+	var diffs []*FileDiff
+	lines := strings.Split(diffContent, "\n")
+
+	// Files of diff -u output start with --- and +++ headers instead of diff --git
+	gitHeaders := strings.HasPrefix(diffContent, "diff --git") || strings.Contains(diffContent, "\ndiff --git")
+
+	var currentDiff *FileDiff
+	var diffLines []string
+	inHunks := false
+
+	saveDiff := func() {
+		if currentDiff != nil {
+			currentDiff.SetDiff(strings.Join(diffLines, "\n"))
+			diffs = append(diffs, currentDiff)
+		}
+	}
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			saveDiff()
+			currentDiff = &FileDiff{}
+			diffLines = []string{line}
+			inHunks = false
+
+		case !gitHeaders && strings.HasPrefix(line, "--- ") && (currentDiff == nil || inHunks) &&
+			i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			saveDiff()
+			currentDiff = &FileDiff{}
+			diffLines = nil
+			inHunks = false
+			fallthrough
+
+		case strings.HasPrefix(line, "--- ") && currentDiff != nil && !inHunks:
+			// Old file path
+			if strings.Contains(line, "/dev/null") {
+				currentDiff.IsNew = true
+			} else if path := diffHeaderPath(line, "--- ", "a/"); path != "" {
+				currentDiff.OldPath = path
+			}
+			diffLines = append(diffLines, line)
+
+		case strings.HasPrefix(line, "+++ ") && currentDiff != nil && !inHunks:
+			// New file path
+			if strings.Contains(line, "/dev/null") {
+				currentDiff.IsDeleted = true
+			} else if path := diffHeaderPath(line, "+++ ", "b/"); path != "" {
+				currentDiff.NewPath = path
+			}
+			diffLines = append(diffLines, line)
+
+		case currentDiff != nil:
+			if strings.HasPrefix(line, "@@") {
+				inHunks = true
+			}
+			// Binary files have no hunks, git prints a marker or a binary patch instead
+			if !inHunks && (strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch") {
+				currentDiff.IsBinary = true
+			}
+			diffLines = append(diffLines, line)
+		}
+	}
+	saveDiff()
+
+	// Set default paths and detect renames
+	for _, diff := range diffs {
+		if diff.NewPath == "" && diff.OldPath != "" {
+			diff.NewPath = diff.OldPath
+		}
+		if diff.OldPath == "" && diff.NewPath != "" {
+			diff.OldPath = diff.NewPath
+		}
+		if diff.OldPath != "" && diff.NewPath != "" && diff.OldPath != diff.NewPath {
+			diff.IsRenamed = true
+		}
+	}
+
+	return diffs
+}
+
+// diffHeaderPath returns the path of the --- or +++ header line without the a/ or b/ prefix of git
+// and the timestamp of diff -u
+func diffHeaderPath(line, header, gitPrefix string) string {
+	path := strings.TrimPrefix(line, header)
+	if i := strings.IndexByte(path, '\t'); i >= 0 {
+		path = path[:i]
+	}
+	return strings.TrimPrefix(strings.TrimSpace(path), gitPrefix)
+}
+
+// binaryExtensions are extensions of files that are binary regardless of their content
+var binaryExtensions = []string{
+	".png", ".jpg", ".jpeg", ".gif", ".bmp", ".ico", ".webp", ".pdf", ".zip", ".gz", ".tgz", ".tar", ".7z", ".rar",
+	".jar", ".war", ".class", ".pyc", ".exe", ".dll", ".so", ".dylib", ".a", ".o", ".bin", ".wasm",
+	".woff", ".woff2", ".ttf", ".otf", ".eot", ".mp3", ".mp4", ".mov", ".avi", ".wav", ".sqlite", ".db",
+}
+
+// IsBinaryPath checks if the file is binary by its extension, it is used when the provider returns no patch
+// and doesn't tell why
+func IsBinaryPath(filePath string) bool {
+	lower := strings.ToLower(filePath)
+	for _, ext := range binaryExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+const diffContextLines = 3
+
+// BuildUnifiedDiff returns hunks of the unified diff between two versions of a file without file headers,
+// like patches of providers; ok is false if there are more than maxEdits changed lines, so huge rewrites
+// don't take long to diff
+func BuildUnifiedDiff(before, after string, maxEdits int) (string, bool) {
+	oldLines, newLines := splitContentLines(before), splitContentLines(after)
+	edits, ok := diffLines(oldLines, newLines, maxEdits)
+	if !ok {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for start := 0; start < len(edits); {
+		// A hunk is a run of changes with context lines around, changes closer than two contexts are merged
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		last := first
+		for i := first; i < len(edits); i++ {
+			if edits[i].op == ' ' {
+				if i-last > 2*diffContextLines {
+					break
+				}
+				continue
+			}
+			last = i
+		}
+		from, to := max(first-diffContextLines, start), min(last+diffContextLines+1, len(edits))
+
+		oldStart, newStart := edits[from].oldLine, edits[from].newLine
+		var oldCount, newCount int
+		for _, edit := range edits[from:to] {
+			if edit.op != '+' {
+				oldCount++
+			}
+			if edit.op != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart-- // git points to the line before an insertion into an empty range
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, edit := range edits[from:to] {
+			sb.WriteByte(edit.op)
+			sb.WriteString(edit.text)
+			sb.WriteByte('\n')
+		}
+		start = to
+	}
+
+	return sb.String(), true
+}
+
+// lineEdit is a line of the diff: ' ' is kept, '-' is removed, '+' is added;
+// oldLine and newLine are 1-based numbers of the line in both versions, the next line for missing ones
+type lineEdit struct {
+	op      byte
+	text    string
+	oldLine int
+	newLine int
+}
+
+// diffLines returns the shortest edit script between two versions by the Myers algorithm,
+// ok is false if it has more than maxEdits removed and added lines
+func diffLines(a, b []string, maxEdits int) ([]lineEdit, bool) {
+	n, m := len(a), len(b)
+	maxEdits = min(maxEdits, n+m)
+
+	// trace[d] is the furthest x on diagonals -d..d after d edits, indexed by k+d
+	var trace [][]int
+	v := make([]int, 2*maxEdits+3)
+	offset := maxEdits + 1
+	for d := 0; d <= maxEdits; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down: a line of b is added
+			} else {
+				x = v[offset+k-1] + 1 // right: a line of a is removed
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+				return backtrackEdits(a, b, trace), true
+			}
+		}
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+	}
+	return nil, false
+}
+
+// backtrackEdits restores the edit script from the trace of diffLines
+func backtrackEdits(a, b []string, trace [][]int) []lineEdit {
+	var reversed []lineEdit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			reversed = append(reversed, lineEdit{op: ' ', text: a[x], oldLine: x + 1, newLine: y + 1})
+		}
+		if x == prevX {
+			reversed = append(reversed, lineEdit{op: '+', text: b[prevY], oldLine: x + 1, newLine: prevY + 1})
+		} else {
+			reversed = append(reversed, lineEdit{op: '-', text: a[prevX], oldLine: prevX + 1, newLine: y + 1})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		reversed = append(reversed, lineEdit{op: ' ', text: a[x], oldLine: x + 1, newLine: y + 1})
+	}
+	slices.Reverse(reversed)
+	return reversed
+}
+
+// splitContentLines splits the file content into lines, the final newline doesn't start a new line
+func splitContentLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// numberedContent returns the content of lines with their numbers, replaced lines get other text
+func numberedContent(count int, replaced map[int]string) string {
+	var sb strings.Builder
+	for i := 1; i <= count; i++ {
+		if text, ok := replaced[i]; ok {
+			sb.WriteString(text + "\n")
+			continue
+		}
+		fmt.Fprintf(&sb, "%d\n", i)
+	}
+	return sb.String()
+}
+
+func TestBuildUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   string
+		after    string
+		maxEdits int
+		want     string
+		wantOK   bool
+	}{
+		{
+			name:     "changed line",
+			before:   numberedContent(10, nil),
+			after:    numberedContent(10, map[int]string{5: "five"}),
+			maxEdits: 100,
+			want:     "@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+			wantOK:   true,
+		},
+		{
+			name:     "distant changes",
+			before:   numberedContent(20, nil),
+			after:    numberedContent(20, map[int]string{2: "two", 18: "eighteen"}),
+			maxEdits: 100,
+			want: "@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n",
+			wantOK: true,
+		},
+		{
+			name:     "new file",
+			after:    "a\nb\n",
+			maxEdits: 100,
+			want:     "@@ -0,0 +1,2 @@\n+a\n+b\n",
+			wantOK:   true,
+		},
+		{
+			name:     "emptied file",
+			before:   "a\n",
+			maxEdits: 100,
+			want:     "@@ -1,1 +0,0 @@\n-a\n",
+			wantOK:   true,
+		},
+		{
+			name:     "same content",
+			before:   "a\nb\n",
+			after:    "a\nb\n",
+			maxEdits: 100,
+			wantOK:   true,
+		},
+		{
+			name:     "too many edits",
+			before:   "a\nb\nc\n",
+			after:    "x\ny\nz\n",
+			maxEdits: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := BuildUnifiedDiff(tt.before, tt.after, tt.maxEdits)
+			if ok != tt.wantOK {
+				t.Fatalf("BuildUnifiedDiff() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("BuildUnifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildUnifiedDiffParses(t *testing.T) {
+	// A built diff has the line numbers of the new version like patches of providers
+	diff, ok := BuildUnifiedDiff(numberedContent(30, nil), numberedContent(30, map[int]string{7: "seven", 25: "twenty-five"}), 100)
+	if !ok {
+		t.Fatal("BuildUnifiedDiff() ok = false")
+	}
+	var added []int
+	for _, hunk := range ParseHunks(diff) {
+		for _, line := range hunk.Lines {
+			if line.Kind == DiffLineAdded {
+				added = append(added, line.NewLine)
+			}
+		}
+	}
+	if want := []int{7, 25}; !slices.Equal(added, want) {
+		t.Errorf("added lines = %v, want %v", added, want)
+	}
+}
+
+func TestParseUnifiedDiffBinary(t *testing.T) {
+	tests := []struct {
+		name       string
+		diff       string
+		wantBinary bool
+	}{
+		{"binary marker", "diff --git a/logo.png b/logo.png\nindex 1..2 100644\nBinary files a/logo.png and b/logo.png differ\n", true},
+		{"binary patch", "diff --git a/app.bin b/app.bin\nindex 1..2 100644\nGIT binary patch\nliteral 4\n", true},
+		{"text with the marker in a line", "diff --git a/a.txt b/a.txt\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-a\n+Binary files differ\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := ParseUnifiedDiff(tt.diff)
+			if len(diffs) != 1 {
+				t.Fatalf("ParseUnifiedDiff() = %d diffs, want 1", len(diffs))
+			}
+			if diffs[0].IsBinary != tt.wantBinary {
+				t.Errorf("IsBinary = %v, want %v", diffs[0].IsBinary, tt.wantBinary)
+			}
+		})
+	}
+}
+
+func TestIsBinaryPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"assets/logo.PNG", true},
+		{"dist/archive.tar.gz", true},
+		{"data/app.sqlite", true},
+		{"main.go", false},
+		{"docs/notes.md", false},
+		{"Makefile", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := IsBinaryPath(tt.path); got != tt.want {
+				t.Errorf("IsBinaryPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"net/http"
+
+	"github.com/maxbolgarin/errm"
+)
+
+// ErrInvalidCredentials is returned by providers when the token is rejected,
+// it is not retriable unlike network errors
+var ErrInvalidCredentials = errm.New("invalid provider credentials")
+
+// ErrNotSupported is returned by optional provider operations that are not available for the requested object
+var ErrNotSupported = errm.New("not supported")
+
+// ErrIgnoredEvent is returned by ParseWebhookEvent for payloads that are not merge request events,
+// e.g. pings, app installations and labels of issues; such webhooks are accepted and skipped
+var ErrIgnoredEvent = errm.New("ignored event")
+
+// Classes of failed provider API requests, providers return them wrapped in ProviderError,
+// so callers can branch with errors.Is or errm.Is
+var (
+	ErrNotFound     = errm.New("not found")
+	ErrRateLimited  = errm.New("rate limited")
+	ErrUnauthorized = errm.New("unauthorized")
+	ErrForbidden    = errm.New("forbidden")
+)
+
+// ProviderError is a failed provider API request, it matches its class and unwraps to the original error
+type ProviderError struct {
+	Class  error // one of ErrNotFound, ErrRateLimited, ErrUnauthorized, ErrForbidden
+	Status int   // HTTP status of the response
+	Err    error
+}
+
+// NewProviderError classifies err by the HTTP status of the response,
+// err is returned as is if it is nil or the status has no class
+func NewProviderError(status int, err error) error {
+	class := ErrorClass(status)
+	if err == nil || class == nil {
+		return err
+	}
+	return &ProviderError{Class: class, Status: status, Err: err}
+}
+
+// ErrorClass returns the class of the HTTP status, nil if the status has no class
+func ErrorClass(status int) error {
+	switch status {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	default:
+		return nil
+	}
+}
+
+func (e *ProviderError) Error() string {
+	return e.Class.Error() + ": " + e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the class of the error
+func (e *ProviderError) Is(target error) bool {
+	return errm.Is(e.Class, target)
+}
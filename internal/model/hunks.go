@@ -0,0 +1,169 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiffLineKind is the kind of a line of a diff hunk
+type DiffLineKind string
+
+const (
+	DiffLineContext DiffLineKind = "context"
+	DiffLineAdded   DiffLineKind = "added"
+	DiffLineRemoved DiffLineKind = "removed"
+)
+
+// DiffLine is a line of a diff hunk without its prefix, removed lines have no new line number
+// and added lines have no old one
+type DiffLine struct {
+	Kind    DiffLineKind
+	Content string
+	OldLine int
+	NewLine int
+	// Position is the number of the line in the diff starting from 1, some providers anchor comments by it
+	Position int
+	// NoNewline is true if the line is the last one of its file version and has no newline at the end
+	NoNewline bool
+}
+
+// DiffHunk is a parsed hunk of a unified diff, counts are taken from its header
+type DiffHunk struct {
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+	// Section is the text after the header, e.g. the function containing the hunk
+	Section string
+	// Position is the number of the header line in the diff starting from 1
+	Position int
+	Lines    []DiffLine
+}
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// ParseHunks parses hunks of the unified diff of one file, file headers are skipped and lines like "\ No newline
+// at end of file" mark the previous line; lines after the counts of the hunk header are exhausted are ignored,
+// so a trailing newline of the diff doesn't become a context line
+func ParseHunks(diff string) []DiffHunk {
+	var (
+		hunks            []DiffHunk
+		oldLine, newLine int
+		oldLeft, newLeft int
+	)
+	for i, line := range strings.Split(diff, "\n") {
+		if match := hunkHeaderRegex.FindStringSubmatch(line); match != nil {
+			hunk := DiffHunk{
+				OldStart: atoi(match[1]),
+				OldCount: countOrOne(match[2]),
+				NewStart: atoi(match[3]),
+				NewCount: countOrOne(match[4]),
+				Section:  match[5],
+				Position: i + 1,
+			}
+			hunks = append(hunks, hunk)
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+			oldLeft, newLeft = hunk.OldCount, hunk.NewCount
+			continue
+		}
+		if len(hunks) == 0 {
+			continue
+		}
+		hunk := &hunks[len(hunks)-1]
+		if strings.HasPrefix(line, `\`) {
+			if len(hunk.Lines) > 0 {
+				hunk.Lines[len(hunk.Lines)-1].NoNewline = true
+			}
+			continue
+		}
+		if oldLeft <= 0 && newLeft <= 0 {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+"):
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffLineAdded, Content: line[1:], NewLine: newLine, Position: i + 1})
+			newLine++
+			newLeft--
+		case strings.HasPrefix(line, "-"):
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffLineRemoved, Content: line[1:], OldLine: oldLine, Position: i + 1})
+			oldLine++
+			oldLeft--
+		default:
+			// Some tools strip the space prefix of empty context lines
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffLineContext, Content: strings.TrimPrefix(line, " "), OldLine: oldLine, NewLine: newLine, Position: i + 1})
+			oldLine++
+			newLine++
+			oldLeft--
+			newLeft--
+		}
+	}
+	return hunks
+}
+
+// SetDiff sets the raw diff of the file and its parsed hunks
+func (f *FileDiff) SetDiff(diff string) {
+	f.Diff = diff
+	f.Hunks = ParseHunks(diff)
+}
+
+// DiffHunks returns parsed hunks of the diff, they are parsed on the first call if the file
+// was constructed without them
+func (f *FileDiff) DiffHunks() []DiffHunk {
+	if f.Hunks == nil && f.Diff != "" {
+		f.Hunks = ParseHunks(f.Diff)
+	}
+	return f.Hunks
+}
+
+// AddedLines returns added lines of all hunks in the order of the diff
+func (f *FileDiff) AddedLines() []DiffLine {
+	var lines []DiffLine
+	for _, hunk := range f.DiffHunks() {
+		for _, line := range hunk.Lines {
+			if line.Kind == DiffLineAdded {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// ChangedLines returns the number of added and removed lines
+func (f *FileDiff) ChangedLines() int {
+	var count int
+	for _, hunk := range f.DiffHunks() {
+		for _, line := range hunk.Lines {
+			if line.Kind != DiffLineContext {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// ClampLineRange returns the range of new lines cut to the end of the hunk containing its start, so comments
+// don't span unchanged gaps between hunks; ok is false if the start is out of hunks
+func (f *FileDiff) ClampLineRange(start, end int) (int, int, bool) {
+	for _, hunk := range f.DiffHunks() {
+		last := hunk.NewStart + hunk.NewCount - 1
+		if start >= hunk.NewStart && start <= last {
+			return start, max(start, min(end, last)), true
+		}
+	}
+	return start, start, false
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// countOrOne returns the count of the hunk header, it is omitted for one line
+func countOrOne(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
@@ -0,0 +1,36 @@
+package model
+
+import "testing"
+
+func TestClampLineRange(t *testing.T) {
+	// New lines 1-4 and 11-14 are in hunks, lines 5-10 are an unchanged gap
+	const diff = "@@ -1,3 +1,4 @@\n a\n+b\n c\n d\n@@ -10,3 +11,4 @@\n e\n+f\n g\n h\n"
+
+	tests := []struct {
+		name       string
+		start, end int
+		wantStart  int
+		wantEnd    int
+		wantOK     bool
+	}{
+		{"range within a hunk", 1, 3, 1, 3, true},
+		{"range of the second hunk", 11, 14, 11, 14, true},
+		{"range crossing the gap", 2, 12, 2, 4, true},
+		{"range past the last hunk", 13, 30, 13, 14, true},
+		{"single line", 2, 2, 2, 2, true},
+		{"end before start", 3, 1, 3, 3, true},
+		{"start in the gap", 6, 12, 6, 6, false},
+		{"start after hunks", 20, 22, 20, 20, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := &FileDiff{Diff: diff}
+			start, end, ok := file.ClampLineRange(tt.start, tt.end)
+			if start != tt.wantStart || end != tt.wantEnd || ok != tt.wantOK {
+				t.Errorf("ClampLineRange(%d, %d) = %d, %d, %v, want %d, %d, %v",
+					tt.start, tt.end, start, end, ok, tt.wantStart, tt.wantEnd, tt.wantOK)
+			}
+		})
+	}
+}
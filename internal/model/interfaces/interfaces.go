@@ -7,8 +7,14 @@ import (
 	"github.com/maxbolgarin/codry/internal/model"
 )
 
-// CodeProvider defines the interface for different VCS providers (GitLab, GitHub, etc.)
+// CodeProvider defines the interface for different VCS providers (GitLab, GitHub, etc.),
+// failed API requests return errors matching model.ErrNotFound, model.ErrRateLimited,
+// model.ErrUnauthorized or model.ErrForbidden if the provider can classify them
 type CodeProvider interface {
+	// ValidateCredentials checks that the configured token is accepted by the provider,
+	// returns error wrapping model.ErrInvalidCredentials if authentication failed
+	ValidateCredentials(ctx context.Context) error
+
 	// Webhook handling
 	ValidateWebhook(payload []byte, authToken string) error
 	ParseWebhookEvent(payload []byte) (*model.CodeEvent, error)
@@ -32,7 +38,139 @@ type CodeProvider interface {
 	GetFileContent(ctx context.Context, projectID, filePath, commitSHA string) (string, error)
 }
 
+// BotIdentifier is implemented by providers that resolve the account of the token when credentials are checked,
+// it is used to recognize comments of the bot by author
+type BotIdentifier interface {
+	// BotUser returns the account of the token, ok is false if it is not resolved yet
+	BotUser() (model.User, bool)
+}
+
+// CheckRunReporter is implemented by providers that support commit status checks (e.g. GitHub Checks API)
+type CheckRunReporter interface {
+	// CreateOrUpdateCheckRun creates a check run if its ID is zero or updates the existing one, returns the check run ID
+	CreateOrUpdateCheckRun(ctx context.Context, projectID string, checkRun *model.CheckRun) (int64, error)
+}
+
+// ReviewSubmitter is implemented by providers that support review verdicts (e.g. GitHub pull request reviews)
+type ReviewSubmitter interface {
+	// SubmitReview submits the review with the verdict event and summary body
+	SubmitReview(ctx context.Context, projectID string, mrIID int, review *model.ReviewSubmission) error
+}
+
+// DocumentPublisher is implemented by providers that can host markdown documents (e.g. GitHub gists)
+type DocumentPublisher interface {
+	// PublishDocument publishes the markdown document and returns its URL
+	PublishDocument(ctx context.Context, title, content string) (string, error)
+}
+
+// CommitComparer is implemented by providers that can compare commits, it is used to review
+// stacked merge requests against the head of their base merge request
+type CommitComparer interface {
+	// GetMergeBase returns the SHA of the best common ancestor of two commits
+	GetMergeBase(ctx context.Context, projectID, first, second string) (string, error)
+	// CompareCommits returns file diffs of the head commit against the base one
+	CompareCommits(ctx context.Context, projectID, base, head string) ([]*model.FileDiff, error)
+}
+
+// MergeResultReader is implemented by providers that keep the result of merging a merge request into its target
+// branch (e.g. GitHub merge refs), it is used to review changes as they will be merged with the current target
+type MergeResultReader interface {
+	// GetMergeResultDiffs returns file diffs of the merge result of the merge request with the head commit
+	// against the target branch, returns error matching model.ErrNotFound if there is no merge result
+	// for the head, e.g. because of conflicts
+	GetMergeResultDiffs(ctx context.Context, projectID string, mrIID int, headSHA string) ([]*model.FileDiff, error)
+}
+
+// CommitCommenter is implemented by providers that can comment commits, it is used to post reviews
+// of commits and commit ranges outside of merge requests
+type CommitCommenter interface {
+	// CreateCommitComment creates a comment of the commit, an inline comment is placed at its file and line
+	// (at its Position in the commit diff for GitHub); returns error matching model.ErrNotSupported
+	// if the inline comment can't be placed
+	CreateCommitComment(ctx context.Context, projectID, sha string, comment *model.Comment) error
+}
+
+// CommitReader is implemented by providers that can list commits of a merge request with their diffs,
+// it is used to review a merge request commit by commit
+type CommitReader interface {
+	// GetMergeRequestCommits returns commits of the merge request from the oldest
+	GetMergeRequestCommits(ctx context.Context, projectID string, mrIID int) ([]*model.Commit, error)
+	// GetCommitDiffs returns file diffs of the commit against its first parent
+	GetCommitDiffs(ctx context.Context, projectID, sha string) ([]*model.FileDiff, error)
+	// GetCommit returns the commit with its first parent
+	GetCommit(ctx context.Context, projectID, sha string) (*model.Commit, error)
+}
+
+// FileLister is implemented by providers that can list files of a repository directory,
+// it is used to find unchanged callers of changed code
+type FileLister interface {
+	// ListFiles returns paths of files directly in the directory at the ref, subdirectories are not listed
+	ListFiles(ctx context.Context, projectID, dir, ref string) ([]string, error)
+}
+
+// CommentResolver is implemented by providers that can resolve comment threads (GitLab discussions,
+// GitHub review threads), it is used to resolve findings addressed by the author on re-review;
+// providers fill Comment.Resolved from GetComments where they know it
+type CommentResolver interface {
+	// ResolveComment resolves the thread of the comment, returns error matching model.ErrNotSupported
+	// if the comment is not in a resolvable thread
+	ResolveComment(ctx context.Context, projectID string, mrIID int, comment *model.Comment) error
+}
+
+// CommentRelocator is implemented by providers that can move an inline comment to another line of its file,
+// providers without it get a new comment at the line instead
+type CommentRelocator interface {
+	// RelocateComment moves the inline comment to the line of the new file at the head of the merge request,
+	// returns error matching model.ErrNotSupported if the comment can't be moved
+	RelocateComment(ctx context.Context, projectID string, mrIID int, comment *model.Comment, line int) error
+}
+
+// LabelManager is implemented by providers that can change labels of a merge request (GitHub, GitLab),
+// it is used to reflect the review outcome in labels for automation gating on them
+type LabelManager interface {
+	// AddLabel adds the label to the merge request, the provider creates the label in the project if needed
+	AddLabel(ctx context.Context, projectID string, mrIID int, label string) error
+	// RemoveLabel removes the label from the merge request, it succeeds if the merge request doesn't have it
+	RemoveLabel(ctx context.Context, projectID string, mrIID int, label string) error
+}
+
+// FindingProcessor is a deterministic analyzer that adds, modifies or suppresses review findings of a file
+// before they are counted and posted, processors run in order of registration and each one gets
+// findings returned by the previous one
+type FindingProcessor interface {
+	Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error)
+}
+
+// MetricsRecorder collects service metrics, labels must have bounded cardinality,
+// so projects and merge requests are never passed to it
+type MetricsRecorder interface {
+	// ReviewStarted counts a started merge request review
+	ReviewStarted(provider string)
+	// ReviewFinished counts a finished review and observes its duration
+	ReviewFinished(provider string, success bool, duration time.Duration)
+	// ReviewStage observes duration of the review stage, failed is true if the stage produced errors
+	ReviewStage(provider, stage string, duration time.Duration, failed bool)
+	// FindingsReported counts findings of the priority
+	FindingsReported(provider string, priority model.ReviewPriority, count int)
+	// LLMCall observes a model call of the review stage with its token usage
+	LLMCall(stage string, duration time.Duration, inputTokens, outputTokens int, err error)
+	// ProviderRequest observes a provider API request, zero status means the request failed without response
+	ProviderRequest(provider, method string, status int, duration time.Duration)
+	// ProviderRateLimitRemaining sets the remaining API quota of the provider from its rate limit headers
+	ProviderRateLimitRemaining(provider string, remaining int)
+	// QueueDepth sets the number of events waiting for review
+	QueueDepth(depth int)
+}
+
+// Notifier sends summaries of finished reviews outside of the provider, e.g. to chats of the team
+type Notifier interface {
+	// NotifyReviewComplete sends the report of the review, the review is not failed by its error
+	NotifyReviewComplete(ctx context.Context, report model.ReviewReport) error
+}
+
 // AgentAPI defines the interface for calling LLM AI models
 type AgentAPI interface {
 	CallAPI(ctx context.Context, req model.APIRequest) (model.APIResponse, error)
+	// ValidateModel checks that the model is available in the API, empty name checks the configured model
+	ValidateModel(ctx context.Context, name string) error
 }
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"context"
+
+	"github.com/maxbolgarin/logze/v2"
+)
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns the context of the review with its correlation ID, components log it with their lines
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID of the review from the context, empty outside of reviews
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// ContextLogger returns the logger with the correlation ID of the context, so lines of every component
+// of one review can be found together; the logger is returned as is outside of reviews
+func ContextLogger(ctx context.Context, log logze.Logger) logze.Logger {
+	if id := CorrelationID(ctx); id != "" {
+		return log.WithFields("review_id", id)
+	}
+	return log
+}
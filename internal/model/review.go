@@ -5,6 +5,14 @@ import (
 	"time"
 )
 
+// AnalysisVersion is the version of deterministic analysis of findings, it must be changed with processors
+// and analyzers, so results cached with another logic are stale
+const AnalysisVersion = "1"
+
+// ActionReadyForReview is the action of the event of a draft merge request marked as ready for review,
+// providers with other names of the transition use it instead, so it gets a full review
+const ActionReadyForReview = "ready_for_review"
+
 // CodeEvent represents a webhook event from any provider
 type CodeEvent struct {
 	Type         string
@@ -16,27 +24,99 @@ type CodeEvent struct {
 	Timestamp    time.Time
 }
 
+// Username returns the username of the user who triggered the event, empty if it is unknown
+func (e *CodeEvent) Username() string {
+	if e == nil || e.User == nil {
+		return ""
+	}
+	return e.User.Username
+}
+
+// DiffSource defines where diffs of a merge request are taken from
+type DiffSource string
+
+const (
+	DiffSourceMergeRequest DiffSource = "merge_request" // diff of the source branch against the merge base
+	DiffSourceMergeResult  DiffSource = "merge_result"  // diff of the result of the merge against the target branch
+)
+
 // ReviewRequest represents a code review request
 type ReviewRequest struct {
 	ProjectID    string
 	MergeRequest *MergeRequest
 	Changes      []*FileDiff
+	// BaseMergeRequest is the open merge request this one is stacked on, its changes are excluded from Changes
+	BaseMergeRequest *MergeRequest
+	// DiffSource is the source of Changes, empty means the diff of the merge request
+	DiffSource DiffSource
 }
 
 // ReviewResult represents the result of a code review process
 type ReviewResult struct {
+	// CorrelationID is the ID of the review in logs of all components
+	CorrelationID string
+
 	ProcessedFiles  int
 	CommentsCreated int
 
+	FindingsByPriority map[ReviewPriority]int
+
 	IsSuccess                   bool
 	IsDescriptionCreated        bool
 	IsChangesOverviewCreated    bool
 	IsArchitectureReviewCreated bool
 	IsCodeReviewCreated         bool
 
+	// OutOfScopeFiles are files out of the ownership scope of the instance, their findings are left to other instances
+	OutOfScopeFiles []string
+	// SuppressedFindings is the number of findings removed by inline suppression comments in the reviewed files
+	SuppressedFindings int
+	// LowConfidenceFindings is the number of findings dropped because their confidence is below the minimum
+	LowConfidenceFindings int
+
+	// TimedOutStages are review stages that were stopped by their timeout or the timeout of the review
+	TimedOutStages []string
+
 	Errors []error
 }
 
+// ReviewReport is the summary of a finished merge request review sent to notifiers
+type ReviewReport struct {
+	CorrelationID string
+	ProjectID     string
+	MergeRequest  *MergeRequest
+
+	ProcessedFiles     int
+	CommentsCreated    int
+	FindingsByPriority map[ReviewPriority]int
+	// Findings are all findings of the review, notifiers pick the most important ones
+	Findings []*ReviewAIComment
+	// TimedOutStages are review stages that were stopped by their timeout or the timeout of the review
+	TimedOutStages []string
+
+	IsSuccess bool
+}
+
+// HasFindingsAtLeast checks if the review found anything of the priority or more severe
+func (r ReviewReport) HasFindingsAtLeast(threshold ReviewPriority) bool {
+	for priority, count := range r.FindingsByPriority {
+		if count > 0 && priority.IsAtLeast(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// CorrelationID identifies the review in logs like "owner/repo#42@1a2b3c4d"
+func (r ReviewRequest) CorrelationID() string {
+	sha := r.MergeRequest.SHA
+	if len(sha) > 8 {
+		sha = sha[:8]
+	}
+	return r.ProjectID + "#" + strconv.Itoa(r.MergeRequest.IID) + "@" + sha
+}
+
+// String identifies the review of the merge request head, it changes with every push
 func (r ReviewRequest) String() string {
 	return r.ProjectID + ":" + r.MergeRequest.SHA + ":" + strconv.Itoa(r.MergeRequest.IID)
 }
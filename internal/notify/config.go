@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+const (
+	defaultMinPriority = model.ReviewPriorityCritical
+	defaultTopFindings = 5
+	defaultTimeout     = 10 * time.Second
+)
+
+// Config represents notifications about finished merge request reviews, a notification is sent
+// only if the review found issues of the minimum priority or more severe
+type Config struct {
+	// MinPriority is the lowest priority of findings that triggers a notification, critical by default
+	MinPriority model.ReviewPriority `yaml:"min_priority" env:"NOTIFY_MIN_PRIORITY"`
+	// TopFindings is the number of the most important findings listed in a notification, 5 by default
+	TopFindings int `yaml:"top_findings" env:"NOTIFY_TOP_FINDINGS"`
+	// Timeout is the timeout of a request of every notifier, 10s by default
+	Timeout time.Duration `yaml:"timeout" env:"NOTIFY_TIMEOUT"`
+
+	Webhook WebhookConfig `yaml:"webhook"`
+	Slack   SlackConfig   `yaml:"slack"`
+}
+
+// WebhookConfig represents a generic outgoing webhook receiving reports as JSON
+type WebhookConfig struct {
+	Enable bool   `yaml:"enable" env:"NOTIFY_WEBHOOK_ENABLE"`
+	URL    string `yaml:"url" env:"NOTIFY_WEBHOOK_URL"`
+	// Secret signs the body with HMAC-SHA256 in the X-Codry-Signature header, the body is not signed if it is empty
+	Secret string `yaml:"secret" env:"NOTIFY_WEBHOOK_SECRET"`
+}
+
+// SlackConfig represents a Slack incoming webhook receiving formatted messages
+type SlackConfig struct {
+	Enable bool `yaml:"enable" env:"NOTIFY_SLACK_ENABLE"`
+	// WebhookURL is the incoming webhook of the channel, keep it secret because it allows posting to the channel
+	WebhookURL string `yaml:"webhook_url" env:"NOTIFY_SLACK_WEBHOOK_URL"`
+}
+
+// IsEnabled checks if any notifier is enabled
+func (c Config) IsEnabled() bool {
+	return c.Webhook.Enable || c.Slack.Enable
+}
+
+// Validate checks the notifiers config and returns all found problems at once
+func (c Config) Validate() error {
+	errs := errm.NewList()
+
+	if c.MinPriority != "" && !c.MinPriority.IsValid() {
+		errs.Errorf("invalid min_priority %q, expected one of critical, high, medium or backlog", c.MinPriority)
+	}
+	if c.TopFindings < 0 {
+		errs.New("top_findings must not be negative")
+	}
+	if c.Timeout < 0 {
+		errs.New("timeout must not be negative")
+	}
+	if c.Webhook.Enable {
+		if err := validateURL(c.Webhook.URL); err != nil {
+			errs.Wrap(err, "webhook.url")
+		}
+	}
+	if c.Slack.Enable {
+		if err := validateURL(c.Slack.WebhookURL); err != nil {
+			errs.Wrap(err, "slack.webhook_url")
+		}
+	}
+
+	return errs.Err()
+}
+
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return errm.New("url is required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errm.Wrap(err, "invalid url")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return errm.New("url must be absolute http or https")
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/lang"
+)
+
+var _ interfaces.Notifier = (*Dispatcher)(nil)
+
+// maxErrorBodySize is the size of the response body of a failed notification kept in the error
+const maxErrorBodySize = 512
+
+// Dispatcher sends reports of reviews with findings of the minimum priority to all enabled notifiers
+type Dispatcher struct {
+	cfg       Config
+	notifiers []namedNotifier
+}
+
+type namedNotifier struct {
+	name string
+	interfaces.Notifier
+}
+
+// New creates the dispatcher of enabled notifiers, the config must be validated
+func New(cfg Config) *Dispatcher {
+	cfg.MinPriority = lang.Check(cfg.MinPriority, defaultMinPriority)
+	cfg.TopFindings = lang.Check(cfg.TopFindings, defaultTopFindings)
+	cfg.Timeout = lang.Check(cfg.Timeout, defaultTimeout)
+
+	client := &http.Client{Timeout: cfg.Timeout}
+	d := &Dispatcher{cfg: cfg}
+	if cfg.Webhook.Enable {
+		d.notifiers = append(d.notifiers, namedNotifier{"webhook", newWebhook(cfg.Webhook, client)})
+	}
+	if cfg.Slack.Enable {
+		d.notifiers = append(d.notifiers, namedNotifier{"slack", newSlack(cfg.Slack, client)})
+	}
+
+	return d
+}
+
+// NotifyReviewComplete sends the report to every notifier if the review found issues of the minimum priority,
+// the report keeps only the most important findings; a failed notifier doesn't stop the others
+func (d *Dispatcher) NotifyReviewComplete(ctx context.Context, report model.ReviewReport) error {
+	if len(d.notifiers) == 0 || report.MergeRequest == nil || !report.HasFindingsAtLeast(d.cfg.MinPriority) {
+		return nil
+	}
+	report.Findings = topFindings(report.Findings, d.cfg.TopFindings)
+
+	errs := errm.NewList()
+	for _, notifier := range d.notifiers {
+		if err := notifier.NotifyReviewComplete(ctx, report); err != nil {
+			errs.Wrap(err, "failed to notify", "notifier", notifier.name)
+		}
+	}
+	return errs.Err()
+}
+
+// topFindings returns the findings sorted by priority from the most severe, the order of equal ones is kept
+func topFindings(findings []*model.ReviewAIComment, limit int) []*model.ReviewAIComment {
+	sorted := slices.Clone(findings)
+	slices.SortStableFunc(sorted, func(a, b *model.ReviewAIComment) int {
+		switch {
+		case a.Priority == b.Priority:
+			return 0
+		case a.Priority.IsAtLeast(b.Priority):
+			return -1
+		}
+		return 1
+	})
+	return sorted[:min(limit, len(sorted))]
+}
+
+// postJSON sends the body and fails on responses other than 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errm.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errm.Wrap(err, "failed to send request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return errm.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+// slackPriorities are priorities in the order of the counts line with their emojis
+var slackPriorities = []struct {
+	priority model.ReviewPriority
+	emoji    string
+}{
+	{model.ReviewPriorityCritical, "🔴"},
+	{model.ReviewPriorityHigh, "🟠"},
+	{model.ReviewPriorityMedium, "🟡"},
+	{model.ReviewPriorityBacklog, "⚪"},
+}
+
+// slackEscaper escapes control characters of Slack mrkdwn in text of the author and the model
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Slack posts reports as formatted messages to an incoming webhook of a channel
+type Slack struct {
+	cfg    SlackConfig
+	client *http.Client
+}
+
+func newSlack(cfg SlackConfig, client *http.Client) *Slack {
+	return &Slack{cfg: cfg, client: client}
+}
+
+// NotifyReviewComplete posts the message with counts of findings, the link to the merge request and top findings
+func (s *Slack) NotifyReviewComplete(ctx context.Context, report model.ReviewReport) error {
+	body, err := json.Marshal(map[string]string{"text": buildSlackMessage(report)})
+	if err != nil {
+		return errm.Wrap(err, "failed to marshal message")
+	}
+	return postJSON(ctx, s.client, s.cfg.WebhookURL, body, nil)
+}
+
+func buildSlackMessage(report model.ReviewReport) string {
+	var sb strings.Builder
+	mr := report.MergeRequest
+
+	name := slackEscaper.Replace(fmt.Sprintf("%s #%d: %s", report.ProjectID, mr.IID, mr.Title))
+	if mr.URL != "" {
+		name = fmt.Sprintf("<%s|%s>", mr.URL, name)
+	}
+	sb.WriteString("*Codry review of ")
+	sb.WriteString(name)
+	sb.WriteString("*\n")
+
+	var counts []string
+	for _, p := range slackPriorities {
+		if count := report.FindingsByPriority[p.priority]; count > 0 {
+			counts = append(counts, fmt.Sprintf("%s %d %s", p.emoji, count, p.priority))
+		}
+	}
+	sb.WriteString(strings.Join(counts, " · "))
+	sb.WriteString(fmt.Sprintf(" in %d files, %d comments posted", report.ProcessedFiles, report.CommentsCreated))
+	if !report.IsSuccess {
+		sb.WriteString(", some stages failed")
+	}
+	if len(report.TimedOutStages) > 0 {
+		sb.WriteString(", timed out: " + strings.Join(report.TimedOutStages, ", "))
+	}
+	sb.WriteString("\n")
+
+	if len(report.Findings) > 0 {
+		sb.WriteString("\n*Top findings:*\n")
+	}
+	for _, finding := range report.Findings {
+		location := finding.FilePath
+		if finding.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, finding.Line)
+		}
+		sb.WriteString(fmt.Sprintf("• [%s] `%s` %s\n", finding.Priority, slackEscaper.Replace(location), slackEscaper.Replace(finding.Title)))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+const (
+	// webhookEvent is the event of every payload, so receivers can tell it from future events
+	webhookEvent = "review_complete"
+	// signatureHeader is the header with the HMAC-SHA256 of the body like "sha256=<hex>"
+	signatureHeader = "X-Codry-Signature"
+)
+
+// Webhook posts reports as JSON to the configured URL
+type Webhook struct {
+	cfg    WebhookConfig
+	client *http.Client
+}
+
+func newWebhook(cfg WebhookConfig, client *http.Client) *Webhook {
+	return &Webhook{cfg: cfg, client: client}
+}
+
+type webhookPayload struct {
+	Event           string                       `json:"event"`
+	ReviewID        string                       `json:"review_id"`
+	ProjectID       string                       `json:"project_id"`
+	MRIID           int                          `json:"mr_iid"`
+	Title           string                       `json:"title"`
+	URL             string                       `json:"url"`
+	Author          string                       `json:"author"`
+	ProcessedFiles  int                          `json:"processed_files"`
+	CommentsCreated int                          `json:"comments_created"`
+	Findings        map[model.ReviewPriority]int `json:"findings"`
+	TopFindings     []webhookFinding             `json:"top_findings"`
+	TimedOutStages  []string                     `json:"timed_out_stages,omitempty"`
+	Success         bool                         `json:"success"`
+}
+
+type webhookFinding struct {
+	File     string               `json:"file"`
+	Line     int                  `json:"line"`
+	Priority model.ReviewPriority `json:"priority"`
+	Title    string               `json:"title"`
+}
+
+// NotifyReviewComplete posts the report, the body is signed if the secret is set
+func (w *Webhook) NotifyReviewComplete(ctx context.Context, report model.ReviewReport) error {
+	mr := report.MergeRequest
+	payload := webhookPayload{
+		Event:           webhookEvent,
+		ReviewID:        report.CorrelationID,
+		ProjectID:       report.ProjectID,
+		MRIID:           mr.IID,
+		Title:           mr.Title,
+		URL:             mr.URL,
+		Author:          mr.Author.Username,
+		ProcessedFiles:  report.ProcessedFiles,
+		CommentsCreated: report.CommentsCreated,
+		Findings:        report.FindingsByPriority,
+		TopFindings:     make([]webhookFinding, 0, len(report.Findings)),
+		TimedOutStages:  report.TimedOutStages,
+		Success:         report.IsSuccess,
+	}
+	for _, finding := range report.Findings {
+		payload.TopFindings = append(payload.TopFindings, webhookFinding{
+			File:     finding.FilePath,
+			Line:     finding.Line,
+			Priority: finding.Priority,
+			Title:    finding.Title,
+		})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errm.Wrap(err, "failed to marshal payload")
+	}
+
+	var headers map[string]string
+	if w.cfg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.cfg.Secret))
+		mac.Write(body)
+		headers = map[string]string{signatureHeader: "sha256=" + hex.EncodeToString(mac.Sum(nil))}
+	}
+
+	return postJSON(ctx, w.client, w.cfg.URL, body, headers)
+}
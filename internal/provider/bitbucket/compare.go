@@ -0,0 +1,181 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/lang"
+)
+
+var (
+	_ interfaces.CommitComparer  = (*Provider)(nil)
+	_ interfaces.CommitReader    = (*Provider)(nil)
+	_ interfaces.CommitCommenter = (*Provider)(nil)
+)
+
+// GetMergeBase returns the hash of the best common ancestor of two commits
+func (p *Provider) GetMergeBase(ctx context.Context, projectID, first, second string) (string, error) {
+	// Parse workspace/repo_slug from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return "", errm.New("invalid Bitbucket project ID format, expected 'workspace/repo_slug'")
+	}
+	workspace, repoSlug := parts[0], parts[1]
+
+	apiURL := fmt.Sprintf("repositories/%s/%s/merge-base/%s..%s", workspace, repoSlug, first, second)
+
+	var commit struct {
+		Hash string `json:"hash"`
+	}
+	resp, err := p.client.Get(ctx, apiURL, &commit)
+	if err != nil {
+		return "", wrapError(resp, err, "failed to get merge base from Bitbucket")
+	}
+
+	return commit.Hash, nil
+}
+
+// CompareCommits returns file diffs of the head commit against the base one
+func (p *Provider) CompareCommits(ctx context.Context, projectID, base, head string) ([]*model.FileDiff, error) {
+	// Parse workspace/repo_slug from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid Bitbucket project ID format, expected 'workspace/repo_slug'")
+	}
+	workspace, repoSlug := parts[0], parts[1]
+
+	// Bitbucket diff spec is the changed commit first and the one it is compared to second
+	apiURL := fmt.Sprintf("repositories/%s/%s/diff/%s..%s", workspace, repoSlug, head, base)
+
+	resp, err := p.client.Get(ctx, apiURL)
+	if err != nil {
+		return nil, wrapError(resp, err, "failed to get diff from Bitbucket")
+	}
+
+	return model.ParseUnifiedDiff(string(resp.Body())), nil
+}
+
+// GetMergeRequestCommits returns commits of the pull request from the oldest, only the first page of 100 commits is read
+func (p *Provider) GetMergeRequestCommits(ctx context.Context, projectID string, mrIID int) ([]*model.Commit, error) {
+	// Parse workspace/repo_slug from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid Bitbucket project ID format, expected 'workspace/repo_slug'")
+	}
+	workspace, repoSlug := parts[0], parts[1]
+
+	apiURL := fmt.Sprintf("repositories/%s/%s/pullrequests/%d/commits?pagelen=100", workspace, repoSlug, mrIID)
+
+	var response struct {
+		Values []bitbucketCommit `json:"values"`
+	}
+	resp, err := p.client.Get(ctx, apiURL, &response)
+	if err != nil {
+		return nil, wrapError(resp, err, "failed to list pull request commits")
+	}
+
+	commits := make([]*model.Commit, 0, len(response.Values))
+	for _, commit := range response.Values {
+		commits = append(commits, convertCommit(commit))
+	}
+
+	// Bitbucket lists pull request commits from the newest
+	slices.Reverse(commits)
+
+	return commits, nil
+}
+
+// GetCommit returns the commit with its first parent
+func (p *Provider) GetCommit(ctx context.Context, projectID, sha string) (*model.Commit, error) {
+	// Parse workspace/repo_slug from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid Bitbucket project ID format, expected 'workspace/repo_slug'")
+	}
+	workspace, repoSlug := parts[0], parts[1]
+
+	apiURL := fmt.Sprintf("repositories/%s/%s/commit/%s", workspace, repoSlug, sha)
+
+	var commit bitbucketCommit
+	resp, err := p.client.Get(ctx, apiURL, &commit)
+	if err != nil {
+		return nil, wrapError(resp, err, "failed to get commit from Bitbucket")
+	}
+
+	return convertCommit(commit), nil
+}
+
+// convertCommit converts a commit of a pull request or of the repository
+func convertCommit(commit bitbucketCommit) *model.Commit {
+	createdAt, _ := time.Parse(time.RFC3339, commit.Date)
+	modelCommit := &model.Commit{
+		SHA:     commit.Hash,
+		Message: commit.Message,
+		Author: model.User{
+			ID:       commit.Author.User.UUID,
+			Username: commit.Author.User.Username,
+			Name:     lang.Check(commit.Author.User.DisplayName, commit.Author.Raw),
+		},
+		CreatedAt: createdAt,
+	}
+	if len(commit.Parents) > 0 {
+		modelCommit.ParentSHA = commit.Parents[0].Hash
+	}
+	return modelCommit
+}
+
+// GetCommitDiffs returns file diffs of the commit against its first parent
+func (p *Provider) GetCommitDiffs(ctx context.Context, projectID, sha string) ([]*model.FileDiff, error) {
+	// Parse workspace/repo_slug from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid Bitbucket project ID format, expected 'workspace/repo_slug'")
+	}
+	workspace, repoSlug := parts[0], parts[1]
+
+	apiURL := fmt.Sprintf("repositories/%s/%s/diff/%s", workspace, repoSlug, sha)
+
+	resp, err := p.client.Get(ctx, apiURL)
+	if err != nil {
+		return nil, wrapError(resp, err, "failed to get commit diff from Bitbucket")
+	}
+
+	return model.ParseUnifiedDiff(string(resp.Body())), nil
+}
+
+// CreateCommitComment creates a comment of the commit, inline comments are placed at their line of the new file
+func (p *Provider) CreateCommitComment(ctx context.Context, projectID, sha string, comment *model.Comment) error {
+	// Parse workspace/repo_slug from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return errm.New("invalid Bitbucket project ID format, expected 'workspace/repo_slug'")
+	}
+	workspace, repoSlug := parts[0], parts[1]
+
+	apiURL := fmt.Sprintf("repositories/%s/%s/commit/%s/comments", workspace, repoSlug, sha)
+
+	commentData := map[string]any{
+		"content": map[string]any{
+			"raw": comment.FullBody(),
+		},
+	}
+	if comment.Type == model.CommentTypeInline && comment.FilePath != "" && comment.Line > 0 {
+		commentData["inline"] = map[string]any{
+			"path": comment.FilePath,
+			"to":   comment.Line,
+		}
+	}
+
+	resp, err := p.client.Post(ctx, apiURL, commentData)
+	if err != nil {
+		return wrapError(resp, err, "failed to create commit comment")
+	}
+
+	return nil
+}
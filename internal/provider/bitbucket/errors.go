@@ -0,0 +1,39 @@
+package bitbucket
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/maxbolgarin/cliex"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+// statusErrors are errors of the client by HTTP statuses with a class, the client returns
+// them without the response when the request fails
+var statusErrors = map[int]error{
+	http.StatusNotFound:        cliex.ErrNotFound,
+	http.StatusTooManyRequests: cliex.ErrTooManyRequests,
+	http.StatusUnauthorized:    cliex.ErrUnauthorized,
+	http.StatusForbidden:       cliex.ErrForbidden,
+}
+
+// wrapError wraps the error of the Bitbucket API call classified by the response status,
+// resp is nil if the request was not sent or failed
+func wrapError(resp *resty.Response, err error, msg string) error {
+	return errm.Wrap(model.NewProviderError(responseStatus(resp, err), err), msg)
+}
+
+// responseStatus returns the HTTP status of the failed request, 0 if it is unknown
+func responseStatus(resp *resty.Response, err error) int {
+	if resp != nil {
+		return resp.StatusCode()
+	}
+	for status, statusErr := range statusErrors {
+		if errors.Is(err, statusErr) {
+			return status
+		}
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+package bitbucket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+func TestProviderErrorClasses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   error
+	}{
+		{"not found", http.StatusNotFound, model.ErrNotFound},
+		{"unauthorized", http.StatusUnauthorized, model.ErrUnauthorized},
+		{"forbidden", http.StatusForbidden, model.ErrForbidden},
+		{"rate limited", http.StatusTooManyRequests, model.ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"type": "error", "error": {"message": "failed"}}`))
+			}))
+			defer server.Close()
+
+			provider, err := New(model.ProviderConfig{Token: "token", BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			_, err = provider.GetMergeRequest(context.Background(), "workspace/repo", 1)
+			if !errm.Is(err, tt.want) {
+				t.Errorf("GetMergeRequest() error = %v, want %v", err, tt.want)
+			}
+			for _, other := range []error{model.ErrNotFound, model.ErrRateLimited, model.ErrUnauthorized, model.ErrForbidden} {
+				if other != tt.want && errm.Is(err, other) {
+					t.Errorf("GetMergeRequest() error = %v, matches %v", err, other)
+				}
+			}
+		})
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/maxbolgarin/cliex"
@@ -20,7 +22,10 @@ import (
 	"github.com/maxbolgarin/logze/v2"
 )
 
-var _ interfaces.CodeProvider = (*Provider)(nil)
+var (
+	_ interfaces.CodeProvider  = (*Provider)(nil)
+	_ interfaces.BotIdentifier = (*Provider)(nil)
+)
 
 const (
 	defaultBaseURL = "https://api.bitbucket.org/2.0"
@@ -31,6 +36,9 @@ type Provider struct {
 	config model.ProviderConfig
 	logger logze.Logger
 	client *cliex.HTTP
+
+	// bot is the account of the token, it is resolved when credentials are checked
+	bot atomic.Pointer[model.User]
 }
 
 // New creates a new Bitbucket provider
@@ -51,6 +59,9 @@ func New(config model.ProviderConfig) (*Provider, error) {
 		return nil, errm.Wrap(err, "failed to create Bitbucket client")
 	}
 	cli.C().SetBasicAuth("x-auth-token", config.Token)
+	if config.Transport != nil {
+		cli.C().SetTransport(config.Transport)
+	}
 
 	return &Provider{
 		client: cli,
@@ -59,6 +70,45 @@ func New(config model.ProviderConfig) (*Provider, error) {
 	}, nil
 }
 
+// ValidateCredentials checks the token by getting the current user
+func (p *Provider) ValidateCredentials(ctx context.Context) error {
+	var user bitbucketUser
+	resp, err := p.client.Get(ctx, "user", &user)
+	if err != nil {
+		if status := responseStatus(resp, err); status == http.StatusUnauthorized || status == http.StatusForbidden {
+			return errm.Wrap(model.ErrInvalidCredentials, "Bitbucket rejected token", "status", status)
+		}
+		return wrapError(resp, err, "failed to get current user")
+	}
+	p.setBotUser(model.User{ID: user.UUID, Username: user.Username, Name: user.DisplayName})
+	return nil
+}
+
+// BotUser returns the account of the token resolved by ValidateCredentials, ok is false if it is not resolved yet
+func (p *Provider) BotUser() (model.User, bool) {
+	if bot := p.bot.Load(); bot != nil {
+		return *bot, true
+	}
+	return model.User{}, false
+}
+
+// setBotUser stores the account of the token, the change of the account is logged once
+func (p *Provider) setBotUser(bot model.User) {
+	if old := p.bot.Swap(&bot); old != nil && *old == bot {
+		return
+	}
+	p.logger.Info("resolved bot account", "id", bot.ID, "username", bot.Username)
+	if p.config.BotUsername != "" && p.config.BotUsername != bot.Username {
+		p.logger.Warn("configured bot username differs from the token account, the account ID is used to recognize the bot",
+			"bot_username", p.config.BotUsername, "username", bot.Username)
+	}
+}
+
+// isBot checks if the user is the bot by the resolved account ID, the username is a fallback
+func (p *Provider) isBot(user *model.User) bool {
+	return user.IsBot(p.bot.Load(), p.config.BotUsername)
+}
+
 // ValidateWebhook validates the Bitbucket webhook signature
 func (p *Provider) ValidateWebhook(payload []byte, signature string) error {
 	if p.config.WebhookSecret == "" {
@@ -86,6 +136,10 @@ func (p *Provider) ParseWebhookEvent(payload []byte) (*model.CodeEvent, error) {
 	if err := json.Unmarshal(payload, &bitbucketPayload); err != nil {
 		return nil, errm.Wrap(err, "failed to parse Bitbucket webhook payload")
 	}
+	if bitbucketPayload.PullRequest.ID == 0 {
+		// Pushes and other repository events have no pull request
+		return nil, errm.Wrap(model.ErrIgnoredEvent, "payload has no pull request")
+	}
 
 	// Detect event type from headers or payload
 	eventType := "pullrequest"
@@ -156,9 +210,9 @@ func (p *Provider) GetMergeRequest(ctx context.Context, projectID string, mrIID
 	apiURL := fmt.Sprintf("repositories/%s/%s/pullrequests/%d", workspace, repoSlug, mrIID)
 
 	var pr bitbucketPullRequest
-	_, err := p.client.Get(ctx, apiURL, &pr)
+	resp, err := p.client.Get(ctx, apiURL, &pr)
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to get pull request from Bitbucket")
+		return nil, wrapError(resp, err, "failed to get pull request from Bitbucket")
 	}
 
 	// Convert reviewers
@@ -212,11 +266,11 @@ func (p *Provider) GetMergeRequestDiffs(ctx context.Context, projectID string, m
 
 	resp, err := p.client.Get(ctx, apiURL)
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to get diff from Bitbucket")
+		return nil, wrapError(resp, err, "failed to get diff from Bitbucket")
 	}
 
 	// Parse diff into FileDiff objects
-	diffs := p.parseDiffContent(string(resp.Body()))
+	diffs := model.ParseUnifiedDiff(string(resp.Body()))
 
 	return diffs, nil
 }
@@ -238,9 +292,9 @@ func (p *Provider) UpdateMergeRequestDescription(ctx context.Context, projectID
 		"description": description,
 	}
 
-	_, err := p.client.Put(ctx, apiURL, updateData)
+	resp, err := p.client.Put(ctx, apiURL, updateData)
 	if err != nil {
-		return errm.Wrap(err, "failed to update pull request description")
+		return wrapError(resp, err, "failed to update pull request description")
 	}
 
 	return nil
@@ -261,7 +315,7 @@ func (p *Provider) CreateComment(ctx context.Context, projectID string, mrIID in
 	// Prepare comment data
 	commentData := map[string]any{
 		"content": map[string]any{
-			"raw": comment.Body,
+			"raw": comment.FullBody(),
 		},
 	}
 
@@ -285,9 +339,9 @@ func (p *Provider) CreateComment(ctx context.Context, projectID string, mrIID in
 		commentData["inline"] = inlineData
 	}
 
-	_, err := p.client.Post(ctx, apiURL, commentData)
+	resp, err := p.client.Post(ctx, apiURL, commentData)
 	if err != nil {
-		return errm.Wrap(err, "failed to create comment")
+		return wrapError(resp, err, "failed to create comment")
 	}
 
 	return nil
@@ -313,82 +367,10 @@ func (p *Provider) extractLineRange(body string) (int, int) {
 	return 0, 0
 }
 
-// parseDiffContent parses unified diff content into FileDiff objects
-func (p *Provider) parseDiffContent(diffContent string) []*model.FileDiff {
-	var diffs []*model.FileDiff
-	lines := strings.Split(diffContent, "\n")
-
-	var currentDiff *model.FileDiff
-	var diffLines []string
-
-	for _, line := range lines {
-		switch {
-		case strings.HasPrefix(line, "diff --git"):
-			// Save previous diff if exists
-			if currentDiff != nil {
-				currentDiff.Diff = strings.Join(diffLines, "\n")
-				diffs = append(diffs, currentDiff)
-			}
-
-			// Start new diff
-			currentDiff = &model.FileDiff{}
-			diffLines = []string{line}
-
-		case strings.HasPrefix(line, "--- ") && currentDiff != nil:
-			// Old file path
-			if strings.Contains(line, "/dev/null") {
-				currentDiff.IsNew = true
-			} else {
-				path := strings.TrimPrefix(line, "--- a/")
-				if path != "" {
-					currentDiff.OldPath = path
-				}
-			}
-			diffLines = append(diffLines, line)
-
-		case strings.HasPrefix(line, "+++ ") && currentDiff != nil:
-			// New file path
-			if strings.Contains(line, "/dev/null") {
-				currentDiff.IsDeleted = true
-			} else {
-				path := strings.TrimPrefix(line, "+++ b/")
-				if path != "" {
-					currentDiff.NewPath = path
-				}
-			}
-			diffLines = append(diffLines, line)
-
-		case currentDiff != nil:
-			diffLines = append(diffLines, line)
-		}
-	}
-
-	// Add the last diff
-	if currentDiff != nil {
-		currentDiff.Diff = strings.Join(diffLines, "\n")
-		diffs = append(diffs, currentDiff)
-	}
-
-	// Set default paths and detect renames
-	for _, diff := range diffs {
-		if diff.NewPath == "" && diff.OldPath != "" {
-			diff.NewPath = diff.OldPath
-		}
-		if diff.OldPath == "" && diff.NewPath != "" {
-			diff.OldPath = diff.NewPath
-		}
-		if diff.OldPath != "" && diff.NewPath != "" && diff.OldPath != diff.NewPath {
-			diff.IsRenamed = true
-		}
-	}
-
-	return diffs
-}
-
 // IsMergeRequestEvent determines if a webhook event is a pull request event that should be processed
 func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 	// Only process pull request events (Bitbucket calls them pullrequest)
-	if event.Type != "pullrequest" {
+	if event == nil || event.MergeRequest == nil || event.Type != "pullrequest" {
 		return false
 	}
 
@@ -407,7 +389,7 @@ func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 	}
 
 	// Don't process events from the bot itself to avoid loops
-	if event.User.Username == p.config.BotUsername {
+	if p.isBot(event.User) {
 		return false
 	}
 
@@ -418,7 +400,7 @@ func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 		// Check if the bot was added as a reviewer
 		botIsReviewer := false
 		for _, reviewer := range event.MergeRequest.Reviewers {
-			if reviewer.Username == p.config.BotUsername {
+			if p.isBot(&reviewer) {
 				botIsReviewer = true
 				break
 			}
@@ -483,9 +465,9 @@ func (p *Provider) ListMergeRequests(ctx context.Context, projectID string, filt
 		Values []bitbucketPullRequest `json:"values"`
 	}
 
-	_, err := p.client.Get(ctx, apiURL, &response)
+	resp, err := p.client.Get(ctx, apiURL, &response)
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to list pull requests")
+		return nil, wrapError(resp, err, "failed to list pull requests")
 	}
 
 	var result []*model.MergeRequest
@@ -575,7 +557,7 @@ func (p *Provider) GetFileContent(ctx context.Context, projectID, filePath, comm
 
 	resp, err := p.client.Get(ctx, apiURL)
 	if err != nil {
-		return "", errm.Wrap(err, "failed to get file content from Bitbucket")
+		return "", wrapError(resp, err, "failed to get file content from Bitbucket")
 	}
 
 	return string(resp.Body()), nil
@@ -597,17 +579,19 @@ func (p *Provider) GetComments(ctx context.Context, projectID string, mrIID int)
 		Values []bitbucketComment `json:"values"`
 	}
 
-	_, err := p.client.Get(ctx, apiURL, &response)
+	resp, err := p.client.Get(ctx, apiURL, &response)
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to get comments from Bitbucket")
+		return nil, wrapError(resp, err, "failed to get comments from Bitbucket")
 	}
 
 	var allComments []*model.Comment
 
 	for _, comment := range response.Values {
+		body, footer := model.SplitCommentFooter(comment.Content.Raw)
 		modelComment := &model.Comment{
-			ID:   strconv.Itoa(comment.ID),
-			Body: comment.Content.Raw,
+			ID:     strconv.Itoa(comment.ID),
+			Body:   body,
+			Footer: footer,
 			Author: model.User{
 				ID:       comment.User.UUID,
 				Username: comment.User.Username,
@@ -657,9 +641,9 @@ func (p *Provider) UpdateComment(ctx context.Context, projectID string, mrIID in
 		},
 	}
 
-	_, err := p.client.Put(ctx, apiURL, updateData)
+	resp, err := p.client.Put(ctx, apiURL, updateData)
 	if err != nil {
-		return errm.Wrap(err, "failed to update comment")
+		return wrapError(resp, err, "failed to update comment")
 	}
 
 	return nil
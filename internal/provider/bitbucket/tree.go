@@ -0,0 +1,52 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var _ interfaces.FileLister = (*Provider)(nil)
+
+// ListFiles returns paths of files directly in the directory at the ref, only the first page of 100 entries is read;
+// symlinks and submodules are skipped because their content is not code of the file
+func (p *Provider) ListFiles(ctx context.Context, projectID, dir, ref string) ([]string, error) {
+	// Parse workspace/repo_slug from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid Bitbucket project ID format, expected 'workspace/repo_slug'")
+	}
+	workspace, repoSlug := parts[0], parts[1]
+
+	// Trailing slash makes Bitbucket list the directory instead of returning a file
+	path := strings.Trim(dir, "/")
+	if path != "" {
+		path += "/"
+	}
+	apiURL := fmt.Sprintf("repositories/%s/%s/src/%s/%s?pagelen=100", workspace, repoSlug, ref, path)
+
+	var response struct {
+		Values []struct {
+			Type       string   `json:"type"`
+			Path       string   `json:"path"`
+			Attributes []string `json:"attributes"`
+		} `json:"values"`
+	}
+	resp, err := p.client.Get(ctx, apiURL, &response)
+	if err != nil {
+		return nil, wrapError(resp, err, "failed to list directory from Bitbucket")
+	}
+
+	files := make([]string, 0, len(response.Values))
+	for _, entry := range response.Values {
+		if entry.Type == "commit_file" && !slices.Contains(entry.Attributes, "link") && !slices.Contains(entry.Attributes, "subrepository") {
+			files = append(files, entry.Path)
+		}
+	}
+
+	return files, nil
+}
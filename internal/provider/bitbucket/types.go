@@ -70,6 +70,19 @@ type bitbucketRepository struct {
 	} `json:"workspace"`
 }
 
+type bitbucketCommit struct {
+	Hash    string `json:"hash"`
+	Message string `json:"message"`
+	Date    string `json:"date"`
+	Author  struct {
+		Raw  string        `json:"raw"`
+		User bitbucketUser `json:"user"`
+	} `json:"author"`
+	Parents []struct {
+		Hash string `json:"hash"`
+	} `json:"parents"`
+}
+
 type bitbucketComment struct {
 	ID        int    `json:"id"`
 	CreatedOn string `json:"created_on"`
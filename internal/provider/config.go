@@ -1,9 +1,14 @@
 package provider
 
 import (
+	"net/http"
+	"net/url"
+	"os"
 	"slices"
+	"time"
 
 	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/lang"
 )
 
 type ProviderType string
@@ -17,22 +22,135 @@ const (
 
 var supportedProviderTypes = []ProviderType{GitLab, GitHub, Bitbucket}
 
+const (
+	defaultRateLimitThreshold = 0.2
+	defaultRateLimitMaxDelay  = 5 * time.Second
+
+	defaultPollingMinInterval = 30 * time.Second
+	defaultPollingMaxInterval = 10 * time.Minute
+)
+
 // Config represents VCS provider configuration
 type Config struct {
+	// Name is the webhook path suffix of the provider, provider type is used by default
+	Name          string       `yaml:"name" env:"PROVIDER_NAME"`
 	Type          ProviderType `yaml:"type" env:"PROVIDER_TYPE"`
 	BaseURL       string       `yaml:"base_url" env:"PROVIDER_BASE_URL"`
 	Token         string       `yaml:"token" env:"PROVIDER_TOKEN"`
 	WebhookSecret string       `yaml:"webhook_secret" env:"PROVIDER_WEBHOOK_SECRET"`
 	BotUsername   string       `yaml:"bot_username" env:"PROVIDER_BOT_USERNAME"`
+
+	// UploadURL is the upload API URL of GitHub Enterprise, base URL is used if it is empty
+	UploadURL string `yaml:"upload_url" env:"PROVIDER_UPLOAD_URL"`
+	// CACertFile is a PEM bundle of certificates trusted in addition to the system ones,
+	// e.g. for a self-hosted provider with an internal CA
+	CACertFile string `yaml:"ca_cert_file" env:"PROVIDER_CA_CERT_FILE"`
+
+	Filter    FilterConfig    `yaml:"filter"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Polling   PollingConfig   `yaml:"polling"`
+
+	// Transport wraps requests to the provider API, it is set by the application
+	Transport http.RoundTripper `yaml:"-"`
+}
+
+// FilterConfig limits merge requests that are fetched for review in polling mode
+type FilterConfig struct {
+	// Authors is a list of usernames or IDs, merge requests from other authors are skipped
+	Authors []string `yaml:"authors" env:"PROVIDER_FILTER_AUTHORS"`
+	// TargetBranches is a list of branches, merge requests into other branches are skipped
+	TargetBranches []string `yaml:"target_branches" env:"PROVIDER_FILTER_TARGET_BRANCHES"`
+	// Labels must all be set on merge request, Bitbucket doesn't support labels
+	Labels []string `yaml:"labels" env:"PROVIDER_FILTER_LABELS"`
+}
+
+// RateLimitConfig represents throttling of API requests by rate limit headers of responses,
+// requests are delayed when the remaining share of the quota is below the threshold
+type RateLimitConfig struct {
+	Disable bool `yaml:"disable" env:"PROVIDER_RATE_LIMIT_DISABLE"`
+	// Threshold is the remaining share of the quota that starts throttling, 0.2 by default
+	Threshold float64 `yaml:"threshold" env:"PROVIDER_RATE_LIMIT_THRESHOLD"`
+	// MaxDelay is the longest delay before a request, 5s by default
+	MaxDelay time.Duration `yaml:"max_delay" env:"PROVIDER_RATE_LIMIT_MAX_DELAY"`
+}
+
+// PollingConfig represents polling of updated merge requests in serve mode for deployments without webhooks,
+// the interval is doubled after every poll without updates and is reset to the minimum when they are found
+type PollingConfig struct {
+	// Projects are IDs or paths of polled projects, polling is disabled if empty
+	Projects []string `yaml:"projects" env:"PROVIDER_POLLING_PROJECTS"`
+	// MinInterval is the interval between polls with updates, 30s by default
+	MinInterval time.Duration `yaml:"min_interval" env:"PROVIDER_POLLING_MIN_INTERVAL"`
+	// MaxInterval is the longest interval between polls without updates, 10m by default
+	MaxInterval time.Duration `yaml:"max_interval" env:"PROVIDER_POLLING_MAX_INTERVAL"`
+}
+
+// WebhookName returns the name of the provider webhook path
+func (c Config) WebhookName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return string(c.Type)
 }
 
 func (c *Config) PrepareAndValidate() error {
+	c.RateLimit.Threshold = lang.Check(c.RateLimit.Threshold, defaultRateLimitThreshold)
+	c.RateLimit.MaxDelay = lang.Check(c.RateLimit.MaxDelay, defaultRateLimitMaxDelay)
+	return c.Validate()
+}
+
+// Validate checks the config and returns all found problems at once
+func (c Config) Validate() error {
+	errs := errm.NewList()
 	if c.Token == "" {
-		return errm.New("token is required")
+		errs.New("token is required")
 	}
 	if c.Type == "" || !slices.Contains(supportedProviderTypes, c.Type) {
-		return errm.New("invalid provider type: %s", c.Type)
+		errs.Errorf("invalid provider type %q, expected one of %v", c.Type, supportedProviderTypes)
+	}
+	if err := validateURL(c.BaseURL); err != nil {
+		errs.Wrap(err, "invalid base_url")
+	}
+	if err := validateURL(c.UploadURL); err != nil {
+		errs.Wrap(err, "invalid upload_url")
+	}
+	if c.UploadURL != "" && c.Type != GitHub {
+		errs.New("upload_url is supported only by GitHub provider")
+	}
+	if c.CACertFile != "" {
+		if _, err := os.Stat(c.CACertFile); err != nil {
+			errs.Wrap(err, "invalid ca_cert_file")
+		}
+	}
+	if c.RateLimit.Threshold < 0 || c.RateLimit.Threshold >= 1 {
+		errs.Errorf("rate_limit.threshold must be in [0, 1), got %v", c.RateLimit.Threshold)
+	}
+	if c.RateLimit.MaxDelay < 0 {
+		errs.New("rate_limit.max_delay must not be negative")
 	}
+	if c.Polling.MinInterval < 0 || c.Polling.MaxInterval < 0 {
+		errs.New("polling intervals must not be negative")
+	}
+	if c.Polling.MinInterval > 0 && c.Polling.MaxInterval > 0 && c.Polling.MinInterval > c.Polling.MaxInterval {
+		errs.Errorf("polling.min_interval %s is greater than polling.max_interval %s", c.Polling.MinInterval, c.Polling.MaxInterval)
+	}
+	return errs.Err()
+}
 
+// validateURL checks that the URL is absolute with http or https scheme, empty URL is valid
+func validateURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errm.Errorf("expected http or https URL, got %q", rawURL)
+	}
+	if u.Host == "" {
+		return errm.Errorf("no host in URL %q", rawURL)
+	}
 	return nil
 }
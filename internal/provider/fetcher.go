@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/maxbolgarin/codry/internal/model"
@@ -28,14 +29,20 @@ func (o *FetchOptions) SetDefaults() {
 // Fetcher provides utility methods for fetching merge requests from repositories
 type Fetcher struct {
 	provider interfaces.CodeProvider
+	filter   model.MergeRequestFilter
 	log      logze.Logger
 }
 
-// NewFetcher creates a new MR fetcher instance
-func NewFetcher(provider interfaces.CodeProvider) *Fetcher {
+// NewFetcher creates a new MR fetcher instance, merge requests not matching the filter config are skipped
+func NewFetcher(provider interfaces.CodeProvider, cfg FilterConfig) *Fetcher {
 	return &Fetcher{
 		provider: provider,
-		log:      logze.With("component", "fetcher"),
+		filter: model.MergeRequestFilter{
+			Authors:        cfg.Authors,
+			TargetBranches: cfg.TargetBranches,
+			Labels:         cfg.Labels,
+		},
+		log: logze.With("component", "fetcher"),
 	}
 }
 
@@ -45,13 +52,17 @@ func (f *Fetcher) FetchOpenMRs(ctx context.Context, projectID string) ([]*model.
 		State: []string{"open", "opened"}, // Support both GitLab and GitHub terminology
 		Limit: 100,                        // Get up to 100 MRs per page
 	}
-	return f.provider.ListMergeRequests(ctx, projectID, filter)
+	return f.listMergeRequests(ctx, projectID, filter)
 }
 
 // FetchRecentMRs retrieves merge requests updated in the last specified duration
 func (f *Fetcher) FetchRecentMRs(ctx context.Context, projectID string, since time.Duration) ([]*model.MergeRequest, error) {
 	sinceTime := time.Now().Add(-since)
-	return f.provider.GetMergeRequestUpdates(ctx, projectID, sinceTime)
+	mrs, err := f.provider.GetMergeRequestUpdates(ctx, projectID, sinceTime)
+	if err != nil {
+		return nil, err
+	}
+	return f.applyFilter(mrs, &f.filter), nil
 }
 
 // FetchMRsByAuthor retrieves merge requests created by a specific author
@@ -61,7 +72,7 @@ func (f *Fetcher) FetchMRsByAuthor(ctx context.Context, projectID, authorID stri
 		AuthorID: authorID,
 		Limit:    50,
 	}
-	return f.provider.ListMergeRequests(ctx, projectID, filter)
+	return f.listMergeRequests(ctx, projectID, filter)
 }
 
 // FetchMRsToReview retrieves merge requests that need review based on various criteria
@@ -79,7 +90,7 @@ func (f *Fetcher) FetchMRsToReview(ctx context.Context, projectID string, option
 	if options.CreatedSince != nil {
 		filter.CreatedAfter = options.CreatedSince
 	}
-	return f.provider.ListMergeRequests(ctx, projectID, filter)
+	return f.listMergeRequests(ctx, projectID, filter)
 }
 
 // PollForUpdates continuously polls for updated merge requests
@@ -101,15 +112,16 @@ func (f *Fetcher) PollForUpdates(ctx context.Context, projectID string, interval
 				continue
 			}
 
+			// Update the last update time to the most recent MR update, including skipped ones
+			for _, mr := range mrs {
+				if mr.UpdatedAt.After(lastUpdate) {
+					lastUpdate = mr.UpdatedAt
+				}
+			}
+
+			mrs = f.applyFilter(mrs, &f.filter)
 			if len(mrs) > 0 {
 				callback(mrs)
-
-				// Update the last update time to the most recent MR update
-				for _, mr := range mrs {
-					if mr.UpdatedAt.After(lastUpdate) {
-						lastUpdate = mr.UpdatedAt
-					}
-				}
 			}
 		}
 	}
@@ -117,6 +129,8 @@ func (f *Fetcher) PollForUpdates(ctx context.Context, projectID string, interval
 
 // BatchProcessMRs processes multiple merge requests with a callback function
 func (f *Fetcher) BatchProcessMRs(ctx context.Context, projectID string, filter *model.MergeRequestFilter, processor func(*model.MergeRequest) error) error {
+	filter = f.withConfigFilter(filter)
+
 	page := 0
 	for {
 		filter.Page = page
@@ -128,6 +142,8 @@ func (f *Fetcher) BatchProcessMRs(ctx context.Context, projectID string, filter
 		if len(mrs) == 0 {
 			break // No more results
 		}
+		fetched := len(mrs)
+		mrs = f.applyFilter(mrs, filter)
 
 		f.log.Debug("processing MR batch", "count", len(mrs), "page", page)
 
@@ -141,7 +157,7 @@ func (f *Fetcher) BatchProcessMRs(ctx context.Context, projectID string, filter
 		}
 
 		// If we got fewer results than the limit, we've reached the end
-		if len(mrs) < filter.Limit {
+		if fetched < filter.Limit {
 			break
 		}
 
@@ -149,3 +165,40 @@ func (f *Fetcher) BatchProcessMRs(ctx context.Context, projectID string, filter
 	}
 	return nil
 }
+
+// listMergeRequests lists merge requests with the filter combined with the filter config
+func (f *Fetcher) listMergeRequests(ctx context.Context, projectID string, filter *model.MergeRequestFilter) ([]*model.MergeRequest, error) {
+	filter = f.withConfigFilter(filter)
+	mrs, err := f.provider.ListMergeRequests(ctx, projectID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return f.applyFilter(mrs, filter), nil
+}
+
+// withConfigFilter returns a copy of the filter with criteria from the filter config,
+// a single allowed target branch is passed as TargetBranch so providers can apply it server-side
+func (f *Fetcher) withConfigFilter(filter *model.MergeRequestFilter) *model.MergeRequestFilter {
+	out := *filter
+	out.Authors = append(slices.Clone(filter.Authors), f.filter.Authors...)
+	out.TargetBranches = append(slices.Clone(filter.TargetBranches), f.filter.TargetBranches...)
+	out.Labels = append(slices.Clone(filter.Labels), f.filter.Labels...)
+	if out.TargetBranch == "" && len(out.TargetBranches) == 1 {
+		out.TargetBranch = out.TargetBranches[0]
+	}
+	return &out
+}
+
+// applyFilter drops merge requests that don't match the filter, it makes filtering uniform across providers
+func (f *Fetcher) applyFilter(mrs []*model.MergeRequest, filter *model.MergeRequestFilter) []*model.MergeRequest {
+	filtered := make([]*model.MergeRequest, 0, len(mrs))
+	for _, mr := range mrs {
+		if filter.Matches(mr) {
+			filtered = append(filtered, mr)
+		}
+	}
+	if skipped := len(mrs) - len(filtered); skipped > 0 {
+		f.log.Debug("skipped merge requests not matching filter", "skipped", skipped)
+	}
+	return filtered
+}
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var _ interfaces.CheckRunReporter = (*Provider)(nil)
+
+// CreateOrUpdateCheckRun creates a new check run or updates an existing one using the Checks API.
+// Note that the Checks API accepts only GitHub App installation tokens, personal tokens will get 403.
+func (p *Provider) CreateOrUpdateCheckRun(ctx context.Context, projectID string, checkRun *model.CheckRun) (int64, error) {
+	if checkRun == nil {
+		return 0, errm.New("check run is nil")
+	}
+
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return 0, errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	var (
+		output      *github.CheckRunOutput
+		detailsURL  *string
+		conclusion  *string
+		completedAt *github.Timestamp
+	)
+	if checkRun.Title != "" || checkRun.Summary != "" {
+		output = &github.CheckRunOutput{
+			Title:   github.String(checkRun.Title),
+			Summary: github.String(checkRun.Summary),
+		}
+	}
+	if checkRun.DetailsURL != "" {
+		detailsURL = github.String(checkRun.DetailsURL)
+	}
+	if checkRun.Status == model.CheckRunStatusCompleted {
+		conclusion = github.String(string(checkRun.Conclusion))
+		completedAt = &github.Timestamp{Time: time.Now()}
+	}
+
+	if checkRun.ID == 0 {
+		created, _, err := p.client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+			Name:        checkRun.Name,
+			HeadSHA:     checkRun.HeadSHA,
+			DetailsURL:  detailsURL,
+			Status:      github.String(string(checkRun.Status)),
+			Conclusion:  conclusion,
+			StartedAt:   &github.Timestamp{Time: time.Now()},
+			CompletedAt: completedAt,
+			Output:      output,
+		})
+		if err != nil {
+			return 0, wrapError(err, "failed to create check run")
+		}
+		return created.GetID(), nil
+	}
+
+	updated, _, err := p.client.Checks.UpdateCheckRun(ctx, owner, repo, checkRun.ID, github.UpdateCheckRunOptions{
+		Name:        checkRun.Name,
+		DetailsURL:  detailsURL,
+		Status:      github.String(string(checkRun.Status)),
+		Conclusion:  conclusion,
+		CompletedAt: completedAt,
+		Output:      output,
+	})
+	if err != nil {
+		return 0, wrapError(err, "failed to update check run")
+	}
+
+	return updated.GetID(), nil
+}
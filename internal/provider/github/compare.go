@@ -0,0 +1,167 @@
+package github
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var (
+	_ interfaces.CommitComparer  = (*Provider)(nil)
+	_ interfaces.CommitReader    = (*Provider)(nil)
+	_ interfaces.CommitCommenter = (*Provider)(nil)
+)
+
+// GetMergeBase returns the SHA of the merge base commit of two commits
+func (p *Provider) GetMergeBase(ctx context.Context, projectID, first, second string) (string, error) {
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return "", errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	comparison, _, err := p.client.Repositories.CompareCommits(ctx, owner, repo, first, second, nil)
+	if err != nil {
+		return "", wrapError(err, "failed to compare commits")
+	}
+
+	sha := comparison.GetMergeBaseCommit().GetSHA()
+	if sha == "" {
+		return "", errm.New("commits have no merge base")
+	}
+	return sha, nil
+}
+
+// CompareCommits returns files changed in the head commit since the base one,
+// GitHub returns at most 300 files of a comparison
+func (p *Provider) CompareCommits(ctx context.Context, projectID, base, head string) ([]*model.FileDiff, error) {
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	comparison, _, err := p.client.Repositories.CompareCommits(ctx, owner, repo, base, head, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to compare commits")
+	}
+
+	return convertCommitFiles(comparison.Files), nil
+}
+
+// GetMergeRequestCommits returns commits of the pull request from the oldest, GitHub lists at most 250 commits
+func (p *Provider) GetMergeRequestCommits(ctx context.Context, projectID string, mrIID int) ([]*model.Commit, error) {
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	opts := &github.ListOptions{PerPage: 100}
+	var commits []*model.Commit
+
+	for {
+		page, resp, err := p.client.PullRequests.ListCommits(ctx, owner, repo, mrIID, opts)
+		if err != nil {
+			return nil, wrapError(err, "failed to list pull request commits")
+		}
+
+		for _, commit := range page {
+			commits = append(commits, convertCommit(commit))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return commits, nil
+}
+
+// GetCommit returns the commit with its first parent
+func (p *Provider) GetCommit(ctx context.Context, projectID, sha string) (*model.Commit, error) {
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	commit, _, err := p.client.Repositories.GetCommit(ctx, owner, repo, sha, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to get commit")
+	}
+
+	return convertCommit(commit), nil
+}
+
+// convertCommit converts a commit of a pull request or of the repository
+func convertCommit(commit *github.RepositoryCommit) *model.Commit {
+	modelCommit := &model.Commit{
+		SHA:       commit.GetSHA(),
+		Message:   commit.GetCommit().GetMessage(),
+		CreatedAt: commit.GetCommit().GetAuthor().GetDate().Time,
+		Author: model.User{
+			ID:       strconv.FormatInt(commit.GetAuthor().GetID(), 10),
+			Username: commit.GetAuthor().GetLogin(),
+			Name:     commit.GetCommit().GetAuthor().GetName(),
+		},
+	}
+	if len(commit.Parents) > 0 {
+		modelCommit.ParentSHA = commit.Parents[0].GetSHA()
+	}
+	return modelCommit
+}
+
+// GetCommitDiffs returns files changed in the commit, GitHub returns at most 300 files of a commit
+func (p *Provider) GetCommitDiffs(ctx context.Context, projectID, sha string) ([]*model.FileDiff, error) {
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	commit, _, err := p.client.Repositories.GetCommit(ctx, owner, repo, sha, nil)
+	if err != nil {
+		return nil, wrapError(err, "failed to get commit")
+	}
+
+	return convertCommitFiles(commit.Files), nil
+}
+
+// CreateCommitComment creates a comment of the commit, inline comments are placed at their position in the commit diff
+func (p *Provider) CreateCommitComment(ctx context.Context, projectID, sha string, comment *model.Comment) error {
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	body := comment.FullBody()
+	repoComment := &github.RepositoryComment{Body: &body}
+	if comment.Type == model.CommentTypeInline && comment.FilePath != "" {
+		// Commit comments are placed by the position in the diff, lines out of the diff have no position
+		if comment.Position <= 0 {
+			return errm.Wrap(model.ErrNotSupported, "line is not in the commit diff", "file", comment.FilePath, "line", comment.Line)
+		}
+		repoComment.Path = &comment.FilePath
+		repoComment.Position = &comment.Position
+	}
+
+	_, _, err := p.client.Repositories.CreateComment(ctx, owner, repo, sha, repoComment)
+	if err != nil {
+		return wrapError(err, "failed to create commit comment")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+// wrapError wraps the error of the GitHub API call classified by the response status,
+// primary and secondary rate limits are both reported as model.ErrRateLimited
+func wrapError(err error, msg string) error {
+	var (
+		rateLimitErr  *github.RateLimitError
+		abuseLimitErr *github.AbuseRateLimitError
+		responseErr   *github.ErrorResponse
+	)
+	switch {
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseLimitErr):
+		err = &model.ProviderError{Class: model.ErrRateLimited, Status: http.StatusForbidden, Err: err}
+	case errors.As(err, &responseErr) && responseErr.Response != nil:
+		err = model.NewProviderError(responseErr.Response.StatusCode, err)
+	}
+	return errm.Wrap(err, msg)
+}
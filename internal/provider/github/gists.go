@@ -0,0 +1,29 @@
+package github
+
+import (
+	"context"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+const gistFilename = "codry-review.md"
+
+var _ interfaces.DocumentPublisher = (*Provider)(nil)
+
+// PublishDocument creates a secret gist with the markdown document and returns its URL.
+// Note that gists can't be created with GitHub App installation tokens, a personal token is required.
+func (p *Provider) PublishDocument(ctx context.Context, title, content string) (string, error) {
+	gist, _, err := p.client.Gists.Create(ctx, &github.Gist{
+		Description: github.String(title),
+		Public:      github.Bool(false),
+		Files: map[github.GistFilename]github.GistFile{
+			gistFilename: {Content: github.String(content)},
+		},
+	})
+	if err != nil {
+		return "", wrapError(err, "failed to create gist")
+	}
+
+	return gist.GetHTMLURL(), nil
+}
@@ -0,0 +1,43 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var _ interfaces.LabelManager = (*Provider)(nil)
+
+// AddLabel adds the label to the pull request with the issues API, GitHub creates a missing label
+func (p *Provider) AddLabel(ctx context.Context, projectID string, mrIID int, label string) error {
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+
+	_, _, err := p.client.Issues.AddLabelsToIssue(ctx, parts[0], parts[1], mrIID, []string{label})
+	if err != nil {
+		return wrapError(err, "failed to add label")
+	}
+	return nil
+}
+
+// RemoveLabel removes the label from the pull request, GitHub responds with not found if it has no such label
+func (p *Provider) RemoveLabel(ctx context.Context, projectID string, mrIID int, label string) error {
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+
+	_, err := p.client.Issues.RemoveLabelForIssue(ctx, parts[0], parts[1], mrIID, label)
+	if err != nil {
+		if err = wrapError(err, "failed to remove label"); errm.Is(err, model.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		remove     bool
+		projectID  string
+		status     int
+		wantMethod string
+		wantPath   string
+		wantBody   string
+		wantErr    bool
+	}{
+		{
+			name:       "add",
+			projectID:  "owner/repo",
+			status:     http.StatusOK,
+			wantMethod: http.MethodPost,
+			wantPath:   "/api/v3/repos/owner/repo/issues/7/labels",
+			wantBody:   `["codry:approved"]` + "\n",
+		},
+		{
+			name:       "add fails",
+			projectID:  "owner/repo",
+			status:     http.StatusForbidden,
+			wantMethod: http.MethodPost,
+			wantPath:   "/api/v3/repos/owner/repo/issues/7/labels",
+			wantBody:   `["codry:approved"]` + "\n",
+			wantErr:    true,
+		},
+		{
+			name:       "remove",
+			remove:     true,
+			projectID:  "owner/repo",
+			status:     http.StatusOK,
+			wantMethod: http.MethodDelete,
+			wantPath:   "/api/v3/repos/owner/repo/issues/7/labels/codry:approved",
+		},
+		{
+			name:       "remove missing label",
+			remove:     true,
+			projectID:  "owner/repo",
+			status:     http.StatusNotFound,
+			wantMethod: http.MethodDelete,
+			wantPath:   "/api/v3/repos/owner/repo/issues/7/labels/codry:approved",
+		},
+		{
+			name:       "remove fails",
+			remove:     true,
+			projectID:  "owner/repo",
+			status:     http.StatusInternalServerError,
+			wantMethod: http.MethodDelete,
+			wantPath:   "/api/v3/repos/owner/repo/issues/7/labels/codry:approved",
+			wantErr:    true,
+		},
+		{
+			name:      "invalid project ID",
+			projectID: "repo",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				method, path, body = r.Method, r.URL.Path, string(data)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					_, _ = w.Write([]byte(`[]`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"message": "failed"}`))
+			}))
+			defer server.Close()
+
+			provider, err := New(model.ProviderConfig{Token: "token", BaseURL: server.URL + "/"})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if tt.remove {
+				err = provider.RemoveLabel(context.Background(), tt.projectID, 7, "codry:approved")
+			} else {
+				err = provider.AddLabel(context.Background(), tt.projectID, 7, "codry:approved")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if method != tt.wantMethod || path != tt.wantPath || body != tt.wantBody {
+				t.Errorf("request = %s %s %q, want %s %s %q", method, path, body, tt.wantMethod, tt.wantPath, tt.wantBody)
+			}
+		})
+	}
+}
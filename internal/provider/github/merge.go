@@ -0,0 +1,44 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var _ interfaces.MergeResultReader = (*Provider)(nil)
+
+// GetMergeResultDiffs returns files changed by the test merge commit of the pull request (refs/pull/N/merge)
+// against its first parent, the head of the base branch it was merged with; GitHub removes the ref
+// if the pull request has conflicts and updates it asynchronously after pushes, so a ref of another head is ignored
+func (p *Provider) GetMergeResultDiffs(ctx context.Context, projectID string, mrIID int, headSHA string) ([]*model.FileDiff, error) {
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	ref, _, err := p.client.Git.GetRef(ctx, owner, repo, fmt.Sprintf("pull/%d/merge", mrIID))
+	if err != nil {
+		return nil, wrapError(err, "failed to get merge ref")
+	}
+	mergeSHA := ref.GetObject().GetSHA()
+
+	commit, _, err := p.client.Git.GetCommit(ctx, owner, repo, mergeSHA)
+	if err != nil {
+		return nil, wrapError(err, "failed to get merge commit")
+	}
+	if len(commit.Parents) != 2 {
+		return nil, errm.Errorf("merge commit %s has %d parents", mergeSHA, len(commit.Parents))
+	}
+	if headSHA != "" && commit.Parents[1].GetSHA() != headSHA {
+		return nil, errm.Wrap(model.ErrNotFound, "merge ref is outdated", "merge_head", commit.Parents[1].GetSHA(), "head", headSHA)
+	}
+
+	return p.CompareCommits(ctx, projectID, commit.Parents[0].GetSHA(), mergeSHA)
+}
@@ -6,10 +6,11 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"regexp"
+	"net/http"
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/go-github/v57/github"
@@ -20,7 +21,10 @@ import (
 	"golang.org/x/oauth2"
 )
 
-var _ interfaces.CodeProvider = (*Provider)(nil)
+var (
+	_ interfaces.CodeProvider  = (*Provider)(nil)
+	_ interfaces.BotIdentifier = (*Provider)(nil)
+)
 
 const (
 	defaultBaseURL = "https://github.com"
@@ -31,6 +35,9 @@ type Provider struct {
 	client *github.Client
 	config model.ProviderConfig
 	logger logze.Logger
+
+	// bot is the account of the token, it is resolved when credentials are checked
+	bot atomic.Pointer[model.User]
 }
 
 // New creates a new GitHub provider
@@ -44,17 +51,26 @@ func New(config model.ProviderConfig) (*Provider, error) {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: config.Token},
 	)
-	tc := oauth2.NewClient(context.Background(), ts)
+	ctx := context.Background()
+	// OAuth2 client is layered over the configured transport, e.g. with a custom CA
+	if config.Transport != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: config.Transport})
+	}
+	tc := oauth2.NewClient(ctx, ts)
 
 	// Create GitHub client
 	client := github.NewClient(tc)
 
-	// Set base URL if provided (for GitHub Enterprise)
+	// Set base and upload URLs if provided (for GitHub Enterprise)
 	if config.BaseURL != "" && config.BaseURL != defaultBaseURL {
+		uploadURL := config.UploadURL
+		if uploadURL == "" {
+			uploadURL = config.BaseURL
+		}
 		var err error
-		client, err = github.NewClient(tc).WithEnterpriseURLs(config.BaseURL, config.BaseURL)
+		client, err = client.WithEnterpriseURLs(config.BaseURL, uploadURL)
 		if err != nil {
-			return nil, errm.Wrap(err, "failed to create Git	Hub Enterprise client")
+			return nil, errm.Wrap(err, "failed to create GitHub Enterprise client", "base_url", config.BaseURL, "upload_url", uploadURL)
 		}
 	}
 
@@ -65,6 +81,58 @@ func New(config model.ProviderConfig) (*Provider, error) {
 	}, nil
 }
 
+// ValidateCredentials checks the token by getting the authenticated user, installation tokens of GitHub Apps
+// can't read the user and are checked by the rate limit endpoint, which accepts any valid token; only 401
+// means the token is invalid, rate limits and other errors are transient
+func (p *Provider) ValidateCredentials(ctx context.Context) error {
+	user, resp, err := p.client.Users.Get(ctx, "")
+	if err == nil {
+		p.setBotUser(model.User{ID: strconv.FormatInt(user.GetID(), 10), Username: user.GetLogin(), Name: user.GetName()})
+		return nil
+	}
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		return errm.Wrap(model.ErrInvalidCredentials, "GitHub rejected token", "status", resp.StatusCode)
+	}
+	err = wrapError(err, "failed to get authenticated user")
+	if errm.Is(err, model.ErrRateLimited) || !errm.Is(err, model.ErrForbidden) {
+		return err
+	}
+
+	_, resp, err = p.client.RateLimit.Get(ctx)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return errm.Wrap(model.ErrInvalidCredentials, "GitHub rejected token", "status", resp.StatusCode)
+		}
+		return wrapError(err, "failed to get rate limits")
+	}
+	return nil
+}
+
+// BotUser returns the account of the token resolved by ValidateCredentials, ok is false if it is not resolved yet
+func (p *Provider) BotUser() (model.User, bool) {
+	if bot := p.bot.Load(); bot != nil {
+		return *bot, true
+	}
+	return model.User{}, false
+}
+
+// setBotUser stores the account of the token, the change of the account is logged once
+func (p *Provider) setBotUser(bot model.User) {
+	if old := p.bot.Swap(&bot); old != nil && *old == bot {
+		return
+	}
+	p.logger.Info("resolved bot account", "id", bot.ID, "username", bot.Username)
+	if p.config.BotUsername != "" && p.config.BotUsername != bot.Username {
+		p.logger.Warn("configured bot username differs from the token account, the account ID is used to recognize the bot",
+			"bot_username", p.config.BotUsername, "username", bot.Username)
+	}
+}
+
+// isBot checks if the user is the bot by the resolved account ID, the username is a fallback
+func (p *Provider) isBot(user *model.User) bool {
+	return user.IsBot(p.bot.Load(), p.config.BotUsername)
+}
+
 // ValidateWebhook validates the GitHub webhook signature
 func (p *Provider) ValidateWebhook(payload []byte, signature string) error {
 	if p.config.WebhookSecret == "" {
@@ -98,6 +166,10 @@ func (p *Provider) ParseWebhookEvent(payload []byte) (*model.CodeEvent, error) {
 	if err := json.Unmarshal(payload, &githubPayload); err != nil {
 		return nil, errm.Wrap(err, "failed to parse GitHub webhook payload")
 	}
+	if githubPayload.PullRequest.Number == 0 {
+		// Pings, installations, issue labels and other events of the app have no pull request
+		return nil, errm.Wrap(model.ErrIgnoredEvent, "payload has no pull request", "action", githubPayload.Action)
+	}
 
 	// Convert reviewers
 	var reviewers []model.User
@@ -109,6 +181,11 @@ func (p *Provider) ParseWebhookEvent(payload []byte) (*model.CodeEvent, error) {
 		})
 	}
 
+	labels := make([]string, 0, len(githubPayload.PullRequest.Labels))
+	for _, label := range githubPayload.PullRequest.Labels {
+		labels = append(labels, label.Name)
+	}
+
 	event := &model.CodeEvent{
 		Type:      "pull_request",
 		Action:    githubPayload.Action,
@@ -134,6 +211,7 @@ func (p *Provider) ParseWebhookEvent(payload []byte) (*model.CodeEvent, error) {
 				Name:     githubPayload.PullRequest.User.Name,
 			},
 			Reviewers: reviewers,
+			Labels:    labels,
 		},
 	}
 
@@ -152,7 +230,7 @@ func (p *Provider) GetMergeRequest(ctx context.Context, projectID string, mrIID
 	// Get pull request
 	pr, _, err := p.client.PullRequests.Get(ctx, owner, repo, mrIID)
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to get pull request from GitHub")
+		return nil, wrapError(err, "failed to get pull request from GitHub")
 	}
 
 	// Get requested reviewers
@@ -183,6 +261,7 @@ func (p *Provider) GetMergeRequest(ctx context.Context, projectID string, mrIID
 			Name:     pr.User.GetName(),
 		},
 		Reviewers: reviewers,
+		Labels:    convertLabels(pr.Labels),
 		CreatedAt: pr.GetCreatedAt().Time,
 		UpdatedAt: pr.GetUpdatedAt().Time,
 	}, nil
@@ -204,7 +283,7 @@ func (p *Provider) GetMergeRequestDiffs(ctx context.Context, projectID string, m
 	for {
 		files, resp, err := p.client.PullRequests.ListFiles(ctx, owner, repo, mrIID, opts)
 		if err != nil {
-			return nil, errm.Wrap(err, "failed to list pull request files")
+			return nil, wrapError(err, "failed to list pull request files")
 		}
 
 		allFiles = append(allFiles, files...)
@@ -215,17 +294,26 @@ func (p *Provider) GetMergeRequestDiffs(ctx context.Context, projectID string, m
 		opts.Page = resp.NextPage
 	}
 
-	// Convert to our models
+	return convertCommitFiles(allFiles), nil
+}
+
+// convertCommitFiles converts changed files of a pull request or a comparison to file diffs
+func convertCommitFiles(files []*github.CommitFile) []*model.FileDiff {
 	var fileDiffs []*model.FileDiff
-	for _, file := range allFiles {
+	for _, file := range files {
+		// GitHub omits the patch of binary files and of files too large to diff, only textual changes have
+		// counted lines; renamed files without changes have neither
+		noPatch := file.GetPatch() == "" && file.GetStatus() != "removed"
 		fileDiff := &model.FileDiff{
-			OldPath:   file.GetPreviousFilename(),
-			NewPath:   file.GetFilename(),
-			Diff:      file.GetPatch(),
-			IsNew:     file.GetStatus() == "added",
-			IsDeleted: file.GetStatus() == "removed",
-			IsRenamed: file.GetStatus() == "renamed",
-			IsBinary:  file.GetPatch() == "" && file.GetStatus() != "removed" && file.GetStatus() != "added",
+			OldPath:    file.GetPreviousFilename(),
+			NewPath:    file.GetFilename(),
+			Diff:       file.GetPatch(),
+			Hunks:      model.ParseHunks(file.GetPatch()),
+			IsNew:      file.GetStatus() == "added",
+			IsDeleted:  file.GetStatus() == "removed",
+			IsRenamed:  file.GetStatus() == "renamed",
+			IsBinary:   noPatch && file.GetChanges() == 0 && file.GetStatus() != "renamed",
+			IsTooLarge: noPatch && file.GetChanges() > 0,
 		}
 
 		// Handle renamed files
@@ -236,7 +324,7 @@ func (p *Provider) GetMergeRequestDiffs(ctx context.Context, projectID string, m
 		fileDiffs = append(fileDiffs, fileDiff)
 	}
 
-	return fileDiffs, nil
+	return fileDiffs
 }
 
 // UpdateMergeRequestDescription updates the description of a pull request
@@ -255,7 +343,7 @@ func (p *Provider) UpdateMergeRequestDescription(ctx context.Context, projectID
 
 	_, _, err := p.client.PullRequests.Edit(ctx, owner, repo, mrIID, updateRequest)
 	if err != nil {
-		return errm.Wrap(err, "failed to update pull request description")
+		return wrapError(err, "failed to update pull request description")
 	}
 
 	return nil
@@ -271,7 +359,7 @@ func (p *Provider) CreateComment(ctx context.Context, projectID string, mrIID in
 	owner, repo := parts[0], parts[1]
 
 	// Check if this is a line-specific comment
-	if comment.Type == model.CommentTypeInline && comment.FilePath != "" && comment.Line > 0 {
+	if comment.Type == model.CommentTypeInline && comment.FilePath != "" && (comment.Line > 0 || comment.OldLine > 0) {
 		return p.createPositionedComment(ctx, owner, repo, mrIID, comment)
 	}
 
@@ -283,7 +371,7 @@ func (p *Provider) createPositionedComment(ctx context.Context, owner, repo stri
 	// Get the pull request to obtain the commit SHA
 	pr, _, err := p.client.PullRequests.Get(ctx, owner, repo, mrIID)
 	if err != nil {
-		return errm.Wrap(err, "failed to get pull request for commit SHA")
+		return wrapError(err, "failed to get pull request for commit SHA")
 	}
 
 	head := pr.GetHead()
@@ -297,90 +385,57 @@ func (p *Provider) createPositionedComment(ctx context.Context, owner, repo stri
 	}
 
 	// Create pull request review comment with proper GitHub API format
+	body := comment.FullBody()
 	reviewComment := &github.PullRequestComment{
-		Body:     &comment.Body,
+		Body:     &body,
 		Path:     &comment.FilePath,
 		CommitID: &commitID,
 	}
 
-	// Handle range comments vs single line comments
-	if comment.Type == model.CommentTypeReview || comment.Type == model.CommentTypeInline {
-		// Check if this is a range comment by parsing the comment body
-		if p.isRangeComment(comment.Body) {
-			startLine, endLine := p.extractLineRange(comment.Body)
-			if startLine > 0 && endLine > startLine {
-				// GitHub range comment format
-				side := "RIGHT" // Comments on new lines are on the RIGHT side
-				reviewComment.StartLine = &startLine
-				reviewComment.Line = &endLine
-				reviewComment.Side = &side
-
-			} else {
-				// Fall back to single line
-				p.setSingleLineComment(reviewComment, comment)
-			}
-		} else {
-			// Single line comment
-			p.setSingleLineComment(reviewComment, comment)
-		}
-	} else {
-		// Regular single line comment
-		p.setSingleLineComment(reviewComment, comment)
-	}
+	setCommentLines(reviewComment, comment)
 
 	_, _, err = p.client.PullRequests.CreateComment(ctx, owner, repo, mrIID, reviewComment)
 	if err != nil {
-		return errm.Wrap(err, "failed to create positioned comment")
+		return wrapError(err, "failed to create positioned comment")
 	}
 
 	return nil
 }
 
-// setSingleLineComment sets up a single line comment
-func (p *Provider) setSingleLineComment(reviewComment *github.PullRequestComment, comment *model.Comment) {
-	if comment.Line > 0 {
-		line := comment.Line
-		side := "RIGHT" // Comments on new lines are on the RIGHT side
-		reviewComment.Line = &line
-		reviewComment.Side = &side
+// setCommentLines places the comment on the RIGHT side at its new line, a multi-line comment spans from
+// its start line, a comment of a removed line without a new one is placed on the LEFT side at its old line;
+// ranges must be within one hunk of the diff, they are cut by the reviewer
+func setCommentLines(reviewComment *github.PullRequestComment, comment *model.Comment) {
+	switch {
+	case comment.StartLine > 0 && comment.EndLine > comment.StartLine:
+		reviewComment.StartLine, reviewComment.StartSide = github.Int(comment.StartLine), github.String("RIGHT")
+		reviewComment.Line, reviewComment.Side = github.Int(comment.EndLine), github.String("RIGHT")
+	case comment.Line > 0:
+		reviewComment.Line, reviewComment.Side = github.Int(comment.Line), github.String("RIGHT")
+	case comment.OldLine > 0:
+		reviewComment.Line, reviewComment.Side = github.Int(comment.OldLine), github.String("LEFT")
 	}
-
-	// Use position as fallback
-	if comment.Position > 0 {
-		reviewComment.Position = &comment.Position
-	}
-}
-
-// isRangeComment checks if a comment body indicates it's a range comment
-func (p *Provider) isRangeComment(body string) bool {
-	return strings.Contains(body, "*(lines ") && strings.Contains(body, "-")
 }
 
-// extractLineRange extracts start and end line numbers from comment body
-func (p *Provider) extractLineRange(body string) (int, int) {
-	// Look for pattern: *(lines 19-32)*
-	re := regexp.MustCompile(`\*\(lines (\d+)-(\d+)\)\*`)
-	matches := re.FindStringSubmatch(body)
-
-	if len(matches) >= 3 {
-		startLine, _ := strconv.Atoi(matches[1])
-		endLine, _ := strconv.Atoi(matches[2])
-		return startLine, endLine
+// rangeEndLine returns the last line of the multi-line review comment, 0 for a single line
+func rangeEndLine(comment *github.PullRequestComment) int {
+	if comment.StartLine == nil {
+		return 0
 	}
-
-	return 0, 0
+	return comment.GetLine()
 }
 
 // createRegularComment creates a regular (non-positioned) issue comment
 func (p *Provider) createRegularComment(ctx context.Context, owner, repo string, mrIID int, comment *model.Comment) error {
 	// Create issue comment (GitHub treats PR comments as issue comments)
+	body := comment.FullBody()
 	githubComment := &github.IssueComment{
-		Body: &comment.Body,
+		Body: &body,
 	}
 
 	_, _, err := p.client.Issues.CreateComment(ctx, owner, repo, mrIID, githubComment)
 	if err != nil {
-		return errm.Wrap(err, "failed to create pull request comment")
+		return wrapError(err, "failed to create pull request comment")
 	}
 
 	return nil
@@ -389,6 +444,9 @@ func (p *Provider) createRegularComment(ctx context.Context, owner, repo string,
 // IsMergeRequestEvent determines if a webhook event is a merge request event that should be processed
 func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 	// Only process pull request events
+	if event == nil || event.MergeRequest == nil {
+		return false
+	}
 	if event.Type != "pull_request" {
 		p.logger.Debug("ignoring non-pull request event", "event_type", event.Type)
 		return false
@@ -396,11 +454,12 @@ func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 
 	// Check for relevant actions
 	relevantActions := []string{
-		"opened",           // When PR is opened
-		"reopened",         // When PR is reopened
-		"synchronize",      // When PR is updated with new commits
-		"review_requested", // When reviewer is added
-		"ready_for_review", // When PR is marked ready for review
+		"opened",                   // When PR is opened
+		"reopened",                 // When PR is reopened
+		"synchronize",              // When PR is updated with new commits
+		"review_requested",         // When reviewer is added
+		model.ActionReadyForReview, // When PR is marked ready for review
+		"labeled",                  // When label is added, e.g. the one required for review
 	}
 
 	isRelevantAction := slices.Contains(relevantActions, event.Action)
@@ -410,7 +469,7 @@ func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 	}
 
 	// Don't process events from the bot itself to avoid loops
-	if event.User.Username == p.config.BotUsername {
+	if p.isBot(event.User) {
 		return false
 	}
 
@@ -421,7 +480,7 @@ func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 		// Check if the bot was added as a reviewer
 		botIsReviewer := false
 		for _, reviewer := range event.MergeRequest.Reviewers {
-			if reviewer.Username == p.config.BotUsername {
+			if p.isBot(&reviewer) {
 				botIsReviewer = true
 				break
 			}
@@ -472,7 +531,7 @@ func (p *Provider) ListMergeRequests(ctx context.Context, projectID string, filt
 	// GitHub doesn't support author filter in list API, so we'll filter afterward
 	prs, _, err := p.client.PullRequests.List(ctx, owner, repo, opts)
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to list pull requests")
+		return nil, wrapError(err, "failed to list pull requests")
 	}
 
 	var result []*model.MergeRequest
@@ -522,6 +581,7 @@ func (p *Provider) ListMergeRequests(ctx context.Context, projectID string, filt
 				Name:     pr.User.GetName(),
 			},
 			Reviewers: reviewers,
+			Labels:    convertLabels(pr.Labels),
 			CreatedAt: pr.GetCreatedAt().Time,
 			UpdatedAt: pr.GetUpdatedAt().Time,
 		}
@@ -531,6 +591,15 @@ func (p *Provider) ListMergeRequests(ctx context.Context, projectID string, filt
 	return result, nil
 }
 
+// convertLabels returns names of pull request labels
+func convertLabels(labels []*github.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
+
 // GetMergeRequestUpdates retrieves pull requests updated since a specific time
 func (p *Provider) GetMergeRequestUpdates(ctx context.Context, projectID string, since time.Time) ([]*model.MergeRequest, error) {
 	filter := &model.MergeRequestFilter{
@@ -556,7 +625,7 @@ func (p *Provider) GetFileContent(ctx context.Context, projectID, filePath, comm
 		Ref: commitSHA,
 	})
 	if err != nil {
-		return "", errm.Wrap(err, "failed to get file content from GitHub")
+		return "", wrapError(err, "failed to get file content from GitHub")
 	}
 
 	if resp.StatusCode != 200 {
@@ -590,14 +659,16 @@ func (p *Provider) GetComments(ctx context.Context, projectID string, mrIID int)
 	// Get issue comments (general PR comments)
 	issueComments, _, err := p.client.Issues.ListComments(ctx, owner, repo, mrIID, &github.IssueListCommentsOptions{})
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to get issue comments from GitHub")
+		return nil, wrapError(err, "failed to get issue comments from GitHub")
 	}
 
 	for _, comment := range issueComments {
+		body, footer := model.SplitCommentFooter(comment.GetBody())
 		allComments = append(allComments, &model.Comment{
-			ID:   strconv.FormatInt(comment.GetID(), 10),
-			Body: comment.GetBody(),
-			Type: model.CommentTypeGeneral,
+			ID:     strconv.FormatInt(comment.GetID(), 10),
+			Body:   body,
+			Footer: footer,
+			Type:   model.CommentTypeGeneral,
 			Author: model.User{
 				ID:       strconv.FormatInt(comment.User.GetID(), 10),
 				Username: comment.User.GetLogin(),
@@ -611,17 +682,24 @@ func (p *Provider) GetComments(ctx context.Context, projectID string, mrIID int)
 	// Get review comments (line-specific comments)
 	reviewComments, _, err := p.client.PullRequests.ListComments(ctx, owner, repo, mrIID, &github.PullRequestListCommentsOptions{})
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to get review comments from GitHub")
+		return nil, wrapError(err, "failed to get review comments from GitHub")
 	}
 
 	for _, comment := range reviewComments {
+		body, footer := model.SplitCommentFooter(comment.GetBody())
 		allComments = append(allComments, &model.Comment{
-			ID:       strconv.FormatInt(comment.GetID(), 10),
-			Body:     comment.GetBody(),
-			FilePath: comment.GetPath(),
-			Line:     comment.GetLine(),
-			Position: comment.GetPosition(),
-			Type:     model.CommentTypeInline,
+			ID:        strconv.FormatInt(comment.GetID(), 10),
+			Body:      body,
+			Footer:    footer,
+			FilePath:  comment.GetPath(),
+			Line:      comment.GetLine(),
+			StartLine: comment.GetStartLine(),
+			EndLine:   rangeEndLine(comment),
+			Position:  comment.GetPosition(),
+			Type:      model.CommentTypeInline,
+			CommitSHA: comment.GetCommitID(),
+			// GitHub drops the position of a comment whose line was changed by later commits
+			Outdated: comment.Position == nil,
 			Author: model.User{
 				ID:       strconv.FormatInt(comment.User.GetID(), 10),
 				Username: comment.User.GetLogin(),
@@ -662,7 +740,7 @@ func (p *Provider) UpdateComment(ctx context.Context, projectID string, mrIID in
 		Body: &newBody,
 	})
 	if err != nil {
-		return errm.Wrap(err, "failed to update comment")
+		return wrapError(err, "failed to update comment")
 	}
 
 	return nil
@@ -0,0 +1,247 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+func newTestProvider(t *testing.T) *Provider {
+	t.Helper()
+	provider, err := New(model.ProviderConfig{Token: "token"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return provider
+}
+
+func TestParseWebhookEventIgnored(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"ping", `{"zen": "Keep it logically awesome.", "hook_id": 1, "hook": {"type": "Repository"}, "repository": {"full_name": "owner/repo"}}`},
+		{"installation", `{"action": "created", "installation": {"id": 1}, "sender": {"login": "admin", "id": 2}}`},
+		{"issue label", `{"action": "labeled", "issue": {"number": 3}, "label": {"name": "bug"}}`},
+		{"empty object", `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := newTestProvider(t).ParseWebhookEvent([]byte(tt.payload))
+			if !errm.Is(err, model.ErrIgnoredEvent) {
+				t.Errorf("ParseWebhookEvent() error = %v, want %v", err, model.ErrIgnoredEvent)
+			}
+			if event != nil {
+				t.Errorf("ParseWebhookEvent() event = %+v, want nil", event)
+			}
+		})
+	}
+}
+
+func TestParseWebhookEventPullRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     string
+		wantAction  string
+		wantIID     int
+		wantAuthor  string
+		wantProcess bool
+	}{
+		{
+			name:        "only required fields",
+			payload:     `{"action": "opened", "pull_request": {"number": 7}}`,
+			wantAction:  "opened",
+			wantIID:     7,
+			wantProcess: true,
+		},
+		{
+			name:        "null optional fields",
+			payload:     `{"action": "synchronize", "sender": null, "pull_request": {"number": 8, "user": null, "requested_reviewers": null, "labels": null, "head": null}}`,
+			wantAction:  "synchronize",
+			wantIID:     8,
+			wantProcess: true,
+		},
+		{
+			name:       "review requested without reviewers",
+			payload:    `{"action": "review_requested", "pull_request": {"number": 9, "user": {"login": "dev", "id": 1}}}`,
+			wantAction: "review_requested",
+			wantIID:    9,
+			wantAuthor: "dev",
+		},
+		{
+			name:       "closed",
+			payload:    `{"action": "closed", "pull_request": {"number": 10}}`,
+			wantAction: "closed",
+			wantIID:    10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestProvider(t)
+			event, err := provider.ParseWebhookEvent([]byte(tt.payload))
+			if err != nil {
+				t.Fatalf("ParseWebhookEvent() error = %v", err)
+			}
+			if event.Action != tt.wantAction || event.MergeRequest.IID != tt.wantIID || event.MergeRequest.Author.Username != tt.wantAuthor {
+				t.Errorf("ParseWebhookEvent() = action %q, iid %d, author %q, want %q, %d, %q",
+					event.Action, event.MergeRequest.IID, event.MergeRequest.Author.Username, tt.wantAction, tt.wantIID, tt.wantAuthor)
+			}
+			if got := provider.IsMergeRequestEvent(event); got != tt.wantProcess {
+				t.Errorf("IsMergeRequestEvent() = %v, want %v", got, tt.wantProcess)
+			}
+		})
+	}
+}
+
+func TestIsMergeRequestEventIncomplete(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *model.CodeEvent
+		want  bool
+	}{
+		{"nil event", nil, false},
+		{"no merge request", &model.CodeEvent{Type: "pull_request", Action: "opened"}, false},
+		{"no user", &model.CodeEvent{Type: "pull_request", Action: "opened", MergeRequest: &model.MergeRequest{IID: 1}}, true},
+		{"other event type", &model.CodeEvent{Type: "push", Action: "opened", MergeRequest: &model.MergeRequest{IID: 1}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTestProvider(t).IsMergeRequestEvent(tt.event); got != tt.want {
+				t.Errorf("IsMergeRequestEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertCommitFilesWithoutPatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		file           *github.CommitFile
+		wantBinary     bool
+		wantTooLarge   bool
+		wantDiffLength int
+	}{
+		{
+			name:           "textual change",
+			file:           &github.CommitFile{Filename: github.String("main.go"), Status: github.String("modified"), Patch: github.String("@@ -1 +1 @@\n-a\n+b"), Changes: github.Int(2)},
+			wantDiffLength: len("@@ -1 +1 @@\n-a\n+b"),
+		},
+		{
+			name:         "textual file too large to diff",
+			file:         &github.CommitFile{Filename: github.String("gen.go"), Status: github.String("modified"), Changes: github.Int(12000)},
+			wantTooLarge: true,
+		},
+		{
+			name:         "added file too large to diff",
+			file:         &github.CommitFile{Filename: github.String("gen.go"), Status: github.String("added"), Changes: github.Int(9000)},
+			wantTooLarge: true,
+		},
+		{
+			name:       "binary file",
+			file:       &github.CommitFile{Filename: github.String("logo.png"), Status: github.String("modified")},
+			wantBinary: true,
+		},
+		{
+			name: "renamed file without changes",
+			file: &github.CommitFile{Filename: github.String("b.go"), PreviousFilename: github.String("a.go"), Status: github.String("renamed")},
+		},
+		{
+			name: "removed file",
+			file: &github.CommitFile{Filename: github.String("old.go"), Status: github.String("removed"), Changes: github.Int(40)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := convertCommitFiles([]*github.CommitFile{tt.file})
+			if len(diffs) != 1 {
+				t.Fatalf("convertCommitFiles() = %d diffs, want 1", len(diffs))
+			}
+			got := diffs[0]
+			if got.IsBinary != tt.wantBinary || got.IsTooLarge != tt.wantTooLarge || len(got.Diff) != tt.wantDiffLength {
+				t.Errorf("convertCommitFiles() = binary %v, too large %v, diff length %d, want %v, %v, %d",
+					got.IsBinary, got.IsTooLarge, len(got.Diff), tt.wantBinary, tt.wantTooLarge, tt.wantDiffLength)
+			}
+		})
+	}
+}
+
+func TestSetCommentLines(t *testing.T) {
+	tests := []struct {
+		name          string
+		comment       model.Comment
+		wantStartLine int
+		wantStartSide string
+		wantLine      int
+		wantSide      string
+	}{
+		{
+			name:          "multi-line range",
+			comment:       model.Comment{Line: 10, StartLine: 10, EndLine: 14},
+			wantStartLine: 10,
+			wantStartSide: "RIGHT",
+			wantLine:      14,
+			wantSide:      "RIGHT",
+		},
+		{
+			name:     "single line",
+			comment:  model.Comment{Line: 12},
+			wantLine: 12,
+			wantSide: "RIGHT",
+		},
+		{
+			name:     "empty range is a single line",
+			comment:  model.Comment{Line: 12, StartLine: 12, EndLine: 12},
+			wantLine: 12,
+			wantSide: "RIGHT",
+		},
+		{
+			name:     "removed line",
+			comment:  model.Comment{OldLine: 7},
+			wantLine: 7,
+			wantSide: "LEFT",
+		},
+		{
+			name:     "new line is preferred over the old one",
+			comment:  model.Comment{Line: 8, OldLine: 7},
+			wantLine: 8,
+			wantSide: "RIGHT",
+		},
+		{
+			name:    "no lines",
+			comment: model.Comment{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reviewComment github.PullRequestComment
+			setCommentLines(&reviewComment, &tt.comment)
+			if got := reviewComment.GetStartLine(); got != tt.wantStartLine {
+				t.Errorf("StartLine = %d, want %d", got, tt.wantStartLine)
+			}
+			if got := reviewComment.GetStartSide(); got != tt.wantStartSide {
+				t.Errorf("StartSide = %q, want %q", got, tt.wantStartSide)
+			}
+			if got := reviewComment.GetLine(); got != tt.wantLine {
+				t.Errorf("Line = %d, want %d", got, tt.wantLine)
+			}
+			if got := reviewComment.GetSide(); got != tt.wantSide {
+				t.Errorf("Side = %q, want %q", got, tt.wantSide)
+			}
+			// Comments read back from GitHub keep their range
+			wantEndLine := 0
+			if tt.wantStartLine > 0 {
+				wantEndLine = tt.wantLine
+			}
+			if got := rangeEndLine(&reviewComment); got != wantEndLine {
+				t.Errorf("rangeEndLine() = %d, want %d", got, wantEndLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var _ interfaces.ReviewSubmitter = (*Provider)(nil)
+
+// SubmitReview creates a pull request review with the given event (COMMENT or REQUEST_CHANGES)
+func (p *Provider) SubmitReview(ctx context.Context, projectID string, mrIID int, review *model.ReviewSubmission) error {
+	if review == nil {
+		return errm.New("review is nil")
+	}
+
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	request := &github.PullRequestReviewRequest{
+		Body:  github.String(review.Body),
+		Event: github.String(string(review.Event)),
+	}
+	if review.CommitSHA != "" {
+		request.CommitID = github.String(review.CommitSHA)
+	}
+
+	_, _, err := p.client.PullRequests.CreateReview(ctx, owner, repo, mrIID, request)
+	if err != nil {
+		return wrapError(err, "failed to create pull request review")
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var _ interfaces.CommentResolver = (*Provider)(nil)
+
+// reviewThreadsQuery lists review threads of the pull request with IDs of their first comments,
+// REST API doesn't expose threads, so they are resolved with GraphQL
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100) {
+        nodes { id isResolved comments(first: 1) { nodes { databaseId } } }
+      }
+    }
+  }
+}`
+
+const resolveReviewThreadMutation = `mutation($threadId: ID!) {
+  resolveReviewThread(input: {threadId: $threadId}) { thread { id } }
+}`
+
+// ResolveComment resolves the review thread started by the comment, only the first 100 threads
+// of the pull request are searched; general comments return model.ErrNotSupported
+func (p *Provider) ResolveComment(ctx context.Context, projectID string, mrIID int, comment *model.Comment) error {
+	if comment == nil {
+		return errm.New("comment is nil")
+	}
+	if comment.Type != model.CommentTypeInline {
+		return errm.Wrap(model.ErrNotSupported, "general comments are not in review threads")
+	}
+
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	threadID := comment.ThreadID
+	if threadID == "" {
+		var data struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						Nodes []struct {
+							ID         string `json:"id"`
+							IsResolved bool   `json:"isResolved"`
+							Comments   struct {
+								Nodes []struct {
+									DatabaseID int64 `json:"databaseId"`
+								} `json:"nodes"`
+							} `json:"comments"`
+						} `json:"nodes"`
+					} `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}
+		variables := map[string]any{"owner": owner, "repo": repo, "number": mrIID}
+		if err := p.graphQL(ctx, reviewThreadsQuery, variables, &data); err != nil {
+			return errm.Wrap(err, "failed to list review threads")
+		}
+
+		for _, thread := range data.Repository.PullRequest.ReviewThreads.Nodes {
+			if len(thread.Comments.Nodes) == 0 || strconv.FormatInt(thread.Comments.Nodes[0].DatabaseID, 10) != comment.ID {
+				continue
+			}
+			if thread.IsResolved {
+				return nil
+			}
+			threadID = thread.ID
+			break
+		}
+	}
+	if threadID == "" {
+		return errm.Wrap(model.ErrNotSupported, "comment doesn't start a review thread", "comment_id", comment.ID)
+	}
+
+	if err := p.graphQL(ctx, resolveReviewThreadMutation, map[string]any{"threadId": threadID}, nil); err != nil {
+		return errm.Wrap(err, "failed to resolve review thread")
+	}
+	return nil
+}
+
+// graphQL runs the query against the GraphQL endpoint next to the REST API of the client
+// and decodes its data, errors of the response are returned as error
+func (p *Provider) graphQL(ctx context.Context, query string, variables map[string]any, data any) error {
+	// https://api.github.com/graphql for github.com and https://host/api/graphql for GitHub Enterprise
+	endpoint, err := p.client.BaseURL.Parse("../graphql")
+	if err != nil {
+		return errm.Wrap(err, "failed to build GraphQL URL")
+	}
+	req, err := p.client.NewRequest(http.MethodPost, endpoint.String(), map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return errm.Wrap(err, "failed to create GraphQL request")
+	}
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := p.client.Do(ctx, req, &response); err != nil {
+		return wrapError(err, "failed to call GitHub GraphQL API")
+	}
+	if len(response.Errors) > 0 {
+		return errm.Errorf("GitHub GraphQL API error: %s", response.Errors[0].Message)
+	}
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Data, data)
+}
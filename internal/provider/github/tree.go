@@ -0,0 +1,40 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v57/github"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var _ interfaces.FileLister = (*Provider)(nil)
+
+// ListFiles returns paths of files directly in the directory at the ref, GitHub lists at most 1000 entries;
+// symlinks and submodules are skipped because their content is not code of the file
+func (p *Provider) ListFiles(ctx context.Context, projectID, dir, ref string) ([]string, error) {
+	// Parse owner/repo from projectID
+	parts := strings.Split(projectID, "/")
+	if len(parts) != 2 {
+		return nil, errm.New("invalid GitHub project ID format, expected 'owner/repo'")
+	}
+	owner, repo := parts[0], parts[1]
+
+	_, entries, _, err := p.client.Repositories.GetContents(ctx, owner, repo, dir, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		return nil, wrapError(err, "failed to list directory from GitHub")
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		// Submodules are listed as files without download URL for backwards compatibility
+		if entry.GetType() == "file" && entry.GetDownloadURL() != "" {
+			files = append(files, entry.GetPath())
+		}
+	}
+
+	return files, nil
+}
@@ -28,6 +28,9 @@ type githubPayload struct {
 			Login string `json:"login"`
 			Name  string `json:"name"`
 		} `json:"requested_reviewers"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
 	} `json:"pull_request"`
 	Repository struct {
 		ID       int    `json:"id"`
@@ -0,0 +1,203 @@
+package gitlab
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+var (
+	_ interfaces.CommitComparer  = (*Provider)(nil)
+	_ interfaces.CommitReader    = (*Provider)(nil)
+	_ interfaces.CommitCommenter = (*Provider)(nil)
+)
+
+// GetMergeBase returns the SHA of the common ancestor of two commits
+func (p *Provider) GetMergeBase(ctx context.Context, projectID, first, second string) (string, error) {
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return "", errm.Wrap(err, "invalid project ID")
+	}
+
+	commit, _, err := p.client.Repositories.MergeBase(projectIDInt, &gitlab.MergeBaseOptions{
+		Ref: &[]string{first, second},
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return "", errm.Wrap(err, "failed to get merge base")
+	}
+
+	return commit.ID, nil
+}
+
+// CompareCommits returns file diffs of the head commit against the base one
+func (p *Provider) CompareCommits(ctx context.Context, projectID, base, head string) ([]*model.FileDiff, error) {
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return nil, errm.Wrap(err, "invalid project ID")
+	}
+
+	compare, _, err := p.client.Repositories.Compare(projectIDInt, &gitlab.CompareOptions{
+		From: &base,
+		To:   &head,
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to compare commits")
+	}
+
+	return convertDiffs(compare.Diffs), nil
+}
+
+// GetMergeRequestCommits returns commits of the merge request from the oldest
+func (p *Provider) GetMergeRequestCommits(ctx context.Context, projectID string, mrIID int) ([]*model.Commit, error) {
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return nil, errm.Wrap(err, "invalid project ID")
+	}
+
+	var commits []*model.Commit
+	page := 1
+
+	for {
+		opts := &gitlab.GetMergeRequestCommitsOptions{Page: page, PerPage: 100}
+		mrCommits, resp, err := p.client.MergeRequests.GetMergeRequestCommits(projectIDInt, mrIID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, errm.Wrap(err, "failed to list merge request commits")
+		}
+
+		for _, commit := range mrCommits {
+			commits = append(commits, convertCommit(commit))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+
+	// GitLab lists merge request commits from the newest
+	slices.Reverse(commits)
+
+	return commits, nil
+}
+
+// GetCommit returns the commit with its first parent
+func (p *Provider) GetCommit(ctx context.Context, projectID, sha string) (*model.Commit, error) {
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return nil, errm.Wrap(err, "invalid project ID")
+	}
+
+	commit, _, err := p.client.Commits.GetCommit(projectIDInt, sha, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to get commit")
+	}
+
+	return convertCommit(commit), nil
+}
+
+// convertCommit converts a commit of a merge request or of the project
+func convertCommit(commit *gitlab.Commit) *model.Commit {
+	modelCommit := &model.Commit{
+		SHA:     commit.ID,
+		Message: commit.Message,
+		Author:  model.User{Name: commit.AuthorName}, // commits have no GitLab user
+	}
+	if len(commit.ParentIDs) > 0 {
+		modelCommit.ParentSHA = commit.ParentIDs[0]
+	}
+	if commit.CreatedAt != nil {
+		modelCommit.CreatedAt = *commit.CreatedAt
+	}
+	return modelCommit
+}
+
+// GetCommitDiffs returns file diffs of the commit against its first parent
+func (p *Provider) GetCommitDiffs(ctx context.Context, projectID, sha string) ([]*model.FileDiff, error) {
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return nil, errm.Wrap(err, "invalid project ID")
+	}
+
+	var allDiffs []*gitlab.Diff
+	page := 1
+
+	for {
+		opts := &gitlab.GetCommitDiffOptions{ListOptions: gitlab.ListOptions{Page: page, PerPage: 100}}
+		diffs, resp, err := p.client.Commits.GetCommitDiff(projectIDInt, sha, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, errm.Wrap(err, "failed to get commit diff")
+		}
+
+		allDiffs = append(allDiffs, diffs...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+
+	return convertDiffs(allDiffs), nil
+}
+
+// convertDiffs converts diffs of a commit or a comparison to file diffs
+func convertDiffs(diffs []*gitlab.Diff) []*model.FileDiff {
+	fileDiffs := make([]*model.FileDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		isBinary, isTooLarge := classifyDiff(diff.Diff, diff.NewPath, diff.DeletedFile, diff.RenamedFile)
+		fileDiffs = append(fileDiffs, &model.FileDiff{
+			OldPath:    diff.OldPath,
+			NewPath:    diff.NewPath,
+			Diff:       diff.Diff,
+			Hunks:      model.ParseHunks(diff.Diff),
+			IsNew:      diff.NewFile,
+			IsDeleted:  diff.DeletedFile,
+			IsRenamed:  diff.RenamedFile,
+			IsBinary:   isBinary,
+			IsTooLarge: isTooLarge,
+		})
+	}
+	return fileDiffs
+}
+
+// classifyDiff tells binary files from textual ones that GitLab returned without a diff because they are
+// too large or collapsed; renamed files without changes have no diff too, so they are not marked
+func classifyDiff(diff, newPath string, deleted, renamed bool) (isBinary, isTooLarge bool) {
+	switch {
+	case strings.HasPrefix(diff, "Binary files "):
+		return true, false
+	case diff != "" || deleted:
+		return false, false
+	case model.IsBinaryPath(newPath):
+		return true, false
+	}
+	return false, !renamed
+}
+
+// CreateCommitComment creates a comment of the commit, inline comments are placed at their line of the new file
+func (p *Provider) CreateCommitComment(ctx context.Context, projectID, sha string, comment *model.Comment) error {
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return errm.Wrap(err, "invalid project ID")
+	}
+
+	body := comment.FullBody()
+	opts := &gitlab.PostCommitCommentOptions{Note: &body}
+	if comment.Type == model.CommentTypeInline && comment.FilePath != "" && comment.Line > 0 {
+		lineType := "new"
+		opts.Path = &comment.FilePath
+		opts.Line = &comment.Line
+		opts.LineType = &lineType
+	}
+
+	_, _, err = p.client.Commits.PostCommitComment(projectIDInt, sha, opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return errm.Wrap(err, "failed to create commit comment")
+	}
+	return nil
+}
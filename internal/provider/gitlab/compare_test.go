@@ -0,0 +1,31 @@
+package gitlab
+
+import "testing"
+
+func TestClassifyDiff(t *testing.T) {
+	tests := []struct {
+		name         string
+		diff         string
+		newPath      string
+		deleted      bool
+		renamed      bool
+		wantBinary   bool
+		wantTooLarge bool
+	}{
+		{name: "textual change", diff: "@@ -1 +1 @@\n-a\n+b\n", newPath: "main.go"},
+		{name: "textual file too large to diff", newPath: "gen.go", wantTooLarge: true},
+		{name: "binary marker", diff: "Binary files a/data and b/data differ\n", newPath: "data", wantBinary: true},
+		{name: "binary extension", newPath: "assets/logo.png", wantBinary: true},
+		{name: "deleted file", newPath: "old.go", deleted: true},
+		{name: "renamed file without changes", newPath: "b.go", renamed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isBinary, isTooLarge := classifyDiff(tt.diff, tt.newPath, tt.deleted, tt.renamed)
+			if isBinary != tt.wantBinary || isTooLarge != tt.wantTooLarge {
+				t.Errorf("classifyDiff() = %v, %v, want %v, %v", isBinary, isTooLarge, tt.wantBinary, tt.wantTooLarge)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package gitlab
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+var _ interfaces.LabelManager = (*Provider)(nil)
+
+// AddLabel adds the label to the merge request keeping other labels, GitLab creates a missing label
+func (p *Provider) AddLabel(ctx context.Context, projectID string, mrIID int, label string) error {
+	return p.updateLabels(ctx, projectID, mrIID, &gitlab.UpdateMergeRequestOptions{
+		AddLabels: &gitlab.LabelOptions{label},
+	})
+}
+
+// RemoveLabel removes the label from the merge request, GitLab ignores labels the merge request doesn't have
+func (p *Provider) RemoveLabel(ctx context.Context, projectID string, mrIID int, label string) error {
+	return p.updateLabels(ctx, projectID, mrIID, &gitlab.UpdateMergeRequestOptions{
+		RemoveLabels: &gitlab.LabelOptions{label},
+	})
+}
+
+func (p *Provider) updateLabels(ctx context.Context, projectID string, mrIID int, opts *gitlab.UpdateMergeRequestOptions) error {
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return errm.Wrap(err, "invalid project ID")
+	}
+
+	_, _, err = p.client.MergeRequests.UpdateMergeRequest(projectIDInt, mrIID, opts, gitlab.WithContext(ctx))
+	if err != nil {
+		return errm.Wrap(err, "failed to update merge request labels")
+	}
+	return nil
+}
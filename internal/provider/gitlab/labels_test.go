@@ -0,0 +1,94 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		remove     bool
+		projectID  string
+		status     int
+		wantFields map[string]string
+		wantErr    bool
+	}{
+		{
+			name:       "add",
+			projectID:  "42",
+			status:     http.StatusOK,
+			wantFields: map[string]string{"add_labels": "codry:approved"},
+		},
+		{
+			name:       "remove",
+			remove:     true,
+			projectID:  "42",
+			status:     http.StatusOK,
+			wantFields: map[string]string{"remove_labels": "codry:approved"},
+		},
+		{
+			name:       "update fails",
+			projectID:  "42",
+			status:     http.StatusForbidden,
+			wantFields: map[string]string{"add_labels": "codry:approved"},
+			wantErr:    true,
+		},
+		{
+			name:      "invalid project ID",
+			projectID: "group/project",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields map[string]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut || r.URL.Path != "/api/v4/projects/42/merge_requests/7" {
+					t.Errorf("request = %s %s, want PUT /api/v4/projects/42/merge_requests/7", r.Method, r.URL.Path)
+				}
+				data, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(data, &fields); err != nil {
+					t.Errorf("request body %s: %v", data, err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					_, _ = w.Write([]byte(`{"iid": 7}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"message": "failed"}`))
+			}))
+			defer server.Close()
+
+			provider, err := New(model.ProviderConfig{Token: "token", BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if tt.remove {
+				err = provider.RemoveLabel(context.Background(), tt.projectID, 7, "codry:approved")
+			} else {
+				err = provider.AddLabel(context.Background(), tt.projectID, 7, "codry:approved")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(fields) != len(tt.wantFields) {
+				t.Errorf("request fields = %v, want %v", fields, tt.wantFields)
+			}
+			for key, value := range tt.wantFields {
+				if fields[key] != value {
+					t.Errorf("request field %s = %q, want %q", key, fields[key], value)
+				}
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/maxbolgarin/codry/internal/model"
@@ -23,13 +24,20 @@ const (
 	defaultBaseURL = "https://gitlab.com"
 )
 
-var _ interfaces.CodeProvider = (*Provider)(nil)
+var (
+	_ interfaces.CodeProvider    = (*Provider)(nil)
+	_ interfaces.CommentResolver = (*Provider)(nil)
+	_ interfaces.BotIdentifier   = (*Provider)(nil)
+)
 
 // Provider implements the CodeProvider interface for GitLab
 type Provider struct {
 	client *gitlab.Client
 	config model.ProviderConfig
 	logger logze.Logger
+
+	// bot is the account of the token, it is resolved when credentials are checked
+	bot atomic.Pointer[model.User]
 }
 
 // New creates a new GitLab provider
@@ -44,7 +52,12 @@ func New(config model.ProviderConfig) (*Provider, error) {
 		baseURL = defaultBaseURL
 	}
 
-	client, err := gitlab.NewClient(config.Token, gitlab.WithBaseURL(baseURL))
+	options := []gitlab.ClientOptionFunc{gitlab.WithBaseURL(baseURL)}
+	if config.Transport != nil {
+		options = append(options, gitlab.WithHTTPClient(&http.Client{Transport: config.Transport}))
+	}
+
+	client, err := gitlab.NewClient(config.Token, options...)
 	if err != nil {
 		return nil, errm.Wrap(err, "failed to create GitLab client")
 	}
@@ -56,6 +69,44 @@ func New(config model.ProviderConfig) (*Provider, error) {
 	}, nil
 }
 
+// ValidateCredentials checks the token by getting the current user
+func (p *Provider) ValidateCredentials(ctx context.Context) error {
+	user, resp, err := p.client.Users.CurrentUser(gitlab.WithContext(ctx))
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return errm.Wrap(model.ErrInvalidCredentials, "GitLab rejected token", "status", resp.StatusCode)
+		}
+		return errm.Wrap(err, "failed to get current user")
+	}
+	p.setBotUser(model.User{ID: strconv.Itoa(user.ID), Username: user.Username, Name: user.Name})
+	return nil
+}
+
+// BotUser returns the account of the token resolved by ValidateCredentials, ok is false if it is not resolved yet
+func (p *Provider) BotUser() (model.User, bool) {
+	if bot := p.bot.Load(); bot != nil {
+		return *bot, true
+	}
+	return model.User{}, false
+}
+
+// setBotUser stores the account of the token, the change of the account is logged once
+func (p *Provider) setBotUser(bot model.User) {
+	if old := p.bot.Swap(&bot); old != nil && *old == bot {
+		return
+	}
+	p.logger.Info("resolved bot account", "id", bot.ID, "username", bot.Username)
+	if p.config.BotUsername != "" && p.config.BotUsername != bot.Username {
+		p.logger.Warn("configured bot username differs from the token account, the account ID is used to recognize the bot",
+			"bot_username", p.config.BotUsername, "username", bot.Username)
+	}
+}
+
+// isBot checks if the user is the bot by the resolved account ID, the username is a fallback
+func (p *Provider) isBot(user *model.User) bool {
+	return user.IsBot(p.bot.Load(), p.config.BotUsername)
+}
+
 // ValidateWebhook validates the webhook signature
 func (p *Provider) ValidateWebhook(payload []byte, signature string) error {
 	if p.config.WebhookSecret == "" {
@@ -75,10 +126,24 @@ func (p *Provider) ParseWebhookEvent(payload []byte) (*model.CodeEvent, error) {
 	if err := json.Unmarshal(payload, &gitlabPayload); err != nil {
 		return nil, errm.Wrap(err, "failed to parse GitLab webhook payload")
 	}
+	if gitlabPayload.ObjectKind != "merge_request" || gitlabPayload.ObjectAttributes.IID == 0 {
+		// Pushes, notes, pipelines and other hooks of the project are not reviewed
+		return nil, errm.Wrap(model.ErrIgnoredEvent, "payload is not a merge request event", "object_kind", gitlabPayload.ObjectKind)
+	}
+
+	labels := make([]string, 0, len(gitlabPayload.Labels))
+	for _, label := range gitlabPayload.Labels {
+		labels = append(labels, label.Title)
+	}
+
+	action := gitlabPayload.ObjectAttributes.Action
+	if draft := gitlabPayload.Changes.Draft; action == "update" && draft.Previous && !draft.Current {
+		action = model.ActionReadyForReview // GitLab reports it as an update of the draft flag
+	}
 
 	event := &model.CodeEvent{
 		Type:      gitlabPayload.ObjectKind,
-		Action:    gitlabPayload.ObjectAttributes.Action,
+		Action:    action,
 		ProjectID: strconv.Itoa(gitlabPayload.Project.ID),
 		User: &model.User{
 			ID:       strconv.Itoa(gitlabPayload.User.ID),
@@ -95,6 +160,7 @@ func (p *Provider) ParseWebhookEvent(payload []byte) (*model.CodeEvent, error) {
 			URL:          gitlabPayload.ObjectAttributes.URL,
 			State:        gitlabPayload.ObjectAttributes.State,
 			SHA:          gitlabPayload.ObjectAttributes.LastCommit.ID,
+			Labels:       labels,
 		},
 	}
 
@@ -143,6 +209,7 @@ func (p *Provider) GetMergeRequest(ctx context.Context, projectID string, mrIID
 			Name:     mr.Author.Name,
 		},
 		Reviewers: reviewers,
+		Labels:    mr.Labels,
 		CreatedAt: lang.Deref(mr.CreatedAt),
 		UpdatedAt: lang.Deref(mr.UpdatedAt),
 	}, nil
@@ -186,14 +253,17 @@ func (p *Provider) GetMergeRequestDiffs(ctx context.Context, projectID string, m
 	// Convert to our models
 	var fileDiffs []*model.FileDiff
 	for _, diff := range allDiffs {
+		isBinary, isTooLarge := classifyDiff(diff.Diff, diff.NewPath, diff.DeletedFile, diff.RenamedFile)
 		fileDiff := &model.FileDiff{
-			OldPath:   diff.OldPath,
-			NewPath:   diff.NewPath,
-			Diff:      diff.Diff,
-			IsNew:     diff.NewFile,
-			IsDeleted: diff.DeletedFile,
-			IsRenamed: diff.RenamedFile,
-			IsBinary:  diff.Diff == "" && !diff.DeletedFile && !diff.NewFile, // Heuristic for binary files
+			OldPath:    diff.OldPath,
+			NewPath:    diff.NewPath,
+			Diff:       diff.Diff,
+			Hunks:      model.ParseHunks(diff.Diff),
+			IsNew:      diff.NewFile,
+			IsDeleted:  diff.DeletedFile,
+			IsRenamed:  diff.RenamedFile,
+			IsBinary:   isBinary,
+			IsTooLarge: isTooLarge,
 		}
 		fileDiffs = append(fileDiffs, fileDiff)
 	}
@@ -256,8 +326,9 @@ func (p *Provider) CreateComment(ctx context.Context, projectID string, mrIID in
 			}
 		}
 
+		body := comment.FullBody()
 		discussionOpts := &gitlab.CreateMergeRequestDiscussionOptions{
-			Body:     &comment.Body,
+			Body:     &body,
 			Position: positionOpts,
 		}
 
@@ -276,8 +347,9 @@ func (p *Provider) CreateComment(ctx context.Context, projectID string, mrIID in
 
 // createRegularComment creates a regular (non-positioned) discussion
 func (p *Provider) createRegularComment(projectID int, mrIID int, comment *model.Comment) error {
+	body := comment.FullBody()
 	discussionOpts := &gitlab.CreateMergeRequestDiscussionOptions{
-		Body: &comment.Body,
+		Body: &body,
 	}
 
 	discussion, _, err := p.client.Discussions.CreateMergeRequestDiscussion(projectID, mrIID, discussionOpts)
@@ -317,6 +389,11 @@ func (p *Provider) ListMergeRequests(ctx context.Context, projectID string, filt
 		opts.SourceBranch = &filter.SourceBranch
 	}
 
+	if len(filter.Labels) > 0 {
+		labels := gitlab.LabelOptions(filter.Labels)
+		opts.Labels = &labels
+	}
+
 	if filter.AuthorID != "" {
 		authorIDInt, err := strconv.Atoi(filter.AuthorID)
 		if err == nil {
@@ -365,6 +442,7 @@ func (p *Provider) ListMergeRequests(ctx context.Context, projectID string, filt
 				Name:     mr.Author.Name,
 			},
 			Reviewers: reviewers,
+			Labels:    mr.Labels,
 			CreatedAt: lang.Deref(mr.CreatedAt),
 			UpdatedAt: lang.Deref(mr.UpdatedAt),
 		}
@@ -388,17 +466,18 @@ func (p *Provider) GetMergeRequestUpdates(ctx context.Context, projectID string,
 // IsMergeRequestEvent determines if a webhook event is a merge request event that should be processed
 func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 	// Only process merge request events
-	if event.Type != "merge_request" {
+	if event == nil || event.MergeRequest == nil || event.Type != "merge_request" {
 		return false
 	}
 
 	// Check for relevant actions
 	relevantActions := []string{
-		"open",   // When MR is opened
-		"reopen", // When MR is reopened
-		"update", // When MR is updated
-		"merge",  // When MR is merged (for cleanup)
-		"close",  // When MR is closed (for cleanup)
+		"open",                     // When MR is opened
+		"reopen",                   // When MR is reopened
+		"update",                   // When MR is updated
+		model.ActionReadyForReview, // When draft MR is marked ready
+		"merge",                    // When MR is merged (for cleanup)
+		"close",                    // When MR is closed (for cleanup)
 	}
 
 	isRelevantAction := slices.Contains(relevantActions, event.Action)
@@ -408,7 +487,7 @@ func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
 	}
 
 	// Don't process events from the bot itself to avoid loops
-	if event.User.Username == p.config.BotUsername {
+	if p.isBot(event.User) {
 		return false
 	}
 
@@ -486,9 +565,13 @@ func (p *Provider) GetComments(ctx context.Context, projectID string, mrIID int)
 
 	for _, discussion := range discussions {
 		for _, note := range discussion.Notes {
+			body, footer := model.SplitCommentFooter(note.Body)
 			comment := &model.Comment{
-				ID:   strconv.Itoa(note.ID),
-				Body: note.Body,
+				ID:       strconv.Itoa(note.ID),
+				Body:     body,
+				Footer:   footer,
+				ThreadID: discussion.ID,
+				Resolved: note.Resolved,
 				Author: model.User{
 					ID:       strconv.Itoa(note.Author.ID),
 					Username: note.Author.Username,
@@ -502,6 +585,7 @@ func (p *Provider) GetComments(ctx context.Context, projectID string, mrIID int)
 			if note.Position != nil && note.Position.NewPath != "" {
 				comment.Type = model.CommentTypeInline
 				comment.FilePath = note.Position.NewPath
+				comment.CommitSHA = note.Position.HeadSHA
 				if note.Position.NewLine != 0 {
 					comment.Line = note.Position.NewLine
 				}
@@ -564,3 +648,26 @@ func (p *Provider) UpdateComment(ctx context.Context, projectID string, mrIID in
 
 	return nil
 }
+
+// ResolveComment resolves the discussion of the comment, GitLab rejects discussions that are not resolvable
+func (p *Provider) ResolveComment(ctx context.Context, projectID string, mrIID int, comment *model.Comment) error {
+	if comment == nil {
+		return errm.New("comment is nil")
+	}
+	if comment.ThreadID == "" {
+		return errm.Wrap(model.ErrNotSupported, "comment is not in a discussion", "comment_id", comment.ID)
+	}
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return errm.Wrap(err, "invalid project ID")
+	}
+
+	_, _, err = p.client.Discussions.ResolveMergeRequestDiscussion(projectIDInt, mrIID, comment.ThreadID, &gitlab.ResolveMergeRequestDiscussionOptions{
+		Resolved: gitlab.Ptr(true),
+	}, gitlab.WithContext(ctx))
+	if err != nil {
+		return errm.Wrap(err, "failed to resolve discussion")
+	}
+
+	return nil
+}
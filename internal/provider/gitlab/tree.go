@@ -0,0 +1,48 @@
+package gitlab
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+var _ interfaces.FileLister = (*Provider)(nil)
+
+// ListFiles returns paths of files directly in the directory at the ref
+func (p *Provider) ListFiles(ctx context.Context, projectID, dir, ref string) ([]string, error) {
+	projectIDInt, err := strconv.Atoi(projectID)
+	if err != nil {
+		return nil, errm.Wrap(err, "invalid project ID")
+	}
+
+	var files []string
+	page := 1
+
+	for {
+		opts := &gitlab.ListTreeOptions{
+			ListOptions: gitlab.ListOptions{Page: page, PerPage: 100},
+			Path:        &dir,
+			Ref:         &ref,
+		}
+		nodes, resp, err := p.client.Repositories.ListTree(projectIDInt, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, errm.Wrap(err, "failed to list repository tree")
+		}
+
+		for _, node := range nodes {
+			if node.Type == "blob" {
+				files = append(files, node.Path)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+
+	return files, nil
+}
@@ -13,6 +13,9 @@ type gitlabPayload struct {
 		ID   int    `json:"id"`
 		Name string `json:"name"`
 	} `json:"project"`
+	Labels []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
 	ObjectAttributes struct {
 		IID          int    `json:"iid"`
 		Action       string `json:"action"`
@@ -27,4 +30,10 @@ type gitlabPayload struct {
 			ID string `json:"id"`
 		} `json:"last_commit"`
 	} `json:"object_attributes"`
+	Changes struct {
+		Draft struct {
+			Previous bool `json:"previous"`
+			Current  bool `json:"current"`
+		} `json:"draft"`
+	} `json:"changes"`
 }
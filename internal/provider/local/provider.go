@@ -0,0 +1,286 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+var _ interfaces.CodeProvider = (*Provider)(nil)
+
+// Refs of file versions, the merge request of the provider is from HeadRef to BaseRef
+const (
+	BaseRef = "base"
+	HeadRef = "head"
+)
+
+const mergeRequestIID = 1
+
+// Provider implements the CodeProvider interface for diffs from local files, it is used to review
+// changes offline; files before changes are read from the directory, head versions are built by applying diffs,
+// comments are kept in memory
+type Provider struct {
+	dir       string
+	originals map[string]string
+	diffs     []*model.FileDiff
+	mr        *model.MergeRequest
+
+	mu       sync.Mutex
+	comments []*model.Comment
+}
+
+// New creates a provider of the diffs, dir is the directory with files before changes, it can be empty;
+// originals are contents of files before changes by path, they take precedence over the directory
+func New(dir string, diffs []*model.FileDiff, originals map[string]string) *Provider {
+	return &Provider{
+		dir:       dir,
+		originals: originals,
+		diffs:     diffs,
+		mr: &model.MergeRequest{
+			ID:           strconv.Itoa(mergeRequestIID),
+			IID:          mergeRequestIID,
+			Title:        "Local changes",
+			SourceBranch: HeadRef,
+			TargetBranch: BaseRef,
+			SHA:          HeadRef,
+			State:        "opened",
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		},
+	}
+}
+
+// MergeRequest returns the merge request of the diffs
+func (p *Provider) MergeRequest() *model.MergeRequest {
+	return p.mr
+}
+
+// Comments returns comments created by the reviewer
+func (p *Provider) Comments() []*model.Comment {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*model.Comment(nil), p.comments...)
+}
+
+// RelocateComment moves the created inline comment to the line
+func (p *Provider) RelocateComment(ctx context.Context, projectID string, mrIID int, comment *model.Comment, line int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, created := range p.comments {
+		if created.ID == comment.ID {
+			created.Line = line
+			created.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return errm.Wrap(model.ErrNotFound, "comment not found", "id", comment.ID)
+}
+
+// ValidateCredentials always succeeds, local files need no credentials
+func (p *Provider) ValidateCredentials(ctx context.Context) error {
+	return nil
+}
+
+// ValidateWebhook returns error, local provider doesn't receive webhooks
+func (p *Provider) ValidateWebhook(payload []byte, authToken string) error {
+	return errm.New("local provider doesn't receive webhooks")
+}
+
+// ParseWebhookEvent returns error, local provider doesn't receive webhooks
+func (p *Provider) ParseWebhookEvent(payload []byte) (*model.CodeEvent, error) {
+	return nil, errm.New("local provider doesn't receive webhooks")
+}
+
+// IsMergeRequestEvent always returns false, local provider doesn't receive webhooks
+func (p *Provider) IsMergeRequestEvent(event *model.CodeEvent) bool {
+	return false
+}
+
+// GetMergeRequest returns the merge request of the diffs
+func (p *Provider) GetMergeRequest(ctx context.Context, projectID string, mrIID int) (*model.MergeRequest, error) {
+	if mrIID != mergeRequestIID {
+		return nil, model.ErrNotFound
+	}
+	return p.mr, nil
+}
+
+// GetMergeRequestDiffs returns the diffs
+func (p *Provider) GetMergeRequestDiffs(ctx context.Context, projectID string, mrIID int) ([]*model.FileDiff, error) {
+	if mrIID != mergeRequestIID {
+		return nil, model.ErrNotFound
+	}
+	return p.diffs, nil
+}
+
+// UpdateMergeRequestDescription sets the description of the merge request
+func (p *Provider) UpdateMergeRequestDescription(ctx context.Context, projectID string, mrIID int, description string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mr.Description = description
+	return nil
+}
+
+// ListMergeRequests returns the merge request of the diffs
+func (p *Provider) ListMergeRequests(ctx context.Context, projectID string, filter *model.MergeRequestFilter) ([]*model.MergeRequest, error) {
+	return []*model.MergeRequest{p.mr}, nil
+}
+
+// GetMergeRequestUpdates returns the merge request of the diffs
+func (p *Provider) GetMergeRequestUpdates(ctx context.Context, projectID string, since time.Time) ([]*model.MergeRequest, error) {
+	return []*model.MergeRequest{p.mr}, nil
+}
+
+// CreateComment keeps the comment in memory
+func (p *Provider) CreateComment(ctx context.Context, projectID string, mrIID int, comment *model.Comment) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	created := *comment
+	created.ID = strconv.Itoa(len(p.comments) + 1)
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	p.comments = append(p.comments, &created)
+	return nil
+}
+
+// GetComments returns created comments
+func (p *Provider) GetComments(ctx context.Context, projectID string, mrIID int) ([]*model.Comment, error) {
+	return p.Comments(), nil
+}
+
+// UpdateComment updates the body of the created comment
+func (p *Provider) UpdateComment(ctx context.Context, projectID string, mrIID int, commentID string, newBody string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, comment := range p.comments {
+		if comment.ID == commentID {
+			comment.Body = newBody
+			comment.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return errm.Wrap(model.ErrNotFound, "comment not found", "id", commentID)
+}
+
+// GetFileContent returns the file before changes for BaseRef and the file with applied diff for other refs
+func (p *Provider) GetFileContent(ctx context.Context, projectID, filePath, commitSHA string) (string, error) {
+	if commitSHA == BaseRef {
+		return p.originalContent(filePath)
+	}
+
+	for _, diff := range p.diffs {
+		if diff.NewPath != filePath {
+			continue
+		}
+		if diff.IsDeleted {
+			return "", errm.Wrap(model.ErrNotFound, "file is deleted", "path", filePath)
+		}
+
+		var original string
+		if !diff.IsNew {
+			var err error
+			original, err = p.originalContent(diff.OldPath)
+			if err != nil {
+				return "", err
+			}
+		}
+		content, err := applyDiff(original, diff.DiffHunks())
+		if err != nil {
+			return "", errm.Wrap(err, "failed to apply diff", "path", filePath)
+		}
+		return content, nil
+	}
+
+	for _, diff := range p.diffs {
+		if diff.OldPath == filePath && (diff.IsDeleted || diff.IsRenamed) {
+			return "", errm.Wrap(model.ErrNotFound, "file is deleted or renamed", "path", filePath)
+		}
+	}
+
+	return p.originalContent(filePath)
+}
+
+// originalContent returns the content of the file before changes
+func (p *Provider) originalContent(filePath string) (string, error) {
+	if content, ok := p.originals[filePath]; ok {
+		return content, nil
+	}
+	if p.dir == "" {
+		return "", errm.Wrap(model.ErrNotFound, "file is not found", "path", filePath)
+	}
+
+	path := filepath.Join(p.dir, filepath.FromSlash(filePath))
+	if rel, err := filepath.Rel(p.dir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return "", errm.Errorf("path %s is outside of the directory", filePath)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errm.Wrap(model.ErrNotFound, "file is not found", "path", filePath)
+		}
+		return "", errm.Wrap(err, "failed to read file", "path", filePath)
+	}
+	return string(content), nil
+}
+
+// applyDiff applies hunks of the unified diff to the content, context and removed lines must match the content
+func applyDiff(content string, hunks []model.DiffHunk) (string, error) {
+	var original []string
+	if content != "" {
+		original = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	var (
+		result  []string
+		next    int // index of the next original line to copy
+		noNewLF bool
+	)
+	for _, hunk := range hunks {
+		start := hunk.OldStart
+		if hunk.OldCount == 0 {
+			start++ // empty range is after the line
+		}
+		if start-1 < next || start-1 > len(original) {
+			return "", errm.Errorf("hunk of lines %d-%d doesn't match the file", hunk.OldStart, hunk.OldStart+hunk.OldCount-1)
+		}
+		result = append(result, original[next:start-1]...)
+		next = start - 1
+
+		for _, line := range hunk.Lines {
+			if line.Kind == model.DiffLineAdded {
+				result = append(result, line.Content)
+				noNewLF = line.NoNewline
+				continue
+			}
+			if next >= len(original) || original[next] != line.Content {
+				return "", errm.Errorf("line %d doesn't match the diff", next+1)
+			}
+			if line.Kind == model.DiffLineContext {
+				result = append(result, line.Content)
+				noNewLF = line.NoNewline
+			}
+			next++
+		}
+	}
+	result = append(result, original[next:]...)
+
+	if len(result) == 0 {
+		return "", nil
+	}
+	text := strings.Join(result, "\n")
+	if !noNewLF {
+		text += "\n"
+	}
+	return text, nil
+}
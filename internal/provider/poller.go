@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/lang"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// pollingLookback is how far back the first poll of a project looks for updated merge requests
+const pollingLookback = 24 * time.Hour
+
+// ReviewFunc reviews the merge request found by polling
+type ReviewFunc func(ctx context.Context, projectID string, mr *model.MergeRequest) error
+
+// Poller reviews updated merge requests of projects with an adaptive interval, it is used instead of webhooks;
+// a merge request is reviewed once per head commit
+type Poller struct {
+	fetcher *Fetcher
+	review  ReviewFunc
+	cfg     PollingConfig
+	log     logze.Logger
+
+	lastSeen map[string]time.Time // the latest update time of merge requests by project
+	reviewed map[string]string    // head SHA of reviewed merge requests by project and IID
+}
+
+// NewPoller creates a poller of projects from the config, merge requests not matching the fetcher filter are skipped
+func NewPoller(fetcher *Fetcher, cfg PollingConfig, review ReviewFunc) *Poller {
+	cfg.MinInterval = lang.Check(cfg.MinInterval, defaultPollingMinInterval)
+	cfg.MaxInterval = max(lang.Check(cfg.MaxInterval, defaultPollingMaxInterval), cfg.MinInterval)
+
+	return &Poller{
+		fetcher:  fetcher,
+		review:   review,
+		cfg:      cfg,
+		log:      logze.With("component", "poller"),
+		lastSeen: make(map[string]time.Time, len(cfg.Projects)),
+		reviewed: make(map[string]string),
+	}
+}
+
+// Run polls projects until ctx is canceled, the interval is doubled up to the maximum after
+// a poll without updates and is reset to the minimum when updates are found
+func (p *Poller) Run(ctx context.Context) {
+	p.log.Info("polling merge requests", "projects", p.cfg.Projects, "min_interval", p.cfg.MinInterval, "max_interval", p.cfg.MaxInterval)
+
+	interval := p.cfg.MinInterval
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if p.poll(ctx) > 0 {
+			interval = p.cfg.MinInterval
+		} else {
+			interval = min(interval*2, p.cfg.MaxInterval)
+		}
+		p.log.Debug("next poll", "interval", interval)
+		timer.Reset(interval)
+	}
+}
+
+// poll reviews updated merge requests of all projects and returns the number of found updates,
+// errors are logged and count as no updates, so a failing API is not hammered
+func (p *Poller) poll(ctx context.Context) int {
+	var updates int
+	for _, projectID := range p.cfg.Projects {
+		since, ok := p.lastSeen[projectID]
+		if !ok {
+			since = time.Now().Add(-pollingLookback)
+		}
+
+		mrs, err := p.fetcher.provider.GetMergeRequestUpdates(ctx, projectID, since)
+		if err != nil {
+			if ctx.Err() != nil {
+				return updates
+			}
+			p.log.Error("failed to fetch merge request updates", "project_id", projectID, "error", err)
+			continue
+		}
+
+		// The latest update time includes skipped merge requests, so they are not fetched again
+		for _, mr := range mrs {
+			if mr.UpdatedAt.After(since) {
+				since = mr.UpdatedAt
+			}
+		}
+		p.lastSeen[projectID] = since
+
+		for _, mr := range p.fetcher.applyFilter(mrs, &p.fetcher.filter) {
+			key := projectID + "!" + strconv.Itoa(mr.IID)
+			if mr.SHA != "" && p.reviewed[key] == mr.SHA {
+				continue
+			}
+			updates++
+
+			if err := p.review(ctx, projectID, mr); err != nil {
+				p.log.Error("failed to review merge request", "project_id", projectID, "mr_iid", mr.IID, "error", err)
+				continue
+			}
+			p.reviewed[key] = mr.SHA
+		}
+	}
+	return updates
+}
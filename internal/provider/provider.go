@@ -17,9 +17,11 @@ func NewProvider(cfg Config) (interfaces.CodeProvider, error) {
 
 	cfgForProvider := model.ProviderConfig{
 		BaseURL:       cfg.BaseURL,
+		UploadURL:     cfg.UploadURL,
 		Token:         cfg.Token,
 		WebhookSecret: cfg.WebhookSecret,
 		BotUsername:   cfg.BotUsername,
+		Transport:     cfg.Transport,
 	}
 
 	var provider interfaces.CodeProvider
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// rateLimitTransport tracks the remaining quota of the provider API from response headers
+// and delays requests when it runs low, so long scans spread the rest of the quota until its reset
+// instead of exhausting it in the middle of a review
+type rateLimitTransport struct {
+	provider string
+	cfg      RateLimitConfig
+	recorder interfaces.MetricsRecorder
+	base     http.RoundTripper
+	log      logze.Logger
+
+	mu    sync.Mutex
+	state rateLimitState
+}
+
+// rateLimitState is the quota from the last response with rate limit headers
+type rateLimitState struct {
+	limit     int
+	remaining int
+	reset     time.Time
+	nearLimit bool // Bitbucket reports only that less than 20% of the quota remains
+}
+
+// RateLimitTransport returns a round tripper that throttles requests of the provider API by rate limit headers
+// of GitHub (X-RateLimit-*), GitLab (RateLimit-*) and Bitbucket (X-RateLimit-NearLimit), remaining quota is
+// recorded if the recorder is not nil; nil base uses the default transport
+func RateLimitTransport(provider string, cfg RateLimitConfig, recorder interfaces.MetricsRecorder, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if cfg.Disable {
+		return base
+	}
+	return &rateLimitTransport{
+		provider: provider,
+		cfg:      cfg,
+		recorder: recorder,
+		base:     base,
+		log:      logze.With("provider", provider, "component", "rate-limit"),
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if delay := t.delay(time.Now()); delay > 0 {
+		model.ContextLogger(req.Context(), t.log).Debug("quota runs low, delaying request", "delay", delay.String())
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		t.update(resp.Header)
+	}
+	return resp, err
+}
+
+// delay returns the wait before the next request: the time until reset is shared by the remaining requests
+// when the remaining share of the quota is below the threshold, it is capped by the max delay
+func (t *rateLimitTransport) delay(now time.Time) time.Duration {
+	t.mu.Lock()
+	state := t.state
+	t.mu.Unlock()
+
+	if state.reset.IsZero() || !now.Before(state.reset) {
+		if state.nearLimit {
+			return t.cfg.MaxDelay
+		}
+		return 0
+	}
+	if state.limit <= 0 || float64(state.remaining) >= float64(state.limit)*t.cfg.Threshold {
+		return 0
+	}
+	if state.remaining <= 0 {
+		return t.cfg.MaxDelay
+	}
+	return min(state.reset.Sub(now)/time.Duration(state.remaining), t.cfg.MaxDelay)
+}
+
+// update saves the quota from response headers, responses without them don't change the state
+func (t *rateLimitTransport) update(header http.Header) {
+	state, ok := parseRateLimitHeaders(header)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	t.state = state
+	t.mu.Unlock()
+
+	if t.recorder != nil && state.limit > 0 {
+		t.recorder.ProviderRateLimitRemaining(t.provider, state.remaining)
+	}
+}
+
+// parseRateLimitHeaders returns the quota from rate limit headers, false if there are no such headers
+func parseRateLimitHeaders(header http.Header) (rateLimitState, bool) {
+	var state rateLimitState
+	if nearLimit := header.Get("X-RateLimit-NearLimit"); nearLimit != "" {
+		state.nearLimit = strings.EqualFold(nearLimit, "true")
+		return state, true
+	}
+
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		limit, errLimit := strconv.Atoi(header.Get(prefix + "Limit"))
+		remaining, errRemaining := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if errLimit != nil || errRemaining != nil {
+			continue
+		}
+		state.limit, state.remaining = limit, remaining
+		if reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			state.reset = time.Unix(reset, 0)
+		}
+		return state, true
+	}
+
+	return state, false
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	"github.com/maxbolgarin/errm"
+)
+
+// NewTransport returns the base transport of provider API requests that trusts certificates
+// from the CA bundle of the config, nil is returned if there is no bundle and the default transport is used
+func NewTransport(cfg Config) (http.RoundTripper, error) {
+	if cfg.CACertFile == "" {
+		return nil, nil
+	}
+
+	pem, err := os.ReadFile(cfg.CACertFile)
+	if err != nil {
+		return nil, errm.Wrap(err, "read CA bundle")
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errm.Errorf("no PEM certificates in CA bundle %s", cfg.CACertFile)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	return transport, nil
+}
@@ -0,0 +1,222 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// DefaultArchitectureInputSize is the default limit of the architecture review input in bytes,
+// it is about 25k tokens and fits context of all supported models with room for the prompt
+const DefaultArchitectureInputSize = 100_000
+
+const (
+	maxSummaryEntities  = 30
+	maxSummarySignature = 200
+)
+
+// FileChangeSummary is a compact entity-level summary of changes in one file
+type FileChangeSummary struct {
+	FilePath        string           `json:"file_path"`
+	Status          string           `json:"status"` // added, deleted, renamed, modified
+	AddedLines      int              `json:"added_lines"`
+	RemovedLines    int              `json:"removed_lines"`
+	Layer           string           `json:"layer"`
+	Scope           string           `json:"scope"` // local, package, project
+	Entities        []ChangedEntity  `json:"entities"`
+	BreakingChanges []BreakingChange `json:"breaking_changes"`
+	Priority        int              `json:"priority"` // files with exported and cross-boundary changes go first
+}
+
+// ArchitectureInputAssembler builds input of the architecture review from compact per-file summaries
+// instead of raw diffs, so the review gets system-level signal and big merge requests fit the context
+type ArchitectureInputAssembler struct {
+	semanticAnalyzer *SemanticAnalyzer
+	maxSize          int
+	log              logze.Logger
+}
+
+// NewArchitectureInputAssembler creates a new assembler, maxSize limits the input size in bytes
+func NewArchitectureInputAssembler(provider interfaces.CodeProvider, maxSize int) *ArchitectureInputAssembler {
+	if maxSize <= 0 {
+		maxSize = DefaultArchitectureInputSize
+	}
+	return &ArchitectureInputAssembler{
+		semanticAnalyzer: NewSemanticAnalyzer(provider),
+		maxSize:          maxSize,
+		log:              logze.With("component", "architecture-input-assembler"),
+	}
+}
+
+// Assemble summarizes every changed file and concatenates summaries by priority until the size limit,
+// omitted files are listed at the end so the model knows the input is truncated
+func (a *ArchitectureInputAssembler) Assemble(ctx context.Context, request model.ReviewRequest, files []*model.FileDiff) string {
+	summaries := make([]FileChangeSummary, 0, len(files))
+	for _, file := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		summaries = append(summaries, a.summarizeFile(ctx, request, file))
+	}
+
+	slices.SortStableFunc(summaries, func(x, y FileChangeSummary) int {
+		return y.Priority - x.Priority
+	})
+
+	var (
+		sb      strings.Builder
+		omitted []string
+	)
+	for _, summary := range summaries {
+		rendered := renderFileChangeSummary(summary)
+		if sb.Len()+len(rendered) > a.maxSize {
+			omitted = append(omitted, summary.FilePath)
+			continue
+		}
+		sb.WriteString(rendered)
+	}
+
+	if len(omitted) > 0 {
+		model.ContextLogger(ctx, a.log).Warn("architecture review input is truncated", "omitted_files", len(omitted), "max_size", a.maxSize)
+		sb.WriteString(renderOmittedFiles(omitted, a.maxSize-sb.Len()))
+	}
+
+	return sb.String()
+}
+
+// summarizeFile builds summary of the file from semantic analysis, it falls back to diff stats on error
+func (a *ArchitectureInputAssembler) summarizeFile(ctx context.Context, request model.ReviewRequest, file *model.FileDiff) FileChangeSummary {
+	summary := FileChangeSummary{
+		FilePath: file.NewPath,
+		Status:   fileStatus(file),
+	}
+	summary.AddedLines, summary.RemovedLines = countDiffLines(file.Diff)
+
+	result, err := a.semanticAnalyzer.AnalyzeChanges(ctx, request, file)
+	if err != nil {
+		model.ContextLogger(ctx, a.log).Debug("failed to analyze file for architecture review", "file", file.NewPath, "error", err)
+	} else {
+		summary.Layer = result.ArchitecturalScope.Layer
+		summary.Scope = result.ImpactAnalysis.Scope
+		summary.Entities = result.ChangedEntities
+		summary.BreakingChanges = result.ImpactAnalysis.BreakingChanges
+	}
+
+	summary.Priority = summaryPriority(summary)
+
+	return summary
+}
+
+// summaryPriority scores the file by how likely its changes affect other parts of the system
+func summaryPriority(summary FileChangeSummary) int {
+	priority := 0
+	for _, entity := range summary.Entities {
+		if entity.IsExported {
+			priority += 2
+		}
+	}
+	priority += 5 * len(summary.BreakingChanges)
+
+	switch summary.Scope {
+	case "project":
+		priority += 4
+	case "package":
+		priority += 2
+	}
+	if summary.Layer != "" {
+		priority++
+	}
+	if summary.Status != "modified" {
+		priority++
+	}
+
+	return priority
+}
+
+func renderFileChangeSummary(summary FileChangeSummary) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("### %s (%s, +%d -%d", summary.FilePath, summary.Status, summary.AddedLines, summary.RemovedLines))
+	if summary.Layer != "" {
+		sb.WriteString(", layer: " + summary.Layer)
+	}
+	if summary.Scope != "" {
+		sb.WriteString(", scope: " + summary.Scope)
+	}
+	sb.WriteString(")\n")
+
+	if len(summary.Entities) == 0 {
+		sb.WriteString("- no entity-level changes detected\n")
+	}
+	for i, entity := range summary.Entities {
+		if i == maxSummaryEntities {
+			sb.WriteString(fmt.Sprintf("- ... and %d more entities\n", len(summary.Entities)-maxSummaryEntities))
+			break
+		}
+		visibility := "unexported"
+		if entity.IsExported {
+			visibility = "exported"
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s %s %s", entity.ChangeType, visibility, entity.Type, entity.Name))
+		if signature := collapseSignature(entity.Signature); signature != "" {
+			sb.WriteString(": `" + signature + "`")
+		}
+		sb.WriteString("\n")
+	}
+	for _, change := range summary.BreakingChanges {
+		sb.WriteString("- BREAKING: " + change.Description + "\n")
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// renderOmittedFiles lists files that don't fit the limit, only count is written if the list is too long
+func renderOmittedFiles(omitted []string, available int) string {
+	note := fmt.Sprintf("NOTE: input is truncated, %d changed files are omitted due to size limit", len(omitted))
+	list := note + ": " + strings.Join(omitted, ", ") + "\n"
+	if len(list) <= available {
+		return list
+	}
+	return note + "\n"
+}
+
+func collapseSignature(signature string) string {
+	signature = strings.Join(strings.Fields(signature), " ")
+	if len(signature) > maxSummarySignature {
+		signature = signature[:maxSummarySignature] + "..."
+	}
+	return signature
+}
+
+func fileStatus(file *model.FileDiff) string {
+	switch {
+	case file.IsNew:
+		return "added"
+	case file.IsDeleted:
+		return "deleted"
+	case file.IsRenamed:
+		return "renamed from " + file.OldPath
+	}
+	return "modified"
+}
+
+// countDiffLines returns the number of added and removed lines in the unified diff
+func countDiffLines(diff string) (int, int) {
+	var added, removed int
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,292 @@
+package analyze
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+// maxCallerSearchFiles limits the number of files fetched to find callers of changed entities
+const maxCallerSearchFiles = 50
+
+const (
+	// DefaultCallerSnippets is the default number of snippets of unchanged callers of changed functions
+	DefaultCallerSnippets = 3
+	// DefaultCallerSnippetsTokens is the default cap of the total size of caller snippets in tokens
+	DefaultCallerSnippetsTokens = 1500
+)
+
+// relationshipCaller is the relationship of snippets of unchanged callers of changed functions
+const relationshipCaller = "caller"
+
+// callerFile is a parsed unchanged Go file searched for callers of changed entities
+type callerFile struct {
+	path    string
+	content string
+	fset    *token.FileSet
+	file    *ast.File
+}
+
+// loadCallerFiles parses unchanged Go files of the changed package and of other directories with changed
+// Go files at the head commit, callers from other packages are found only in these directories;
+// it returns nothing if the provider can't list files
+func loadCallerFiles(ctx context.Context, provider interfaces.CodeProvider, request model.ReviewRequest, filePath string) ([]callerFile, error) {
+	lister, ok := provider.(interfaces.FileLister)
+	if !ok {
+		return nil, nil
+	}
+
+	changed := make(map[string]struct{}, len(request.Changes))
+	dirs := []string{filepath.Dir(filePath)}
+	for _, change := range request.Changes {
+		changed[change.NewPath] = struct{}{}
+		if dir := filepath.Dir(change.NewPath); detectLanguage(change.NewPath) == LanguageGo && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	var files []callerFile
+	for _, dir := range dirs {
+		listDir := dir
+		if listDir == "." {
+			listDir = "" // repository root
+		}
+		paths, err := lister.ListFiles(ctx, request.ProjectID, listDir, request.MergeRequest.SHA)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			continue // directory of a deleted file doesn't exist at the head commit
+		}
+
+		for _, candidate := range paths {
+			if _, ok := changed[candidate]; ok || detectLanguage(candidate) != LanguageGo || strings.HasSuffix(candidate, "_test.go") {
+				continue
+			}
+			if len(files) >= maxCallerSearchFiles {
+				return files, nil
+			}
+
+			content, err := FetchFileContent(ctx, provider, request.ProjectID, candidate, request.MergeRequest.SHA)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+				continue
+			}
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, candidate, content, parser.SkipObjectResolution)
+			if err != nil {
+				continue
+			}
+			files = append(files, callerFile{path: candidate, content: content, fset: fset, file: file})
+		}
+	}
+
+	return files, nil
+}
+
+// findGoCallers returns functions of the files that call the changed function or method of the package
+// in the directory, receiver types are not resolved, so methods are matched by name except calls
+// on the receiver of a method of another type
+func findGoCallers(files []callerFile, entity ChangedEntity, packageDir string) []Dependent {
+	if entity.Type != EntityTypeFunction && entity.Type != EntityTypeMethod {
+		return nil
+	}
+
+	var dependents []Dependent
+	for _, f := range files {
+		// Qualifier is empty for files of the same package, others must import it
+		qualifier := ""
+		if filepath.Dir(f.path) != packageDir {
+			if !entity.IsExported {
+				continue
+			}
+			var imported bool
+			qualifier, imported = goImportName(f.file, packageDir)
+			if !imported {
+				continue
+			}
+		}
+
+		for _, decl := range f.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			// Receiver of a method of another type can't be the receiver of the changed method
+			otherReceiver := ""
+			if entity.Receiver != "" && fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 &&
+				receiverTypeName(fn.Recv.List[0].Type) != receiverTypeOf(entity.Receiver) {
+				otherReceiver = fn.Recv.List[0].Names[0].Name
+			}
+
+			var callLines []int
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if ok && isGoCallOf(call.Fun, entity, qualifier, otherReceiver) {
+					callLines = append(callLines, f.fset.Position(call.Pos()).Line)
+				}
+				return true
+			})
+			if len(callLines) == 0 {
+				continue
+			}
+
+			dependent := Dependent{
+				Name:        fn.Name.Name,
+				Type:        EntityTypeFunction,
+				Package:     f.file.Name.Name,
+				FilePath:    f.path,
+				LineNumber:  f.fset.Position(fn.Pos()).Line,
+				CallLines:   callLines,
+				CodeSnippet: f.content[f.fset.Position(fn.Pos()).Offset:f.fset.Position(fn.End()).Offset],
+			}
+			if fn.Recv != nil && len(fn.Recv.List) > 0 {
+				dependent.Type = EntityTypeMethod
+				dependent.Name = receiverTypeName(fn.Recv.List[0].Type) + "." + fn.Name.Name
+			}
+			dependent.UsageContext = fmt.Sprintf("calls %s at lines %s", qualifiedEntityName(entity), joinInts(callLines))
+
+			dependents = append(dependents, dependent)
+		}
+	}
+
+	return dependents
+}
+
+// selectCallerSnippets selects snippets of unchanged callers of changed functions: callers of exported
+// functions and callers with more calls go first, snippets are added while they fit the token cap;
+// non-positive maxSnippets selects nothing and non-positive maxTokens removes the cap
+func selectCallerSnippets(entities []ChangedEntity, maxSnippets, maxTokens int) []RelatedCodeSnippet {
+	if maxSnippets <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		entity    *ChangedEntity
+		dependent Dependent
+	}
+	var candidates []candidate
+
+	for i := range entities {
+		for _, dependent := range entities[i].Dependents {
+			if dependent.CodeSnippet != "" {
+				candidates = append(candidates, candidate{entity: &entities[i], dependent: dependent})
+			}
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.entity.IsExported != b.entity.IsExported {
+			if a.entity.IsExported {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(len(b.dependent.CallLines), len(a.dependent.CallLines)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.dependent.FilePath, b.dependent.FilePath); c != 0 {
+			return c
+		}
+		return strings.Compare(a.dependent.Name, b.dependent.Name)
+	})
+
+	var (
+		snippets []RelatedCodeSnippet
+		tokens   int
+	)
+	for _, c := range candidates {
+		if len(snippets) >= maxSnippets {
+			break
+		}
+		size := approxTokens(c.dependent.CodeSnippet)
+		if maxTokens > 0 && tokens+size > maxTokens {
+			continue // a smaller caller may still fit
+		}
+		tokens += size
+
+		snippets = append(snippets, RelatedCodeSnippet{
+			EntityName:   c.dependent.Name,
+			EntityType:   string(c.dependent.Type),
+			FilePath:     c.dependent.FilePath,
+			CodeSnippet:  c.dependent.CodeSnippet,
+			Relationship: relationshipCaller,
+			Relevance:    fmt.Sprintf("caller of changed %s", c.entity.FullName),
+			LineNumbers:  c.dependent.CallLines,
+		})
+	}
+
+	return snippets
+}
+
+// isGoCallOf checks if the called expression is the entity: a plain call in the same package,
+// a call qualified with the package name from other packages or a method call on any value
+// except the package and the receiver of a method of another type
+func isGoCallOf(fun ast.Expr, entity ChangedEntity, qualifier, otherReceiver string) bool {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return entity.Type == EntityTypeFunction && qualifier == "" && f.Name == entity.Name
+	case *ast.SelectorExpr:
+		if f.Sel.Name != entity.Name {
+			return false
+		}
+		x, ok := f.X.(*ast.Ident)
+		if entity.Type == EntityTypeMethod {
+			return !ok || (x.Name != otherReceiver && (qualifier == "" || x.Name != qualifier))
+		}
+		return ok && qualifier != "" && x.Name == qualifier
+	case *ast.IndexExpr:
+		return isGoCallOf(f.X, entity, qualifier, otherReceiver) // explicit instantiation of a generic function
+	case *ast.IndexListExpr:
+		return isGoCallOf(f.X, entity, qualifier, otherReceiver)
+	}
+	return false
+}
+
+// qualifiedEntityName returns the name of the entity qualified by the receiver type for methods like "Store.Get"
+func qualifiedEntityName(entity ChangedEntity) string {
+	if receiverType := receiverTypeOf(entity.Receiver); receiverType != "" {
+		return receiverType + "." + entity.Name
+	}
+	return entity.Name
+}
+
+// goImportName returns the name the file uses for the package in the repository directory,
+// import paths are matched by the directory suffix because the module path is unknown
+func goImportName(file *ast.File, packageDir string) (string, bool) {
+	dir := filepath.ToSlash(packageDir)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || (importPath != dir && !strings.HasSuffix(importPath, "/"+dir)) {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name, spec.Name.Name != "_" && spec.Name.Name != "."
+		}
+		return path.Base(importPath), true
+	}
+	return "", false
+}
+
+// joinInts joins numbers with commas
+func joinInts(numbers []int) string {
+	parts := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		parts = append(parts, strconv.Itoa(n))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,116 @@
+package analyze
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// parseCallerFiles parses the Go sources by path like loadCallerFiles
+func parseCallerFiles(t *testing.T, sources map[string]string) []callerFile {
+	t.Helper()
+	var files []callerFile
+	for filePath, content := range sources {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, filePath, content, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatalf("ParseFile(%s) error = %v", filePath, err)
+		}
+		files = append(files, callerFile{path: filePath, content: content, fset: fset, file: file})
+	}
+	slices.SortFunc(files, func(a, b callerFile) int { return strings.Compare(a.path, b.path) })
+	return files
+}
+
+func TestFindGoCallers(t *testing.T) {
+	sources := map[string]string{
+		"store/cache.go": "package store\n\nfunc warm() {\n\tGet(\"a\")\n\tGet(\"b\")\n}\n\n" +
+			"func (c *Cache) flush() {\n\tc.Save()\n}\n\nfunc sync(s *Store) {\n\ts.Save()\n}\n",
+		"api/handler.go": "package api\n\nimport \"example.com/app/store\"\n\nfunc handle() {\n\tstore.Get(\"c\")\n}\n",
+		"cli/main.go":    "package main\n\nfunc run() {\n\tGet(\"d\")\n}\n",
+	}
+	get := ChangedEntity{Type: EntityTypeFunction, Name: "Get", IsExported: true}
+
+	tests := []struct {
+		name   string
+		entity ChangedEntity
+		want   []string
+	}{
+		{
+			name:   "exported function",
+			entity: get,
+			want:   []string{"handle [6] in api/handler.go", "warm [4 5] in store/cache.go"},
+		},
+		{
+			name:   "unexported function is called in its package only",
+			entity: ChangedEntity{Type: EntityTypeFunction, Name: "Get"},
+			want:   []string{"warm [4 5] in store/cache.go"},
+		},
+		{
+			// The receiver of a method of another type can't be the receiver of the changed method
+			name:   "method",
+			entity: ChangedEntity{Type: EntityTypeMethod, Name: "Save", Receiver: "s *Store", IsExported: true},
+			want:   []string{"sync [13] in store/cache.go"},
+		},
+		{
+			name:   "type",
+			entity: ChangedEntity{Type: EntityTypeStruct, Name: "Get", IsExported: true},
+		},
+	}
+
+	files := parseCallerFiles(t, sources)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, dependent := range findGoCallers(files, tt.entity, "store") {
+				got = append(got, fmt.Sprintf("%s %v in %s", dependent.Name, dependent.CallLines, dependent.FilePath))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findGoCallers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectCallerSnippets(t *testing.T) {
+	caller := func(name string, calls int, size int) Dependent {
+		return Dependent{Name: name, FilePath: name + ".go", CallLines: make([]int, calls), CodeSnippet: strings.Repeat("x", size*4)}
+	}
+	entities := []ChangedEntity{
+		{FullName: "store.get", Dependents: []Dependent{caller("internal", 5, 10)}},
+		{FullName: "store.Get", IsExported: true, Dependents: []Dependent{
+			caller("once", 1, 10), caller("twice", 2, 10), caller("large", 3, 100), {Name: "empty", CallLines: []int{1}},
+		}},
+	}
+
+	tests := []struct {
+		name        string
+		maxSnippets int
+		maxTokens   int
+		want        []string
+	}{
+		{"exported first then more calls", 10, 0, []string{"large", "twice", "once", "internal"}},
+		{"snippet limit", 2, 0, []string{"large", "twice"}},
+		{"large snippet is skipped by the token cap", 3, 30, []string{"twice", "once", "internal"}},
+		{"disabled", 0, 0, nil},
+		{"negative snippets", -1, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, snippet := range selectCallerSnippets(entities, tt.maxSnippets, tt.maxTokens) {
+				got = append(got, snippet.EntityName)
+				if snippet.Relationship != relationshipCaller || !strings.HasPrefix(snippet.Relevance, "caller of changed store.") {
+					t.Errorf("snippet %s = %q %q, want caller relationship", snippet.EntityName, snippet.Relationship, snippet.Relevance)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("selectCallerSnippets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package analyze
+
+import (
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// securitySensitivePaths are parts of paths of files whose changes are reviewed first
+var securitySensitivePaths = []string{
+	"auth", "security", "crypto", "secret", "password", "token", "session", "permission",
+	"acl", "oauth", "jwt", "tls", "cert", "payment", "billing",
+}
+
+// DefaultChangeRiskWeights are weights of change risk signals used if none are configured
+var DefaultChangeRiskWeights = ChangeRiskWeights{
+	Breaking:      4,
+	Exported:      2,
+	CrossPackage:  2,
+	Security:      4,
+	SensitivePath: 3,
+}
+
+// ChangeRiskWeights are scores added to the change risk by its signals, 0 ignores a signal
+type ChangeRiskWeights struct {
+	// Breaking is added for deleted exported entities and changed exported signatures
+	Breaking int `yaml:"breaking"`
+	// Exported is added for changed exported entities without breaking changes
+	Exported int `yaml:"exported"`
+	// CrossPackage is added for changes of many exported entities that likely affect other packages,
+	// it is the scope signal of the architecture review input
+	CrossPackage int `yaml:"cross_package"`
+	// Security is added for possible security issues found by the scanner
+	Security int `yaml:"security"`
+	// SensitivePath is added for paths like auth, crypto or payment
+	SensitivePath int `yaml:"sensitive_path"`
+}
+
+// ChangeRisk is the risk of a file change estimated from its diff only, so it is cheap to find for every file
+// of a large merge request and is used to choose files to review first
+type ChangeRisk struct {
+	FilePath string
+	Score    int
+	// Reasons explain the score like "breaking changes", empty for an ordinary change
+	Reasons []string
+	// ChangedLines is the number of added and removed lines, larger changes go first among equal scores
+	ChangedLines int
+	// EstimatedTokens is the approximate size of the diff in tokens, it is the cost of the file in the review budget
+	EstimatedTokens int
+}
+
+// AssessChangeRisk scores the change by breaking, exported and cross-package changes found by the semantic analysis
+// of the diff, security issues found by the scanner and security-sensitive path; files are not fetched from the provider
+func (sa *SemanticAnalyzer) AssessChangeRisk(fileDiff *model.FileDiff, weights ChangeRiskWeights, scanner *SecurityScanner) ChangeRisk {
+	risk := ChangeRisk{
+		FilePath:        fileDiff.NewPath,
+		ChangedLines:    fileDiff.ChangedLines(),
+		EstimatedTokens: approxTokens(fileDiff.Diff),
+	}
+
+	// Exported test functions are not API, so test files get only the path and scanner signals
+	if !IsTestFile(fileDiff.NewPath, DefaultTestFileConventions) {
+		impact := sa.analyzeImpact(sa.extractEntitiesFromDiff(fileDiff))
+		switch {
+		case len(impact.BreakingChanges) > 0:
+			risk.Score += weights.Breaking
+			risk.Reasons = append(risk.Reasons, "breaking changes")
+		case impact.RiskLevel != "low":
+			risk.Score += weights.Exported
+			risk.Reasons = append(risk.Reasons, "exported changes")
+		}
+		if impact.Scope == "project" {
+			risk.Score += weights.CrossPackage
+			risk.Reasons = append(risk.Reasons, "cross-package changes")
+		}
+	}
+
+	if len(scanner.ScanDiff(fileDiff.NewPath, fileDiff.Diff)) > 0 {
+		risk.Score += weights.Security
+		risk.Reasons = append(risk.Reasons, "possible security issues")
+	}
+
+	pathLower := strings.ToLower(fileDiff.NewPath)
+	for _, part := range securitySensitivePaths {
+		if strings.Contains(pathLower, part) {
+			risk.Score += weights.SensitivePath
+			risk.Reasons = append(risk.Reasons, "security-sensitive path")
+			break
+		}
+	}
+
+	return risk
+}
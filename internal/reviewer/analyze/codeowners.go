@@ -0,0 +1,125 @@
+package analyze
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// CodeOwnersPaths are locations of the CODEOWNERS file in the repository, the first existing one is used
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS", ".bitbucket/CODEOWNERS"}
+
+// CodeOwners are ownership rules of a CODEOWNERS file, the last matching rule defines owners of a file
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern  *regexp.Regexp
+	owners   []string
+	catchAll bool // the rule matches every file like *
+}
+
+// LoadCodeOwners loads the first existing CODEOWNERS file at the ref, it returns error if there is no file
+func LoadCodeOwners(ctx context.Context, provider interfaces.CodeProvider, projectID, ref string) (*CodeOwners, error) {
+	for _, path := range CodeOwnersPaths {
+		content, err := FetchFileContent(ctx, provider, projectID, path, ref)
+		if err == nil {
+			return ParseCodeOwners(content), nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, errm.New("CODEOWNERS is not found")
+}
+
+// ParseCodeOwners parses rules of a CODEOWNERS file, comments, section headers of GitLab
+// and lines with invalid patterns are skipped
+func ParseCodeOwners(content string) *CodeOwners {
+	owners := &CodeOwners{}
+	for _, line := range strings.Split(content, "\n") {
+		line = stripCodeOwnersComment(line)
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+
+		pattern, err := codeOwnersPatternRegex(strings.ReplaceAll(fields[0], `\#`, "#"))
+		if err != nil {
+			continue
+		}
+		owners.rules = append(owners.rules, codeOwnersRule{
+			pattern:  pattern,
+			owners:   fields[1:],
+			catchAll: fields[0] == "*" || fields[0] == "**" || fields[0] == "/**",
+		})
+	}
+	return owners
+}
+
+// Owners returns owners of the file, catchAll is true if they are defined by a rule for every file;
+// a file matched by a rule without owners has no owners
+func (c *CodeOwners) Owners(filePath string) (owners []string, catchAll bool) {
+	if c == nil {
+		return nil, false
+	}
+	filePath = strings.TrimPrefix(filePath, "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(filePath) {
+			return c.rules[i].owners, c.rules[i].catchAll
+		}
+	}
+	return nil, false
+}
+
+// stripCodeOwnersComment removes the comment of the line, escaped \# is kept
+func stripCodeOwnersComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] != '\\') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// codeOwnersPatternRegex converts a gitignore-style pattern to a regex: patterns with a slash except
+// the trailing one are relative to the root, others match at any level; a matched directory matches its files
+func codeOwnersPatternRegex(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		return nil, errm.New("empty pattern")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("(?:/.*)?$")
+
+	return regexp.Compile(sb.String())
+}
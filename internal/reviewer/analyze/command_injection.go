@@ -0,0 +1,153 @@
+package analyze
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	goExecCommandRegex    = regexp.MustCompile(`\bexec\.Command(Context)?\(`)
+	pythonSubprocessRegex = regexp.MustCompile(`\bsubprocess\.(?:run|call|check_call|check_output|Popen|getoutput|getstatusoutput)\(`)
+	pythonOSSystemRegex   = regexp.MustCompile(`\bos\.(?:system|popen)\(`)
+	pythonShellTrueRegex  = regexp.MustCompile(`\bshell\s*=\s*True\b`)
+	// exec imported from child_process is called directly or on the module, other .exec methods like RegExp.exec are skipped
+	jsChildExecRegex = regexp.MustCompile(`(?:^|[^.\w$])(?:child_process\.|childProcess\.)?exec(?:Sync)?\(`)
+
+	// shells running the command string of the next argument
+	shellCommandFlags = map[string]string{
+		"sh": "-c", "bash": "-c", "zsh": "-c", "/bin/sh": "-c", "/bin/bash": "-c",
+		"cmd": "/c", "cmd.exe": "/c", "powershell": "-Command", "pwsh": "-Command",
+	}
+)
+
+// detectCommandInjection returns description if the line runs a shell command built from non-literal values:
+// exec.Command of a shell with -c, Python subprocess with shell=True and os.system, Node child_process.exec;
+// to keep false positives low the command string must interpolate or concatenate a variable
+func detectCommandInjection(code string, language SupportedLanguage) (string, bool) {
+	// Commands are matched in string literals, so only comments are removed
+	code = StripComments(code, language)
+	if strings.TrimSpace(code) == "" {
+		return "", false
+	}
+
+	switch language {
+	case LanguageGo:
+		loc := goExecCommandRegex.FindStringSubmatchIndex(code)
+		if loc == nil {
+			return "", false
+		}
+		args := callArguments(code[loc[1]:])
+		if loc[2] >= 0 && len(args) > 0 {
+			args = args[1:] // context of exec.CommandContext
+		}
+		if len(args) < 3 {
+			return "", false
+		}
+		shell, ok := unquoteLiteral(args[0])
+		if !ok || shellCommandFlags[shell] == "" {
+			return "", false
+		}
+		if flag, ok := unquoteLiteral(args[1]); !ok || !strings.EqualFold(flag, shellCommandFlags[shell]) {
+			return "", false
+		}
+		if isInterpolated(args[2], language) {
+			return "shell command is built from non-literal values and run with " + shell + " " + shellCommandFlags[shell] +
+				", pass arguments to exec.Command separately without a shell", true
+		}
+	case LanguagePython:
+		if loc := pythonOSSystemRegex.FindStringIndex(code); loc != nil {
+			if args := callArguments(code[loc[1]:]); len(args) > 0 && isInterpolated(args[0], language) {
+				return "shell command is built from non-literal values and run with os.system, use subprocess.run with a list of arguments", true
+			}
+		}
+		if loc := pythonSubprocessRegex.FindStringIndex(code); loc != nil && pythonShellTrueRegex.MatchString(code[loc[1]:]) {
+			if args := callArguments(code[loc[1]:]); len(args) > 0 && isInterpolated(args[0], language) {
+				return "shell command is built from non-literal values and run with shell=True, pass a list of arguments without shell=True", true
+			}
+		}
+	case LanguageJavaScript, LanguageTypeScript:
+		if loc := jsChildExecRegex.FindStringIndex(code); loc != nil {
+			if args := callArguments(code[loc[1]:]); len(args) > 0 && isInterpolated(args[0], language) {
+				return "shell command is built from non-literal values and run with child_process.exec, use execFile or spawn with a list of arguments", true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// isInterpolated checks if the expression is a string built from a variable: concatenation with a literal,
+// fmt.Sprintf with %s or %v verbs, Python f-strings and formatting, JS template literals with ${}
+func isInterpolated(expr string, language SupportedLanguage) bool {
+	if concatAfterLiteralRegex.MatchString(expr) || concatBeforeLiteralRegex.MatchString(expr) {
+		return true
+	}
+	switch language {
+	case LanguageGo:
+		match := goSprintfRegex.FindStringSubmatch(expr)
+		return match != nil && printfVerbRegex.MatchString(match[1])
+	case LanguagePython:
+		if match := pythonFStringRegex.FindStringSubmatch(expr); match != nil && strings.Contains(match[1], "{") {
+			return true
+		}
+		return pythonFormatRegex.MatchString(expr)
+	case LanguageJavaScript, LanguageTypeScript:
+		return jsTemplateRegex.MatchString(expr)
+	}
+	return false
+}
+
+// callArguments splits arguments of the call the text starts after its opening parenthesis, nested calls and
+// string literals are kept whole; arguments of a call continued on the next lines end at the end of the text
+func callArguments(text string) []string {
+	var (
+		args  []string
+		depth int
+		start int
+		quote byte
+	)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			switch {
+			case c == '\\' && quote != '`':
+				i++
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return append(args, strings.TrimSpace(text[start:i]))
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(text[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(text[start:]); rest != "" {
+		args = append(args, rest)
+	}
+	return args
+}
+
+// unquoteLiteral returns the value of the expression if it is a single plain string literal
+func unquoteLiteral(expr string) (string, bool) {
+	if len(expr) < 2 || !strings.ContainsRune(`"'`+"`", rune(expr[0])) || expr[len(expr)-1] != expr[0] {
+		return "", false
+	}
+	value := expr[1 : len(expr)-1]
+	if strings.ContainsRune(value, rune(expr[0])) {
+		return "", false // "a" + "b" is not a single literal
+	}
+	return value, true
+}
@@ -0,0 +1,325 @@
+package analyze
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// ConcurrencyHintType represents the category of a concurrency hint
+type ConcurrencyHintType string
+
+const (
+	ConcurrencyHintLoopVariableCapture     ConcurrencyHintType = "loop_variable_capture"
+	ConcurrencyHintUnlockedMapWrite        ConcurrencyHintType = "unlocked_map_write"
+	ConcurrencyHintWaitGroupAddInGoroutine ConcurrencyHintType = "waitgroup_add_in_goroutine"
+)
+
+// ConcurrencyHint is a suspicious concurrent pattern in added Go code, it is not a confirmed issue:
+// description explains why it is suspicious, so the reviewer can confirm or dismiss it
+type ConcurrencyHint struct {
+	Type        ConcurrencyHintType `json:"type"`        // category of the hint
+	FilePath    string              `json:"file_path"`   // file where it was found
+	Line        int                 `json:"line"`        // line in the new file
+	Code        string              `json:"code"`        // line with the pattern
+	Description string              `json:"description"` // why the pattern is suspicious
+}
+
+// structSync describes a struct declared in the file that guards its maps with a mutex
+type structSync struct {
+	hasMutex  bool
+	mapFields map[string]bool
+}
+
+// FindConcurrencyHints returns suspicious concurrent patterns in added lines of the Go file content:
+// goroutine closures capturing loop variables (only if goVersion is known and before 1.22),
+// WaitGroup.Add inside the spawned goroutine and map writes in methods of mutex-guarded structs without locking.
+// Patterns are matched conservatively, the file is skipped if it cannot be parsed.
+func FindConcurrencyHints(filePath, content, diff, goVersion string) []ConcurrencyHint {
+	if detectLanguage(filePath) != LanguageGo || content == "" {
+		return nil
+	}
+	parsed, err := parseGoFile(filePath, content)
+	if err != nil {
+		return nil
+	}
+	return findConcurrencyHints(filePath, parsed, diff, goVersion)
+}
+
+// findConcurrencyHints returns concurrency hints of the parsed Go file
+func findConcurrencyHints(filePath string, parsed *parsedFile, diff, goVersion string) []ConcurrencyHint {
+	fset, file := parsed.fset, parsed.file
+
+	added := make(map[int]bool)
+	for _, line := range ParseAddedLines(diff) {
+		added[line.Number] = true
+	}
+	lines := strings.Split(parsed.src, "\n")
+
+	var hints []ConcurrencyHint
+	addHint := func(hintType ConcurrencyHintType, pos token.Pos, description string) {
+		line := fset.Position(pos).Line
+		if !added[line] || line > len(lines) {
+			return
+		}
+		hints = append(hints, ConcurrencyHint{
+			Type:        hintType,
+			FilePath:    filePath,
+			Line:        line,
+			Code:        strings.TrimSpace(lines[line-1]),
+			Description: description,
+		})
+	}
+
+	checkLoopVars := isGoVersionBefore(goVersion, 1, 22)
+	structs := collectSyncStructs(file)
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.RangeStmt:
+			if checkLoopVars && n.Tok == token.DEFINE {
+				findLoopVarCaptures(n.Body, []ast.Expr{n.Key, n.Value}, addHint)
+			}
+
+		case *ast.ForStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok && checkLoopVars && init.Tok == token.DEFINE {
+				findLoopVarCaptures(n.Body, init.Lhs, addHint)
+			}
+
+		case *ast.GoStmt:
+			if lit, ok := n.Call.Fun.(*ast.FuncLit); ok {
+				findWaitGroupAdd(lit.Body, addHint)
+			}
+
+		case *ast.FuncDecl:
+			findUnlockedMapWrites(n, structs, addHint)
+		}
+		return true
+	})
+
+	return hints
+}
+
+// findLoopVarCaptures reports goroutine closures in the loop body that use loop variables directly,
+// variables shadowed with v := v or passed as arguments are different objects and are not reported
+func findLoopVarCaptures(body *ast.BlockStmt, loopVars []ast.Expr, addHint func(ConcurrencyHintType, token.Pos, string)) {
+	objects := make(map[*ast.Object]string)
+	for _, expr := range loopVars {
+		if ident, ok := expr.(*ast.Ident); ok && ident.Name != "_" && ident.Obj != nil {
+			objects[ident.Obj] = ident.Name
+		}
+	}
+	if len(objects) == 0 || body == nil {
+		return
+	}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		stmt, ok := node.(*ast.GoStmt)
+		if !ok {
+			return true
+		}
+		lit, ok := stmt.Call.Fun.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+
+		var captured []string
+		ast.Inspect(lit.Body, func(inner ast.Node) bool {
+			if ident, ok := inner.(*ast.Ident); ok && ident.Obj != nil {
+				if name, ok := objects[ident.Obj]; ok && !contains(captured, name) {
+					captured = append(captured, name)
+				}
+			}
+			return true
+		})
+		if len(captured) > 0 {
+			addHint(ConcurrencyHintLoopVariableCapture, stmt.Pos(), "Goroutine closure captures loop variable "+strings.Join(captured, ", ")+
+				": before Go 1.22 the variable is shared between iterations, so goroutines may observe a later value."+
+				" Pass it as an argument or shadow it with v := v.")
+		}
+		return false
+	})
+}
+
+// findWaitGroupAdd reports WaitGroup.Add calls inside the spawned goroutine
+func findWaitGroupAdd(body *ast.BlockStmt, addHint func(ConcurrencyHintType, token.Pos, string)) {
+	ast.Inspect(body, func(node ast.Node) bool {
+		if _, ok := node.(*ast.FuncLit); ok {
+			return false // nested closures may be called synchronously
+		}
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Add" || !isWaitGroupExpr(sel.X) {
+			return true
+		}
+		addHint(ConcurrencyHintWaitGroupAddInGoroutine, call.Pos(), "WaitGroup.Add is called inside the spawned goroutine:"+
+			" Wait may return before the goroutine starts and increments the counter. Call Add before the go statement.")
+		return true
+	})
+}
+
+// findUnlockedMapWrites reports map writes through the receiver in methods that don't lock the struct mutex,
+// methods with the Locked suffix are expected to be called with the lock held
+func findUnlockedMapWrites(decl *ast.FuncDecl, structs map[string]structSync, addHint func(ConcurrencyHintType, token.Pos, string)) {
+	if decl.Recv == nil || len(decl.Recv.List) != 1 || len(decl.Recv.List[0].Names) != 1 || decl.Body == nil {
+		return
+	}
+	if strings.HasSuffix(decl.Name.Name, "Locked") {
+		return
+	}
+
+	recvType := decl.Recv.List[0].Type
+	if star, ok := recvType.(*ast.StarExpr); ok {
+		recvType = star.X
+	}
+	typeIdent, ok := recvType.(*ast.Ident)
+	if !ok {
+		return
+	}
+	info, ok := structs[typeIdent.Name]
+	if !ok || !info.hasMutex || len(info.mapFields) == 0 {
+		return
+	}
+	recvName := decl.Recv.List[0].Names[0].Name
+
+	locked := false
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		if call, ok := node.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && (sel.Sel.Name == "Lock" || sel.Sel.Name == "RLock") {
+				locked = true
+			}
+		}
+		return !locked
+	})
+	if locked {
+		return
+	}
+
+	report := func(pos token.Pos, field string) {
+		addHint(ConcurrencyHintUnlockedMapWrite, pos, "Map "+recvName+"."+field+" is written without locking, while "+typeIdent.Name+
+			" has a mutex that likely guards it: concurrent writes cause a fatal runtime error."+
+			" Dismiss if the method is only called with the lock held.")
+	}
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if field, ok := receiverMapField(lhs, recvName, info); ok {
+					report(n.Pos(), field)
+					break
+				}
+			}
+		case *ast.IncDecStmt:
+			if field, ok := receiverMapField(n.X, recvName, info); ok {
+				report(n.Pos(), field)
+			}
+		case *ast.CallExpr:
+			if ident, ok := n.Fun.(*ast.Ident); ok && ident.Name == "delete" && len(n.Args) == 2 {
+				if field, ok := receiverField(n.Args[0], recvName, info); ok {
+					report(n.Pos(), field)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// collectSyncStructs returns structs of the file with their map fields and presence of a mutex field
+func collectSyncStructs(file *ast.File) map[string]structSync {
+	structs := make(map[string]structSync)
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+
+		info := structSync{mapFields: make(map[string]bool)}
+		for _, field := range st.Fields.List {
+			fieldType := field.Type
+			if star, ok := fieldType.(*ast.StarExpr); ok {
+				fieldType = star.X
+			}
+			if sel, ok := fieldType.(*ast.SelectorExpr); ok {
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "sync" && (sel.Sel.Name == "Mutex" || sel.Sel.Name == "RWMutex") {
+					info.hasMutex = true
+				}
+			}
+			if _, ok := fieldType.(*ast.MapType); ok {
+				for _, name := range field.Names {
+					info.mapFields[name.Name] = true
+				}
+			}
+		}
+		structs[spec.Name.Name] = info
+		return false
+	})
+	return structs
+}
+
+// receiverMapField returns the map field of recv.field[key] expression
+func receiverMapField(expr ast.Expr, recvName string, info structSync) (string, bool) {
+	index, ok := expr.(*ast.IndexExpr)
+	if !ok {
+		return "", false
+	}
+	return receiverField(index.X, recvName, info)
+}
+
+// receiverField returns the field name of recv.field expression if it is a map field
+func receiverField(expr ast.Expr, recvName string, info structSync) (string, bool) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	recv, ok := sel.X.(*ast.Ident)
+	if !ok || recv.Name != recvName || !info.mapFields[sel.Sel.Name] {
+		return "", false
+	}
+	return sel.Sel.Name, true
+}
+
+// isWaitGroupExpr checks if the expression looks like a WaitGroup by its name: wg, s.wg, waitGroup
+func isWaitGroupExpr(expr ast.Expr) bool {
+	var name string
+	switch x := expr.(type) {
+	case *ast.Ident:
+		name = x.Name
+	case *ast.SelectorExpr:
+		name = x.Sel.Name
+	default:
+		return false
+	}
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, "wg") || strings.Contains(name, "waitgroup")
+}
+
+// isGoVersionBefore checks if go directive version like 1.21 or 1.21.3 is before major.minor,
+// unknown version returns false
+func isGoVersionBefore(version string, major, minor int) bool {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) < 2 {
+		return false
+	}
+	versionMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minorPart := parts[1]
+	if i := strings.IndexFunc(minorPart, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minorPart = minorPart[:i] // 1.21rc1
+	}
+	versionMinor, err := strconv.Atoi(minorPart)
+	if err != nil {
+		return false
+	}
+	return versionMajor < major || (versionMajor == major && versionMinor < minor)
+}
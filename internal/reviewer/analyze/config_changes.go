@@ -0,0 +1,376 @@
+package analyze
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+	"gopkg.in/yaml.v3"
+)
+
+// maxConfigValueLength is the length of a rendered config value above which it is cut
+const maxConfigValueLength = 120
+
+var (
+	// secretConfigKeyRegex matches keys whose values are credentials, their values are never shown
+	secretConfigKeyRegex = regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|pwd|credential|private[_-]?key|access[_-]?key)`)
+
+	tomlTableRegex = regexp.MustCompile(`^\s*(\[\[?)\s*([^\]]+?)\s*\]\]?\s*(?:#.*)?$`)
+	tomlKeyRegex   = regexp.MustCompile(`^\s*([A-Za-z0-9_."' -]+?)\s*=`)
+)
+
+// ConfigRiskRule flags an added or changed key of a structured config file whose new value is risky,
+// all set conditions must match
+type ConfigRiskRule struct {
+	Name string `yaml:"name"`
+	// Key is a case-insensitive regular expression of the full key like server.tls.enabled
+	Key string `yaml:"key"`
+	// Value is a case-insensitive regular expression of the new value, any value matches if it is empty
+	Value string `yaml:"value"`
+	// Above is the number the new value must exceed, it is not checked if it is zero
+	Above float64 `yaml:"above"`
+	// Description explains the risk in the finding
+	Description string `yaml:"description"`
+}
+
+// DefaultConfigRiskRules flag disabled TLS, wildcard CORS, enabled debug mode and very high limits
+var DefaultConfigRiskRules = []ConfigRiskRule{
+	{
+		Name:        "tls_disabled",
+		Key:         `(?:^|[._-])(?:tls|ssl|https)(?:[._-]?(?:enabled?|required|verify))?$`,
+		Value:       `^(?:false|no|off|disabled?|none)$`,
+		Description: "TLS is turned off, traffic and credentials go over the network in plain text",
+	},
+	{
+		Name:        "tls_verify_skipped",
+		Key:         `insecure|skip[._-]?(?:tls[._-]?|ssl[._-]?)?verify`,
+		Value:       `^(?:true|yes|on)$`,
+		Description: "verification of TLS certificates is skipped, the connection is open to man-in-the-middle attacks",
+	},
+	{
+		Name:        "wide_cors",
+		Key:         `cors|allow(?:ed)?[._-]?origins?`,
+		Value:       `(?:^|[\s,\[])\*(?:$|[\s,\]])`,
+		Description: "CORS allows any origin, every site can send requests with credentials of the user",
+	},
+	{
+		Name:        "debug_enabled",
+		Key:         `(?:^|[._-])debug(?:[._-]?mode)?$`,
+		Value:       `^(?:true|yes|on)$`,
+		Description: "debug mode is turned on, it may expose internals and slow the service down",
+	},
+	{
+		Name:        "high_limit",
+		Key:         `(?:^|[._-])(?:max|limit)|(?:limit|size|connections|conns|retries|workers|burst)$`,
+		Above:       100000,
+		Description: "the limit is very high, it may let a single client exhaust memory, connections or quota",
+	},
+}
+
+// ConfigAnalyzer builds key-level diffs of JSON, YAML and TOML files and flags risky values with its rules
+type ConfigAnalyzer struct {
+	rules []compiledConfigRiskRule
+}
+
+type compiledConfigRiskRule struct {
+	ConfigRiskRule
+	key   *regexp.Regexp
+	value *regexp.Regexp
+}
+
+// configValue is a flattened value of a config key with its line in the file, 0 if the line is unknown
+type configValue struct {
+	value string
+	line  int
+}
+
+// NewConfigAnalyzer creates an analyzer with risk rules, it returns error if a rule is invalid
+func NewConfigAnalyzer(rules []ConfigRiskRule) (*ConfigAnalyzer, error) {
+	ca := &ConfigAnalyzer{rules: make([]compiledConfigRiskRule, 0, len(rules))}
+	for _, rule := range rules {
+		if rule.Key == "" {
+			return nil, errm.Errorf("key of config risk rule %q is empty", rule.Name)
+		}
+		compiled := compiledConfigRiskRule{ConfigRiskRule: rule}
+		var err error
+		if compiled.key, err = regexp.Compile("(?i)" + rule.Key); err != nil {
+			return nil, errm.Wrap(err, "invalid key of config risk rule", "rule", rule.Name)
+		}
+		if rule.Value != "" {
+			if compiled.value, err = regexp.Compile("(?i)" + rule.Value); err != nil {
+				return nil, errm.Wrap(err, "invalid value of config risk rule", "rule", rule.Name)
+			}
+		}
+		ca.rules = append(ca.rules, compiled)
+	}
+	return ca, nil
+}
+
+// IsStructuredConfig checks if the file is a JSON, YAML or TOML file whose keys can be compared
+func IsStructuredConfig(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return true
+	}
+	return false
+}
+
+// IsSecretConfigKey checks if the value of the key is likely a credential
+func IsSecretConfigKey(key string) bool {
+	return secretConfigKeyRegex.MatchString(key)
+}
+
+// Diff returns added, removed and changed keys of the config file sorted by key, the old version of a new file
+// is empty; it returns error if a version can't be parsed, so the file is reviewed by its line diff only
+func (ca *ConfigAnalyzer) Diff(filePath, before, after string) ([]model.ConfigKeyChange, error) {
+	oldValues, err := parseConfig(filePath, before)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to parse old version")
+	}
+	newValues, err := parseConfig(filePath, after)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to parse new version")
+	}
+
+	var changes []model.ConfigKeyChange
+	for key, newValue := range newValues {
+		oldValue, ok := oldValues[key]
+		switch {
+		case !ok:
+			changes = append(changes, model.ConfigKeyChange{Key: key, Kind: model.ConfigKeyAdded, NewValue: newValue.value, Line: newValue.line})
+		case oldValue.value != newValue.value:
+			changes = append(changes, model.ConfigKeyChange{Key: key, Kind: model.ConfigKeyChanged, OldValue: oldValue.value, NewValue: newValue.value, Line: newValue.line})
+		default:
+			continue
+		}
+		changes[len(changes)-1].Risk = ca.risk(key, newValue.value)
+	}
+	for key, oldValue := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			changes = append(changes, model.ConfigKeyChange{Key: key, Kind: model.ConfigKeyRemoved, OldValue: oldValue.value})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b model.ConfigKeyChange) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return changes, nil
+}
+
+// risk returns the description of the first rule matching the new value of the key, empty if there is none
+func (ca *ConfigAnalyzer) risk(key, value string) string {
+	for _, rule := range ca.rules {
+		if !rule.key.MatchString(key) {
+			continue
+		}
+		if rule.value != nil && !rule.value.MatchString(value) {
+			continue
+		}
+		if rule.Above != 0 {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil || number <= rule.Above {
+				continue
+			}
+		}
+		if rule.Description == "" {
+			return rule.Name
+		}
+		return rule.Description
+	}
+	return ""
+}
+
+// parseConfig flattens keys of the config file by its extension, empty content has no keys
+func parseConfig(filePath, content string) (map[string]configValue, error) {
+	values := make(map[string]configValue)
+	if strings.TrimSpace(content) == "" {
+		return values, nil
+	}
+
+	if strings.EqualFold(filepath.Ext(filePath), ".toml") {
+		var data map[string]any
+		if _, err := toml.Decode(content, &data); err != nil {
+			return nil, err
+		}
+		flattenTOML("", data, tomlKeyLines(content), values)
+		return values, nil
+	}
+
+	// JSON is parsed as YAML, it keeps lines of keys; every document of a multi-document YAML is indexed
+	var documents []*yaml.Node
+	decoder := yaml.NewDecoder(bytes.NewReader([]byte(content)))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, &document)
+	}
+	for i, document := range documents {
+		prefix := ""
+		if len(documents) > 1 {
+			prefix = fmt.Sprintf("[%d]", i)
+		}
+		flattenYAML(prefix, document, values)
+	}
+	return values, nil
+}
+
+// flattenYAML adds leaf values of the node to values, lists of scalars are one value
+func flattenYAML(prefix string, node *yaml.Node, values map[string]configValue) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			flattenYAML(prefix, child, values)
+		}
+	case yaml.AliasNode:
+		flattenYAML(prefix, node.Alias, values)
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			values[prefix] = configValue{value: "{}", line: node.Line}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				flattenYAML(prefix, value, values) // merged mapping of an anchor
+				continue
+			}
+			flattenYAML(joinConfigKey(prefix, key.Value), value, values)
+		}
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				items = nil
+				break
+			}
+			items = append(items, item.Value)
+		}
+		if items != nil || len(node.Content) == 0 {
+			values[prefix] = configValue{value: renderConfigList(items), line: node.Line}
+			return
+		}
+		for i, item := range node.Content {
+			flattenYAML(fmt.Sprintf("%s[%d]", prefix, i), item, values)
+		}
+	case yaml.ScalarNode:
+		values[prefix] = configValue{value: node.Value, line: node.Line}
+	}
+}
+
+// flattenTOML adds leaf values of the decoded table to values, lines are taken from the scanned file
+func flattenTOML(prefix string, data map[string]any, lines map[string]int, values map[string]configValue) {
+	if len(data) == 0 && prefix != "" {
+		values[prefix] = configValue{value: "{}", line: lines[prefix]}
+	}
+	for name, value := range data {
+		key := joinConfigKey(prefix, name)
+		switch value := value.(type) {
+		case map[string]any:
+			flattenTOML(key, value, lines, values)
+		case []map[string]any:
+			for i, table := range value {
+				flattenTOML(fmt.Sprintf("%s[%d]", key, i), table, lines, values)
+			}
+		case []any:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				if table, ok := item.(map[string]any); ok {
+					flattenTOML(fmt.Sprintf("%s[%d]", key, len(items)), table, lines, values)
+					continue
+				}
+				items = append(items, tomlScalar(item))
+			}
+			if len(items) > 0 || len(value) == 0 {
+				values[key] = configValue{value: renderConfigList(items), line: lines[key]}
+			}
+		default:
+			values[key] = configValue{value: tomlScalar(value), line: lines[key]}
+		}
+	}
+}
+
+// tomlScalar renders the decoded TOML value like it is written in the file
+func tomlScalar(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// tomlKeyLines returns lines of keys of the TOML file by scanning table headers and assignments,
+// keys of inline tables and multi-line values are not found and have no line
+func tomlKeyLines(content string) map[string]int {
+	var (
+		lines  = make(map[string]int)
+		table  string
+		arrays = make(map[string]int) // number of elements of arrays of tables
+	)
+	for i, line := range strings.Split(content, "\n") {
+		if match := tomlTableRegex.FindStringSubmatch(line); match != nil {
+			table = normalizeTOMLKey(match[2])
+			if match[1] == "[[" {
+				index := arrays[table]
+				arrays[table]++
+				table = fmt.Sprintf("%s[%d]", table, index)
+			}
+			lines[table] = i + 1
+			continue
+		}
+		if match := tomlKeyRegex.FindStringSubmatch(line); match != nil {
+			key := joinConfigKey(table, normalizeTOMLKey(match[1]))
+			if _, ok := lines[key]; !ok {
+				lines[key] = i + 1
+			}
+		}
+	}
+	return lines
+}
+
+// normalizeTOMLKey removes quotes and spaces around parts of the dotted TOML key
+func normalizeTOMLKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// joinConfigKey appends the name to the key path
+func joinConfigKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// renderConfigList renders a list of scalars like [a, b]
+func renderConfigList(items []string) string {
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// TruncateConfigValue cuts the long rendered value of a config key for prompts and comments
+func TruncateConfigValue(value string) string {
+	if len(value) <= maxConfigValueLength {
+		return value
+	}
+	return strings.ToValidUTF8(value[:maxConfigValueLength], "") + "…"
+}
@@ -0,0 +1,230 @@
+package analyze
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+// maxConfigUsageFiles limits the number of code files fetched to find readers of keys of one review revision
+const maxConfigUsageFiles = 100
+
+var (
+	// configUsageDirs are conventional directories of code loading configs, they are searched with the directories
+	// of the config file and of changed files; missing ones are skipped
+	configUsageDirs = []string{"config", "configs", "internal/config", "pkg/config", "cmd", "internal/app", "src/config"}
+
+	configKeyIndexRegex = regexp.MustCompile(`\[\d+\]`)
+	camelBoundaryRegex  = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+)
+
+// ConfigUsageFinder finds code files of the head commit that read keys of changed config files: the dotted key
+// in quotes like viper.GetString("server.timeout"), the environment variable like APP_SERVER_TIMEOUT, the struct
+// field or attribute of a multi-word key like MaxBodySize and serialization tags of the key and its parent;
+// listings and contents are cached for the revision, so config files of one review share them
+type ConfigUsageFinder struct {
+	provider interfaces.CodeProvider
+	cache    revisionCache
+}
+
+// NewConfigUsageFinder creates a finder of code reading config keys
+func NewConfigUsageFinder(provider interfaces.CodeProvider) *ConfigUsageFinder {
+	return &ConfigUsageFinder{provider: provider}
+}
+
+// configKeyMatcher matches code reading a config key: any of the patterns, or all tags in one file
+type configKeyMatcher struct {
+	patterns []*regexp.Regexp
+	tags     []*regexp.Regexp
+}
+
+// FindUsages returns paths of code files reading every key, keys without readers are omitted; the searched
+// files are code files in the directory of the config file and its parents, in directories of changed files
+// and conventional config packages, at most maxConfigUsageFiles of them; it returns nothing if the provider
+// can't list files, error is returned only if the context is done
+func (f *ConfigUsageFinder) FindUsages(ctx context.Context, request model.ReviewRequest, configPath string, keys []string) (map[string][]string, error) {
+	lister, ok := f.provider.(interfaces.FileLister)
+	if !ok || len(keys) == 0 {
+		return nil, nil
+	}
+
+	files, err := f.searchFiles(ctx, lister, request, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := searchRevision(request)
+	usages := make(map[string][]string)
+	for _, key := range keys {
+		cacheKey := "usage:" + configPath + ":" + key
+		if found, ok := f.cache.get(revision, cacheKey); ok {
+			if found != "" {
+				usages[key] = strings.Split(found, "\n")
+			}
+			continue
+		}
+
+		matcher := newConfigKeyMatcher(key)
+		var found []string
+		for _, filePath := range files {
+			content, err := f.content(ctx, request, revision, filePath)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+				continue
+			}
+			if matcher.matches(content) {
+				found = append(found, filePath)
+			}
+		}
+		slices.Sort(found)
+		f.cache.set(revision, cacheKey, strings.Join(found, "\n"))
+		if len(found) > 0 {
+			usages[key] = found
+		}
+	}
+	return usages, nil
+}
+
+// searchFiles returns paths of code files to search for readers of keys, test files and the config files
+// of the merge request are skipped
+func (f *ConfigUsageFinder) searchFiles(ctx context.Context, lister interfaces.FileLister, request model.ReviewRequest, configPath string) ([]string, error) {
+	dirs := searchDirs(configPath)
+	for _, change := range request.Changes {
+		if dir := path.Dir(change.NewPath); detectLanguage(change.NewPath) != LanguageUnknown && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, dir := range configUsageDirs {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	revision := searchRevision(request)
+	var files []string
+	for _, dir := range dirs {
+		listing, ok := f.cache.get(revision, "list:"+dir)
+		if !ok {
+			listDir := dir
+			if listDir == "." {
+				listDir = "" // repository root
+			}
+			// Error means the directory doesn't exist at the head commit
+			paths, err := lister.ListFiles(ctx, request.ProjectID, listDir, request.MergeRequest.SHA)
+			if err != nil && ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			listing = strings.Join(paths, "\n")
+			f.cache.set(revision, "list:"+dir, listing)
+		}
+
+		for _, candidate := range strings.Split(listing, "\n") {
+			if candidate == "" || detectLanguage(candidate) == LanguageUnknown || IsTestFile(candidate, DefaultTestFileConventions) {
+				continue
+			}
+			if len(files) >= maxConfigUsageFiles {
+				return files, nil
+			}
+			files = append(files, candidate)
+		}
+	}
+	return files, nil
+}
+
+// content returns the content of the file at the head commit, it is fetched once per revision
+func (f *ConfigUsageFinder) content(ctx context.Context, request model.ReviewRequest, revision, filePath string) (string, error) {
+	if content, ok := f.cache.get(revision, "file:"+filePath); ok {
+		return content, nil
+	}
+	content, err := FetchFileContent(ctx, f.provider, request.ProjectID, filePath, request.MergeRequest.SHA)
+	if err != nil {
+		return "", err
+	}
+	f.cache.set(revision, "file:"+filePath, content)
+	return content, nil
+}
+
+// newConfigKeyMatcher builds patterns of code reading the key, indexes of list elements are dropped;
+// one-word keys like timeout are matched only by tags together with their parent, they are too common alone
+func newConfigKeyMatcher(key string) configKeyMatcher {
+	var parts []string
+	for _, part := range strings.Split(configKeyIndexRegex.ReplaceAllString(key, ""), ".") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return configKeyMatcher{}
+	}
+
+	var words []string
+	for _, part := range parts {
+		words = append(words, keyWords(part)...)
+	}
+	last := parts[len(parts)-1]
+	lastWords := keyWords(last)
+
+	var matcher configKeyMatcher
+	if len(parts) > 1 || len(lastWords) > 1 {
+		quoted := regexp.QuoteMeta(strings.Join(parts, "."))
+		matcher.patterns = append(matcher.patterns, regexp.MustCompile(`["'`+"`"+`]`+quoted+`["'`+"`"+`]`))
+	}
+	if len(words) > 1 {
+		env := regexp.QuoteMeta(strings.ToUpper(strings.Join(words, "_")))
+		matcher.patterns = append(matcher.patterns, regexp.MustCompile(`\b(?:[A-Z0-9]+_)*`+env+`\b`))
+	}
+	if len(lastWords) > 1 {
+		var field strings.Builder
+		for _, word := range lastWords {
+			field.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+		matcher.patterns = append(matcher.patterns,
+			regexp.MustCompile(`\b`+regexp.QuoteMeta(field.String())+`\b`),
+			regexp.MustCompile(`\.`+regexp.QuoteMeta(last)+`\b`))
+	}
+
+	tagged := parts[len(parts)-1:]
+	if len(parts) > 1 {
+		tagged = parts[len(parts)-2:]
+	}
+	if len(parts) > 1 || len(lastWords) > 1 {
+		for _, part := range tagged {
+			matcher.tags = append(matcher.tags, regexp.MustCompile(`(?:yaml|json|toml|mapstructure|koanf|env):"`+regexp.QuoteMeta(part)+`[",]`))
+		}
+	}
+	return matcher
+}
+
+// matches checks if the code reads the key
+func (m configKeyMatcher) matches(content string) bool {
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(content) {
+			return true
+		}
+	}
+	if len(m.tags) == 0 {
+		return false
+	}
+	for _, tag := range m.tags {
+		if !tag.MatchString(content) {
+			return false
+		}
+	}
+	return true
+}
+
+// keyWords splits the part of the key into lowercase words by separators and camel case
+func keyWords(part string) []string {
+	part = camelBoundaryRegex.ReplaceAllString(part, "${1}_${2}")
+	return strings.FieldsFunc(strings.ToLower(part), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
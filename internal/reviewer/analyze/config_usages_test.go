@@ -0,0 +1,86 @@
+package analyze
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+func TestConfigKeyMatcher(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		code string
+		want bool
+	}{
+		{"dotted key in quotes", "server.read_timeout", `timeout := viper.GetDuration("server.read_timeout")`, true},
+		{"dotted key in single quotes", "server.read_timeout", `timeout = config['server.read_timeout']`, true},
+		{"environment variable", "server.read_timeout", `os.Getenv("APP_SERVER_READ_TIMEOUT")`, true},
+		{"struct field", "server.read_timeout", "ReadTimeout time.Duration", true},
+		{"attribute", "server.read_timeout", "timeout = settings.read_timeout", true},
+		{"camel case key", "server.maxBodySize", "limit := cfg.Server.MaxBodySize", true},
+		{"tags of the key and its parent", "server.timeout", "Server struct {\n\tTimeout int `yaml:\"timeout\"`\n} `yaml:\"server\"`", true},
+		{"tag of the key only", "server.timeout", "Timeout int `yaml:\"timeout\"`", false},
+		{"key with an index", "servers[0].read_timeout", `viper.Get("servers.read_timeout")`, true},
+		{"other key with the same suffix", "server.read_timeout", `viper.GetDuration("client.read_timeout_ms")`, false},
+		{"one-word key alone", "timeout", "ctx, cancel := context.WithTimeout(ctx, timeout)", false},
+		{"one-word key with its tag", "timeout", "Timeout int `json:\"timeout\"`", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newConfigKeyMatcher(tt.key).matches(tt.code); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindUsages(t *testing.T) {
+	files := map[string]string{
+		"deploy/config.yaml":              "database:\n  max_conns: 20\nserver:\n  port: 8080\n",
+		"internal/config/config.go":       "package config\n\ntype Database struct {\n\tMaxConns int `yaml:\"max_conns\"`\n}\n",
+		"internal/config/config_test.go":  "package config\n\nvar _ = \"database.max_conns\"\n",
+		"cmd/main.go":                     "package main\n\nvar conns = viper.GetInt(\"database.max_conns\")\n",
+		"internal/store/store.go":         "package store\n\nvar limit = os.Getenv(\"DATABASE_MAX_CONNS\")\n",
+		"internal/unrelated/unrelated.go": "package unrelated\n\nvar conns = viper.GetInt(\"database.max_conns\")\n",
+	}
+	request := model.ReviewRequest{
+		ProjectID:    "app",
+		MergeRequest: &model.MergeRequest{TargetBranch: "main", SHA: "head"},
+		Changes: []*model.FileDiff{
+			{NewPath: "deploy/config.yaml"},
+			{NewPath: "internal/store/store.go"},
+		},
+	}
+	keys := []string{"database.max_conns", "server.port"}
+	// Only code files in searched directories read the key, unrelated/ is not searched and test files are skipped
+	want := map[string][]string{"database.max_conns": {"cmd/main.go", "internal/config/config.go", "internal/store/store.go"}}
+
+	tree := &treeProvider{files: files}
+	finder := NewConfigUsageFinder(listingTreeProvider{tree})
+	for range 2 {
+		got, err := finder.FindUsages(context.Background(), request, "deploy/config.yaml", keys)
+		if err != nil {
+			t.Fatalf("FindUsages() error = %v", err)
+		}
+		if !maps.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("FindUsages() = %v, want %v", got, want)
+		}
+	}
+	// Contents are fetched once for both keys and both searches
+	for _, filePath := range want["database.max_conns"] {
+		if got := tree.fetchCount(filePath); got != 1 {
+			t.Errorf("fetches of %s = %d, want 1", filePath, got)
+		}
+	}
+
+	// Files are not searched without listings
+	got, err := NewConfigUsageFinder(&treeProvider{files: files}).FindUsages(context.Background(), request, "deploy/config.yaml", keys)
+	if err != nil || got != nil {
+		t.Errorf("FindUsages() without listing = %v, %v, want nil", got, err)
+	}
+}
@@ -0,0 +1,181 @@
+package analyze
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// ConstantChange is an exported Go constant whose value differs between two versions of a file
+type ConstantChange struct {
+	Name     string
+	Type     string // name of the constant type, empty for untyped constants
+	OldValue string
+	NewValue string
+	// Line is the line of the constant in the new version
+	Line int
+	// Shifted is true if the declaration of the constant is unchanged and its value moved because of
+	// a change of an iota sequence, like a value inserted in the middle of an enum
+	Shifted bool
+	// CauseLine is the line of the first added or changed constant of the declaration in the new version,
+	// it is the line of the constant itself if nothing is added before it
+	CauseLine int
+}
+
+// goConstSpec is a spec of a const declaration, source has no comments and implicit iota repetitions have only names
+type goConstSpec struct {
+	source string
+	line   int
+}
+
+// goConstant is an evaluated exported constant of a file
+type goConstant struct {
+	typeName string
+	value    string
+	line     int
+	spec     string // source of its spec
+}
+
+// FindGoConstantChanges returns exported constants of both versions whose values differ, values are evaluated
+// with iota; constants depending on imported packages can't be evaluated and are skipped
+func FindGoConstantChanges(before, after string) ([]ConstantChange, error) {
+	oldFile, err := parseGoFile("", before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old version: %w", err)
+	}
+	newFile, err := parseGoFile("", after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new version: %w", err)
+	}
+	return findGoConstantChanges(oldFile, newFile), nil
+}
+
+// findGoConstantChanges returns exported constants of the parsed versions whose values differ
+func findGoConstantChanges(before, after *parsedFile) []ConstantChange {
+	oldConsts, oldDecls := goConstantValues(before)
+	newConsts, newDecls := goConstantValues(after)
+
+	oldSpecs := make(map[string]struct{})
+	for _, specs := range oldDecls {
+		for _, spec := range specs {
+			oldSpecs[spec.source] = struct{}{}
+		}
+	}
+
+	var changes []ConstantChange
+	for name, newConst := range newConsts {
+		oldConst, ok := oldConsts[name]
+		if !ok || oldConst.value == newConst.value {
+			continue
+		}
+		change := ConstantChange{
+			Name:      name,
+			Type:      newConst.typeName,
+			OldValue:  oldConst.value,
+			NewValue:  newConst.value,
+			Line:      newConst.line,
+			Shifted:   oldConst.spec == newConst.spec,
+			CauseLine: newConst.line,
+		}
+		if change.Shifted {
+			change.CauseLine = firstNewSpecLine(newDecls, newConst.line, oldSpecs)
+		}
+		changes = append(changes, change)
+	}
+
+	slices.SortFunc(changes, func(a, b ConstantChange) int { return a.Line - b.Line })
+	return changes
+}
+
+// goConstantValues type-checks the file alone and returns its exported constants by name and specs of const declarations
+func goConstantValues(parsed *parsedFile) (map[string]goConstant, [][]goConstSpec) {
+	fset, file, content := parsed.fset, parsed.file, parsed.src
+
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{
+		Error: func(error) {}, // imports are not resolved, constants using them are skipped
+	}
+	_, _ = conf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+
+	var decls [][]goConstSpec
+
+	consts := make(map[string]goConstant)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+
+		var specs []goConstSpec
+		for _, spec := range gen.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			source := strings.Join(strings.Fields(content[fset.Position(valueSpec.Pos()).Offset:fset.Position(valueSpec.End()).Offset]), " ")
+			specs = append(specs, goConstSpec{source: source, line: fset.Position(valueSpec.Pos()).Line})
+
+			for _, name := range valueSpec.Names {
+				obj, ok := info.Defs[name].(*types.Const)
+				if !ok || !name.IsExported() || obj.Val().Kind() == constant.Unknown {
+					continue
+				}
+				typeName := types.TypeString(obj.Type(), func(*types.Package) string { return "" })
+				if basic, ok := obj.Type().(*types.Basic); ok && basic.Info()&types.IsUntyped != 0 {
+					typeName = ""
+				}
+				consts[name.Name] = goConstant{
+					typeName: typeName,
+					value:    obj.Val().String(),
+					line:     fset.Position(name.Pos()).Line,
+					spec:     source,
+				}
+			}
+		}
+		decls = append(decls, specs)
+	}
+
+	return consts, decls
+}
+
+// firstNewSpecLine returns the line of the first spec of the declaration containing the line that is not
+// in the known specs, the line itself is returned if all specs before it are known
+func firstNewSpecLine(decls [][]goConstSpec, line int, known map[string]struct{}) int {
+	for _, specs := range decls {
+		if len(specs) == 0 || line < specs[0].line || line > specs[len(specs)-1].line {
+			continue
+		}
+		for _, spec := range specs {
+			if spec.line > line {
+				break
+			}
+			if _, ok := known[spec.source]; !ok {
+				return spec.line
+			}
+		}
+	}
+	return line
+}
+
+// constantBreakingChanges returns behavior breaking changes of changed exported constants
+func constantBreakingChanges(changes []ConstantChange) []BreakingChange {
+	breaking := make([]BreakingChange, 0, len(changes))
+	for _, change := range changes {
+		item := BreakingChange{
+			Type:        "behavior",
+			Entity:      change.Name,
+			Description: fmt.Sprintf("value of exported constant %s changed from %s to %s", change.Name, change.OldValue, change.NewValue),
+			Mitigation:  "check code and stored data that rely on the old value",
+		}
+		if change.Shifted {
+			item.Description = fmt.Sprintf("value of exported constant %s shifted from %s to %s by a change of its iota sequence", change.Name, change.OldValue, change.NewValue)
+			item.Mitigation = "add new enum values at the end of the sequence or assign explicit values"
+		}
+		breaking = append(breaking, item)
+	}
+	return breaking
+}
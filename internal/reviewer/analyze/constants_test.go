@@ -0,0 +1,96 @@
+package analyze
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFindGoConstantChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []ConstantChange
+	}{
+		{
+			name:   "changed value",
+			before: "package p\n\nconst DefaultTimeout = 30\n",
+			after:  "package p\n\nconst DefaultTimeout = 60\n",
+			want:   []ConstantChange{{Name: "DefaultTimeout", OldValue: "30", NewValue: "60", Line: 3, CauseLine: 3}},
+		},
+		{
+			name: "enum gains a middle value",
+			before: "package p\n\ntype Status int\n\nconst (\n" +
+				"\tStatusNew Status = iota\n\tStatusDone\n\tStatusFailed\n)\n",
+			after: "package p\n\ntype Status int\n\nconst (\n" +
+				"\tStatusNew Status = iota\n\tStatusRunning\n\tStatusDone\n\tStatusFailed\n)\n",
+			want: []ConstantChange{
+				{Name: "StatusDone", Type: "Status", OldValue: "1", NewValue: "2", Line: 8, Shifted: true, CauseLine: 7},
+				{Name: "StatusFailed", Type: "Status", OldValue: "2", NewValue: "3", Line: 9, Shifted: true, CauseLine: 7},
+			},
+		},
+		{
+			name: "enum gains a last value",
+			before: "package p\n\nconst (\n" +
+				"\tLow = iota\n\tHigh\n)\n",
+			after: "package p\n\nconst (\n" +
+				"\tLow = iota\n\tHigh\n\tCritical\n)\n",
+		},
+		{
+			name:   "unexported and imported constants",
+			before: "package p\n\nimport \"time\"\n\nconst limit = 1\n\nconst Wait = time.Second\n",
+			after:  "package p\n\nimport \"time\"\n\nconst limit = 2\n\nconst Wait = 2 * time.Second\n",
+		},
+		{
+			name:   "string constant",
+			before: "package p\n\nconst Version = \"v1\"\n",
+			after:  "package p\n\nconst Version = \"v2\"\n",
+			want:   []ConstantChange{{Name: "Version", OldValue: `"v1"`, NewValue: `"v2"`, Line: 3, CauseLine: 3}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindGoConstantChanges(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("FindGoConstantChanges() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FindGoConstantChanges() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConstantBreakingChanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		change     ConstantChange
+		wantPrefix string
+	}{
+		{
+			name:       "changed value",
+			change:     ConstantChange{Name: "DefaultTimeout", OldValue: "30", NewValue: "60"},
+			wantPrefix: "value of exported constant DefaultTimeout changed from 30 to 60",
+		},
+		{
+			name:       "shifted enum value",
+			change:     ConstantChange{Name: "StatusDone", Type: "Status", OldValue: "1", NewValue: "2", Shifted: true},
+			wantPrefix: "value of exported constant StatusDone shifted from 1 to 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaking := constantBreakingChanges([]ConstantChange{tt.change})
+			if len(breaking) != 1 {
+				t.Fatalf("constantBreakingChanges() = %d changes, want 1", len(breaking))
+			}
+			got := breaking[0]
+			if got.Type != "behavior" || got.Entity != tt.change.Name || !strings.HasPrefix(got.Description, tt.wantPrefix) {
+				t.Errorf("constantBreakingChanges() = %+v, want behavior change of %s with %q", got, tt.change.Name, tt.wantPrefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package analyze
+
+import (
+	"context"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// ErrContentUnavailable is returned when file content can't be fetched because there is no provider
+var ErrContentUnavailable = errm.New("file content is not available")
+
+// FetchFileContent gets file content from the provider normalized for analysis, it is nil-safe so analyzers
+// can be used without a provider and fall back to diff-only analysis; binary content returns model.ErrBinaryContent
+func FetchFileContent(ctx context.Context, provider interfaces.CodeProvider, projectID, filePath, ref string) (string, error) {
+	if provider == nil {
+		return "", ErrContentUnavailable
+	}
+	raw, err := provider.GetFileContent(ctx, projectID, filePath, ref)
+	if err != nil {
+		return "", err
+	}
+	return model.NormalizeContent(raw).AnalysisText()
+}
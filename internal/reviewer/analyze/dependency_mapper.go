@@ -1,10 +1,14 @@
 package analyze
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/maxbolgarin/codry/internal/model"
@@ -48,6 +52,9 @@ type CodeEntity struct {
 	EndLine       int        `json:"end_line"`       // end line in file
 	IsExported    bool       `json:"is_exported"`    // whether it's exported
 	Signature     string     `json:"signature"`      // function/method signature
+	Receiver      string     `json:"receiver"`       // method receiver with its name
+	Parameters    []string   `json:"parameters"`     // function/method parameters
+	Returns       []string   `json:"returns"`        // function/method results
 	DocComment    string     `json:"doc_comment"`    // documentation comment
 	CodeSnippet   string     `json:"code_snippet"`   // actual code
 	Complexity    int        `json:"complexity"`     // cyclomatic complexity
@@ -142,28 +149,18 @@ type ImportUsage struct {
 
 // MapDependencies creates a comprehensive dependency graph for changed entities
 func (dm *DependencyMapper) MapDependencies(ctx context.Context, request model.ReviewRequest, changedEntities []ChangedEntity, filePath string) (*DependencyGraph, error) {
-	log := dm.log.WithFields("file", filePath, "entities", len(changedEntities))
+	log := model.ContextLogger(ctx, dm.log).WithFields("file", filePath, "entities", len(changedEntities))
 	log.Debug("starting dependency mapping")
 
-	graph := &DependencyGraph{
-		Entities:     make(map[string]*CodeEntity),
-		Dependencies: make(map[string][]Relationship),
-		Dependents:   make(map[string][]Relationship),
-		CallGraph:    make(map[string][]FunctionCall),
-		TypeUsage:    make(map[string][]TypeUsage),
-		ImportGraph:  make(map[string][]ImportUsage),
-		PackageScope: make(map[string][]string),
-	}
-
-	// Convert changed entities to code entities
-	for _, entity := range changedEntities {
-		codeEntity := dm.convertToCodeEntity(entity, filePath)
-		graph.Entities[codeEntity.ID] = codeEntity
-	}
+	graph := dm.NewEntitiesGraph(changedEntities, filePath)
 
 	// Map direct dependencies for each changed entity
 	for _, entity := range changedEntities {
-		entityID := dm.generateEntityID(entity.Name, entity.Type, filePath)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entityID := EntityID(filePath, entity.Type, entity.Name, entity.Receiver)
 
 		// Find function calls
 		if entity.Type == EntityTypeFunction || entity.Type == EntityTypeMethod {
@@ -205,6 +202,9 @@ func (dm *DependencyMapper) MapDependencies(ctx context.Context, request model.R
 	}
 
 	// Analyze import relationships
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	importUsages, err := dm.analyzeImports(ctx, request, filePath)
 	if err != nil {
 		log.Warn("failed to analyze imports", "error", err)
@@ -215,6 +215,9 @@ func (dm *DependencyMapper) MapDependencies(ctx context.Context, request model.R
 	}
 
 	// Build package scope map
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	err = dm.buildPackageScope(ctx, request, graph, filePath)
 	if err != nil {
 		log.Warn("failed to build package scope", "error", err)
@@ -228,10 +231,32 @@ func (dm *DependencyMapper) MapDependencies(ctx context.Context, request model.R
 	return graph, nil
 }
 
+// NewEntitiesGraph creates a graph with changed entities only, it doesn't need file content
+// and is used as is when only the diff is available
+func (dm *DependencyMapper) NewEntitiesGraph(changedEntities []ChangedEntity, filePath string) *DependencyGraph {
+	graph := &DependencyGraph{
+		Entities:     make(map[string]*CodeEntity),
+		Dependencies: make(map[string][]Relationship),
+		Dependents:   make(map[string][]Relationship),
+		CallGraph:    make(map[string][]FunctionCall),
+		TypeUsage:    make(map[string][]TypeUsage),
+		ImportGraph:  make(map[string][]ImportUsage),
+		PackageScope: make(map[string][]string),
+	}
+
+	// Convert changed entities to code entities
+	for _, entity := range changedEntities {
+		codeEntity := dm.convertToCodeEntity(entity, filePath)
+		graph.Entities[codeEntity.ID] = codeEntity
+	}
+
+	return graph
+}
+
 // convertToCodeEntity converts a ChangedEntity to a CodeEntity
 func (dm *DependencyMapper) convertToCodeEntity(entity ChangedEntity, filePath string) *CodeEntity {
 	return &CodeEntity{
-		ID:            dm.generateEntityID(entity.Name, entity.Type, filePath),
+		ID:            EntityID(filePath, entity.Type, entity.Name, entity.Receiver),
 		Name:          entity.Name,
 		Type:          entity.Type,
 		Package:       dm.extractPackageFromPath(filePath),
@@ -240,16 +265,37 @@ func (dm *DependencyMapper) convertToCodeEntity(entity ChangedEntity, filePath s
 		EndLine:       entity.EndLine,
 		IsExported:    entity.IsExported,
 		Signature:     entity.Signature,
+		Receiver:      entity.Receiver,
+		Parameters:    entity.Parameters,
+		Returns:       entity.Returns,
 		CodeSnippet:   entity.AfterCode,
 		BusinessArea:  dm.inferBusinessArea(filePath, entity.Name),
 		SecurityLevel: dm.inferSecurityLevel(filePath, entity.Name, entity.AfterCode),
 	}
 }
 
-// generateEntityID generates a unique ID for an entity
-func (dm *DependencyMapper) generateEntityID(name string, entityType EntityType, filePath string) string {
-	pkg := dm.extractPackageFromPath(filePath)
-	return fmt.Sprintf("%s.%s.%s", pkg, string(entityType), name)
+// EntityID returns the ID of the entity in dependency graphs: the directory of its file, its type and its name
+// qualified by the receiver type for methods, e.g. "internal/store.method.Store.Get"; directories and receivers
+// keep entities of packages with the same name and methods of different types apart
+func EntityID(filePath string, entityType EntityType, name, receiver string) string {
+	dir := path.Dir(filepath.ToSlash(filePath))
+	if dir == "." {
+		dir = "" // file in the root or unknown file
+	}
+	if receiverType := receiverTypeOf(receiver); receiverType != "" {
+		name = receiverType + "." + name
+	}
+	return fmt.Sprintf("%s.%s.%s", dir, string(entityType), name)
+}
+
+// receiverTypeOf returns the type of the receiver like "s *Server[T]" without its name, pointer and type parameters
+func receiverTypeOf(receiver string) string {
+	receiver, _, _ = strings.Cut(receiver, "[")
+	fields := strings.Fields(receiver)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimLeft(fields[len(fields)-1], "*")
 }
 
 // extractPackageFromPath extracts package name from file path
@@ -272,19 +318,13 @@ func (dm *DependencyMapper) findFunctionCalls(ctx context.Context, request model
 	functionCallRegex := regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*(?:\.[a-zA-Z_][a-zA-Z0-9_]*)*)\s*\(`)
 	methodCallRegex := regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*\.\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\(`)
 
-	lines := strings.Split(code, "\n")
+	// Calls in comments and string literals are not calls, positions of the rest are kept
+	originalLines := strings.Split(code, "\n")
+	lines := strings.Split(StripCode(code, detectLanguage(filePath)), "\n")
 	for lineNum, line := range lines {
-		originalLine := line
+		originalLine := originalLines[lineNum]
 		line = strings.TrimSpace(line)
-
-		// Skip comment lines completely
-		if dm.isCommentLine(line) {
-			continue
-		}
-
-		// Remove inline comments from the line
-		line = dm.removeInlineComments(line)
-		if strings.TrimSpace(line) == "" {
+		if line == "" {
 			continue
 		}
 
@@ -326,69 +366,6 @@ func (dm *DependencyMapper) findFunctionCalls(ctx context.Context, request model
 	return calls, nil
 }
 
-// isCommentLine checks if a line is a comment (handles Go, JS, Python, etc.)
-func (dm *DependencyMapper) isCommentLine(line string) bool {
-	trimmed := strings.TrimSpace(line)
-	return strings.HasPrefix(trimmed, "//") || // Go, JS, C++
-		strings.HasPrefix(trimmed, "#") || // Python, shell
-		strings.HasPrefix(trimmed, "/*") || // Multi-line comments
-		strings.HasPrefix(trimmed, "*") || // Inside multi-line comments
-		strings.HasPrefix(trimmed, "<!--") || // HTML comments
-		strings.HasPrefix(trimmed, "--") // SQL comments
-}
-
-// removeInlineComments removes inline comments from a line
-func (dm *DependencyMapper) removeInlineComments(line string) string {
-	// Handle different comment styles
-	commentMarkers := []string{"//", "#"}
-
-	for _, marker := range commentMarkers {
-		if idx := strings.Index(line, marker); idx != -1 {
-			// Make sure it's not inside a string literal
-			if !dm.isInsideStringLiteral(line, idx) {
-				line = line[:idx]
-			}
-		}
-	}
-
-	return line
-}
-
-// isInsideStringLiteral checks if the given index is inside a string literal
-func (dm *DependencyMapper) isInsideStringLiteral(line string, index int) bool {
-	inSingleQuote := false
-	inDoubleQuote := false
-	inBacktick := false
-
-	for i, char := range line {
-		if i >= index {
-			break
-		}
-
-		switch char {
-		case '\'':
-			if !inDoubleQuote && !inBacktick {
-				inSingleQuote = !inSingleQuote
-			}
-		case '"':
-			if !inSingleQuote && !inBacktick {
-				inDoubleQuote = !inDoubleQuote
-			}
-		case '`':
-			if !inSingleQuote && !inDoubleQuote {
-				inBacktick = !inBacktick
-			}
-		case '\\':
-			// Skip escaped characters
-			if i+1 < len(line) {
-				i++
-			}
-		}
-	}
-
-	return inSingleQuote || inDoubleQuote || inBacktick
-}
-
 // isValidFunctionCall validates that the matched text is actually a function call
 func (dm *DependencyMapper) isValidFunctionCall(name string) bool {
 	// Skip if it looks like a comment artifact
@@ -462,7 +439,7 @@ func (dm *DependencyMapper) findTypeUsages(ctx context.Context, request model.Re
 				typeName := dm.cleanTypeName(match[1])
 				usages = append(usages, TypeUsage{
 					TypeName:     typeName,
-					TypeID:       dm.generateEntityID(typeName, EntityTypeType, ""),
+					TypeID:       EntityID(filePath, EntityTypeType, typeName, ""),
 					UsageContext: UsageVariable,
 					FilePath:     filePath,
 					LineNumber:   entity.StartLine + lineNum,
@@ -480,7 +457,7 @@ func (dm *DependencyMapper) findTypeUsages(ctx context.Context, request model.Re
 				typeName := dm.cleanTypeName(match[1])
 				usages = append(usages, TypeUsage{
 					TypeName:     typeName,
-					TypeID:       dm.generateEntityID(typeName, EntityTypeType, ""),
+					TypeID:       EntityID(filePath, EntityTypeType, typeName, ""),
 					UsageContext: UsageParameter,
 					FilePath:     filePath,
 					LineNumber:   entity.StartLine + lineNum,
@@ -498,7 +475,7 @@ func (dm *DependencyMapper) findTypeUsages(ctx context.Context, request model.Re
 				typeName := dm.cleanTypeName(match[1])
 				usages = append(usages, TypeUsage{
 					TypeName:     typeName,
-					TypeID:       dm.generateEntityID(typeName, EntityTypeType, ""),
+					TypeID:       EntityID(filePath, EntityTypeType, typeName, ""),
 					UsageContext: UsageReturn,
 					FilePath:     filePath,
 					LineNumber:   entity.StartLine + lineNum,
@@ -575,69 +552,34 @@ func (dm *DependencyMapper) findDirectDependencies(ctx context.Context, request
 	return dependencies, nil
 }
 
-// findDependents finds entities that depend on the given entity
-func (dm *DependencyMapper) findDependents(ctx context.Context, request model.ReviewRequest, entity ChangedEntity, filePath string) ([]Relationship, error) {
-	var dependents []Relationship
-
-	// This would require searching the entire codebase for references
-	// For now, implement a basic version that searches in the same package
-	packageDir := filepath.Dir(filePath)
-
-	// Search for usages in package files (simplified implementation)
-	commonFiles := []string{
-		"config.go", "types.go", "constants.go", "errors.go", "utils.go",
-		"helpers.go", "models.go", "handlers.go", "service.go", "repository.go",
-	}
-
-	for _, filename := range commonFiles {
-		fullPath := filepath.Join(packageDir, filename)
-		if fullPath == filePath {
-			continue // Skip the same file
+// findDependents converts callers found by the semantic analysis to relationships
+func (dm *DependencyMapper) findDependents(_ context.Context, _ model.ReviewRequest, entity ChangedEntity, _ string) ([]Relationship, error) {
+	dependents := make([]Relationship, 0, len(entity.Dependents))
+	for _, dependent := range entity.Dependents {
+		relType := RelationshipFunctionCall
+		if entity.Type == EntityTypeMethod {
+			relType = RelationshipMethodCall
 		}
-
-		content, err := dm.provider.GetFileContent(ctx, request.ProjectID, fullPath, request.MergeRequest.TargetBranch)
-		if err != nil {
-			continue // File doesn't exist or can't be read
-		}
-
-		// Search for references to the entity
-		usages := dm.findEntityUsages(entity.Name, content, fullPath)
-		dependents = append(dependents, usages...)
+		dependents = append(dependents, Relationship{
+			Target:      dependent.Name,
+			Type:        relType,
+			Context:     dependent.UsageContext,
+			FilePath:    dependent.FilePath,
+			LineNumber:  dependent.LineNumber,
+			CodeSnippet: dependent.CodeSnippet,
+			Strength:    0.8, // High strength for direct calls
+		})
 	}
 
 	return dependents, nil
 }
 
-// findEntityUsages finds usages of an entity in code content
-func (dm *DependencyMapper) findEntityUsages(entityName, content, filePath string) []Relationship {
-	var usages []Relationship
-
-	lines := strings.Split(content, "\n")
-	for lineNum, line := range lines {
-		if strings.Contains(line, entityName) {
-			// Create a basic usage relationship
-			usages = append(usages, Relationship{
-				Target:      entityName,
-				Type:        RelationshipFunctionCall, // Simplified - could be more specific
-				Context:     "usage",
-				FilePath:    filePath,
-				LineNumber:  lineNum + 1,
-				CodeSnippet: strings.TrimSpace(line),
-				Strength:    0.5,
-				IsExternal:  false,
-			})
-		}
-	}
-
-	return usages
-}
-
 // analyzeImports analyzes import relationships
 func (dm *DependencyMapper) analyzeImports(ctx context.Context, request model.ReviewRequest, filePath string) (map[string][]ImportUsage, error) {
 	importUsages := make(map[string][]ImportUsage)
 
 	// Get file content
-	content, err := dm.provider.GetFileContent(ctx, request.ProjectID, filePath, request.MergeRequest.SHA)
+	content, err := FetchFileContent(ctx, dm.provider, request.ProjectID, filePath, request.MergeRequest.SHA)
 	if err != nil {
 		return importUsages, fmt.Errorf("failed to get file content: %w", err)
 	}
@@ -697,8 +639,8 @@ func (dm *DependencyMapper) buildPackageScope(ctx context.Context, request model
 	packageName := dm.extractPackageFromPath(filePath)
 
 	var entityIDs []string
-	for entityID, entity := range graph.Entities {
-		if entity.Package == packageName {
+	for _, entityID := range slices.Sorted(maps.Keys(graph.Entities)) {
+		if graph.Entities[entityID].Package == packageName {
 			entityIDs = append(entityIDs, entityID)
 		}
 	}
@@ -707,6 +649,21 @@ func (dm *DependencyMapper) buildPackageScope(ctx context.Context, request model
 	return nil
 }
 
+// compareRelationships orders relationships by strength descending,
+// ties are broken by target, file path and line number to keep the order deterministic
+func compareRelationships(a, b Relationship) int {
+	if c := cmp.Compare(b.Strength, a.Strength); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Target, b.Target); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.FilePath, b.FilePath); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.LineNumber, b.LineNumber)
+}
+
 // isExternalEntity checks if an entity is external to the project
 func (dm *DependencyMapper) isExternalEntity(entityName string) bool {
 	// Simple heuristic - if it contains a dot and starts with lowercase, it's likely external
@@ -0,0 +1,145 @@
+package analyze
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// cancelingProvider cancels the context after the number of fetched files and counts all fetches,
+// 0 doesn't cancel
+type cancelingProvider struct {
+	interfaces.CodeProvider
+	cancel      context.CancelFunc
+	cancelAfter int
+
+	mu      sync.Mutex
+	fetches int
+}
+
+func (p *cancelingProvider) GetFileContent(ctx context.Context, projectID, filePath, commitSHA string) (string, error) {
+	p.mu.Lock()
+	p.fetches++
+	if p.fetches == p.cancelAfter {
+		p.cancel()
+	}
+	p.mu.Unlock()
+	return p.CodeProvider.GetFileContent(ctx, projectID, filePath, commitSHA)
+}
+
+func (p *cancelingProvider) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetches
+}
+
+func TestMapDependenciesStopsOnCancel(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		cancelAfter int
+	}{
+		{"before mapping", "testdata/go/multi_hunk", 0},
+		{"before mapping of methods", "testdata/go/same_method_name", 0},
+		{"while reading imports", "testdata/go/multi_hunk", 1},
+		{"while reading imports of methods", "testdata/go/same_method_name", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := loadFixture(t, tt.fixture)
+			analysis, err := f.semantic.AnalyzeChanges(context.Background(), f.request, f.fileDiff)
+			if err != nil {
+				t.Fatalf("AnalyzeChanges() error = %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelAfter == 0 {
+				cancel()
+			}
+			provider := &cancelingProvider{CodeProvider: f.mapper.provider, cancel: cancel, cancelAfter: tt.cancelAfter}
+			mapper := NewDependencyMapper(provider)
+
+			_, err = mapper.MapDependencies(ctx, f.request, analysis.ChangedEntities, f.fileDiff.NewPath)
+			if !errm.Is(err, context.Canceled) {
+				t.Errorf("MapDependencies() error = %v, want %v", err, context.Canceled)
+			}
+			if got := provider.count(); got != tt.cancelAfter {
+				t.Errorf("fetches = %d, want %d", got, tt.cancelAfter)
+			}
+
+			// Searches don't continue in the background after the return
+			time.Sleep(50 * time.Millisecond)
+			if got := provider.count(); got != tt.cancelAfter {
+				t.Errorf("fetches after return = %d, want %d", got, tt.cancelAfter)
+			}
+		})
+	}
+}
+
+func TestEntityID(t *testing.T) {
+	tests := []struct {
+		name       string
+		filePath   string
+		entityType EntityType
+		entity     string
+		receiver   string
+		want       string
+	}{
+		{"function in the root", "main.go", EntityTypeFunction, "main", "", ".function.main"},
+		{"function of a package", "internal/store/store.go", EntityTypeFunction, "Open", "", "internal/store.function.Open"},
+		{"pointer receiver", "internal/store/store.go", EntityTypeMethod, "Get", "s *Store", "internal/store.method.Store.Get"},
+		{"value receiver without name", "internal/store/store.go", EntityTypeMethod, "Get", "Store", "internal/store.method.Store.Get"},
+		{"generic receiver", "internal/store/cache.go", EntityTypeMethod, "Get", "c *Cache[K, V]", "internal/store.method.Cache.Get"},
+		{"package with the same name", "pkg/store/store.go", EntityTypeFunction, "Open", "", "pkg/store.function.Open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EntityID(tt.filePath, tt.entityType, tt.entity, tt.receiver); got != tt.want {
+				t.Errorf("EntityID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntityIDRoundTrip(t *testing.T) {
+	for _, dir := range findFixtures(t) {
+		name, _ := filepath.Rel(fixturesDir, dir)
+		t.Run(name, func(t *testing.T) {
+			f := loadFixture(t, dir)
+			ctx := context.Background()
+			analysis, err := f.semantic.AnalyzeChanges(ctx, f.request, f.fileDiff)
+			if err != nil {
+				t.Fatalf("AnalyzeChanges() error = %v", err)
+			}
+			graph, err := f.mapper.MapDependencies(ctx, f.request, analysis.ChangedEntities, f.fileDiff.NewPath)
+			if err != nil {
+				t.Fatalf("MapDependencies() error = %v", err)
+			}
+
+			contexts := NewEnhancedContextBuilder(nil).buildEntityContexts(analysis.ChangedEntities, graph, f.fileDiff.NewPath)
+			if len(contexts) != len(analysis.ChangedEntities) {
+				t.Fatalf("buildEntityContexts() = %d contexts, want %d", len(contexts), len(analysis.ChangedEntities))
+			}
+			for i, entityCtx := range contexts {
+				entity := analysis.ChangedEntities[i]
+				graphEntity, ok := graph.Entities[entityCtx.Entity.ID]
+				if !ok {
+					t.Errorf("entity %s has ID %q that is not in the graph", entity.FullName, entityCtx.Entity.ID)
+					continue
+				}
+				// The context uses the entity of the graph, so the lookup hit
+				if graphEntity != entityCtx.Entity {
+					t.Errorf("context of %s is not built from the graph entity %q", entity.FullName, entityCtx.Entity.ID)
+				}
+			}
+		})
+	}
+}
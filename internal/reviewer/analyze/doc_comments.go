@@ -0,0 +1,76 @@
+package analyze
+
+import (
+	"strings"
+)
+
+// Doc comment styles of the project detected by ProjectStyleAnalyzer
+const (
+	DocCommentStyleGodoc    = "godoc"    // doc comments start with the symbol name
+	DocCommentStyleStandard = "standard" // doc comments are written, but not in godoc form
+	DocCommentStyleNone     = "none"     // exported symbols are mostly undocumented
+)
+
+// MissingDocComment is an added exported symbol without a proper doc comment
+type MissingDocComment struct {
+	Entity   ChangedEntity
+	Misnamed bool // doc comment exists, but doesn't start with the symbol name
+}
+
+// FindMissingDocComments returns exported functions, methods, types and consts added in the diff of the Go file
+// whose doc comment is empty or, for the godoc style, doesn't start with the symbol name;
+// renamed symbols that became exported are also added ones, before is empty for new files
+func FindMissingDocComments(before, after, diff string, isNew bool, style string) ([]MissingDocComment, error) {
+	if style == DocCommentStyleNone {
+		return nil, nil
+	}
+
+	analyzer := NewGoAnalyzer()
+	afterEntities, err := analyzer.ParseEntities(after)
+	if err != nil {
+		return nil, err
+	}
+	var beforeEntities []ChangedEntity
+	if !isNew {
+		if beforeEntities, err = analyzer.ParseEntities(before); err != nil {
+			return nil, err
+		}
+	}
+
+	added, removed := parseChangedLineNumbers(diff)
+
+	var missing []MissingDocComment
+	for _, entity := range matchChangedEntities(beforeEntities, afterEntities, added, removed, true) {
+		if entity.ChangeType != ChangeTypeAdded || !entity.IsExported || entity.Type == EntityTypeVar {
+			continue
+		}
+		if _, ok := added[entity.StartLine]; !ok {
+			continue // declaration line is unchanged, only the doc comment or the body was edited
+		}
+
+		switch {
+		case entity.DocComment == "":
+			missing = append(missing, MissingDocComment{Entity: entity})
+		case style != DocCommentStyleStandard && entity.Type != EntityTypeConst && !isGodocComment(entity.DocComment, entity.Name):
+			// consts are usually documented by the comment of their group
+			missing = append(missing, MissingDocComment{Entity: entity, Misnamed: true})
+		}
+	}
+
+	return missing, nil
+}
+
+// isGodocComment checks if the doc comment starts with the symbol name, optionally after an article
+func isGodocComment(doc, name string) bool {
+	for _, prefix := range []string{"", "A ", "An ", "The "} {
+		rest, ok := strings.CutPrefix(doc, prefix+name)
+		if ok && (rest == "" || !isIdentRune(rest[0])) {
+			return true
+		}
+	}
+	return strings.HasPrefix(doc, "Deprecated:")
+}
+
+func isIdentRune(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
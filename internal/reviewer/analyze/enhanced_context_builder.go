@@ -3,6 +3,7 @@ package analyze
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/maxbolgarin/codry/internal/agent/prompts"
@@ -11,28 +12,37 @@ import (
 	"github.com/maxbolgarin/logze/v2"
 )
 
+// maxRelatedCodeSnippets limits the number of related snippets added to the context
+const maxRelatedCodeSnippets = 10
+
 // EnhancedContextBuilder builds sophisticated, targeted context for AI code review
 type EnhancedContextBuilder struct {
 	provider         interfaces.CodeProvider
 	semanticAnalyzer *SemanticAnalyzer
 	styleAnalyzer    *ProjectStyleAnalyzer
 	dependencyMapper *DependencyMapper
+	securityScanner  *SecurityScanner
 	log              logze.Logger
 }
 
-// NewEnhancedContextBuilder creates a new enhanced context builder
+// NewEnhancedContextBuilder creates a new enhanced context builder,
+// provider is optional: without it the context is built from the diff only
 func NewEnhancedContextBuilder(provider interfaces.CodeProvider) *EnhancedContextBuilder {
 	return &EnhancedContextBuilder{
 		provider:         provider,
 		semanticAnalyzer: NewSemanticAnalyzer(provider),
 		styleAnalyzer:    NewProjectStyleAnalyzer(provider),
 		dependencyMapper: NewDependencyMapper(provider),
+		securityScanner:  NewSecurityScanner(DefaultSecretsAllowlist),
 		log:              logze.With("component", "enhanced-context-builder"),
 	}
 }
 
 // TargetedContext represents focused, semantic context for code review
 type TargetedContext struct {
+	// ContentAvailable is false if file content can't be fetched and the context is built from the diff only
+	ContentAvailable bool `json:"content_available"`
+
 	// Core change information
 	ChangedEntities  []EntityContext         `json:"changed_entities"`  // entities that were changed
 	DependencyGraph  *DependencyGraph        `json:"dependency_graph"`  // semantic relationships
@@ -42,6 +52,11 @@ type TargetedContext struct {
 	// Focused code snippets (not entire files)
 	BeforeAfterPairs []BeforeAfterPair    `json:"before_after_pairs"` // before/after code for changed entities
 	RelatedCode      []RelatedCodeSnippet `json:"related_code"`       // relevant code from dependencies/dependents
+	NamingViolations []NamingViolation    `json:"naming_violations"`  // introduced names with inconsistent initialisms
+	SecurityFindings []SecurityFinding    `json:"security_findings"`  // deterministic security findings in added lines
+	LoopCalls        []PerformanceFinding `json:"loop_calls"`         // network and database calls inside loops in changed code
+	ConcurrencyHints []ConcurrencyHint    `json:"concurrency_hints"`  // suspicious goroutine and locking patterns in added Go code
+	PanicHints       []PanicHint          `json:"panic_hints"`        // panic-prone expressions in added Go code
 
 	// Contextual insights
 	BusinessImpact       BusinessImpactInfo       `json:"business_impact"`       // business-level impact assessment
@@ -171,7 +186,7 @@ type FocusArea struct {
 
 // BuildTargetedContext creates comprehensive, targeted context for code review
 func (ecb *EnhancedContextBuilder) BuildTargetedContext(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff) (*TargetedContext, error) {
-	log := ecb.log.WithFields("file", fileDiff.NewPath, "project", request.ProjectID)
+	log := model.ContextLogger(ctx, ecb.log).WithFields("file", fileDiff.NewPath, "project", request.ProjectID)
 	targetedCtx := &TargetedContext{}
 
 	// Step 1: Perform semantic analysis to understand what changed
@@ -182,42 +197,94 @@ func (ecb *EnhancedContextBuilder) BuildTargetedContext(ctx context.Context, req
 	}
 	targetedCtx.SemanticAnalysis = semanticResult
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	targetedCtx.ContentAvailable = ecb.isContentAvailable(ctx, request, fileDiff, semanticResult)
+	if !targetedCtx.ContentAvailable {
+		log.Debug("file content is not available, building context from diff only")
+	}
+
 	// Step 2: Analyze project style and conventions
-	projectStyle, err := ecb.styleAnalyzer.AnalyzeProjectStyle(ctx, request, fileDiff.NewPath)
-	if err != nil {
-		log.Warn("failed project style analysis", "error", err)
-		projectStyle = &ProjectStyleInfo{} // Use empty result as fallback
+	projectStyle := &ProjectStyleInfo{}
+	if targetedCtx.ContentAvailable {
+		projectStyle, err = ecb.styleAnalyzer.AnalyzeProjectStyle(ctx, request, fileDiff.NewPath)
+		if err != nil {
+			log.Warn("failed project style analysis", "error", err)
+			projectStyle = &ProjectStyleInfo{} // Use empty result as fallback
+		}
 	}
 	targetedCtx.ProjectStyle = projectStyle
 
+	// Check names introduced in the diff against the project initialisms, it is the Go convention
+	if detectLanguage(fileDiff.NewPath) == LanguageGo {
+		initialisms := projectStyle.CodingConventions.NamingStyle.Abbreviations
+		if len(initialisms) == 0 {
+			initialisms = ecb.styleAnalyzer.Initialisms()
+		}
+		targetedCtx.NamingViolations = FindNamingViolations(fileDiff.Diff, initialisms)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Step 3: Map dependencies and relationships
-	dependencyGraph, err := ecb.dependencyMapper.MapDependencies(ctx, request, semanticResult.ChangedEntities, fileDiff.NewPath)
-	if err != nil {
-		log.Warn("failed dependency mapping", "error", err)
-		dependencyGraph = &DependencyGraph{} // Use empty result as fallback
+	dependencyGraph := ecb.dependencyMapper.NewEntitiesGraph(semanticResult.ChangedEntities, fileDiff.NewPath)
+	if targetedCtx.ContentAvailable {
+		dependencyGraph, err = ecb.dependencyMapper.MapDependencies(ctx, request, semanticResult.ChangedEntities, fileDiff.NewPath)
+		if err != nil {
+			log.Warn("failed dependency mapping", "error", err)
+			dependencyGraph = &DependencyGraph{} // Use empty result as fallback
+		}
 	}
 	targetedCtx.DependencyGraph = dependencyGraph
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Step 4: Build entity contexts with rich information
-	targetedCtx.ChangedEntities = ecb.buildEntityContexts(semanticResult.ChangedEntities, dependencyGraph)
+	targetedCtx.ChangedEntities = ecb.buildEntityContexts(semanticResult.ChangedEntities, dependencyGraph, fileDiff.NewPath)
 
 	// Step 5: Create before/after pairs for easy comparison
 	targetedCtx.BeforeAfterPairs = ecb.buildBeforeAfterPairs(semanticResult.ChangedEntities)
 
-	// Step 6: Gather related code snippets (not entire files)
-	targetedCtx.RelatedCode = ecb.buildRelatedCodeSnippets(ctx, request, dependencyGraph, fileDiff.NewPath)
+	// Step 6: Gather related code snippets (not entire files), callers of changed functions go first
+	// because they are the most likely to break
+	targetedCtx.RelatedCode = ecb.semanticAnalyzer.callerSnippets(semanticResult.ChangedEntities)
+	targetedCtx.RelatedCode = append(targetedCtx.RelatedCode, ecb.buildRelatedCodeSnippets(ctx, request, dependencyGraph, fileDiff.NewPath)...)
+
+	// Scan added lines for obvious security issues
+	targetedCtx.SecurityFindings = ecb.securityScanner.ScanDiff(fileDiff.NewPath, fileDiff.Diff)
+
+	// Find network and database calls inside loops (N+1 queries)
+	targetedCtx.LoopCalls = FindLoopCalls(fileDiff.NewPath, fileDiff.Diff)
+
+	// Find suspicious goroutine and locking patterns, they need the whole file to resolve loops and structs;
+	// the file parsed by the semantic analysis is reused
+	if targetedCtx.ContentAvailable && semanticResult.goFiles != nil {
+		if after := semanticResult.goFiles.after; after != nil {
+			targetedCtx.ConcurrencyHints = findConcurrencyHints(fileDiff.NewPath, after, fileDiff.Diff, projectStyle.Dependencies.GoVersion)
+			targetedCtx.PanicHints = findPanicHints(fileDiff.NewPath, after, fileDiff.Diff)
+		} else if err := semanticResult.goFiles.afterErr; err != nil {
+			log.Debug("file is not parsed for concurrency hints", "error", err)
+		}
+	}
 
 	// Step 7: Build contextual insights
 	targetedCtx.BusinessImpact = ecb.buildBusinessImpact(semanticResult.BusinessContext, semanticResult.ChangedEntities)
 	targetedCtx.ArchitecturalContext = ecb.buildArchitecturalContext(semanticResult.ArchitecturalScope)
-	targetedCtx.QualityContext = ecb.buildQualityContext(projectStyle, dependencyGraph, semanticResult.ChangedEntities)
-	targetedCtx.SecurityContext = ecb.buildSecurityContext(projectStyle.SecurityPatterns, semanticResult.ChangedEntities)
+	targetedCtx.QualityContext = ecb.buildQualityContext(projectStyle, dependencyGraph, semanticResult.ChangedEntities, targetedCtx.LoopCalls)
+	targetedCtx.SecurityContext = ecb.buildSecurityContext(projectStyle.SecurityPatterns, semanticResult.ChangedEntities, targetedCtx.SecurityFindings)
 
 	// Step 8: Generate review guidance for the AI
 	targetedCtx.ReviewGuidance = ecb.buildReviewGuidance(targetedCtx)
 	targetedCtx.FocusAreas = ecb.buildFocusAreas(targetedCtx)
 
 	log.Debug("targeted context built successfully",
+		"content_available", targetedCtx.ContentAvailable,
 		"changed_entities", len(targetedCtx.ChangedEntities),
 		"related_code_snippets", len(targetedCtx.RelatedCode),
 		"focus_areas", len(targetedCtx.FocusAreas))
@@ -225,12 +292,26 @@ func (ecb *EnhancedContextBuilder) BuildTargetedContext(ctx context.Context, req
 	return targetedCtx, nil
 }
 
+// isContentAvailable checks if the provider can return content of the changed file,
+// the file is fetched only if the semantic analysis didn't fetch it
+func (ecb *EnhancedContextBuilder) isContentAvailable(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, semanticResult *SemanticAnalysisResult) bool {
+	if semanticResult.contentChecked {
+		return semanticResult.contentAvailable
+	}
+	filePath, ref := fileDiff.NewPath, request.MergeRequest.SHA
+	if fileDiff.IsDeleted {
+		filePath, ref = fileDiff.OldPath, request.MergeRequest.TargetBranch
+	}
+	_, err := FetchFileContent(ctx, ecb.provider, request.ProjectID, filePath, ref)
+	return err == nil
+}
+
 // buildEntityContexts creates rich context for each changed entity
-func (ecb *EnhancedContextBuilder) buildEntityContexts(changedEntities []ChangedEntity, graph *DependencyGraph) []EntityContext {
+func (ecb *EnhancedContextBuilder) buildEntityContexts(changedEntities []ChangedEntity, graph *DependencyGraph, filePath string) []EntityContext {
 	var contexts []EntityContext
 
 	for _, entity := range changedEntities {
-		entityID := fmt.Sprintf("%s.%s.%s", extractPackageFromPath(entity.Name), string(entity.Type), entity.Name)
+		entityID := EntityID(filePath, entity.Type, entity.Name, entity.Receiver)
 
 		// Get entity from graph if available
 		var codeEntity *CodeEntity
@@ -244,6 +325,9 @@ func (ecb *EnhancedContextBuilder) buildEntityContexts(changedEntities []Changed
 				Type:          entity.Type,
 				IsExported:    entity.IsExported,
 				Signature:     entity.Signature,
+				Receiver:      entity.Receiver,
+				Parameters:    entity.Parameters,
+				Returns:       entity.Returns,
 				CodeSnippet:   entity.AfterCode,
 				BusinessArea:  inferBusinessAreaFromEntity(entity),
 				SecurityLevel: inferSecurityLevelFromEntity(entity),
@@ -312,39 +396,57 @@ func (ecb *EnhancedContextBuilder) buildBeforeAfterPairs(changedEntities []Chang
 	return pairs
 }
 
-// buildRelatedCodeSnippets gathers relevant code snippets from related entities
+// buildRelatedCodeSnippets gathers relevant code snippets from related entities,
+// the strongest relationships are selected first so the result is stable across runs
 func (ecb *EnhancedContextBuilder) buildRelatedCodeSnippets(ctx context.Context, request model.ReviewRequest, graph *DependencyGraph, filePath string) []RelatedCodeSnippet {
-	var snippets []RelatedCodeSnippet
+	type candidate struct {
+		entityID string
+		rel      Relationship
+	}
+	var candidates []candidate
 
-	// Collect snippets from high-strength relationships
+	// Collect high-strength relationships
 	for entityID, relationships := range graph.Dependencies {
 		for _, rel := range relationships {
 			if rel.Strength > 0.7 && rel.CodeSnippet != "" { // Only high-strength relationships
-				snippet := RelatedCodeSnippet{
-					EntityName:   rel.Target,
-					EntityType:   string(rel.Type),
-					FilePath:     rel.FilePath,
-					CodeSnippet:  rel.CodeSnippet,
-					Relationship: string(rel.Type),
-					Relevance:    fmt.Sprintf("Used by %s", entityID),
-					LineNumbers:  []int{rel.LineNumber},
-				}
-				snippets = append(snippets, snippet)
-
-				// Limit to avoid overwhelming the AI
-				if len(snippets) >= 10 {
-					break
-				}
+				candidates = append(candidates, candidate{entityID: entityID, rel: rel})
 			}
 		}
-		if len(snippets) >= 10 {
-			break
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if c := compareRelationships(a.rel, b.rel); c != 0 {
+			return c
 		}
+		return strings.Compare(a.entityID, b.entityID)
+	})
+
+	// Limit to avoid overwhelming the AI
+	if len(candidates) > maxRelatedCodeSnippets {
+		candidates = candidates[:maxRelatedCodeSnippets]
+	}
+
+	snippets := make([]RelatedCodeSnippet, 0, len(candidates))
+	for _, c := range candidates {
+		snippets = append(snippets, RelatedCodeSnippet{
+			EntityName:   c.rel.Target,
+			EntityType:   string(c.rel.Type),
+			FilePath:     c.rel.FilePath,
+			CodeSnippet:  c.rel.CodeSnippet,
+			Relationship: string(c.rel.Type),
+			Relevance:    fmt.Sprintf("Used by %s", c.entityID),
+			LineNumbers:  []int{c.rel.LineNumber},
+		})
 	}
 
 	return snippets
 }
 
+// approxTokens estimates the number of tokens in the text, a token is about 4 characters of code
+func approxTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
 // buildBusinessImpact creates business impact assessment
 func (ecb *EnhancedContextBuilder) buildBusinessImpact(businessCtx BusinessContext, entities []ChangedEntity) BusinessImpactInfo {
 	return BusinessImpactInfo{
@@ -371,8 +473,8 @@ func (ecb *EnhancedContextBuilder) buildArchitecturalContext(archScope Architect
 }
 
 // buildQualityContext creates code quality context
-func (ecb *EnhancedContextBuilder) buildQualityContext(style *ProjectStyleInfo, graph *DependencyGraph, entities []ChangedEntity) QualityContextInfo {
-	return QualityContextInfo{
+func (ecb *EnhancedContextBuilder) buildQualityContext(style *ProjectStyleInfo, graph *DependencyGraph, entities []ChangedEntity, loopCalls []PerformanceFinding) QualityContextInfo {
+	info := QualityContextInfo{
 		ComplexityLevel:       calculateComplexityLevel(entities),
 		TestabilityImpact:     calculateTestabilityImpact(entities),
 		MaintainabilityImpact: calculateMaintainabilityImpact(entities),
@@ -381,11 +483,21 @@ func (ecb *EnhancedContextBuilder) buildQualityContext(style *ProjectStyleInfo,
 		BestPractices:         getApplicableBestPractices(style),
 		AntiPatterns:          getAntiPatternsToAvoid(style),
 	}
+
+	// Calls inside loops are found deterministically and make performance impact high
+	if len(loopCalls) > 0 {
+		info.PerformanceImpact = "high"
+		for _, finding := range loopCalls {
+			info.QualityRisks = append(info.QualityRisks, fmt.Sprintf("line %d: %s", finding.Line, finding.Description))
+		}
+	}
+
+	return info
 }
 
 // buildSecurityContext creates security context
-func (ecb *EnhancedContextBuilder) buildSecurityContext(securityPatterns SecurityPatterns, entities []ChangedEntity) SecurityContextInfo {
-	return SecurityContextInfo{
+func (ecb *EnhancedContextBuilder) buildSecurityContext(securityPatterns SecurityPatterns, entities []ChangedEntity, findings []SecurityFinding) SecurityContextInfo {
+	info := SecurityContextInfo{
 		SecurityLevel:    calculateOverallSecurityLevel(entities),
 		ThreatAreas:      identifyThreatAreas(entities),
 		SecurityPatterns: getRelevantSecurityPatterns(securityPatterns),
@@ -393,6 +505,17 @@ func (ecb *EnhancedContextBuilder) buildSecurityContext(securityPatterns Securit
 		SecurityRisks:    identifySecurityRisks(entities),
 		Mitigations:      suggestSecurityMitigations(entities),
 	}
+
+	// Deterministic findings are high-confidence, so they raise the security level
+	for _, finding := range findings {
+		if !contains(info.ThreatAreas, string(finding.Type)) {
+			info.ThreatAreas = append(info.ThreatAreas, string(finding.Type))
+		}
+		info.SecurityRisks = append(info.SecurityRisks, fmt.Sprintf("line %d (%s): %s", finding.Line, finding.Severity, finding.Description))
+		info.SecurityLevel = "high"
+	}
+
+	return info
 }
 
 // buildReviewGuidance creates guidance for the AI reviewer
@@ -436,15 +559,73 @@ func (ecb *EnhancedContextBuilder) buildFocusAreas(targetedCtx *TargetedContext)
 		})
 	}
 
+	// Naming focus area
+	if len(targetedCtx.NamingViolations) > 0 {
+		examples := make([]string, 0, len(targetedCtx.NamingViolations))
+		for _, v := range targetedCtx.NamingViolations {
+			examples = append(examples, fmt.Sprintf("%s -> %s", v.Name, v.Suggestion))
+		}
+		areas = append(areas, FocusArea{
+			Name:       "Naming Conventions",
+			Priority:   "medium",
+			Reason:     "New identifiers spell initialisms in mixed case",
+			Specifics:  "Check names introduced in this change: " + strings.Join(examples, ", "),
+			Examples:   "userId should be userID, HttpClient should be HTTPClient",
+			Guidelines: "Initialisms keep a consistent case: " + strings.Join(targetedCtx.ProjectStyle.CodingConventions.NamingStyle.Abbreviations, ", "),
+		})
+	}
+
 	// Performance focus area
 	if targetedCtx.QualityContext.PerformanceImpact == "high" {
-		areas = append(areas, FocusArea{
+		area := FocusArea{
 			Name:       "Performance Impact",
 			Priority:   "medium",
 			Reason:     "Changes may impact performance",
 			Specifics:  "Look for inefficient algorithms, N+1 queries, memory leaks",
 			Examples:   "Loops in database queries, large object creation",
 			Guidelines: "Consider algorithmic complexity and resource usage",
+		}
+		if len(targetedCtx.LoopCalls) > 0 {
+			calls := make([]string, 0, len(targetedCtx.LoopCalls))
+			for _, finding := range targetedCtx.LoopCalls {
+				calls = append(calls, fmt.Sprintf("%s at line %d (loop at line %d)", finding.Call, finding.Line, finding.LoopLine))
+			}
+			area.Priority = "high"
+			area.Reason = "Network or database calls are made inside loops"
+			area.Specifics = "Check calls inside loops: " + strings.Join(calls, ", ")
+		}
+		areas = append(areas, area)
+	}
+
+	// Concurrency focus area
+	if len(targetedCtx.ConcurrencyHints) > 0 {
+		hints := make([]string, 0, len(targetedCtx.ConcurrencyHints))
+		for _, hint := range targetedCtx.ConcurrencyHints {
+			hints = append(hints, fmt.Sprintf("%s at line %d", hint.Type, hint.Line))
+		}
+		areas = append(areas, FocusArea{
+			Name:       "Concurrency Safety",
+			Priority:   "high",
+			Reason:     "Added code has goroutine or locking patterns that often cause races",
+			Specifics:  "Check hints: " + strings.Join(hints, ", "),
+			Examples:   "Loop variable captured by goroutine, WaitGroup.Add inside goroutine, map write without lock",
+			Guidelines: "Confirm a hint only if the race is reachable, dismiss it otherwise",
+		})
+	}
+
+	// Reliability focus area
+	if len(targetedCtx.PanicHints) > 0 {
+		hints := make([]string, 0, len(targetedCtx.PanicHints))
+		for _, hint := range targetedCtx.PanicHints {
+			hints = append(hints, fmt.Sprintf("%s at line %d", hint.Type, hint.Line))
+		}
+		areas = append(areas, FocusArea{
+			Name:       "Runtime Panics",
+			Priority:   "high",
+			Reason:     "Added code has expressions that panic on unexpected input",
+			Specifics:  "Check hints: " + strings.Join(hints, ", "),
+			Examples:   "x.(T) without ok, write to a map declared with var, strings.Split(s, sep)[1] without a length check",
+			Guidelines: "Report a panic only if the input can reach it, expressions guarded by the caller are safe",
 		})
 	}
 
@@ -455,21 +636,24 @@ func (ecb *EnhancedContextBuilder) buildFocusAreas(targetedCtx *TargetedContext)
 func (ecb *EnhancedContextBuilder) ConvertToPromptsContext(targetedCtx *TargetedContext) *prompts.EnhancedContext {
 	// Convert our rich context to the format expected by the prompts package
 	promptsCtx := &prompts.EnhancedContext{
-		FilePath:  "", // Will be set by caller
-		CleanDiff: "", // Will be set by caller
+		FilePath:         "", // Will be set by caller
+		CleanDiff:        "", // Will be set by caller
+		ContentAvailable: targetedCtx.ContentAvailable,
 	}
 
 	// Convert changed entities to enhanced function signatures with business context
 	for _, entityCtx := range targetedCtx.ChangedEntities {
-		if entityCtx.Entity.Type == EntityTypeFunction {
-			// Create enhanced function signature with business and risk context
+		if entityCtx.Entity.Type == EntityTypeFunction || entityCtx.Entity.Type == EntityTypeMethod {
+			// Signature is parsed from the source, business and risk context go to annotations
 			sig := prompts.FunctionSignature{
 				Name:       entityCtx.Entity.Name,
+				Receiver:   entityCtx.Entity.Receiver,
+				Parameters: entityCtx.Entity.Parameters,
+				Returns:    entityCtx.Entity.Returns,
 				IsExported: entityCtx.Entity.IsExported,
 				LineNumber: entityCtx.Entity.StartLine,
 			}
 
-			// Add business context to parameters field for additional context
 			var contextInfo []string
 			if entityCtx.Entity.BusinessArea != "general" {
 				contextInfo = append(contextInfo, fmt.Sprintf("Domain: %s", entityCtx.Entity.BusinessArea))
@@ -484,9 +668,7 @@ func (ecb *EnhancedContextBuilder) ConvertToPromptsContext(targetedCtx *Targeted
 				contextInfo = append(contextInfo, fmt.Sprintf("Dependents: %d", len(entityCtx.Dependents)))
 			}
 
-			if len(contextInfo) > 0 {
-				sig.Parameters = contextInfo
-			}
+			sig.Annotations = contextInfo
 
 			promptsCtx.FunctionSignatures = append(promptsCtx.FunctionSignatures, sig)
 		}
@@ -514,8 +696,19 @@ func (ecb *EnhancedContextBuilder) ConvertToPromptsContext(targetedCtx *Targeted
 		}
 	}
 
-	// Convert related code to related files with enhanced relationship context
+	// Convert related code to related files with enhanced relationship context,
+	// callers are shown as a whole with their label
 	for _, relatedCode := range targetedCtx.RelatedCode {
+		if relatedCode.Relationship == relationshipCaller {
+			promptsCtx.Callers = append(promptsCtx.Callers, prompts.CallerSnippet{
+				Label:     relatedCode.Relevance,
+				Name:      relatedCode.EntityName,
+				Path:      relatedCode.FilePath,
+				CallLines: relatedCode.LineNumbers,
+				Snippet:   relatedCode.CodeSnippet,
+			})
+			continue
+		}
 		promptsCtx.RelatedFiles = append(promptsCtx.RelatedFiles, prompts.RelatedFile{
 			Path:         relatedCode.FilePath,
 			Relationship: fmt.Sprintf("%s (relevance: %s)", relatedCode.Relationship, relatedCode.Relevance),
@@ -568,6 +761,20 @@ func (ecb *EnhancedContextBuilder) ConvertToPromptsContext(targetedCtx *Targeted
 	// Build ACTUAL usage patterns with real code examples from project style
 	promptsCtx.UsagePatterns = ecb.buildMeaningfulUsagePatterns(targetedCtx.ProjectStyle, targetedCtx.ChangedEntities)
 
+	// Add naming violations of introduced identifiers
+	if len(targetedCtx.NamingViolations) > 0 {
+		examples := make([]string, 0, len(targetedCtx.NamingViolations))
+		for _, v := range targetedCtx.NamingViolations {
+			examples = append(examples, fmt.Sprintf("%s should be %s (%s)", v.Name, v.Suggestion, v.Line))
+		}
+		promptsCtx.UsagePatterns = append(promptsCtx.UsagePatterns, prompts.UsagePattern{
+			Pattern:      "naming_initialisms",
+			Description:  "Identifiers introduced in this change spell initialisms in mixed case",
+			Examples:     examples,
+			BestPractice: "Keep initialisms in a consistent case (URL, ID, HTTP, API), e.g. userID, HTTPClient",
+		})
+	}
+
 	// Add review guidance as usage patterns for strategic direction
 	if targetedCtx.ReviewGuidance.PrimaryFocus != "" {
 		promptsCtx.UsagePatterns = append(promptsCtx.UsagePatterns, prompts.UsagePattern{
@@ -582,12 +789,29 @@ func (ecb *EnhancedContextBuilder) ConvertToPromptsContext(targetedCtx *Targeted
 	promptsCtx.SecurityContext = prompts.SecurityContext{
 		HasAuthenticationLogic:  targetedCtx.SecurityContext.SecurityLevel == "high" || contains(targetedCtx.SecurityContext.ThreatAreas, "authentication"),
 		HandlesUserInput:        contains(targetedCtx.SecurityContext.ThreatAreas, "input_validation"),
-		AccessesDatabase:        contains(targetedCtx.SecurityContext.ThreatAreas, "database"),
+		AccessesDatabase:        contains(targetedCtx.SecurityContext.ThreatAreas, "database") || contains(targetedCtx.SecurityContext.ThreatAreas, string(SecurityFindingSQLInjection)),
 		NetworkOperations:       contains(targetedCtx.SecurityContext.ThreatAreas, "network"),
 		CryptographicOperations: contains(targetedCtx.SecurityContext.ThreatAreas, "cryptography"),
 		HasInputValidation:      len(targetedCtx.SecurityContext.SecurityRisks) == 0, // Assume good validation if no risks
 		HandlesFileOperations:   contains(targetedCtx.SecurityContext.ThreatAreas, "file_operations"),
 	}
+	for _, hint := range targetedCtx.ConcurrencyHints {
+		promptsCtx.ConcurrencyHints = append(promptsCtx.ConcurrencyHints, prompts.ConcurrencyHint{
+			Type:        string(hint.Type),
+			Line:        hint.Line,
+			Code:        hint.Code,
+			Description: hint.Description,
+		})
+	}
+	for _, finding := range targetedCtx.SecurityFindings {
+		promptsCtx.SecurityContext.Findings = append(promptsCtx.SecurityContext.Findings, prompts.SecurityFinding{
+			Type:        string(finding.Type),
+			Line:        finding.Line,
+			Severity:    finding.Severity,
+			Code:        finding.Code,
+			Description: finding.Description,
+		})
+	}
 
 	// Convert semantic changes with enhanced business context
 	for _, entityCtx := range targetedCtx.ChangedEntities {
@@ -849,14 +1073,6 @@ func mapEntityTypeToSemanticType(entityType EntityType) string {
 
 // Helper functions
 
-func extractPackageFromPath(filePath string) string {
-	parts := strings.Split(filePath, "/")
-	if len(parts) > 0 {
-		return parts[len(parts)-1]
-	}
-	return "unknown"
-}
-
 func inferBusinessAreaFromEntity(entity ChangedEntity) string {
 	nameLower := strings.ToLower(entity.Name)
 	if strings.Contains(nameLower, "auth") {
@@ -872,6 +1088,11 @@ func inferBusinessAreaFromEntity(entity ChangedEntity) string {
 }
 
 func inferSecurityLevelFromEntity(entity ChangedEntity) string {
+	for _, line := range strings.Split(entity.AfterCode, "\n") {
+		if _, _, ok := detectHardcodedSecret(line); ok {
+			return "high"
+		}
+	}
 	combined := strings.ToLower(entity.Name + " " + entity.AfterCode)
 	if strings.Contains(combined, "password") || strings.Contains(combined, "secret") {
 		return "high"
@@ -0,0 +1,172 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+func TestBuildRelatedCodeSnippetsOrder(t *testing.T) {
+	rel := func(target string, strength float64) Relationship {
+		return Relationship{Target: target, Type: RelationshipFunctionCall, Strength: strength, CodeSnippet: target + "()"}
+	}
+	// manyTargets are more relationships than the limit, all of the same strength
+	var manyTargets []Relationship
+	for i := range maxRelatedCodeSnippets + 5 {
+		manyTargets = append(manyTargets, rel(fmt.Sprintf("t%02d", i), 0.8))
+	}
+
+	tests := []struct {
+		name  string
+		graph map[string][]Relationship
+		want  []string
+	}{
+		{
+			name: "strength descending",
+			graph: map[string][]Relationship{
+				"a": {rel("weak", 0.75), rel("strong", 0.95)},
+				"b": {rel("middle", 0.85)},
+			},
+			want: []string{"strong", "middle", "weak"},
+		},
+		{
+			name: "ties by target name",
+			graph: map[string][]Relationship{
+				"a": {rel("zeta", 0.9), rel("alpha", 0.9)},
+				"b": {rel("beta", 0.9)},
+			},
+			want: []string{"alpha", "beta", "zeta"},
+		},
+		{
+			name: "weak and empty relationships are skipped",
+			graph: map[string][]Relationship{
+				"a": {rel("weak", 0.5), {Target: "empty", Strength: 0.9}, rel("kept", 0.8)},
+			},
+			want: []string{"kept"},
+		},
+		{
+			name: "truncated to the strongest",
+			graph: map[string][]Relationship{
+				"a": manyTargets[:7],
+				"b": append(slices.Clone(manyTargets[7:]), rel("top", 0.99)),
+			},
+			want: []string{"top", "t00", "t01", "t02", "t03", "t04", "t05", "t06", "t07", "t08"},
+		},
+	}
+
+	ecb := NewEnhancedContextBuilder(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := &DependencyGraph{Dependencies: tt.graph}
+			// Maps are iterated in random order, so the selection is checked many times
+			for range 20 {
+				snippets := ecb.buildRelatedCodeSnippets(context.Background(), model.ReviewRequest{}, graph, "main.go")
+				got := make([]string, 0, len(snippets))
+				for _, snippet := range snippets {
+					got = append(got, snippet.EntityName)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Fatalf("buildRelatedCodeSnippets() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertToPromptsContextSignatures(t *testing.T) {
+	const source = `package payment
+
+func (s *Service) Charge(ctx context.Context, amount, fee int64) (string, error) {
+	return "", nil
+}
+
+func Split(total int64) (head, tail int64) {
+	return total / 2, total - total/2
+}
+
+func reset() {}
+`
+	tests := []struct {
+		name       string
+		entity     string
+		risk       string
+		dependents int
+		want       prompts.FunctionSignature
+	}{
+		{
+			name:       "method",
+			entity:     "Charge",
+			risk:       "high",
+			dependents: 2,
+			want: prompts.FunctionSignature{
+				Name:        "Charge",
+				Receiver:    "s *Service",
+				Parameters:  []string{"ctx context.Context", "amount int64", "fee int64"},
+				Returns:     []string{"string", "error"},
+				IsExported:  true,
+				LineNumber:  3,
+				Annotations: []string{"Domain: payment", "Risk: high", "Dependents: 2"},
+			},
+		},
+		{
+			name:   "function with named results",
+			entity: "Split",
+			risk:   "low",
+			want: prompts.FunctionSignature{
+				Name:        "Split",
+				Parameters:  []string{"total int64"},
+				Returns:     []string{"head int64", "tail int64"},
+				IsExported:  true,
+				LineNumber:  7,
+				Annotations: []string{"Domain: payment"},
+			},
+		},
+		{
+			name:   "function without parameters",
+			entity: "reset",
+			risk:   "low",
+			want: prompts.FunctionSignature{
+				Name:        "reset",
+				LineNumber:  11,
+				Annotations: []string{"Domain: payment"},
+			},
+		},
+	}
+
+	entities, err := NewGoAnalyzer().ParseEntities(source)
+	if err != nil {
+		t.Fatalf("ParseEntities() error = %v", err)
+	}
+	ecb := NewEnhancedContextBuilder(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := slices.IndexFunc(entities, func(e ChangedEntity) bool { return e.Name == tt.entity })
+			if i < 0 {
+				t.Fatalf("entity %s is not parsed", tt.entity)
+			}
+			entity := ecb.dependencyMapper.convertToCodeEntity(entities[i], "internal/payment/service.go")
+			entity.BusinessArea, entity.SecurityLevel = "payment", "low"
+
+			promptsCtx := ecb.ConvertToPromptsContext(&TargetedContext{
+				ChangedEntities: []EntityContext{{
+					Entity:     entity,
+					RiskLevel:  tt.risk,
+					Dependents: make([]DependentContext, tt.dependents),
+				}},
+			})
+			if len(promptsCtx.FunctionSignatures) != 1 {
+				t.Fatalf("ConvertToPromptsContext() signatures = %d, want 1", len(promptsCtx.FunctionSignatures))
+			}
+			got := promptsCtx.FunctionSignatures[0]
+			if got.Name != tt.want.Name || got.Receiver != tt.want.Receiver || got.IsExported != tt.want.IsExported ||
+				got.LineNumber != tt.want.LineNumber || !slices.Equal(got.Parameters, tt.want.Parameters) ||
+				!slices.Equal(got.Returns, tt.want.Returns) || !slices.Equal(got.Annotations, tt.want.Annotations) {
+				t.Errorf("ConvertToPromptsContext() signature =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package analyze
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/provider/local"
+)
+
+// Every directory of testdata with diff.patch is a fixture: before/ has files of the repository
+// before changes and expected.json is the golden output
+const (
+	fixturesDir         = "testdata"
+	fixtureDiffFile     = "diff.patch"
+	fixtureBeforeDir    = "before"
+	fixtureExpectedFile = "expected.json"
+)
+
+var updateFixtures = flag.Bool("update", false, "rewrite golden files of fixtures with the current output")
+
+// fixtureOutput is the golden output of a fixture, it has only stable fields of the analysis,
+// so golden files don't change with unrelated fields like code snippets
+type fixtureOutput struct {
+	Entities []fixtureEntity `json:"entities"`
+	// GraphEntities are IDs of entities of the dependency graph
+	GraphEntities []string `json:"graph_entities"`
+	// Imports are import paths of the dependency graph
+	Imports []string `json:"imports"`
+}
+
+type fixtureEntity struct {
+	Type       EntityType `json:"type"`
+	Name       string     `json:"name"`
+	ChangeType ChangeType `json:"change_type"`
+	StartLine  int        `json:"start_line"`
+	EndLine    int        `json:"end_line"`
+	IsExported bool       `json:"is_exported"`
+	Signature  string     `json:"signature,omitempty"`
+}
+
+// fixture is a loaded fixture with analyzers over the files before changes
+type fixture struct {
+	request  model.ReviewRequest
+	fileDiff *model.FileDiff
+	semantic *SemanticAnalyzer
+	mapper   *DependencyMapper
+}
+
+func TestAnalyzerFixtures(t *testing.T) {
+	for _, dir := range findFixtures(t) {
+		name, _ := filepath.Rel(fixturesDir, dir)
+		t.Run(name, func(t *testing.T) {
+			f := loadFixture(t, dir)
+			ctx := context.Background()
+
+			analysis, err := f.semantic.AnalyzeChanges(ctx, f.request, f.fileDiff)
+			if err != nil {
+				t.Fatalf("AnalyzeChanges() error = %v", err)
+			}
+			graph, err := f.mapper.MapDependencies(ctx, f.request, analysis.ChangedEntities, f.fileDiff.NewPath)
+			if err != nil {
+				t.Fatalf("MapDependencies() error = %v", err)
+			}
+
+			actual, err := json.MarshalIndent(newFixtureOutput(analysis, graph), "", "  ")
+			if err != nil {
+				t.Fatalf("failed to marshal output: %v", err)
+			}
+			actual = append(actual, '\n')
+
+			expectedPath := filepath.Join(dir, fixtureExpectedFile)
+			if *updateFixtures {
+				if err := os.WriteFile(expectedPath, actual, 0o644); err != nil {
+					t.Fatalf("failed to write golden file: %v", err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(expectedPath)
+			if err != nil {
+				t.Fatalf("failed to read golden file, run with -update to create it: %v", err)
+			}
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("output doesn't match %s:\n%s", fixtureExpectedFile, actual)
+			}
+		})
+	}
+}
+
+func BenchmarkEntityExtraction(b *testing.B) {
+	for _, dir := range findFixtures(b) {
+		name, _ := filepath.Rel(fixturesDir, dir)
+		b.Run(name, func(b *testing.B) {
+			f := loadFixture(b, dir)
+			ctx := context.Background()
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = f.semantic.AnalyzeChanges(ctx, f.request, f.fileDiff)
+			}
+		})
+	}
+}
+
+func BenchmarkDependencyMapping(b *testing.B) {
+	for _, dir := range findFixtures(b) {
+		name, _ := filepath.Rel(fixturesDir, dir)
+		b.Run(name, func(b *testing.B) {
+			f := loadFixture(b, dir)
+			ctx := context.Background()
+			analysis, err := f.semantic.AnalyzeChanges(ctx, f.request, f.fileDiff)
+			if err != nil {
+				b.Fatalf("AnalyzeChanges() error = %v", err)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = f.mapper.MapDependencies(ctx, f.request, analysis.ChangedEntities, f.fileDiff.NewPath)
+			}
+		})
+	}
+}
+
+// loadFixture reads the diff of the fixture, files are served by the local provider
+func loadFixture(tb testing.TB, dir string) fixture {
+	tb.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, fixtureDiffFile))
+	if err != nil {
+		tb.Fatalf("failed to read diff file: %v", err)
+	}
+	diffs := model.ParseUnifiedDiff(string(content))
+	if len(diffs) != 1 {
+		tb.Fatalf("fixture must have a diff of one file, got %d", len(diffs))
+	}
+	provider := local.New(filepath.Join(dir, fixtureBeforeDir), diffs, nil)
+	return fixture{
+		request:  model.ReviewRequest{ProjectID: "local", MergeRequest: provider.MergeRequest(), Changes: diffs},
+		fileDiff: diffs[0],
+		semantic: NewSemanticAnalyzer(provider),
+		mapper:   NewDependencyMapper(provider),
+	}
+}
+
+// findFixtures returns directories with diff files in lexical order
+func findFixtures(tb testing.TB) []string {
+	tb.Helper()
+	var fixtures []string
+	err := filepath.WalkDir(fixturesDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == fixtureBeforeDir {
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() && entry.Name() == fixtureDiffFile {
+			fixtures = append(fixtures, filepath.Dir(path))
+		}
+		return nil
+	})
+	if err != nil {
+		tb.Fatalf("failed to find fixtures: %v", err)
+	}
+	if len(fixtures) == 0 {
+		tb.Fatalf("no fixtures found in %s", fixturesDir)
+	}
+	return fixtures
+}
+
+func newFixtureOutput(analysis *SemanticAnalysisResult, graph *DependencyGraph) fixtureOutput {
+	output := fixtureOutput{
+		Entities:      make([]fixtureEntity, 0, len(analysis.ChangedEntities)),
+		GraphEntities: make([]string, 0, len(graph.Entities)),
+		Imports:       make([]string, 0, len(graph.ImportGraph)),
+	}
+	for _, entity := range analysis.ChangedEntities {
+		output.Entities = append(output.Entities, fixtureEntity{
+			Type:       entity.Type,
+			Name:       entity.Name,
+			ChangeType: entity.ChangeType,
+			StartLine:  entity.StartLine,
+			EndLine:    entity.EndLine,
+			IsExported: entity.IsExported,
+			Signature:  entity.Signature,
+		})
+	}
+	// Entities of some languages are collected from maps, so their order is not stable
+	slices.SortStableFunc(output.Entities, func(a, b fixtureEntity) int {
+		return cmp.Or(cmp.Compare(a.StartLine, b.StartLine), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Type, b.Type))
+	})
+	for id := range graph.Entities {
+		output.GraphEntities = append(output.GraphEntities, id)
+	}
+	for importPath := range graph.ImportGraph {
+		output.Imports = append(output.Imports, importPath)
+	}
+	slices.Sort(output.GraphEntities)
+	slices.Sort(output.Imports)
+	return output
+}
@@ -0,0 +1,157 @@
+package analyze
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// GoAnalyzer extracts top-level declarations from Go sources using the standard parser
+type GoAnalyzer struct{}
+
+// NewGoAnalyzer creates a new Go analyzer
+func NewGoAnalyzer() *GoAnalyzer {
+	return &GoAnalyzer{}
+}
+
+// ParseEntities parses the source and returns functions, methods, types, consts and vars with their line ranges,
+// doc comment of a grouped spec falls back to the doc comment of its group
+func (ga *GoAnalyzer) ParseEntities(content string) ([]ChangedEntity, error) {
+	parsed, err := parseGoFile("", content)
+	if err != nil {
+		return nil, err
+	}
+	return goEntities(parsed), nil
+}
+
+// goEntities returns top-level declarations of the parsed file
+func goEntities(parsed *parsedFile) []ChangedEntity {
+	fset, file := parsed.fset, parsed.file
+	lines := strings.Split(parsed.src, "\n")
+	newEntity := func(entityType EntityType, name, fullName string, node ast.Node, doc *ast.CommentGroup) ChangedEntity {
+		start, end := fset.Position(node.Pos()).Line, fset.Position(node.End()).Line
+		return ChangedEntity{
+			Type:       entityType,
+			Name:       name,
+			FullName:   fullName,
+			Package:    file.Name.Name,
+			IsExported: ast.IsExported(name),
+			StartLine:  start,
+			EndLine:    end,
+			AfterCode:  strings.Join(lines[start-1:min(end, len(lines))], "\n"),
+			DocComment: strings.TrimSpace(doc.Text()),
+		}
+	}
+
+	var entities []ChangedEntity
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				entity := newEntity(EntityTypeFunction, d.Name.Name, d.Name.Name, d, d.Doc)
+				entity.Signature = strings.TrimSpace(strings.SplitN(entity.AfterCode, "{", 2)[0])
+				entity.Parameters, entity.Returns = goFieldList(d.Type.Params), goFieldList(d.Type.Results)
+				entities = append(entities, entity)
+				continue
+			}
+			recv := receiverTypeName(d.Recv.List[0].Type)
+			entity := newEntity(EntityTypeMethod, d.Name.Name, recv+"."+d.Name.Name, d, d.Doc)
+			entity.IsExported = entity.IsExported && ast.IsExported(recv)
+			entity.Signature = strings.TrimSpace(strings.SplitN(entity.AfterCode, "{", 2)[0])
+			entity.Receiver = strings.Join(goFieldList(d.Recv), ", ")
+			entity.Parameters, entity.Returns = goFieldList(d.Type.Params), goFieldList(d.Type.Results)
+			entities = append(entities, entity)
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					doc := s.Doc
+					if doc == nil {
+						doc = d.Doc
+					}
+					var node ast.Node = s
+					if !d.Lparen.IsValid() {
+						node = d // ungrouped declaration starts with the type keyword
+					}
+					entities = append(entities, newEntity(goTypeEntityType(s.Type), s.Name.Name, s.Name.Name, node, doc))
+
+				case *ast.ValueSpec:
+					entityType := EntityTypeVar
+					if d.Tok == token.CONST {
+						entityType = EntityTypeConst
+					}
+					doc := s.Doc
+					if doc == nil {
+						doc = d.Doc
+					}
+					var node ast.Node = s
+					if !d.Lparen.IsValid() {
+						node = d
+					}
+					for _, name := range s.Names {
+						if name.Name == "_" {
+							continue
+						}
+						entities = append(entities, newEntity(entityType, name.Name, name.Name, node, doc))
+					}
+				}
+			}
+		}
+	}
+
+	return entities
+}
+
+// goTypeEntityType returns the entity type of the type declaration
+func goTypeEntityType(expr ast.Expr) EntityType {
+	switch expr.(type) {
+	case *ast.StructType:
+		return EntityTypeStruct
+	case *ast.InterfaceType:
+		return EntityTypeInterface
+	default:
+		return EntityTypeType
+	}
+}
+
+// goFieldList returns fields of parameters, results or receiver as "name type", fields declared
+// without names as type only; a group like "a, b int" is returned as separate fields
+func goFieldList(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	result := make([]string, 0, fields.NumFields())
+	for _, field := range fields.List {
+		fieldType := types.ExprString(field.Type)
+		if len(field.Names) == 0 {
+			result = append(result, fieldType)
+			continue
+		}
+		for _, name := range field.Names {
+			result = append(result, name.Name+" "+fieldType)
+		}
+	}
+	return result
+}
+
+// receiverTypeName returns the type name of the method receiver without pointer and type parameters
+func receiverTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package analyze
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// goPseudoVersionRegex matches pseudo-versions like v0.0.0-20240102150405-abcdef123456 of commits without a release tag
+var goPseudoVersionRegex = regexp.MustCompile(`-(?:0\.)?\d{14}-[0-9a-f]{12}(?:\+incompatible)?$`)
+
+// GoModRequire is a module required in go.mod
+type GoModRequire struct {
+	Path     string
+	Version  string
+	Indirect bool // marked with the // indirect comment
+	Line     int
+}
+
+// ParseGoModRequires returns modules of require directives and blocks of go.mod content
+func ParseGoModRequires(content string) []GoModRequire {
+	var (
+		requires       []GoModRequire
+		inRequireBlock bool
+	)
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+
+		// Handle require block
+		if strings.HasPrefix(line, "require (") || line == "require(" {
+			inRequireBlock = true
+			continue
+		}
+		if inRequireBlock && line == ")" {
+			inRequireBlock = false
+			continue
+		}
+		if !inRequireBlock && !strings.HasPrefix(line, "require ") {
+			continue
+		}
+
+		code, comment, _ := strings.Cut(strings.TrimPrefix(line, "require "), "//")
+		parts := strings.Fields(code)
+		if len(parts) < 2 {
+			continue
+		}
+		requires = append(requires, GoModRequire{
+			Path:     strings.Trim(parts[0], `"`),
+			Version:  parts[1],
+			Indirect: strings.HasPrefix(strings.TrimSpace(comment), "indirect"),
+			Line:     i + 1,
+		})
+	}
+	return requires
+}
+
+// AddedGoDependency is a module required directly in the new version of go.mod but not in the old one
+type AddedGoDependency struct {
+	GoModRequire
+	// WasIndirect is true if the module was an indirect dependency already
+	WasIndirect bool
+	// PseudoVersion is true if the version is a commit without a release tag, the module may be unmaintained
+	PseudoVersion bool
+	// Purpose is the purpose of the module like "logging" guessed by its path, empty if it is unknown
+	Purpose string
+	// Overlaps are direct dependencies of the old version with the same purpose
+	Overlaps []string
+}
+
+// FindAddedGoDependencies compares two versions of go.mod and returns new direct dependencies in the order of lines,
+// before is empty for a new go.mod; it uses only the content of go.mod, registries of modules are not requested
+func FindAddedGoDependencies(before, after string) []AddedGoDependency {
+	oldRequires := make(map[string]GoModRequire)
+	for _, require := range ParseGoModRequires(before) {
+		oldRequires[require.Path] = require
+	}
+
+	var added []AddedGoDependency
+	for _, require := range ParseGoModRequires(after) {
+		old, existed := oldRequires[require.Path]
+		if require.Indirect || (existed && !old.Indirect) {
+			continue
+		}
+		dependency := AddedGoDependency{
+			GoModRequire:  require,
+			WasIndirect:   existed,
+			PseudoVersion: goPseudoVersionRegex.MatchString(require.Version),
+			Purpose:       identifyLibraryType(require.Path).Purpose,
+		}
+		if dependency.Purpose != "" {
+			for _, old := range oldRequires {
+				if !old.Indirect && identifyLibraryType(old.Path).Purpose == dependency.Purpose {
+					dependency.Overlaps = append(dependency.Overlaps, old.Path)
+				}
+			}
+			slices.Sort(dependency.Overlaps)
+		}
+		added = append(added, dependency)
+	}
+	return added
+}
@@ -0,0 +1,302 @@
+package analyze
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+var goModuleRegex = regexp.MustCompile(`(?m)^module\s+"?([^\s"]+)"?`)
+
+// MethodChange is a method of a Go receiver type removed from the file or changed in its signature
+// or moved to a pointer receiver
+type MethodChange struct {
+	Type   string // receiver type name without pointer and type parameters
+	Method string
+	Before string // signature before the change like "(context.Context, string) error"
+	// After is the signature after the change, empty if the method was removed
+	After   string
+	Pointer bool // the method has a pointer receiver after the change
+	// Line is the line of the method after the change or the line of the removal in the new file
+	Line int
+}
+
+// InterfaceAssertion is a compile-time check of an implementation like var _ Iface = (*T)(nil)
+type InterfaceAssertion struct {
+	Interface string // name of the interface without package
+	// ImportPath is the import path of the package of the interface, empty for interfaces of the same package
+	ImportPath string
+	Type       string
+	Pointer    bool // the pointer type is asserted, so methods with pointer receivers are in its method set
+	File       string
+	Line       int
+}
+
+// GoMethod is a method of a receiver type with its signature
+type GoMethod struct {
+	Signature string
+	Pointer   bool
+	Line      int
+}
+
+// FindMethodChanges returns methods whose receiver types lost or changed them in the Go file,
+// methods of the new version are compared with the old one by receiver type and name
+func FindMethodChanges(before, after, diff string) ([]MethodChange, error) {
+	beforeMethods, err := ParseGoMethods(before)
+	if err != nil {
+		return nil, err
+	}
+	afterMethods, err := ParseGoMethods(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []MethodChange
+	for typeName, methods := range beforeMethods {
+		for name, old := range methods {
+			change := MethodChange{Type: typeName, Method: name, Before: old.Signature}
+			current, ok := afterMethods[typeName][name]
+			switch {
+			case !ok:
+				change.Line = newLineOfOldLine(diff, old.Line)
+			case current.Signature != old.Signature || (current.Pointer && !old.Pointer):
+				change.After = current.Signature
+				change.Pointer = current.Pointer
+				change.Line = current.Line
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// ParseGoMethods returns methods of the Go source by receiver type name and method name
+func ParseGoMethods(content string) (map[string]map[string]GoMethod, error) {
+	methods := make(map[string]map[string]GoMethod)
+	if content == "" {
+		return methods, nil
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+			continue
+		}
+		typeName := receiverTypeName(fn.Recv.List[0].Type)
+		_, pointer := fn.Recv.List[0].Type.(*ast.StarExpr)
+		if typeName == "" {
+			continue
+		}
+		if methods[typeName] == nil {
+			methods[typeName] = make(map[string]GoMethod)
+		}
+		methods[typeName][fn.Name.Name] = GoMethod{
+			Signature: signatureString(fset, fn.Type),
+			Pointer:   pointer,
+			Line:      fset.Position(fn.Pos()).Line,
+		}
+	}
+	return methods, nil
+}
+
+// ParseInterfaceAssertions returns assertions like var _ Iface = (*T)(nil), var _ pkg.Iface = T{} or
+// var _ Iface = &T{} of the Go file, imported interfaces get import paths of their packages
+func ParseInterfaceAssertions(filePath, content string) []InterfaceAssertion {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := importPath[strings.LastIndex(importPath, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+
+	var assertions []InterfaceAssertion
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value, ok := spec.(*ast.ValueSpec)
+			if !ok || len(value.Names) != 1 || value.Names[0].Name != "_" || len(value.Values) != 1 || value.Type == nil {
+				continue
+			}
+			assertion := InterfaceAssertion{File: filePath, Line: fset.Position(value.Pos()).Line}
+			switch iface := value.Type.(type) {
+			case *ast.Ident:
+				assertion.Interface = iface.Name
+			case *ast.SelectorExpr:
+				pkg, ok := iface.X.(*ast.Ident)
+				if !ok || imports[pkg.Name] == "" {
+					continue
+				}
+				assertion.Interface = iface.Sel.Name
+				assertion.ImportPath = imports[pkg.Name]
+			default:
+				continue
+			}
+			assertion.Type, assertion.Pointer = assertedTypeName(value.Values[0])
+			if assertion.Type != "" {
+				assertions = append(assertions, assertion)
+			}
+		}
+	}
+	return assertions
+}
+
+// ParseInterfaceMethods returns signatures of methods declared in the interface of the Go source by name,
+// methods of embedded interfaces are not included; ok is false if there is no such interface
+func ParseInterfaceMethods(content, name string) (methods map[string]string, ok bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, decl := range file.Decls {
+		gen, isGen := decl.(*ast.GenDecl)
+		if !isGen || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec, isType := spec.(*ast.TypeSpec)
+			if !isType || typeSpec.Name.Name != name {
+				continue
+			}
+			iface, isInterface := typeSpec.Type.(*ast.InterfaceType)
+			if !isInterface {
+				return nil, false
+			}
+			methods = make(map[string]string)
+			for _, field := range iface.Methods.List {
+				funcType, isFunc := field.Type.(*ast.FuncType)
+				if !isFunc {
+					continue // embedded interface or type constraint
+				}
+				for _, methodName := range field.Names {
+					methods[methodName.Name] = signatureString(fset, funcType)
+				}
+			}
+			return methods, true
+		}
+	}
+	return nil, false
+}
+
+// BreaksInterface checks if the method change breaks the assertion of the interface with the methods,
+// expected is the signature of the method required by the interface
+func BreaksInterface(change MethodChange, assertion InterfaceAssertion, methods map[string]string) (expected string, broken bool) {
+	expected, ok := methods[change.Method]
+	switch {
+	case !ok || change.Type != assertion.Type:
+		return "", false
+	case change.After == "":
+		return expected, true
+	case change.After != expected:
+		return expected, true
+	default:
+		// A value type doesn't have methods with pointer receivers in its method set
+		return expected, change.Pointer && !assertion.Pointer
+	}
+}
+
+// ParseGoModulePath returns the module path of go.mod content, empty if it is not found
+func ParseGoModulePath(content string) string {
+	if match := goModuleRegex.FindStringSubmatch(content); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// assertedTypeName returns the type of the asserted value: (*T)(nil), &T{}, new(T) or T{}
+func assertedTypeName(expr ast.Expr) (name string, pointer bool) {
+	switch v := expr.(type) {
+	case *ast.CallExpr:
+		if fun, ok := v.Fun.(*ast.Ident); ok && fun.Name == "new" && len(v.Args) == 1 {
+			return receiverTypeName(v.Args[0]), true
+		}
+		if paren, ok := v.Fun.(*ast.ParenExpr); ok {
+			_, pointer = paren.X.(*ast.StarExpr)
+			return receiverTypeName(paren.X), pointer
+		}
+	case *ast.UnaryExpr:
+		if lit, ok := v.X.(*ast.CompositeLit); ok && v.Op == token.AND {
+			return receiverTypeName(lit.Type), true
+		}
+	case *ast.CompositeLit:
+		return receiverTypeName(v.Type), false
+	}
+	return "", false
+}
+
+// signatureString renders types of parameters and results of the function without names like "(string, ...int) error"
+func signatureString(fset *token.FileSet, funcType *ast.FuncType) string {
+	render := func(list *ast.FieldList) []string {
+		if list == nil {
+			return nil
+		}
+		var types []string
+		for _, field := range list.List {
+			var buf bytes.Buffer
+			if err := printer.Fprint(&buf, fset, field.Type); err != nil {
+				continue
+			}
+			for range max(len(field.Names), 1) {
+				types = append(types, buf.String())
+			}
+		}
+		return types
+	}
+
+	signature := "(" + strings.Join(render(funcType.Params), ", ") + ")"
+	switch results := render(funcType.Results); len(results) {
+	case 0:
+	case 1:
+		signature += " " + results[0]
+	default:
+		signature += " (" + strings.Join(results, ", ") + ")"
+	}
+	return signature
+}
+
+// newLineOfOldLine returns the line of the new file where the line of the old file was removed,
+// it is the last context or added line before it, so it exists even if the end of the file was removed
+func newLineOfOldLine(diff string, oldLine int) int {
+	next := 0 // number of the next line of the new file
+	for _, hunk := range model.ParseHunks(diff) {
+		next = hunk.NewStart
+		for _, line := range hunk.Lines {
+			switch {
+			case line.Kind == model.DiffLineAdded:
+				next = line.NewLine + 1
+			case line.OldLine >= oldLine:
+				return max(next-1, 1)
+			case line.Kind == model.DiffLineContext:
+				next = line.NewLine + 1
+			}
+		}
+	}
+	return max(next-1, 1)
+}
@@ -0,0 +1,1138 @@
+package analyze
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JSAnalyzer extracts entities from JavaScript and TypeScript sources using a tokenizer
+// and a declaration parser, it understands classes, decorators, arrow functions,
+// object members and export modifiers which diff patterns can't recognize reliably.
+//
+// The parser is written here because there is no pure Go TypeScript parser to depend on:
+// tree-sitter grammars need cgo, esbuild keeps its AST in internal packages and goja parses
+// only ES5 JavaScript. Only declarations are parsed, bodies are skipped by matching brackets,
+// so unknown syntax inside functions doesn't break the result; sources with unbalanced brackets
+// or unterminated literals return an error and the diff patterns are used instead
+type JSAnalyzer struct{}
+
+// NewJSAnalyzer creates a new JavaScript/TypeScript analyzer
+func NewJSAnalyzer() *JSAnalyzer {
+	return &JSAnalyzer{}
+}
+
+// ParseEntities parses the source and returns declared entities with their line ranges
+func (ja *JSAnalyzer) ParseEntities(content string) ([]ChangedEntity, error) {
+	tokens, err := tokenizeJS(content)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := newJSParser(content, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	p.parseStatements(0, len(p.tokens), "", false)
+	p.applyExportLists()
+
+	return p.entities, nil
+}
+
+type jsTokenKind int
+
+const (
+	jsTokenEOF jsTokenKind = iota
+	jsTokenIdent
+	jsTokenPunct
+	jsTokenString
+	jsTokenTemplate
+	jsTokenNumber
+	jsTokenRegex
+)
+
+type jsToken struct {
+	kind  jsTokenKind
+	text  string
+	line  int    // line where the token starts
+	start int    // byte offset of the token start
+	end   int    // byte offset after the token end
+	nl    bool   // whether the token is the first on its line
+	doc   string // comments right before the token
+}
+
+// jsPunctuators are multi-character punctuators that matter for parsing,
+// everything else is tokenized char by char so generics like Array<Array<T>> stay balanced
+var jsPunctuators = []string{"...", "===", "!==", "=>", "?.", "==", "!=", "&&", "||", "??", "++", "--"}
+
+// jsRegexPrecedingKeywords are keywords after which a slash starts a regular expression
+var jsRegexPrecedingKeywords = map[string]struct{}{
+	"return": {}, "typeof": {}, "instanceof": {}, "in": {}, "of": {}, "new": {}, "delete": {},
+	"void": {}, "throw": {}, "case": {}, "do": {}, "else": {}, "yield": {}, "await": {},
+}
+
+type jsLexer struct {
+	src  string
+	pos  int
+	line int
+	nl   bool
+	doc  []string
+	prev jsToken
+}
+
+// tokenizeJS splits JavaScript/TypeScript source into tokens, expressions inside
+// template literals are skipped as a part of the template token
+func tokenizeJS(src string) ([]jsToken, error) {
+	l := &jsLexer{src: src, line: 1, nl: true}
+	if strings.HasPrefix(src, "#!") {
+		l.skipLine()
+	}
+
+	var tokens []jsToken
+	for {
+		tok, ok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return tokens, nil
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+func (l *jsLexer) next() (jsToken, bool, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return jsToken{}, false, err
+	}
+	if l.pos >= len(l.src) {
+		return jsToken{}, false, nil
+	}
+
+	tok := jsToken{line: l.line, start: l.pos, nl: l.nl, doc: strings.Join(l.doc, "\n")}
+	l.nl, l.doc = false, nil
+
+	var err error
+	c := l.src[l.pos]
+	switch {
+	case isJSIdentStart(c):
+		tok.kind = jsTokenIdent
+		for l.pos < len(l.src) && isJSIdentPart(l.src[l.pos]) {
+			l.pos++
+		}
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		tok.kind = jsTokenNumber
+		for l.pos < len(l.src) && (isJSIdentPart(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+	case c == '"' || c == '\'':
+		tok.kind = jsTokenString
+		l.scanString(c)
+	case c == '`':
+		tok.kind = jsTokenTemplate
+		err = l.scanTemplate()
+	case c == '/' && l.regexAllowed():
+		tok.kind = jsTokenRegex
+		err = l.scanRegex()
+	default:
+		tok.kind = jsTokenPunct
+		l.pos++
+		for _, punct := range jsPunctuators {
+			if strings.HasPrefix(l.src[tok.start:], punct) {
+				l.pos = tok.start + len(punct)
+				break
+			}
+		}
+	}
+	if err != nil {
+		return jsToken{}, false, err
+	}
+
+	tok.end = l.pos
+	tok.text = l.src[tok.start:tok.end]
+	l.prev = tok
+
+	return tok, true, nil
+}
+
+func (l *jsLexer) skipSpaceAndComments() error {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.nl = true
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "//"):
+			start := l.pos
+			l.skipLine()
+			l.addDoc(strings.TrimSpace(l.src[start:l.pos]))
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return fmt.Errorf("unterminated comment at line %d", l.line)
+			}
+			comment := l.src[l.pos : l.pos+2+end+2]
+			l.addDoc(comment)
+			l.line += strings.Count(comment, "\n")
+			l.pos += len(comment)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// addDoc remembers a comment for the next token, trailing comments of the previous line are ignored
+func (l *jsLexer) addDoc(comment string) {
+	if l.nl || l.prev.kind == jsTokenEOF {
+		l.doc = append(l.doc, comment)
+	}
+}
+
+func (l *jsLexer) skipLine() {
+	for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+		l.pos++
+	}
+}
+
+// scanString scans a quoted string, an unterminated string ends at the line end
+// so that apostrophes in JSX text don't swallow the rest of the file
+func (l *jsLexer) scanString(quote byte) {
+	l.pos++
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			if l.pos+1 < len(l.src) && l.src[l.pos+1] == '\n' {
+				l.line++
+			}
+			l.pos += 2
+		case quote:
+			l.pos++
+			return
+		case '\n':
+			return
+		default:
+			l.pos++
+		}
+	}
+	l.pos = min(l.pos, len(l.src))
+}
+
+func (l *jsLexer) scanTemplate() error {
+	startLine := l.line
+	l.pos++
+	for l.pos < len(l.src) {
+		switch {
+		case l.src[l.pos] == '\\':
+			if l.pos+1 < len(l.src) && l.src[l.pos+1] == '\n' {
+				l.line++
+			}
+			l.pos += 2
+		case l.src[l.pos] == '`':
+			l.pos++
+			return nil
+		case l.src[l.pos] == '\n':
+			l.line++
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "${"):
+			l.pos += 2
+			if err := l.skipTemplateExpression(); err != nil {
+				return err
+			}
+		default:
+			l.pos++
+		}
+	}
+	return fmt.Errorf("unterminated template literal at line %d", startLine)
+}
+
+func (l *jsLexer) skipTemplateExpression() error {
+	startLine := l.line
+	depth := 1
+	for {
+		tok, ok, err := l.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("unterminated template expression at line %d", startLine)
+		}
+		switch tok.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (l *jsLexer) scanRegex() error {
+	startLine := l.line
+	l.pos++
+	inClass := false
+	for l.pos < len(l.src) {
+		switch l.src[l.pos] {
+		case '\\':
+			l.pos += 2
+			continue
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				l.pos++
+				for l.pos < len(l.src) && isJSIdentPart(l.src[l.pos]) {
+					l.pos++
+				}
+				return nil
+			}
+		case '\n':
+			return fmt.Errorf("unterminated regular expression at line %d", startLine)
+		}
+		l.pos++
+	}
+	return fmt.Errorf("unterminated regular expression at line %d", startLine)
+}
+
+// regexAllowed reports whether a slash at the current position starts a regular expression
+// rather than a division, it depends on the previous token
+func (l *jsLexer) regexAllowed() bool {
+	switch l.prev.kind {
+	case jsTokenEOF:
+		return true
+	case jsTokenIdent:
+		_, ok := jsRegexPrecedingKeywords[l.prev.text]
+		return ok
+	case jsTokenPunct:
+		// `</` closes a JSX element
+		return l.prev.text != ")" && l.prev.text != "]" && l.prev.text != "}" && l.prev.text != "<"
+	}
+	return false
+}
+
+func isJSIdentStart(c byte) bool {
+	return c == '_' || c == '$' || c == '#' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isJSIdentPart(c byte) bool {
+	return isJSIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// jsParser walks tokens and collects declarations, it doesn't descend into function bodies
+type jsParser struct {
+	src      string
+	lines    []string
+	tokens   []jsToken
+	match    []int // index of the matching bracket for every opening and closing bracket
+	entities []ChangedEntity
+
+	exportedNames map[string]struct{}
+	defaultName   string
+}
+
+// jsDecl describes a declaration found by the parser
+type jsDecl struct {
+	kind      EntityType
+	name      string
+	container string
+	first     int // first token including decorators
+	sigStart  int // first token of the signature
+	sigEnd    int // last token of the signature, -1 if there is no signature
+	last      int // last token of the declaration
+	exported  bool
+	isDefault bool
+}
+
+func newJSParser(src string, tokens []jsToken) (*jsParser, error) {
+	p := &jsParser{
+		src:           src,
+		lines:         strings.Split(src, "\n"),
+		tokens:        tokens,
+		match:         make([]int, len(tokens)),
+		exportedNames: make(map[string]struct{}),
+	}
+
+	var stack []int
+	for i, tok := range tokens {
+		p.match[i] = -1
+		if tok.kind != jsTokenPunct {
+			continue
+		}
+		switch tok.text {
+		case "(", "[", "{":
+			stack = append(stack, i)
+		case ")", "]", "}":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("unexpected %q at line %d", tok.text, tok.line)
+			}
+			open := stack[len(stack)-1]
+			if !jsBracketsMatch(tokens[open].text, tok.text) {
+				return nil, fmt.Errorf("mismatched %q at line %d", tok.text, tok.line)
+			}
+			stack = stack[:len(stack)-1]
+			p.match[open], p.match[i] = i, open
+		}
+	}
+	if len(stack) > 0 {
+		open := tokens[stack[len(stack)-1]]
+		return nil, fmt.Errorf("unclosed %q at line %d", open.text, open.line)
+	}
+
+	return p, nil
+}
+
+func jsBracketsMatch(open, closing string) bool {
+	return (open == "(" && closing == ")") || (open == "[" && closing == "]") || (open == "{" && closing == "}")
+}
+
+// parseStatements parses declarations in the module, namespace or block scope
+func (p *jsParser) parseStatements(i, end int, container string, exportedScope bool) {
+	for i < end {
+		if p.is(i, ";") {
+			i++
+			continue
+		}
+		i = p.parseStatement(i, end, container, exportedScope) + 1
+	}
+}
+
+// parseStatement parses a single statement and returns the index of its last token
+func (p *jsParser) parseStatement(i, end int, container string, exportedScope bool) int {
+	d := jsDecl{container: container, first: i, sigEnd: -1}
+
+	for p.is(i, "@") {
+		i = p.skipDecorator(i) + 1
+	}
+	d.sigStart = i
+
+modifiers:
+	for ; i < end; i++ {
+		switch {
+		case p.is(i, "export"):
+			d.exported = true
+		case p.is(i, "default") && d.exported:
+			d.isDefault = true
+		case p.is(i, "declare") && p.isIdent(i+1):
+		case p.is(i, "abstract") && p.is(i+1, "class"):
+		default:
+			break modifiers
+		}
+	}
+	if i >= end {
+		return end - 1
+	}
+	d.exported = d.exported || exportedScope
+
+	switch {
+	case p.is(i, "import"):
+		return p.exprEnd(i, end, false)
+
+	case d.exported && (p.is(i, "{") || (p.is(i, "type") && p.is(i+1, "{"))):
+		if p.is(i, "type") {
+			i++
+		}
+		p.collectExportList(i)
+		return p.exprEnd(i, end, false)
+
+	case p.is(i, "function"), p.is(i, "async") && p.is(i+1, "function") && !p.tokens[i+1].nl:
+		if p.is(i, "async") {
+			i++
+		}
+		return p.parseFunction(d, i, end)
+
+	case p.is(i, "class"):
+		return p.parseClass(d, i, end)
+
+	case p.is(i, "interface") && p.isIdent(i+1):
+		return p.parseBlockDecl(d, EntityTypeInterface, i+1, end)
+
+	case p.is(i, "enum") && p.isIdent(i+1):
+		return p.parseBlockDecl(d, EntityTypeType, i+1, end)
+
+	case p.is(i, "const") && p.is(i+1, "enum") && p.isIdent(i+2):
+		return p.parseBlockDecl(d, EntityTypeType, i+2, end)
+
+	case p.is(i, "type") && p.isIdent(i+1) && (p.is(i+2, "=") || p.is(i+2, "<")):
+		return p.parseTypeAlias(d, i+1, end)
+
+	case p.is(i, "const"), p.is(i, "let"), p.is(i, "var"):
+		return p.parseVariables(d, i, end)
+
+	case (p.is(i, "namespace") || p.is(i, "module")) && p.isName(i+1):
+		j := i + 1
+		for p.is(j+1, ".") && p.isIdent(j+2) {
+			j += 2
+		}
+		if !p.is(j+1, "{") {
+			return p.exprEnd(i, end, false)
+		}
+		name := strings.Trim(p.text(i+1, j), `"'`)
+		p.parseStatements(j+2, p.match[j+1], joinJSName(container, name), d.exported)
+		return p.match[j+1]
+
+	case d.isDefault:
+		return p.parseDefaultExport(d, i, end)
+	}
+
+	return p.exprEnd(i, end, false)
+}
+
+// parseDefaultExport parses `export default <expression>`
+func (p *jsParser) parseDefaultExport(d jsDecl, i, end int) int {
+	if sigEnd, last, ok := p.parseFunctionValue(i, end, false); ok {
+		d.kind, d.name, d.sigEnd, d.last = EntityTypeFunction, "default", sigEnd, last
+		p.addEntity(d)
+		return last
+	}
+
+	last := p.exprEnd(i, end, false)
+	switch {
+	case p.is(i, "{"):
+		p.parseObject(i, joinJSName(d.container, "default"), true)
+	case p.isIdent(i) && last == i:
+		p.defaultName = p.tokens[i].text
+		p.exportedNames[p.tokens[i].text] = struct{}{}
+	}
+	return last
+}
+
+// parseFunction parses a function declaration starting at the `function` keyword
+func (p *jsParser) parseFunction(d jsDecl, i, end int) int {
+	j := i + 1
+	if p.is(j, "*") {
+		j++
+	}
+	if p.isIdent(j) {
+		d.name = p.tokens[j].text
+		j++
+	} else if d.isDefault {
+		d.name = "default"
+	} else {
+		return p.exprEnd(i, end, false)
+	}
+
+	if p.is(j, "<") {
+		closing, ok := p.skipAngles(j)
+		if !ok {
+			return p.exprEnd(i, end, false)
+		}
+		j = closing + 1
+	}
+	if !p.is(j, "(") {
+		return p.exprEnd(i, end, false)
+	}
+
+	d.kind = EntityTypeFunction
+	d.sigEnd, d.last = p.parseFunctionTail(p.match[j], end)
+	p.addEntity(d)
+
+	return d.last
+}
+
+// parseFunctionTail parses an optional return type and a body after the closing
+// parenthesis of parameters, it returns the last signature token and the last token
+func (p *jsParser) parseFunctionTail(closeParen, end int) (int, int) {
+	sigEnd := closeParen
+	j := closeParen + 1
+	if p.is(j, ":") {
+		j = p.skipType(j+1, end, false)
+		sigEnd = j - 1
+	}
+
+	switch {
+	case p.is(j, "{"):
+		return sigEnd, p.match[j]
+	case p.is(j, ";"):
+		return sigEnd, j
+	}
+	return sigEnd, sigEnd
+}
+
+// parseFunctionValue parses an arrow function or a function expression,
+// it returns the last signature token, the last token and whether there was a function
+func (p *jsParser) parseFunctionValue(i, end int, commaStops bool) (int, int, bool) {
+	j := i
+	if p.is(j, "async") && !p.is(j+1, "=>") && !p.is(j+1, ".") && !p.tokens[min(j+1, len(p.tokens)-1)].nl {
+		j++
+	}
+
+	if p.is(j, "function") {
+		j++
+		if p.is(j, "*") {
+			j++
+		}
+		if p.isIdent(j) {
+			j++
+		}
+		if p.is(j, "<") {
+			closing, ok := p.skipAngles(j)
+			if !ok {
+				return 0, 0, false
+			}
+			j = closing + 1
+		}
+		if !p.is(j, "(") {
+			return 0, 0, false
+		}
+		sigEnd, last := p.parseFunctionTail(p.match[j], end)
+		return sigEnd, last, true
+	}
+
+	if p.is(j, "<") {
+		closing, ok := p.skipAngles(j)
+		if !ok {
+			return 0, 0, false
+		}
+		j = closing + 1
+	}
+
+	var arrow int
+	switch {
+	case p.is(j, "("):
+		arrow = p.match[j] + 1
+		if p.is(arrow, ":") {
+			arrow = p.skipType(arrow+1, end, true)
+		}
+		if !p.is(arrow, "=>") {
+			return 0, 0, false
+		}
+	case p.isIdent(j) && p.is(j+1, "=>"):
+		arrow = j + 1
+	default:
+		return 0, 0, false
+	}
+
+	if p.is(arrow+1, "{") {
+		return arrow - 1, p.match[arrow+1], true
+	}
+	return arrow - 1, p.exprEnd(arrow+1, end, commaStops), true
+}
+
+// parseClass parses a class declaration starting at the `class` keyword
+func (p *jsParser) parseClass(d jsDecl, i, end int) int {
+	j := i + 1
+	switch {
+	case p.isIdent(j) && !p.is(j, "extends") && !p.is(j, "implements"):
+		d.name = p.tokens[j].text
+		j++
+	case d.isDefault:
+		d.name = "default"
+	default:
+		return p.exprEnd(i, end, false)
+	}
+
+	body := p.findBody(j, end)
+	if body < 0 {
+		return p.exprEnd(i, end, false)
+	}
+
+	d.kind = EntityTypeType
+	d.sigEnd, d.last = body-1, p.match[body]
+	p.addEntity(d)
+
+	p.parseClassBody(body+1, p.match[body], joinJSName(d.container, d.name), d.exported)
+
+	return d.last
+}
+
+// parseClassBody parses members of a class, methods and function-valued properties become entities
+func (p *jsParser) parseClassBody(i, end int, className string, classExported bool) {
+	for i < end {
+		if p.is(i, ";") {
+			i++
+			continue
+		}
+
+		d := jsDecl{kind: EntityTypeMethod, container: className, first: i, sigEnd: -1, exported: classExported}
+		for p.is(i, "@") {
+			i = p.skipDecorator(i) + 1
+		}
+		d.sigStart = i
+
+		for p.isMemberModifier(i) {
+			if p.is(i, "private") || p.is(i, "protected") {
+				d.exported = false
+			}
+			i++
+		}
+		if p.is(i, "*") {
+			i++
+		}
+
+		if p.is(i, "{") { // static initialization block
+			i = p.match[i] + 1
+			continue
+		}
+
+		name, nameEnd, ok := p.memberName(i)
+		if !ok {
+			i = p.exprEnd(i, end, false) + 1
+			continue
+		}
+		d.name = name
+		if strings.HasPrefix(name, "#") {
+			d.exported = false
+		}
+
+		j := nameEnd + 1
+		if p.is(j, "?") || p.is(j, "!") {
+			j++
+		}
+		if p.is(j, "<") {
+			if closing, ok := p.skipAngles(j); ok {
+				j = closing + 1
+			}
+		}
+
+		if p.is(j, "(") {
+			d.sigEnd, d.last = p.parseFunctionTail(p.match[j], end)
+			p.addEntity(d)
+			i = d.last + 1
+			continue
+		}
+
+		// property, it is an entity only when it holds a function
+		if p.is(j, ":") {
+			j = p.skipType(j+1, end, false)
+		}
+		if p.is(j, "=") {
+			if sigEnd, last, ok := p.parseFunctionValue(j+1, end, false); ok {
+				d.sigEnd, d.last = sigEnd, last
+				p.addEntity(d)
+				i = last + 1
+				continue
+			}
+		}
+		i = p.exprEnd(nameEnd, end, false) + 1
+	}
+}
+
+// parseObject parses members of an object literal, methods and function-valued properties become entities
+func (p *jsParser) parseObject(open int, container string, exported bool) {
+	end := p.match[open]
+	for i := open + 1; i < end; {
+		if p.is(i, ",") {
+			i++
+			continue
+		}
+		if p.is(i, "...") {
+			i = p.exprEnd(i, end, true) + 1
+			continue
+		}
+
+		d := jsDecl{kind: EntityTypeMethod, container: container, first: i, sigStart: i, sigEnd: -1, exported: exported}
+		for p.isMemberModifier(i) {
+			i++
+		}
+		if p.is(i, "*") {
+			i++
+		}
+
+		name, nameEnd, ok := p.memberName(i)
+		if !ok {
+			i = p.exprEnd(i, end, true) + 1
+			continue
+		}
+		d.name = name
+
+		j := nameEnd + 1
+		if p.is(j, "<") {
+			if closing, ok := p.skipAngles(j); ok {
+				j = closing + 1
+			}
+		}
+
+		switch {
+		case p.is(j, "("):
+			d.sigEnd, d.last = p.parseFunctionTail(p.match[j], end)
+			p.addEntity(d)
+			i = d.last + 1
+			continue
+
+		case p.is(j, ":"):
+			if sigEnd, last, ok := p.parseFunctionValue(j+1, end, true); ok {
+				d.sigEnd, d.last = sigEnd, last
+				p.addEntity(d)
+				i = last + 1
+				continue
+			}
+			if p.is(j+1, "{") {
+				last := p.exprEnd(j+1, end, true)
+				p.parseObject(j+1, joinJSName(container, name), exported)
+				i = last + 1
+				continue
+			}
+		}
+		i = p.exprEnd(nameEnd, end, true) + 1
+	}
+}
+
+// parseVariables parses a `const`, `let` or `var` statement, function values become functions
+// and object literals are parsed for their members
+func (p *jsParser) parseVariables(d jsDecl, i, end int) int {
+	kind := EntityTypeVar
+	if p.is(i, "const") {
+		kind = EntityTypeConst
+	}
+
+	j := i + 1
+	for j < end {
+		decl := d
+		if j != i+1 {
+			decl.first, decl.sigStart = j, j
+		}
+
+		if !p.isIdent(j) { // destructuring
+			return p.exprEnd(j, end, false)
+		}
+		decl.name = p.tokens[j].text
+		j++
+		if p.is(j, "!") {
+			j++
+		}
+		if p.is(j, ":") {
+			j = p.skipType(j+1, end, false)
+		}
+
+		switch {
+		case !p.is(j, "="):
+			decl.kind, decl.sigEnd, decl.last = kind, j-1, j-1
+			p.addEntity(decl)
+
+		default:
+			if sigEnd, last, ok := p.parseFunctionValue(j+1, end, true); ok {
+				decl.kind, decl.sigEnd, decl.last = EntityTypeFunction, sigEnd, last
+				p.addEntity(decl)
+				j = last + 1
+				break
+			}
+
+			decl.kind, decl.sigEnd, decl.last = kind, j-1, p.exprEnd(j+1, end, true)
+			p.addEntity(decl)
+			if p.is(j+1, "{") {
+				p.parseObject(j+1, joinJSName(d.container, decl.name), decl.exported)
+			}
+			j = decl.last + 1
+		}
+
+		if !p.is(j, ",") {
+			break
+		}
+		j++
+	}
+
+	if p.is(j, ";") {
+		return j
+	}
+	return j - 1
+}
+
+// parseBlockDecl parses an interface or enum declaration starting at its name
+func (p *jsParser) parseBlockDecl(d jsDecl, kind EntityType, nameIdx, end int) int {
+	body := p.findBody(nameIdx+1, end)
+	if body < 0 {
+		return p.exprEnd(nameIdx, end, false)
+	}
+
+	d.kind, d.name = kind, p.tokens[nameIdx].text
+	d.sigEnd, d.last = body-1, p.match[body]
+	p.addEntity(d)
+
+	return d.last
+}
+
+// parseTypeAlias parses a type alias starting at its name
+func (p *jsParser) parseTypeAlias(d jsDecl, nameIdx, end int) int {
+	j := nameIdx + 1
+	if p.is(j, "<") {
+		closing, ok := p.skipAngles(j)
+		if !ok {
+			return p.exprEnd(nameIdx, end, false)
+		}
+		j = closing + 1
+	}
+
+	d.kind, d.name = EntityTypeType, p.tokens[nameIdx].text
+	d.sigEnd, d.last = j-1, p.exprEnd(j, end, false)
+	p.addEntity(d)
+
+	if p.is(d.last+1, ";") {
+		return d.last + 1
+	}
+	return d.last
+}
+
+// collectExportList remembers names from `export { a, b as c }`
+func (p *jsParser) collectExportList(open int) {
+	for j := open + 1; j < p.match[open]; j++ {
+		if p.isIdent(j) && (j == open+1 || p.is(j-1, ",")) {
+			p.exportedNames[p.tokens[j].text] = struct{}{}
+		}
+		if p.is(j, "default") && p.is(j-1, "as") && p.isIdent(j-2) {
+			p.defaultName = p.tokens[j-2].text
+		}
+	}
+}
+
+// applyExportLists marks module level entities that are exported separately from their declaration,
+// members of classes and objects are exported with them as if the export was inline
+func (p *jsParser) applyExportLists() {
+	for i := range p.entities {
+		e := &p.entities[i]
+		name, _, isMember := strings.Cut(e.FullName, ".")
+		_, exported := p.exportedNames[name]
+		if exported || (p.defaultName != "" && name == p.defaultName) {
+			e.IsExported = true
+		}
+		if !isMember && p.defaultName != "" && name == p.defaultName {
+			e.IsDefault = true
+		}
+	}
+}
+
+func (p *jsParser) addEntity(d jsDecl) {
+	lastTok := p.tokens[d.last]
+	entity := ChangedEntity{
+		Type:       d.kind,
+		Name:       d.name,
+		FullName:   joinJSName(d.container, d.name),
+		IsExported: d.exported,
+		IsDefault:  d.isDefault,
+		StartLine:  p.tokens[d.first].line,
+		EndLine:    lastTok.line + strings.Count(lastTok.text, "\n"),
+		DocComment: strings.TrimSpace(p.tokens[d.first].doc),
+	}
+	if d.sigEnd >= d.sigStart {
+		entity.Signature = strings.Join(strings.Fields(p.text(d.sigStart, d.sigEnd)), " ")
+	}
+	if entity.EndLine <= len(p.lines) {
+		entity.AfterCode = strings.Join(p.lines[entity.StartLine-1:entity.EndLine], "\n")
+	}
+
+	p.entities = append(p.entities, entity)
+}
+
+// exprEnd returns the last token of an expression or a statement that starts at i,
+// it ends before `;` (or `,` if commaStops) or at a line break that looks like an automatic semicolon
+func (p *jsParser) exprEnd(i, end int, commaStops bool) int {
+	if i >= end {
+		return end - 1
+	}
+	j := i
+	for {
+		if p.isOpener(j) {
+			j = p.match[j]
+		}
+		k := j + 1
+		if k >= end {
+			return j
+		}
+		next := p.tokens[k]
+		if p.is(k, ";") || (commaStops && p.is(k, ",")) {
+			return j
+		}
+		if next.nl && jsEndsExpression(p.tokens[j]) && jsStartsStatement(next) {
+			return j
+		}
+		j = k
+	}
+}
+
+// skipType skips a type annotation and returns the index of the first token after it
+func (p *jsParser) skipType(i, end int, stopAtArrow bool) int {
+	j := i
+	for j < end {
+		tok := p.tokens[j]
+		switch {
+		case p.is(j, "{"):
+			if j > i && !jsTypeContinues(p.tokens[j-1]) {
+				return j
+			}
+			j = p.match[j] + 1
+			continue
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.match[j] + 1
+			continue
+		case p.is(j, "<"):
+			if closing, ok := p.skipAngles(j); ok {
+				j = closing + 1
+				continue
+			}
+		case p.is(j, ";"), p.is(j, ","), p.is(j, "="), p.is(j, ")"), p.is(j, "]"), p.is(j, "}"):
+			return j
+		case stopAtArrow && p.is(j, "=>"):
+			return j
+		case j > i && tok.nl && jsEndsExpression(p.tokens[j-1]) && jsStartsStatement(tok):
+			return j
+		}
+		j++
+	}
+	return j
+}
+
+// skipAngles skips type parameters or arguments and returns the index of the closing `>`
+func (p *jsParser) skipAngles(i int) (int, bool) {
+	depth := 0
+	for j := i; j < len(p.tokens); j++ {
+		switch {
+		case p.isOpener(j):
+			j = p.match[j]
+		case p.is(j, "<"):
+			depth++
+		case p.is(j, ">"):
+			depth--
+			if depth == 0 {
+				return j, true
+			}
+		case p.is(j, ";"), p.is(j, ")"), p.is(j, "]"), p.is(j, "}"):
+			return i, false
+		}
+	}
+	return i, false
+}
+
+// skipDecorator skips a decorator like @Component({...}) and returns its last token
+func (p *jsParser) skipDecorator(i int) int {
+	j := i + 1
+	for p.is(j+1, ".") && p.isIdent(j+2) {
+		j += 2
+	}
+	if p.is(j+1, "(") {
+		return p.match[j+1]
+	}
+	return j
+}
+
+// findBody returns the index of the `{` that opens a declaration body
+func (p *jsParser) findBody(i, end int) int {
+	for j := i; j < end; j++ {
+		switch {
+		case p.is(j, "{"):
+			return j
+		case p.is(j, "<"):
+			if closing, ok := p.skipAngles(j); ok {
+				j = closing
+			}
+		case p.is(j, "("), p.is(j, "["):
+			j = p.match[j]
+		case p.is(j, ";"):
+			return -1
+		}
+	}
+	return -1
+}
+
+// memberName parses a class or object member name, it returns the name and its last token
+func (p *jsParser) memberName(i int) (string, int, bool) {
+	if i >= len(p.tokens) {
+		return "", 0, false
+	}
+	tok := p.tokens[i]
+	switch {
+	case p.is(i, "["):
+		return p.text(i, p.match[i]), p.match[i], true
+	case tok.kind == jsTokenIdent, tok.kind == jsTokenNumber:
+		return tok.text, i, true
+	case tok.kind == jsTokenString:
+		return strings.Trim(tok.text, `"'`), i, true
+	}
+	return "", 0, false
+}
+
+// isMemberModifier reports whether the token is a modifier of the member that follows it
+func (p *jsParser) isMemberModifier(i int) bool {
+	switch {
+	case p.is(i, "public"), p.is(i, "private"), p.is(i, "protected"), p.is(i, "static"),
+		p.is(i, "readonly"), p.is(i, "abstract"), p.is(i, "async"), p.is(i, "override"),
+		p.is(i, "declare"), p.is(i, "accessor"), p.is(i, "get"), p.is(i, "set"):
+	default:
+		return false
+	}
+	if i+1 >= len(p.tokens) || p.tokens[i+1].nl {
+		return false
+	}
+	next := p.tokens[i+1]
+	return next.kind == jsTokenIdent || next.kind == jsTokenString || next.kind == jsTokenNumber ||
+		p.is(i+1, "[") || p.is(i+1, "*") || (p.is(i, "static") && p.is(i+1, "{"))
+}
+
+func (p *jsParser) is(i int, text string) bool {
+	if i < 0 || i >= len(p.tokens) {
+		return false
+	}
+	tok := p.tokens[i]
+	return (tok.kind == jsTokenPunct || tok.kind == jsTokenIdent) && tok.text == text
+}
+
+func (p *jsParser) isIdent(i int) bool {
+	return i >= 0 && i < len(p.tokens) && p.tokens[i].kind == jsTokenIdent
+}
+
+func (p *jsParser) isName(i int) bool {
+	return p.isIdent(i) || (i < len(p.tokens) && p.tokens[i].kind == jsTokenString)
+}
+
+func (p *jsParser) isOpener(i int) bool {
+	return p.is(i, "(") || p.is(i, "[") || p.is(i, "{")
+}
+
+// text returns the source text between the start of token i and the end of token j
+func (p *jsParser) text(i, j int) string {
+	return p.src[p.tokens[i].start:p.tokens[j].end]
+}
+
+func joinJSName(container, name string) string {
+	if container == "" {
+		return name
+	}
+	return container + "." + name
+}
+
+// jsEndsExpression reports whether an expression can end with the token
+func jsEndsExpression(tok jsToken) bool {
+	switch tok.kind {
+	case jsTokenIdent, jsTokenString, jsTokenTemplate, jsTokenNumber, jsTokenRegex:
+		return true
+	case jsTokenPunct:
+		switch tok.text {
+		case ")", "]", "}", ">", "++", "--":
+			return true
+		}
+	}
+	return false
+}
+
+// jsStartsStatement reports whether the token on a new line starts a new statement
+func jsStartsStatement(tok jsToken) bool {
+	if tok.kind == jsTokenPunct {
+		return tok.text == "@"
+	}
+	if tok.kind != jsTokenIdent {
+		return false
+	}
+	switch tok.text {
+	case "instanceof", "in", "of", "as", "satisfies", "extends", "implements":
+		return false
+	}
+	return true
+}
+
+// jsTypeContinues reports whether a type goes on after the token, so `{` after it is an object type
+func jsTypeContinues(tok jsToken) bool {
+	switch tok.text {
+	case ":", "|", "&", "<", ",", "=>", "(", "[", "=", "?", "keyof", "typeof", "readonly", "extends":
+		return true
+	}
+	return false
+}
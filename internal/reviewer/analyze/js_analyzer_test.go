@@ -0,0 +1,139 @@
+package analyze
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// jsEntity is a part of the parsed entity checked by tests
+type jsEntity struct {
+	Type      EntityType
+	FullName  string
+	Exported  bool
+	Default   bool
+	StartLine int
+	EndLine   int
+	Signature string
+}
+
+func TestJSAnalyzerParseEntities(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []jsEntity
+	}{
+		{
+			name:    "typed function",
+			content: "export function add(a: number, b: number): number {\n  return a + b\n}\n",
+			want: []jsEntity{
+				{EntityTypeFunction, "add", true, false, 1, 3, "export function add(a: number, b: number): number"},
+			},
+		},
+		{
+			name:    "anonymous default function",
+			content: "export default function () {\n  return 1\n}\n",
+			want: []jsEntity{
+				{EntityTypeFunction, "default", true, true, 1, 3, "export default function ()"},
+			},
+		},
+		{
+			name:    "async arrow function",
+			content: "export const handler = async (req: Request, res: Response) => {\n  res.send('ok')\n}\n",
+			want: []jsEntity{
+				{EntityTypeFunction, "handler", true, false, 1, 3, "export const handler = async (req: Request, res: Response)"},
+			},
+		},
+		{
+			name: "decorated class",
+			content: "@Component({selector: 'app'})\n" +
+				"export class Widget extends Base {\n" +
+				"  @Input() name: string\n" +
+				"  private count = 0\n" +
+				"  constructor(private svc: Service) {\n" +
+				"    super()\n" +
+				"  }\n" +
+				"  render(): string {\n" +
+				"    return `<div>${this.name}</div>`\n" +
+				"  }\n" +
+				"  static create = (n: string) => new Widget(n)\n" +
+				"}\n",
+			want: []jsEntity{
+				{EntityTypeType, "Widget", true, false, 1, 12, "export class Widget extends Base"},
+				{EntityTypeMethod, "Widget.constructor", true, false, 5, 7, "constructor(private svc: Service)"},
+				{EntityTypeMethod, "Widget.render", true, false, 8, 10, "render(): string"},
+				{EntityTypeMethod, "Widget.create", true, false, 11, 11, "static create = (n: string)"},
+			},
+		},
+		{
+			name:    "object members exported by a list",
+			content: "const api = {\n  get: (id) => fetch(`/x/${id}`),\n  post(body) {\n    return body\n  },\n}\nexport { api }\n",
+			want: []jsEntity{
+				{EntityTypeConst, "api", true, false, 1, 6, "const api"},
+				{EntityTypeMethod, "api.get", true, false, 2, 2, "get: (id)"},
+				{EntityTypeMethod, "api.post", true, false, 3, 5, "post(body)"},
+			},
+		},
+		{
+			name:    "class exported as default later",
+			content: "class Service {\n  run() {}\n}\nexport default Service\n",
+			want: []jsEntity{
+				{EntityTypeType, "Service", true, true, 1, 3, "class Service"},
+				{EntityTypeMethod, "Service.run", true, false, 2, 2, "run()"},
+			},
+		},
+		{
+			name:    "type declarations",
+			content: "interface User {\n  id: string\n}\ntype ID = string | number\nenum Color { Red, Green }\n",
+			want: []jsEntity{
+				{EntityTypeInterface, "User", false, false, 1, 3, "interface User"},
+				{EntityTypeType, "ID", false, false, 4, 4, "type ID"},
+				{EntityTypeType, "Color", false, false, 5, 5, "enum Color"},
+			},
+		},
+		{
+			name:    "regex with brackets and division",
+			content: "const re = /[/]}{/g\nfunction half(x) {\n  return x / 2\n}\n",
+			want: []jsEntity{
+				{EntityTypeConst, "re", false, false, 1, 1, "const re"},
+				{EntityTypeFunction, "half", false, false, 2, 4, "function half(x)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entities, err := NewJSAnalyzer().ParseEntities(tt.content)
+			if err != nil {
+				t.Fatalf("ParseEntities() error = %v", err)
+			}
+			got := make([]jsEntity, 0, len(entities))
+			for _, e := range entities {
+				got = append(got, jsEntity{e.Type, e.FullName, e.IsExported, e.IsDefault, e.StartLine, e.EndLine, e.Signature})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseEntities() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSAnalyzerParseEntitiesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unclosed bracket", "function broken( {\n", `unclosed "{"`},
+		{"unterminated template", "const s = `unterminated\n", "unterminated template literal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJSAnalyzer().ParseEntities(tt.content)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseEntities() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
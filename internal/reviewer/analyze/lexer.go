@@ -0,0 +1,236 @@
+package analyze
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// lexerRules are comment and string literal syntaxes of a language
+type lexerRules struct {
+	lineComments   []string // markers of comments to the end of line like "//" and "#"
+	blockComments  bool     // /* */ comments
+	nestedComments bool     // block comments may be nested like in Rust
+	quotes         string   // quotes of single-line strings with escapes
+	multiQuotes    string   // quotes of multi-line strings with escapes like JS template literals
+	rawQuotes      string   // quotes of multi-line strings without escapes like Go raw strings
+	tripleQuotes   string   // quotes of triple-quoted strings like Python """ and Java text blocks
+	rustStrings    bool     // r#"raw"# strings and 'c' chars that are not 'lifetimes
+	cppRawStrings  bool     // R"delim(raw)delim" strings
+}
+
+var languageLexerRules = map[SupportedLanguage]lexerRules{
+	LanguageGo:         {lineComments: []string{"//"}, blockComments: true, quotes: `"'`, rawQuotes: "`"},
+	LanguageJavaScript: {lineComments: []string{"//"}, blockComments: true, quotes: `"'`, multiQuotes: "`"},
+	LanguageTypeScript: {lineComments: []string{"//"}, blockComments: true, quotes: `"'`, multiQuotes: "`"},
+	LanguagePython:     {lineComments: []string{"#"}, quotes: `"'`, tripleQuotes: `"'`},
+	LanguageJava:       {lineComments: []string{"//"}, blockComments: true, quotes: `"'`, tripleQuotes: `"`},
+	LanguageRust:       {lineComments: []string{"//"}, blockComments: true, nestedComments: true, multiQuotes: `"`, rustStrings: true},
+	LanguageC:          {lineComments: []string{"//"}, blockComments: true, quotes: `"'`},
+	LanguageCpp:        {lineComments: []string{"//"}, blockComments: true, quotes: `"'`, cppRawStrings: true},
+}
+
+// StripCode replaces comments and contents of string literals of the code with spaces, quotes are kept,
+// so heuristic scanners don't match text of comments and strings and positions of the rest are not changed;
+// newlines are kept, code of an unsupported language is returned as is
+func StripCode(code string, language SupportedLanguage) string {
+	return lexCode(code, language, true)
+}
+
+// StripComments replaces comments of the code with spaces keeping string literals and newlines,
+// it is for scanners that match contents of strings like SQL queries
+func StripComments(code string, language SupportedLanguage) string {
+	return lexCode(code, language, false)
+}
+
+func lexCode(code string, language SupportedLanguage, maskStrings bool) string {
+	rules, ok := languageLexerRules[language]
+	if !ok {
+		return code
+	}
+
+	out := []byte(code)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+
+		if rules.isLineComment(rest) {
+			end := strings.IndexByte(rest, '\n')
+			if end == -1 {
+				end = len(rest)
+			}
+			blank(i, i+end)
+			i += end
+			continue
+		}
+		if rules.blockComments && strings.HasPrefix(rest, "/*") {
+			end := i + blockCommentEnd(rest, rules.nestedComments)
+			blank(i, end)
+			i = end
+			continue
+		}
+
+		open, close, end, ok := rules.stringLiteral(code, i)
+		if !ok {
+			i++
+			continue
+		}
+		if maskStrings {
+			blank(i+open, max(i+open, end-close))
+		}
+		i = end
+	}
+
+	return string(out)
+}
+
+func (r lexerRules) isLineComment(text string) bool {
+	for _, marker := range r.lineComments {
+		if strings.HasPrefix(text, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringLiteral returns lengths of the opening and closing delimiters of the string literal starting at the index
+// and the index after it, ok is false if there is no literal; an unterminated literal ends at the end of line
+// or of the code for multi-line literals
+func (r lexerRules) stringLiteral(code string, i int) (open, close, end int, ok bool) {
+	rest := code[i:]
+	c := rest[0]
+
+	if r.tripleQuotes != "" && strings.IndexByte(r.tripleQuotes, c) >= 0 && strings.HasPrefix(rest, strings.Repeat(string(c), 3)) {
+		delim := rest[:3]
+		return 3, 3, i + 3 + quotedEnd(rest[3:], delim, true, true), true
+	}
+	if r.rustStrings && !isIdentByte(code, i-1) {
+		if delim, n := rustRawDelimiter(rest); n > 0 {
+			return n, len(delim), i + n + quotedEnd(rest[n:], delim, false, true), true
+		}
+		if c == '\'' {
+			// 'a' and '\n' are chars, 'a without the closing quote is a lifetime
+			if n := rustCharLength(rest); n > 0 {
+				return 1, 1, i + n, true
+			}
+			return 0, 0, 0, false
+		}
+	}
+	if r.cppRawStrings && c == 'R' && strings.HasPrefix(rest, `R"`) && cppRawPrefix(code[:i]) {
+		if paren := strings.IndexByte(rest, '('); paren > 0 {
+			delim := ")" + rest[2:paren] + `"`
+			return paren + 1, len(delim), i + paren + 1 + quotedEnd(rest[paren+1:], delim, false, true), true
+		}
+	}
+
+	switch {
+	case strings.IndexByte(r.rawQuotes, c) >= 0:
+		return 1, 1, i + 1 + quotedEnd(rest[1:], string(c), false, true), true
+	case strings.IndexByte(r.multiQuotes, c) >= 0:
+		return 1, 1, i + 1 + quotedEnd(rest[1:], string(c), true, true), true
+	case strings.IndexByte(r.quotes, c) >= 0:
+		return 1, 1, i + 1 + quotedEnd(rest[1:], string(c), true, false), true
+	}
+	return 0, 0, 0, false
+}
+
+// quotedEnd returns the index after the closing delimiter in the text after the opening one,
+// an escaped delimiter doesn't close the literal
+func quotedEnd(text, delim string, escapes, multiline bool) int {
+	for i := 0; i < len(text); i++ {
+		switch {
+		case escapes && text[i] == '\\':
+			i++
+		case !multiline && text[i] == '\n':
+			return i
+		case strings.HasPrefix(text[i:], delim):
+			return i + len(delim)
+		}
+	}
+	return len(text)
+}
+
+// blockCommentEnd returns the index after the block comment the text starts with
+func blockCommentEnd(text string, nested bool) int {
+	depth := 0
+	for i := 0; i < len(text)-1; i++ {
+		switch {
+		case text[i] == '/' && text[i+1] == '*' && (nested || depth == 0):
+			depth++
+			i++
+		case text[i] == '*' && text[i+1] == '/':
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(text)
+}
+
+// rustRawDelimiter returns the closing delimiter and the length of the opening one of r"..." and r#"..."# strings,
+// including byte strings like br"..."
+func rustRawDelimiter(text string) (string, int) {
+	n := 0
+	if strings.HasPrefix(text, "br") {
+		n = 2
+	} else if strings.HasPrefix(text, "r") {
+		n = 1
+	} else {
+		return "", 0
+	}
+	hashes := 0
+	for n+hashes < len(text) && text[n+hashes] == '#' {
+		hashes++
+	}
+	if n+hashes >= len(text) || text[n+hashes] != '"' {
+		return "", 0
+	}
+	return `"` + strings.Repeat("#", hashes), n + hashes + 1
+}
+
+// rustCharLength returns the length of the char literal the text starts with, 0 if it is a lifetime
+func rustCharLength(text string) int {
+	if len(text) < 3 {
+		return 0
+	}
+	if text[1] == '\\' {
+		// The escaped char may be a quote, the closing quote is after it like in '\'' or '\u{1F600}'
+		if len(text) > 3 {
+			if end := strings.IndexByte(text[3:], '\''); end >= 0 && end <= 10 {
+				return end + 4
+			}
+		}
+		return 0
+	}
+	_, size := utf8.DecodeRuneInString(text[1:])
+	if 1+size < len(text) && text[1+size] == '\'' {
+		return size + 2
+	}
+	return 0
+}
+
+// cppRawPrefix checks that R" is a raw string: it is not a part of an identifier except encoding prefixes
+func cppRawPrefix(before string) bool {
+	for _, prefix := range []string{"u8", "u", "U", "L"} {
+		if strings.HasSuffix(before, prefix) && !isIdentByte(before, len(before)-len(prefix)-1) {
+			return true
+		}
+	}
+	return !isIdentByte(before, len(before)-1)
+}
+
+func isIdentByte(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	c := text[i]
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,153 @@
+package analyze
+
+import "testing"
+
+func TestStripCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		language SupportedLanguage
+		code     string
+		want     string
+	}{
+		{
+			name:     "go comment marker in a string",
+			language: LanguageGo,
+			code:     `s := "a // b" // note`,
+			want:     `s := "      "        `,
+		},
+		{
+			name:     "go escaped quotes",
+			language: LanguageGo,
+			code:     `s := "say \"hi\" // no"`,
+			want:     `s := "                "`,
+		},
+		{
+			name:     "go rune of a quote",
+			language: LanguageGo,
+			code:     `c := '"' + "x"`,
+			want:     `c := ' ' + " "`,
+		},
+		{
+			name:     "go backtick string without escapes",
+			language: LanguageGo,
+			code:     "q := `say \"hi\" \\` + x",
+			want:     "q := `          ` + x",
+		},
+		{
+			name:     "go multi-line backtick string",
+			language: LanguageGo,
+			code:     "a := `line1\n// not comment`\nb := 1 // c",
+			want:     "a := `     \n              `\nb := 1     ",
+		},
+		{
+			name:     "go block comment with quotes",
+			language: LanguageGo,
+			code:     `a /* b "c */ d`,
+			want:     `a            d`,
+		},
+		{
+			name:     "python triple-quoted string",
+			language: LanguagePython,
+			code:     "s = \"\"\"it's \"quoted\"\n# not comment\"\"\"  # real",
+			want:     "s = \"\"\"             \n             \"\"\"        ",
+		},
+		{
+			name:     "python nested quotes",
+			language: LanguagePython,
+			code:     `s = "it's" + 'say "hi"'  # c'`,
+			want:     `s = "    " + '        '      `,
+		},
+		{
+			name:     "python hash in a string",
+			language: LanguagePython,
+			code:     `url = "http://x#y"`,
+			want:     `url = "          "`,
+		},
+		{
+			name:     "javascript template literal",
+			language: LanguageJavaScript,
+			code:     "const s = `a ${b} // c`; // d",
+			want:     "const s = `           `;     ",
+		},
+		{
+			name:     "java text block",
+			language: LanguageJava,
+			code:     "String s = \"\"\"\n  \"a\" // b\n  \"\"\";",
+			want:     "String s = \"\"\"\n          \n  \"\"\";",
+		},
+		{
+			name:     "rust nested comments and lifetimes",
+			language: LanguageRust,
+			code:     `fn f<'a>(s: &'a str) /* a /* b */ c */ {}`,
+			want:     `fn f<'a>(s: &'a str)                   {}`,
+		},
+		{
+			name:     "rust raw string",
+			language: LanguageRust,
+			code:     `let s = r#"a "b" c"#;`,
+			want:     `let s = r#"       "#;`,
+		},
+		{
+			name:     "cpp raw string",
+			language: LanguageCpp,
+			code:     `auto s = R"x(a ")" b)x";`,
+			want:     `auto s = R"x(       )x";`,
+		},
+		{
+			name:     "c comments",
+			language: LanguageC,
+			code:     "int a; /* x\ny */ int b; // z",
+			want:     "int a;     \n     int b;     ",
+		},
+		{
+			name:     "unsupported language",
+			language: LanguageUnknown,
+			code:     `x = "a" # b`,
+			want:     `x = "a" # b`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripCode(tt.code, tt.language); got != tt.want {
+				t.Errorf("StripCode() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		language SupportedLanguage
+		code     string
+		want     string
+	}{
+		{
+			name:     "go strings are kept",
+			language: LanguageGo,
+			code:     `q := "SELECT * FROM t -- x" // c`,
+			want:     `q := "SELECT * FROM t -- x"     `,
+		},
+		{
+			name:     "go comment marker in a backtick string",
+			language: LanguageGo,
+			code:     "q := `a // b` /* c */",
+			want:     "q := `a // b`        ",
+		},
+		{
+			name:     "python comment after a triple-quoted string",
+			language: LanguagePython,
+			code:     "q = '''# a''' # b",
+			want:     "q = '''# a'''    ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripComments(tt.code, tt.language); got != tt.want {
+				t.Errorf("StripComments() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
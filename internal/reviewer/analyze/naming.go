@@ -0,0 +1,158 @@
+package analyze
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultInitialisms is a list of common Go initialisms that should have a consistent case
+var DefaultInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS",
+	"ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH",
+	"TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID", "URI", "URL", "UTF8", "VM", "XML",
+	"XMPP", "XSRF", "XSS",
+}
+
+// NamingViolation represents an identifier introduced in the diff that breaks initialism conventions
+type NamingViolation struct {
+	Name       string `json:"name"`       // identifier as written in the diff
+	Suggestion string `json:"suggestion"` // identifier with fixed initialisms
+	Initialism string `json:"initialism"` // first violated initialism
+	Line       string `json:"line"`       // added line where the identifier is declared
+}
+
+var declaredIdentifierPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*func\s*(?:\([^)]*\)\s*)?([A-Za-z_][A-Za-z0-9_]*)\s*[\[(]`),
+	regexp.MustCompile(`^\s*(?:type|var|const)\s+([A-Za-z_][A-Za-z0-9_]*)`),
+	regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*(?:\s*,\s*[A-Za-z_][A-Za-z0-9_]*)*)\s*:=`),
+	regexp.MustCompile(`^\s*([A-Z][A-Za-z0-9_]*)\s+[\[\]*A-Za-z.]+.*` + "`"),
+}
+
+// FindNamingViolations returns identifiers declared on added diff lines that spell initialisms
+// in mixed case (e.g. userId instead of userID). Identifiers that also exist on removed lines
+// are not reported because they are not introduced by the change.
+func FindNamingViolations(diff string, initialisms []string) []NamingViolation {
+	if len(initialisms) == 0 {
+		return nil
+	}
+
+	upper := make(map[string]struct{}, len(initialisms))
+	for _, initialism := range initialisms {
+		upper[strings.ToUpper(initialism)] = struct{}{}
+	}
+
+	added := make(map[string]string)
+	var addedOrder []string
+	removed := make(map[string]struct{})
+
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		isAdded := strings.HasPrefix(line, "+")
+		if !isAdded && !strings.HasPrefix(line, "-") {
+			continue
+		}
+		code := line[1:]
+
+		for _, name := range extractDeclaredIdentifiers(code) {
+			if !isAdded {
+				removed[name] = struct{}{}
+				continue
+			}
+			if _, ok := added[name]; !ok {
+				added[name] = strings.TrimSpace(code)
+				addedOrder = append(addedOrder, name)
+			}
+		}
+	}
+
+	var violations []NamingViolation
+	for _, name := range addedOrder {
+		if _, ok := removed[name]; ok {
+			continue
+		}
+		suggestion, initialism := fixInitialisms(name, upper)
+		if suggestion == name {
+			continue
+		}
+		violations = append(violations, NamingViolation{
+			Name:       name,
+			Suggestion: suggestion,
+			Initialism: initialism,
+			Line:       added[name],
+		})
+	}
+
+	return violations
+}
+
+func extractDeclaredIdentifiers(code string) []string {
+	var names []string
+	for _, pattern := range declaredIdentifierPatterns {
+		match := pattern.FindStringSubmatch(code)
+		if len(match) < 2 {
+			continue
+		}
+		for _, name := range strings.Split(match[1], ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && name != "_" && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// fixInitialisms rewrites mixed-case initialisms in the identifier to the single-case form,
+// returns the fixed identifier and the first initialism that was fixed
+func fixInitialisms(name string, initialisms map[string]struct{}) (string, string) {
+	words := splitCamelCase(name)
+
+	var first string
+	for i, word := range words {
+		if word == strings.ToUpper(word) || word == strings.ToLower(word) {
+			continue // already consistent: URL, url
+		}
+
+		base, suffix := word, ""
+		if _, ok := initialisms[strings.ToUpper(base)]; !ok && strings.HasSuffix(word, "s") {
+			base, suffix = strings.TrimSuffix(word, "s"), "s" // plural form: Ids -> IDs
+		}
+		if _, ok := initialisms[strings.ToUpper(base)]; !ok {
+			continue
+		}
+
+		words[i] = strings.ToUpper(base) + suffix
+		if first == "" {
+			first = strings.ToUpper(base)
+		}
+	}
+
+	return strings.Join(words, ""), first
+}
+
+// splitCamelCase splits identifier into words: HTTPClient -> [HTTP Client], userId -> [user Id]
+func splitCamelCase(name string) []string {
+	runes := []rune(name)
+
+	var (
+		words []string
+		start int
+	)
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		isBoundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) ||
+			cur == '_' || prev == '_'
+		if isBoundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(runes[start:]))
+
+	return words
+}
@@ -0,0 +1,330 @@
+package analyze
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PanicHintType represents the category of a panic hint
+type PanicHintType string
+
+const (
+	PanicHintUncheckedAssertion PanicHintType = "unchecked_type_assertion"
+	PanicHintNilMapWrite        PanicHintType = "nil_map_write"
+	PanicHintUncheckedIndex     PanicHintType = "unchecked_index"
+)
+
+// nolintRegex matches //nolint and //nolint:codry,other comments, a list without codry doesn't suppress hints
+var nolintRegex = regexp.MustCompile(`//\s*nolint\b(?::([\w,-]+))?`)
+
+// splitFuncs are functions returning slices whose elements are indexed without checking the length,
+// the value is the first index that may be out of range: Split returns at least one element
+var splitFuncs = map[string]int{
+	"Split": 1, "SplitN": 1, "SplitAfter": 1, "SplitAfterN": 1,
+	"Fields": 0, "FieldsFunc": 0,
+}
+
+// PanicHint is an added Go expression that may panic at runtime, it is found without type information,
+// so the expression is flagged only in obvious cases
+type PanicHint struct {
+	Type        PanicHintType `json:"type"`        // category of the hint
+	FilePath    string        `json:"file_path"`   // file where it was found
+	Line        int           `json:"line"`        // line in the new file
+	Code        string        `json:"code"`        // line with the expression
+	InLoop      bool          `json:"in_loop"`     // expression is evaluated on every iteration of a loop
+	Description string        `json:"description"` // why the expression may panic
+}
+
+// FindPanicHints returns panic-prone expressions in added lines of the Go file content: type assertions
+// without the comma ok form, writes to maps that are declared but never made and constant indexes of results
+// of strings.Split, strings.Fields and os.Args without a length check in the function. Test files are skipped,
+// lines with //nolint or //nolint:codry comments are suppressed, the file is skipped if it cannot be parsed.
+func FindPanicHints(filePath, content, diff string) []PanicHint {
+	if detectLanguage(filePath) != LanguageGo || content == "" {
+		return nil
+	}
+	parsed, err := parseGoFile(filePath, content)
+	if err != nil {
+		return nil
+	}
+	return findPanicHints(filePath, parsed, diff)
+}
+
+// findPanicHints returns panic hints of the parsed Go file, test files are skipped
+func findPanicHints(filePath string, parsed *parsedFile, diff string) []PanicHint {
+	if strings.HasSuffix(filePath, "_test.go") {
+		return nil
+	}
+	fset, file := parsed.fset, parsed.file
+
+	added := make(map[int]bool)
+	for _, line := range ParseAddedLines(diff) {
+		added[line.Number] = true
+	}
+	suppressed := nolintLines(fset, file)
+	lines := strings.Split(parsed.src, "\n")
+
+	var hints []PanicHint
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		loops := loopBodies(fn.Body)
+		addHint := func(hintType PanicHintType, node ast.Node, description string) {
+			line := fset.Position(node.Pos()).Line
+			if !added[line] || suppressed[line] || line > len(lines) {
+				return
+			}
+			hint := PanicHint{
+				Type:        hintType,
+				FilePath:    filePath,
+				Line:        line,
+				Code:        strings.TrimSpace(lines[line-1]),
+				Description: description,
+			}
+			for _, loop := range loops {
+				if node.Pos() >= loop.Pos() && node.End() <= loop.End() {
+					hint.InLoop = true
+					hint.Description += " It is evaluated on every iteration of the loop."
+					break
+				}
+			}
+			hints = append(hints, hint)
+		}
+
+		findUncheckedAssertions(fn.Body, addHint)
+		findNilMapWrites(fn, addHint)
+		findUncheckedIndexes(fn.Body, addHint)
+	}
+
+	slices.SortStableFunc(hints, func(a, b PanicHint) int { return a.Line - b.Line })
+	return hints
+}
+
+// findUncheckedAssertions reports type assertions that are not in the v, ok := x.(T) form, type switches are safe
+func findUncheckedAssertions(body *ast.BlockStmt, addHint func(PanicHintType, ast.Node, string)) {
+	checked := make(map[*ast.TypeAssertExpr]bool)
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) == 2 && len(n.Rhs) == 1 {
+				if assertion, ok := ast.Unparen(n.Rhs[0]).(*ast.TypeAssertExpr); ok {
+					checked[assertion] = true
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) == 2 && len(n.Values) == 1 {
+				if assertion, ok := ast.Unparen(n.Values[0]).(*ast.TypeAssertExpr); ok {
+					checked[assertion] = true
+				}
+			}
+		case *ast.TypeAssertExpr:
+			if n.Type != nil && !checked[n] {
+				addHint(PanicHintUncheckedAssertion, n, "Type assertion without the comma ok form panics if the value has another type."+
+					" Use v, ok := x.(T) and handle the mismatch, dismiss if the type is guaranteed.")
+			}
+		}
+		return true
+	})
+}
+
+// findNilMapWrites reports writes to maps declared with var or as named results of the function
+// that are never assigned in it, so they are nil when written
+func findNilMapWrites(fn *ast.FuncDecl, addHint func(PanicHintType, ast.Node, string)) {
+	nilMaps := make(map[*ast.Object]string)
+	if fn.Type.Results != nil {
+		for _, field := range fn.Type.Results.List {
+			if _, ok := field.Type.(*ast.MapType); ok {
+				for _, name := range field.Names {
+					if name.Obj != nil {
+						nilMaps[name.Obj] = name.Name
+					}
+				}
+			}
+		}
+	}
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		spec, ok := node.(*ast.ValueSpec)
+		if !ok || len(spec.Values) > 0 {
+			return true
+		}
+		if _, ok := spec.Type.(*ast.MapType); ok {
+			for _, name := range spec.Names {
+				if name.Obj != nil {
+					nilMaps[name.Obj] = name.Name
+				}
+			}
+		}
+		return true
+	})
+	if len(nilMaps) == 0 {
+		return
+	}
+
+	// Any assignment of the map or taking its address may make it, the write is not reported then
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Obj != nil {
+					delete(nilMaps, ident.Obj)
+				}
+			}
+		case *ast.UnaryExpr:
+			if ident, ok := n.X.(*ast.Ident); ok && n.Op == token.AND && ident.Obj != nil {
+				delete(nilMaps, ident.Obj)
+			}
+		}
+		return true
+	})
+
+	report := func(node ast.Node, expr ast.Expr) {
+		index, ok := expr.(*ast.IndexExpr)
+		if !ok {
+			return
+		}
+		ident, ok := index.X.(*ast.Ident)
+		if !ok || ident.Obj == nil {
+			return
+		}
+		if name, ok := nilMaps[ident.Obj]; ok {
+			addHint(PanicHintNilMapWrite, node, "Map "+name+" is declared but never made, writing to a nil map panics."+
+				" Initialize it with make or a composite literal.")
+		}
+	}
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				report(n, lhs)
+			}
+		case *ast.IncDecStmt:
+			report(n, n.X)
+		}
+		return true
+	})
+}
+
+// findUncheckedIndexes reports constant indexes of results of strings and bytes split functions and of os.Args,
+// directly or through a variable, if the function never checks the length of the indexed value
+func findUncheckedIndexes(body *ast.BlockStmt, addHint func(PanicHintType, ast.Node, string)) {
+	lenChecked := make(map[string]bool) // by the expression passed to len
+	splitVars := make(map[*ast.Object]string)
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.CallExpr:
+			if fun, ok := n.Fun.(*ast.Ident); ok && fun.Name == "len" && len(n.Args) == 1 {
+				lenChecked[exprName(n.Args[0])] = true
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) == 1 && len(n.Rhs) == 1 {
+				if ident, ok := n.Lhs[0].(*ast.Ident); ok && ident.Obj != nil {
+					if name, _, ok := splitCall(n.Rhs[0]); ok {
+						splitVars[ident.Obj] = name
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		index, ok := node.(*ast.IndexExpr)
+		if !ok {
+			return true
+		}
+		lit, ok := index.Index.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return true
+		}
+		value, err := strconv.Atoi(lit.Value)
+		if err != nil {
+			return true
+		}
+
+		var source string
+		minIndex := 0
+		switch x := index.X.(type) {
+		case *ast.CallExpr:
+			source, minIndex, ok = splitCall(x)
+		case *ast.Ident:
+			if name, known := splitVars[x.Obj]; known && x.Obj != nil && !lenChecked[x.Name] {
+				source, minIndex, ok = name, splitFuncs[name[strings.LastIndex(name, ".")+1:]], true
+			}
+		case *ast.SelectorExpr:
+			source, minIndex, ok = "os.Args", 1, exprName(x) == "os.Args" && !lenChecked["os.Args"]
+		}
+		if !ok || source == "" || value < minIndex {
+			return true
+		}
+		addHint(PanicHintUncheckedIndex, index, "Index "+lit.Value+" of the result of "+source+
+			" is used without checking its length, the index is out of range for shorter input."+
+			" Check len before indexing or use strings.Cut.")
+		return true
+	})
+}
+
+// splitCall returns the name of the strings or bytes split function of the call and its first unsafe index
+func splitCall(expr ast.Expr) (name string, minIndex int, ok bool) {
+	call, isCall := expr.(*ast.CallExpr)
+	if !isCall {
+		return "", 0, false
+	}
+	name = exprName(call.Fun)
+	pkg, fun, found := strings.Cut(name, ".")
+	if !found || (pkg != "strings" && pkg != "bytes") {
+		return "", 0, false
+	}
+	minIndex, ok = splitFuncs[fun]
+	return name, minIndex, ok
+}
+
+// exprName returns the name of identifier or selector expression like "os.Args", empty for other expressions
+func exprName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		if base := exprName(x.X); base != "" {
+			return base + "." + x.Sel.Name
+		}
+	}
+	return ""
+}
+
+// loopBodies returns bodies of for and range loops of the function, including nested ones
+func loopBodies(body *ast.BlockStmt) []*ast.BlockStmt {
+	var loops []*ast.BlockStmt
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ForStmt:
+			loops = append(loops, n.Body)
+		case *ast.RangeStmt:
+			loops = append(loops, n.Body)
+		}
+		return true
+	})
+	return loops
+}
+
+// nolintLines returns lines with //nolint comments that suppress hints
+func nolintLines(fset *token.FileSet, file *ast.File) map[int]bool {
+	lines := make(map[int]bool)
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			match := nolintRegex.FindStringSubmatch(comment.Text)
+			if match == nil {
+				continue
+			}
+			if match[1] == "" || slices.Contains(strings.Split(match[1], ","), "codry") {
+				lines[fset.Position(comment.Pos()).Line] = true
+			}
+		}
+	}
+	return lines
+}
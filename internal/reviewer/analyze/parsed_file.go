@@ -0,0 +1,67 @@
+package analyze
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+// parsedFile is a Go source parsed once and shared by analyzers of the file, the analyzers must not modify the tree
+type parsedFile struct {
+	src  string
+	fset *token.FileSet
+	file *ast.File
+}
+
+// parseGoFile parses the Go source with comments and resolved identifiers, so the tree fits every analyzer
+func parseGoFile(filePath, src string) (*parsedFile, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return &parsedFile{src: src, fset: fset, file: file}, nil
+}
+
+// goFileVersions are versions of the reviewed Go file parsed once for the semantic analysis and the context
+// of the review; a version is nil if the file has no such version or it failed to be fetched or parsed
+type goFileVersions struct {
+	before, after       *parsedFile
+	beforeErr, afterErr error
+	// beforeFetched and afterFetched are true if content of the version was fetched, even if it is not parsed
+	beforeFetched, afterFetched bool
+}
+
+// parseGoVersions parses the old version of the file at the target branch and the new one at the head commit
+func parseGoVersions(ctx context.Context, provider interfaces.CodeProvider, request model.ReviewRequest, fileDiff *model.FileDiff) *goFileVersions {
+	versions := &goFileVersions{}
+	if !fileDiff.IsDeleted {
+		versions.after, versions.afterFetched, versions.afterErr = fetchGoFile(ctx, provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	}
+	if !fileDiff.IsNew {
+		versions.before, versions.beforeFetched, versions.beforeErr = fetchGoFile(ctx, provider, request.ProjectID, fileDiff.OldPath, request.MergeRequest.TargetBranch)
+	}
+	return versions
+}
+
+// fetchGoFile returns the parsed file and true if its content was fetched, the error may be of parsing
+func fetchGoFile(ctx context.Context, provider interfaces.CodeProvider, projectID, filePath, ref string) (*parsedFile, bool, error) {
+	content, err := FetchFileContent(ctx, provider, projectID, filePath, ref)
+	if err != nil {
+		return nil, false, err
+	}
+	parsed, err := parseGoFile(filePath, content)
+	return parsed, true, err
+}
+
+// hasContent checks if the head version of the file, or the old one of a deleted file, was fetched
+func (v *goFileVersions) hasContent(fileDiff *model.FileDiff) bool {
+	if fileDiff.IsDeleted {
+		return v.beforeFetched
+	}
+	return v.afterFetched
+}
@@ -0,0 +1,104 @@
+package analyze
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const parsedFileGoVersion = "1.21"
+
+// goFixtureVersions returns parsed versions of Go fixtures by name, both versions of every fixture are parsed
+func goFixtureVersions(tb testing.TB) map[string]*goFileVersions {
+	tb.Helper()
+	versions := make(map[string]*goFileVersions)
+	for _, dir := range findFixtures(tb) {
+		name, _ := filepath.Rel(fixturesDir, dir)
+		if !strings.HasPrefix(name, "go"+string(filepath.Separator)) {
+			continue
+		}
+		f := loadFixture(tb, dir)
+		files := parseGoVersions(context.Background(), f.semantic.provider, f.request, f.fileDiff)
+		if files.before == nil || files.after == nil {
+			tb.Fatalf("fixture %s is not parsed: %v, %v", name, files.beforeErr, files.afterErr)
+		}
+		versions[name] = files
+	}
+	return versions
+}
+
+func TestParsedFileSharedByAnalyzers(t *testing.T) {
+	ga := NewGoAnalyzer()
+	for name, files := range goFixtureVersions(t) {
+		t.Run(name, func(t *testing.T) {
+			before, after := files.before, files.after
+			diff := addedDiff(after.src)
+
+			// The tree parsed once gives every analyzer the same result as its own parsing
+			for _, version := range []*parsedFile{before, after} {
+				want, err := ga.ParseEntities(version.src)
+				if err != nil {
+					t.Fatalf("ParseEntities() error = %v", err)
+				}
+				if got := goEntities(version); !reflect.DeepEqual(got, want) {
+					t.Errorf("goEntities() =\n%+v\nwant\n%+v", got, want)
+				}
+			}
+			wantConstants, err := FindGoConstantChanges(before.src, after.src)
+			if err != nil {
+				t.Fatalf("FindGoConstantChanges() error = %v", err)
+			}
+			if got := findGoConstantChanges(before, after); !reflect.DeepEqual(got, wantConstants) {
+				t.Errorf("findGoConstantChanges() = %+v, want %+v", got, wantConstants)
+			}
+			wantPanics := FindPanicHints("main.go", after.src, diff)
+			if got := findPanicHints("main.go", after, diff); !reflect.DeepEqual(got, wantPanics) {
+				t.Errorf("findPanicHints() = %+v, want %+v", got, wantPanics)
+			}
+			wantHints := FindConcurrencyHints("main.go", after.src, diff, parsedFileGoVersion)
+			if got := findConcurrencyHints("main.go", after, diff, parsedFileGoVersion); !reflect.DeepEqual(got, wantHints) {
+				t.Errorf("findConcurrencyHints() = %+v, want %+v", got, wantHints)
+			}
+		})
+	}
+}
+
+// BenchmarkGoFileAnalyzers runs Go analyzers of a file version pair parsing the sources in every analyzer
+// and parsing them once for all analyzers; parses/op is the number of parsed sources
+func BenchmarkGoFileAnalyzers(b *testing.B) {
+	ga := NewGoAnalyzer()
+	versions := goFixtureVersions(b)
+	for _, name := range slices.Sorted(maps.Keys(versions)) {
+		before, after := versions[name].before.src, versions[name].after.src
+		diff := addedDiff(after)
+
+		b.Run(name+"/parse per analyzer", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				_, _ = ga.ParseEntities(before)
+				_, _ = ga.ParseEntities(after)
+				_, _ = FindGoConstantChanges(before, after)
+				_ = FindPanicHints("main.go", after, diff)
+				_ = FindConcurrencyHints("main.go", after, diff, parsedFileGoVersion)
+			}
+			b.ReportMetric(6, "parses/op")
+		})
+		b.Run(name+"/parse once", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				oldFile, _ := parseGoFile("main.go", before)
+				newFile, _ := parseGoFile("main.go", after)
+				_ = goEntities(oldFile)
+				_ = goEntities(newFile)
+				_ = findGoConstantChanges(oldFile, newFile)
+				_ = findPanicHints("main.go", newFile, diff)
+				_ = findConcurrencyHints("main.go", newFile, diff, parsedFileGoVersion)
+			}
+			b.ReportMetric(2, "parses/op")
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package analyze
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// PerformanceFindingType represents the category of a deterministic performance finding
+type PerformanceFindingType string
+
+const (
+	PerformanceFindingCallInLoop  PerformanceFindingType = "call_in_loop"
+	PerformanceFindingAwaitInLoop PerformanceFindingType = "await_in_loop"
+)
+
+// PerformanceFinding is a network or database call inside a loop body found in changed code,
+// it is a potential N+1 query that should be batched or moved out of the loop
+type PerformanceFinding struct {
+	Type        PerformanceFindingType `json:"type"`        // category of the finding
+	FilePath    string                 `json:"file_path"`   // file where it was found
+	LoopLine    int                    `json:"loop_line"`   // line of the loop header in the new file
+	Line        int                    `json:"line"`        // line of the call in the new file
+	Call        string                 `json:"call"`        // called expression, e.g. client.Get
+	Code        string                 `json:"code"`        // line with the call
+	Description string                 `json:"description"` // human readable explanation
+}
+
+// hunkLine is a line of the new file inside a diff hunk
+type hunkLine struct {
+	Number  int
+	Content string
+	Added   bool
+}
+
+// loopFrame is a loop which body is being scanned
+type loopFrame struct {
+	line      int  // loop header line
+	added     bool // loop header is added in the diff
+	depth     int  // bracket depth before the header (indent for Python)
+	statement bool // for/while loop, false for iteration callbacks like forEach
+	single    bool // loop body is a single statement without braces
+}
+
+var (
+	goLoopRegex        = regexp.MustCompile(`^\s*(?:[A-Za-z_]\w*:\s*)?for\b`)
+	cLoopRegex         = regexp.MustCompile(`^\s*(?:[A-Za-z_$][\w$]*:\s*)?(?:for(?:\s+await)?|while)\s*\(|^\s*do\s*\{`)
+	jsIterationRegex   = regexp.MustCompile(`\.(?:forEach|map|flatMap|filter|reduce|some|every)\s*\(`)
+	pythonLoopRegex    = regexp.MustCompile(`^\s*(?:async\s+)?(?:for|while)\b.*:\s*(?:#.*)?$`)
+	pythonComprehRegex = regexp.MustCompile(`[\[({].*\bfor\b.+\bin\b`)
+
+	methodCallRegex = regexp.MustCompile(`([A-Za-z_$][\w$]*)\s*\.\s*([A-Za-z_$][\w$]*)\s*\(`)
+	fetchCallRegex  = regexp.MustCompile(`(?:^|[^\w$.])(fetch|axios)\s*\(`)
+	awaitRegex      = regexp.MustCompile(`(?:^|[^\w$.])await\b`)
+)
+
+// callMethodPrefixes are lowercase prefixes of client methods that usually make a network or database round trip
+var callMethodPrefixes = []string{
+	"get", "post", "put", "patch", "delete", "do", "query", "exec", "find", "fetch",
+	"request", "send", "insert", "update", "upsert", "select", "count", "load", "save", "create", "remove",
+}
+
+// callReceiverWords are lowercase words of receiver names that look like network or database clients
+var callReceiverWords = map[string]struct{}{
+	"client": {}, "db": {}, "conn": {}, "tx": {}, "session": {}, "repo": {}, "repository": {},
+	"store": {}, "storage": {}, "dao": {}, "api": {}, "http": {}, "requests": {}, "axios": {},
+	"provider": {}, "collection": {}, "rdb": {}, "redis": {}, "stub": {}, "grpc": {}, "sql": {}, "pool": {},
+}
+
+// FindLoopCalls returns network and database calls inside loop bodies in changed code of the diff:
+// Go for loops, JS/TS/Java/C for/while loops and iteration callbacks, Python loops and comprehensions.
+// A call is reported if the call or the loop header is added in the diff, loops that start
+// outside of the hunk are not detected.
+func FindLoopCalls(filePath, diff string) []PerformanceFinding {
+	language := detectLanguage(filePath)
+
+	var findings []PerformanceFinding
+	for _, hunk := range parseHunks(diff) {
+		switch language {
+		case LanguagePython:
+			findings = append(findings, findPythonLoopCalls(filePath, hunk)...)
+		case LanguageGo, LanguageJavaScript, LanguageTypeScript, LanguageJava, LanguageCpp, LanguageC:
+			findings = append(findings, findBracedLoopCalls(filePath, language, hunk)...)
+		}
+	}
+
+	return findings
+}
+
+// findBracedLoopCalls scans languages with bracket delimited blocks
+func findBracedLoopCalls(filePath string, language SupportedLanguage, hunk []hunkLine) []PerformanceFinding {
+	var (
+		findings []PerformanceFinding
+		stack    []loopFrame
+		depth    int
+	)
+	for _, line := range hunk {
+		code := stripCodeLine(line.Content, language)
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+
+		if header, rest, ok := matchLoopHeader(code, language); ok {
+			frame := loopFrame{line: line.Number, added: line.Added, depth: depth, statement: header}
+			if finding, ok := checkLoopCall(filePath, language, line, rest, frame); ok {
+				findings = append(findings, finding)
+			} else if len(stack) > 0 {
+				// header of the nested loop may call client to get items: for _, x := range client.List()
+				if finding, ok := checkLoopCall(filePath, language, line, code, stack[len(stack)-1]); ok {
+					findings = append(findings, finding)
+				}
+			}
+			depth += bracketDelta(code)
+			frame.single = depth <= frame.depth && !strings.Contains(code, "{")
+			if depth > frame.depth || frame.single {
+				stack = append(stack, frame)
+			}
+			continue
+		}
+
+		if len(stack) > 0 {
+			if finding, ok := checkLoopCall(filePath, language, line, code, stack[len(stack)-1]); ok {
+				findings = append(findings, finding)
+			}
+		}
+
+		depth += bracketDelta(code)
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+			if depth > top.depth {
+				break
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return findings
+}
+
+// matchLoopHeader checks if the line starts a loop, returns true for statement loops and
+// the part of the line that belongs to the loop body
+func matchLoopHeader(code string, language SupportedLanguage) (bool, string, bool) {
+	switch language {
+	case LanguageGo:
+		if goLoopRegex.MatchString(code) {
+			_, body, _ := strings.Cut(code, "{")
+			return true, body, true
+		}
+		return false, "", false
+	case LanguageJavaScript, LanguageTypeScript:
+		if loc := jsIterationRegex.FindStringIndex(code); loc != nil && !cLoopRegex.MatchString(code) {
+			return false, code[loc[1]:], true
+		}
+	}
+	if cLoopRegex.MatchString(code) {
+		return true, loopBodyPart(code), true
+	}
+	return false, "", false
+}
+
+// loopBodyPart returns the part of the loop header line after the condition: while (x) fetch(url)
+func loopBodyPart(code string) string {
+	if _, body, found := strings.Cut(code, "{"); found {
+		return body
+	}
+	start := strings.Index(code, "(")
+	if start < 0 {
+		return ""
+	}
+	depth := 0
+	for i := start; i < len(code); i++ {
+		switch code[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return code[i+1:]
+			}
+		}
+	}
+	return ""
+}
+
+// findPythonLoopCalls scans Python code where blocks are delimited by indentation
+func findPythonLoopCalls(filePath string, hunk []hunkLine) []PerformanceFinding {
+	var (
+		findings []PerformanceFinding
+		stack    []loopFrame
+	)
+	for _, line := range hunk {
+		code := stripCodeLine(line.Content, LanguagePython)
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+
+		indent := indentWidth(code)
+		for len(stack) > 0 && indent <= stack[len(stack)-1].depth {
+			stack = stack[:len(stack)-1]
+		}
+
+		switch {
+		case pythonLoopRegex.MatchString(line.Content):
+			stack = append(stack, loopFrame{line: line.Number, added: line.Added, depth: indent, statement: true})
+		case pythonComprehRegex.MatchString(code):
+			frame := loopFrame{line: line.Number, added: line.Added, depth: indent}
+			if finding, ok := checkLoopCall(filePath, LanguagePython, line, code, frame); ok {
+				findings = append(findings, finding)
+			}
+		case len(stack) > 0:
+			if finding, ok := checkLoopCall(filePath, LanguagePython, line, code, stack[len(stack)-1]); ok {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	return findings
+}
+
+// checkLoopCall returns finding if the code inside the loop body makes a network or database call
+func checkLoopCall(filePath string, language SupportedLanguage, line hunkLine, code string, frame loopFrame) (PerformanceFinding, bool) {
+	if !line.Added && !frame.added {
+		return PerformanceFinding{}, false
+	}
+
+	finding := PerformanceFinding{
+		FilePath: filePath,
+		LoopLine: frame.line,
+		Line:     line.Number,
+		Code:     strings.TrimSpace(line.Content),
+	}
+
+	if call, ok := detectClientCall(code); ok {
+		finding.Type = PerformanceFindingCallInLoop
+		finding.Call = call
+		finding.Description = fmt.Sprintf("%s is called inside the loop at line %d, it makes a round trip per iteration (N+1), "+
+			"fetch data in batch before the loop", call, frame.line)
+		return finding, true
+	}
+
+	isJS := language == LanguageJavaScript || language == LanguageTypeScript
+	if isJS && frame.statement && awaitRegex.MatchString(code) {
+		finding.Type = PerformanceFindingAwaitInLoop
+		finding.Call = "await"
+		finding.Description = fmt.Sprintf("await inside the loop at line %d runs iterations sequentially, "+
+			"start operations together and use Promise.all if they are independent", frame.line)
+		return finding, true
+	}
+
+	return PerformanceFinding{}, false
+}
+
+// detectClientCall returns called expression if the code calls a network or database client method
+func detectClientCall(code string) (string, bool) {
+	if match := fetchCallRegex.FindStringSubmatch(code); match != nil {
+		return match[1], true
+	}
+
+	for _, match := range methodCallRegex.FindAllStringSubmatch(code, -1) {
+		receiver, method := match[1], strings.ToLower(match[2])
+		if !isClientReceiver(receiver) {
+			continue
+		}
+		for _, prefix := range callMethodPrefixes {
+			if strings.HasPrefix(method, prefix) {
+				return receiver + "." + match[2], true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// isClientReceiver checks if any word of the receiver name looks like a client: httpClient, userRepo, db
+func isClientReceiver(receiver string) bool {
+	for _, word := range splitCamelCase(strings.Trim(receiver, "_$")) {
+		if _, ok := callReceiverWords[strings.ToLower(strings.Trim(word, "_"))]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stripCodeLine masks string literals and removes comments of the line,
+// so brackets and calls inside them are not counted
+func stripCodeLine(line string, language SupportedLanguage) string {
+	return StripCode(line, language)
+}
+
+// bracketDelta returns the change of bracket depth after the line
+func bracketDelta(code string) int {
+	delta := 0
+	for _, r := range code {
+		switch r {
+		case '{', '(', '[':
+			delta++
+		case '}', ')', ']':
+			delta--
+		}
+	}
+	return delta
+}
+
+// indentWidth returns the width of leading whitespace, tab is counted as 4 spaces
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// parseHunks returns lines of the new file for every hunk of the unified diff
+func parseHunks(diff string) [][]hunkLine {
+	var hunks [][]hunkLine
+	for _, hunk := range model.ParseHunks(diff) {
+		var current []hunkLine
+		for _, line := range hunk.Lines {
+			if line.Kind == model.DiffLineRemoved {
+				continue // removed line does not exist in the new file
+			}
+			current = append(current, hunkLine{Number: line.NewLine, Content: line.Content, Added: line.Kind == model.DiffLineAdded})
+		}
+		if len(current) > 0 {
+			hunks = append(hunks, current)
+		}
+	}
+	return hunks
+}
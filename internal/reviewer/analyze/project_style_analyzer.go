@@ -3,30 +3,44 @@ package analyze
 import (
 	"context"
 	"fmt"
+	"maps"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/maxbolgarin/codry/internal/model"
 	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
 	"github.com/maxbolgarin/logze/v2"
 	"gopkg.in/yaml.v3"
 )
 
 // ProjectStyleAnalyzer analyzes project-specific patterns and conventions
 type ProjectStyleAnalyzer struct {
-	provider interfaces.CodeProvider
-	log      logze.Logger
+	provider    interfaces.CodeProvider
+	initialisms []string
+	log         logze.Logger
+
+	// search caches go.mod and linter configs of sub-projects found for changed files
+	search revisionCache
 }
 
 // NewProjectStyleAnalyzer creates a new project style analyzer
 func NewProjectStyleAnalyzer(provider interfaces.CodeProvider) *ProjectStyleAnalyzer {
 	return &ProjectStyleAnalyzer{
-		provider: provider,
-		log:      logze.With("component", "project-style-analyzer"),
+		provider:    provider,
+		initialisms: DefaultInitialisms,
+		log:         logze.With("component", "project-style-analyzer"),
 	}
 }
 
+// Initialisms returns the list of initialisms used for naming checks
+func (psa *ProjectStyleAnalyzer) Initialisms() []string {
+	return psa.initialisms
+}
+
 // ProjectStyleInfo contains comprehensive project style information
 type ProjectStyleInfo struct {
 	LinterConfig       LinterConfig       `json:"linter_config"`
@@ -42,6 +56,7 @@ type ProjectStyleInfo struct {
 // LinterConfig represents linter configuration and rules
 type LinterConfig struct {
 	Tool            string                `json:"tool"`             // golangci-lint, staticcheck, etc.
+	Path            string                `json:"path"`             // path of the config nearest to the file
 	EnabledLinters  []string              `json:"enabled_linters"`  // list of enabled linters
 	DisabledLinters []string              `json:"disabled_linters"` // list of disabled linters
 	Rules           map[string]LinterRule `json:"rules"`            // specific rule configurations
@@ -63,6 +78,7 @@ type ComplexityLimits struct {
 
 // DependencyInfo represents project dependencies and their implications
 type DependencyInfo struct {
+	ModuleRoot      string                 `json:"module_root"` // directory of go.mod nearest to the file
 	GoVersion       string                 `json:"go_version"`
 	Dependencies    []Dependency           `json:"dependencies"`
 	TestDeps        []Dependency           `json:"test_dependencies"`
@@ -248,13 +264,13 @@ type OptimizationHint struct {
 
 // AnalyzeProjectStyle performs comprehensive project style analysis
 func (psa *ProjectStyleAnalyzer) AnalyzeProjectStyle(ctx context.Context, request model.ReviewRequest, filePath string) (*ProjectStyleInfo, error) {
-	log := psa.log.WithFields("project", request.ProjectID, "file", filePath)
+	log := model.ContextLogger(ctx, psa.log).WithFields("project", request.ProjectID, "file", filePath)
 	log.Debug("starting project style analysis")
 
 	style := &ProjectStyleInfo{}
 
 	// Analyze linter configuration
-	linterConfig, err := psa.analyzeLinterConfig(ctx, request)
+	linterConfig, err := psa.AnalyzeLinterConfig(ctx, request, filePath)
 	if err != nil {
 		log.Warn("failed to analyze linter config", "error", err)
 	} else {
@@ -262,7 +278,7 @@ func (psa *ProjectStyleAnalyzer) AnalyzeProjectStyle(ctx context.Context, reques
 	}
 
 	// Analyze dependencies
-	dependencies, err := psa.analyzeDependencies(ctx, request)
+	dependencies, err := psa.analyzeDependencies(ctx, request, filePath)
 	if err != nil {
 		log.Warn("failed to analyze dependencies", "error", err)
 	} else {
@@ -321,12 +337,13 @@ func (psa *ProjectStyleAnalyzer) AnalyzeProjectStyle(ctx context.Context, reques
 	return style, nil
 }
 
-// analyzeLinterConfig analyzes the project's linter configuration
-func (psa *ProjectStyleAnalyzer) analyzeLinterConfig(ctx context.Context, request model.ReviewRequest) (LinterConfig, error) {
+// AnalyzeLinterConfig analyzes golangci-lint configuration of the target branch nearest to the file,
+// so files of a sub-project get its own config
+func (psa *ProjectStyleAnalyzer) AnalyzeLinterConfig(ctx context.Context, request model.ReviewRequest, filePath string) (LinterConfig, error) {
 	config := LinterConfig{}
 
 	// Try to get .golangci.yml or .golangci.yaml
-	content, err := psa.getLinterConfigContent(ctx, request)
+	configPath, content, err := psa.findNearestFile(ctx, request, filePath, linterConfigFiles)
 	if err != nil {
 		return config, fmt.Errorf("failed to get linter config: %w", err)
 	}
@@ -363,6 +380,7 @@ func (psa *ProjectStyleAnalyzer) analyzeLinterConfig(ctx context.Context, reques
 	}
 
 	config.Tool = "golangci-lint"
+	config.Path = configPath
 	config.EnabledLinters = golangciConfig.Linters.Enable
 	config.DisabledLinters = golangciConfig.Linters.Disable
 
@@ -383,90 +401,61 @@ func (psa *ProjectStyleAnalyzer) analyzeLinterConfig(ctx context.Context, reques
 	return config, nil
 }
 
-// getLinterConfigContent tries to get linter configuration content
-func (psa *ProjectStyleAnalyzer) getLinterConfigContent(ctx context.Context, request model.ReviewRequest) (string, error) {
-	configFiles := []string{".golangci.yml", ".golangci.yaml", ".golangci-lint.yml", ".golangci-lint.yaml"}
+// GoVersion returns the go directive of go.mod of the module of the file, e.g. 1.21 or 1.21.3,
+// it is empty if go.mod has no directive
+func (psa *ProjectStyleAnalyzer) GoVersion(ctx context.Context, request model.ReviewRequest, filePath string) (string, error) {
+	_, content, err := psa.findNearestFile(ctx, request, filePath, goModFiles)
+	if err != nil {
+		return "", errm.Wrap(err, "failed to get go.mod")
+	}
+	return parseGoDirective(content), nil
+}
 
-	for _, configFile := range configFiles {
-		content, err := psa.provider.GetFileContent(ctx, request.ProjectID, configFile, request.MergeRequest.TargetBranch)
-		if err == nil {
-			return content, nil
+// parseGoDirective returns the version of the go directive of go.mod content, empty if it is not found
+func parseGoDirective(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "go ") {
+			return strings.TrimPrefix(line, "go ")
 		}
 	}
-
-	return "", fmt.Errorf("no linter config found")
+	return ""
 }
 
-// analyzeDependencies analyzes go.mod and extracts dependency information
-func (psa *ProjectStyleAnalyzer) analyzeDependencies(ctx context.Context, request model.ReviewRequest) (DependencyInfo, error) {
+// analyzeDependencies analyzes go.mod of the module of the file and extracts dependency information
+func (psa *ProjectStyleAnalyzer) analyzeDependencies(ctx context.Context, request model.ReviewRequest, filePath string) (DependencyInfo, error) {
 	deps := DependencyInfo{
 		CommonLibraries: make(map[string]LibraryInfo),
 	}
 
 	// Get go.mod content
-	content, err := psa.provider.GetFileContent(ctx, request.ProjectID, "go.mod", request.MergeRequest.TargetBranch)
+	goModPath, content, err := psa.findNearestFile(ctx, request, filePath, goModFiles)
 	if err != nil {
 		return deps, fmt.Errorf("failed to get go.mod: %w", err)
 	}
+	deps.ModuleRoot = path.Dir(goModPath)
 
-	// Parse go.mod for dependencies
-	lines := strings.Split(content, "\n")
-	inRequireBlock := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Extract Go version
-		if strings.HasPrefix(line, "go ") {
-			deps.GoVersion = strings.TrimPrefix(line, "go ")
-			continue
-		}
-
-		// Handle require block
-		if strings.HasPrefix(line, "require (") {
-			inRequireBlock = true
-			continue
-		}
-		if inRequireBlock && line == ")" {
-			inRequireBlock = false
-			continue
-		}
-
-		// Parse dependency lines
-		if strings.HasPrefix(line, "require ") || inRequireBlock {
-			dep := psa.parseDependencyLine(line)
-			if dep.Name != "" {
-				deps.Dependencies = append(deps.Dependencies, dep)
+	// Extract Go version
+	deps.GoVersion = parseGoDirective(content)
 
-				// Identify common libraries and their purposes
-				if libraryInfo := psa.identifyLibraryType(dep.Name); libraryInfo.Purpose != "" {
-					deps.CommonLibraries[dep.Name] = libraryInfo
-				}
-			}
+	// Parse go.mod for dependencies
+	for _, require := range ParseGoModRequires(content) {
+		deps.Dependencies = append(deps.Dependencies, Dependency{
+			Name:    require.Path,
+			Package: require.Path,
+		})
+
+		// Identify common libraries and their purposes
+		if libraryInfo := identifyLibraryType(require.Path); libraryInfo.Purpose != "" {
+			deps.CommonLibraries[require.Path] = libraryInfo
 		}
 	}
 
 	return deps, nil
 }
 
-// parseDependencyLine parses a single dependency line from go.mod
-func (psa *ProjectStyleAnalyzer) parseDependencyLine(line string) Dependency {
-	line = strings.TrimSpace(line)
-	line = strings.TrimPrefix(line, "require ")
-
-	parts := strings.Fields(line)
-	if len(parts) >= 2 {
-		return Dependency{
-			Name:    parts[0],
-			Package: parts[0],
-		}
-	}
-
-	return Dependency{}
-}
-
 // identifyLibraryType identifies the type and purpose of a library
-func (psa *ProjectStyleAnalyzer) identifyLibraryType(name string) LibraryInfo {
+func identifyLibraryType(name string) LibraryInfo {
 	nameLower := strings.ToLower(name)
 
 	// Common library patterns
@@ -562,8 +551,12 @@ func (psa *ProjectStyleAnalyzer) getPackageFiles(ctx context.Context, request mo
 	}
 
 	for _, filename := range commonFiles {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		fullPath := filepath.Join(packageDir, filename)
-		content, err := psa.provider.GetFileContent(ctx, request.ProjectID, fullPath, request.MergeRequest.TargetBranch)
+		content, err := FetchFileContent(ctx, psa.provider, request.ProjectID, fullPath, request.MergeRequest.TargetBranch)
 		if err == nil {
 			files[filename] = content
 		}
@@ -580,6 +573,7 @@ func (psa *ProjectStyleAnalyzer) analyzeNamingStyle(packageFiles map[string]stri
 		ConstantNaming:  "PascalCase",
 		TypeNaming:      "PascalCase",
 		InterfaceNaming: "er_suffix",
+		Abbreviations:   psa.initialisms,
 	}
 
 	// Analyze function names
@@ -587,7 +581,7 @@ func (psa *ProjectStyleAnalyzer) analyzeNamingStyle(packageFiles map[string]stri
 	typeRegex := regexp.MustCompile(`type\s+([A-Za-z_][A-Za-z0-9_]*)\s+`)
 	constRegex := regexp.MustCompile(`const\s+([A-Za-z_][A-Za-z0-9_]*)\s*=`)
 
-	for _, content := range packageFiles {
+	for _, content := range sortedFileContents(packageFiles) {
 		// Analyze function naming patterns
 		funcMatches := functionRegex.FindAllStringSubmatch(content, -1)
 		for _, match := range funcMatches {
@@ -625,6 +619,16 @@ func (psa *ProjectStyleAnalyzer) analyzeNamingStyle(packageFiles map[string]stri
 	return style
 }
 
+// sortedFileContents returns file contents ordered by file path,
+// so that conventions detected from several files don't depend on map iteration order
+func sortedFileContents(packageFiles map[string]string) []string {
+	contents := make([]string, 0, len(packageFiles))
+	for _, path := range slices.Sorted(maps.Keys(packageFiles)) {
+		contents = append(contents, packageFiles[path])
+	}
+	return contents
+}
+
 // analyzeStructureStyle analyzes code structure conventions
 func (psa *ProjectStyleAnalyzer) analyzeStructureStyle(packageFiles map[string]string) StructureStyle {
 	return StructureStyle{
@@ -640,14 +644,14 @@ func (psa *ProjectStyleAnalyzer) analyzeStructureStyle(packageFiles map[string]s
 // analyzeCommentingStyle analyzes commenting conventions
 func (psa *ProjectStyleAnalyzer) analyzeCommentingStyle(packageFiles map[string]string) CommentingStyle {
 	style := CommentingStyle{
-		DocCommentStyle: "godoc",
+		DocCommentStyle: detectDocCommentStyle(packageFiles),
 		InlineComments:  "encouraged",
 		TODOStyle:       "TODO:",
 		CommentLength:   80,
 	}
 
 	// Look for documentation comment patterns
-	for _, content := range packageFiles {
+	for _, content := range sortedFileContents(packageFiles) {
 		if strings.Contains(content, "// TODO:") {
 			style.TODOStyle = "TODO:"
 		} else if strings.Contains(content, "// FIXME:") {
@@ -658,6 +662,55 @@ func (psa *ProjectStyleAnalyzer) analyzeCommentingStyle(packageFiles map[string]
 	return style
 }
 
+// AnalyzeCommentingStyle analyzes commenting conventions of the package of the file in the target branch
+func (psa *ProjectStyleAnalyzer) AnalyzeCommentingStyle(ctx context.Context, request model.ReviewRequest, filePath string) (CommentingStyle, error) {
+	packageFiles, err := psa.getPackageFiles(ctx, request, filepath.Dir(filePath))
+	if err != nil {
+		return CommentingStyle{}, fmt.Errorf("failed to get package files: %w", err)
+	}
+	return psa.analyzeCommentingStyle(packageFiles), nil
+}
+
+// detectDocCommentStyle checks doc comments of exported functions, methods and types of Go files:
+// godoc if most of them start with the symbol name, none if most symbols are undocumented, standard otherwise;
+// godoc is returned if there are not enough symbols to decide
+func detectDocCommentStyle(packageFiles map[string]string) string {
+	const minSymbols = 5
+
+	analyzer := NewGoAnalyzer()
+	var total, documented, godoc int
+	for _, content := range sortedFileContents(packageFiles) {
+		entities, err := analyzer.ParseEntities(content)
+		if err != nil {
+			continue
+		}
+		for _, entity := range entities {
+			if !entity.IsExported || entity.Type == EntityTypeConst || entity.Type == EntityTypeVar {
+				continue
+			}
+			total++
+			if entity.DocComment == "" {
+				continue
+			}
+			documented++
+			if isGodocComment(entity.DocComment, entity.Name) {
+				godoc++
+			}
+		}
+	}
+
+	switch {
+	case total < minSymbols:
+		return DocCommentStyleGodoc
+	case documented*2 < total:
+		return DocCommentStyleNone
+	case godoc*2 < documented:
+		return DocCommentStyleStandard
+	default:
+		return DocCommentStyleGodoc
+	}
+}
+
 // analyzeImportStyle analyzes import conventions
 func (psa *ProjectStyleAnalyzer) analyzeImportStyle(packageFiles map[string]string) ImportStyle {
 	style := ImportStyle{
@@ -668,7 +721,7 @@ func (psa *ProjectStyleAnalyzer) analyzeImportStyle(packageFiles map[string]stri
 	// Analyze import patterns
 	importRegex := regexp.MustCompile(`import\s+(?:([a-zA-Z_][a-zA-Z0-9_]*)\s+)?"([^"]+)"`)
 
-	for _, content := range packageFiles {
+	for _, content := range sortedFileContents(packageFiles) {
 		matches := importRegex.FindAllStringSubmatch(content, -1)
 		for _, match := range matches {
 			if len(match) >= 3 && match[1] != "" {
@@ -693,7 +746,7 @@ func (psa *ProjectStyleAnalyzer) analyzeErrorStyle(packageFiles map[string]strin
 	}
 
 	// Analyze error patterns
-	for _, content := range packageFiles {
+	for _, content := range sortedFileContents(packageFiles) {
 		if strings.Contains(content, "errors.Wrap") {
 			style.ErrorWrapping = "pkg/errors"
 		} else if strings.Contains(content, "fmt.Errorf") {
@@ -738,7 +791,11 @@ func (psa *ProjectStyleAnalyzer) analyzeTestingConventions(ctx context.Context,
 	testFiles := []string{"example_test.go", "main_test.go", "config_test.go"}
 
 	for _, testFile := range testFiles {
-		content, err := psa.provider.GetFileContent(ctx, request.ProjectID, testFile, request.MergeRequest.TargetBranch)
+		if err := ctx.Err(); err != nil {
+			return conventions, err
+		}
+
+		content, err := FetchFileContent(ctx, psa.provider, request.ProjectID, testFile, request.MergeRequest.TargetBranch)
 		if err == nil {
 			if strings.Contains(content, "testify") {
 				conventions.TestFramework = "testify"
@@ -0,0 +1,96 @@
+package analyze
+
+import (
+	"regexp"
+	"strings"
+)
+
+var pythonDefRegex = regexp.MustCompile(`^\s*(async\s+def|def|class)\s+(\w+)`)
+
+// PythonAnalyzer extracts top-level functions, classes and their methods from Python sources,
+// bodies are detected by indentation
+type PythonAnalyzer struct{}
+
+// NewPythonAnalyzer creates a new Python analyzer
+func NewPythonAnalyzer() *PythonAnalyzer {
+	return &PythonAnalyzer{}
+}
+
+// pythonBlock is a def or class whose body is not closed yet
+type pythonBlock struct {
+	entity  int // index in entities, -1 for nested functions which are not reported
+	indent  int
+	isClass bool
+	name    string
+}
+
+// ParseEntities parses the source and returns entities with their line ranges, names starting
+// with an underscore are not exported; nested functions are a part of their parent
+func (pa *PythonAnalyzer) ParseEntities(content string) ([]ChangedEntity, error) {
+	lines := strings.Split(content, "\n")
+
+	var (
+		entities []ChangedEntity
+		open     []pythonBlock
+		lastCode int // last non-empty line of code
+	)
+	closeBlocks := func(indent int) {
+		for len(open) > 0 && indent <= open[len(open)-1].indent {
+			block := open[len(open)-1]
+			open = open[:len(open)-1]
+			if block.entity < 0 {
+				continue
+			}
+			entity := &entities[block.entity]
+			entity.EndLine = max(lastCode, entity.StartLine)
+			entity.AfterCode = strings.Join(lines[entity.StartLine-1:entity.EndLine], "\n")
+		}
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		closeBlocks(indent)
+		lastCode = i + 1
+
+		matches := pythonDefRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		isClass, name := matches[1] == "class", matches[2]
+
+		block := pythonBlock{entity: -1, indent: indent, isClass: isClass, name: name}
+		entity := ChangedEntity{
+			Name:       name,
+			FullName:   name,
+			IsExported: isPythonPublic(name),
+			StartLine:  i + 1,
+		}
+		switch {
+		case isClass && len(open) == 0:
+			entity.Type = EntityTypeType
+		case isClass:
+			// nested classes are a part of their parent
+		case len(open) == 0:
+			entity.Type = EntityTypeFunction
+			entity.Signature = strings.TrimSuffix(trimmed, ":")
+		case open[len(open)-1].isClass:
+			parent := open[len(open)-1]
+			entity.Type = EntityTypeMethod
+			entity.FullName = parent.name + "." + name
+			entity.IsExported = entity.IsExported && isPythonPublic(parent.name)
+			entity.Signature = strings.TrimSuffix(trimmed, ":")
+		}
+		if entity.Type != "" {
+			block.entity = len(entities)
+			entities = append(entities, entity)
+		}
+		open = append(open, block)
+	}
+	closeBlocks(-1)
+
+	return entities, nil
+}
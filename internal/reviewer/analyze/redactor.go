@@ -0,0 +1,131 @@
+package analyze
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Kinds of redacted values used in placeholders
+const (
+	redactionSecret     = "SECRET"
+	redactionPrivateKey = "PRIVATE_KEY"
+	redactionEmail      = "EMAIL"
+	redactionCustom     = "CUSTOM"
+)
+
+var (
+	privateKeyEndRegex = regexp.MustCompile(`-----END (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)
+	// key body is base64, the first character can't be '+' to keep the diff marker of the line
+	privateKeyBodyRegex = regexp.MustCompile(`[A-Za-z0-9/=][A-Za-z0-9+/=]{15,}`)
+	// local part starts with a letter or digit to keep the diff marker of the line
+	emailRegex = regexp.MustCompile(`\b[A-Za-z0-9][A-Za-z0-9._%+-]*@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)
+)
+
+// Redactor masks secrets, emails and custom patterns in content sent to the model, the same value
+// is always replaced with the same placeholder like <REDACTED_SECRET_1> so the model can still
+// reason about it; values are matched within a line, so line numbers are not changed
+type Redactor struct {
+	patterns  []*regexp.Regexp
+	allowlist []string
+
+	mu           sync.Mutex
+	placeholders map[string]string
+	kinds        map[string]int
+	redactions   int
+}
+
+// NewRedactor creates a redactor with additional patterns of values to mask,
+// allowlist are path patterns (glob or substring) of files that are not redacted like test fixtures
+func NewRedactor(patterns []*regexp.Regexp, allowlist []string) *Redactor {
+	return &Redactor{
+		patterns:     patterns,
+		allowlist:    allowlist,
+		placeholders: make(map[string]string),
+		kinds:        make(map[string]int),
+	}
+}
+
+// Redact returns the text of the file with masked values, files from the allowlist are returned as is;
+// empty path is used for text of many files, it is always redacted
+func (r *Redactor) Redact(filePath, text string) string {
+	if filePath != "" && matchesPathPatterns(filePath, r.allowlist) {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	inPrivateKey := false
+	for i, line := range lines {
+		switch {
+		case privateKeyBeginRegex.MatchString(line):
+			inPrivateKey = true
+		case privateKeyEndRegex.MatchString(line):
+			inPrivateKey = false
+		case inPrivateKey:
+			line = privateKeyBodyRegex.ReplaceAllStringFunc(line, func(value string) string {
+				return r.placeholder(redactionPrivateKey, value)
+			})
+		}
+		lines[i] = r.redactLine(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Redactions returns the number of masked values
+func (r *Redactor) Redactions() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.redactions
+}
+
+// redactLine masks known tokens, high-entropy values assigned to secret-like names, emails and custom patterns
+func (r *Redactor) redactLine(line string) string {
+	for _, pattern := range knownSecretPatterns {
+		if pattern.regex == privateKeyBeginRegex {
+			continue // header is not a secret, key body is redacted line by line
+		}
+		line = pattern.regex.ReplaceAllStringFunc(line, func(value string) string {
+			return r.placeholder(redactionSecret, value)
+		})
+	}
+
+	// Only the assigned value is replaced, the name stays for the model to understand the code
+	matches := secretAssignmentRegex.FindAllStringSubmatchIndex(line, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		start, end := matches[i][2], matches[i][3]
+		value := line[start:end]
+		if isSecretPlaceholder(value) || shannonEntropy(value) < secretMinEntropy {
+			continue
+		}
+		line = line[:start] + r.placeholder(redactionSecret, value) + line[end:]
+	}
+
+	line = emailRegex.ReplaceAllStringFunc(line, func(value string) string {
+		return r.placeholder(redactionEmail, value)
+	})
+	for _, pattern := range r.patterns {
+		line = pattern.ReplaceAllStringFunc(line, func(value string) string {
+			return r.placeholder(redactionCustom, value)
+		})
+	}
+
+	return line
+}
+
+// placeholder returns the placeholder of the value, it is created on the first occurrence
+func (r *Redactor) placeholder(kind, value string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.redactions++
+	key := kind + ":" + value
+	if placeholder, ok := r.placeholders[key]; ok {
+		return placeholder
+	}
+	r.kinds[kind]++
+	placeholder := fmt.Sprintf("<REDACTED_%s_%d>", kind, r.kinds[kind])
+	r.placeholders[key] = placeholder
+	return placeholder
+}
@@ -0,0 +1,69 @@
+package analyze
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigPath is the path of the review configuration stored in the reviewed repository
+const RepoConfigPath = ".codry.yml"
+
+// RepoConfig is the review configuration stored in the reviewed repository
+type RepoConfig struct {
+	Imports RepoImportsConfig `yaml:"imports"`
+	Search  RepoSearchConfig  `yaml:"search"`
+	// Instructions are custom instructions for the code and architecture review of the repository
+	Instructions string `yaml:"instructions"`
+}
+
+// RepoSearchConfig represents codebase-wide searches of the repository
+type RepoSearchConfig struct {
+	// ExcludeDirs are patterns of directories skipped by searches in addition to the ones of the reviewer config
+	ExcludeDirs []string `yaml:"exclude_dirs"`
+}
+
+// RepoImportsConfig represents import rules of the repository
+type RepoImportsConfig struct {
+	// Forbidden are import paths (with subpackages) that must not be added
+	Forbidden []string `yaml:"forbidden"`
+	// Preferred are alternatives of forbidden imports in "forbidden -> preferred" format
+	Preferred []string `yaml:"preferred"`
+}
+
+// LoadRepoConfig loads the repository configuration at the ref, it returns error if the file doesn't exist
+func LoadRepoConfig(ctx context.Context, provider interfaces.CodeProvider, projectID, ref string) (*RepoConfig, error) {
+	content, err := FetchFileContent(ctx, provider, projectID, RepoConfigPath, ref)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to get repository config")
+	}
+
+	var cfg RepoConfig
+	if err := yaml.Unmarshal([]byte(content), &cfg); err != nil {
+		return nil, errm.Wrap(err, "failed to parse repository config")
+	}
+
+	return &cfg, nil
+}
+
+// Merge returns import style with rules of the repository config added
+func (s ImportStyle) Merge(cfg RepoImportsConfig) ImportStyle {
+	s.ForbiddenImports = append(slices.Clone(s.ForbiddenImports), cfg.Forbidden...)
+	s.PreferredImports = append(slices.Clone(s.PreferredImports), cfg.Preferred...)
+	return s
+}
+
+// PreferredImport returns the alternative of the forbidden import from "forbidden -> preferred" entries
+func (s ImportStyle) PreferredImport(forbidden string) (string, bool) {
+	for _, entry := range s.PreferredImports {
+		from, to, ok := strings.Cut(entry, "->")
+		if ok && strings.TrimSpace(from) == forbidden {
+			return strings.TrimSpace(to), true
+		}
+	}
+	return "", false
+}
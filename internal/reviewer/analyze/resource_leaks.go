@@ -0,0 +1,265 @@
+package analyze
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/errm"
+)
+
+// ResourcePair is a call acquiring a resource and the method releasing it
+type ResourcePair struct {
+	// Acquire is "pkg.Func" for a function of the imported package or ".Method" for a method of any value,
+	// a method called without arguments like ".Lock" acquires its receiver, otherwise it returns the resource
+	Acquire string
+	// Release is the method called on the resource like "Close" or a method of its field like "Body.Close"
+	Release string
+}
+
+// DefaultResourcePairs are resources that leak if they are not released in the function that acquires them
+var DefaultResourcePairs = []ResourcePair{
+	{Acquire: "os.Open", Release: "Close"},
+	{Acquire: "os.Create", Release: "Close"},
+	{Acquire: "os.OpenFile", Release: "Close"},
+	{Acquire: ".Query", Release: "Close"},
+	{Acquire: ".QueryContext", Release: "Close"},
+	{Acquire: "http.Get", Release: "Body.Close"},
+	{Acquire: "http.Head", Release: "Body.Close"},
+	{Acquire: "http.Post", Release: "Body.Close"},
+	{Acquire: "http.PostForm", Release: "Body.Close"},
+	{Acquire: ".Lock", Release: "Unlock"},
+	{Acquire: ".RLock", Release: "RUnlock"},
+}
+
+// ParseResourcePair parses the pair in "acquire -> release" format like "os.Open -> Close" or ".Lock -> Unlock"
+func ParseResourcePair(entry string) (ResourcePair, error) {
+	acquire, release, ok := strings.Cut(entry, "->")
+	pair := ResourcePair{Acquire: strings.TrimSpace(acquire), Release: strings.TrimSpace(release)}
+	if !ok || pair.Release == "" || !strings.Contains(pair.Acquire, ".") || strings.HasSuffix(pair.Acquire, ".") {
+		return ResourcePair{}, errm.Errorf("invalid resource pair %q, expected \"pkg.Func -> Method\" or \".Method -> Method\"", entry)
+	}
+	return pair, nil
+}
+
+// ResourceLeak is a resource acquired in an added line of a Go function that is not released in it
+type ResourceLeak struct {
+	FilePath string
+	Line     int
+	Code     string
+	// Resource is the expression of the resource like "rows" or "s.mu", empty if the result of the call is discarded
+	Resource string
+	Pair     ResourcePair
+}
+
+// FindResourceLeaks returns resources acquired in added lines of the Go file content without a deferred or direct
+// call of the release method in the same function. Resources returned, stored or passed to deferred calls
+// are owned by others and are not reported. Test files, lines with //nolint or //nolint:codry comments
+// and files that cannot be parsed are skipped.
+func FindResourceLeaks(filePath, content, diff string, pairs []ResourcePair) []ResourceLeak {
+	if detectLanguage(filePath) != LanguageGo || strings.HasSuffix(filePath, "_test.go") || content == "" || len(pairs) == 0 {
+		return nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, content, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	added := make(map[int]bool)
+	for _, line := range ParseAddedLines(diff) {
+		added[line.Number] = true
+	}
+	suppressed := nolintLines(fset, file)
+	lines := strings.Split(content, "\n")
+
+	var leaks []ResourceLeak
+	checkScope := func(body *ast.BlockStmt) {
+		for _, acquisition := range findAcquisitions(body, pairs) {
+			line := fset.Position(acquisition.call.Pos()).Line
+			if !added[line] || suppressed[line] || line > len(lines) {
+				continue
+			}
+			if acquisition.resource != "" && (isReleased(body, acquisition) || escapes(body, acquisition)) {
+				continue
+			}
+			leaks = append(leaks, ResourceLeak{
+				FilePath: filePath,
+				Line:     line,
+				Code:     strings.TrimSpace(lines[line-1]),
+				Resource: acquisition.resource,
+				Pair:     acquisition.pair,
+			})
+		}
+	}
+
+	// Every function literal is a scope of its own, resources acquired in it are released in it
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncDecl:
+			if n.Body != nil {
+				checkScope(n.Body)
+			}
+		case *ast.FuncLit:
+			checkScope(n.Body)
+		}
+		return true
+	})
+
+	slices.SortStableFunc(leaks, func(a, b ResourceLeak) int { return a.Line - b.Line })
+	return leaks
+}
+
+// acquisition is a call acquiring a resource in a function scope
+type acquisition struct {
+	call     *ast.CallExpr
+	assign   ast.Node // statement assigning the resource, nil for receivers of calls like mu.Lock()
+	resource string
+	pair     ResourcePair
+}
+
+// findAcquisitions returns acquiring calls of the scope without nested function literals: results assigned
+// to variables, discarded results and receivers of statement method calls without arguments like mu.Lock(),
+// methods with arguments like db.Query(q) return the resource; calls passing the result
+// to other expressions like return os.Open(path) transfer the resource and are skipped
+func findAcquisitions(body *ast.BlockStmt, pairs []ResourcePair) []acquisition {
+	var acquisitions []acquisition
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ExprStmt:
+			call, ok := n.X.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			pair, method, ok := matchAcquire(call, pairs)
+			if !ok {
+				return true
+			}
+			if method && len(call.Args) == 0 {
+				// The receiver is the resource of method calls without arguments like mu.Lock()
+				if receiver := exprName(call.Fun.(*ast.SelectorExpr).X); receiver != "" {
+					acquisitions = append(acquisitions, acquisition{call: call, resource: receiver, pair: pair})
+				}
+				return true
+			}
+			acquisitions = append(acquisitions, acquisition{call: call, pair: pair})
+		case *ast.AssignStmt:
+			if len(n.Rhs) == 1 && len(n.Lhs) > 0 {
+				acquisitions = appendAssigned(acquisitions, n, n.Lhs[0], n.Rhs[0], pairs)
+			}
+		case *ast.ValueSpec:
+			if len(n.Values) == 1 && len(n.Names) > 0 {
+				acquisitions = appendAssigned(acquisitions, n, n.Names[0], n.Values[0], pairs)
+			}
+		}
+		return true
+	})
+	return acquisitions
+}
+
+// appendAssigned adds the acquisition if the value is an acquiring call, the first variable is the resource
+func appendAssigned(acquisitions []acquisition, assign ast.Node, lhs, value ast.Expr, pairs []ResourcePair) []acquisition {
+	call, ok := ast.Unparen(value).(*ast.CallExpr)
+	if !ok {
+		return acquisitions
+	}
+	pair, method, ok := matchAcquire(call, pairs)
+	if !ok || (method && len(call.Args) == 0) {
+		return acquisitions // methods without arguments like url.Query() don't return resources
+	}
+	ident, ok := lhs.(*ast.Ident)
+	if !ok {
+		return acquisitions // stored to a field or an element, it is owned by the value
+	}
+	resource := ident.Name
+	if resource == "_" {
+		resource = ""
+	}
+	return append(acquisitions, acquisition{call: call, assign: assign, resource: resource, pair: pair})
+}
+
+// matchAcquire returns the pair whose acquire call is the call, method is true for ".Method" pairs
+func matchAcquire(call *ast.CallExpr, pairs []ResourcePair) (pair ResourcePair, method, ok bool) {
+	sel, isSel := call.Fun.(*ast.SelectorExpr)
+	if !isSel {
+		return ResourcePair{}, false, false
+	}
+	name := exprName(sel)
+	for _, pair := range pairs {
+		if strings.HasPrefix(pair.Acquire, ".") {
+			if sel.Sel.Name == pair.Acquire[1:] {
+				return pair, true, true
+			}
+		} else if name == pair.Acquire {
+			return pair, false, true
+		}
+	}
+	return ResourcePair{}, false, false
+}
+
+// isReleased checks if the release method of the resource is called anywhere in the scope,
+// including deferred function literals like defer func() { _ = f.Close() }()
+func isReleased(body *ast.BlockStmt, acquisition acquisition) bool {
+	release := acquisition.resource + "." + acquisition.pair.Release
+	released := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if call, ok := node.(*ast.CallExpr); ok && exprName(call.Fun) == release {
+			released = true
+		}
+		return !released
+	})
+	return released
+}
+
+// escapes checks if the resource variable leaves the scope as a value: it is returned, stored, sent to a channel
+// or passed to a deferred or go call, so another code is responsible for its release; passing it to other calls
+// like io.ReadAll(resp.Body) doesn't take the ownership
+func escapes(body *ast.BlockStmt, acquisition acquisition) bool {
+	if acquisition.assign == nil {
+		return false
+	}
+	// The released field like resp.Body is the resource too
+	owned := acquisition.resource
+	if field, _, ok := strings.Cut(acquisition.pair.Release, "."); ok {
+		owned = acquisition.resource + "." + field
+	}
+	var isValue func(expr ast.Expr) bool
+	isValue = func(expr ast.Expr) bool {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.UnaryExpr:
+			return e.Op == token.AND && isValue(e.X)
+		case *ast.KeyValueExpr:
+			return isValue(e.Value)
+		case *ast.CompositeLit:
+			return slices.ContainsFunc(e.Elts, isValue)
+		default:
+			name := exprName(e)
+			return name == acquisition.resource || name == owned
+		}
+	}
+
+	escaped := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ReturnStmt:
+			escaped = slices.ContainsFunc(n.Results, isValue)
+		case *ast.AssignStmt:
+			escaped = n != acquisition.assign && slices.ContainsFunc(n.Rhs, isValue)
+		case *ast.CompositeLit:
+			escaped = isValue(n)
+		case *ast.SendStmt:
+			escaped = isValue(n.Value)
+		case *ast.DeferStmt:
+			escaped = slices.ContainsFunc(n.Call.Args, isValue)
+		case *ast.GoStmt:
+			escaped = slices.ContainsFunc(n.Call.Args, isValue)
+		}
+		return !escaped
+	})
+	return escaped
+}
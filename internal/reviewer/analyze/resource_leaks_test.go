@@ -0,0 +1,152 @@
+package analyze
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// addedDiff returns the diff adding every line of the content
+func addedDiff(content string) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "@@ -0,0 +1,%d @@\n", len(lines))
+	for _, line := range lines {
+		diff.WriteString("+" + line + "\n")
+	}
+	return diff.String()
+}
+
+func TestFindResourceLeaks(t *testing.T) {
+	const header = "package p\n\nimport (\n\t\"database/sql\"\n\t\"net/http\"\n)\n\n"
+	httpGet := ResourcePair{Acquire: "http.Get", Release: "Body.Close"}
+	query := ResourcePair{Acquire: ".Query", Release: "Close"}
+	lock := ResourcePair{Acquire: ".Lock", Release: "Unlock"}
+
+	tests := []struct {
+		name     string
+		filePath string
+		body     string
+		want     []ResourceLeak
+	}{
+		{
+			name: "response body is not closed",
+			body: "func fetch(url string) error {\n\tresp, err := http.Get(url)\n\tif err != nil {\n\t\treturn err\n\t}\n" +
+				"\t_ = resp.StatusCode\n\treturn nil\n}\n",
+			want: []ResourceLeak{{Line: 9, Code: "resp, err := http.Get(url)", Resource: "resp", Pair: httpGet}},
+		},
+		{
+			name: "response body is closed by defer",
+			body: "func fetch(url string) error {\n\tresp, err := http.Get(url)\n\tif err != nil {\n\t\treturn err\n\t}\n" +
+				"\tdefer resp.Body.Close()\n\treturn nil\n}\n",
+		},
+		{
+			name: "response is returned",
+			body: "func fetch(url string) (*http.Response, error) {\n\tresp, err := http.Get(url)\n\treturn resp, err\n}\n",
+		},
+		{
+			name: "response is discarded",
+			body: "func ping(url string) {\n\thttp.Get(url)\n}\n",
+			want: []ResourceLeak{{Line: 9, Code: "http.Get(url)", Pair: httpGet}},
+		},
+		{
+			name: "rows are not closed",
+			body: "func count(db *sql.DB) int {\n\trows, _ := db.Query(\"SELECT id FROM users\")\n\tn := 0\n" +
+				"\tfor rows.Next() {\n\t\tn++\n\t}\n\treturn n\n}\n",
+			want: []ResourceLeak{{Line: 9, Code: `rows, _ := db.Query("SELECT id FROM users")`, Resource: "rows", Pair: query}},
+		},
+		{
+			name: "rows are closed by defer",
+			body: "func count(db *sql.DB) int {\n\trows, _ := db.Query(\"SELECT id FROM users\")\n\tdefer rows.Close()\n" +
+				"\tn := 0\n\tfor rows.Next() {\n\t\tn++\n\t}\n\treturn n\n}\n",
+		},
+		{
+			name: "rows are closed directly",
+			body: "func first(db *sql.DB) bool {\n\trows, _ := db.Query(\"SELECT id FROM users\")\n\tok := rows.Next()\n" +
+				"\trows.Close()\n\treturn ok\n}\n",
+		},
+		{
+			name: "rows are stored",
+			body: "type cursor struct{ rows *sql.Rows }\n\nfunc (c *cursor) open(db *sql.DB) {\n" +
+				"\trows, _ := db.Query(\"SELECT id FROM users\")\n\tc.rows = rows\n}\n",
+		},
+		{
+			name: "rows of a function literal",
+			body: "func run(db *sql.DB) {\n\tdefer func() {}()\n\tgo func() {\n\t\trows, _ := db.Query(\"SELECT 1\")\n" +
+				"\t\t_ = rows.Next()\n\t}()\n}\n",
+			want: []ResourceLeak{{Line: 11, Code: `rows, _ := db.Query("SELECT 1")`, Resource: "rows", Pair: query}},
+		},
+		{
+			name: "lock without unlock",
+			body: "type store struct{ mu interface{ Lock(); Unlock() } }\n\nfunc (s *store) set() {\n\ts.mu.Lock()\n}\n",
+			want: []ResourceLeak{{Line: 11, Code: "s.mu.Lock()", Resource: "s.mu", Pair: lock}},
+		},
+		{
+			name: "suppressed line",
+			body: "func ping(url string) {\n\tresp, _ := http.Get(url) //nolint:codry\n\t_ = resp\n}\n",
+		},
+		{
+			name:     "test file",
+			filePath: "p_test.go",
+			body:     "func ping(url string) {\n\thttp.Get(url)\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := tt.filePath
+			if filePath == "" {
+				filePath = "p.go"
+			}
+			content := header + tt.body
+			for i := range tt.want {
+				tt.want[i].FilePath = filePath
+			}
+			got := FindResourceLeaks(filePath, content, addedDiff(content), DefaultResourcePairs)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FindResourceLeaks() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindResourceLeaksAddedLines(t *testing.T) {
+	const content = "package p\n\nimport \"net/http\"\n\nfunc ping(url string) {\n\thttp.Get(url)\n\thttp.Head(url)\n}\n"
+	// Only the call of the second line is added
+	const diff = "@@ -5,3 +5,4 @@\n func ping(url string) {\n \thttp.Get(url)\n+\thttp.Head(url)\n }\n"
+
+	got := FindResourceLeaks("p.go", content, diff, DefaultResourcePairs)
+	want := []ResourceLeak{{FilePath: "p.go", Line: 7, Code: "http.Head(url)", Pair: ResourcePair{Acquire: "http.Head", Release: "Body.Close"}}}
+	if !slices.Equal(got, want) {
+		t.Errorf("FindResourceLeaks() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParseResourcePair(t *testing.T) {
+	tests := []struct {
+		entry   string
+		want    ResourcePair
+		wantErr bool
+	}{
+		{entry: "os.Open -> Close", want: ResourcePair{Acquire: "os.Open", Release: "Close"}},
+		{entry: "http.Get->Body.Close", want: ResourcePair{Acquire: "http.Get", Release: "Body.Close"}},
+		{entry: " .Lock -> Unlock ", want: ResourcePair{Acquire: ".Lock", Release: "Unlock"}},
+		{entry: "os.Open", wantErr: true},
+		{entry: "os.Open ->", wantErr: true},
+		{entry: "Open -> Close", wantErr: true},
+		{entry: "os. -> Close", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			got, err := ParseResourcePair(tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResourcePair() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseResourcePair() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
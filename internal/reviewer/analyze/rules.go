@@ -0,0 +1,114 @@
+package analyze
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// RulesRepoPath is the path of the rules document stored in the reviewed repository
+const RulesRepoPath = "docs/codry-rules.md"
+
+// headingRegex matches markdown headings, tags of the heading are in square brackets at its end like "## Errors [go, py]"
+var headingRegex = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*(?:\[([^\]]*)\])?\s*#*\s*$`)
+
+// RuleSection is a section of a rules document from its heading to the next heading
+type RuleSection struct {
+	Heading string
+	// Tags are file extensions without dot or language names the section applies to, all files if empty;
+	// sections without tags get tags of their parent heading
+	Tags []string
+	// Content is the text of the section with its heading line
+	Content string
+}
+
+// LoadRepoRules loads sections of the rules document of the repository at the ref, it returns error if there is no document
+func LoadRepoRules(ctx context.Context, provider interfaces.CodeProvider, projectID, ref string) ([]RuleSection, error) {
+	content, err := FetchFileContent(ctx, provider, projectID, RulesRepoPath, ref)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to get rules document")
+	}
+	return ParseRules(content), nil
+}
+
+// ParseRules splits the markdown document into sections by headings, text before the first heading is a section
+// without heading; headings inside code blocks are ignored
+func ParseRules(content string) []RuleSection {
+	var (
+		sections []RuleSection
+		current  RuleSection
+		lines    []string
+		inCode   bool
+		parents  [7][]string // tags of the last heading of every level
+	)
+	flush := func() {
+		current.Content = strings.TrimSpace(strings.Join(lines, "\n"))
+		if current.Content != "" {
+			sections = append(sections, current)
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		match := headingRegex.FindStringSubmatch(line)
+		if inCode || match == nil {
+			lines = append(lines, line)
+			continue
+		}
+
+		flush()
+		level := len(match[1])
+		tags := parseRuleTags(match[3])
+		if len(tags) == 0 {
+			for parent := level - 1; parent > 0 && len(tags) == 0; parent-- {
+				tags = parents[parent]
+			}
+		}
+		parents[level] = tags
+		for deeper := level + 1; deeper < len(parents); deeper++ {
+			parents[deeper] = nil
+		}
+
+		current = RuleSection{Heading: match[2], Tags: tags}
+		lines = []string{match[1] + " " + match[2]}
+	}
+	flush()
+
+	return sections
+}
+
+// SelectRules returns sections that apply to the file by its extension or language, sections without tags apply to all files
+func SelectRules(sections []RuleSection, filePath, language string) []RuleSection {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(filePath), "."))
+	language = strings.ToLower(language)
+
+	var selected []RuleSection
+	for _, section := range sections {
+		if len(section.Tags) == 0 {
+			selected = append(selected, section)
+			continue
+		}
+		for _, tag := range section.Tags {
+			if tag == ext || tag == language {
+				selected = append(selected, section)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// parseRuleTags returns lowercased tags separated by commas or spaces, leading dots of extensions are removed
+func parseRuleTags(text string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' }) {
+		tags = append(tags, strings.ToLower(strings.TrimPrefix(tag, ".")))
+	}
+	return tags
+}
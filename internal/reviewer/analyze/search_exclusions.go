@@ -0,0 +1,120 @@
+package analyze
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+// maxSearchExclusionRefs is the number of refs whose excluded directories of .codry.yml are kept
+const maxSearchExclusionRefs = 100
+
+// DefaultSearchExcludeDirs are names of generated and vendored directories that are not searched for callers,
+// project files and readers of config keys
+var DefaultSearchExcludeDirs = []string{
+	"vendor", "node_modules", "bower_components", "third_party", "dist", "build", "target", "coverage",
+	".git", ".next", ".venv", "venv", "__pycache__",
+}
+
+// searchProvider is the code provider of codebase-wide searches: directories excluded by the config
+// and by .codry.yml of the listed ref are not listed, so their files are never fetched
+type searchProvider struct {
+	interfaces.CodeProvider
+	lister   interfaces.FileLister
+	excludes []string
+
+	mu sync.Mutex
+	// repoExcludes are excluded directories of .codry.yml by "<project>@<ref>", nil if it has none
+	repoExcludes map[string][]string
+}
+
+// NewSearchProvider wraps the provider for codebase-wide searches, directories matching the patterns
+// are skipped as described by IsExcludedSearchDir; the provider is returned as is if it can't list files
+func NewSearchProvider(provider interfaces.CodeProvider, excludeDirs []string) interfaces.CodeProvider {
+	lister, ok := provider.(interfaces.FileLister)
+	if !ok {
+		return provider
+	}
+	return &searchProvider{
+		CodeProvider: provider,
+		lister:       lister,
+		excludes:     excludeDirs,
+		repoExcludes: make(map[string][]string),
+	}
+}
+
+var _ interfaces.FileLister = (*searchProvider)(nil)
+
+// ListFiles lists files of the directory, an excluded directory has no files
+func (p *searchProvider) ListFiles(ctx context.Context, projectID, dir, ref string) ([]string, error) {
+	if IsExcludedSearchDir(dir, p.excludes) || IsExcludedSearchDir(dir, p.repoExcludeDirs(ctx, projectID, ref)) {
+		return nil, nil
+	}
+	return p.lister.ListFiles(ctx, projectID, dir, ref)
+}
+
+// repoExcludeDirs returns excluded directories of .codry.yml at the ref, it is loaded once per ref
+func (p *searchProvider) repoExcludeDirs(ctx context.Context, projectID, ref string) []string {
+	key := projectID + "@" + ref
+	p.mu.Lock()
+	excludes, ok := p.repoExcludes[key]
+	p.mu.Unlock()
+	if ok {
+		return excludes
+	}
+
+	cfg, err := LoadRepoConfig(ctx, p.CodeProvider, projectID, ref)
+	if err != nil && ctx.Err() != nil {
+		return nil
+	}
+	if err == nil {
+		excludes = cfg.Search.ExcludeDirs
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.repoExcludes) >= maxSearchExclusionRefs {
+		clear(p.repoExcludes)
+	}
+	p.repoExcludes[key] = excludes
+	return excludes
+}
+
+// IsExcludedSearchDir checks if the directory is excluded from searches: a pattern without slashes like vendor
+// or *.egg-info matches any directory of the path, other patterns like web/static match the directory and its
+// subdirectories from the repository root; the root itself is never excluded
+func IsExcludedSearchDir(dir string, patterns []string) bool {
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	if dir == "" || len(patterns) == 0 {
+		return false
+	}
+
+	names := strings.Split(dir, "/")
+	for _, pattern := range patterns {
+		pattern = strings.Trim(pattern, "/")
+		if pattern == "" {
+			continue
+		}
+		if !strings.Contains(pattern, "/") {
+			if slices.ContainsFunc(names, func(name string) bool {
+				matched, _ := path.Match(pattern, name)
+				return matched
+			}) {
+				return true
+			}
+			continue
+		}
+		depth := strings.Count(pattern, "/") + 1
+		if depth > len(names) {
+			continue
+		}
+		if matched, _ := path.Match(pattern, strings.Join(names[:depth], "/")); matched {
+			return true
+		}
+	}
+	return false
+}
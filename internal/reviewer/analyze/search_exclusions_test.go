@@ -0,0 +1,122 @@
+package analyze
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+func TestIsExcludedSearchDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		patterns []string
+		want     bool
+	}{
+		{"root", "", DefaultSearchExcludeDirs, false},
+		{"root with a dot", ".", DefaultSearchExcludeDirs, false},
+		{"vendor", "vendor", DefaultSearchExcludeDirs, true},
+		{"subdirectory of vendor", "vendor/github.com/lib", DefaultSearchExcludeDirs, true},
+		{"nested node_modules", "web/node_modules/react", DefaultSearchExcludeDirs, true},
+		{"name containing vendor", "vendors/api", DefaultSearchExcludeDirs, false},
+		{"source directory", "internal/store", DefaultSearchExcludeDirs, false},
+		{"glob of a name", "python/pkg.egg-info", []string{"*.egg-info"}, true},
+		{"path from the root", "web/static/js", []string{"web/static"}, true},
+		{"path not from the root", "app/web/static", []string{"web/static"}, false},
+		{"path with slashes around", "web/static", []string{"/web/static/"}, true},
+		{"no patterns", "vendor", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsExcludedSearchDir(tt.dir, tt.patterns); got != tt.want {
+				t.Errorf("IsExcludedSearchDir(%q, %v) = %v, want %v", tt.dir, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchProviderExcludedDirsNotFetched(t *testing.T) {
+	const reader = "package p\n\nvar conns = viper.GetInt(\"database.max_conns\")\n"
+	files := map[string]string{
+		RepoConfigPath:                 "search:\n  exclude_dirs: [internal/generated]\n",
+		"deploy/config.yaml":           "database:\n  max_conns: 20\n",
+		"cmd/main.go":                  reader,
+		"vendor/lib/config.go":         reader,
+		"vendor/lib/lib.go":            "package lib\n",
+		"internal/generated/config.go": reader,
+		"internal/generated/gen.go":    "package generated\n",
+		"internal/build/config.go":     reader,
+		"internal/build/build.go":      "package build\n",
+	}
+	request := model.ReviewRequest{
+		ProjectID:    "app",
+		MergeRequest: &model.MergeRequest{TargetBranch: "main", SHA: "head"},
+		Changes: []*model.FileDiff{
+			{NewPath: "deploy/config.yaml"},
+			{NewPath: "vendor/lib/lib.go"},
+			{NewPath: "internal/generated/gen.go"},
+			{NewPath: "internal/build/build.go"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		excludes     []string
+		wantUsages   []string
+		wantExcluded []string
+	}{
+		{
+			name:         "default directories and .codry.yml",
+			excludes:     DefaultSearchExcludeDirs,
+			wantUsages:   []string{"cmd/main.go"},
+			wantExcluded: []string{"vendor/lib/config.go", "internal/generated/config.go", "internal/build/config.go"},
+		},
+		{
+			name:         "configured directories replace the defaults",
+			excludes:     []string{"build"},
+			wantUsages:   []string{"cmd/main.go", "vendor/lib/config.go"},
+			wantExcluded: []string{"internal/generated/config.go", "internal/build/config.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := &treeProvider{files: files}
+			provider := NewSearchProvider(listingTreeProvider{tree}, tt.excludes)
+
+			usages, err := NewConfigUsageFinder(provider).FindUsages(context.Background(), request, "deploy/config.yaml", []string{"database.max_conns"})
+			if err != nil {
+				t.Fatalf("FindUsages() error = %v", err)
+			}
+			if got := usages["database.max_conns"]; !slices.Equal(got, tt.wantUsages) {
+				t.Errorf("FindUsages() = %v, want %v", got, tt.wantUsages)
+			}
+
+			for _, filePath := range []string{"vendor/lib/lib.go", "internal/generated/gen.go", "internal/build/build.go"} {
+				if _, err := loadCallerFiles(context.Background(), provider, request, filePath); err != nil {
+					t.Fatalf("loadCallerFiles(%s) error = %v", filePath, err)
+				}
+			}
+
+			for _, filePath := range tt.wantExcluded {
+				if got := tree.fetchCount(filePath); got != 0 {
+					t.Errorf("fetches of %s = %d, want 0", filePath, got)
+				}
+			}
+			// .codry.yml is loaded once for the ref
+			if got := tree.fetchCount(RepoConfigPath); got != 1 {
+				t.Errorf("fetches of %s = %d, want 1", RepoConfigPath, got)
+			}
+		})
+	}
+}
+
+func TestNewSearchProviderWithoutListing(t *testing.T) {
+	provider := &treeProvider{}
+	if got := NewSearchProvider(provider, DefaultSearchExcludeDirs); got != provider {
+		t.Errorf("NewSearchProvider() = %T, want the provider itself", got)
+	}
+}
@@ -0,0 +1,328 @@
+package analyze
+
+import (
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// SecurityFindingType represents the category of a deterministic security finding
+type SecurityFindingType string
+
+const (
+	SecurityFindingSQLInjection     SecurityFindingType = "sql_injection"
+	SecurityFindingHardcodedSecret  SecurityFindingType = "hardcoded_secret"
+	SecurityFindingCommandInjection SecurityFindingType = "command_injection"
+)
+
+// Severity levels of security findings
+const (
+	SecuritySeverityHigh     = "high"
+	SecuritySeverityCritical = "critical"
+)
+
+// DefaultSecretsAllowlist is a list of paths where dummy secrets for tests are expected
+var DefaultSecretsAllowlist = []string{"testdata/", "fixtures/"}
+
+// SecurityFinding is a high-confidence issue found by pattern matching on added diff lines
+type SecurityFinding struct {
+	Type        SecurityFindingType `json:"type"`        // category of the finding
+	FilePath    string              `json:"file_path"`   // file where it was found
+	Line        int                 `json:"line"`        // line number in the new file
+	Severity    string              `json:"severity"`    // high or critical
+	Code        string              `json:"code"`        // offending code line, secret values are redacted
+	Description string              `json:"description"` // human readable explanation
+
+	secret string // matched secret value, it is never serialized
+}
+
+// RedactSecret replaces the matched secret value in the text with its redacted form,
+// the text is returned as is for other findings
+func (f SecurityFinding) RedactSecret(text string) string {
+	if f.secret == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, f.secret, redactSecret(f.secret))
+}
+
+// SecurityScanner performs cheap deterministic security checks on changed code
+// to give the model hints it can corroborate instead of guessing
+type SecurityScanner struct {
+	secretsAllowlist []string
+}
+
+// NewSecurityScanner creates a new security scanner, secretsAllowlist are path patterns (glob or substring)
+// of files that are not scanned for secrets like test fixtures that legitimately contain dummy secrets
+func NewSecurityScanner(secretsAllowlist []string) *SecurityScanner {
+	return &SecurityScanner{
+		secretsAllowlist: secretsAllowlist,
+	}
+}
+
+// ScanDiff scans added lines of the diff and returns found issues
+func (ss *SecurityScanner) ScanDiff(filePath, diff string) []SecurityFinding {
+	language := detectLanguage(filePath)
+	scanSecrets := !ss.isSecretsAllowlisted(filePath)
+
+	var findings []SecurityFinding
+	for _, line := range ParseAddedLines(diff) {
+		if scanSecrets {
+			if secret, desc, ok := detectHardcodedSecret(line.Content); ok {
+				code := strings.TrimSpace(line.Content)
+				if secret != "" {
+					code = strings.ReplaceAll(code, secret, redactSecret(secret))
+				}
+				findings = append(findings, SecurityFinding{
+					Type:        SecurityFindingHardcodedSecret,
+					FilePath:    filePath,
+					Line:        line.Number,
+					Severity:    SecuritySeverityCritical,
+					Code:        code,
+					Description: desc,
+					secret:      secret,
+				})
+				continue
+			}
+		}
+		if desc, ok := detectSQLInjection(line.Content, language); ok {
+			findings = append(findings, SecurityFinding{
+				Type:        SecurityFindingSQLInjection,
+				FilePath:    filePath,
+				Line:        line.Number,
+				Severity:    SecuritySeverityHigh,
+				Code:        strings.TrimSpace(line.Content),
+				Description: desc,
+			})
+		}
+		if desc, ok := detectCommandInjection(line.Content, language); ok {
+			findings = append(findings, SecurityFinding{
+				Type:        SecurityFindingCommandInjection,
+				FilePath:    filePath,
+				Line:        line.Number,
+				Severity:    SecuritySeverityCritical,
+				Code:        strings.TrimSpace(line.Content),
+				Description: desc,
+			})
+		}
+	}
+
+	return findings
+}
+
+func (ss *SecurityScanner) isSecretsAllowlisted(filePath string) bool {
+	return matchesPathPatterns(filePath, ss.secretsAllowlist)
+}
+
+// matchesPathPatterns checks if the path matches any glob pattern or contains any pattern as a substring
+func matchesPathPatterns(filePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, filePath); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(filePath)); matched {
+			return true
+		}
+		if strings.Contains(filePath, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddedLine is a line added in the diff with its number in the new file
+type AddedLine struct {
+	Number  int
+	Content string
+}
+
+// ParseAddedLines returns added lines of the unified diff with their new file line numbers
+func ParseAddedLines(diff string) []AddedLine {
+	return addedLines(model.ParseHunks(diff))
+}
+
+// FileAddedLines returns added lines of the file from its parsed hunks
+func FileAddedLines(fileDiff *model.FileDiff) []AddedLine {
+	return addedLines(fileDiff.DiffHunks())
+}
+
+func addedLines(hunks []model.DiffHunk) []AddedLine {
+	var lines []AddedLine
+	for _, hunk := range hunks {
+		for _, line := range hunk.Lines {
+			if line.Kind == model.DiffLineAdded {
+				lines = append(lines, AddedLine{Number: line.NewLine, Content: line.Content})
+			}
+		}
+	}
+	return lines
+}
+
+var (
+	sqlKeywordRegex = regexp.MustCompile(`(?i)\b(?:SELECT\s+.+\s+FROM|INSERT\s+INTO|UPDATE\s+\w+\s+SET|DELETE\s+FROM|WHERE\s+\w+|ORDER\s+BY|EXEC(?:UTE)?\s+\w+)`)
+
+	// quoted string literals: "..." or '...'
+	stringLiteralRegex = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+
+	// string literal concatenated with identifier, call or index expression on either side
+	concatAfterLiteralRegex  = regexp.MustCompile(`(?:"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')\s*\+\s*[A-Za-z_$]`)
+	concatBeforeLiteralRegex = regexp.MustCompile(`[A-Za-z0-9_$)\]]\s*\+\s*(?:"|')`)
+
+	goSprintfRegex     = regexp.MustCompile(`fmt\.Sprintf\(\s*("(?:[^"\\]|\\.)*"|` + "`[^`]*`" + `)`)
+	printfVerbRegex    = regexp.MustCompile(`%[-+# 0]*[sv]`)
+	pythonFStringRegex = regexp.MustCompile(`\b[fF][rR]?("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')`)
+	pythonFormatRegex  = regexp.MustCompile(`("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*')\s*(?:%\s*[A-Za-z_(]|\.format\()`)
+	jsTemplateRegex    = regexp.MustCompile("`[^`]*\\$\\{[^}]+\\}[^`]*`")
+	javaFormatRegex    = regexp.MustCompile(`String\.format\(\s*("(?:[^"\\]|\\.)*")`)
+)
+
+// detectSQLInjection returns description if the line builds SQL query from non-literal values,
+// to keep false positives low it requires both a query keyword inside a string and interpolation
+func detectSQLInjection(code string, language SupportedLanguage) (string, bool) {
+	// Queries are matched in string literals, so only comments are removed
+	code = StripComments(code, language)
+	if strings.TrimSpace(code) == "" {
+		return "", false
+	}
+
+	switch language {
+	case LanguageGo:
+		if match := goSprintfRegex.FindStringSubmatch(code); match != nil &&
+			sqlKeywordRegex.MatchString(match[1]) && printfVerbRegex.MatchString(match[1]) {
+			return "SQL query is built with fmt.Sprintf from non-literal values, use query parameters instead", true
+		}
+	case LanguagePython:
+		if match := pythonFStringRegex.FindStringSubmatch(code); match != nil &&
+			sqlKeywordRegex.MatchString(match[1]) && strings.Contains(match[1], "{") {
+			return "SQL query is built with f-string interpolation, use query parameters instead", true
+		}
+		if match := pythonFormatRegex.FindStringSubmatch(code); match != nil && sqlKeywordRegex.MatchString(match[1]) {
+			return "SQL query is built with string formatting, use query parameters instead", true
+		}
+	case LanguageJavaScript, LanguageTypeScript:
+		if match := jsTemplateRegex.FindString(code); match != "" && sqlKeywordRegex.MatchString(match) {
+			return "SQL query is built with template literal interpolation, use query parameters instead", true
+		}
+	case LanguageJava:
+		if match := javaFormatRegex.FindStringSubmatch(code); match != nil &&
+			sqlKeywordRegex.MatchString(match[1]) && printfVerbRegex.MatchString(match[1]) {
+			return "SQL query is built with String.format from non-literal values, use PreparedStatement parameters instead", true
+		}
+	}
+
+	// String concatenation is common for all supported languages
+	hasQueryLiteral := false
+	for _, literal := range stringLiteralRegex.FindAllString(code, -1) {
+		if sqlKeywordRegex.MatchString(literal) {
+			hasQueryLiteral = true
+			break
+		}
+	}
+	if hasQueryLiteral && (concatAfterLiteralRegex.MatchString(code) || concatBeforeLiteralRegex.MatchString(code)) {
+		return "SQL query is built by string concatenation with non-literal value, use query parameters instead", true
+	}
+
+	return "", false
+}
+
+// secretPattern is a regex for a well-known token format
+type secretPattern struct {
+	regex       *regexp.Regexp
+	description string
+}
+
+var (
+	privateKeyBeginRegex = regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)
+
+	knownSecretPatterns = []secretPattern{
+		{
+			regex:       privateKeyBeginRegex,
+			description: "private key is committed to the repository, remove it and rotate the key",
+		},
+		{
+			regex:       regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+			description: "AWS access key ID is hardcoded, load it from secret storage and rotate the key",
+		},
+		{
+			regex:       regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}\b|\bgithub_pat_[A-Za-z0-9_]{22,}\b`),
+			description: "GitHub token is hardcoded, load it from secret storage and revoke the token",
+		},
+		{
+			regex:       regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`),
+			description: "JWT is hardcoded, it may grant access until it expires",
+		},
+	}
+
+	// string literal assigned to secret-like name: apiKey = "...", "password": "...", SECRET_TOKEN='...'
+	secretAssignmentRegex = regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|pwd|credential|private[_-]?key|access[_-]?key)[A-Za-z0-9_-]*["']?\s*(?::=|=|:)\s*["'` + "`" + `]([^"'` + "`" + `\s]{16,})["'` + "`" + `]`)
+
+	secretPlaceholders = []string{"xxxx", "****", "example", "changeme", "placeholder", "dummy", "${", "{{", "<"}
+)
+
+const (
+	secretMinEntropy  = 3.5 // bits per char, random base64 and hex strings are above it
+	secretVisiblePart = 4
+)
+
+// detectHardcodedSecret returns the secret value and description if the line contains a secret,
+// known token formats are matched by regex, other values must be assigned to secret-like names
+// and look random enough to filter out human-written strings
+func detectHardcodedSecret(code string) (string, string, bool) {
+	for _, pattern := range knownSecretPatterns {
+		if match := pattern.regex.FindString(code); match != "" {
+			if strings.HasPrefix(match, "-----BEGIN") {
+				return "", pattern.description, true // header is not a secret, key body is on next lines
+			}
+			return match, pattern.description, true
+		}
+	}
+
+	for _, match := range secretAssignmentRegex.FindAllStringSubmatch(code, -1) {
+		value := match[1]
+		if isSecretPlaceholder(value) || shannonEntropy(value) < secretMinEntropy {
+			continue
+		}
+		return value, "high-entropy value is assigned to a secret-like name, load it from environment or secret storage", true
+	}
+
+	return "", "", false
+}
+
+func isSecretPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, placeholder := range secretPlaceholders {
+		if strings.Contains(lower, placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the entropy of the string in bits per character
+func shannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// redactSecret keeps a few leading characters of the secret to help locate it
+func redactSecret(secret string) string {
+	if len(secret) <= secretVisiblePart*2 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:secretVisiblePart] + strings.Repeat("*", 8)
+}
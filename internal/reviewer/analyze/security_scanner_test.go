@@ -0,0 +1,92 @@
+package analyze
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScanDiffCommandInjection(t *testing.T) {
+	tests := []struct {
+		name      string
+		filePath  string
+		line      string
+		wantFound bool
+	}{
+		{"go shell with concatenation", "run.go", `cmd := exec.Command("sh", "-c", "ls "+dir)`, true},
+		{"go shell with sprintf", "run.go", `cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("tar -xf %s", name))`, true},
+		{"go shell with a literal", "run.go", `cmd := exec.Command("sh", "-c", "ls -la")`, false},
+		{"go arguments without a shell", "run.go", `cmd := exec.Command("ls", "-la", dir)`, false},
+		{"go sprintf without verbs", "run.go", `cmd := exec.Command("sh", "-c", fmt.Sprintf("ls"))`, false},
+		{"go commented call", "run.go", `// exec.Command("sh", "-c", "ls "+dir)`, false},
+		{"python f-string with shell", "run.py", `subprocess.run(f"ls {path}", shell=True)`, true},
+		{"python concatenation with shell", "run.py", `subprocess.check_output("git log " + ref, shell=True)`, true},
+		{"python os.system with format", "run.py", `os.system("rm -rf {}".format(path))`, true},
+		{"python f-string without shell", "run.py", `subprocess.run(f"ls {path}")`, false},
+		{"python f-string without variables", "run.py", `subprocess.run(f"ls", shell=True)`, false},
+		{"python list of arguments", "run.py", `subprocess.run(["ls", path], shell=False)`, false},
+		{"js template literal", "run.js", "exec(`ls ${dir}`, callback)", true},
+		{"js regexp exec", "run.js", "pattern.exec(`ls ${dir}`)", false},
+	}
+
+	scanner := NewSecurityScanner(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := "@@ -9,0 +10,1 @@\n+" + tt.line + "\n"
+			var got []SecurityFinding
+			for _, finding := range scanner.ScanDiff(tt.filePath, diff) {
+				if finding.Type == SecurityFindingCommandInjection {
+					got = append(got, finding)
+				}
+			}
+			if !tt.wantFound {
+				if len(got) != 0 {
+					t.Errorf("ScanDiff() = %+v, want no command injection", got)
+				}
+				return
+			}
+			if len(got) != 1 {
+				t.Fatalf("ScanDiff() = %d command injections, want 1", len(got))
+			}
+			if got[0].Line != 10 || got[0].Severity != SecuritySeverityCritical || got[0].Code != tt.line {
+				t.Errorf("ScanDiff() = line %d %s %q, want line 10 %s %q", got[0].Line, got[0].Severity, got[0].Code,
+					SecuritySeverityCritical, tt.line)
+			}
+		})
+	}
+}
+
+func TestBuildSecurityContextThreatAreas(t *testing.T) {
+	finding := func(findingType SecurityFindingType, line int) SecurityFinding {
+		return SecurityFinding{Type: findingType, Line: line, Severity: SecuritySeverityCritical, Description: "unsafe"}
+	}
+	tests := []struct {
+		name     string
+		findings []SecurityFinding
+		want     []string
+	}{
+		{"no findings", nil, nil},
+		{"command injection", []SecurityFinding{finding(SecurityFindingCommandInjection, 3)}, []string{"command_injection"}},
+		{
+			name: "every type once",
+			findings: []SecurityFinding{
+				finding(SecurityFindingCommandInjection, 3),
+				finding(SecurityFindingSQLInjection, 5),
+				finding(SecurityFindingCommandInjection, 8),
+			},
+			want: []string{"command_injection", "sql_injection"},
+		},
+	}
+
+	ecb := NewEnhancedContextBuilder(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := ecb.buildSecurityContext(SecurityPatterns{}, nil, tt.findings)
+			if !slices.Equal(info.ThreatAreas, tt.want) {
+				t.Errorf("ThreatAreas = %v, want %v", info.ThreatAreas, tt.want)
+			}
+			if len(tt.findings) > 0 && info.SecurityLevel != "high" {
+				t.Errorf("SecurityLevel = %q, want high", info.SecurityLevel)
+			}
+		})
+	}
+}
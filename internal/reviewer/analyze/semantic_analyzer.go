@@ -3,15 +3,13 @@ package analyze
 import (
 	"context"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
 	"path/filepath"
 	"regexp"
 	"strings"
 
 	"github.com/maxbolgarin/codry/internal/model"
 	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
 	"github.com/maxbolgarin/logze/v2"
 )
 
@@ -30,18 +28,76 @@ const (
 	LanguageUnknown    SupportedLanguage = "unknown"
 )
 
+// LanguageAnalyzer parses source code of a specific language into entities with real line ranges,
+// code of every entity is returned in AfterCode
+type LanguageAnalyzer interface {
+	ParseEntities(content string) ([]ChangedEntity, error)
+}
+
 // SemanticAnalyzer provides deep semantic analysis of code changes
 type SemanticAnalyzer struct {
-	provider interfaces.CodeProvider
-	log      logze.Logger
+	provider   interfaces.CodeProvider
+	analyzers  map[SupportedLanguage]LanguageAnalyzer
+	testImpact *TestImpactAnalyzer
+	log        logze.Logger
+
+	maxCallerSnippets int
+	maxCallerTokens   int
 }
 
 // NewSemanticAnalyzer creates a new semantic analyzer
 func NewSemanticAnalyzer(provider interfaces.CodeProvider) *SemanticAnalyzer {
+	jsAnalyzer := NewJSAnalyzer()
 	return &SemanticAnalyzer{
 		provider: provider,
-		log:      logze.With("component", "semantic-analyzer"),
+		analyzers: map[SupportedLanguage]LanguageAnalyzer{
+			LanguageGo:         NewGoAnalyzer(),
+			LanguageJavaScript: jsAnalyzer,
+			LanguageTypeScript: jsAnalyzer,
+			LanguagePython:     NewPythonAnalyzer(),
+		},
+		testImpact: NewTestImpactAnalyzer(provider, DefaultTestFileConventions),
+		log:        logze.With("component", "semantic-analyzer"),
+
+		maxCallerSnippets: DefaultCallerSnippets,
+		maxCallerTokens:   DefaultCallerSnippetsTokens,
+	}
+}
+
+// SetCallerSnippetLimits sets the number of snippets of unchanged callers of changed functions
+// and the cap of their total size in tokens, non-positive snippets disables them and
+// non-positive tokens removes the cap
+func (sa *SemanticAnalyzer) SetCallerSnippetLimits(maxSnippets, maxTokens int) {
+	sa.maxCallerSnippets = maxSnippets
+	sa.maxCallerTokens = maxTokens
+}
+
+// FindCallerSnippets returns snippets of unchanged Go functions calling functions and methods changed in the file,
+// callers are searched in the changed package and other directories with changed Go files at the head commit;
+// it returns nothing if snippets are disabled or the provider can't list files
+func (sa *SemanticAnalyzer) FindCallerSnippets(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff) ([]RelatedCodeSnippet, error) {
+	if sa.maxCallerSnippets <= 0 || fileDiff.IsDeleted || detectLanguage(fileDiff.NewPath) != LanguageGo {
+		return nil, nil
+	}
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath)
+
+	entities, err := parseGoVersions(ctx, sa.provider, request, fileDiff).changedEntities(fileDiff, log)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to find changed entities")
+	}
+	if len(entities) == 0 {
+		return nil, nil
 	}
+	if err := sa.analyzeDependents(ctx, request, entities, fileDiff.NewPath); err != nil {
+		return nil, errm.Wrap(err, "failed to find callers")
+	}
+
+	return sa.callerSnippets(entities), nil
+}
+
+// callerSnippets selects snippets of callers of the entities within the limits of the analyzer
+func (sa *SemanticAnalyzer) callerSnippets(entities []ChangedEntity) []RelatedCodeSnippet {
+	return selectCallerSnippets(entities, sa.maxCallerSnippets, sa.maxCallerTokens)
 }
 
 // ChangedEntity represents a specific code entity that was changed
@@ -51,12 +107,16 @@ type ChangedEntity struct {
 	FullName     string       `json:"full_name"`    // package.Type.Method or package.Function
 	Package      string       `json:"package"`      // package name
 	IsExported   bool         `json:"is_exported"`  // whether it's exported
+	IsDefault    bool         `json:"is_default"`   // whether it's a default export (JS/TS)
 	StartLine    int          `json:"start_line"`   // start line in file
 	EndLine      int          `json:"end_line"`     // end line in file
 	ChangeType   ChangeType   `json:"change_type"`  // added, modified, deleted
 	BeforeCode   string       `json:"before_code"`  // code before change
 	AfterCode    string       `json:"after_code"`   // code after change
 	Signature    string       `json:"signature"`    // function/method signature
+	Receiver     string       `json:"receiver"`     // method receiver with its name, e.g. "s *Server"
+	Parameters   []string     `json:"parameters"`   // function/method parameters with names if they are declared
+	Returns      []string     `json:"returns"`      // function/method result types with names if they are declared
 	DocComment   string       `json:"doc_comment"`  // documentation comment
 	Dependencies []Dependency `json:"dependencies"` // what this entity depends on
 	Dependents   []Dependent  `json:"dependents"`   // what depends on this entity
@@ -103,6 +163,8 @@ type Dependent struct {
 	FilePath     string     `json:"file_path"`     // file path of dependent
 	UsageContext string     `json:"usage_context"` // how it uses this entity
 	CodeSnippet  string     `json:"code_snippet"`  // relevant code snippet
+	LineNumber   int        `json:"line_number"`   // start line of the dependent
+	CallLines    []int      `json:"call_lines"`    // lines where the dependent calls this entity
 }
 
 // SemanticAnalysisResult contains the results of semantic analysis
@@ -112,6 +174,12 @@ type SemanticAnalysisResult struct {
 	BusinessContext    BusinessContext    `json:"business_context"`
 	ArchitecturalScope ArchitecturalScope `json:"architectural_scope"`
 	ProjectPatterns    ProjectPatterns    `json:"project_patterns"`
+
+	// goFiles are parsed versions of a Go file shared with analyzers of the review context, nil for other languages
+	goFiles *goFileVersions
+	// contentChecked is true if the analysis fetched the changed file, contentAvailable is the result;
+	// the head version is fetched or the old one of a deleted file
+	contentChecked, contentAvailable bool
 }
 
 // ImpactAnalysis analyzes the potential impact of changes
@@ -202,7 +270,7 @@ type TestImpact struct {
 
 // AnalyzeChanges performs deep semantic analysis of code changes
 func (sa *SemanticAnalyzer) AnalyzeChanges(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff) (*SemanticAnalysisResult, error) {
-	log := sa.log.WithFields("file", fileDiff.NewPath, "project", request.ProjectID)
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath, "project", request.ProjectID)
 	log.Debug("starting semantic analysis")
 
 	result := &SemanticAnalysisResult{}
@@ -212,40 +280,51 @@ func (sa *SemanticAnalyzer) AnalyzeChanges(ctx context.Context, request model.Re
 	log.Debug("detected language", "language", language)
 
 	// Use language-specific analysis strategy
+	var err error
 	switch language {
 	case LanguageGo:
-		return sa.analyzeGoChanges(ctx, request, fileDiff, result)
+		result, err = sa.analyzeGoChanges(ctx, request, fileDiff, result)
 	case LanguageJavaScript, LanguageTypeScript:
-		return sa.analyzeJSChanges(ctx, request, fileDiff, result)
+		result, err = sa.analyzeJSChanges(ctx, request, fileDiff, result)
 	case LanguagePython:
-		return sa.analyzePythonChanges(ctx, request, fileDiff, result)
+		result, err = sa.analyzePythonChanges(ctx, request, fileDiff, result)
 	case LanguageJava:
-		return sa.analyzeJavaChanges(ctx, request, fileDiff, result)
+		result, err = sa.analyzeJavaChanges(ctx, request, fileDiff, result)
 	case LanguageRust:
-		return sa.analyzeRustChanges(ctx, request, fileDiff, result)
+		result, err = sa.analyzeRustChanges(ctx, request, fileDiff, result)
 	case LanguageC, LanguageCpp:
-		return sa.analyzeCChanges(ctx, request, fileDiff, result)
+		result, err = sa.analyzeCChanges(ctx, request, fileDiff, result)
 	default:
-		return sa.analyzeGenericChanges(ctx, request, fileDiff, result)
+		result, err = sa.analyzeGenericChanges(ctx, request, fileDiff, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Test impact is found by parsing both versions, so it doesn't depend on entities of diff patterns
+	testImpact, _, err := sa.testImpact.Analyze(ctx, request, fileDiff)
+	if err != nil {
+		log.Debug("failed to analyze test impact", "error", err)
 	}
+	result.ImpactAnalysis.TestImpact = testImpact
+
+	return result, nil
 }
 
 // analyzeGoChanges performs Go-specific semantic analysis
 func (sa *SemanticAnalyzer) analyzeGoChanges(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, result *SemanticAnalysisResult) (*SemanticAnalysisResult, error) {
-	log := sa.log.WithFields("file", fileDiff.NewPath, "language", "go")
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath, "language", "go")
 
-	// Parse the file to understand its structure using Go AST
-	beforeAST, afterAST, err := sa.parseFileVersions(ctx, request, fileDiff)
+	// Parse both file versions once for all analyzers, methods are paired between them by receiver type and name,
+	// fall back to diff patterns if the source can't be parsed
+	result.goFiles = parseGoVersions(ctx, sa.provider, request, fileDiff)
+	result.contentChecked, result.contentAvailable = true, result.goFiles.hasContent(fileDiff)
+	entities, err := result.goFiles.changedEntities(fileDiff, log)
 	if err != nil {
-		log.Warn("failed to parse Go AST, falling back to diff analysis", "error", err)
-		return sa.analyzeGenericChanges(ctx, request, fileDiff, result)
-	}
-
-	// Identify changed entities using AST comparison for Go
-	result.ChangedEntities, err = sa.identifyChangedEntities(beforeAST, afterAST, fileDiff)
-	if err != nil {
-		log.Warn("failed to identify changed entities", "error", err)
+		log.Debug("failed to parse source, falling back to diff patterns", "error", err)
+		entities = sa.extractEntitiesFromDiff(fileDiff)
 	}
+	result.ChangedEntities = entities
 
 	// Go-specific dependency analysis
 	err = sa.analyzeDependencies(ctx, request, result.ChangedEntities, fileDiff.NewPath)
@@ -262,6 +341,15 @@ func (sa *SemanticAnalyzer) analyzeGoChanges(ctx context.Context, request model.
 	// Perform impact analysis
 	result.ImpactAnalysis = sa.analyzeImpact(result.ChangedEntities)
 
+	// Changed values of exported constants are silent behavior changes
+	if constChanges := sa.analyzeConstantChanges(ctx, fileDiff, result.goFiles); len(constChanges) > 0 {
+		result.ImpactAnalysis.BreakingChanges = append(result.ImpactAnalysis.BreakingChanges, constantBreakingChanges(constChanges)...)
+		result.ImpactAnalysis.RiskLevel = "high"
+		if result.ImpactAnalysis.Scope == "local" {
+			result.ImpactAnalysis.Scope = "package"
+		}
+	}
+
 	// Determine business context
 	result.BusinessContext = sa.analyzeBusinessContext(fileDiff.NewPath, result.ChangedEntities)
 
@@ -280,10 +368,17 @@ func (sa *SemanticAnalyzer) analyzeGoChanges(ctx context.Context, request model.
 
 // analyzeJSChanges performs JavaScript/TypeScript-specific semantic analysis
 func (sa *SemanticAnalyzer) analyzeJSChanges(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, result *SemanticAnalysisResult) (*SemanticAnalysisResult, error) {
-	log := sa.log.WithFields("file", fileDiff.NewPath, "language", "javascript")
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath, "language", "javascript")
 
-	// Extract entities from diff using JS-specific patterns
-	result.ChangedEntities = sa.extractJSEntitiesFromDiff(fileDiff)
+	// Parse both file versions, fall back to JS-specific diff patterns if the source can't be parsed
+	entities, err := sa.extractEntitiesWithParser(ctx, request, fileDiff)
+	if err != nil {
+		log.Debug("failed to parse source, falling back to diff patterns", "error", err)
+		entities = sa.extractJSEntitiesFromDiff(fileDiff)
+	} else if !fileDiff.IsDeleted {
+		result.contentChecked, result.contentAvailable = true, true // the head version is parsed
+	}
+	result.ChangedEntities = entities
 
 	// Perform basic impact analysis
 	result.ImpactAnalysis = sa.analyzeImpact(result.ChangedEntities)
@@ -299,10 +394,17 @@ func (sa *SemanticAnalyzer) analyzeJSChanges(ctx context.Context, request model.
 
 // analyzePythonChanges performs Python-specific semantic analysis
 func (sa *SemanticAnalyzer) analyzePythonChanges(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, result *SemanticAnalysisResult) (*SemanticAnalysisResult, error) {
-	log := sa.log.WithFields("file", fileDiff.NewPath, "language", "python")
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath, "language", "python")
 
-	// Extract entities from diff using Python-specific patterns
-	result.ChangedEntities = sa.extractPythonEntitiesFromDiff(fileDiff)
+	// Parse both file versions, fall back to Python-specific diff patterns if the source can't be fetched
+	entities, err := sa.extractEntitiesWithParser(ctx, request, fileDiff)
+	if err != nil {
+		log.Debug("failed to parse source, falling back to diff patterns", "error", err)
+		entities = sa.extractPythonEntitiesFromDiff(fileDiff)
+	} else if !fileDiff.IsDeleted {
+		result.contentChecked, result.contentAvailable = true, true // the head version is parsed
+	}
+	result.ChangedEntities = entities
 
 	// Perform basic analysis
 	result.ImpactAnalysis = sa.analyzeImpact(result.ChangedEntities)
@@ -316,7 +418,7 @@ func (sa *SemanticAnalyzer) analyzePythonChanges(ctx context.Context, request mo
 
 // analyzeJavaChanges performs Java-specific semantic analysis
 func (sa *SemanticAnalyzer) analyzeJavaChanges(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, result *SemanticAnalysisResult) (*SemanticAnalysisResult, error) {
-	log := sa.log.WithFields("file", fileDiff.NewPath, "language", "java")
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath, "language", "java")
 
 	result.ChangedEntities = sa.extractJavaEntitiesFromDiff(fileDiff)
 	result.ImpactAnalysis = sa.analyzeImpact(result.ChangedEntities)
@@ -330,7 +432,7 @@ func (sa *SemanticAnalyzer) analyzeJavaChanges(ctx context.Context, request mode
 
 // analyzeRustChanges performs Rust-specific semantic analysis
 func (sa *SemanticAnalyzer) analyzeRustChanges(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, result *SemanticAnalysisResult) (*SemanticAnalysisResult, error) {
-	log := sa.log.WithFields("file", fileDiff.NewPath, "language", "rust")
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath, "language", "rust")
 
 	result.ChangedEntities = sa.extractRustEntitiesFromDiff(fileDiff)
 	result.ImpactAnalysis = sa.analyzeImpact(result.ChangedEntities)
@@ -344,7 +446,7 @@ func (sa *SemanticAnalyzer) analyzeRustChanges(ctx context.Context, request mode
 
 // analyzeCChanges performs C/C++-specific semantic analysis
 func (sa *SemanticAnalyzer) analyzeCChanges(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, result *SemanticAnalysisResult) (*SemanticAnalysisResult, error) {
-	log := sa.log.WithFields("file", fileDiff.NewPath, "language", "c")
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath, "language", "c")
 
 	result.ChangedEntities = sa.extractCEntitiesFromDiff(fileDiff)
 	result.ImpactAnalysis = sa.analyzeImpact(result.ChangedEntities)
@@ -358,7 +460,7 @@ func (sa *SemanticAnalyzer) analyzeCChanges(ctx context.Context, request model.R
 
 // analyzeGenericChanges performs generic analysis for unknown languages
 func (sa *SemanticAnalyzer) analyzeGenericChanges(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, result *SemanticAnalysisResult) (*SemanticAnalysisResult, error) {
-	log := sa.log.WithFields("file", fileDiff.NewPath, "language", "generic")
+	log := model.ContextLogger(ctx, sa.log).WithFields("file", fileDiff.NewPath, "language", "generic")
 
 	// Use basic diff analysis for unknown languages
 	result.ChangedEntities = sa.extractEntitiesFromDiff(fileDiff)
@@ -371,56 +473,161 @@ func (sa *SemanticAnalyzer) analyzeGenericChanges(ctx context.Context, request m
 	return result, nil
 }
 
-// parseFileVersions parses both the before and after versions of a file
-func (sa *SemanticAnalyzer) parseFileVersions(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff) (*ast.File, *ast.File, error) {
-	var beforeAST, afterAST *ast.File
-	var err error
+// analyzeConstantChanges returns exported constants of the Go file with changed values, new and deleted files have none
+func (sa *SemanticAnalyzer) analyzeConstantChanges(ctx context.Context, fileDiff *model.FileDiff, files *goFileVersions) []ConstantChange {
+	if fileDiff.IsNew || fileDiff.IsDeleted {
+		return nil
+	}
+	if files.before == nil || files.after == nil {
+		model.ContextLogger(ctx, sa.log).Debug("file versions are not parsed, skipping constant changes", "file", fileDiff.NewPath,
+			"old_error", files.beforeErr, "new_error", files.afterErr)
+		return nil
+	}
+	return findGoConstantChanges(files.before, files.after)
+}
 
-	fset := token.NewFileSet()
+// extractEntitiesWithParser parses both versions of the file with the language analyzer
+// and returns entities that contain lines changed in the diff
+func (sa *SemanticAnalyzer) extractEntitiesWithParser(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff) ([]ChangedEntity, error) {
+	language := sa.detectLanguage(fileDiff.NewPath)
+	analyzer, ok := sa.analyzers[language]
+	if !ok {
+		return nil, fmt.Errorf("no parser for language %s", language)
+	}
+	return parseChangedEntities(ctx, sa.provider, analyzer, request, fileDiff, sa.log)
+}
 
-	// Parse before version (if file is not new)
+// parseChangedEntities parses both versions of the file with the analyzer and returns entities
+// that contain lines changed in the diff, the head version is taken from the MR commit
+func parseChangedEntities(ctx context.Context, provider interfaces.CodeProvider, analyzer LanguageAnalyzer, request model.ReviewRequest, fileDiff *model.FileDiff, log logze.Logger) ([]ChangedEntity, error) {
+	var before, after []ChangedEntity
+	var beforeErr, afterErr error
+	if !fileDiff.IsDeleted {
+		content, err := FetchFileContent(ctx, provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file content: %w", err)
+		}
+		after, afterErr = analyzer.ParseEntities(content)
+	}
 	if !fileDiff.IsNew {
-		beforeContent, contentErr := sa.getFileContent(ctx, request, fileDiff.OldPath, request.MergeRequest.TargetBranch)
-		if contentErr == nil {
-			beforeAST, err = parser.ParseFile(fset, fileDiff.OldPath, beforeContent, parser.ParseComments)
-			if err != nil {
-				sa.log.Warn("failed to parse before version", "error", err)
-			}
+		var content string
+		content, beforeErr = FetchFileContent(ctx, provider, request.ProjectID, fileDiff.OldPath, request.MergeRequest.TargetBranch)
+		if beforeErr == nil {
+			before, beforeErr = analyzer.ParseEntities(content)
 		}
 	}
 
-	// Parse after version (if file is not deleted)
-	if !fileDiff.IsDeleted {
-		afterContent, contentErr := sa.getFileContent(ctx, request, fileDiff.NewPath, request.MergeRequest.SHA)
-		if contentErr == nil {
-			afterAST, err = parser.ParseFile(fset, fileDiff.NewPath, afterContent, parser.ParseComments)
-			if err != nil {
-				sa.log.Warn("failed to parse after version", "error", err)
-			}
+	return selectChangedEntities(fileDiff, before, beforeErr, after, afterErr, log)
+}
+
+// changedEntities returns entities of the parsed versions that contain lines changed in the diff
+func (v *goFileVersions) changedEntities(fileDiff *model.FileDiff, log logze.Logger) ([]ChangedEntity, error) {
+	var before, after []ChangedEntity
+	if v.after != nil {
+		after = goEntities(v.after)
+	}
+	if v.before != nil {
+		before = goEntities(v.before)
+	}
+	return selectChangedEntities(fileDiff, before, v.beforeErr, after, v.afterErr, log)
+}
+
+// selectChangedEntities returns entities of both versions that contain lines changed in the diff; the new version
+// must be parsed, the old one is required only for deleted files
+func selectChangedEntities(fileDiff *model.FileDiff, before []ChangedEntity, beforeErr error, after []ChangedEntity, afterErr error, log logze.Logger) ([]ChangedEntity, error) {
+	if afterErr != nil {
+		return nil, fmt.Errorf("failed to parse after version: %w", afterErr)
+	}
+
+	beforeParsed := false
+	if !fileDiff.IsNew {
+		switch {
+		case beforeErr == nil:
+			beforeParsed = true
+		case fileDiff.IsDeleted:
+			return nil, fmt.Errorf("failed to parse before version: %w", beforeErr)
+		default:
+			log.Debug("failed to parse before version", "file", fileDiff.OldPath, "error", beforeErr)
 		}
 	}
 
-	return beforeAST, afterAST, nil
+	added, removed := parseChangedLineNumbers(fileDiff.Diff)
+
+	return matchChangedEntities(before, after, added, removed, beforeParsed || fileDiff.IsNew), nil
 }
 
-// getFileContent retrieves file content with fallback strategies
-func (sa *SemanticAnalyzer) getFileContent(ctx context.Context, request model.ReviewRequest, filePath, ref string) (string, error) {
-	content, err := sa.provider.GetFileContent(ctx, request.ProjectID, filePath, ref)
-	if err != nil {
-		return "", fmt.Errorf("failed to get file content: %w", err)
+// matchChangedEntities selects entities that contain added or removed lines and sets their change type,
+// entities are matched between versions by type and full name
+func matchChangedEntities(before, after []ChangedEntity, added, removed map[int]struct{}, knownBefore bool) []ChangedEntity {
+	beforeByKey := make(map[string]ChangedEntity, len(before))
+	for _, entity := range before {
+		beforeByKey[entityKey(entity)] = entity
 	}
-	return content, nil
-}
 
-// identifyChangedEntities compares ASTs to identify what entities have changed
-func (sa *SemanticAnalyzer) identifyChangedEntities(beforeAST, afterAST *ast.File, fileDiff *model.FileDiff) ([]ChangedEntity, error) {
 	var entities []ChangedEntity
+	afterKeys := make(map[string]struct{}, len(after))
+	for _, entity := range after {
+		key := entityKey(entity)
+		afterKeys[key] = struct{}{}
+
+		old, existed := beforeByKey[key]
+		if !containsAnyLine(entity, added) && !(existed && containsAnyLine(old, removed)) {
+			continue
+		}
+
+		switch {
+		case existed:
+			entity.ChangeType = ChangeTypeModified
+			entity.BeforeCode = old.AfterCode
+		case knownBefore:
+			entity.ChangeType = ChangeTypeAdded
+		default:
+			entity.ChangeType = ChangeTypeModified
+		}
+		entities = append(entities, entity)
+	}
+
+	for _, entity := range before {
+		if _, ok := afterKeys[entityKey(entity)]; ok || !containsAnyLine(entity, removed) {
+			continue
+		}
+		entity.ChangeType = ChangeTypeDeleted
+		entity.BeforeCode, entity.AfterCode = entity.AfterCode, ""
+		entities = append(entities, entity)
+	}
+
+	return entities
+}
+
+func entityKey(entity ChangedEntity) string {
+	return string(entity.Type) + ":" + entity.FullName
+}
+
+func containsAnyLine(entity ChangedEntity, lines map[int]struct{}) bool {
+	for line := entity.StartLine; line <= entity.EndLine; line++ {
+		if _, ok := lines[line]; ok {
+			return true
+		}
+	}
+	return false
+}
 
-	// For now, implement a basic version that analyzes the diff
-	// In a more sophisticated version, we would compare the ASTs directly
-	entities = sa.extractEntitiesFromDiff(fileDiff)
+// parseChangedLineNumbers returns numbers of added lines in the new file and removed lines in the old file
+func parseChangedLineNumbers(diff string) (map[int]struct{}, map[int]struct{}) {
+	added := make(map[int]struct{})
+	removed := make(map[int]struct{})
+	for _, hunk := range model.ParseHunks(diff) {
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case model.DiffLineAdded:
+				added[line.NewLine] = struct{}{}
+			case model.DiffLineRemoved:
+				removed[line.OldLine] = struct{}{}
+			}
+		}
+	}
 
-	return entities, nil
+	return added, removed
 }
 
 // extractEntitiesFromDiff extracts entities from diff analysis (basic implementation)
@@ -429,6 +636,7 @@ func (sa *SemanticAnalyzer) extractEntitiesFromDiff(fileDiff *model.FileDiff) []
 
 	// Parse diff lines to identify function/type changes
 	lines := strings.Split(fileDiff.Diff, "\n")
+	language := detectLanguage(fileDiff.NewPath)
 	currentEntity := &ChangedEntity{}
 	inEntity := false
 
@@ -448,8 +656,16 @@ func (sa *SemanticAnalyzer) extractEntitiesFromDiff(fileDiff *model.FileDiff) []
 			// Extract function name and signature
 			funcInfo := sa.parseFunctionSignature(line)
 			currentEntity.Name = funcInfo.Name
+			currentEntity.FullName = funcInfo.Name
 			currentEntity.Signature = funcInfo.Signature
-			currentEntity.IsExported = sa.isExported(funcInfo.Name)
+			currentEntity.IsExported = isExported(language, funcInfo.Name, line)
+			if funcInfo.Receiver != "" {
+				recv := receiverTypeOf(funcInfo.Receiver)
+				currentEntity.Type = EntityTypeMethod
+				currentEntity.Receiver = funcInfo.Receiver
+				currentEntity.FullName = recv + "." + funcInfo.Name
+				currentEntity.IsExported = currentEntity.IsExported && isExported(language, recv, line)
+			}
 
 			if strings.HasPrefix(line, "+") {
 				currentEntity.AfterCode = strings.TrimPrefix(line, "+")
@@ -472,7 +688,7 @@ func (sa *SemanticAnalyzer) extractEntitiesFromDiff(fileDiff *model.FileDiff) []
 			// Extract type name
 			typeInfo := sa.parseTypeDefinition(line)
 			currentEntity.Name = typeInfo.Name
-			currentEntity.IsExported = sa.isExported(typeInfo.Name)
+			currentEntity.IsExported = isExported(language, typeInfo.Name, line)
 
 			if strings.HasPrefix(line, "+") {
 				currentEntity.AfterCode = strings.TrimPrefix(line, "+")
@@ -530,13 +746,14 @@ func (sa *SemanticAnalyzer) parseFunctionSignature(line string) FunctionInfo {
 	cleanLine := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(line, "+"), "-"))
 
 	// Basic function parsing (can be improved with proper AST parsing)
-	funcRegex := regexp.MustCompile(`func\s*(?:\([^)]*\))?\s*([A-Za-z_][A-Za-z0-9_]*)\s*\([^)]*\)(?:\s*[^{]*)?`)
+	funcRegex := regexp.MustCompile(`func\s*(?:\(([^)]*)\))?\s*([A-Za-z_][A-Za-z0-9_]*)\s*(?:\[[^\]]*\])?\s*\([^)]*\)(?:\s*[^{]*)?`)
 	matches := funcRegex.FindStringSubmatch(cleanLine)
 
-	if len(matches) >= 2 {
+	if len(matches) >= 3 {
 		return FunctionInfo{
-			Name:      matches[1],
+			Name:      matches[2],
 			Signature: cleanLine,
+			Receiver:  strings.TrimSpace(matches[1]),
 		}
 	}
 
@@ -570,13 +787,6 @@ func (sa *SemanticAnalyzer) getChangeTypeFromLine(line string) ChangeType {
 	return ChangeTypeModified
 }
 
-func (sa *SemanticAnalyzer) isExported(name string) bool {
-	if len(name) == 0 {
-		return false
-	}
-	return name[0] >= 'A' && name[0] <= 'Z'
-}
-
 // analyzeDependencies finds what each changed entity depends on
 func (sa *SemanticAnalyzer) analyzeDependencies(ctx context.Context, request model.ReviewRequest, entities []ChangedEntity, filePath string) error {
 	// For each entity, analyze its dependencies
@@ -584,41 +794,38 @@ func (sa *SemanticAnalyzer) analyzeDependencies(ctx context.Context, request mod
 		entity := &entities[i]
 
 		// Analyze code to find dependencies
-		dependencies := sa.extractDependenciesFromCode(entity.AfterCode)
+		dependencies := sa.extractDependenciesFromCode(entity.AfterCode, detectLanguage(filePath))
 		entity.Dependencies = dependencies
 	}
 
 	return nil
 }
 
-// analyzeDependents finds what depends on each changed entity
+// analyzeDependents finds unchanged functions calling each changed function or method
 func (sa *SemanticAnalyzer) analyzeDependents(ctx context.Context, request model.ReviewRequest, entities []ChangedEntity, filePath string) error {
-	// This would require searching the entire codebase for references
-	// For now, implement a basic version
-	for i := range entities {
-		entity := &entities[i]
-
-		// Find dependents by searching for usage patterns
-		dependents, err := sa.findDependents(ctx, request, entity.Name, filepath.Dir(filePath))
-		if err != nil {
-			sa.log.Warn("failed to find dependents", "entity", entity.Name, "error", err)
-			continue
-		}
+	files, err := loadCallerFiles(ctx, sa.provider, request, filePath)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
 
-		entity.Dependents = dependents
+	for i := range entities {
+		entities[i].Dependents = findGoCallers(files, entities[i], filepath.Dir(filePath))
 	}
 
 	return nil
 }
 
-// extractDependenciesFromCode analyzes code to find its dependencies
-func (sa *SemanticAnalyzer) extractDependenciesFromCode(code string) []Dependency {
+// extractDependenciesFromCode analyzes code to find its dependencies, comments and string literals are skipped
+func (sa *SemanticAnalyzer) extractDependenciesFromCode(code string, language SupportedLanguage) []Dependency {
 	var dependencies []Dependency
 
 	// Look for function calls, type usage, etc.
 	// This is a simplified implementation
-	lines := strings.Split(code, "\n")
-	for _, line := range lines {
+	originalLines := strings.Split(code, "\n")
+	for i, line := range strings.Split(StripCode(code, language), "\n") {
 		line = strings.TrimSpace(line)
 
 		// Look for function calls (basic pattern)
@@ -631,7 +838,7 @@ func (sa *SemanticAnalyzer) extractDependenciesFromCode(code string) []Dependenc
 					Name:         match[1],
 					Type:         EntityTypeFunction,
 					UsageContext: "function_call",
-					CodeSnippet:  line,
+					CodeSnippet:  strings.TrimSpace(originalLines[i]),
 				})
 			}
 		}
@@ -640,18 +847,6 @@ func (sa *SemanticAnalyzer) extractDependenciesFromCode(code string) []Dependenc
 	return dependencies
 }
 
-// findDependents searches for entities that depend on the given entity
-func (sa *SemanticAnalyzer) findDependents(ctx context.Context, request model.ReviewRequest, entityName, packagePath string) ([]Dependent, error) {
-	var dependents []Dependent
-
-	// This is a simplified implementation - in practice, we'd want to:
-	// 1. Search through Go files in the package
-	// 2. Look for references to the entity
-	// 3. Analyze imports and usage patterns
-
-	return dependents, nil
-}
-
 // analyzeImpact determines the impact scope and risk level of changes
 func (sa *SemanticAnalyzer) analyzeImpact(entities []ChangedEntity) ImpactAnalysis {
 	impact := ImpactAnalysis{
@@ -668,10 +863,11 @@ func (sa *SemanticAnalyzer) analyzeImpact(entities []ChangedEntity) ImpactAnalys
 			hasExportedChanges = true
 			if entity.ChangeType == ChangeTypeDeleted || sa.isSignatureChange(entity) {
 				hasBreakingChanges = true
+				name := qualifiedEntityName(entity)
 				impact.BreakingChanges = append(impact.BreakingChanges, BreakingChange{
 					Type:        "signature",
-					Entity:      entity.Name,
-					Description: fmt.Sprintf("%s %s has breaking changes", entity.Type, entity.Name),
+					Entity:      name,
+					Description: fmt.Sprintf("%s %s has breaking changes", entity.Type, name),
 				})
 			}
 		}
@@ -792,6 +988,10 @@ func (sa *SemanticAnalyzer) analyzeProjectPatterns(ctx context.Context, request
 
 // detectLanguage detects programming language from file path
 func (sa *SemanticAnalyzer) detectLanguage(filePath string) SupportedLanguage {
+	return detectLanguage(filePath)
+}
+
+func detectLanguage(filePath string) SupportedLanguage {
 	ext := strings.ToLower(filepath.Ext(filePath))
 
 	switch ext {
@@ -933,21 +1133,24 @@ func (sa *SemanticAnalyzer) extractCEntitiesFromDiff(fileDiff *model.FileDiff) [
 	return entities
 }
 
-// extractEntitiesWithPatterns is a helper method to extract entities using regex patterns
+// extractEntitiesWithPatterns is a helper method to extract entities using regex patterns,
+// visibility is checked on the diff line declaring the name
 func (sa *SemanticAnalyzer) extractEntitiesWithPatterns(fileDiff *model.FileDiff, patterns []string, entityType EntityType) []ChangedEntity {
 	var entities []ChangedEntity
+	language := detectLanguage(fileDiff.NewPath)
 
 	for _, pattern := range patterns {
 		regex := regexp.MustCompile(pattern)
-		matches := regex.FindAllStringSubmatch(fileDiff.Diff, -1)
+		matches := regex.FindAllStringSubmatchIndex(fileDiff.Diff, -1)
 
 		for _, match := range matches {
-			if len(match) > 1 {
+			if len(match) > 3 && match[2] >= 0 {
+				name := fileDiff.Diff[match[2]:match[3]]
 				entity := ChangedEntity{
 					Type:       entityType,
-					Name:       match[1],
+					Name:       name,
 					ChangeType: ChangeTypeModified, // Default assumption
-					IsExported: sa.isExported(match[1]),
+					IsExported: isExported(language, name, lineAt(fileDiff.Diff, match[2])),
 				}
 				entities = append(entities, entity)
 			}
@@ -957,6 +1160,16 @@ func (sa *SemanticAnalyzer) extractEntitiesWithPatterns(fileDiff *model.FileDiff
 	return entities
 }
 
+// lineAt returns the line of the text containing the byte offset
+func lineAt(text string, offset int) string {
+	start := strings.LastIndexByte(text[:offset], '\n') + 1
+	end := strings.IndexByte(text[offset:], '\n')
+	if end < 0 {
+		return text[start:]
+	}
+	return text[start : offset+end]
+}
+
 // Language-specific project pattern analysis methods
 
 // analyzeGoProjectPatterns analyzes Go-specific project patterns
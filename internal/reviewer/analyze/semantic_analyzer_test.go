@@ -0,0 +1,94 @@
+package analyze
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/provider/local"
+)
+
+func TestAnalyzeChangesSameMethodName(t *testing.T) {
+	const before = "package res\n\ntype A struct{}\n\nfunc (a *A) Close() error {\n\treturn nil\n}\n\n" +
+		"type B struct{}\n\nfunc (b *B) Close() error {\n\treturn nil\n}\n"
+
+	// entity is a part of the changed entity checked by tests
+	type entity struct {
+		FullName   string
+		Receiver   string
+		ChangeType ChangeType
+	}
+	tests := []struct {
+		name         string
+		diff         string
+		wantEntities []entity
+		wantBreaking []string
+		wantGraph    []string
+	}{
+		{
+			name: "method of the second type",
+			diff: "--- a/res/res.go\n+++ b/res/res.go\n@@ -11,3 +11,3 @@\n" +
+				"-func (b *B) Close() error {\n+func (b *B) Close(force bool) error {\n \treturn nil\n }\n",
+			wantEntities: []entity{{"B.Close", "b *B", ChangeTypeModified}},
+			wantBreaking: []string{"B.Close"},
+			wantGraph:    []string{"res.method.B.Close"},
+		},
+		{
+			name: "methods of both types",
+			diff: "--- a/res/res.go\n+++ b/res/res.go\n@@ -5,3 +5,3 @@\n" +
+				"-func (a *A) Close() error {\n+func (a *A) Close(force bool) error {\n \treturn nil\n }\n" +
+				"@@ -11,3 +11,3 @@\n" +
+				"-func (b *B) Close() error {\n+func (b *B) Close(force bool) error {\n \treturn nil\n }\n",
+			wantEntities: []entity{{"A.Close", "a *A", ChangeTypeModified}, {"B.Close", "b *B", ChangeTypeModified}},
+			wantBreaking: []string{"A.Close", "B.Close"},
+			wantGraph:    []string{"res.method.A.Close", "res.method.B.Close"},
+		},
+		{
+			name: "removed method of the first type",
+			diff: "--- a/res/res.go\n+++ b/res/res.go\n@@ -4,5 +4,1 @@\n" +
+				" \n-func (a *A) Close() error {\n-\treturn nil\n-}\n-\n",
+			wantEntities: []entity{{"A.Close", "a *A", ChangeTypeDeleted}},
+			wantBreaking: []string{"A.Close"},
+			wantGraph:    []string{"res.method.A.Close"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := model.ParseUnifiedDiff(tt.diff)
+			provider := local.New("", diffs, map[string]string{"res/res.go": before})
+			request := model.ReviewRequest{ProjectID: "local", MergeRequest: provider.MergeRequest(), Changes: diffs}
+			ctx := context.Background()
+
+			analysis, err := NewSemanticAnalyzer(provider).AnalyzeChanges(ctx, request, diffs[0])
+			if err != nil {
+				t.Fatalf("AnalyzeChanges() error = %v", err)
+			}
+			entities := make([]entity, 0, len(analysis.ChangedEntities))
+			for _, e := range analysis.ChangedEntities {
+				entities = append(entities, entity{e.FullName, e.Receiver, e.ChangeType})
+			}
+			if !slices.Equal(entities, tt.wantEntities) {
+				t.Errorf("ChangedEntities = %+v, want %+v", entities, tt.wantEntities)
+			}
+
+			var breaking []string
+			for _, change := range analysis.ImpactAnalysis.BreakingChanges {
+				breaking = append(breaking, change.Entity)
+			}
+			if !slices.Equal(breaking, tt.wantBreaking) {
+				t.Errorf("BreakingChanges = %v, want %v", breaking, tt.wantBreaking)
+			}
+
+			graph, err := NewDependencyMapper(provider).MapDependencies(ctx, request, analysis.ChangedEntities, diffs[0].NewPath)
+			if err != nil {
+				t.Fatalf("MapDependencies() error = %v", err)
+			}
+			if ids := slices.Sorted(maps.Keys(graph.Entities)); !slices.Equal(ids, tt.wantGraph) {
+				t.Errorf("graph entities = %v, want %v", ids, tt.wantGraph)
+			}
+		})
+	}
+}
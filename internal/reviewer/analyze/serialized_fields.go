@@ -0,0 +1,243 @@
+package analyze
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SerializedFieldChangeType represents the category of a change of a serialized struct field
+type SerializedFieldChangeType string
+
+const (
+	SerializedFieldRemoved     SerializedFieldChangeType = "removed"
+	SerializedFieldRenamed     SerializedFieldChangeType = "renamed"
+	SerializedFieldTypeChanged SerializedFieldChangeType = "type_changed"
+)
+
+// serializationTagKeys are keys of struct tags naming fields in serialized data
+var serializationTagKeys = []string{"json", "yaml", "protobuf"}
+
+// SerializedFieldChange is a change of a field of a Go struct with serialization tags that breaks
+// consumers of the serialized data: the field is removed, its serialized name or type is changed
+type SerializedFieldChange struct {
+	Type   SerializedFieldChangeType
+	Struct string
+	Field  string
+	// OldTag and NewTag are serialization parts of tags like `json:"user_id"`, NewTag is empty for removed fields
+	OldTag string
+	NewTag string
+	// OldType and NewType are source types of the field, NewType is empty for removed fields
+	OldType string
+	NewType string
+	// Line is the line of the field in the new version, the line of the struct for removed fields
+	Line int
+}
+
+// goStructField is an exported named field of a struct with its serialization tags
+type goStructField struct {
+	name     string
+	typeExpr string
+	tags     map[string]string // values by key of serializationTagKeys
+	line     int
+}
+
+// goSerializedStruct is a struct type of a file with at least one serialization tag
+type goSerializedStruct struct {
+	fields []goStructField
+	line   int
+}
+
+// FindSerializedFieldChanges compares structs with json, yaml or protobuf tags of two versions of a Go file:
+// fields removed from a kept struct, fields whose serialized name changed (the json or yaml name, the protobuf
+// field number, wire type or name) and fields whose type changed are returned; a field renamed in Go with
+// the same serialized names is the same field, structs without serialization tags are skipped
+func FindSerializedFieldChanges(before, after string) ([]SerializedFieldChange, error) {
+	oldStructs, err := goSerializedStructs(before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old version: %w", err)
+	}
+	newStructs, err := goSerializedStructs(after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new version: %w", err)
+	}
+
+	var changes []SerializedFieldChange
+	for structName, oldStruct := range oldStructs {
+		newStruct, ok := newStructs[structName]
+		if !ok {
+			continue // removed and renamed types are breaking changes of the package API
+		}
+
+		for _, oldField := range oldStruct.fields {
+			if len(oldField.tags) == 0 {
+				continue
+			}
+			change := SerializedFieldChange{
+				Struct:  structName,
+				Field:   oldField.name,
+				OldTag:  formatSerializationTags(oldField.tags),
+				OldType: oldField.typeExpr,
+			}
+
+			newField, ok := findSerializedField(newStruct.fields, oldField)
+			if !ok {
+				change.Type = SerializedFieldRemoved
+				change.Line = newStruct.line
+				changes = append(changes, change)
+				continue
+			}
+			change.NewTag = formatSerializationTags(newField.tags)
+			change.NewType = newField.typeExpr
+			change.Line = newField.line
+
+			switch {
+			case !sameSerializedNames(oldField.tags, newField.tags):
+				change.Type = SerializedFieldRenamed
+			case oldField.typeExpr != newField.typeExpr:
+				change.Type = SerializedFieldTypeChanged
+			default:
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b SerializedFieldChange) int {
+		return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Struct, b.Struct), cmp.Compare(a.Field, b.Field))
+	})
+	return changes, nil
+}
+
+// goSerializedStructs returns struct types of the file with at least one serialization tag by name
+func goSerializedStructs(content string) (map[string]goSerializedStruct, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	structs := make(map[string]goSerializedStruct)
+	ast.Inspect(file, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		structType, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+
+		item := goSerializedStruct{line: fset.Position(spec.Pos()).Line}
+		tagged := false
+		for _, field := range structType.Fields.List {
+			tags := serializationTags(field.Tag)
+			tagged = tagged || len(tags) > 0
+			// Embedded fields are flattened into the parent, their fields are checked in their own structs
+			for _, name := range field.Names {
+				if !name.IsExported() {
+					continue
+				}
+				item.fields = append(item.fields, goStructField{
+					name:     name.Name,
+					typeExpr: types.ExprString(field.Type),
+					tags:     tags,
+					line:     fset.Position(name.Pos()).Line,
+				})
+			}
+		}
+		if tagged {
+			structs[spec.Name.Name] = item
+		}
+		return true
+	})
+
+	return structs, nil
+}
+
+// serializationTags returns values of serialization keys of the struct tag
+func serializationTags(tag *ast.BasicLit) map[string]string {
+	if tag == nil {
+		return nil
+	}
+	raw, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return nil
+	}
+	var tags map[string]string
+	for _, key := range serializationTagKeys {
+		if value, ok := reflect.StructTag(raw).Lookup(key); ok {
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			tags[key] = value
+		}
+	}
+	return tags
+}
+
+// findSerializedField returns the field of the new struct with the name of the old field, or a field with another
+// name and the same serialized names, so renaming the Go field only doesn't count
+func findSerializedField(fields []goStructField, old goStructField) (goStructField, bool) {
+	for _, field := range fields {
+		if field.name == old.name {
+			return field, true
+		}
+	}
+	for _, name := range old.tags {
+		if name == "" || strings.HasPrefix(name, ",") {
+			return goStructField{}, false // the default name is the Go name, it is changed
+		}
+	}
+	for _, field := range fields {
+		if len(field.tags) > 0 && sameSerializedNames(old.tags, field.tags) {
+			return field, true
+		}
+	}
+	return goStructField{}, false
+}
+
+// sameSerializedNames checks that every serialization tag of the old field names the field the same way in the new one
+func sameSerializedNames(oldTags, newTags map[string]string) bool {
+	for key, oldValue := range oldTags {
+		if serializedName(key, oldValue) != serializedName(key, newTags[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// serializedName returns the part of the tag value identifying the field in serialized data: the name of json
+// and yaml tags without options, the wire type, the field number and the name of protobuf tags
+func serializedName(key, value string) string {
+	if key != "protobuf" {
+		name, _, _ := strings.Cut(value, ",")
+		return name
+	}
+	parts := strings.Split(value, ",")
+	identity := make([]string, 0, 3)
+	for i, part := range parts {
+		if i < 2 || strings.HasPrefix(part, "name=") {
+			identity = append(identity, part)
+		}
+	}
+	return strings.Join(identity, ",")
+}
+
+// formatSerializationTags renders tags like `json:"id,omitempty" yaml:"id"` in the order of serializationTagKeys
+func formatSerializationTags(tags map[string]string) string {
+	parts := make([]string, 0, len(tags))
+	for _, key := range serializationTagKeys {
+		if value, ok := tags[key]; ok {
+			parts = append(parts, fmt.Sprintf("%s:%q", key, value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
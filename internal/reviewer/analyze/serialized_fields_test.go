@@ -0,0 +1,99 @@
+package analyze
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindSerializedFieldChanges(t *testing.T) {
+	const before = "package p\n\ntype User struct {\n" +
+		"\tID    int    `json:\"id\"`\n" +
+		"\tName  string `json:\"name,omitempty\" yaml:\"name\"`\n" +
+		"\tEmail string `json:\"email\"`\n" +
+		"\tnote  string\n}\n"
+
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []SerializedFieldChange
+	}{
+		{
+			name:   "renamed tag",
+			before: before,
+			after: "package p\n\ntype User struct {\n" +
+				"\tID    int    `json:\"user_id\"`\n" +
+				"\tName  string `json:\"name,omitempty\" yaml:\"name\"`\n" +
+				"\tEmail string `json:\"email\"`\n" +
+				"\tnote  string\n}\n",
+			want: []SerializedFieldChange{{
+				Type: SerializedFieldRenamed, Struct: "User", Field: "ID", OldTag: `json:"id"`, NewTag: `json:"user_id"`,
+				OldType: "int", NewType: "int", Line: 4,
+			}},
+		},
+		{
+			name:   "renamed go field with the same tag",
+			before: before,
+			after: "package p\n\ntype User struct {\n" +
+				"\tUserID int    `json:\"id\"`\n" +
+				"\tName   string `json:\"name\" yaml:\"name\"`\n" +
+				"\tEmail  string `json:\"email\"`\n" +
+				"\tnotes  string\n}\n",
+		},
+		{
+			name:   "removed field",
+			before: before,
+			after: "package p\n\ntype User struct {\n" +
+				"\tID   int    `json:\"id\"`\n" +
+				"\tName string `json:\"name,omitempty\" yaml:\"name\"`\n}\n",
+			want: []SerializedFieldChange{{
+				Type: SerializedFieldRemoved, Struct: "User", Field: "Email", OldTag: `json:"email"`, OldType: "string", Line: 3,
+			}},
+		},
+		{
+			name:   "changed type and yaml name",
+			before: before,
+			after: "package p\n\ntype User struct {\n" +
+				"\tID    string `json:\"id\"`\n" +
+				"\tName  string `json:\"name,omitempty\" yaml:\"full_name\"`\n" +
+				"\tEmail string `json:\"email\"`\n}\n",
+			want: []SerializedFieldChange{
+				{
+					Type: SerializedFieldTypeChanged, Struct: "User", Field: "ID", OldTag: `json:"id"`, NewTag: `json:"id"`,
+					OldType: "int", NewType: "string", Line: 4,
+				},
+				{
+					Type: SerializedFieldRenamed, Struct: "User", Field: "Name", OldTag: `json:"name,omitempty" yaml:"name"`,
+					NewTag: `json:"name,omitempty" yaml:"full_name"`, OldType: "string", NewType: "string", Line: 5,
+				},
+			},
+		},
+		{
+			name:   "protobuf field number",
+			before: "package p\n\ntype Msg struct {\n\tId int64 `protobuf:\"varint,1,opt,name=id,proto3\" json:\"id,omitempty\"`\n}\n",
+			after:  "package p\n\ntype Msg struct {\n\tId int64 `protobuf:\"varint,2,opt,name=id,proto3\" json:\"id,omitempty\"`\n}\n",
+			want: []SerializedFieldChange{{
+				Type: SerializedFieldRenamed, Struct: "Msg", Field: "Id",
+				OldTag: `json:"id,omitempty" protobuf:"varint,1,opt,name=id,proto3"`, NewTag: `json:"id,omitempty" protobuf:"varint,2,opt,name=id,proto3"`,
+				OldType: "int64", NewType: "int64", Line: 4,
+			}},
+		},
+		{
+			name:   "struct without serialization tags",
+			before: "package p\n\ntype Options struct {\n\tLimit int `default:\"10\"`\n\tName  string\n}\n",
+			after:  "package p\n\ntype Options struct {\n\tLimit string `default:\"10\"`\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindSerializedFieldChanges(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("FindSerializedFieldChanges() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FindSerializedFieldChanges() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package analyze
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultSuppressionDirective is the word of inline comments suppressing findings like `// codry:ignore performance`
+const DefaultSuppressionDirective = "codry:ignore"
+
+// DefaultSuppressionCommentMarkers start comments of most languages: C-like, shell and Python, SQL and Lua
+var DefaultSuppressionCommentMarkers = []string{"//", "#", "--"}
+
+// Suppression is a set of findings suppressed on a line
+type Suppression struct {
+	// All is true for directives without issue types or with "all"
+	All bool
+	// IssueTypes are suppressed issue types in lower case like "performance"
+	IssueTypes []string
+}
+
+// Matches checks if the suppression covers findings of the issue type
+func (s Suppression) Matches(issueType string) bool {
+	if s.All {
+		return true
+	}
+	for _, suppressed := range s.IssueTypes {
+		if strings.EqualFold(suppressed, issueType) {
+			return true
+		}
+	}
+	return false
+}
+
+// SuppressionSyntax finds inline suppression directives in file content, the syntax doesn't depend on the language:
+// a comment marker, the directive and an optional comma-separated list of issue types, then an optional reason
+type SuppressionSyntax struct {
+	regex *regexp.Regexp
+}
+
+// NewSuppressionSyntax creates the syntax of the directive like "codry:ignore" after one of the comment markers
+func NewSuppressionSyntax(directive string, commentMarkers []string) *SuppressionSyntax {
+	markers := make([]string, 0, len(commentMarkers))
+	for _, marker := range commentMarkers {
+		markers = append(markers, regexp.QuoteMeta(marker))
+	}
+	return &SuppressionSyntax{
+		regex: regexp.MustCompile(`(?:` + strings.Join(markers, "|") + `)[ \t]*` + regexp.QuoteMeta(directive) +
+			`(?:[ \t]+([\w-]+(?:[ \t]*,[ \t]*[\w-]+)*))?(?:[ \t]|$)`),
+	}
+}
+
+// Parse returns suppressions by line of the content: a directive after code suppresses findings of its line,
+// a directive alone on a line suppresses findings of the next line
+func (s *SuppressionSyntax) Parse(content string) map[int]Suppression {
+	suppressions := make(map[int]Suppression)
+	for i, line := range strings.Split(content, "\n") {
+		match := s.regex.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+
+		suppression := Suppression{All: match[2] == -1}
+		if !suppression.All {
+			for _, issueType := range strings.Split(line[match[2]:match[3]], ",") {
+				issueType = strings.ToLower(strings.TrimSpace(issueType))
+				suppression.All = suppression.All || issueType == "all"
+				suppression.IssueTypes = append(suppression.IssueTypes, issueType)
+			}
+		}
+
+		lineNumber := i + 1
+		if strings.TrimSpace(line[:match[0]]) == "" {
+			lineNumber++
+		}
+		existing := suppressions[lineNumber]
+		suppressions[lineNumber] = Suppression{
+			All:        existing.All || suppression.All,
+			IssueTypes: append(existing.IssueTypes, suppression.IssueTypes...),
+		}
+	}
+	return suppressions
+}
@@ -0,0 +1,163 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// DefaultTestFileConventions are test file names of a source file by language: {name} is the source file
+// name without extension and {ext} is its extension, paths are relative to the directory of the source file
+// or to the repository root if they start with a slash
+var DefaultTestFileConventions = map[string][]string{
+	string(LanguageGo):         {"{name}_test.go"},
+	string(LanguagePython):     {"test_{name}.py", "{name}_test.py", "tests/test_{name}.py", "/tests/test_{name}.py"},
+	string(LanguageJavaScript): {"{name}.test{ext}", "{name}.spec{ext}", "__tests__/{name}.test{ext}"},
+	string(LanguageTypeScript): {"{name}.test{ext}", "{name}.spec{ext}", "__tests__/{name}.test{ext}"},
+}
+
+// testableEntityTypes are entities expected to be referenced in tests, consts and vars are tested through them
+var testableEntityTypes = map[EntityType]bool{
+	EntityTypeFunction:  true,
+	EntityTypeMethod:    true,
+	EntityTypeType:      true,
+	EntityTypeStruct:    true,
+	EntityTypeInterface: true,
+}
+
+// TestImpactAnalyzer matches exported entities changed in a source file to its test files
+type TestImpactAnalyzer struct {
+	provider    interfaces.CodeProvider
+	conventions map[string][]string
+	analyzers   map[SupportedLanguage]LanguageAnalyzer
+	log         logze.Logger
+}
+
+// NewTestImpactAnalyzer creates a test impact analyzer with test file conventions by language,
+// languages without conventions are not analyzed
+func NewTestImpactAnalyzer(provider interfaces.CodeProvider, conventions map[string][]string) *TestImpactAnalyzer {
+	jsAnalyzer := NewJSAnalyzer()
+	return &TestImpactAnalyzer{
+		provider:    provider,
+		conventions: conventions,
+		analyzers: map[SupportedLanguage]LanguageAnalyzer{
+			LanguageGo:         NewGoAnalyzer(),
+			LanguagePython:     NewPythonAnalyzer(),
+			LanguageJavaScript: jsAnalyzer,
+			LanguageTypeScript: jsAnalyzer,
+		},
+		log: logze.With("component", "test-impact-analyzer"),
+	}
+}
+
+// Analyze returns existing test files of the changed file and its added or modified exported entities
+// that are not referenced in them; if no test file exists, every such entity is untested
+// and the first candidate is returned as the test file to create
+func (ta *TestImpactAnalyzer) Analyze(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff) (TestImpact, []ChangedEntity, error) {
+	var impact TestImpact
+
+	analyzer, ok := ta.analyzers[detectLanguage(fileDiff.NewPath)]
+	candidates := TestFileCandidates(fileDiff.NewPath, ta.conventions)
+	if !ok || fileDiff.IsDeleted || len(candidates) == 0 || IsTestFile(fileDiff.NewPath, ta.conventions) {
+		return impact, nil, nil
+	}
+
+	entities, err := parseChangedEntities(ctx, ta.provider, analyzer, request, fileDiff, ta.log)
+	if err != nil {
+		return impact, nil, err
+	}
+	var changed []ChangedEntity
+	for _, entity := range entities {
+		if entity.IsExported && entity.ChangeType != ChangeTypeDeleted && testableEntityTypes[entity.Type] {
+			changed = append(changed, entity)
+		}
+	}
+	if len(changed) == 0 {
+		return impact, nil, nil
+	}
+
+	var tests []string
+	for _, candidate := range candidates {
+		content, err := FetchFileContent(ctx, ta.provider, request.ProjectID, candidate, request.MergeRequest.SHA)
+		if err != nil {
+			continue // there is no test file with this name
+		}
+		impact.AffectedTestFiles = append(impact.AffectedTestFiles, candidate)
+		tests = append(tests, content)
+	}
+
+	var untested []ChangedEntity
+	for _, entity := range changed {
+		if !isReferencedIn(entity.Name, tests) {
+			untested = append(untested, entity)
+		}
+	}
+	if len(untested) == 0 {
+		return impact, nil, nil
+	}
+
+	impact.RequiresNewTests = true
+	if len(impact.AffectedTestFiles) == 0 {
+		impact.AffectedTestFiles = candidates[:1]
+		impact.TestingStrategy = fmt.Sprintf("Create `%s` with tests of the changed exported entities.", candidates[0])
+	} else {
+		impact.TestingStrategy = fmt.Sprintf("Add tests of the changed exported entities to `%s`.", impact.AffectedTestFiles[0])
+	}
+
+	return impact, untested, nil
+}
+
+// TestFileCandidates returns possible test files of the source file by conventions of its language
+func TestFileCandidates(filePath string, conventions map[string][]string) []string {
+	ext := path.Ext(filePath)
+	name := strings.TrimSuffix(path.Base(filePath), ext)
+	dir := path.Dir(filePath)
+
+	patterns := conventions[string(detectLanguage(filePath))]
+	candidates := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		candidate := strings.NewReplacer("{name}", name, "{ext}", ext).Replace(pattern)
+		if rooted, ok := strings.CutPrefix(candidate, "/"); ok {
+			candidate = path.Clean(rooted)
+		} else {
+			candidate = path.Join(dir, candidate)
+		}
+		if candidate != filePath {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// IsTestFile checks if the file name matches a test file convention of its language
+func IsTestFile(filePath string, conventions map[string][]string) bool {
+	ext := path.Ext(filePath)
+	base := path.Base(filePath)
+	for _, pattern := range conventions[string(detectLanguage(filePath))] {
+		glob := strings.NewReplacer("{name}", "*", "{ext}", ext).Replace(path.Base(pattern))
+		if ok, _ := path.Match(glob, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isReferencedIn checks if the name is used as a whole word in any of the sources
+func isReferencedIn(name string, sources []string) bool {
+	re, err := regexp.Compile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	if err != nil {
+		return false
+	}
+	for _, source := range sources {
+		if re.MatchString(source) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned for missing keys
+var ErrNotFound = errors.New("not found")
+
+// Store keeps values in memory
+type Store struct {
+	values map[string]string
+}
+
+// New creates a store
+func New() *Store {
+	return &Store{values: make(map[string]string)}
+}
+
+// Get returns the value of the key
+func (s *Store) Get(ctx context.Context, key string) (string, error) {
+	value, ok := s.values[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Put sets the value of the key
+func (s *Store) Put(key, value string) {
+	s.values[key] = value
+}
+
+func (s *Store) size() int {
+	return len(s.values)
+}
@@ -0,0 +1,27 @@
+package worker
+
+import "sync"
+
+// Run calls fn for every item in parallel and waits for all of them
+func Run(items []int, fn func(int)) {
+	var wg sync.WaitGroup
+	for _, item := range items {
+		wg.Add(1)
+		go func(item int) {
+			defer wg.Done()
+			fn(item)
+		}(item)
+	}
+	wg.Wait()
+}
+
+type counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *counter) inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
@@ -0,0 +1,19 @@
+package util
+
+import "strings"
+
+// Normalize trims and lowercases the value
+func Normalize(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// Join joins non-empty parts
+func Join(parts []string, sep string) string {
+	var result []string
+	for _, part := range parts {
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	return strings.Join(result, sep)
+}
@@ -0,0 +1,26 @@
+package res
+
+import "os"
+
+// File closes an open file
+type File struct {
+	f *os.File
+}
+
+// Close closes the file
+func (f *File) Close() error {
+	return f.f.Close()
+}
+
+// Pool keeps open files
+type Pool struct {
+	files []*File
+}
+
+// Close closes all files of the pool
+func (p *Pool) Close() error {
+	for _, f := range p.files {
+		f.Close()
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+from dataclasses import dataclass
+
+
+@dataclass
+class Order:
+    id: str
+    total: float
+
+
+def total(orders):
+    return sum(order.total for order in orders)
+
+
+def _validate(order):
+    return order.total >= 0
+
+
+class OrderService:
+    def __init__(self, repo):
+        self.repo = repo
+
+    def create(self, order):
+        self.repo.save(order)
+        return order
+
+    def cancel(self, order_id):
+        self.repo.delete(order_id)
@@ -0,0 +1,23 @@
+import time
+
+
+def retry(attempts):
+    def decorator(fn):
+        def wrapper(*args, **kwargs):
+            for _ in range(attempts):
+                try:
+                    return fn(*args, **kwargs)
+                except Exception:
+                    time.sleep(1)
+            return fn(*args, **kwargs)
+        return wrapper
+    return decorator
+
+
+class Client:
+    def __init__(self, url):
+        self.url = url
+
+    @retry(3)
+    def fetch(self, path):
+        return self.url + path
@@ -0,0 +1,24 @@
+import { Http } from "./http";
+
+export interface User {
+  id: string;
+  name: string;
+}
+
+export class UserService {
+  constructor(private http: Http) {}
+
+  async getUser(id: string): Promise<User> {
+    return this.http.get(`/users/${id}`);
+  }
+
+  async listUsers(): Promise<User[]> {
+    return this.http.get("/users");
+  }
+}
+
+export function formatUser(user: User): string {
+  return user.name;
+}
+
+const cacheSize = 100;
@@ -0,0 +1,17 @@
+type Handler = (payload: unknown) => void;
+
+export function createBus() {
+  const handlers: Record<string, Handler[]> = {};
+
+  function on(event: string, handler: Handler) {
+    (handlers[event] ||= []).push(handler);
+  }
+
+  function emit(event: string, payload: unknown) {
+    for (const handler of handlers[event] || []) {
+      handler(payload);
+    }
+  }
+
+  return { on, emit };
+}
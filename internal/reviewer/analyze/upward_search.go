@@ -0,0 +1,152 @@
+package analyze
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// maxUpwardSearchDepth is the number of parent directories of a file searched for the files of its sub-project,
+// the repository root is always searched
+const maxUpwardSearchDepth = 8
+
+var (
+	// goModFiles mark the root of a Go module
+	goModFiles = []string{"go.mod"}
+	// linterConfigFiles are golangci-lint configs in the order of preference
+	linterConfigFiles = []string{".golangci.yml", ".golangci.yaml", ".golangci-lint.yml", ".golangci-lint.yaml"}
+)
+
+// revisionCache caches lookups of repository files for one revision of the repository, so files of one module
+// or of one review share them; it is reset when another revision is searched
+type revisionCache struct {
+	mu       sync.Mutex
+	revision string
+	// entries are keyed by the kind of the lookup: "dir:<names>|<dir>" -> path of the file found in the directory,
+	// empty if there is none, "list:<dir>" -> paths of files in the directory separated by newlines,
+	// "file:<path>" -> content of the file; other users prefix their keys with their own kinds
+	entries map[string]string
+}
+
+func (s *revisionCache) get(revision, key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.revision != revision {
+		s.revision, s.entries = revision, make(map[string]string)
+	}
+	value, ok := s.entries[key]
+	return value, ok
+}
+
+func (s *revisionCache) set(revision, key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.revision == revision {
+		s.entries[key] = value
+	}
+}
+
+// searchRevision identifies the state of the target branch and the merge request the cache is valid for
+func searchRevision(request model.ReviewRequest) string {
+	return request.ProjectID + "@" + request.MergeRequest.TargetBranch + "@" + request.MergeRequest.SHA
+}
+
+// findNearestFile returns the path and the content of the first of the names found in the directory of the file
+// or in its nearest parent at the target branch, so files of a sub-project of a monorepo get its go.mod and
+// linter config instead of the ones of the repository root
+func (psa *ProjectStyleAnalyzer) findNearestFile(ctx context.Context, request model.ReviewRequest, filePath string, names []string) (string, string, error) {
+	revision := searchRevision(request)
+	for _, dir := range searchDirs(filePath) {
+		if err := ctx.Err(); err != nil {
+			return "", "", err
+		}
+
+		key := "dir:" + strings.Join(names, ",") + "|" + dir
+		found, ok := psa.search.get(revision, key)
+		if !ok {
+			found = psa.findInDir(ctx, request, revision, dir, names)
+			psa.search.set(revision, key, found)
+		}
+		if found == "" {
+			continue
+		}
+
+		content, err := psa.cachedContent(ctx, request, revision, found)
+		if err != nil {
+			return "", "", err
+		}
+		return found, content, nil
+	}
+
+	return "", "", errm.New("file is not found in the directory and its parents", "names", names)
+}
+
+// findInDir returns the path of the first of the names in the directory, files are listed if the provider
+// can do it, otherwise every name is fetched
+func (psa *ProjectStyleAnalyzer) findInDir(ctx context.Context, request model.ReviewRequest, revision, dir string, names []string) string {
+	lister, ok := psa.provider.(interfaces.FileLister)
+	if !ok {
+		for _, name := range names {
+			filePath := path.Join(dir, name)
+			if _, err := psa.cachedContent(ctx, request, revision, filePath); err == nil {
+				return filePath
+			}
+		}
+		return ""
+	}
+
+	listing, ok := psa.search.get(revision, "list:"+dir)
+	if !ok {
+		listDir := dir
+		if listDir == "." {
+			listDir = "" // repository root
+		}
+		// Error means the directory is new and doesn't exist in the target branch
+		paths, err := lister.ListFiles(ctx, request.ProjectID, listDir, request.MergeRequest.TargetBranch)
+		if err != nil && ctx.Err() != nil {
+			return ""
+		}
+		listing = strings.Join(paths, "\n")
+		psa.search.set(revision, "list:"+dir, listing)
+	}
+	paths := strings.Split(listing, "\n")
+	for _, name := range names {
+		if filePath := path.Join(dir, name); slices.Contains(paths, filePath) {
+			return filePath
+		}
+	}
+	return ""
+}
+
+// cachedContent returns the content of the file at the target branch, found files are fetched once per revision
+func (psa *ProjectStyleAnalyzer) cachedContent(ctx context.Context, request model.ReviewRequest, revision, filePath string) (string, error) {
+	if content, ok := psa.search.get(revision, "file:"+filePath); ok {
+		return content, nil
+	}
+	content, err := FetchFileContent(ctx, psa.provider, request.ProjectID, filePath, request.MergeRequest.TargetBranch)
+	if err != nil {
+		return "", err
+	}
+	psa.search.set(revision, "file:"+filePath, content)
+	return content, nil
+}
+
+// searchDirs returns the directory of the file and its parents from the nearest up to the depth limit,
+// the repository root is the last one
+func searchDirs(filePath string) []string {
+	dir := path.Dir(strings.TrimPrefix(filePath, "/"))
+	var dirs []string
+	for len(dirs) < maxUpwardSearchDepth && dir != "." && dir != "/" {
+		dirs = append(dirs, dir)
+		dir = path.Dir(dir)
+	}
+	return append(dirs, ".")
+}
@@ -0,0 +1,167 @@
+package analyze
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// treeProvider serves files of the repository tree and counts fetches of every file
+type treeProvider struct {
+	interfaces.CodeProvider
+	files map[string]string
+
+	mu      sync.Mutex
+	fetches map[string]int
+}
+
+func (p *treeProvider) GetFileContent(_ context.Context, _, filePath, _ string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fetches == nil {
+		p.fetches = make(map[string]int)
+	}
+	p.fetches[filePath]++
+	content, ok := p.files[filePath]
+	if !ok {
+		return "", errm.Wrap(model.ErrNotFound, "file is not found", "path", filePath)
+	}
+	return content, nil
+}
+
+func (p *treeProvider) fetchCount(filePath string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetches[filePath]
+}
+
+// listingTreeProvider is the tree provider that lists files of directories
+type listingTreeProvider struct {
+	*treeProvider
+}
+
+func (p listingTreeProvider) ListFiles(_ context.Context, _, dir, _ string) ([]string, error) {
+	var paths []string
+	for filePath := range p.files {
+		fileDir := path.Dir(filePath)
+		if fileDir == "." {
+			fileDir = ""
+		}
+		if fileDir == dir {
+			paths = append(paths, filePath)
+		}
+	}
+	return paths, nil
+}
+
+func TestNearestProjectFiles(t *testing.T) {
+	linterConfig := func(lines int) string {
+		return "linters-settings:\n  funlen:\n    lines: " + strconv.Itoa(lines) + "\n"
+	}
+	files := map[string]string{
+		"go.mod":                      "module example.com/mono\n\ngo 1.21\n",
+		".golangci.yml":               linterConfig(80),
+		"services/api/go.mod":         "module example.com/mono/api\n\ngo 1.22\n",
+		"services/api/.golangci.yaml": linterConfig(40),
+		"services/worker/go.mod":      "module example.com/mono/worker\n\ngo 1.23\n",
+		"a/go.mod":                    "module example.com/mono/a\n\ngo 1.24\n",
+	}
+
+	tests := []struct {
+		name           string
+		filePath       string
+		wantModuleRoot string
+		wantGoVersion  string
+		wantLinterPath string
+		wantFuncLength int
+	}{
+		{"root package", "main.go", ".", "1.21", ".golangci.yml", 80},
+		{"package of the root module", "internal/store/store.go", ".", "1.21", ".golangci.yml", 80},
+		{"nested module with its config", "services/api/handlers/user.go", "services/api", "1.22", "services/api/.golangci.yaml", 40},
+		{"nested module without config", "services/worker/main.go", "services/worker", "1.23", ".golangci.yml", 80},
+		{"module beyond the depth limit", "a/b/c/d/e/f/g/h/i/j/k.go", ".", "1.21", ".golangci.yml", 80},
+	}
+
+	providers := map[string]func() (interfaces.CodeProvider, *treeProvider){
+		"fetching": func() (interfaces.CodeProvider, *treeProvider) {
+			tree := &treeProvider{files: files}
+			return tree, tree
+		},
+		"listing": func() (interfaces.CodeProvider, *treeProvider) {
+			tree := &treeProvider{files: files}
+			return listingTreeProvider{tree}, tree
+		},
+	}
+	request := model.ReviewRequest{ProjectID: "mono", MergeRequest: &model.MergeRequest{TargetBranch: "main", SHA: "head"}}
+
+	for providerName, newProvider := range providers {
+		for _, tt := range tests {
+			t.Run(providerName+"/"+tt.name, func(t *testing.T) {
+				provider, tree := newProvider()
+				psa := NewProjectStyleAnalyzer(provider)
+				ctx := context.Background()
+
+				deps, err := psa.analyzeDependencies(ctx, request, tt.filePath)
+				if err != nil {
+					t.Fatalf("analyzeDependencies() error = %v", err)
+				}
+				if deps.ModuleRoot != tt.wantModuleRoot || deps.GoVersion != tt.wantGoVersion {
+					t.Errorf("analyzeDependencies() = module %q, go %q, want %q, %q",
+						deps.ModuleRoot, deps.GoVersion, tt.wantModuleRoot, tt.wantGoVersion)
+				}
+				linter, err := psa.AnalyzeLinterConfig(ctx, request, tt.filePath)
+				if err != nil {
+					t.Fatalf("AnalyzeLinterConfig() error = %v", err)
+				}
+				if linter.Path != tt.wantLinterPath || linter.Complexity.FuncLength != tt.wantFuncLength {
+					t.Errorf("AnalyzeLinterConfig() = %q with %d lines, want %q with %d lines",
+						linter.Path, linter.Complexity.FuncLength, tt.wantLinterPath, tt.wantFuncLength)
+				}
+
+				// Other files of the directory use the cached lookups, found files are fetched once
+				sibling := path.Join(path.Dir(tt.filePath), "other.go")
+				if _, err := psa.analyzeDependencies(ctx, request, sibling); err != nil {
+					t.Fatalf("analyzeDependencies() of the sibling error = %v", err)
+				}
+				if _, err := psa.AnalyzeLinterConfig(ctx, request, sibling); err != nil {
+					t.Fatalf("AnalyzeLinterConfig() of the sibling error = %v", err)
+				}
+				for _, found := range []string{path.Join(tt.wantModuleRoot, "go.mod"), tt.wantLinterPath} {
+					if got := tree.fetchCount(found); got != 1 {
+						t.Errorf("fetches of %s = %d, want 1", found, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSearchDirs(t *testing.T) {
+	tests := []struct {
+		filePath string
+		want     []string
+	}{
+		{"main.go", []string{"."}},
+		{"/main.go", []string{"."}},
+		{"services/api/user.go", []string{"services/api", "services", "."}},
+		{"a/b/c/d/e/f/g/h/i/j/k.go", []string{
+			"a/b/c/d/e/f/g/h/i/j", "a/b/c/d/e/f/g/h/i", "a/b/c/d/e/f/g/h", "a/b/c/d/e/f/g",
+			"a/b/c/d/e/f", "a/b/c/d/e", "a/b/c/d", "a/b/c", ".",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			if got := searchDirs(tt.filePath); !slices.Equal(got, tt.want) {
+				t.Errorf("searchDirs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
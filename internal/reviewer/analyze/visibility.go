@@ -0,0 +1,50 @@
+package analyze
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	jsExportRegex   = regexp.MustCompile(`^export\b`)
+	javaPublicRegex = regexp.MustCompile(`\b(?:public|protected)\b`)
+	rustPubRegex    = regexp.MustCompile(`^pub\s`)
+	cStaticRegex    = regexp.MustCompile(`^(?:(?:inline|const)\s+)*static\b`)
+)
+
+// isExported checks if the entity declared on the line is visible outside of its package or module by the rules
+// of the language: capitalization in Go, the export keyword in JS and TS, public and protected members in Java,
+// no leading underscore in Python, the pub keyword in Rust and no static in C and C++; unknown languages use
+// the Go rule; the line may keep its diff prefix
+func isExported(language SupportedLanguage, name, declaration string) bool {
+	if name == "" {
+		return false
+	}
+	declaration = strings.TrimLeft(strings.TrimLeft(declaration, "+-"), " \t")
+	switch language {
+	case LanguageJavaScript, LanguageTypeScript:
+		return jsExportRegex.MatchString(declaration)
+	case LanguageJava:
+		return javaPublicRegex.MatchString(declaration)
+	case LanguagePython:
+		return isPythonPublic(name)
+	case LanguageRust:
+		return rustPubRegex.MatchString(declaration)
+	case LanguageC, LanguageCpp:
+		return !cStaticRegex.MatchString(declaration)
+	default:
+		first, _ := utf8.DecodeRuneInString(name)
+		return unicode.IsUpper(first)
+	}
+}
+
+// isPythonPublic checks the name by the Python convention: a leading underscore makes it private,
+// special names like __init__ are public
+func isPythonPublic(name string) bool {
+	if strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__") && len(name) > 4 {
+		return true
+	}
+	return !strings.HasPrefix(name, "_")
+}
@@ -0,0 +1,128 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+func TestIsExported(t *testing.T) {
+	tests := []struct {
+		name        string
+		language    SupportedLanguage
+		entity      string
+		declaration string
+		want        bool
+	}{
+		{"go exported", LanguageGo, "Open", "func Open() error {", true},
+		{"go unexported", LanguageGo, "open", "func open() error {", false},
+		{"go unicode exported", LanguageGo, "Ärger", "func Ärger() {", true},
+
+		{"js exported function", LanguageJavaScript, "fetchUser", "export function fetchUser(id) {", true},
+		{"js exported default", LanguageJavaScript, "App", "export default class App {", true},
+		{"js capitalized without export", LanguageJavaScript, "Helper", "class Helper {", false},
+		{"js export of an added line", LanguageJavaScript, "load", "+export const load = () => {", true},
+		{"ts exported interface", LanguageTypeScript, "user", "export interface user {", true},
+		{"ts type without export", LanguageTypeScript, "User", "type User = {", false},
+
+		{"java public method", LanguageJava, "save", "    public void save() {", true},
+		{"java protected method", LanguageJava, "load", "protected static int load() {", true},
+		{"java private method", LanguageJava, "Parse", "private String Parse(String s) {", false},
+		{"java package private class", LanguageJava, "Store", "class Store {", false},
+
+		{"python public function", LanguagePython, "fetch", "def fetch(url):", true},
+		{"python private function", LanguagePython, "_fetch", "def _fetch(url):", false},
+		{"python mangled name", LanguagePython, "__secret", "def __secret(self):", false},
+		{"python special method", LanguagePython, "__init__", "def __init__(self):", true},
+		{"python uppercase private constant", LanguagePython, "_TIMEOUT", "_TIMEOUT = 5", false},
+
+		{"rust pub function", LanguageRust, "parse", "pub fn parse(s: &str) {", true},
+		{"rust crate visible struct", LanguageRust, "Config", "pub(crate) struct Config {", false},
+		{"rust private function", LanguageRust, "Parse", "fn Parse(s: &str) {", false},
+
+		{"c function", LanguageC, "read_all", "int read_all(int fd) {", true},
+		{"c static function", LanguageC, "ReadAll", "static int ReadAll(int fd) {", false},
+		{"cpp inline static function", LanguageCpp, "helper", "inline static int helper() {", false},
+		{"cpp removed line", LanguageCpp, "run", "-void run() {", true},
+
+		{"unknown language uses go rule", LanguageUnknown, "Run", "Run()", true},
+		{"empty name", LanguageJavaScript, "", "export function () {", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isExported(tt.language, tt.entity, tt.declaration); got != tt.want {
+				t.Errorf("isExported(%q, %q) = %v, want %v", tt.entity, tt.declaration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractEntitiesVisibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		extract  func(*SemanticAnalyzer, *model.FileDiff) []ChangedEntity
+		filePath string
+		diff     string
+		want     map[string]bool
+	}{
+		{
+			name:     "javascript",
+			extract:  (*SemanticAnalyzer).extractJSEntitiesFromDiff,
+			filePath: "src/user.js",
+			diff:     "@@ -1,2 +1,2 @@\n+export function fetchUser(id) {\n+function Render() {\n",
+			want:     map[string]bool{"fetchUser": true, "Render": false},
+		},
+		{
+			name:     "python",
+			extract:  (*SemanticAnalyzer).extractPythonEntitiesFromDiff,
+			filePath: "app/user.py",
+			diff:     "@@ -1,2 +1,2 @@\n+def fetch_user(id):\n+def _Render():\n",
+			want:     map[string]bool{"fetch_user": true, "_Render": false},
+		},
+		{
+			name:     "java",
+			extract:  (*SemanticAnalyzer).extractJavaEntitiesFromDiff,
+			filePath: "src/User.java",
+			diff:     "@@ -1,2 +1,2 @@\n+    public void save() {\n+    private void Load() {\n",
+			want:     map[string]bool{"save": true, "Load": false},
+		},
+		{
+			name:     "rust",
+			extract:  (*SemanticAnalyzer).extractRustEntitiesFromDiff,
+			filePath: "src/user.rs",
+			diff:     "@@ -1,2 +1,2 @@\n+pub fn parse(s: &str) {\n+fn Render() {\n",
+			want:     map[string]bool{"parse": true, "Render": false},
+		},
+		{
+			name:     "c",
+			extract:  (*SemanticAnalyzer).extractCEntitiesFromDiff,
+			filePath: "src/user.c",
+			diff:     "@@ -1,2 +1,2 @@\n+int read_user(int id) {\n+static int Render(int id) {\n",
+			want:     map[string]bool{"read_user": true, "Render": false},
+		},
+	}
+
+	sa := NewSemanticAnalyzer(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entities := tt.extract(sa, &model.FileDiff{NewPath: tt.filePath, Diff: tt.diff})
+			found := make(map[string]bool)
+			for _, entity := range entities {
+				want, ok := tt.want[entity.Name]
+				if !ok {
+					continue
+				}
+				found[entity.Name] = true
+				if entity.IsExported != want {
+					t.Errorf("%s IsExported = %v, want %v", entity.Name, entity.IsExported, want)
+				}
+			}
+			for name := range tt.want {
+				if !found[name] {
+					t.Errorf("entity %s is not extracted", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package reviewer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/errm"
+)
+
+// anchorNeighbors is the number of lines before and after the commented line that are a part of its anchor
+const anchorNeighbors = 2
+
+var anchorMarkerRegex = regexp.MustCompile(`<!-- codry-anchor:([0-9a-f]+) -->`)
+
+// relocateAnchoredComments moves inline findings of previous reviews whose anchored code moved to other lines
+// since, so they are not orphaned by line shifts; anchors of unresolved findings are remembered,
+// the same findings are not posted again by this review
+func (s *Reviewer) relocateAnchoredComments(ctx context.Context, bundle *reviewBundle) {
+	if !s.cfg.CommentAnchoring {
+		return
+	}
+	request := bundle.request
+	bundle.anchors = make(map[string]bool)
+
+	comments, err := s.provider.GetComments(ctx, request.ProjectID, request.MergeRequest.IID)
+	if err != nil {
+		bundle.log.Warn("failed to get comments to relocate anchored findings", "error", err)
+		return
+	}
+
+	var relocated int
+	for _, comment := range comments {
+		if ctx.Err() != nil {
+			return
+		}
+		anchor := commentAnchor(comment)
+		if comment.Type != model.CommentTypeInline || comment.Resolved || anchor == "" || !s.isBotComment(comment) {
+			continue
+		}
+
+		lines, err := s.headLines(ctx, bundle, comment.FilePath)
+		if err != nil {
+			bundle.log.DebugIf(s.cfg.Verbose, "failed to get file of anchored finding", "error", err, "file", comment.FilePath)
+			continue
+		}
+		line := findAnchorLine(lines, anchor, comment.Line)
+		if line == 0 {
+			continue // the code was changed, the finding may be addressed
+		}
+		bundle.anchors[anchorKey(comment.FilePath, anchor)] = true
+		if line == comment.Line {
+			continue
+		}
+
+		if err := s.relocateComment(ctx, request, comment, line); err != nil {
+			bundle.log.Warn("failed to relocate anchored finding", "error", err, "comment_id", comment.ID, "file", comment.FilePath)
+			continue
+		}
+		relocated++
+		bundle.log.DebugIf(s.cfg.Verbose, "relocated anchored finding", "file", comment.FilePath, "from", comment.Line, "to", line)
+	}
+
+	if relocated > 0 {
+		bundle.log.Info("relocated anchored findings", "count", relocated)
+	}
+}
+
+// relocateComment moves the comment if the provider can do it, otherwise the comment is posted again
+// at the line and the old thread is resolved if the provider supports it
+func (s *Reviewer) relocateComment(ctx context.Context, request model.ReviewRequest, comment *model.Comment, line int) error {
+	if relocator, ok := s.provider.(interfaces.CommentRelocator); ok {
+		err := relocator.RelocateComment(ctx, request.ProjectID, request.MergeRequest.IID, comment, line)
+		if !errm.Is(err, model.ErrNotSupported) {
+			return err
+		}
+	}
+
+	moved := &model.Comment{
+		Body:     comment.Body,
+		Footer:   comment.Footer,
+		FilePath: comment.FilePath,
+		Line:     line,
+		Type:     model.CommentTypeInline,
+	}
+	if err := s.provider.CreateComment(ctx, request.ProjectID, request.MergeRequest.IID, moved); err != nil {
+		return errm.Wrap(err, "failed to create relocated comment")
+	}
+	if resolver, ok := s.provider.(interfaces.CommentResolver); ok {
+		if err := resolver.ResolveComment(ctx, request.ProjectID, request.MergeRequest.IID, comment); err != nil && !errm.Is(err, model.ErrNotSupported) {
+			return errm.Wrap(err, "failed to resolve moved comment")
+		}
+	}
+	return nil
+}
+
+// anchorFinding adds the anchor of the commented line to the footer of the inline comment, it returns false
+// if a finding of a previous review with the same anchor is not resolved, findings of this review are not compared
+func (s *Reviewer) anchorFinding(ctx context.Context, bundle *reviewBundle, comment *model.Comment) bool {
+	if !s.cfg.CommentAnchoring || comment.Type != model.CommentTypeInline || comment.Line == 0 {
+		return true
+	}
+	lines, err := s.headLines(ctx, bundle, comment.FilePath)
+	if err != nil {
+		bundle.log.DebugIf(s.cfg.Verbose, "failed to get file to anchor finding", "error", err, "file", comment.FilePath)
+		return true
+	}
+	anchor := lineAnchor(lines, comment.Line)
+	if anchor == "" {
+		return true
+	}
+	if bundle.anchors[anchorKey(comment.FilePath, anchor)] {
+		return false
+	}
+
+	marker := fmt.Sprintf("<!-- codry-anchor:%s -->", anchor)
+	comment.Footer = strings.TrimSpace(comment.Footer + "\n" + marker)
+	return true
+}
+
+// headLines returns lines of the file at the head of the merge request, files are fetched once per review
+func (s *Reviewer) headLines(ctx context.Context, bundle *reviewBundle, filePath string) ([]string, error) {
+	if lines, ok := bundle.headFiles[filePath]; ok {
+		return lines, nil
+	}
+	content, err := analyze.FetchFileContent(ctx, s.provider, bundle.request.ProjectID, filePath, bundle.request.MergeRequest.SHA)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(content, "\n")
+	if bundle.headFiles == nil {
+		bundle.headFiles = make(map[string][]string)
+	}
+	bundle.headFiles[filePath] = lines
+	return lines, nil
+}
+
+// commentAnchor returns the anchor from the footer of the comment, empty if it has no anchor
+func commentAnchor(comment *model.Comment) string {
+	if match := anchorMarkerRegex.FindStringSubmatch(comment.Footer); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// lineAnchor returns the short hash of the line with its neighbors, whitespace at the ends of lines
+// is ignored, so reindented code keeps its anchor; it is empty for a line out of the file or a blank line
+func lineAnchor(lines []string, line int) string {
+	if line < 1 || line > len(lines) || strings.TrimSpace(lines[line-1]) == "" {
+		return ""
+	}
+	h := sha256.New()
+	for i := max(line-1-anchorNeighbors, 0); i <= min(line-1+anchorNeighbors, len(lines)-1); i++ {
+		h.Write([]byte(strings.TrimSpace(lines[i])))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// findAnchorLine returns the line with the anchor nearest to the previous line of the comment, 0 if there is none
+func findAnchorLine(lines []string, anchor string, previous int) int {
+	if lineAnchor(lines, previous) == anchor {
+		return previous
+	}
+	found := 0
+	for line := 1; line <= len(lines); line++ {
+		if lineAnchor(lines, line) != anchor {
+			continue
+		}
+		if found == 0 || abs(line-previous) < abs(found-previous) {
+			found = line
+		}
+	}
+	return found
+}
+
+func anchorKey(filePath, anchor string) string {
+	return filePath + ":" + anchor
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -16,7 +16,10 @@ func (s *Reviewer) generateArchitectureReview(ctx context.Context, bundle *revie
 
 	bundle.log.Debug("generating architecture review")
 
-	err := s.createOrUpdateArchitectureReview(ctx, bundle.request, bundle.fullDiffString)
+	// Summaries of changes instead of the full diff keep the review on system level and fit big merge requests
+	changes := bundle.redact("", s.architectureInput.Assemble(ctx, bundle.request, bundle.filesToReview))
+
+	err := s.createOrUpdateArchitectureReview(ctx, bundle.request, changes, bundle.instructions, bundle.intent)
 	if err != nil {
 		msg := "failed to generate architecture review"
 		bundle.log.Err(err, msg)
@@ -24,17 +27,21 @@ func (s *Reviewer) generateArchitectureReview(ctx context.Context, bundle *revie
 		return
 	}
 
-	bundle.log.InfoIf(s.cfg.Verbose, "generated and updated architecture review comment")
+	bundle.log.InfoIf(s.cfg.Verbose, "generated and updated architecture review", "in_description", s.cfg.ArchitectureReviewInDescription)
 
 	bundle.result.IsArchitectureReviewCreated = true
 }
 
-func (s *Reviewer) createOrUpdateArchitectureReview(ctx context.Context, request model.ReviewRequest, fullDiff string) error {
-	architectureResult, err := s.agent.GenerateArchitectureReview(ctx, fullDiff)
+func (s *Reviewer) createOrUpdateArchitectureReview(ctx context.Context, request model.ReviewRequest, changes, instructions, intent string) error {
+	architectureResult, err := s.agent.GenerateArchitectureReview(ctx, changes, instructions, intent)
 	if err != nil {
 		return errm.Wrap(err, "failed to generate architecture review")
 	}
 
+	if s.cfg.ArchitectureReviewInDescription {
+		return s.updateDescriptionSection(ctx, request, startMarkerArchitecture, endMarkerArchitecture, architectureResult)
+	}
+
 	// Wrap the architecture result with markers
 	wrappedContent := s.wrapArchitectureContent(architectureResult)
 
@@ -88,7 +95,7 @@ func (s *Reviewer) findExistingArchitectureComment(ctx context.Context, projectI
 	}
 
 	for _, comment := range comments {
-		if s.isArchitectureReviewComment(comment.Body) {
+		if s.isArchitectureReviewComment(comment.Body) && s.isBotComment(comment) {
 			return comment, nil
 		}
 	}
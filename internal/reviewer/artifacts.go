@@ -0,0 +1,118 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+// maxArtifactFetches limits fetched contents of added binary and undiffable files to find their sizes
+const maxArtifactFetches = 20
+
+// artifact is an added file that is likely committed by accident
+type artifact struct {
+	path   string
+	binary bool
+	size   int // in bytes, 0 if unknown
+}
+
+// reportArtifacts adds one informational finding listing added binary files and added textual files larger
+// than the limit, e.g. build outputs, media or data dumps; it is posted like findings of files
+func (s *Reviewer) reportArtifacts(ctx context.Context, bundle *reviewBundle) {
+	if !s.cfg.Artifacts.Enable {
+		return
+	}
+	artifacts := s.findArtifacts(ctx, bundle)
+	if len(artifacts) == 0 {
+		return
+	}
+	bundle.log.InfoIf(s.cfg.Verbose, "found added binary or large files", "count", len(artifacts))
+
+	finding := artifactsFinding(artifacts)
+	if s.collectsFindings() {
+		bundle.findings = append(bundle.findings, finding)
+	} else {
+		bundle.result.CommentsCreated += s.postReviewComments(ctx, bundle, bundle.request, []*model.ReviewAIComment{finding})
+	}
+	bundle.reportedFindings = append(bundle.reportedFindings, finding)
+}
+
+// findArtifacts returns added binary files and added textual files larger than the limit, files excluded
+// from the review and allowed paths are skipped; sizes of textual files are taken from their diffs,
+// other files are fetched at the head commit
+func (s *Reviewer) findArtifacts(ctx context.Context, bundle *reviewBundle) []artifact {
+	request := bundle.request
+	var (
+		artifacts []artifact
+		fetched   int
+	)
+	for _, file := range request.Changes {
+		if !file.IsNew || s.isExcludedPath(file.NewPath) || matchesPath(file.NewPath, s.cfg.Artifacts.AllowedPaths) {
+			continue
+		}
+
+		item := artifact{path: file.NewPath, binary: file.IsBinary}
+		switch {
+		case !file.IsBinary && !file.IsTooLarge:
+			for _, line := range analyze.FileAddedLines(file) {
+				item.size += len(line.Content) + 1
+			}
+		case fetched < maxArtifactFetches:
+			fetched++
+			content, err := s.provider.GetFileContent(ctx, request.ProjectID, file.NewPath, request.MergeRequest.SHA)
+			if err != nil {
+				bundle.log.DebugIf(s.cfg.Verbose, "failed to get size of added file", "file", file.NewPath, "error", err)
+				break
+			}
+			item.size = len(content)
+		}
+
+		// A textual file without a diff is too large to diff, it is reported even if its size is unknown
+		if item.binary || item.size > s.cfg.Artifacts.MaxTextSize || (file.IsTooLarge && item.size == 0) {
+			artifacts = append(artifacts, item)
+		}
+	}
+	return artifacts
+}
+
+// artifactsFinding groups artifacts into one finding of the merge request
+func artifactsFinding(artifacts []artifact) *model.ReviewAIComment {
+	var description strings.Builder
+	description.WriteString("These files look like build outputs, media or data committed by accident, " +
+		"every clone of the repository downloads them even after they are deleted:\n")
+	for _, item := range artifacts {
+		var details []string
+		if item.binary {
+			details = append(details, "binary")
+		}
+		if item.size > 0 {
+			details = append(details, formatFileSize(item.size))
+		} else if !item.binary {
+			details = append(details, "too large to diff")
+		}
+		description.WriteString(fmt.Sprintf("- `%s` — %s\n", item.path, strings.Join(details, ", ")))
+	}
+
+	return &model.ReviewAIComment{
+		IssueType:   model.IssueTypeOther,
+		Confidence:  model.ConfidenceHigh,
+		Priority:    model.ReviewPriorityBacklog,
+		Title:       fmt.Sprintf("%d binary or large files are added", len(artifacts)),
+		Description: strings.TrimSuffix(description.String(), "\n"),
+		Suggestion:  "Remove the files and add them to .gitignore, or track them with Git LFS if they must be versioned.",
+	}
+}
+
+// formatFileSize renders the size in bytes, KB or MB
+func formatFileSize(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
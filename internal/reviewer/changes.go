@@ -8,6 +8,8 @@ import (
 
 	"github.com/maxbolgarin/codry/internal/agent/prompts"
 	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
 	"github.com/maxbolgarin/errm"
 	"github.com/maxbolgarin/lang"
 	"github.com/maxbolgarin/logze/v2"
@@ -19,22 +21,48 @@ func (s *Reviewer) generateCodeReview(ctx context.Context, bundle *reviewBundle)
 		return
 	}
 	bundle.log.Debug("generating code review")
+	bundle.codeReviewFiles, bundle.fileLimit = s.limitCodeReviewFiles(s.applyOwnershipScope(bundle), bundle.log)
 
+	s.relocateAnchoredComments(ctx, bundle)
+	s.resolveAddressedComments(ctx, bundle)
+	s.collectPostedLines(ctx, bundle)
 	s.reviewCodeChanges(ctx, bundle)
+	s.reportArtifacts(ctx, bundle)
+	s.postCollectedFindings(ctx, bundle)
+	s.postFileLimitSummary(ctx, bundle)
 
 	bundle.log.InfoIf(s.cfg.Verbose, "finished code review")
 
 	bundle.result.IsCodeReviewCreated = true
 }
 
-// reviewCodeChanges reviews individual files and creates comments
+// reviewCodeChanges reviews individual files of the merge request or of its commits and creates comments
 func (s *Reviewer) reviewCodeChanges(ctx context.Context, bundle *reviewBundle) {
-	for _, change := range bundle.filesToReview {
+	if s.cfg.ReviewScope == ReviewScopeCommits {
+		if reader, ok := s.provider.(interfaces.CommitReader); ok {
+			s.reviewCommits(ctx, bundle, reader)
+			return
+		}
+		bundle.log.Warn("provider does not support reading commits, reviewing the whole merge request")
+	}
+	s.reviewFiles(ctx, bundle, bundle.request, bundle.codeReviewFiles, "")
+}
+
+// reviewFiles reviews files of the request and creates comments, commitSHA is set to findings
+// if the request is a commit of the merge request
+func (s *Reviewer) reviewFiles(ctx context.Context, bundle *reviewBundle, request model.ReviewRequest, files []*model.FileDiff, commitSHA string) {
+	for _, change := range files {
+		if err := ctx.Err(); err != nil {
+			bundle.log.Warn("code review interrupted", "error", err)
+			bundle.result.Errors = append(bundle.result.Errors, errm.Wrap(err, "code review interrupted"))
+			return
+		}
+
 		// Guard old path
 		change.OldPath = lang.Check(change.OldPath, change.NewPath)
 
 		fileHash := s.getFileHash(change.Diff)
-		if oldHash, ok := s.processedMRs.Lookup(bundle.request.String(), change.NewPath); ok {
+		if oldHash, ok := s.processedMRs.Lookup(request.String(), change.NewPath); ok {
 			if oldHash == fileHash {
 				bundle.log.DebugIf(s.cfg.Verbose, "skipping already reviewed", "file", change.NewPath)
 				continue
@@ -43,44 +71,105 @@ func (s *Reviewer) reviewCodeChanges(ctx context.Context, bundle *reviewBundle)
 
 		bundle.log.DebugIf(s.cfg.Verbose, "performing review", "file", change.NewPath)
 
-		reviewResult, err := s.performBasicReview(ctx, bundle.request, change, bundle.log)
+		reviewResult, err := s.reviewFile(ctx, bundle, request, change)
 		if err != nil {
 			msg := "failed to perform basic review"
 			bundle.log.Err(err, msg)
 			bundle.result.Errors = append(bundle.result.Errors, errm.Wrap(err, msg))
 			continue
 		}
+		bundle.reviewedFiles++
 
 		// Skip if no issues found
-		if reviewResult == nil || !reviewResult.HasIssues || len(reviewResult.Comments) == 0 {
+		if len(reviewResult.Comments) == 0 {
 			bundle.log.DebugIf(s.cfg.Verbose, "no issues found", "file", change.NewPath)
-			s.processedMRs.Set(bundle.request.String(), change.NewPath, fileHash)
+			s.processedMRs.Set(request.String(), change.NewPath, fileHash)
 			continue
 		}
 
-		commentsCreated := s.processReviewResults(ctx, bundle.request, change, reviewResult, bundle.log)
-		bundle.result.CommentsCreated += commentsCreated
-		s.processedMRs.Set(bundle.request.String(), change.NewPath, fileHash)
+		for _, comment := range reviewResult.Comments {
+			comment.CommitSHA = commitSHA
+		}
+		if s.collectsFindings() {
+			// Findings are posted after all files are reviewed, when all of them are known
+			bundle.findings = append(bundle.findings, reviewResult.Comments...)
+		} else {
+			bundle.result.CommentsCreated += s.postReviewComments(ctx, bundle, request, reviewResult.Comments)
+		}
+		bundle.reportedFindings = append(bundle.reportedFindings, reviewResult.Comments...)
+		s.processedMRs.Set(request.String(), change.NewPath, fileHash)
 
 		bundle.log.InfoIf(s.cfg.Verbose, "reviewed successfully", "file", change.NewPath, "comments", len(reviewResult.Comments))
 	}
 }
 
+// reviewFile reviews the file with the model and finding processors, comments of the result are prepared for posting
+func (s *Reviewer) reviewFile(ctx context.Context, bundle *reviewBundle, request model.ReviewRequest, change *model.FileDiff) (*model.FileReviewResult, error) {
+	guidance := prompts.ReviewGuidance{
+		Rules:        s.fileRules(bundle, change.NewPath),
+		Instructions: bundle.instructions,
+		Intent:       bundle.intent,
+
+		ConfigChanges:    s.promptConfigChanges(bundle, request, change.NewPath),
+		SecurityFindings: s.promptSecurityFindings(bundle, change),
+		ConcurrencyHints: s.promptConcurrencyHints(ctx, bundle, request, change),
+		Callers:          s.promptCallers(ctx, bundle, request, change),
+	}
+	if !s.cfg.Strictness.DisablePromptDirective {
+		guidance.Strictness = s.cfg.Strictness.Level
+	}
+	reviewResult, err := s.generateFindings(ctx, bundle, request, change, guidance)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviewResult == nil {
+		reviewResult = &model.FileReviewResult{File: change.NewPath}
+	}
+	if !reviewResult.HasIssues {
+		reviewResult.Comments = nil
+	}
+	reviewResult.Comments = append(reviewResult.Comments, s.configDependentsFindings(bundle, request, change)...)
+	reviewResult.Comments = s.runFindingProcessors(ctx, request, change, reviewResult.Comments, bundle.log)
+	reviewResult.Comments = s.suppressFindings(ctx, bundle, request, change, reviewResult.Comments)
+	boostRuleFindings(reviewResult.Comments, bundle.rules)
+	bundle.criticality.weigh(reviewResult.Comments)
+	reviewResult.Comments = s.filterByStrictness(bundle, reviewResult.Comments)
+	if len(reviewResult.Comments) > 0 {
+		s.prepareReviewComments(change, reviewResult, bundle.log)
+	}
+
+	return reviewResult, nil
+}
+
 // performBasicReview performs basic review without enhanced context (fallback)
-func (s *Reviewer) performBasicReview(ctx context.Context, request model.ReviewRequest, change *model.FileDiff, log logze.Logger) (*model.FileReviewResult, error) {
-	fullFileContent, cleanDiff, err := s.prepareFileContentAndDiff(ctx, request, change, log)
+func (s *Reviewer) performBasicReview(ctx context.Context, bundle *reviewBundle, request model.ReviewRequest, change *model.FileDiff, guidance prompts.ReviewGuidance) (*model.FileReviewResult, error) {
+	originalContent, cleanDiff, err := s.prepareFileContentAndDiff(ctx, request, change, bundle.log)
 	if err != nil {
 		return nil, errm.Wrap(err, "failed to prepare file content and diff")
 	}
-	return s.agent.ReviewCode(ctx, change.NewPath, fullFileContent, cleanDiff)
+	fileContext := extractContextWindow(originalContent, hunkRanges(change.DiffHunks()), s.diffContextLines(change.NewPath))
+	return s.agent.ReviewCode(ctx, change.NewPath, bundle.redact(change.NewPath, fileContext), bundle.redact(change.NewPath, cleanDiff), guidance)
 }
 
-// processReviewResults processes the review results and creates comments
-func (s *Reviewer) processReviewResults(ctx context.Context, request model.ReviewRequest, change *model.FileDiff, reviewResult *model.FileReviewResult, log logze.Logger) int {
-	commentsCreated := 0
+// runFindingProcessors passes findings of the file through registered processors in order,
+// findings of the failed processor are discarded and the next one gets the previous set
+func (s *Reviewer) runFindingProcessors(ctx context.Context, request model.ReviewRequest, change *model.FileDiff, findings []*model.ReviewAIComment, log logze.Logger) []*model.ReviewAIComment {
+	for i, p := range s.processors {
+		processed, err := p.Process(ctx, request, change, findings)
+		if err != nil {
+			log.Warn("failed to run finding processor", "error", err, "processor", fmt.Sprintf("%d:%T", i, p), "file", change.NewPath)
+			continue
+		}
+		findings = processed
+	}
+	return findings
+}
 
+// prepareReviewComments enhances review comments with diff positions, file path and programming language
+func (s *Reviewer) prepareReviewComments(change *model.FileDiff, reviewResult *model.FileReviewResult, log logze.Logger) {
 	// Enhance comments with diff position information and set programming language
-	if err := s.parser.enhanceReviewComments(change.Diff, reviewResult.Comments); err != nil {
+	if err := s.parser.enhanceReviewComments(change, reviewResult.Comments); err != nil {
 		log.Warn("failed to enhance comments with diff positions", "error", err)
 	}
 
@@ -90,29 +179,64 @@ func (s *Reviewer) processReviewResults(ctx context.Context, request model.Revie
 		if comment.CodeLanguage == "" {
 			comment.CodeLanguage = detectedLanguage
 		}
+		// Ensure file path is set (AI might not include it in JSON response)
+		if comment.FilePath == "" {
+			comment.FilePath = change.NewPath
+		}
 	}
+}
 
-	// Create line-specific comments
-	for _, reviewComment := range reviewResult.Comments {
-		// Ensure file path is set (AI might not include it in JSON response)
-		if reviewComment.FilePath == "" {
-			reviewComment.FilePath = change.NewPath
+// postReviewComments creates line-specific comments and returns the number of created ones, created findings
+// and findings posted by the previous review are counted by priority
+func (s *Reviewer) postReviewComments(ctx context.Context, bundle *reviewBundle, request model.ReviewRequest, reviewComments []*model.ReviewAIComment) int {
+	log := bundle.log
+	commentsCreated := 0
+	s.collectPostedFingerprints(ctx, bundle)
+
+	for _, reviewComment := range reviewComments {
+		if ctx.Err() != nil {
+			break
 		}
 
-		comment := reviewToComment(s.cfg.Language, reviewComment)
+		comment := s.reviewToComment(reviewComment)
 		comment.Type = model.CommentTypeInline
+		if reviewComment.FilePath == "" || reviewComment.CommitSHA != "" {
+			// Systemic finding of many files or a finding of a commit, whose lines may not exist in the head
+			comment.Type = model.CommentTypeGeneral
+			comment.FilePath, comment.Line, comment.OldLine, comment.Position = "", 0, 0, 0
+			comment.StartLine, comment.EndLine = 0, 0
+		}
+		comment.Footer = s.buildCommentFooter(reviewComment)
+		if !s.anchorFinding(ctx, bundle, comment) {
+			log.DebugIf(s.cfg.Verbose, "skipping finding already posted at its anchor", "file", reviewComment.FilePath, "line", reviewComment.Line)
+			continue
+		}
+		if bundle.postedLines[postedLineKey(comment.FilePath, comment.Line)] && comment.Type == model.CommentTypeInline {
+			log.DebugIf(s.cfg.Verbose, "skipping finding at line of previous finding", "file", reviewComment.FilePath, "line", reviewComment.Line)
+			continue
+		}
+		fingerprint := s.findingFingerprint(ctx, bundle, reviewComment)
+		if bundle.postedFingerprints[fingerprint] {
+			log.DebugIf(s.cfg.Verbose, "skipping finding already posted", "file", reviewComment.FilePath, "line", reviewComment.Line)
+			// The finding is still open on the MR, so it counts for the check run and the verdict
+			bundle.result.FindingsByPriority[reviewComment.Priority]++
+			continue
+		}
+		comment.Footer = strings.TrimSpace(comment.Footer + "\n" + fingerprintMarker(fingerprint))
 
 		err := s.provider.CreateComment(ctx, request.ProjectID, request.MergeRequest.IID, comment)
 		if err != nil {
-			log.Error("failed to create comment", "error", err, "file", change.NewPath, "line", reviewComment.Line)
+			log.Error("failed to create comment", "error", err, "file", reviewComment.FilePath, "line", reviewComment.Line)
 			continue
 		}
+		bundle.postedFingerprints[fingerprint] = true
+		bundle.result.FindingsByPriority[reviewComment.Priority]++
 
 		commentsCreated++
 
 		log.DebugIf(s.cfg.Verbose,
 			"created comment",
-			"file", change.NewPath,
+			"file", reviewComment.FilePath,
 			"line", reviewComment.Line,
 			"type", reviewComment.IssueType,
 			"priority", reviewComment.Priority,
@@ -160,7 +284,7 @@ func (s *Reviewer) getOriginalFileContent(ctx context.Context, request model.Rev
 	// Try to get the file content from the target branch (base branch)
 	// This represents the "before" state that changes are being applied to
 	if request.MergeRequest.TargetBranch != "" {
-		content, err := s.provider.GetFileContent(ctx, request.ProjectID, filePath, request.MergeRequest.TargetBranch)
+		content, err := analyze.FetchFileContent(ctx, s.provider, request.ProjectID, filePath, request.MergeRequest.TargetBranch)
 		if err == nil {
 			return content, nil
 		}
@@ -170,7 +294,7 @@ func (s *Reviewer) getOriginalFileContent(ctx context.Context, request model.Rev
 	// Fallback: try to get from source commit (this will be the "after" state, but better than nothing)
 	// In a proper implementation, we'd want to get the parent commit of the source branch
 	if request.MergeRequest.SHA != "" {
-		content, err := s.provider.GetFileContent(ctx, request.ProjectID, filePath, request.MergeRequest.SHA)
+		content, err := analyze.FetchFileContent(ctx, s.provider, request.ProjectID, filePath, request.MergeRequest.SHA)
 		if err != nil {
 			return "", errm.Wrap(err, "failed to get file content from any source")
 		}
@@ -199,57 +323,34 @@ func (s *Reviewer) getFileHash(diff string) string {
 	return fmt.Sprintf("%d:%d", len(diff), hash)
 }
 
-// reviewToComment converts a LineReviewComment to a Comment model
-func reviewToComment(language model.Language, lrc *model.ReviewAIComment) *model.Comment {
-	reviewHeaders := prompts.DefaultLanguages[language].CodeReviewHeaders
-	header := reviewHeaders.GetByType(lrc.IssueType)
-
-	comment := strings.Builder{}
-	comment.WriteString("## ")
-	comment.WriteString(header)
-	comment.WriteString("\n\n**")
-	comment.WriteString(reviewHeaders.ConfidenceHeader)
-	comment.WriteString("**: ")
-	comment.WriteString(reviewHeaders.GetConfidence(lrc.Confidence))
-	comment.WriteString("\n**")
-	comment.WriteString(reviewHeaders.PriorityHeader)
-	comment.WriteString("**: ")
-	comment.WriteString(reviewHeaders.GetPriority(lrc.Priority))
-	comment.WriteString("\n\n")
-	if lrc.Title != "" {
-		comment.WriteString("### ")
-		comment.WriteString(lrc.Title)
-		comment.WriteString("\n\n")
+// buildCommentFooter renders footer with run metadata for the review comment
+func (s *Reviewer) buildCommentFooter(lrc *model.ReviewAIComment) string {
+	if s.cfg.Footer.Disable {
+		return ""
 	}
-	if lrc.Description != "" {
-		comment.WriteString(lrc.Description)
-		comment.WriteString("\n\n")
+	modelName := "unknown model"
+	if s.agent != nil && s.agent.ModelName() != "" {
+		modelName = s.agent.ModelName()
 	}
+	footer := strings.NewReplacer(
+		"{model}", modelName,
+		"{confidence}", string(lrc.Confidence),
+		"{priority}", string(lrc.Priority),
+	).Replace(s.cfg.Footer.Template)
 
-	// Add current problematic code section if we have a code snippet
-	if lrc.Suggestion != "" {
-		comment.WriteString("### ")
-		comment.WriteString(reviewHeaders.SuggestionHeader)
-		comment.WriteString("\n\n")
-		comment.WriteString(lrc.Suggestion)
-		if lrc.CodeSnippet != "" {
-			comment.WriteString("\n\n")
-
-			if strings.HasPrefix(lrc.CodeSnippet, "`") {
-				comment.WriteString(lrc.CodeSnippet)
-			} else {
-				comment.WriteString("```")
-				comment.WriteString(lrc.CodeLanguage)
-				comment.WriteString("\n")
-				comment.WriteString(lrc.CodeSnippet)
-				comment.WriteString("\n```")
-			}
-		}
-	}
+	return "<sub>" + footer + "</sub>"
+}
 
-	body := comment.String()
+// reviewToComment converts a LineReviewComment to a Comment model, the body is rendered with the template of its issue type
+func (s *Reviewer) reviewToComment(lrc *model.ReviewAIComment) *model.Comment {
+	data := newCommentData(s.cfg.Language, s.severityBadge(lrc.Priority), lrc)
+	body, err := s.commentTemplates.render(data)
+	if err != nil {
+		s.log.Warn("failed to render comment template, using default", "error", err, "issue_type", lrc.IssueType)
+		body, _ = s.defaultCommentTemplates.render(data)
+	}
 
-	return &model.Comment{
+	comment := &model.Comment{
 		Body:     body,
 		FilePath: lrc.FilePath,
 		Line:     lrc.Line,
@@ -257,6 +358,10 @@ func reviewToComment(language model.Language, lrc *model.ReviewAIComment) *model
 		Position: lrc.Position,
 		Type:     model.CommentTypeReview,
 	}
+	if lrc.IsRangeComment() {
+		comment.StartLine, comment.EndLine = lrc.Line, lrc.EndLine
+	}
+	return comment
 }
 
 // detectProgrammingLanguage detects programming language from file path
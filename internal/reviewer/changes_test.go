@@ -0,0 +1,376 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/provider/local"
+	"github.com/maxbolgarin/errm"
+)
+
+const emptyReviewResponse = `{"file": "", "comments": [], "has_issues": false}`
+
+// stubAPI is the model of tests, code review calls are counted and answered by review,
+// other calls are answered by text or get a fixed text
+type stubAPI struct {
+	review func(ctx context.Context, call int) (string, error)
+	text   func(ctx context.Context) (string, error)
+
+	mu            sync.Mutex
+	reviewCalls   int
+	reviewPrompts []string
+}
+
+func (a *stubAPI) CallAPI(ctx context.Context, req model.APIRequest) (model.APIResponse, error) {
+	if req.ResponseType != "application/json" {
+		content := "Stub response."
+		if a.text != nil {
+			var err error
+			if content, err = a.text(ctx); err != nil {
+				return model.APIResponse{}, err
+			}
+		}
+		return model.APIResponse{CreateTime: time.Now(), Content: content}, nil
+	}
+	a.mu.Lock()
+	a.reviewCalls++
+	a.reviewPrompts = append(a.reviewPrompts, req.Prompt)
+	call := a.reviewCalls
+	a.mu.Unlock()
+
+	content := emptyReviewResponse
+	if a.review != nil {
+		var err error
+		if content, err = a.review(ctx, call); err != nil {
+			return model.APIResponse{}, err
+		}
+	}
+	return model.APIResponse{CreateTime: time.Now(), Content: content}, nil
+}
+
+func (a *stubAPI) ValidateModel(context.Context, string) error {
+	return nil
+}
+
+func (a *stubAPI) calls() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.reviewCalls
+}
+
+// prompts returns user prompts of code review calls
+func (a *stubAPI) prompts() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.reviewPrompts)
+}
+
+// countingProvider counts fetched files of the wrapped provider
+type countingProvider struct {
+	interfaces.CodeProvider
+
+	mu      sync.Mutex
+	fetches int
+}
+
+func (p *countingProvider) GetFileContent(ctx context.Context, projectID, filePath, commitSHA string) (string, error) {
+	p.mu.Lock()
+	p.fetches++
+	p.mu.Unlock()
+	return p.CodeProvider.GetFileContent(ctx, projectID, filePath, commitSHA)
+}
+
+// IsMergeRequestEvent accepts every event with a merge request, so events are handled like webhooks
+func (p *countingProvider) IsMergeRequestEvent(event *model.CodeEvent) bool {
+	return event != nil && event.MergeRequest != nil
+}
+
+func (p *countingProvider) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetches
+}
+
+// newFilesDiff returns the diff adding Go files with a function each
+func newFilesDiff(names ...string) string {
+	var diff strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&diff, "diff --git a/%[1]s b/%[1]s\nnew file mode 100644\n--- /dev/null\n+++ b/%[1]s\n", name)
+		diff.WriteString("@@ -0,0 +1,5 @@\n+package sample\n+\n+func Run() int {\n+\treturn 1\n+}\n")
+	}
+	return diff.String()
+}
+
+// testConfig is the config of the reviewer in tests, files of the diffs aren't filtered by size
+func testConfig() Config {
+	return Config{FileFilter: FileFilter{MaxFileSize: 10000}}
+}
+
+// newTestReviewer creates the reviewer of the diff over the local provider and the stub model
+func newTestReviewer(t *testing.T, cfg Config, diff string, api *stubAPI) (*Reviewer, *countingProvider, model.ReviewRequest) {
+	t.Helper()
+	return newTestReviewerWithOriginals(t, cfg, diff, nil, api)
+}
+
+// newTestReviewerWithOriginals creates the reviewer of the diff like newTestReviewer, originals are contents
+// of files of the target branch by path
+func newTestReviewerWithOriginals(t *testing.T, cfg Config, diff string, originals map[string]string, api *stubAPI) (*Reviewer, *countingProvider, model.ReviewRequest) {
+	t.Helper()
+	diffs := model.ParseUnifiedDiff(diff)
+	localProvider := local.New(t.TempDir(), diffs, originals)
+	provider := &countingProvider{CodeProvider: localProvider}
+	codeReviewer, err := New(cfg, provider, agent.NewWithAPI(agent.Config{}, api))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	request := model.ReviewRequest{ProjectID: "local", MergeRequest: localProvider.MergeRequest(), Changes: diffs}
+	return codeReviewer, provider, request
+}
+
+func TestReviewChangesStopsOnCancel(t *testing.T) {
+	files := []string{"a.go", "b.go", "c.go", "d.go"}
+	tests := []struct {
+		name        string
+		cancelAfter int
+	}{
+		{"first file", 1},
+		{"middle file", 2},
+		{"last file", len(files)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			api := &stubAPI{review: func(_ context.Context, call int) (string, error) {
+				if call == tt.cancelAfter {
+					cancel()
+				}
+				return emptyReviewResponse, nil
+			}}
+			codeReviewer, provider, request := newTestReviewer(t, testConfig(), newFilesDiff(files...), api)
+
+			_, err := codeReviewer.ReviewChanges(ctx, request)
+			fetches := provider.count()
+			if tt.cancelAfter < len(files) && !errm.Is(err, context.Canceled) {
+				t.Errorf("ReviewChanges() error = %v, want %v", err, context.Canceled)
+			}
+			if got := api.calls(); got != tt.cancelAfter {
+				t.Errorf("review calls = %d, want %d", got, tt.cancelAfter)
+			}
+
+			// Nothing runs in the background after the return, so the number of calls doesn't grow
+			time.Sleep(50 * time.Millisecond)
+			if got := provider.count(); got != fetches {
+				t.Errorf("file fetches after return = %d, want %d", got, fetches)
+			}
+		})
+	}
+}
+
+// recordingNotifier keeps reports of completed reviews
+type recordingNotifier struct {
+	reports []model.ReviewReport
+}
+
+func (n *recordingNotifier) NotifyReviewComplete(_ context.Context, report model.ReviewReport) error {
+	n.reports = append(n.reports, report)
+	return nil
+}
+
+func TestReviewMergeRequestStageTimeouts(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	// slow is a response of the model that hangs until the call is canceled
+	slow := func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+
+	tests := []struct {
+		name            string
+		timeouts        TimeoutsConfig
+		slowText        bool
+		slowReview      bool
+		wantTimedOut    []string
+		wantReviewCalls int
+	}{
+		{
+			name:            "no timeouts",
+			timeouts:        TimeoutsConfig{Description: timeout, CodeReview: timeout},
+			wantReviewCalls: 1,
+		},
+		{
+			name:            "slow description",
+			timeouts:        TimeoutsConfig{Description: timeout},
+			slowText:        true,
+			wantTimedOut:    []string{stageDescription},
+			wantReviewCalls: 1,
+		},
+		{
+			name:            "slow code review",
+			timeouts:        TimeoutsConfig{CodeReview: timeout},
+			slowReview:      true,
+			wantTimedOut:    []string{stageCodeReview},
+			wantReviewCalls: 1,
+		},
+		{
+			name:       "review deadline",
+			timeouts:   TimeoutsConfig{Review: timeout, CodeReview: time.Hour},
+			slowReview: true,
+			// Stages after the deadline of the review are stopped too
+			wantTimedOut:    []string{stageCodeReview, stageVerdict},
+			wantReviewCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &stubAPI{}
+			if tt.slowText {
+				api.text = slow
+			}
+			if tt.slowReview {
+				api.review = func(ctx context.Context, _ int) (string, error) { return slow(ctx) }
+			}
+			cfg := testConfig()
+			cfg.EnableDescriptionGeneration = true
+			cfg.EnableCodeReview = true
+			cfg.Timeouts = tt.timeouts
+			codeReviewer, _, request := newTestReviewer(t, cfg, newFilesDiff("a.go"), api)
+			notifier := &recordingNotifier{}
+			codeReviewer.SetNotifier(notifier)
+
+			done := make(chan error, 1)
+			go func() {
+				done <- codeReviewer.ReviewMergeRequest(context.Background(), request.ProjectID, request.MergeRequest)
+			}()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("ReviewMergeRequest() error = %v", err)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("ReviewMergeRequest() hangs on the slow model")
+			}
+
+			if len(notifier.reports) != 1 {
+				t.Fatalf("reports = %d, want 1", len(notifier.reports))
+			}
+			if got := notifier.reports[0].TimedOutStages; !slices.Equal(got, tt.wantTimedOut) {
+				t.Errorf("TimedOutStages = %v, want %v", got, tt.wantTimedOut)
+			}
+			if got := api.calls(); got != tt.wantReviewCalls {
+				t.Errorf("review calls = %d, want %d", got, tt.wantReviewCalls)
+			}
+		})
+	}
+}
+
+// inlineComments returns the number of inline comments created by the reviewer
+func inlineComments(provider *local.Provider) int {
+	var count int
+	for _, comment := range provider.Comments() {
+		if comment.Type == model.CommentTypeInline {
+			count++
+		}
+	}
+	return count
+}
+
+func TestHandleEventReadyForReview(t *testing.T) {
+	// The model describes the issue of the same line differently at every review of the file
+	const finding = `{"file": "a.go", "has_issues": true, "comments": [{"file_path": "a.go", "line": 4, "issue_type": "bug", ` +
+		`"confidence": "high", "priority": "high", "title": "Constant result %d", "description": "Run always returns 1.", "suggestion": "Return the computed value."}]}`
+
+	tests := []struct {
+		name            string
+		action          string
+		wantReviewCalls int
+	}{
+		{"new commits of the draft", "synchronize", 1},
+		{"draft is ready for review", model.ActionReadyForReview, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &stubAPI{review: func(_ context.Context, call int) (string, error) { return fmt.Sprintf(finding, call), nil }}
+			cfg := testConfig()
+			cfg.EnableCodeReview = true
+			codeReviewer, provider, request := newTestReviewer(t, cfg, newFilesDiff("a.go"), api)
+			localProvider := provider.CodeProvider.(*local.Provider)
+			ctx := context.Background()
+
+			// The draft is reviewed first
+			if err := codeReviewer.ReviewMergeRequest(ctx, request.ProjectID, request.MergeRequest); err != nil {
+				t.Fatalf("ReviewMergeRequest() error = %v", err)
+			}
+			drafted := inlineComments(localProvider)
+			if drafted == 0 {
+				t.Fatal("no inline comments of the draft")
+			}
+
+			event := &model.CodeEvent{Type: "merge_request", Action: tt.action, ProjectID: request.ProjectID, MergeRequest: request.MergeRequest}
+			if err := codeReviewer.HandleEvent(ctx, event); err != nil {
+				t.Fatalf("HandleEvent() error = %v", err)
+			}
+			if got := api.calls(); got != tt.wantReviewCalls {
+				t.Errorf("review calls = %d, want %d", got, tt.wantReviewCalls)
+			}
+			// Findings of the draft stage are reconciled, not posted again
+			if got := inlineComments(localProvider); got != drafted {
+				t.Errorf("inline comments = %d, want %d", got, drafted)
+			}
+		})
+	}
+}
+
+func TestReviewMergeRequestCommandInjection(t *testing.T) {
+	const title = "Shell command is built from non-literal values"
+
+	tests := []struct {
+		name     string
+		line     string
+		disable  bool
+		wantLine int
+	}{
+		{"shell command with concatenation", `	_ = exec.Command("sh", "-c", "ls "+dir).Run()`, false, 6},
+		{"literal shell command", `	_ = exec.Command("sh", "-c", "ls -la").Run()`, false, 0},
+		{"processor is disabled", `	_ = exec.Command("sh", "-c", "ls "+dir).Run()`, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.EnableCodeReview = true
+			cfg.Processors.CommandInjection.Disable = tt.disable
+			diff := addedFileDiff("run.go", "package sample", "", `import "os/exec"`, "", "func list(dir string) {", tt.line, "}")
+			codeReviewer, provider, request := newTestReviewer(t, cfg, diff, &stubAPI{})
+
+			if err := codeReviewer.ReviewMergeRequest(context.Background(), request.ProjectID, request.MergeRequest); err != nil {
+				t.Fatalf("ReviewMergeRequest() error = %v", err)
+			}
+			var lines []int
+			for _, comment := range provider.CodeProvider.(*local.Provider).Comments() {
+				if comment.Type == model.CommentTypeInline && strings.Contains(comment.Body, title) {
+					lines = append(lines, comment.Line)
+				}
+			}
+			var want []int
+			if tt.wantLine > 0 {
+				want = []int{tt.wantLine}
+			}
+			if !slices.Equal(lines, want) {
+				t.Errorf("command injection comments on lines %v, want %v", lines, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+var checkRunPriorities = []model.ReviewPriority{
+	model.ReviewPriorityCritical,
+	model.ReviewPriorityHigh,
+	model.ReviewPriorityMedium,
+	model.ReviewPriorityBacklog,
+}
+
+// startCheckRun reports in_progress check run on the MR head commit if the provider supports it
+func (s *Reviewer) startCheckRun(ctx context.Context, bundle *reviewBundle) {
+	reporter, ok := s.checkRunReporter()
+	if !ok {
+		return
+	}
+
+	id, err := reporter.CreateOrUpdateCheckRun(ctx, bundle.request.ProjectID, &model.CheckRun{
+		Name:       s.cfg.CheckRun.Name,
+		HeadSHA:    bundle.request.MergeRequest.SHA,
+		Status:     model.CheckRunStatusInProgress,
+		Title:      "Review in progress",
+		DetailsURL: bundle.request.MergeRequest.URL,
+	})
+	if err != nil {
+		bundle.log.Warn("failed to start check run", "error", err)
+		return
+	}
+
+	bundle.checkRunID = id
+}
+
+// finishCheckRun completes the check run with a conclusion derived from the review findings
+func (s *Reviewer) finishCheckRun(ctx context.Context, bundle *reviewBundle) {
+	reporter, ok := s.checkRunReporter()
+	if !ok {
+		return
+	}
+
+	conclusion, title := s.checkRunConclusion(*bundle.result)
+
+	_, err := reporter.CreateOrUpdateCheckRun(ctx, bundle.request.ProjectID, &model.CheckRun{
+		ID:         bundle.checkRunID,
+		Name:       s.cfg.CheckRun.Name,
+		HeadSHA:    bundle.request.MergeRequest.SHA,
+		Status:     model.CheckRunStatusCompleted,
+		Conclusion: conclusion,
+		Title:      title,
+		Summary:    s.buildFindingsSummary(*bundle.result),
+		DetailsURL: bundle.request.MergeRequest.URL,
+	})
+	if err != nil {
+		bundle.log.Warn("failed to complete check run", "error", err)
+		return
+	}
+
+	bundle.log.DebugIf(s.cfg.Verbose, "check run completed", "conclusion", conclusion)
+}
+
+func (s *Reviewer) checkRunReporter() (interfaces.CheckRunReporter, bool) {
+	if !s.cfg.CheckRun.Enable {
+		return nil, false
+	}
+	reporter, ok := s.provider.(interfaces.CheckRunReporter)
+	if !ok {
+		s.log.DebugIf(s.cfg.Verbose, "provider does not support check runs, skipping")
+		return nil, false
+	}
+	return reporter, true
+}
+
+// checkRunConclusion returns failure if any finding reaches the configured priority threshold,
+// neutral if there are other findings or review errors and success otherwise
+func (s *Reviewer) checkRunConclusion(result model.ReviewResult) (model.CheckRunConclusion, string) {
+	var total, failing int
+	for priority, count := range result.FindingsByPriority {
+		total += count
+		if priority.IsAtLeast(s.cfg.CheckRun.FailurePriority) {
+			failing += count
+		}
+	}
+
+	switch {
+	case failing > 0:
+		return model.CheckRunConclusionFailure, fmt.Sprintf("%d blocking issues found", failing)
+	case total > 0:
+		return model.CheckRunConclusionNeutral, fmt.Sprintf("%d issues found", total)
+	case len(result.Errors) > 0:
+		return model.CheckRunConclusionNeutral, "Review completed with errors"
+	}
+	return model.CheckRunConclusionSuccess, "No issues found"
+}
+
+// buildFindingsSummary renders findings by priority with the same badges as review comments
+func (s *Reviewer) buildFindingsSummary(result model.ReviewResult) string {
+	var sb strings.Builder
+
+	sb.WriteString(s.buildPriorityTable(result.FindingsByPriority))
+
+	sb.WriteString(fmt.Sprintf("\nReviewed files: %d, created comments: %d", result.ProcessedFiles, result.CommentsCreated))
+	if len(result.Errors) > 0 {
+		sb.WriteString(fmt.Sprintf(", errors: %d", len(result.Errors)))
+	}
+	if len(result.TimedOutStages) > 0 {
+		sb.WriteString(", timed out stages: " + strings.Join(result.TimedOutStages, ", "))
+	}
+
+	return sb.String()
+}
+
+// buildPriorityTable renders the number of findings by priority
+func (s *Reviewer) buildPriorityTable(counts map[model.ReviewPriority]int) string {
+	var sb strings.Builder
+
+	sb.WriteString("| Priority | Findings |\n|---|---|\n")
+	for _, priority := range checkRunPriorities {
+		sb.WriteString(fmt.Sprintf("| %s | %d |\n", s.priorityLabel(priority), counts[priority]))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,143 @@
+package reviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// findingClusterSimilarity is the minimal share of common words of normalized titles in one cluster
+const findingClusterSimilarity = 0.75
+
+// inlineCodeRegex matches identifiers in backticks, they differ between files reporting the same issue
+var inlineCodeRegex = regexp.MustCompile("`[^`]*`")
+
+// findingCluster is a group of findings of one issue type with similar titles
+type findingCluster struct {
+	words    map[string]struct{} // normalized title words of the first finding
+	indexes  []int
+	filesSet map[string]struct{}
+}
+
+// clusterFindings collapses findings of one issue type with similar titles reported in at least threshold files
+// into one file-less finding that lists all affected locations; other findings are returned in their order
+func clusterFindings(findings []*model.ReviewAIComment, threshold int) []*model.ReviewAIComment {
+	if threshold <= 1 || len(findings) < threshold {
+		return findings
+	}
+
+	var clusters []*findingCluster
+	for i, finding := range findings {
+		words := normalizedTitleWords(finding)
+		if len(words) == 0 {
+			continue
+		}
+
+		var target *findingCluster
+		for _, cluster := range clusters {
+			if findings[cluster.indexes[0]].IssueType == finding.IssueType && wordsSimilarity(cluster.words, words) >= findingClusterSimilarity {
+				target = cluster
+				break
+			}
+		}
+		if target == nil {
+			target = &findingCluster{words: words, filesSet: make(map[string]struct{})}
+			clusters = append(clusters, target)
+		}
+		target.indexes = append(target.indexes, i)
+		target.filesSet[finding.FilePath] = struct{}{}
+	}
+
+	// Collapsed cluster takes the place of its first finding
+	collapsed := make(map[int]*model.ReviewAIComment)
+	clustered := make(map[int]struct{})
+	for _, cluster := range clusters {
+		if len(cluster.filesSet) < threshold {
+			continue
+		}
+		members := make([]*model.ReviewAIComment, 0, len(cluster.indexes))
+		for _, i := range cluster.indexes {
+			members = append(members, findings[i])
+			clustered[i] = struct{}{}
+		}
+		collapsed[cluster.indexes[0]] = collapseFindings(members, len(cluster.filesSet))
+	}
+	if len(collapsed) == 0 {
+		return findings
+	}
+
+	result := make([]*model.ReviewAIComment, 0, len(findings)-len(clustered)+len(collapsed))
+	for i, finding := range findings {
+		if systemic, ok := collapsed[i]; ok {
+			result = append(result, systemic)
+			continue
+		}
+		if _, ok := clustered[i]; !ok {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+// collapseFindings returns one systemic finding with the highest priority of members and the list of their locations
+func collapseFindings(members []*model.ReviewAIComment, files int) *model.ReviewAIComment {
+	representative := members[0]
+	for _, member := range members[1:] {
+		if member.Priority != representative.Priority && member.Priority.IsAtLeast(representative.Priority) {
+			representative = member
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(representative.Description)
+	sb.WriteString(fmt.Sprintf("\n\n**The issue recurs in %d files:**\n", files))
+	for _, member := range members {
+		sb.WriteString("- `" + member.FilePath + "`")
+		if member.Line > 0 {
+			sb.WriteString(fmt.Sprintf(" L%d", member.Line))
+		}
+		if member.Title != representative.Title {
+			sb.WriteString(": " + member.Title)
+		}
+		sb.WriteString("\n")
+	}
+
+	systemic := *representative
+	systemic.FilePath = ""
+	systemic.Line, systemic.EndLine, systemic.OldLine, systemic.Position = 0, 0, 0, 0
+	systemic.Description = strings.TrimSpace(sb.String())
+	systemic.CodeSnippet = ""
+	return &systemic
+}
+
+// normalizedTitleWords returns lowercased words of the title without identifiers in backticks
+func normalizedTitleWords(finding *model.ReviewAIComment) map[string]struct{} {
+	title := inlineCodeRegex.ReplaceAllString(finding.Title, " ")
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		words[field] = struct{}{}
+	}
+	return words
+}
+
+// wordsSimilarity returns the Jaccard index of two word sets
+func wordsSimilarity(a, b map[string]struct{}) float64 {
+	var common int
+	for word := range a {
+		if _, ok := b[word]; ok {
+			common++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
@@ -0,0 +1,187 @@
+package reviewer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+const (
+	commentTemplateExt         = ".tmpl"
+	defaultCommentTemplateName = "default"
+)
+
+// defaultCommentTemplate renders findings of all issue types if there is no template for the type
+const defaultCommentTemplate = `## {{if .Badge}}{{.Badge}} · {{end}}{{.Header}}
+
+**{{.ConfidenceHeader}}**: {{.Confidence}}
+**{{.PriorityHeader}}**: {{.Priority}}{{if .Rule}}
+**Rule**: {{.Rule}}{{end}}{{if .CommitSHA}}
+**Commit**: {{.CommitSHA}}{{if .FilePath}} · ` + "`{{.FilePath}}`" + `{{if .Line}} L{{.Line}}{{end}}{{end}}{{end}}
+
+{{if .Title}}### {{.Title}}
+
+{{end}}{{if .Description}}{{.Description}}
+
+{{end}}{{if .Suggestion}}### {{.SuggestionHeader}}
+
+{{.Suggestion}}{{if .CodeSnippet}}
+
+{{codeBlock .CodeSnippet .CodeLanguage}}{{end}}{{end}}`
+
+// commentData is the finding passed to comment templates, headers and levels are in the review language
+type commentData struct {
+	IssueType    model.IssueType
+	Header       string // header of the issue type
+	Badge        string // severity badge, empty if badges are disabled
+	Title        string
+	Description  string
+	Suggestion   string
+	CodeSnippet  string
+	CodeLanguage string
+	Confidence   string
+	Priority     string
+	FilePath     string
+	Line         int
+	CommitSHA    string // reviewed commit if the merge request is reviewed commit by commit
+	Rule         string // heading of the violated project rule
+
+	ConfidenceHeader string
+	PriorityHeader   string
+	SuggestionHeader string
+}
+
+// commentTemplates renders comment bodies with templates keyed by issue type
+type commentTemplates struct {
+	byType   map[model.IssueType]*template.Template
+	fallback *template.Template
+}
+
+var commentTemplateFuncs = template.FuncMap{
+	"codeBlock": codeBlock,
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+}
+
+// loadCommentTemplates parses the default template and templates from dir named by issue type,
+// e.g. security.tmpl; default.tmpl replaces the default template. Every template is executed
+// with a sample finding, so errors are reported at startup instead of on the first comment
+func loadCommentTemplates(dir string) (*commentTemplates, error) {
+	fallback, err := parseCommentTemplate(defaultCommentTemplateName, defaultCommentTemplate)
+	if err != nil {
+		return nil, errm.Wrap(err, "parse default template")
+	}
+	templates := &commentTemplates{
+		byType:   make(map[model.IssueType]*template.Template),
+		fallback: fallback,
+	}
+	if dir == "" {
+		return templates, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+commentTemplateExt))
+	if err != nil {
+		return nil, errm.Wrap(err, "list templates")
+	}
+	if len(paths) == 0 {
+		return nil, errm.Errorf("no %s templates in %s", commentTemplateExt, dir)
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), commentTemplateExt)
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errm.Wrap(err, "read template "+path)
+		}
+		tmpl, err := parseCommentTemplate(name, string(text))
+		if err != nil {
+			return nil, errm.Wrap(err, "invalid template "+path)
+		}
+		if name == defaultCommentTemplateName {
+			templates.fallback = tmpl
+		} else {
+			templates.byType[model.IssueType(name)] = tmpl
+		}
+	}
+
+	return templates, nil
+}
+
+// parseCommentTemplate parses the template and checks that it renders a sample finding
+func parseCommentTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(commentTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	sample := commentData{
+		IssueType:    model.IssueType(name),
+		Header:       "Header",
+		Badge:        "Badge",
+		Title:        "Title",
+		Description:  "Description",
+		Suggestion:   "Suggestion",
+		CodeSnippet:  "code",
+		CodeLanguage: "go",
+		Confidence:   "High",
+		Priority:     "High",
+		FilePath:     "main.go",
+		Line:         1,
+		CommitSHA:    "0123456789abcdef0123456789abcdef01234567",
+		Rule:         "Error handling",
+	}
+	if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// render renders the comment body with the template of the issue type or the default one
+func (ct *commentTemplates) render(data commentData) (string, error) {
+	tmpl, ok := ct.byType[data.IssueType]
+	if !ok {
+		tmpl = ct.fallback
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", errm.Wrap(err, "render template "+tmpl.Name())
+	}
+	return sb.String(), nil
+}
+
+// newCommentData converts the finding to template data in the review language
+func newCommentData(language model.Language, badge string, lrc *model.ReviewAIComment) commentData {
+	headers := prompts.DefaultLanguages[language].CodeReviewHeaders
+	return commentData{
+		IssueType:        lrc.IssueType,
+		Header:           headers.GetByType(lrc.IssueType),
+		Badge:            badge,
+		Title:            lrc.Title,
+		Description:      lrc.Description,
+		Suggestion:       lrc.Suggestion,
+		CodeSnippet:      lrc.CodeSnippet,
+		CodeLanguage:     lrc.CodeLanguage,
+		Confidence:       headers.GetConfidence(lrc.Confidence),
+		Priority:         headers.GetPriority(lrc.Priority),
+		FilePath:         lrc.FilePath,
+		Line:             lrc.Line,
+		CommitSHA:        lrc.CommitSHA,
+		Rule:             lrc.Rule,
+		ConfidenceHeader: headers.ConfidenceHeader,
+		PriorityHeader:   headers.PriorityHeader,
+		SuggestionHeader: headers.SuggestionHeader,
+	}
+}
+
+// codeBlock wraps the snippet into a fenced code block unless it is already formatted
+func codeBlock(snippet, language string) string {
+	if snippet == "" || strings.HasPrefix(snippet, "`") {
+		return snippet
+	}
+	return "```" + language + "\n" + snippet + "\n```"
+}
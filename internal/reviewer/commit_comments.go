@@ -0,0 +1,60 @@
+package reviewer
+
+import (
+	"context"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/lang"
+)
+
+// PostCommitFindings posts findings of a commit review as comments of the commit: findings at lines get inline
+// comments at their positions in the commit diff, other findings and findings the provider can't place inline
+// are posted as one summary comment; returns the number of created comments and error matching
+// model.ErrNotSupported if the provider can't comment commits
+func (s *Reviewer) PostCommitFindings(ctx context.Context, projectID, sha string, findings []*model.ReviewAIComment) (int, error) {
+	commenter, ok := s.provider.(interfaces.CommitCommenter)
+	if !ok {
+		return 0, errm.Wrap(model.ErrNotSupported, "provider does not support commit comments")
+	}
+	log := s.log.WithFields("project_id", projectID, "commit_sha", lang.TruncateString(sha, 8))
+
+	var (
+		summary []*model.ReviewAIComment
+		created int
+	)
+	for _, finding := range findings {
+		if err := ctx.Err(); err != nil {
+			return created, errm.Wrap(err, "posting interrupted")
+		}
+		if finding.FilePath == "" || finding.Line <= 0 {
+			summary = append(summary, finding)
+			continue
+		}
+
+		comment := s.reviewToComment(finding)
+		comment.Type = model.CommentTypeInline
+		comment.CommitSHA = sha
+		comment.Footer = s.buildCommentFooter(finding)
+		if err := commenter.CreateCommitComment(ctx, projectID, sha, comment); err != nil {
+			if !errm.Is(err, model.ErrNotSupported) {
+				log.Warn("failed to create inline commit comment, the finding is added to the summary", "error", err, "file", finding.FilePath, "line", finding.Line)
+			}
+			summary = append(summary, finding)
+			continue
+		}
+		created++
+	}
+
+	if len(summary) > 0 {
+		comment := &model.Comment{Body: s.RenderFindings(summary), Type: model.CommentTypeGeneral}
+		if err := commenter.CreateCommitComment(ctx, projectID, sha, comment); err != nil {
+			return created, errm.Wrap(err, "failed to create summary commit comment")
+		}
+		created++
+	}
+
+	log.InfoIf(s.cfg.Verbose, "posted commit findings", "findings", len(findings), "comments", created, "in_summary", len(summary))
+	return created, nil
+}
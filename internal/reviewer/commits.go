@@ -0,0 +1,65 @@
+package reviewer
+
+import (
+	"context"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/lang"
+)
+
+// reviewCommits reviews diffs of merge request commits one by one from the oldest, every commit is reviewed
+// against its first parent and its findings reference it; files are filtered as in the whole review
+// and files skipped due to the limit of files per review are not reviewed in commits
+func (s *Reviewer) reviewCommits(ctx context.Context, bundle *reviewBundle, reader interfaces.CommitReader) {
+	commits, err := reader.GetMergeRequestCommits(ctx, bundle.request.ProjectID, bundle.request.MergeRequest.IID)
+	if err != nil {
+		msg := "failed to get merge request commits"
+		bundle.log.Err(err, msg)
+		bundle.result.Errors = append(bundle.result.Errors, errm.Wrap(err, msg))
+		return
+	}
+
+	for _, commit := range commits {
+		if err := ctx.Err(); err != nil {
+			bundle.log.Warn("code review interrupted", "error", err)
+			bundle.result.Errors = append(bundle.result.Errors, errm.Wrap(err, "code review interrupted"))
+			return
+		}
+		log := bundle.log.WithFields("commit_sha", lang.TruncateString(commit.SHA, 8))
+
+		diffs, err := reader.GetCommitDiffs(ctx, bundle.request.ProjectID, commit.SHA)
+		if err != nil {
+			msg := "failed to get commit diffs"
+			log.Err(err, msg)
+			bundle.result.Errors = append(bundle.result.Errors, errm.Wrap(err, msg, "commit_sha", commit.SHA))
+			continue
+		}
+
+		request := commitReviewRequest(bundle.request, commit, diffs)
+		s.restoreTooLargeDiffs(ctx, request, log)
+		files, _, _ := s.filterFilesForReview(request, log)
+		files = bundle.fileLimit.filter(s.ownedFiles(files))
+		if len(files) == 0 {
+			continue
+		}
+
+		log.InfoIf(s.cfg.Verbose, "reviewing commit", "files", len(files), "message", lang.TruncateString(commit.Message, 60))
+		s.reviewFiles(ctx, bundle, request, files, commit.SHA)
+	}
+}
+
+// commitReviewRequest returns the request of the commit diffs: the commit is the head
+// and its parent is the original version of files
+func commitReviewRequest(request model.ReviewRequest, commit *model.Commit, diffs []*model.FileDiff) model.ReviewRequest {
+	mr := *request.MergeRequest
+	mr.SHA = commit.SHA
+	if commit.ParentSHA != "" {
+		mr.TargetBranch = commit.ParentSHA
+	}
+
+	request.MergeRequest = &mr
+	request.Changes = diffs
+	return request
+}
@@ -1,9 +1,18 @@
 package reviewer
 
 import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
 	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/codry/internal/reviewer/processor"
+	"github.com/maxbolgarin/errm"
 )
 
 const (
@@ -15,28 +24,765 @@ const (
 
 	startMarkerArchitecture = "<!-- Codry: ai-architecture-start -->"
 	endMarkerArchitecture   = "<!-- Codry: ai-architecture-end -->"
+
+	startMarkerReport = "<!-- Codry: ai-report-start -->"
+	endMarkerReport   = "<!-- Codry: ai-report-end -->"
+
+	startMarkerFindings = "<!-- Codry: ai-findings-start -->"
+	endMarkerFindings   = "<!-- Codry: ai-findings-end -->"
+
+	startMarkerFileLimit = "<!-- Codry: ai-file-limit-start -->"
+	endMarkerFileLimit   = "<!-- Codry: ai-file-limit-end -->"
+
+	defaultCheckRunName = "Codry Review"
+
+	defaultSkipLabel             = "codry:skip"
+	defaultChangesRequestedLabel = "codry:changes-requested"
+	defaultApprovedLabel         = "codry:approved"
+
+	defaultExternalReportThreshold = 20
+	defaultExternalReportTopInline = 5
+
+	defaultDiffContextLines = 15
+
+	defaultFindingClustersThreshold = 3
+
+	defaultInstructionsMaxLength = 2000
+	defaultIntentMaxLength       = 2000
+	defaultRulesMaxLength        = 8000
+
+	defaultConfigChangesMaxKeys       = 100
+	defaultConfigChangesMinDependents = 3
+
+	defaultTriageMaxReviewTokens = 100000
+
+	defaultArtifactsMaxTextSize = 1 << 20
+
+	defaultReviewTimeout             = 30 * time.Minute
+	defaultDescriptionTimeout        = 5 * time.Minute
+	defaultChangesOverviewTimeout    = 5 * time.Minute
+	defaultArchitectureReviewTimeout = 10 * time.Minute
+	defaultCodeReviewTimeout         = 20 * time.Minute
+	defaultVerdictTimeout            = 2 * time.Minute
+
+	defaultFooterTemplate = "🤖 codry • {model} • confidence {confidence} • reply `/codry ignore` to dismiss"
 )
 
 type Config struct {
-	FileFilter             FileFilter    `yaml:"file_filter"`
+	FileFilter FileFilter `yaml:"file_filter"`
+	// MaxFilesPerMR is the former limit of files per review, the smaller of it and MaxFilesPerReview is used
 	MaxFilesPerMR          int           `yaml:"max_files_per_mr" env:"REVIEW_MAX_FILES_PER_MR"`
 	MinFilesForDescription int           `yaml:"min_files_for_description" env:"REVIEW_MIN_FILES_FOR_DESCRIPTION"`
 	ProcessingDelay        time.Duration `yaml:"processing_delay" env:"REVIEW_PROCESSING_DELAY"`
+	// MaxArchitectureInputSize limits the size in bytes of change summaries sent to the architecture review
+	MaxArchitectureInputSize int `yaml:"max_architecture_input_size" env:"REVIEW_MAX_ARCHITECTURE_INPUT_SIZE"`
+	// MaxFilesPerReview limits files reviewed by the model one by one, files with the highest risk are chosen
+	// and the rest are listed in a comment; other stages see all files, 0 means no limit
+	MaxFilesPerReview int `yaml:"max_files_per_review" env:"REVIEW_MAX_FILES_PER_REVIEW"`
 
 	UpdateDescriptionOnMR           bool `yaml:"update_description_on_mr" env:"REVIEW_UPDATE_DESCRIPTION_ON_MR"`
 	EnableDescriptionGeneration     bool `yaml:"enable_description_generation" env:"REVIEW_ENABLE_DESCRIPTION_GENERATION"`
 	EnableChangesOverviewGeneration bool `yaml:"enable_changes_overview_generation" env:"REVIEW_ENABLE_CHANGES_OVERVIEW_GENERATION"`
 	EnableArchitectureReview        bool `yaml:"enable_architecture_review" env:"REVIEW_ENABLE_ARCHITECTURE_REVIEW"`
+	// ArchitectureReviewInDescription puts the architecture review into a codry section of the MR description
+	// below the generated description instead of a comment, the section is updated in place on re-review
+	ArchitectureReviewInDescription bool `yaml:"architecture_review_in_description" env:"REVIEW_ARCHITECTURE_REVIEW_IN_DESCRIPTION"`
 	EnableCodeReview                bool `yaml:"enable_code_review" env:"REVIEW_ENABLE_CODE_REVIEW"`
+	// ResolveAddressedComments resolves threads of inline findings of previous reviews whose lines were changed,
+	// it is supported by GitLab and GitHub and needs the comment footer to recognize findings
+	ResolveAddressedComments bool `yaml:"resolve_addressed_comments" env:"REVIEW_RESOLVE_ADDRESSED_COMMENTS"`
+	// CommentAnchoring stores a hash of the commented line with its neighbors in inline findings, so on re-review
+	// findings whose code moved are relocated to their new lines and findings already posted are not repeated
+	CommentAnchoring bool `yaml:"comment_anchoring" env:"REVIEW_COMMENT_ANCHORING"`
+
+	DiffContext DiffContextConfig `yaml:"diff_context"`
+
+	CheckRun CheckRunConfig `yaml:"check_run"`
+	Labels   LabelsConfig   `yaml:"labels"`
+	Footer   FooterConfig   `yaml:"footer"`
+	Severity SeverityConfig `yaml:"severity"`
+
+	ExternalReport ExternalReportConfig `yaml:"external_report"`
+	Processors     ProcessorsConfig     `yaml:"processors"`
+	// CommentMode defines how findings are posted: inline (default), summary or single, the last two
+	// post one general comment updated on every review and don't need inline positions
+	CommentMode CommentMode `yaml:"comment_mode" env:"REVIEW_COMMENT_MODE"`
+	// ReviewScope defines what the code review stage reviews: the whole diff of the merge request (default)
+	// or diffs of its commits one by one, findings of commits reference them and are posted as general comments
+	ReviewScope ReviewScope `yaml:"review_scope" env:"REVIEW_SCOPE"`
+	// DiffSource defines diffs of the merge request: merge_request (default) or merge_result, the result of merging
+	// it into the current target branch that shows problems appearing only after the merge; the diff of the merge
+	// request is used if the provider has no merge result or the merge request has conflicts; inline comments
+	// are posted to lines of the head commit, so they may be shifted in files also changed in the target branch
+	DiffSource model.DiffSource `yaml:"diff_source" env:"REVIEW_DIFF_SOURCE"`
+	// FindingClusters represents collapsing of the same issue reported in many files into one systemic finding
+	FindingClusters FindingClustersConfig `yaml:"finding_clusters"`
+	// CommentTemplates represents templates of review comment bodies by issue type
+	CommentTemplates CommentTemplatesConfig `yaml:"comment_templates"`
+	// OwnershipScope are paths (glob or substring) of files whose findings this instance posts, the code review
+	// of other files is left to other instances of the monorepo while all files are still used as context;
+	// all files are owned if it is empty
+	OwnershipScope []string `yaml:"ownership_scope" env:"REVIEW_OWNERSHIP_SCOPE"`
+	// StackedReview represents review of merge requests targeting the source branch of another open one
+	StackedReview StackedReviewConfig `yaml:"stacked_review"`
+	// Redaction represents masking of secrets and personal data in content sent to the model
+	Redaction RedactionConfig `yaml:"redaction"`
+	// Suppression represents inline comments in the code that suppress findings of their lines
+	Suppression SuppressionConfig `yaml:"suppression"`
+	// Strictness represents the persona of the code review: the prompt directive and the filter of findings
+	Strictness StrictnessConfig `yaml:"strictness"`
+	// Instructions represents custom instructions added to the code and architecture review prompts
+	Instructions InstructionsConfig `yaml:"instructions"`
+	// Intent represents the title and the description of the merge request added to the review prompts
+	Intent IntentConfig `yaml:"intent"`
+	// Rules represents project rules documents added to the code review prompt
+	Rules RulesConfig `yaml:"rules"`
+	// Timeouts limit durations of the whole review and its stages
+	Timeouts TimeoutsConfig `yaml:"timeouts"`
+	// Criticality represents weighting of findings by criticality of their files
+	Criticality CriticalityConfig `yaml:"criticality"`
+	// RawFindings represents the cache of findings of the model before processing and weighting
+	RawFindings RawFindingsConfig `yaml:"raw_findings"`
+	// ConfigChanges represents key-level review of changed JSON, YAML and TOML files
+	ConfigChanges ConfigChangesConfig `yaml:"config_changes"`
+	// Artifacts represents the check of accidentally committed binary and large files
+	Artifacts ArtifactsConfig `yaml:"artifacts"`
+	// Triage represents the choice of files for the code review of large merge requests by the risk of their changes
+	Triage TriageConfig `yaml:"triage"`
+	// Search represents codebase-wide searches for callers, project files and readers of config keys
+	Search SearchConfig `yaml:"search"`
+	// Callers represents snippets of unchanged functions calling changed ones added to the code review prompt
+	Callers CallersConfig `yaml:"callers"`
 
 	Language model.Language `yaml:"language" env:"REVIEW_LANGUAGE"`
 	Verbose  bool           `yaml:"verbose" env:"REVIEW_VERBOSE"`
 }
 
+// CommentMode defines how review findings are posted to the merge request
+type CommentMode string
+
+const (
+	CommentModeInline  CommentMode = "inline"  // every finding is an inline comment on its line
+	CommentModeSummary CommentMode = "summary" // one general comment with a table of findings
+	CommentModeSingle  CommentMode = "single"  // one general comment with full findings grouped by file
+)
+
+var supportedCommentModes = []CommentMode{CommentModeInline, CommentModeSummary, CommentModeSingle}
+
+// ReviewScope defines diffs reviewed by the code review stage
+type ReviewScope string
+
+const (
+	ReviewScopeMergeRequest ReviewScope = "merge_request" // the final diff of the merge request against its target
+	ReviewScopeCommits      ReviewScope = "commits"       // diff of every commit against its parent
+)
+
+var supportedReviewScopes = []ReviewScope{ReviewScopeMergeRequest, ReviewScopeCommits}
+
+var supportedStrictnessLevels = []prompts.Strictness{prompts.StrictnessStrict, prompts.StrictnessBalanced, prompts.StrictnessLightweight}
+
+var supportedDiffSources = []model.DiffSource{model.DiffSourceMergeRequest, model.DiffSourceMergeResult}
+
 // FileFilter represents criteria for filtering files to review
 type FileFilter struct {
 	MaxFileSize       int      `yaml:"max_file_size" env:"REVIEW_FILE_FILTER_MAX_FILE_SIZE"`
 	AllowedExtensions []string `yaml:"allowed_extensions" env:"REVIEW_FILE_FILTER_ALLOWED_EXTENSIONS"`
 	ExcludedPaths     []string `yaml:"excluded_paths" env:"REVIEW_FILE_FILTER_EXCLUDED_PATHS"`
 	IncludeOnlyCode   bool     `yaml:"include_only_code" env:"REVIEW_FILE_FILTER_INCLUDE_ONLY_CODE"`
+	// AnalyzeExtensions limits review to files with these extensions like .go or .d.ts, all files are reviewed if empty
+	AnalyzeExtensions []string `yaml:"analyze_extensions" env:"REVIEW_FILE_FILTER_ANALYZE_EXTENSIONS"`
+	// IgnoreExtensions are extensions of files that are never reviewed like .md, .lock or .svg
+	IgnoreExtensions []string `yaml:"ignore_extensions" env:"REVIEW_FILE_FILTER_IGNORE_EXTENSIONS"`
+}
+
+// DiffContextConfig represents the part of the original file sent to the file review:
+// changed regions with surrounding lines, it balances token cost and context of the review
+type DiffContextConfig struct {
+	// Lines is the number of lines around each changed region, negative sends the whole file
+	Lines int `yaml:"lines" env:"REVIEW_DIFF_CONTEXT_LINES"`
+	// Languages overrides Lines for languages like go, python or typescript
+	Languages map[string]int `yaml:"languages"`
+}
+
+// CheckRunConfig represents settings of the status check reported on the MR head commit
+type CheckRunConfig struct {
+	Enable bool   `yaml:"enable" env:"REVIEW_CHECK_RUN_ENABLE"`
+	Name   string `yaml:"name" env:"REVIEW_CHECK_RUN_NAME"`
+	// FailurePriority is the lowest finding priority that fails the check, findings below it give neutral conclusion
+	FailurePriority model.ReviewPriority `yaml:"failure_priority" env:"REVIEW_CHECK_RUN_FAILURE_PRIORITY"`
+}
+
+// StackedReviewConfig represents review of stacked merge requests: if the target branch of the merge request
+// has an open merge request itself, only changes made on top of its head are reviewed
+type StackedReviewConfig struct {
+	Enable bool `yaml:"enable" env:"REVIEW_STACKED_REVIEW_ENABLE"`
+}
+
+// RedactionConfig represents masking of secrets, emails and custom patterns in diffs and file content
+// sent to the model, a value is replaced with the same placeholder in all prompts of the review
+// and line numbers are kept; the number of masked values is logged after every review
+type RedactionConfig struct {
+	Enable bool `yaml:"enable" env:"REVIEW_REDACTION_ENABLE"`
+	// Patterns are regular expressions of additional values to mask like internal hostnames, they are matched within a line
+	Patterns []string `yaml:"patterns" env:"REVIEW_REDACTION_PATTERNS"`
+	// Allowlist are path patterns (glob or substring) of files sent as is like test fixtures, testdata/ and fixtures/ by default
+	Allowlist []string `yaml:"allowlist" env:"REVIEW_REDACTION_ALLOWLIST"`
+}
+
+// SuppressionConfig represents inline directives like `// codry:ignore performance,bug` that suppress findings
+// of their line, or of the next line if the comment is alone on its line; a directive without issue types
+// or with "all" suppresses all findings, text after the list is a reason. Suppressed findings are counted in reports
+type SuppressionConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_SUPPRESSION_DISABLE"`
+	// Directive is the word after the comment marker, "codry:ignore" by default
+	Directive string `yaml:"directive" env:"REVIEW_SUPPRESSION_DIRECTIVE"`
+	// CommentMarkers start comments with directives, "//", "#" and "--" by default
+	CommentMarkers []string `yaml:"comment_markers" env:"REVIEW_SUPPRESSION_COMMENT_MARKERS"`
+}
+
+// StrictnessConfig represents the preset of the code review persona that tunes the prompt and the filter of findings
+// together: strict reports everything, balanced keeps the prompt and drops findings below medium confidence,
+// lightweight asks only for real bugs and drops findings below high priority and high confidence;
+// knobs of the preset can be overridden one by one
+type StrictnessConfig struct {
+	// Level is strict, balanced or lightweight, balanced by default
+	Level prompts.Strictness `yaml:"level" env:"REVIEW_STRICTNESS_LEVEL"`
+	// MinPriority is the lowest priority of reported findings, high for lightweight and backlog for other levels
+	MinPriority model.ReviewPriority `yaml:"min_priority" env:"REVIEW_STRICTNESS_MIN_PRIORITY"`
+	// MinConfidence is the lowest confidence of reported findings, high for lightweight, medium for balanced
+	// and low for strict; very_high or high is a high precision mode, it composes with MinPriority
+	MinConfidence model.ReviewConfidence `yaml:"min_confidence" env:"REVIEW_STRICTNESS_MIN_CONFIDENCE"`
+	// DisablePromptDirective keeps the prompt without the directive of the level, findings are still filtered
+	DisablePromptDirective bool `yaml:"disable_prompt_directive" env:"REVIEW_STRICTNESS_DISABLE_PROMPT_DIRECTIVE"`
+}
+
+// LabelsConfig represents labels that opt merge request in or out of review,
+// for providers without labels (Bitbucket) a [label] marker in the description is used instead
+type LabelsConfig struct {
+	// Skip is a label that disables review of the merge request
+	Skip string `yaml:"skip" env:"REVIEW_LABELS_SKIP"`
+	// Require is a label that must be set to review the merge request, all merge requests are reviewed if it is empty
+	Require string `yaml:"require" env:"REVIEW_LABELS_REQUIRE"`
+	// Outcome represents labels reflecting the outcome of the code review (GitHub and GitLab)
+	Outcome OutcomeLabelsConfig `yaml:"outcome"`
+}
+
+// OutcomeLabelsConfig represents the label set after the code review: changes requested if any finding reaches
+// the priority and approved otherwise; the other outcome label is removed, a review with errors gets no label
+type OutcomeLabelsConfig struct {
+	Enable           bool   `yaml:"enable" env:"REVIEW_LABELS_OUTCOME_ENABLE"`
+	ChangesRequested string `yaml:"changes_requested" env:"REVIEW_LABELS_OUTCOME_CHANGES_REQUESTED"`
+	Approved         string `yaml:"approved" env:"REVIEW_LABELS_OUTCOME_APPROVED"`
+	// Priority is the lowest finding priority that requests changes, high by default
+	Priority model.ReviewPriority `yaml:"priority" env:"REVIEW_LABELS_OUTCOME_PRIORITY"`
+}
+
+// FooterConfig represents the footer appended to review comments,
+// template supports {model}, {confidence} and {priority} placeholders
+type FooterConfig struct {
+	Disable  bool   `yaml:"disable" env:"REVIEW_FOOTER_DISABLE"`
+	Template string `yaml:"template" env:"REVIEW_FOOTER_TEMPLATE"`
+}
+
+// CommentTemplatesConfig represents Go text/template templates that render review comments from findings,
+// the built-in template is used for issue types without a template
+type CommentTemplatesConfig struct {
+	// Dir is a directory with templates named by issue type like security.tmpl or refactor.tmpl,
+	// default.tmpl replaces the built-in template; templates are validated at startup
+	Dir string `yaml:"dir" env:"REVIEW_COMMENT_TEMPLATES_DIR"`
+}
+
+// SeverityConfig represents visual severity of findings and the review verdict derived from it
+type SeverityConfig struct {
+	// Badges maps finding priority to a badge shown in comments and summaries, e.g. critical: "🔴 Critical",
+	// default badges are used if it is not set, set it to empty map to disable badges
+	Badges map[model.ReviewPriority]string `yaml:"badges"`
+	// RequestChangesPriority is the lowest finding priority that makes the review request changes
+	// (GitHub only), it is disabled if empty
+	RequestChangesPriority model.ReviewPriority `yaml:"request_changes_priority" env:"REVIEW_SEVERITY_REQUEST_CHANGES_PRIORITY"`
+}
+
+// ExternalReportConfig represents publishing of huge reviews as an external document (GitHub gist),
+// only a link comment and the most important findings are posted to the merge request
+type ExternalReportConfig struct {
+	Enable bool `yaml:"enable" env:"REVIEW_EXTERNAL_REPORT_ENABLE"`
+	// Threshold is the number of findings above which they are published to the external document
+	Threshold int `yaml:"threshold" env:"REVIEW_EXTERNAL_REPORT_THRESHOLD"`
+	// TopInline is the number of the most important findings that are still posted inline
+	TopInline int `yaml:"top_inline" env:"REVIEW_EXTERNAL_REPORT_TOP_INLINE"`
+}
+
+// FindingClustersConfig represents grouping of findings with the same issue type and similar titles across files,
+// a cluster is posted as one general comment listing affected files instead of an inline comment per file
+type FindingClustersConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_FINDING_CLUSTERS_DISABLE"`
+	// Threshold is the minimal number of files with the issue to collapse its findings, 3 by default
+	Threshold int `yaml:"threshold" env:"REVIEW_FINDING_CLUSTERS_THRESHOLD"`
+}
+
+// ProcessorsConfig represents built-in deterministic finding processors
+type ProcessorsConfig struct {
+	// ForbiddenImports are import paths (with subpackages) that must not be added,
+	// the list is extended with imports.forbidden of .codry.yml in the target branch of the reviewed repository
+	ForbiddenImports []string `yaml:"forbidden_imports" env:"REVIEW_PROCESSORS_FORBIDDEN_IMPORTS"`
+	// PreferredImports are alternatives of forbidden imports in "forbidden -> preferred" format
+	PreferredImports []string `yaml:"preferred_imports" env:"REVIEW_PROCESSORS_PREFERRED_IMPORTS"`
+	// LicenseHeader is the license text which new files must start with, only its first line is checked
+	LicenseHeader string `yaml:"license_header" env:"REVIEW_PROCESSORS_LICENSE_HEADER"`
+
+	// ErrorChecks represents detection of discarded errors in changed Go code
+	ErrorChecks ErrorChecksConfig `yaml:"error_checks"`
+	// DocComments represents detection of added exported Go symbols without doc comments
+	DocComments DocCommentsConfig `yaml:"doc_comments"`
+	// TestCoverage represents detection of changed exported entities not referenced in tests
+	TestCoverage TestCoverageConfig `yaml:"test_coverage"`
+	// FunctionSize represents detection of changed Go functions exceeding length and parameter limits
+	FunctionSize FunctionSizeConfig `yaml:"function_size"`
+	// ConstantChanges represents detection of exported Go constants with changed values
+	ConstantChanges ConstantChangesConfig `yaml:"constant_changes"`
+	// Dockerfile represents checks of changed Dockerfiles
+	Dockerfile DockerfileChecksConfig `yaml:"dockerfile"`
+	// ShellScripts represents checks of changed shell scripts
+	ShellScripts ShellScriptChecksConfig `yaml:"shell_scripts"`
+	// DebugArtifacts represents detection of debugging leftovers in added lines
+	DebugArtifacts DebugArtifactsConfig `yaml:"debug_artifacts"`
+	// InterfaceDrift represents detection of changed Go methods breaking asserted interface implementations
+	InterfaceDrift InterfaceDriftConfig `yaml:"interface_drift"`
+	// PanicChecks represents detection of panic-prone expressions in added Go code
+	PanicChecks PanicChecksConfig `yaml:"panic_checks"`
+	// ResourceLeaks represents detection of Go resources acquired without a release in the same function
+	ResourceLeaks ResourceLeaksConfig `yaml:"resource_leaks"`
+	// LoopCalls represents detection of network and database calls inside loops of changed code
+	LoopCalls LoopCallsConfig `yaml:"loop_calls"`
+	// SerializedFields represents detection of breaking changes of Go struct fields with serialization tags
+	SerializedFields SerializedFieldsConfig `yaml:"serialized_fields"`
+	// NewDependencies represents listing of direct dependencies added to go.mod files, it is disabled by default
+	NewDependencies NewDependenciesConfig `yaml:"new_dependencies"`
+	// Naming represents detection of Go identifiers introduced with mixed-case initialisms
+	Naming NamingChecksConfig `yaml:"naming"`
+	// CommandInjection represents detection of shell commands built from non-literal values in added lines
+	CommandInjection CommandInjectionConfig `yaml:"command_injection"`
+	// Secrets represents detection of secrets hardcoded in added lines, its allowlist is also used to rank files
+	Secrets SecretsChecksConfig `yaml:"secrets"`
+}
+
+// ErrorChecksConfig represents detection of discarded errors in changed Go code
+type ErrorChecksConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_ERROR_CHECKS_DISABLE"`
+	// Ignore are calls whose errors may be discarded: "pkg.Func" or a method name, fmt printing is ignored by default
+	Ignore []string `yaml:"ignore" env:"REVIEW_PROCESSORS_ERROR_CHECKS_IGNORE"`
+	// CheckDeferredClose enables findings for deferred Close calls without error handling
+	CheckDeferredClose bool `yaml:"check_deferred_close" env:"REVIEW_PROCESSORS_ERROR_CHECKS_DEFERRED_CLOSE"`
+}
+
+// DocCommentsConfig represents detection of added exported Go symbols without doc comments,
+// the godoc form is required only if the package already follows it
+type DocCommentsConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_DOC_COMMENTS_DISABLE"`
+}
+
+// TestCoverageConfig represents detection of exported entities added or modified in a source file
+// that are not referenced in its test files (Go, Python, JavaScript and TypeScript)
+type TestCoverageConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_TEST_COVERAGE_DISABLE"`
+	// Conventions are test file names by language that override the defaults, e.g. python: ["test_{name}.py"];
+	// {name} is the source file name without extension, {ext} is its extension, a leading slash means the repository root
+	Conventions map[string][]string `yaml:"conventions"`
+}
+
+// FunctionSizeConfig represents detection of added or modified Go functions that are too long or take
+// too many parameters, limits of funlen and revive argument-limit in .golangci.yml take precedence
+type FunctionSizeConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_FUNCTION_SIZE_DISABLE"`
+	// MaxLines is the limit of lines in a function body if the linter config has none, 60 by default, negative disables it
+	MaxLines int `yaml:"max_lines" env:"REVIEW_PROCESSORS_FUNCTION_SIZE_MAX_LINES"`
+	// MaxParams is the limit of function parameters if the linter config has none, 5 by default, negative disables it
+	MaxParams int `yaml:"max_params" env:"REVIEW_PROCESSORS_FUNCTION_SIZE_MAX_PARAMS"`
+}
+
+// InstructionsConfig represents custom instructions of the team added to system prompts of the code and
+// architecture review, they are combined from the config, instructions of .codry.yml in the target branch
+// and "codry-focus:" lines of the merge request description
+type InstructionsConfig struct {
+	// Text is the instructions for every review
+	Text string `yaml:"text" env:"REVIEW_INSTRUCTIONS_TEXT"`
+	// MaxLength is the limit of combined instructions in characters, the rest is cut, 2000 by default
+	MaxLength int `yaml:"max_length" env:"REVIEW_INSTRUCTIONS_MAX_LENGTH"`
+	// DisableFocusMarker ignores "codry-focus:" lines of merge request descriptions
+	DisableFocusMarker bool `yaml:"disable_focus_marker" env:"REVIEW_INSTRUCTIONS_DISABLE_FOCUS_MARKER"`
+}
+
+// IntentConfig represents the intent of the merge request stated by its author, the model checks the code against it
+type IntentConfig struct {
+	// Disable keeps the title and the description of merge requests out of the prompts, e.g. for privacy
+	Disable bool `yaml:"disable" env:"REVIEW_INTENT_DISABLE"`
+	// MaxLength is the limit of the title with the description in characters, the rest is cut, 2000 by default
+	MaxLength int `yaml:"max_length" env:"REVIEW_INTENT_MAX_LENGTH"`
+}
+
+// RulesConfig represents markdown documents with project coding standards added to the code review prompt before
+// custom instructions; sections can be limited to file types by tags in headings like "## Errors [go, ts]"
+// and only sections for the reviewed file are sent, sections without tags are sent for all files
+type RulesConfig struct {
+	// Paths are rules documents read at startup, they go before docs/codry-rules.md of the repository
+	Paths []string `yaml:"paths" env:"REVIEW_RULES_PATHS"`
+	// DisableRepoRules ignores docs/codry-rules.md of the target branch
+	DisableRepoRules bool `yaml:"disable_repo_rules" env:"REVIEW_RULES_DISABLE_REPO_RULES"`
+	// MaxLength is the limit of rules for one file in characters, the rest is cut, 8000 by default
+	MaxLength int `yaml:"max_length" env:"REVIEW_RULES_MAX_LENGTH"`
+}
+
+// ConfigChangesConfig represents key-level diffs of changed JSON, YAML and TOML files: added, removed and changed keys
+// with old and new values are added to the review prompt of the file and listed in the overview, keys with risky
+// new values are flagged; files that can't be parsed are reviewed by their line diff only
+type ConfigChangesConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_CONFIG_CHANGES_DISABLE"`
+	// MaxKeys is the limit of changed keys of a file in the prompt and in the search of code reading them,
+	// flagged keys go first, 100 by default
+	MaxKeys int `yaml:"max_keys" env:"REVIEW_CONFIG_CHANGES_MAX_KEYS"`
+	// DisableUsages skips the search of code files reading changed and removed keys
+	DisableUsages bool `yaml:"disable_usages" env:"REVIEW_CONFIG_CHANGES_DISABLE_USAGES"`
+	// MinDependents is the number of code files reading a changed or removed key from which the change gets
+	// a finding of medium priority, high if the new value is flagged; 3 by default, negative disables findings
+	MinDependents int `yaml:"min_dependents" env:"REVIEW_CONFIG_CHANGES_MIN_DEPENDENTS"`
+	// RiskRules flag risky new values of keys, they replace the default rules for disabled TLS, skipped certificate
+	// verification, wildcard CORS, debug mode and very high limits; an empty list disables flagging
+	RiskRules []analyze.ConfigRiskRule `yaml:"risk_rules"`
+}
+
+// ArtifactsConfig represents the check of accidentally committed artifacts: added binary files and added textual
+// files larger than the limit are listed in one informational finding recommending removal or Git LFS
+type ArtifactsConfig struct {
+	Enable bool `yaml:"enable" env:"REVIEW_ARTIFACTS_ENABLE"`
+	// MaxTextSize is the size in bytes of an added textual file above which it is reported, 1 MB by default
+	MaxTextSize int `yaml:"max_text_size" env:"REVIEW_ARTIFACTS_MAX_TEXT_SIZE"`
+	// AllowedPaths are patterns of paths where binary and large files are expected, like excluded_paths
+	AllowedPaths []string `yaml:"allowed_paths" env:"REVIEW_ARTIFACTS_ALLOWED_PATHS"`
+}
+
+// TriageConfig represents the triage of large merge requests: files are ranked by the risk of their changes found
+// without the model, like in the architecture review input, and only the riskiest files fitting the budget are reviewed
+// one by one; the rest are listed in the comment about the partial review and are still seen by other stages
+type TriageConfig struct {
+	// MinChangedLines is the number of added and removed lines of files for the code review from which
+	// the budget is applied, 0 disables it
+	MinChangedLines int `yaml:"min_changed_lines" env:"REVIEW_TRIAGE_MIN_CHANGED_LINES"`
+	// MaxReviewTokens is the budget of estimated tokens of diffs reviewed one by one, 100000 by default;
+	// the riskiest file is reviewed even if it doesn't fit
+	MaxReviewTokens int `yaml:"max_review_tokens" env:"REVIEW_TRIAGE_MAX_REVIEW_TOKENS"`
+	// Weights of risk signals rank files for the budget and for max_files_per_review, all zeros mean defaults
+	Weights analyze.ChangeRiskWeights `yaml:"weights"`
+}
+
+// SearchConfig represents codebase-wide searches for callers of changed code, project files and readers of config keys,
+// files of excluded directories are never listed or fetched
+type SearchConfig struct {
+	// ExcludeDirs are patterns of directories that are not searched: a name or a glob like vendor or *.egg-info
+	// matches any directory of the path, a path like web/static matches from the repository root;
+	// nil means analyze.DefaultSearchExcludeDirs, search.exclude_dirs of .codry.yml are added to them
+	ExcludeDirs []string `yaml:"exclude_dirs" env:"REVIEW_SEARCH_EXCLUDE_DIRS"`
+}
+
+// CallersConfig represents snippets of unchanged Go functions calling functions and methods changed in the file,
+// they are searched in the changed package and other directories with changed Go files and added to the review
+// prompt of the file; callers of exported functions and callers with more calls go first
+type CallersConfig struct {
+	// MaxSnippets is the limit of caller snippets of a file, 3 by default, negative disables them
+	MaxSnippets int `yaml:"max_snippets" env:"REVIEW_CALLERS_MAX_SNIPPETS"`
+	// MaxTokens caps the total size of caller snippets of a file in tokens, 1500 by default, negative removes the cap
+	MaxTokens int `yaml:"max_tokens" env:"REVIEW_CALLERS_MAX_TOKENS"`
+}
+
+// TimeoutsConfig represents limits of review durations, a stage that times out is skipped and the review
+// goes on with results of completed stages; stages are also limited by the timeout of the whole review
+type TimeoutsConfig struct {
+	Review             time.Duration `yaml:"review" env:"REVIEW_TIMEOUTS_REVIEW"`
+	Description        time.Duration `yaml:"description" env:"REVIEW_TIMEOUTS_DESCRIPTION"`
+	ChangesOverview    time.Duration `yaml:"changes_overview" env:"REVIEW_TIMEOUTS_CHANGES_OVERVIEW"`
+	ArchitectureReview time.Duration `yaml:"architecture_review" env:"REVIEW_TIMEOUTS_ARCHITECTURE_REVIEW"`
+	CodeReview         time.Duration `yaml:"code_review" env:"REVIEW_TIMEOUTS_CODE_REVIEW"`
+	Verdict            time.Duration `yaml:"verdict" env:"REVIEW_TIMEOUTS_VERDICT"`
+}
+
+// stage returns the timeout of the review stage, zero for unknown stages
+func (c TimeoutsConfig) stage(name string) time.Duration {
+	switch name {
+	case stageDescription:
+		return c.Description
+	case stageChangesOverview:
+		return c.ChangesOverview
+	case stageArchitectureReview:
+		return c.ArchitectureReview
+	case stageCodeReview:
+		return c.CodeReview
+	case stageVerdict:
+		return c.Verdict
+	}
+	return 0
+}
+
+// CriticalityConfig represents weighting of findings by criticality of their files: priorities of findings
+// in critical files are raised and in peripheral ones are lowered, critical findings are never changed;
+// files owned by a specific rule of CODEOWNERS of the target branch are critical, without patterns
+// and CODEOWNERS all files are neutral
+type CriticalityConfig struct {
+	// Disable turns off weighting, owners are still mentioned if MentionOwners is set
+	Disable bool `yaml:"disable" env:"REVIEW_CRITICALITY_DISABLE"`
+	// High are path patterns (glob or substring) of core files
+	High []string `yaml:"high" env:"REVIEW_CRITICALITY_HIGH"`
+	// Low are path patterns of peripheral files like examples and scripts, they take precedence over CODEOWNERS
+	Low []string `yaml:"low" env:"REVIEW_CRITICALITY_LOW"`
+	// DisableCodeOwners doesn't use CODEOWNERS of the repository
+	DisableCodeOwners bool `yaml:"disable_codeowners" env:"REVIEW_CRITICALITY_DISABLE_CODEOWNERS"`
+	// MentionOwners mentions CODEOWNERS of files with critical and high findings in the findings comment
+	// of summary and single comment modes, so they are notified
+	MentionOwners bool `yaml:"mention_owners" env:"REVIEW_CRITICALITY_MENTION_OWNERS"`
+}
+
+// RawFindingsConfig represents the cache of findings of the model stored per project and head commit before
+// processors, weighting and filtering, so they can be re-run cheaply and deterministically with other settings;
+// an entry is stale if the model, the prompt version, the guidance or the diff of the file changed
+type RawFindingsConfig struct {
+	// Dir is the directory of the cache, findings are not cached if it is empty
+	Dir string `yaml:"dir" env:"REVIEW_RAW_FINDINGS_DIR"`
+	// Replay takes findings from the cache instead of the model, files without a valid entry are not reviewed
+	// and stages generating the description, the changes overview and the architecture review are skipped
+	Replay bool `yaml:"replay" env:"REVIEW_RAW_FINDINGS_REPLAY"`
+}
+
+// ConstantChangesConfig represents detection of exported Go constants whose values changed,
+// including values shifted by a change of an iota sequence
+type ConstantChangesConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_CONSTANT_CHANGES_DISABLE"`
+}
+
+// DockerfileChecksConfig represents checks of Dockerfiles: base images without a pinned tag, the final stage
+// running as root, secrets in ENV and ARG and apt installs without --no-install-recommends
+type DockerfileChecksConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_DOCKERFILE_DISABLE"`
+	// Skip are names of disabled checks: latest_tag, root_user, env_secrets, no_install_recommends
+	Skip []string `yaml:"skip" env:"REVIEW_PROCESSORS_DOCKERFILE_SKIP"`
+}
+
+// ShellScriptChecksConfig represents checks of .sh and .bash files: unquoted variable expansions
+// and new scripts without set -euo pipefail
+type ShellScriptChecksConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_SHELL_SCRIPTS_DISABLE"`
+	// Skip are names of disabled checks: unquoted_variables, strict_mode
+	Skip []string `yaml:"skip" env:"REVIEW_PROCESSORS_SHELL_SCRIPTS_SKIP"`
+}
+
+// DebugArtifactsConfig represents detection of debugging leftovers in added lines: TODO and FIXME comments,
+// panics and os.Exit in Go library code, debug prints of Go, JavaScript, TypeScript and Python, skipped and focused tests
+type DebugArtifactsConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_DEBUG_ARTIFACTS_DISABLE"`
+	// Skip are names of disabled checks: todo, panic, exit, debug_print, skipped_tests
+	Skip []string `yaml:"skip" env:"REVIEW_PROCESSORS_DEBUG_ARTIFACTS_SKIP"`
+}
+
+// InterfaceDriftConfig represents detection of Go methods removed, changed in signature or moved to a pointer
+// receiver while an assertion like var _ Iface = (*T)(nil) of the package requires them
+type InterfaceDriftConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_INTERFACE_DRIFT_DISABLE"`
+}
+
+// PanicChecksConfig represents detection of type assertions without the comma ok form, writes to maps
+// that are never made and constant indexes of strings.Split results and os.Args without a length check
+// in added Go code; a line is skipped if it has a //nolint or //nolint:codry comment
+type PanicChecksConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_PANIC_CHECKS_DISABLE"`
+}
+
+// ResourceLeaksConfig represents detection of files, rows, response bodies and locks acquired in added Go code
+// without a deferred or direct release in the same function; a line is skipped if it has a //nolint:codry comment
+type ResourceLeaksConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_RESOURCE_LEAKS_DISABLE"`
+	// Pairs are resources checked in addition to the defaults in "acquire -> release" format,
+	// e.g. "pgxpool.New -> Close" for a package function or ".Acquire -> Release" for a method of any value
+	Pairs []string `yaml:"pairs" env:"REVIEW_PROCESSORS_RESOURCE_LEAKS_PAIRS"`
+}
+
+// LoopCallsConfig represents detection of network and database calls and JS awaits inside loop bodies
+// of changed Go, JS/TS, Java, C/C++ and Python code (N+1 queries), test files are skipped
+type LoopCallsConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_LOOP_CALLS_DISABLE"`
+}
+
+// SerializedFieldsConfig represents detection of removed fields, changed serialized names and changed types
+// of fields of Go structs with json, yaml or protobuf tags; structs without such tags are not checked
+type SerializedFieldsConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_SERIALIZED_FIELDS_DISABLE"`
+}
+
+// NewDependenciesConfig represents an informational finding listing modules added to go.mod as direct dependencies,
+// modules with pseudo-versions and with the same purpose as existing dependencies are marked; registries are not requested
+type NewDependenciesConfig struct {
+	Enable bool `yaml:"enable" env:"REVIEW_PROCESSORS_NEW_DEPENDENCIES_ENABLE"`
+}
+
+// NamingChecksConfig represents detection of identifiers declared in added Go code that spell initialisms
+// in mixed case like userId or HttpClient, identifiers that also exist in removed lines are skipped
+type NamingChecksConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_NAMING_DISABLE"`
+	// Initialisms replace the default list of common Go initialisms like ID, URL and HTTP
+	Initialisms []string `yaml:"initialisms" env:"REVIEW_PROCESSORS_NAMING_INITIALISMS"`
+}
+
+// CommandInjectionConfig represents critical findings for shell commands built from non-literal values in added lines:
+// Go exec.Command with sh -c, Python subprocess with shell=True and os.system, JS child_process.exec
+type CommandInjectionConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_COMMAND_INJECTION_DISABLE"`
+}
+
+// SecretsChecksConfig represents critical findings for cloud keys, tokens, private keys and high-entropy values
+// assigned to secret-like names in added lines; values are redacted in all findings of the file
+type SecretsChecksConfig struct {
+	Disable bool `yaml:"disable" env:"REVIEW_PROCESSORS_SECRETS_DISABLE"`
+	// Allowlist are path patterns (glob or substring) of files with dummy secrets, testdata/ and fixtures/ by default
+	Allowlist []string `yaml:"allowlist" env:"REVIEW_PROCESSORS_SECRETS_ALLOWLIST"`
+}
+
+// Validate checks the config and returns all found problems at once, defaults are not required to be set
+func (c Config) Validate() error {
+	errs := errm.NewList()
+	if c.MaxFilesPerMR < 0 || c.MaxFilesPerReview < 0 || c.FileFilter.MaxFileSize < 0 || c.MaxArchitectureInputSize < 0 {
+		errs.New("max_files_per_mr, max_files_per_review, max_file_size and max_architecture_input_size must not be negative")
+	}
+	for _, pattern := range c.FileFilter.ExcludedPaths {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs.Errorf("invalid excluded_paths pattern %q", pattern)
+		}
+	}
+	if c.Language != "" {
+		if _, ok := prompts.DefaultLanguages[c.Language]; !ok {
+			errs.Errorf("unsupported language %q", c.Language)
+		}
+	}
+	if c.CommentMode != "" && !slices.Contains(supportedCommentModes, c.CommentMode) {
+		errs.Errorf("invalid comment_mode %q, expected one of %v", c.CommentMode, supportedCommentModes)
+	}
+	if c.ReviewScope != "" && !slices.Contains(supportedReviewScopes, c.ReviewScope) {
+		errs.Errorf("invalid review_scope %q, expected one of %v", c.ReviewScope, supportedReviewScopes)
+	}
+	if c.DiffSource != "" && !slices.Contains(supportedDiffSources, c.DiffSource) {
+		errs.Errorf("invalid diff_source %q, expected one of %v", c.DiffSource, supportedDiffSources)
+	}
+	if p := c.CheckRun.FailurePriority; p != "" && !p.IsValid() {
+		errs.Errorf("invalid check_run.failure_priority %q, expected critical, high, medium or backlog", p)
+	}
+	if l := c.Strictness.Level; l != "" && !slices.Contains(supportedStrictnessLevels, l) {
+		errs.Errorf("invalid strictness.level %q, expected one of %v", l, supportedStrictnessLevels)
+	}
+	if p := c.Strictness.MinPriority; p != "" && !p.IsValid() {
+		errs.Errorf("invalid strictness.min_priority %q, expected critical, high, medium or backlog", p)
+	}
+	if conf := c.Strictness.MinConfidence; conf != "" && !conf.IsValid() {
+		errs.Errorf("invalid strictness.min_confidence %q, expected very_high, high, medium or low", conf)
+	}
+	if p := c.Labels.Outcome.Priority; p != "" && !p.IsValid() {
+		errs.Errorf("invalid labels.outcome.priority %q, expected critical, high, medium or backlog", p)
+	}
+	if outcome := c.Labels.Outcome; outcome.ChangesRequested != "" && outcome.ChangesRequested == outcome.Approved {
+		errs.New("labels.outcome.changes_requested and labels.outcome.approved must differ")
+	}
+	if p := c.Severity.RequestChangesPriority; p != "" && !p.IsValid() {
+		errs.Errorf("invalid severity.request_changes_priority %q, expected critical, high, medium or backlog", p)
+	}
+	if c.ExternalReport.Threshold < 0 || c.FindingClusters.Threshold < 0 {
+		errs.New("external_report.threshold and finding_clusters.threshold must not be negative")
+	}
+	if c.Instructions.MaxLength < 0 {
+		errs.New("instructions.max_length must not be negative")
+	}
+	if c.Intent.MaxLength < 0 {
+		errs.New("intent.max_length must not be negative")
+	}
+	if c.Rules.MaxLength < 0 {
+		errs.New("rules.max_length must not be negative")
+	}
+	if c.ConfigChanges.MaxKeys < 0 {
+		errs.New("config_changes.max_keys must not be negative")
+	}
+	if _, err := analyze.NewConfigAnalyzer(c.ConfigChanges.RiskRules); err != nil {
+		errs.Wrap(err, "invalid config_changes.risk_rules")
+	}
+	if c.Artifacts.MaxTextSize < 0 {
+		errs.New("artifacts.max_text_size must not be negative")
+	}
+	for _, pattern := range c.Artifacts.AllowedPaths {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs.Errorf("invalid artifacts.allowed_paths pattern %q", pattern)
+		}
+	}
+	for _, pattern := range c.Search.ExcludeDirs {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs.Errorf("invalid search.exclude_dirs pattern %q", pattern)
+		}
+	}
+	if c.Triage.MinChangedLines < 0 || c.Triage.MaxReviewTokens < 0 {
+		errs.New("triage.min_changed_lines and triage.max_review_tokens must not be negative")
+	}
+	weights := c.Triage.Weights
+	if min(weights.Breaking, weights.Exported, weights.CrossPackage, weights.Security, weights.SensitivePath) < 0 {
+		errs.New("triage.weights must not be negative")
+	}
+	for _, path := range c.Rules.Paths {
+		if _, err := os.Stat(path); err != nil {
+			errs.Wrap(err, "invalid rules.paths document")
+		}
+	}
+	t := c.Timeouts
+	if t.Review < 0 || t.Description < 0 || t.ChangesOverview < 0 || t.ArchitectureReview < 0 || t.CodeReview < 0 || t.Verdict < 0 {
+		errs.New("timeouts must not be negative")
+	}
+	if c.RawFindings.Replay && c.RawFindings.Dir == "" {
+		errs.New("raw_findings.dir is required for replay")
+	}
+	for _, check := range c.Processors.Dockerfile.Skip {
+		if !slices.Contains(processor.DockerfileCheckNames, check) {
+			errs.Errorf("unknown processors.dockerfile.skip check %q, expected one of %v", check, processor.DockerfileCheckNames)
+		}
+	}
+	for _, check := range c.Processors.ShellScripts.Skip {
+		if !slices.Contains(processor.ShellCheckNames, check) {
+			errs.Errorf("unknown processors.shell_scripts.skip check %q, expected one of %v", check, processor.ShellCheckNames)
+		}
+	}
+	for _, check := range c.Processors.DebugArtifacts.Skip {
+		if !slices.Contains(processor.DebugCheckNames, check) {
+			errs.Errorf("unknown processors.debug_artifacts.skip check %q, expected one of %v", check, processor.DebugCheckNames)
+		}
+	}
+	for _, entry := range c.Processors.ResourceLeaks.Pairs {
+		if _, err := analyze.ParseResourcePair(entry); err != nil {
+			errs.Wrap(err, "invalid processors.resource_leaks.pairs entry")
+		}
+	}
+	for _, pattern := range slices.Concat(c.Criticality.High, c.Criticality.Low) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs.Errorf("invalid criticality pattern %q", pattern)
+		}
+	}
+	for _, pattern := range c.OwnershipScope {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs.Errorf("invalid ownership_scope pattern %q", pattern)
+		}
+	}
+	for _, pattern := range c.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs.Errorf("invalid redaction.patterns regex %q", pattern)
+		}
+	}
+	if strings.ContainsAny(c.Suppression.Directive, " \t\n") {
+		errs.Errorf("suppression.directive must be one word, got %q", c.Suppression.Directive)
+	}
+	if slices.Contains(c.Suppression.CommentMarkers, "") {
+		errs.New("suppression.comment_markers must not be empty")
+	}
+	if c.CommentTemplates.Dir != "" {
+		if _, err := os.Stat(c.CommentTemplates.Dir); err != nil {
+			errs.Wrap(err, "invalid comment_templates.dir")
+		}
+	}
+	return errs.Err()
 }
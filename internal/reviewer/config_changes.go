@@ -0,0 +1,266 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+const (
+	// maxOverviewConfigKeys is the number of changed keys of a config file listed in the overview
+	maxOverviewConfigKeys = 10
+	// hiddenConfigValue replaces values of credential keys sent to the model when redaction is enabled
+	hiddenConfigValue = "<REDACTED>"
+)
+
+// loadConfigChanges builds key-level diffs of changed JSON, YAML and TOML files, files that can't be fetched
+// or parsed are left out and reviewed by their line diff only
+func (s *Reviewer) loadConfigChanges(ctx context.Context, request model.ReviewRequest, files []*model.FileDiff, log logze.Logger) []model.ConfigChangeInfo {
+	if s.cfg.ConfigChanges.Disable {
+		return nil
+	}
+
+	var infos []model.ConfigChangeInfo
+	for _, file := range files {
+		if file.IsDeleted || file.IsBinary || !analyze.IsStructuredConfig(file.NewPath) {
+			continue
+		}
+		before, after, err := s.fetchFileVersions(ctx, request, file)
+		if err != nil {
+			log.DebugIf(s.cfg.Verbose, "failed to get versions of config file", "file", file.NewPath, "error", err)
+			continue
+		}
+		changes, err := s.configAnalyzer.Diff(file.NewPath, before, after)
+		if err != nil {
+			log.DebugIf(s.cfg.Verbose, "failed to diff config keys, using line diff", "file", file.NewPath, "error", err)
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		info := model.ConfigChangeInfo{FilePath: file.NewPath, Changes: changes}
+		if !s.cfg.ConfigChanges.DisableUsages {
+			if err := s.findConfigUsages(ctx, request, &info); err != nil {
+				log.DebugIf(s.cfg.Verbose, "failed to find code reading config keys", "file", file.NewPath, "error", err)
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	slices.SortFunc(infos, func(a, b model.ConfigChangeInfo) int {
+		return strings.Compare(a.FilePath, b.FilePath)
+	})
+	return infos
+}
+
+// findConfigUsages fills code files reading changed and removed keys of the config file, flagged keys are searched first
+func (s *Reviewer) findConfigUsages(ctx context.Context, request model.ReviewRequest, info *model.ConfigChangeInfo) error {
+	var keys []string
+	for _, change := range riskyConfigChangesFirst(info.Changes) {
+		if change.Kind != model.ConfigKeyAdded && len(keys) < s.cfg.ConfigChanges.MaxKeys {
+			keys = append(keys, change.Key)
+		}
+	}
+	usages, err := s.configUsages.FindUsages(ctx, request, info.FilePath, keys)
+	if err != nil {
+		return err
+	}
+
+	for i, change := range info.Changes {
+		info.Changes[i].AffectedFiles = usages[change.Key]
+		for _, filePath := range usages[change.Key] {
+			if !slices.Contains(info.AffectedFiles, filePath) {
+				info.AffectedFiles = append(info.AffectedFiles, filePath)
+			}
+		}
+	}
+	slices.Sort(info.AffectedFiles)
+	return nil
+}
+
+// configChangesOf returns key-level changes of the config file, commits of the merge request get nothing
+// because keys are compared between the target branch and the head
+func (b *reviewBundle) configChangesOf(request model.ReviewRequest, filePath string) (model.ConfigChangeInfo, bool) {
+	if request.MergeRequest.SHA != b.request.MergeRequest.SHA {
+		return model.ConfigChangeInfo{}, false
+	}
+	idx := slices.IndexFunc(b.configChanges, func(info model.ConfigChangeInfo) bool {
+		return info.FilePath == filePath
+	})
+	if idx < 0 {
+		return model.ConfigChangeInfo{}, false
+	}
+	return b.configChanges[idx], true
+}
+
+// promptConfigChanges returns changed keys of the config file for its review prompt, keys with risks go first;
+// values are redacted like the diff, values of credential keys are hidden
+func (s *Reviewer) promptConfigChanges(bundle *reviewBundle, request model.ReviewRequest, filePath string) []model.ConfigKeyChange {
+	info, ok := bundle.configChangesOf(request, filePath)
+	if !ok {
+		return nil
+	}
+
+	changes := riskyConfigChangesFirst(info.Changes)
+	if len(changes) > s.cfg.ConfigChanges.MaxKeys {
+		bundle.log.DebugIf(s.cfg.Verbose, "too many changed config keys, the rest is in the diff", "file", filePath, "keys", len(changes))
+		changes = changes[:s.cfg.ConfigChanges.MaxKeys]
+	}
+
+	for i := range changes {
+		changes[i].OldValue = s.promptConfigValue(bundle, filePath, changes[i].Key, changes[i].OldValue)
+		changes[i].NewValue = s.promptConfigValue(bundle, filePath, changes[i].Key, changes[i].NewValue)
+	}
+	return changes
+}
+
+// promptConfigValue returns the value of the config key cut and redacted for the prompt
+func (s *Reviewer) promptConfigValue(bundle *reviewBundle, filePath, key, value string) string {
+	if value == "" {
+		return ""
+	}
+	if bundle.redactor != nil && analyze.IsSecretConfigKey(key) {
+		return hiddenConfigValue
+	}
+	return bundle.redact(filePath, analyze.TruncateConfigValue(value))
+}
+
+// configDependentsFindings returns findings for changed and removed keys of the config file read by many code files,
+// findings of removed keys and of keys without a known line are anchored to the first added line of the diff
+func (s *Reviewer) configDependentsFindings(bundle *reviewBundle, request model.ReviewRequest, fileDiff *model.FileDiff) []*model.ReviewAIComment {
+	info, ok := bundle.configChangesOf(request, fileDiff.NewPath)
+	if !ok || s.cfg.ConfigChanges.MinDependents < 0 {
+		return nil
+	}
+
+	var (
+		findings  []*model.ReviewAIComment
+		firstLine int
+	)
+	if added := analyze.FileAddedLines(fileDiff); len(added) > 0 {
+		firstLine = added[0].Number
+	}
+	for _, change := range info.Changes {
+		if change.Kind == model.ConfigKeyAdded || len(change.AffectedFiles) < s.cfg.ConfigChanges.MinDependents {
+			continue
+		}
+		line := change.Line
+		if change.Kind == model.ConfigKeyRemoved || line == 0 {
+			line = firstLine
+		}
+		if line == 0 {
+			continue
+		}
+		findings = append(findings, configDependentsFinding(fileDiff.NewPath, line, change))
+	}
+	return findings
+}
+
+// configDependentsFinding describes the impact of the changed or removed key on code reading it
+func configDependentsFinding(filePath string, line int, change model.ConfigKeyChange) *model.ReviewAIComment {
+	finding := &model.ReviewAIComment{
+		FilePath:    filePath,
+		Line:        line,
+		IssueType:   model.IssueTypeBug,
+		Confidence:  model.ConfidenceMedium,
+		Priority:    model.ReviewPriorityMedium,
+		Title:       fmt.Sprintf("Config key `%s` read by %d files is changed", change.Key, len(change.AffectedFiles)),
+		Description: fmt.Sprintf("Code reading the key: %s. ", overviewPaths(change.AffectedFiles)),
+		Suggestion:  "Check that every reader works with the new value and that the config is deployed together with the code expecting it.",
+	}
+	switch {
+	case change.Kind == model.ConfigKeyRemoved:
+		finding.Title = fmt.Sprintf("Removed config key `%s` is still read by %d files", change.Key, len(change.AffectedFiles))
+		finding.Description += "After the key is removed they get an empty or default value, or fail to start if the key is required."
+		finding.Suggestion = "Remove the reads of the key in the same merge request, or keep the key until the code stops using it."
+	case analyze.IsSecretConfigKey(change.Key):
+		finding.Description += "All of them get the new value with the next deployment of the config."
+	default:
+		finding.Description += fmt.Sprintf("All of them get the new value %s instead of %s with the next deployment of the config.",
+			analyze.TruncateConfigValue(change.NewValue), analyze.TruncateConfigValue(change.OldValue))
+	}
+	if change.Risk != "" {
+		finding.Priority = model.ReviewPriorityHigh
+		finding.Description += " The new value is flagged: " + change.Risk + "."
+	}
+	return finding
+}
+
+// riskyConfigChangesFirst returns a copy of the changes with flagged keys first, the order is kept otherwise
+func riskyConfigChangesFirst(changes []model.ConfigKeyChange) []model.ConfigKeyChange {
+	var risky, regular []model.ConfigKeyChange
+	for _, change := range changes {
+		if change.Risk != "" {
+			risky = append(risky, change)
+		} else {
+			regular = append(regular, change)
+		}
+	}
+	return append(risky, regular...)
+}
+
+// configChangesSection renders changed keys of config files for the overview, values of credential keys are not shown
+func configChangesSection(header string, infos []model.ConfigChangeInfo) string {
+	if len(infos) == 0 {
+		return ""
+	}
+
+	var section strings.Builder
+	section.WriteString("\n**")
+	section.WriteString(header)
+	section.WriteString("**:\n")
+	for _, info := range infos {
+		keys := make([]string, 0, min(len(info.Changes), maxOverviewConfigKeys)+1)
+		for _, change := range info.Changes[:min(len(info.Changes), maxOverviewConfigKeys)] {
+			keys = append(keys, overviewConfigKey(change))
+		}
+		if more := len(info.Changes) - len(keys); more > 0 {
+			keys = append(keys, fmt.Sprintf("%d more", more))
+		}
+		section.WriteString(fmt.Sprintf("- `%s`: %s\n", info.FilePath, strings.Join(keys, ", ")))
+		if len(info.AffectedFiles) > 0 {
+			section.WriteString(fmt.Sprintf("  - read by %s\n", overviewPaths(info.AffectedFiles)))
+		}
+
+		for _, change := range info.Changes {
+			if change.Risk != "" {
+				section.WriteString(fmt.Sprintf("  - ⚠️ `%s`: %s\n", change.Key, change.Risk))
+			}
+		}
+	}
+	return section.String()
+}
+
+// overviewConfigKey renders the changed key with its values like `timeout` 30s → 60s
+func overviewConfigKey(change model.ConfigKeyChange) string {
+	key := "`" + change.Key + "`"
+	if analyze.IsSecretConfigKey(change.Key) {
+		return key + " " + string(change.Kind)
+	}
+	switch change.Kind {
+	case model.ConfigKeyAdded:
+		return fmt.Sprintf("%s added (%s)", key, analyze.TruncateConfigValue(change.NewValue))
+	case model.ConfigKeyRemoved:
+		return key + " removed"
+	default:
+		return fmt.Sprintf("%s %s → %s", key, analyze.TruncateConfigValue(change.OldValue), analyze.TruncateConfigValue(change.NewValue))
+	}
+}
+
+// overviewPaths renders the first paths in backticks and the number of the rest
+func overviewPaths(paths []string) string {
+	rendered := make([]string, 0, min(len(paths), maxOverviewConfigKeys)+1)
+	for _, filePath := range paths[:min(len(paths), maxOverviewConfigKeys)] {
+		rendered = append(rendered, "`"+filePath+"`")
+	}
+	if more := len(paths) - len(rendered); more > 0 {
+		rendered = append(rendered, fmt.Sprintf("%d more", more))
+	}
+	return strings.Join(rendered, ", ")
+}
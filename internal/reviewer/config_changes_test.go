@@ -0,0 +1,95 @@
+package reviewer
+
+import (
+	"context"
+	"path"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// listingVersionsProvider lists files of the versions provider at the ref
+type listingVersionsProvider struct {
+	*versionsProvider
+}
+
+func (p listingVersionsProvider) ListFiles(_ context.Context, _, dir, ref string) ([]string, error) {
+	var paths []string
+	for filePath := range p.contents[ref] {
+		if fileDir := path.Dir(filePath); fileDir == dir || (fileDir == "." && dir == "") {
+			paths = append(paths, filePath)
+		}
+	}
+	return paths, nil
+}
+
+func TestLoadConfigChangesAffectedFiles(t *testing.T) {
+	const (
+		configBefore = "database:\n  max_conns: 20\n  name: app\nserver:\n  port: 8080\n"
+		configAfter  = "database:\n  max_conns: 50\n  name: app\nserver:\n  port: 9090\n"
+		configDiff   = "@@ -1,5 +1,5 @@\n database:\n-  max_conns: 20\n+  max_conns: 50\n   name: app\n server:\n-  port: 8080\n+  port: 9090\n"
+		reader       = "package config\n\ntype Config struct {\n\tDatabase struct {\n\t\tMaxConns int `yaml:\"max_conns\"`\n\t} `yaml:\"database\"`\n}\n"
+	)
+
+	tests := []struct {
+		name          string
+		minDependents int
+		wantFindings  []string
+	}{
+		{"below the minimum of dependents", 2, nil},
+		{"at the minimum of dependents", 1, []string{"Config key `database.max_conns` read by 1 files is changed"}},
+		{"findings disabled", -1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := listingVersionsProvider{&versionsProvider{contents: map[string]map[string]string{
+				"main": {"config.yaml": configBefore, "internal/config/config.go": reader},
+				"head": {"config.yaml": configAfter, "internal/config/config.go": reader},
+			}}}
+			cfg := testConfig()
+			cfg.ConfigChanges.MinDependents = tt.minDependents
+			codeReviewer, err := New(cfg, provider, agent.NewWithAPI(agent.Config{}, &stubAPI{}))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			fileDiff := &model.FileDiff{OldPath: "config.yaml", NewPath: "config.yaml", Diff: configDiff}
+			request := model.ReviewRequest{
+				ProjectID:    "app",
+				MergeRequest: &model.MergeRequest{SHA: "head", TargetBranch: "main"},
+				Changes:      []*model.FileDiff{fileDiff},
+			}
+
+			infos := codeReviewer.loadConfigChanges(context.Background(), request, request.Changes, codeReviewer.log)
+			if len(infos) != 1 {
+				t.Fatalf("loadConfigChanges() = %d infos, want 1", len(infos))
+			}
+			// The changed YAML key is mapped to the Go file reading it by its struct tags
+			if want := []string{"internal/config/config.go"}; !slices.Equal(infos[0].AffectedFiles, want) {
+				t.Errorf("AffectedFiles = %v, want %v", infos[0].AffectedFiles, want)
+			}
+			for _, change := range infos[0].Changes {
+				var want []string
+				if change.Key == "database.max_conns" {
+					want = []string{"internal/config/config.go"}
+				}
+				if !slices.Equal(change.AffectedFiles, want) {
+					t.Errorf("AffectedFiles of %s = %v, want %v", change.Key, change.AffectedFiles, want)
+				}
+			}
+
+			bundle := &reviewBundle{request: request, configChanges: infos, log: codeReviewer.log}
+			findings := codeReviewer.configDependentsFindings(bundle, request, fileDiff)
+			if got := titles(findings); !slices.Equal(got, tt.wantFindings) {
+				t.Errorf("configDependentsFindings() = %v, want %v", got, tt.wantFindings)
+			}
+			for _, finding := range findings {
+				if finding.Line != 2 {
+					t.Errorf("finding line = %d, want 2", finding.Line)
+				}
+			}
+		})
+	}
+}
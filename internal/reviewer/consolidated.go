@@ -0,0 +1,152 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/lang"
+)
+
+// postConsolidatedFindings posts all findings as one general comment in summary or single mode,
+// the comment of the previous review is updated, so it always reflects the latest head
+func (s *Reviewer) postConsolidatedFindings(ctx context.Context, bundle *reviewBundle, findings []*model.ReviewAIComment) {
+	if len(findings) == 0 && bundle.reviewedFiles == 0 {
+		return // all files were reviewed in the previous run of the head, its comment is actual
+	}
+
+	var body string
+	if s.cfg.CommentMode == CommentModeSummary {
+		body = s.buildFindingsTable(findings)
+	} else {
+		body = s.buildFindingsComment(findings)
+	}
+	if s.cfg.Criticality.MentionOwners {
+		if mentions := bundle.criticality.mentions(findings); len(mentions) > 0 {
+			body += "\n\n**Owners, please take a look at critical and high findings:** " + strings.Join(mentions, " ")
+		}
+	}
+	if note := s.filteredFindingsNote(bundle); note != "" {
+		body += "\n\n" + note
+	}
+
+	// Comment without findings only replaces the one of the previous review
+	if err := s.upsertMarkedComment(ctx, bundle.request, startMarkerFindings, endMarkerFindings, body, len(findings) > 0); err != nil {
+		msg := "failed to create findings comment"
+		bundle.log.Err(err, msg)
+		bundle.result.Errors = append(bundle.result.Errors, errm.Wrap(err, msg))
+		return
+	}
+	if len(findings) > 0 {
+		bundle.result.CommentsCreated++
+	}
+	for _, finding := range findings {
+		bundle.result.FindingsByPriority[finding.Priority]++
+	}
+
+	bundle.log.InfoIf(s.cfg.Verbose, "posted findings comment", "mode", s.cfg.CommentMode, "findings", len(findings))
+}
+
+// RenderFindings renders findings as markdown grouped by file like the comment of the single comment mode
+func (s *Reviewer) RenderFindings(findings []*model.ReviewAIComment) string {
+	return s.buildFindingsComment(findings)
+}
+
+// buildFindingsComment renders findings grouped by file into collapsible sections,
+// bodies are rendered with templates of their issue types and lines are referenced as text
+func (s *Reviewer) buildFindingsComment(findings []*model.ReviewAIComment) string {
+	var sb strings.Builder
+	s.writeFindingsHeader(&sb, findings)
+
+	files, byFile := groupFindingsByFile(findings)
+	for _, file := range files {
+		fileFindings := byFile[file]
+		label := "Across files"
+		if file != "" {
+			label = "<code>" + file + "</code>"
+		}
+		sb.WriteString(fmt.Sprintf("<details>\n<summary>%s · %d</summary>\n\n", label, len(fileFindings)))
+		for _, finding := range fileFindings {
+			if finding.Line > 0 {
+				sb.WriteString(fmt.Sprintf("**L%d:**\n\n", finding.Line))
+			}
+			sb.WriteString(demoteHeadings(s.reviewToComment(finding).Body))
+			sb.WriteString("\n\n---\n\n")
+		}
+		sb.WriteString("</details>\n\n")
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// buildFindingsTable renders findings as a table with one row per finding
+func (s *Reviewer) buildFindingsTable(findings []*model.ReviewAIComment) string {
+	var sb strings.Builder
+	s.writeFindingsHeader(&sb, findings)
+	if len(findings) == 0 {
+		return strings.TrimSpace(sb.String())
+	}
+
+	sb.WriteString("| Priority | Location | Issue |\n|---|---|---|\n")
+	files, byFile := groupFindingsByFile(findings)
+	for _, file := range files {
+		for _, finding := range byFile[file] {
+			location := findingsFileLabel(file)
+			if finding.Line > 0 {
+				location += fmt.Sprintf(" L%d", finding.Line)
+			}
+			if finding.CommitSHA != "" {
+				location += " @ " + lang.TruncateString(finding.CommitSHA, 8)
+			}
+			data := newCommentData(s.cfg.Language, s.severityBadge(finding.Priority), finding)
+			title := strings.TrimSpace(lang.Check(data.Title, data.Header))
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", s.priorityLabel(finding.Priority), location, escapeTableCell(title)))
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// writeFindingsHeader writes the title with the number of findings and their priorities
+func (s *Reviewer) writeFindingsHeader(sb *strings.Builder, findings []*model.ReviewAIComment) {
+	if len(findings) == 0 {
+		sb.WriteString("## ✅ Code review\n\nNo issues found in the latest changes.\n\n")
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("## 🔍 Code review\n\nReview found %d issues.\n\n", len(findings)))
+	counts := make(map[model.ReviewPriority]int)
+	for _, finding := range findings {
+		counts[finding.Priority]++
+	}
+	sb.WriteString(s.buildPriorityTable(counts))
+	sb.WriteString("\n")
+}
+
+// groupFindingsByFile groups findings by file path, files are in order of their first finding
+func groupFindingsByFile(findings []*model.ReviewAIComment) ([]string, map[string][]*model.ReviewAIComment) {
+	var files []string
+	byFile := make(map[string][]*model.ReviewAIComment)
+	for _, finding := range findings {
+		if _, ok := byFile[finding.FilePath]; !ok {
+			files = append(files, finding.FilePath)
+		}
+		byFile[finding.FilePath] = append(byFile[finding.FilePath], finding)
+	}
+	return files, byFile
+}
+
+// findingsFileLabel returns the file path in code format or a label of systemic findings without a file
+func findingsFileLabel(file string) string {
+	if file == "" {
+		return "Across files"
+	}
+	return "`" + file + "`"
+}
+
+// escapeTableCell makes the text safe for a markdown table cell
+func escapeTableCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
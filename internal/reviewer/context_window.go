@@ -0,0 +1,98 @@
+package reviewer
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// hunkRange is a range of lines of the original file covered by a diff hunk
+type hunkRange struct {
+	Start int // first line, 1-based; for a pure insertion it is the line after which lines are added
+	Count int // number of lines, 0 for a pure insertion
+}
+
+// lineWindow is an inclusive range of lines of the file
+type lineWindow struct {
+	from, to int
+}
+
+// hunkRanges returns ranges of the original file covered by the hunks
+func hunkRanges(hunks []model.DiffHunk) []hunkRange {
+	ranges := make([]hunkRange, 0, len(hunks))
+	for _, hunk := range hunks {
+		ranges = append(ranges, hunkRange{Start: hunk.OldStart, Count: hunk.OldCount})
+	}
+	return ranges
+}
+
+// extractContextWindow returns changed regions of the original content with contextLines lines around them,
+// every line is prefixed with its absolute line number and skipped lines are marked, so the model can
+// refer to real lines without reading the whole file; negative contextLines returns the whole file
+func extractContextWindow(content string, hunks []hunkRange, contextLines int) string {
+	if content == "" || len(hunks) == 0 {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	var windows []lineWindow
+	if contextLines < 0 {
+		windows = []lineWindow{{from: 1, to: len(lines)}}
+	} else {
+		for _, hunk := range hunks {
+			last := hunk.Start + max(hunk.Count, 1) - 1
+			windows = append(windows, lineWindow{
+				from: max(hunk.Start-contextLines, 1),
+				to:   min(last+contextLines, len(lines)),
+			})
+		}
+		windows = mergeLineWindows(windows)
+	}
+
+	width := len(strconv.Itoa(len(lines)))
+	var sb strings.Builder
+	next := 1
+	for _, window := range windows {
+		if window.from > window.to {
+			continue // hunk is beyond the end of the content
+		}
+		if window.from > next {
+			sb.WriteString(fmt.Sprintf("... lines %d-%d are omitted ...\n", next, window.from-1))
+		}
+		for i := window.from; i <= window.to; i++ {
+			sb.WriteString(fmt.Sprintf("%*d | %s\n", width, i, lines[i-1]))
+		}
+		next = window.to + 1
+	}
+	if sb.Len() > 0 && next <= len(lines) {
+		sb.WriteString(fmt.Sprintf("... lines %d-%d are omitted ...\n", next, len(lines)))
+	}
+
+	return sb.String()
+}
+
+// mergeLineWindows sorts windows and merges overlapping and adjacent ones
+func mergeLineWindows(windows []lineWindow) []lineWindow {
+	slices.SortFunc(windows, func(a, b lineWindow) int { return a.from - b.from })
+
+	merged := make([]lineWindow, 0, len(windows))
+	for _, window := range windows {
+		if n := len(merged); n > 0 && window.from <= merged[n-1].to+1 {
+			merged[n-1].to = max(merged[n-1].to, window.to)
+			continue
+		}
+		merged = append(merged, window)
+	}
+	return merged
+}
+
+// diffContextLines returns the number of context lines around changed regions for the file language
+func (s *Reviewer) diffContextLines(filePath string) int {
+	if lines, ok := s.cfg.DiffContext.Languages[detectProgrammingLanguage(filePath)]; ok {
+		return lines
+	}
+	return s.cfg.DiffContext.Lines
+}
@@ -0,0 +1,112 @@
+package reviewer
+
+import (
+	"context"
+	"slices"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// Criticality is the importance of a file for weighting of its findings
+type Criticality string
+
+const (
+	CriticalityHigh   Criticality = "high"   // core files, priorities of findings are raised
+	CriticalityNormal Criticality = "normal" // priorities of findings are kept
+	CriticalityLow    Criticality = "low"    // peripheral files, priorities of findings are lowered
+)
+
+// Priorities from the lowest, critical findings are not weighted
+var weightedPriorities = []model.ReviewPriority{
+	model.ReviewPriorityBacklog,
+	model.ReviewPriorityMedium,
+	model.ReviewPriorityHigh,
+}
+
+// pathCriticality resolves criticality and owners of files of one review, nil value is neutral
+type pathCriticality struct {
+	high     []string
+	low      []string
+	owners   *analyze.CodeOwners // nil if CODEOWNERS is not used or not found
+	noWeight bool                // only owners are resolved for mentions
+}
+
+// loadPathCriticality returns criticality of files of the request with CODEOWNERS of the target branch,
+// nil is returned if weighting and mentions are disabled
+func (s *Reviewer) loadPathCriticality(ctx context.Context, request model.ReviewRequest, log logze.Logger) *pathCriticality {
+	cfg := s.cfg.Criticality
+	if cfg.Disable && !cfg.MentionOwners {
+		return nil
+	}
+
+	criticality := &pathCriticality{high: cfg.High, low: cfg.Low, noWeight: cfg.Disable}
+	if !cfg.DisableCodeOwners {
+		owners, err := analyze.LoadCodeOwners(ctx, s.provider, request.ProjectID, request.MergeRequest.TargetBranch)
+		if err != nil {
+			log.DebugIf(s.cfg.Verbose, "CODEOWNERS is not loaded", "error", err)
+		}
+		criticality.owners = owners
+	}
+
+	return criticality
+}
+
+// level returns criticality of the file: configured patterns go first, then files owned
+// by a specific CODEOWNERS rule are critical
+func (c *pathCriticality) level(filePath string) Criticality {
+	switch {
+	case c == nil || filePath == "":
+		return CriticalityNormal
+	case matchesPath(filePath, c.high):
+		return CriticalityHigh
+	case matchesPath(filePath, c.low):
+		return CriticalityLow
+	}
+	if owners, catchAll := c.owners.Owners(filePath); len(owners) > 0 && !catchAll {
+		return CriticalityHigh
+	}
+	return CriticalityNormal
+}
+
+// weigh raises priorities of findings in critical files and lowers them in peripheral ones by one level
+func (c *pathCriticality) weigh(findings []*model.ReviewAIComment) {
+	if c == nil || c.noWeight {
+		return
+	}
+	for _, finding := range findings {
+		i := slices.Index(weightedPriorities, finding.Priority)
+		if i < 0 {
+			continue
+		}
+		switch c.level(finding.FilePath) {
+		case CriticalityHigh:
+			i = min(i+1, len(weightedPriorities)-1)
+		case CriticalityLow:
+			i = max(i-1, 0)
+		}
+		finding.Priority = weightedPriorities[i]
+	}
+}
+
+// mentions returns owners of files with critical and high findings from matched CODEOWNERS rules,
+// owners are de-duplicated in order of findings
+func (c *pathCriticality) mentions(findings []*model.ReviewAIComment) []string {
+	if c == nil {
+		return nil
+	}
+	var mentions []string
+	for _, finding := range findings {
+		if finding.FilePath == "" || !finding.Priority.IsAtLeast(model.ReviewPriorityHigh) {
+			continue
+		}
+		owners, _ := c.owners.Owners(finding.FilePath)
+		for _, owner := range owners {
+			if !slices.Contains(mentions, owner) {
+				mentions = append(mentions, owner)
+			}
+		}
+	}
+	return mentions
+}
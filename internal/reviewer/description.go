@@ -36,66 +36,71 @@ func (s *Reviewer) createDescription(ctx context.Context, request model.ReviewRe
 		return errm.New("empty description")
 	}
 
-	// Update description with changes section
-	newDescription := s.updateDescriptionWithAISection(request.MergeRequest.Description, description)
+	return s.updateDescriptionSection(ctx, request, startMarkerDesc, endMarkerDesc, description)
+}
+
+// descriptionSections are markers of codry sections of the description in the order of their placement,
+// the sections are above the text of the author
+var descriptionSections = [][2]string{
+	{startMarkerDesc, endMarkerDesc},
+	{startMarkerArchitecture, endMarkerArchitecture},
+}
 
-	// Update MR description
-	err = s.provider.UpdateMergeRequestDescription(ctx, request.ProjectID, request.MergeRequest.IID, newDescription)
+// updateDescriptionSection updates the section with the markers in the MR description, the description of the request
+// is updated too, so the next stage updating another section keeps this one
+func (s *Reviewer) updateDescriptionSection(ctx context.Context, request model.ReviewRequest, startMarker, endMarker, content string) error {
+	newDescription := mergeDescriptionSection(request.MergeRequest.Description, startMarker, endMarker, content)
+
+	err := s.provider.UpdateMergeRequestDescription(ctx, request.ProjectID, request.MergeRequest.IID, newDescription)
 	if err != nil {
 		return errm.Wrap(err, "failed to update MR description")
 	}
+	request.MergeRequest.Description = newDescription
 
 	return nil
 }
 
-// updateDescriptionWithAISection updates MR description with AI section
-func (s *Reviewer) updateDescriptionWithAISection(currentDescription, newAIDescription string) string {
-	var (
-		startPos = strings.Index(currentDescription, startMarkerDesc)
-		endPos   int
-
-		description = strings.Builder{}
-	)
-
-	if startPos != -1 {
-		endPos = strings.Index(currentDescription, endMarkerDesc) + len(endMarkerDesc)
+// mergeDescriptionSection replaces the section with the markers in the description with the content;
+// a new section is placed after codry sections preceding it in descriptionSections, or at the top
+// with the text of the author below a separator, so the order doesn't depend on the order of stages
+func mergeDescriptionSection(currentDescription, startMarker, endMarker, content string) string {
+	section := startMarker + "\n" + content + "\n" + endMarker
+
+	// Check if the section already exists in current description
+	if startPos := strings.Index(currentDescription, startMarker); startPos != -1 {
+		if endPos := strings.Index(currentDescription[startPos:], endMarker); endPos != -1 {
+			endPos += startPos + len(endMarker)
+			return currentDescription[:startPos] + section + currentDescription[endPos:]
+		}
 	}
 
-	// Check if AI section already exists in current description
-	if startPos != -1 && endPos != -1 {
-
-		description.Grow(len(currentDescription[:startPos]) + len(currentDescription[endPos:]) + len(newAIDescription) + len(startMarkerDesc) + len(endMarkerDesc) + 20)
-
-		// Build new description with existing content before AI section
-		description.WriteString(currentDescription[:startPos])
-		description.WriteString(startMarkerDesc)
-		description.WriteString("\n")
-		description.WriteString(newAIDescription)
-		description.WriteString("\n")
-		description.WriteString(endMarkerDesc)
-
-		// Add remaining content after AI section if any
-		if endPos < len(currentDescription) {
-			description.WriteString(currentDescription[endPos:])
+	insertPos, following := 0, false
+	for _, markers := range descriptionSections {
+		if markers[0] == startMarker {
+			following = true
+			continue
+		}
+		pos := strings.Index(currentDescription, markers[0])
+		if pos == -1 {
+			continue
+		}
+		if following {
+			if insertPos == 0 && pos == 0 {
+				// The description starts with a following codry section, it is not the text of the author
+				return section + "\n\n" + currentDescription
+			}
+			continue
+		}
+		if end := strings.Index(currentDescription[pos:], markers[1]); end != -1 {
+			insertPos = max(insertPos, pos+end+len(markers[1]))
 		}
-
-		return description.String()
 	}
-
-	description.Grow(len(currentDescription) + len(newAIDescription) + len(startMarkerDesc) + len(endMarkerDesc) + 20)
-
-	description.WriteString(startMarkerDesc)
-	description.WriteString("\n")
-	description.WriteString(newAIDescription)
-	description.WriteString("\n")
-	description.WriteString(endMarkerDesc)
+	if insertPos > 0 {
+		return currentDescription[:insertPos] + "\n\n" + section + currentDescription[insertPos:]
+	}
 
 	if currentDescription == "" {
-		return description.String()
+		return section
 	}
-
-	description.WriteString("\n\n---\n\n")
-	description.WriteString(currentDescription)
-
-	return description.String()
+	return section + "\n\n---\n\n" + currentDescription
 }
@@ -2,8 +2,6 @@ package reviewer
 
 import (
 	"fmt"
-	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/maxbolgarin/codry/internal/model"
@@ -19,6 +17,13 @@ const (
 	diffRemovedLine diffLineType = "removed"
 )
 
+// diffLineTypes are types of lines of parsed hunks
+var diffLineTypes = map[model.DiffLineKind]diffLineType{
+	model.DiffLineContext: diffContextLine,
+	model.DiffLineAdded:   diffAddedLine,
+	model.DiffLineRemoved: diffRemovedLine,
+}
+
 // diffLine represents a single line in a diff with its context
 type diffLine struct {
 	Type     diffLineType
@@ -61,107 +66,39 @@ const (
 )
 
 // diffParser parses unified diff format
-type diffParser struct {
-	hunkHeaderRegex *regexp.Regexp
-}
+type diffParser struct{}
 
 // newDiffParser creates a new diff parser
 func newDiffParser() *diffParser {
-	return &diffParser{
-		hunkHeaderRegex: regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`),
-	}
+	return &diffParser{}
 }
 
-// parseDiffToLines parses a unified diff and returns line information
+// parseDiffToLines parses a unified diff and returns line information, hunk headers are kept as header lines
 func (dp *diffParser) parseDiffToLines(diff string) ([]*diffLine, error) {
-	lines := strings.Split(diff, "\n")
-	result := make([]*diffLine, 0, len(lines))
-
-	var oldLine, newLine, position int
-
-	for i, line := range lines {
-		position = i + 1
-
-		// Parse diff content
-		if len(line) == 0 {
-			continue
-		}
-
-		// Skip file headers
-		if strings.HasPrefix(line, "diff --git") ||
-			strings.HasPrefix(line, "index ") ||
-			strings.HasPrefix(line, "--- ") ||
-			strings.HasPrefix(line, "+++ ") {
-			continue
-		}
-
-		// Parse hunk header
-		if strings.HasPrefix(line, "@@") {
-			matches := dp.hunkHeaderRegex.FindStringSubmatch(line)
-			if len(matches) >= 4 {
-				oldLine, _ = strconv.Atoi(matches[1])
-				newLine, _ = strconv.Atoi(matches[3])
-			}
-
+	var result []*diffLine
+	for _, hunk := range model.ParseHunks(diff) {
+		result = append(result, &diffLine{
+			Type:     diffHeaderLine,
+			Position: hunk.Position,
+		})
+		for _, line := range hunk.Lines {
 			result = append(result, &diffLine{
-				Type:     diffHeaderLine,
-				Content:  line,
-				Position: position,
+				Type:     diffLineTypes[line.Kind],
+				Content:  line.Content,
+				OldLine:  line.OldLine,
+				NewLine:  line.NewLine,
+				Position: line.Position,
 			})
-			continue
-		}
-
-		switch line[0] {
-		case '+':
-			result = append(result, &diffLine{
-				Type:     diffAddedLine,
-				Content:  line[1:],
-				NewLine:  newLine,
-				Position: position,
-			})
-			newLine++
-
-		case '-':
-			result = append(result, &diffLine{
-				Type:     diffRemovedLine,
-				Content:  line[1:],
-				OldLine:  oldLine,
-				Position: position,
-			})
-			oldLine++
-
-		case ' ':
-			result = append(result, &diffLine{
-				Type:     diffContextLine,
-				Content:  line[1:],
-				OldLine:  oldLine,
-				NewLine:  newLine,
-				Position: position,
-			})
-			oldLine++
-			newLine++
-
-		default:
-			// Context line without space prefix
-			result = append(result, &diffLine{
-				Type:     diffContextLine,
-				Content:  line,
-				OldLine:  oldLine,
-				NewLine:  newLine,
-				Position: position,
-			})
-			oldLine++
-			newLine++
-
 		}
 	}
 
 	return result, nil
 }
 
-// enhanceReviewComments enhances review comments with line positions and context
-func (dp *diffParser) enhanceReviewComments(diff string, comments []*model.ReviewAIComment) error {
-	lineMapping, err := dp.createLineMapping(diff)
+// enhanceReviewComments enhances review comments with line positions and context,
+// ranges are cut to the hunk of their start line because providers reject ranges spanning unchanged gaps
+func (dp *diffParser) enhanceReviewComments(change *model.FileDiff, comments []*model.ReviewAIComment) error {
+	lineMapping, err := dp.createLineMapping(change.Diff)
 	if err != nil {
 		return err
 	}
@@ -172,18 +109,12 @@ func (dp *diffParser) enhanceReviewComments(diff string, comments []*model.Revie
 			comment.Position = position
 		}
 
-		// For range comments, validate that end line is reasonable
 		if comment.IsRangeComment() {
-			// Ensure end line doesn't exceed available lines
-			maxLine := 0
-			for line := range lineMapping {
-				if line > maxLine {
-					maxLine = line
-				}
-			}
-			if comment.EndLine > maxLine {
-				comment.EndLine = maxLine
+			_, end, ok := change.ClampLineRange(comment.Line, comment.EndLine)
+			if !ok || end == comment.Line {
+				end = 0 // single line
 			}
+			comment.EndLine = end
 		}
 	}
 
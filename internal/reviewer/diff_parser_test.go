@@ -0,0 +1,45 @@
+package reviewer
+
+import (
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+func TestEnhanceReviewCommentsRange(t *testing.T) {
+	// New lines 1-4 and 11-14 are in hunks, lines 5-10 are an unchanged gap
+	const diff = "@@ -1,3 +1,4 @@\n a\n+b\n c\n d\n@@ -10,3 +11,4 @@\n e\n+f\n g\n h\n"
+
+	tests := []struct {
+		name          string
+		line, endLine int
+		wantEndLine   int
+		wantStartLine int
+		wantRangeEnd  int
+	}{
+		{"multi-line range within a hunk", 1, 4, 4, 1, 4},
+		{"range crossing the hunk boundary", 2, 13, 4, 2, 4},
+		{"range cut to a single line", 4, 12, 0, 0, 0},
+		{"range starting in the gap", 7, 12, 0, 0, 0},
+		{"single line", 12, 0, 0, 0, 0},
+	}
+
+	codeReviewer, _, _ := newTestReviewer(t, testConfig(), newFilesDiff("a.go"), &stubAPI{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := &model.ReviewAIComment{FilePath: "a.go", Line: tt.line, EndLine: tt.endLine, Title: "Issue"}
+			err := codeReviewer.parser.enhanceReviewComments(&model.FileDiff{NewPath: "a.go", Diff: diff}, []*model.ReviewAIComment{finding})
+			if err != nil {
+				t.Fatalf("enhanceReviewComments() error = %v", err)
+			}
+			if finding.Line != tt.line || finding.EndLine != tt.wantEndLine {
+				t.Errorf("finding lines = %d-%d, want %d-%d", finding.Line, finding.EndLine, tt.line, tt.wantEndLine)
+			}
+
+			comment := codeReviewer.reviewToComment(finding)
+			if comment.StartLine != tt.wantStartLine || comment.EndLine != tt.wantRangeEnd {
+				t.Errorf("comment range = %d-%d, want %d-%d", comment.StartLine, comment.EndLine, tt.wantStartLine, tt.wantRangeEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// getMergeRequestDiffs returns diffs of the merge request from the configured source and the source used,
+// the diff of the merge request is returned if the merge result is not supported by the provider or not available
+func (s *Reviewer) getMergeRequestDiffs(ctx context.Context, projectID string, mr *model.MergeRequest) ([]*model.FileDiff, model.DiffSource, error) {
+	if s.cfg.DiffSource == model.DiffSourceMergeResult {
+		diffs, err := s.getMergeResultDiffs(ctx, projectID, mr)
+		if err == nil {
+			s.log.InfoIf(s.cfg.Verbose, "reviewing merge result", "mr_iid", mr.IID, "files", len(diffs))
+			return diffs, model.DiffSourceMergeResult, nil
+		}
+		if errm.Is(err, model.ErrNotFound) {
+			s.log.Info("merge result is not available, reviewing diff of merge request", "mr_iid", mr.IID)
+		} else {
+			s.log.Warn("failed to get merge result, reviewing diff of merge request", "mr_iid", mr.IID, "error", err)
+		}
+	}
+
+	diffs, err := s.provider.GetMergeRequestDiffs(ctx, projectID, mr.IID)
+	if err != nil {
+		return nil, "", err
+	}
+	return diffs, model.DiffSourceMergeRequest, nil
+}
+
+func (s *Reviewer) getMergeResultDiffs(ctx context.Context, projectID string, mr *model.MergeRequest) ([]*model.FileDiff, error) {
+	reader, ok := s.provider.(interfaces.MergeResultReader)
+	if !ok {
+		return nil, errm.New("provider does not support merge results")
+	}
+	return reader.GetMergeResultDiffs(ctx, projectID, mr.IID, mr.SHA)
+}
+
+// diffSourceNote tells which diff was reviewed if the merge result is configured, empty otherwise
+func (s *Reviewer) diffSourceNote(request model.ReviewRequest) string {
+	if s.cfg.DiffSource != model.DiffSourceMergeResult {
+		return ""
+	}
+	if request.DiffSource == model.DiffSourceMergeResult {
+		return fmt.Sprintf("\n\n> ℹ️ Reviewed as merged into `%s`: the diff of the merge result against the target branch.\n", request.MergeRequest.TargetBranch)
+	}
+	return "\n\n> ℹ️ The merge result is not available (the merge request may have conflicts), the diff of the merge request is reviewed.\n"
+}
@@ -0,0 +1,166 @@
+package reviewer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// fileLimit is the choice of files for the code review of a merge request with more files than the limit
+// or more changed lines than the triage threshold
+type fileLimit struct {
+	total        int
+	changedLines int
+	// budget is the limit of estimated tokens of reviewed diffs, 0 if the triage is not applied
+	budget         int
+	reviewedTokens int
+	reviewed       []analyze.ChangeRisk // in the order of review
+	skipped        []analyze.ChangeRisk
+}
+
+// limitCodeReviewFiles returns files for the code review: all files if they fit the limit of files per review
+// and the triage budget, otherwise files with the highest risk within the limits in the order of risk and the choice
+// for the summary; the triage budget is applied only to merge requests with many changed lines
+func (s *Reviewer) limitCodeReviewFiles(files []*model.FileDiff, log logze.Logger) ([]*model.FileDiff, *fileLimit) {
+	limit := s.maxCodeReviewFiles()
+	if (limit == 0 || len(files) <= limit) && s.cfg.Triage.MinChangedLines == 0 {
+		return files, nil
+	}
+
+	type rankedFile struct {
+		file *model.FileDiff
+		risk analyze.ChangeRisk
+	}
+	ranked := make([]rankedFile, 0, len(files))
+	limited := &fileLimit{total: len(files)}
+	for _, file := range files {
+		risk := s.semantic.AssessChangeRisk(file, s.cfg.Triage.Weights, s.securityScanner)
+		ranked = append(ranked, rankedFile{file: file, risk: risk})
+		limited.changedLines += risk.ChangedLines
+	}
+	if s.cfg.Triage.MinChangedLines > 0 && limited.changedLines >= s.cfg.Triage.MinChangedLines {
+		limited.budget = s.cfg.Triage.MaxReviewTokens
+	}
+	if (limit == 0 || len(files) <= limit) && limited.budget == 0 {
+		return files, nil
+	}
+	slices.SortStableFunc(ranked, func(a, b rankedFile) int {
+		return cmp.Or(cmp.Compare(b.risk.Score, a.risk.Score), cmp.Compare(b.risk.ChangedLines, a.risk.ChangedLines))
+	})
+
+	// Smaller files of lower risk may still fit the budget after a large one is skipped
+	selected := make([]*model.FileDiff, 0, len(files))
+	for _, item := range ranked {
+		overLimit := limit > 0 && len(selected) >= limit
+		overBudget := limited.budget > 0 && len(selected) > 0 && limited.reviewedTokens+item.risk.EstimatedTokens > limited.budget
+		if overLimit || overBudget {
+			limited.skipped = append(limited.skipped, item.risk)
+			continue
+		}
+		selected = append(selected, item.file)
+		limited.reviewed = append(limited.reviewed, item.risk)
+		limited.reviewedTokens += item.risk.EstimatedTokens
+	}
+	if len(limited.skipped) == 0 {
+		return files, nil
+	}
+
+	log.Warn("too many changes to review, files with the highest risk are reviewed", "files", len(files),
+		"reviewed", len(selected), "changed_lines", limited.changedLines, "limit", limit, "budget", limited.budget)
+	return selected, limited
+}
+
+// maxCodeReviewFiles returns the smaller of the limits of files per review and per merge request, 0 means no limit
+func (s *Reviewer) maxCodeReviewFiles() int {
+	switch {
+	case s.cfg.MaxFilesPerMR == 0:
+		return s.cfg.MaxFilesPerReview
+	case s.cfg.MaxFilesPerReview == 0:
+		return s.cfg.MaxFilesPerMR
+	default:
+		return min(s.cfg.MaxFilesPerMR, s.cfg.MaxFilesPerReview)
+	}
+}
+
+// filter removes files skipped due to the limit, files unknown to the limit are kept; nil limit keeps all files
+func (l *fileLimit) filter(files []*model.FileDiff) []*model.FileDiff {
+	if l == nil {
+		return files
+	}
+	skipped := make(map[string]bool, len(l.skipped))
+	for _, risk := range l.skipped {
+		skipped[risk.FilePath] = true
+	}
+	return slices.DeleteFunc(slices.Clone(files), func(file *model.FileDiff) bool { return skipped[file.NewPath] })
+}
+
+// postFileLimitSummary lists files reviewed and skipped due to the limit of files per review or the triage budget
+// in a general comment, the comment of the previous review is updated, it is not created if all files fit the limits
+func (s *Reviewer) postFileLimitSummary(ctx context.Context, bundle *reviewBundle) {
+	if s.maxCodeReviewFiles() == 0 && s.cfg.Triage.MinChangedLines == 0 {
+		return
+	}
+
+	body := fmt.Sprintf("✅ All %d files were reviewed.", len(bundle.codeReviewFiles))
+	if bundle.fileLimit != nil {
+		body = buildFileLimitSummary(bundle.fileLimit)
+	}
+	if err := s.upsertMarkedComment(ctx, bundle.request, startMarkerFileLimit, endMarkerFileLimit, body, bundle.fileLimit != nil); err != nil {
+		msg := "failed to create file limit summary"
+		bundle.log.Err(err, msg)
+		bundle.result.Errors = append(bundle.result.Errors, errm.Wrap(err, msg))
+		return
+	}
+
+	bundle.log.InfoIf(s.cfg.Verbose, "posted file limit summary", "limited", bundle.fileLimit != nil)
+}
+
+// buildFileLimitSummary renders reviewed files with reasons of their choice and skipped files into collapsible sections
+func buildFileLimitSummary(limited *fileLimit) string {
+	var sb strings.Builder
+
+	sb.WriteString("## ⚠️ Partial code review\n\n")
+	sb.WriteString(fmt.Sprintf("The merge request has %d files with %d changed lines, only %d of them were reviewed file by file",
+		limited.total, limited.changedLines, len(limited.reviewed)))
+	if limited.budget > 0 {
+		sb.WriteString(fmt.Sprintf(" within the budget of %d tokens (about %d used)", limited.budget, limited.reviewedTokens))
+	}
+	sb.WriteString(fmt.Sprintf(", it is %d%% of the changed lines. ", coveredLinesPercent(limited)))
+	sb.WriteString("Files with breaking, exported and cross-package changes, possible security issues and security-sensitive paths were chosen first, ")
+	sb.WriteString("the skipped files are still a part of the description and the overview.\n\n")
+
+	writeFiles := func(title string, risks []analyze.ChangeRisk) {
+		sb.WriteString(fmt.Sprintf("<details>\n<summary>%s · %d</summary>\n\n", title, len(risks)))
+		for _, risk := range risks {
+			sb.WriteString(fmt.Sprintf("- `%s`", risk.FilePath))
+			if len(risk.Reasons) > 0 {
+				sb.WriteString(" — " + strings.Join(risk.Reasons, ", "))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n</details>\n")
+	}
+	writeFiles("Reviewed files", limited.reviewed)
+	writeFiles("Skipped files", limited.skipped)
+
+	return sb.String()
+}
+
+// coveredLinesPercent returns the share of changed lines in reviewed files
+func coveredLinesPercent(limited *fileLimit) int {
+	if limited.changedLines == 0 {
+		return 0
+	}
+	var covered int
+	for _, risk := range limited.reviewed {
+		covered += risk.ChangedLines
+	}
+	return covered * 100 / limited.changedLines
+}
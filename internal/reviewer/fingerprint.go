@@ -0,0 +1,58 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+var fingerprintMarkerRegex = regexp.MustCompile(`<!-- codry-finding:([0-9a-f]+) -->`)
+
+// collectPostedFingerprints remembers fingerprints of unresolved findings posted to the merge request, e.g. by a review
+// of the same head that failed in the middle of posting and is retried; the provider keeps them in footers
+// of comments, so they survive restarts of the process; comments are read once per review
+func (s *Reviewer) collectPostedFingerprints(ctx context.Context, bundle *reviewBundle) {
+	if bundle.postedFingerprints != nil {
+		return
+	}
+	bundle.postedFingerprints = make(map[string]bool)
+
+	request := bundle.request
+	comments, err := s.provider.GetComments(ctx, request.ProjectID, request.MergeRequest.IID)
+	if err != nil {
+		bundle.log.Warn("failed to get comments with posted findings", "error", err)
+		return
+	}
+	for _, comment := range comments {
+		match := fingerprintMarkerRegex.FindStringSubmatch(comment.Footer)
+		if match == nil || comment.Resolved || !s.isBotComment(comment) {
+			continue
+		}
+		bundle.postedFingerprints[match[1]] = true
+	}
+	bundle.log.DebugIf(s.cfg.Verbose, "collected fingerprints of posted findings", "count", len(bundle.postedFingerprints))
+}
+
+// findingFingerprint returns the stable hash of the file, the normalized title and the anchor of the commented line,
+// the line number is used if the line can't be anchored; findings of commits include the commit
+func (s *Reviewer) findingFingerprint(ctx context.Context, bundle *reviewBundle, finding *model.ReviewAIComment) string {
+	position := strconv.Itoa(finding.Line)
+	if finding.FilePath != "" && finding.CommitSHA == "" {
+		if lines, err := s.headLines(ctx, bundle, finding.FilePath); err == nil {
+			if anchor := lineAnchor(lines, finding.Line); anchor != "" {
+				position = anchor
+			}
+		}
+	}
+	title := strings.Join(strings.Fields(strings.ToLower(finding.Title)), " ")
+	return hashKey(finding.FilePath, finding.CommitSHA, title, position)[:16]
+}
+
+// fingerprintMarker renders the fingerprint as a hidden marker of the comment footer
+func fingerprintMarker(fingerprint string) string {
+	return fmt.Sprintf("<!-- codry-finding:%s -->", fingerprint)
+}
@@ -0,0 +1,101 @@
+package reviewer
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/provider/local"
+)
+
+// crashingProvider stops the review like a crash of the process after the number of created inline comments
+type crashingProvider struct {
+	interfaces.CodeProvider
+	cancel     context.CancelFunc
+	crashAfter int
+
+	mu      sync.Mutex
+	created int
+}
+
+func (p *crashingProvider) CreateComment(ctx context.Context, projectID string, mrIID int, comment *model.Comment) error {
+	if err := p.CodeProvider.CreateComment(ctx, projectID, mrIID, comment); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if comment.Type == model.CommentTypeInline {
+		p.created++
+		if p.created == p.crashAfter {
+			p.cancel()
+		}
+	}
+	return nil
+}
+
+func TestReviewMergeRequestRetryAfterCrash(t *testing.T) {
+	// Findings without a file path get the path of the reviewed file
+	const findings = `{"has_issues": true, "comments": [` +
+		`{"line": 3, "issue_type": "bug", "confidence": "high", "priority": "high", "title": "First issue", "description": "First.", "suggestion": "Fix."},` +
+		`{"line": 4, "issue_type": "bug", "confidence": "high", "priority": "high", "title": "Second issue", "description": "Second.", "suggestion": "Fix."}]}`
+	diff := newFilesDiff("a.go", "b.go")
+
+	// review runs a new reviewer like a new process, so nothing is kept in memory between runs
+	review := func(t *testing.T, ctx context.Context, provider interfaces.CodeProvider, request model.ReviewRequest) {
+		t.Helper()
+		api := &stubAPI{review: func(context.Context, int) (string, error) { return findings, nil }}
+		cfg := testConfig()
+		cfg.EnableCodeReview = true
+		codeReviewer, err := New(cfg, provider, agent.NewWithAPI(agent.Config{}, api))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		_ = codeReviewer.ReviewMergeRequest(ctx, request.ProjectID, request.MergeRequest)
+	}
+	newProvider := func() (*local.Provider, model.ReviewRequest) {
+		diffs := model.ParseUnifiedDiff(diff)
+		provider := local.New(t.TempDir(), diffs, nil)
+		return provider, model.ReviewRequest{ProjectID: "local", MergeRequest: provider.MergeRequest(), Changes: diffs}
+	}
+
+	// The review without a crash posts all findings once
+	cleanProvider, cleanRequest := newProvider()
+	review(t, context.Background(), cleanProvider, cleanRequest)
+	want := inlineComments(cleanProvider)
+	if want < 4 {
+		t.Fatalf("inline comments of the review = %d, want at least 4", want)
+	}
+
+	provider, request := newProvider()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	crashAfter := want / 2
+	review(t, ctx, &crashingProvider{CodeProvider: provider, cancel: cancel, crashAfter: crashAfter}, request)
+	if got := inlineComments(provider); got != crashAfter {
+		t.Fatalf("inline comments before the crash = %d, want %d", got, crashAfter)
+	}
+
+	// The retry posts only the rest
+	review(t, context.Background(), provider, request)
+	if got := inlineComments(provider); got != want {
+		t.Errorf("inline comments after the retry = %d, want %d", got, want)
+	}
+	seen := make(map[string]bool)
+	for _, comment := range provider.Comments() {
+		if comment.Type != model.CommentTypeInline {
+			continue
+		}
+		match := fingerprintMarkerRegex.FindStringSubmatch(comment.Footer)
+		if match == nil {
+			t.Errorf("comment %s:%d has no fingerprint", comment.FilePath, comment.Line)
+			continue
+		}
+		if seen[match[1]] {
+			t.Errorf("comment %s:%d is posted twice", comment.FilePath, comment.Line)
+		}
+		seen[match[1]] = true
+	}
+}
@@ -0,0 +1,52 @@
+package reviewer
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// focusMarkerRegex matches lines of the merge request description like "codry-focus: performance"
+var focusMarkerRegex = regexp.MustCompile(`(?im)^[ \t]*codry-focus:[ \t]*(.+?)[ \t]*$`)
+
+// reviewInstructions combines custom instructions of the config, .codry.yml of the target branch and focus
+// markers of the merge request description, the result is cut to the configured length
+func (s *Reviewer) reviewInstructions(ctx context.Context, request model.ReviewRequest, log logze.Logger) string {
+	var parts []string
+	if text := strings.TrimSpace(s.cfg.Instructions.Text); text != "" {
+		parts = append(parts, text)
+	}
+
+	repoCfg, err := analyze.LoadRepoConfig(ctx, s.provider, request.ProjectID, request.MergeRequest.TargetBranch)
+	if err != nil {
+		log.DebugIf(s.cfg.Verbose, "repository config is not loaded", "error", err)
+	} else if text := strings.TrimSpace(repoCfg.Instructions); text != "" {
+		parts = append(parts, text)
+	}
+
+	if !s.cfg.Instructions.DisableFocusMarker {
+		var focus []string
+		for _, match := range focusMarkerRegex.FindAllStringSubmatch(request.MergeRequest.Description, -1) {
+			focus = append(focus, match[1])
+		}
+		if len(focus) > 0 {
+			parts = append(parts, "Focus of this merge request: "+strings.Join(focus, "; "))
+		}
+	}
+
+	instructions := strings.Join(parts, "\n\n")
+	if utf8.RuneCountInString(instructions) > s.cfg.Instructions.MaxLength {
+		log.Warn("custom instructions are too long, cutting them", "length", utf8.RuneCountInString(instructions), "limit", s.cfg.Instructions.MaxLength)
+		instructions = string([]rune(instructions)[:s.cfg.Instructions.MaxLength])
+	}
+	if instructions != "" {
+		log.InfoIf(s.cfg.Verbose, "using custom review instructions", "length", utf8.RuneCountInString(instructions))
+	}
+
+	return instructions
+}
@@ -0,0 +1,52 @@
+package reviewer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// mergeRequestIntent returns the title and the description of the merge request written by its author,
+// codry sections of the description are removed and the result is cut to the configured length
+func (s *Reviewer) mergeRequestIntent(mr *model.MergeRequest, log logze.Logger) string {
+	if s.cfg.Intent.Disable || mr == nil {
+		return ""
+	}
+
+	var parts []string
+	if title := strings.TrimSpace(mr.Title); title != "" {
+		parts = append(parts, "Title: "+title)
+	}
+	if description := authorDescription(mr.Description); description != "" {
+		parts = append(parts, "Description:\n"+description)
+	}
+
+	intent := strings.Join(parts, "\n\n")
+	if utf8.RuneCountInString(intent) > s.cfg.Intent.MaxLength {
+		log.DebugIf(s.cfg.Verbose, "merge request intent is too long, cutting it", "length", utf8.RuneCountInString(intent), "limit", s.cfg.Intent.MaxLength)
+		intent = string([]rune(intent)[:s.cfg.Intent.MaxLength]) + "..."
+	}
+
+	return intent
+}
+
+// authorDescription removes codry sections and the separator below them from the description
+func authorDescription(description string) string {
+	for _, markers := range descriptionSections {
+		startPos := strings.Index(description, markers[0])
+		if startPos == -1 {
+			continue
+		}
+		endPos := strings.Index(description[startPos:], markers[1])
+		if endPos == -1 {
+			continue
+		}
+		description = description[:startPos] + description[startPos+endPos+len(markers[1]):]
+	}
+
+	description = strings.TrimSpace(description)
+	description = strings.TrimPrefix(description, "---")
+	return strings.TrimSpace(description)
+}
@@ -0,0 +1,96 @@
+package reviewer
+
+import (
+	"context"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+const (
+	// maxRestoredContentSize is the combined size of both versions of a file above which its diff is not built
+	maxRestoredContentSize = 2 << 20
+	// maxRestoredDiffEdits is the number of changed lines above which building a diff is stopped
+	maxRestoredDiffEdits = 5000
+)
+
+var errDiffTooLarge = errm.New("file is too large to diff")
+
+// restoreTooLargeDiffs builds diffs of textual files that the provider returned without a patch from their contents
+// at the target and the head commits; files with binary content are marked as binary, files that can't be diffed
+// stay too large and are listed in the overview; files that are not reviewed anyway are not fetched
+func (s *Reviewer) restoreTooLargeDiffs(ctx context.Context, request model.ReviewRequest, log logze.Logger) {
+	for _, file := range request.Changes {
+		if !file.IsTooLarge || file.IsDeleted || s.isExcludedPath(file.NewPath) ||
+			!s.isAnalyzedExtension(file.NewPath) || !s.isCodeFile(file.NewPath) {
+			continue
+		}
+
+		diff, err := s.buildFileDiff(ctx, request, file)
+		switch {
+		case errm.Is(err, model.ErrBinaryContent):
+			file.IsBinary, file.IsTooLarge = true, false
+			log.DebugIf(s.cfg.Verbose, "file without patch is binary", "file", file.NewPath)
+		case err != nil:
+			log.DebugIf(s.cfg.Verbose, "failed to build diff of file without patch", "file", file.NewPath, "error", err)
+		default:
+			file.SetDiff(diff)
+			file.IsTooLarge = false
+			log.DebugIf(s.cfg.Verbose, "built diff of file without patch", "file", file.NewPath, "size", len(diff))
+		}
+	}
+}
+
+// buildFileDiff returns the diff of the file built from its versions, the old version of a new file is empty
+func (s *Reviewer) buildFileDiff(ctx context.Context, request model.ReviewRequest, file *model.FileDiff) (string, error) {
+	before, after, err := s.fetchFileVersions(ctx, request, file)
+	if err != nil {
+		return "", err
+	}
+	if len(before)+len(after) > maxRestoredContentSize {
+		return "", errDiffTooLarge
+	}
+
+	diff, ok := model.BuildUnifiedDiff(before, after, maxRestoredDiffEdits)
+	if !ok {
+		return "", errDiffTooLarge
+	}
+	return diff, nil
+}
+
+// fetchFileVersions returns contents of the file at the target and the head commits, the old version of a new file is empty
+func (s *Reviewer) fetchFileVersions(ctx context.Context, request model.ReviewRequest, file *model.FileDiff) (string, string, error) {
+	after, err := analyze.FetchFileContent(ctx, s.provider, request.ProjectID, file.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		return "", "", errm.Wrap(err, "failed to get new version")
+	}
+	var before string
+	if !file.IsNew {
+		oldPath := file.OldPath
+		if oldPath == "" {
+			oldPath = file.NewPath
+		}
+		if before, err = analyze.FetchFileContent(ctx, s.provider, request.ProjectID, oldPath, request.MergeRequest.TargetBranch); err != nil {
+			return "", "", errm.Wrap(err, "failed to get old version")
+		}
+	}
+	return before, after, nil
+}
+
+// tooLargeFiles returns paths of files that are reviewed by their filters but have no diff to review:
+// the provider returned no patch and it can't be built, or the diff is larger than the file size limit
+func (s *Reviewer) tooLargeFiles(request model.ReviewRequest) []string {
+	var paths []string
+	for _, file := range request.Changes {
+		if file.IsDeleted || file.IsBinary || s.isExcludedPath(file.NewPath) ||
+			!s.isAnalyzedExtension(file.NewPath) || !s.isCodeFile(file.NewPath) {
+			continue
+		}
+		if file.IsTooLarge || len(file.Diff) > s.cfg.FileFilter.MaxFileSize {
+			paths = append(paths, file.NewPath)
+		}
+	}
+	return paths
+}
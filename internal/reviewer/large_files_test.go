@@ -0,0 +1,96 @@
+package reviewer
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// versionsProvider serves contents of files by ref and path, other files are not found
+type versionsProvider struct {
+	interfaces.CodeProvider
+	contents map[string]map[string]string
+}
+
+func (p *versionsProvider) GetFileContent(_ context.Context, _, filePath, ref string) (string, error) {
+	content, ok := p.contents[ref][filePath]
+	if !ok {
+		return "", errm.Wrap(model.ErrNotFound, "file is not found", "path", filePath, "ref", ref)
+	}
+	return content, nil
+}
+
+func TestRestoreTooLargeDiffs(t *testing.T) {
+	provider := &versionsProvider{contents: map[string]map[string]string{
+		"main": {
+			"const.go":     "package a\n\nconst A = 1\n",
+			"vendor/x.go":  "package x\n",
+			"renamed.go":   "package a\n\nvar B = 1\n",
+			"unchanged.go": "package a\n",
+		},
+		"head": {
+			"const.go":     "package a\n\nconst A = 2\n",
+			"new.go":       "package a\n",
+			"blob.go":      "package a\x00\x01",
+			"vendor/x.go":  "package x\n\nvar X = 1\n",
+			"moved.go":     "package a\n\nvar B = 2\n",
+			"unchanged.go": "package a\n",
+		},
+	}}
+	changes := []*model.FileDiff{
+		{OldPath: "const.go", NewPath: "const.go", IsTooLarge: true},
+		{NewPath: "new.go", IsNew: true, IsTooLarge: true},
+		{OldPath: "blob.go", NewPath: "blob.go", IsNew: true, IsTooLarge: true},
+		{OldPath: "missing.go", NewPath: "missing.go", IsTooLarge: true},
+		{OldPath: "vendor/x.go", NewPath: "vendor/x.go", IsTooLarge: true},
+		{OldPath: "renamed.go", NewPath: "moved.go", IsRenamed: true, IsTooLarge: true},
+		{OldPath: "unchanged.go", NewPath: "unchanged.go", IsTooLarge: true},
+	}
+	cfg := testConfig()
+	cfg.FileFilter.ExcludedPaths = []string{"vendor/"}
+	codeReviewer, err := New(cfg, provider, agent.NewWithAPI(agent.Config{}, &stubAPI{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	request := model.ReviewRequest{
+		ProjectID:    "local",
+		MergeRequest: &model.MergeRequest{SHA: "head", TargetBranch: "main"},
+		Changes:      changes,
+	}
+
+	codeReviewer.restoreTooLargeDiffs(context.Background(), request, codeReviewer.log)
+
+	tests := []struct {
+		path         string
+		wantDiff     string
+		wantBinary   bool
+		wantTooLarge bool
+	}{
+		{path: "const.go", wantDiff: "@@ -1,3 +1,3 @@\n package a\n \n-const A = 1\n+const A = 2\n"},
+		{path: "new.go", wantDiff: "@@ -0,0 +1,1 @@\n+package a\n"},
+		{path: "blob.go", wantBinary: true},
+		{path: "missing.go", wantTooLarge: true},
+		{path: "vendor/x.go", wantTooLarge: true},
+		{path: "moved.go", wantDiff: "@@ -1,3 +1,3 @@\n package a\n \n-var B = 1\n+var B = 2\n"},
+		{path: "unchanged.go"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got := changes[i]
+			if got.Diff != tt.wantDiff || got.IsBinary != tt.wantBinary || got.IsTooLarge != tt.wantTooLarge {
+				t.Errorf("file = diff %q, binary %v, too large %v, want %q, %v, %v",
+					got.Diff, got.IsBinary, got.IsTooLarge, tt.wantDiff, tt.wantBinary, tt.wantTooLarge)
+			}
+		})
+	}
+
+	// Excluded files are not reviewed, so only the file without content is listed in the overview
+	if got, want := codeReviewer.tooLargeFiles(request), []string{"missing.go"}; !slices.Equal(got, want) {
+		t.Errorf("tooLargeFiles() = %v, want %v", got, want)
+	}
+}
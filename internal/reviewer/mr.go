@@ -3,9 +3,11 @@ package reviewer
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/maxbolgarin/abstract"
 	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
 	"github.com/maxbolgarin/errm"
 	"github.com/maxbolgarin/lang"
 	"github.com/maxbolgarin/logze/v2"
@@ -22,27 +24,105 @@ func (s *Reviewer) GetAndReviewMergeRequest(ctx context.Context, projectID strin
 
 // ReviewMergeRequest handles merge request related events
 func (s *Reviewer) ReviewMergeRequest(ctx context.Context, projectID string, mergeRequest *model.MergeRequest) error {
+	return s.reviewMergeRequest(ctx, projectID, mergeRequest, false)
+}
+
+// reviewMergeRequest reviews the merge request, a full review ignores files and commits reviewed before
+// and doesn't repeat findings posted by previous reviews at the same lines
+func (s *Reviewer) reviewMergeRequest(ctx context.Context, projectID string, mergeRequest *model.MergeRequest, fullReview bool) error {
 	if mergeRequest == nil {
 		return errm.New("merge request is nil")
 	}
 
-	diffs, err := s.provider.GetMergeRequestDiffs(ctx, projectID, mergeRequest.IID)
+	if ok, reason := s.isReviewAllowedByLabels(mergeRequest); !ok {
+		s.log.Info("skipping merge request review", "mr_iid", mergeRequest.IID, "reason", reason)
+		return nil
+	}
+
+	diffs, source, err := s.getMergeRequestDiffs(ctx, projectID, mergeRequest)
 	if err != nil {
 		return errm.Wrap(err, "failed to get merge request diffs")
 	}
 
-	s.processMergeRequestReview(ctx, model.ReviewRequest{
+	request := model.ReviewRequest{
 		ProjectID:    projectID,
 		MergeRequest: mergeRequest,
 		Changes:      diffs,
-	})
+		DiffSource:   source,
+	}
+	// The merge result is compared with the target branch, so it has no changes of the base merge request
+	if source == model.DiffSourceMergeRequest {
+		if err := s.excludeBaseMergeRequestChanges(ctx, &request); err != nil {
+			s.log.Warn("failed to exclude changes of base merge request, reviewing all changes", "mr_iid", mergeRequest.IID, "error", err)
+		}
+	}
+	s.restoreTooLargeDiffs(ctx, request, s.log.WithFields("mr_iid", mergeRequest.IID))
+
+	s.processMergeRequestReview(ctx, request, fullReview)
 
 	return nil
 }
 
+// ReviewChanges reviews files of the request and returns findings without posting them,
+// it is used to review changes outside of merge requests like local diff files
+func (s *Reviewer) ReviewChanges(ctx context.Context, request model.ReviewRequest) ([]*model.ReviewAIComment, error) {
+	if request.MergeRequest == nil {
+		return nil, errm.New("merge request is nil")
+	}
+	ctx = model.WithCorrelationID(ctx, request.CorrelationID())
+	log := s.log.WithFields("review_id", request.CorrelationID(), "project_id", request.ProjectID)
+
+	bundle := &reviewBundle{
+		result: &model.ReviewResult{
+			CorrelationID:      request.CorrelationID(),
+			FindingsByPriority: make(map[model.ReviewPriority]int),
+		},
+		request: request,
+		log:     log,
+		timer:   abstract.StartTimer(),
+	}
+	if s.cfg.Redaction.Enable {
+		bundle.redactor = analyze.NewRedactor(s.redactionPatterns, s.cfg.Redaction.Allowlist)
+	}
+
+	filesToReview, _, _ := s.filterFilesForReview(request, log)
+	if len(filesToReview) == 0 {
+		return nil, nil
+	}
+	filesToReview, _ = s.limitCodeReviewFiles(filesToReview, log)
+	bundle.instructions = s.reviewInstructions(ctx, request, log)
+	bundle.intent = bundle.redact("", s.mergeRequestIntent(request.MergeRequest, log))
+	bundle.criticality = s.loadPathCriticality(ctx, request, log)
+	bundle.rules = s.reviewRules(ctx, request, log)
+
+	var findings []*model.ReviewAIComment
+	errs := errm.NewList()
+	for _, change := range filesToReview {
+		if err := ctx.Err(); err != nil {
+			errs.Wrap(err, "code review interrupted")
+			break
+		}
+		change.OldPath = lang.Check(change.OldPath, change.NewPath)
+
+		reviewResult, err := s.reviewFile(ctx, bundle, request, change)
+		if err != nil {
+			errs.Wrap(err, "failed to review file", "file", change.NewPath)
+			continue
+		}
+		findings = append(findings, reviewResult.Comments...)
+
+		log.InfoIf(s.cfg.Verbose, "reviewed successfully", "file", change.NewPath, "comments", len(reviewResult.Comments))
+	}
+
+	return findings, errs.Err()
+}
+
 // ProcessMergeRequest processes a merge request for the first time
-func (s *Reviewer) processMergeRequestReview(ctx context.Context, request model.ReviewRequest) {
+func (s *Reviewer) processMergeRequestReview(ctx context.Context, request model.ReviewRequest, fullReview bool) {
+	// Components get the correlation ID from the context, so all lines of the review are found by it
+	ctx = model.WithCorrelationID(ctx, request.CorrelationID())
 	log := s.log.WithFields(
+		"review_id", request.CorrelationID(),
 		"project_id", request.ProjectID,
 		"mr_iid", request.MergeRequest.IID,
 		"branch_from", request.MergeRequest.SourceBranch,
@@ -52,32 +132,110 @@ func (s *Reviewer) processMergeRequestReview(ctx context.Context, request model.
 	log.Infof("starting merge request review: %s", request.MergeRequest.Title)
 
 	reviewBundle := &reviewBundle{
-		result:  &model.ReviewResult{},
+		result: &model.ReviewResult{
+			CorrelationID:      request.CorrelationID(),
+			FindingsByPriority: make(map[model.ReviewPriority]int),
+		},
 		request: request,
+		log:     log,
 		timer:   abstract.StartTimer(),
+
+		fullReview: fullReview,
+	}
+	if fullReview {
+		log.Info("performing full review, previously reviewed files are reviewed again")
+		s.forgetReviewedFiles(request)
+	}
+	if s.cfg.Redaction.Enable {
+		// Placeholders are stable within the review, so every review gets its own redactor
+		reviewBundle.redactor = analyze.NewRedactor(s.redactionPatterns, s.cfg.Redaction.Allowlist)
 	}
 
+	s.metrics.ReviewStarted(s.metricsProvider)
+	s.startCheckRun(ctx, reviewBundle)
+
 	defer func() {
+		s.finishCheckRun(ctx, reviewBundle)
+		if reviewBundle.redactor != nil {
+			log.Info("redacted sensitive values sent to the model", "redactions", reviewBundle.redactor.Redactions())
+		}
 		s.logProcessingResults(*reviewBundle.result, reviewBundle.timer, s.log)
+		s.recordReviewMetrics(*reviewBundle.result, reviewBundle.timer)
 	}()
 
 	// Filter files for review
-	filesToReview, totalDiffLength := s.filterFilesForReview(request, log)
+	filesToReview, skippedByExtension, totalDiffLength := s.filterFilesForReview(request, log)
 	if len(filesToReview) == 0 {
 		reviewBundle.result.IsSuccess = true
 		return
 	}
 
 	reviewBundle.filesToReview = filesToReview
-	reviewBundle.fullDiffString = buildDiffString(filesToReview, totalDiffLength)
+	reviewBundle.skippedByExtension = skippedByExtension
+	reviewBundle.tooLarge = s.tooLargeFiles(request)
+	reviewBundle.fullDiffString = buildDiffString(filesToReview, totalDiffLength, reviewBundle.redact)
+	reviewBundle.instructions = s.reviewInstructions(ctx, request, log)
+	reviewBundle.intent = reviewBundle.redact("", s.mergeRequestIntent(request.MergeRequest, log))
+	reviewBundle.criticality = s.loadPathCriticality(ctx, request, log)
+	reviewBundle.rules = s.reviewRules(ctx, request, log)
+	reviewBundle.configChanges = s.loadConfigChanges(ctx, request, filesToReview, log)
 
-	s.generateDescription(ctx, reviewBundle)
-	s.generateChangesOverview(ctx, reviewBundle)
-	s.generateArchitectureReview(ctx, reviewBundle)
-	s.generateCodeReview(ctx, reviewBundle)
+	// Check run is finished with the parent context, so it is completed even if the review timed out
+	reviewCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeouts.Review)
+	defer cancel()
+
+	if !s.cfg.RawFindings.Replay {
+		// Replay re-runs processing of cached findings, stages that only generate text are not repeated
+		s.runStage(reviewCtx, reviewBundle, stageDescription, s.generateDescription)
+		s.runStage(reviewCtx, reviewBundle, stageChangesOverview, s.generateChangesOverview)
+		s.runStage(reviewCtx, reviewBundle, stageArchitectureReview, s.generateArchitectureReview)
+	}
+	s.runStage(reviewCtx, reviewBundle, stageCodeReview, s.generateCodeReview)
+	s.runStage(reviewCtx, reviewBundle, stageVerdict, s.reportReviewOutcome)
 
 	reviewBundle.result.ProcessedFiles = len(filesToReview)
 	reviewBundle.result.IsSuccess = len(reviewBundle.result.Errors) == 0
+
+	s.notifyReviewComplete(ctx, reviewBundle)
+}
+
+// Review stages used as labels of metrics
+const (
+	stageDescription        = "description"
+	stageChangesOverview    = "changes_overview"
+	stageArchitectureReview = "architecture_review"
+	stageCodeReview         = "code_review"
+	stageVerdict            = "verdict"
+)
+
+// runStage runs the review stage with its timeout and records its duration, the stage is failed if it added errors
+// to the result; a timed out stage is recorded in the result and the review goes on with the next one
+func (s *Reviewer) runStage(ctx context.Context, bundle *reviewBundle, stage string, fn func(context.Context, *reviewBundle)) {
+	stageCtx := ctx
+	if timeout := s.cfg.Timeouts.stage(stage); timeout > 0 {
+		var cancel context.CancelFunc
+		stageCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	errorsBefore := len(bundle.result.Errors)
+	start := time.Now()
+	fn(stageCtx, bundle)
+	if errm.Is(stageCtx.Err(), context.DeadlineExceeded) {
+		bundle.log.Warn("review stage timed out, skipping", "stage", stage, "elapsed_time", time.Since(start).String())
+		bundle.result.TimedOutStages = append(bundle.result.TimedOutStages, stage)
+	}
+	s.metrics.ReviewStage(s.metricsProvider, stage, time.Since(start), len(bundle.result.Errors) > errorsBefore)
+}
+
+// recordReviewMetrics records the outcome, duration and findings of the finished review
+func (s *Reviewer) recordReviewMetrics(result model.ReviewResult, timer abstract.Timer) {
+	s.metrics.ReviewFinished(s.metricsProvider, result.IsSuccess, timer.ElapsedTime())
+	for priority, count := range result.FindingsByPriority {
+		if count > 0 {
+			s.metrics.FindingsReported(s.metricsProvider, priority, count)
+		}
+	}
 }
 
 type reviewBundle struct {
@@ -87,10 +245,62 @@ type reviewBundle struct {
 	fullDiffString string
 	log            logze.Logger
 	timer          abstract.Timer
+	checkRunID     int64
+
+	// skippedByExtension are paths of files filtered out by extension lists, they are listed in the overview
+	skippedByExtension []string
+	// tooLarge are paths of files without a diff to review because they are too large, they are listed in the overview
+	tooLarge []string
+	// changesOverview is the rendered changes overview table
+	changesOverview string
+	// findings are collected review comments that are not posted yet
+	findings []*model.ReviewAIComment
+	// reportedFindings are all findings of the review for the notification about it
+	reportedFindings []*model.ReviewAIComment
+	// reviewedFiles is the number of files reviewed in this run, files reviewed in the previous run of the head are skipped
+	reviewedFiles int
+	// codeReviewFiles are files reviewed one by one, files of the merge request up to the limit of files per review
+	codeReviewFiles []*model.FileDiff
+	// fileLimit is the choice of files for the code review, it is nil if all files fit the limit
+	fileLimit *fileLimit
+	// redactor masks sensitive values in content sent to the model, it is nil if redaction is disabled
+	redactor *analyze.Redactor
+	// instructions are custom instructions of the team for the code and architecture review
+	instructions string
+	// intent is the title and the description of the merge request for the code and architecture review
+	intent string
+	// criticality weighs findings by criticality of their files, it is nil if weighting is disabled
+	criticality *pathCriticality
+	// rules are sections of project rules documents, files get sections for their types
+	rules []analyze.RuleSection
+	// configChanges are key-level diffs of config files to review, they go to the overview and prompts of the files
+	configChanges []model.ConfigChangeInfo
+	// anchors are anchors of posted unresolved findings by file, findings with them are not posted again
+	anchors map[string]bool
+	// headFiles are lines of files at the head commit fetched to anchor findings
+	headFiles map[string][]string
+	// fullReview is set when a draft became ready for review, all files are reviewed regardless of previous runs
+	fullReview bool
+	// postedLines are lines of unresolved findings of previous reviews, a full review doesn't post there again
+	postedLines map[string]bool
+	// postedFingerprints are fingerprints of unresolved findings already posted to the merge request, they are
+	// collected before the first finding is posted, so a retried review doesn't post them again
+	postedFingerprints map[string]bool
+}
+
+// redact masks sensitive values in the text of the file before it is sent to the model,
+// empty path is used for text of many files
+func (b *reviewBundle) redact(filePath, text string) string {
+	if b.redactor == nil {
+		return text
+	}
+	return b.redactor.Redact(filePath, text)
 }
 
-func (s *Reviewer) filterFilesForReview(request model.ReviewRequest, log logze.Logger) ([]*model.FileDiff, int64) {
+// filterFilesForReview returns files to review, paths of files skipped by extension and the total diff length
+func (s *Reviewer) filterFilesForReview(request model.ReviewRequest, log logze.Logger) ([]*model.FileDiff, []string, int64) {
 	var filtered []*model.FileDiff
+	var skippedByExtension []string
 
 	var totalDiffLength int64
 
@@ -105,13 +315,19 @@ func (s *Reviewer) filterFilesForReview(request model.ReviewRequest, log logze.L
 			continue
 		}
 
-		if len(file.Diff) > s.cfg.FileFilter.MaxFileSize {
-			log.DebugIf(s.cfg.Verbose, "skipping due to size", "file", file.NewPath, "size", len(file.Diff), "max_size", s.cfg.FileFilter.MaxFileSize)
+		if s.isExcludedPath(file.NewPath) {
+			log.DebugIf(s.cfg.Verbose, "skipping excluded", "file", file.NewPath)
 			continue
 		}
 
-		if s.isExcludedPath(file.NewPath) {
-			log.DebugIf(s.cfg.Verbose, "skipping excluded", "file", file.NewPath)
+		if !s.isAnalyzedExtension(file.NewPath) {
+			log.DebugIf(s.cfg.Verbose, "skipping by extension", "file", file.NewPath)
+			skippedByExtension = append(skippedByExtension, file.NewPath)
+			continue
+		}
+
+		if len(file.Diff) > s.cfg.FileFilter.MaxFileSize {
+			log.DebugIf(s.cfg.Verbose, "skipping due to size", "file", file.NewPath, "size", len(file.Diff), "max_size", s.cfg.FileFilter.MaxFileSize)
 			continue
 		}
 
@@ -127,17 +343,11 @@ func (s *Reviewer) filterFilesForReview(request model.ReviewRequest, log logze.L
 		totalDiffLength += int64(len(file.Diff))
 		totalDiffLength += int64(len(file.OldPath))
 		totalDiffLength += int64(len(file.NewPath))
-
-		// Limit number of files per MR
-		if len(filtered) >= s.cfg.MaxFilesPerMR {
-			log.Warn("reached maximum files limit", "limit", s.cfg.MaxFilesPerMR)
-			break
-		}
 	}
 
 	if len(filtered) == 0 {
 		log.InfoIf(s.cfg.Verbose, "no files to review after filtering")
-		return nil, 0
+		return nil, skippedByExtension, 0
 	}
 
 	log.InfoIf(s.cfg.Verbose, "found files to review",
@@ -145,10 +355,11 @@ func (s *Reviewer) filterFilesForReview(request model.ReviewRequest, log logze.L
 		"diff_length", totalDiffLength,
 	)
 
-	return filtered, totalDiffLength
+	return filtered, skippedByExtension, totalDiffLength
 }
 
-func buildDiffString(files []*model.FileDiff, totalDiffLength int64) string {
+// buildDiffString joins diffs of the files for prompts, redact masks sensitive values in every diff
+func buildDiffString(files []*model.FileDiff, totalDiffLength int64, redact func(filePath, text string) string) string {
 	var fullDiff strings.Builder
 	fullDiff.Grow(int(totalDiffLength) + 30)
 	for _, change := range files {
@@ -157,7 +368,7 @@ func buildDiffString(files []*model.FileDiff, totalDiffLength int64) string {
 		fullDiff.WriteString("\n+++ b/")
 		fullDiff.WriteString(change.NewPath)
 		fullDiff.WriteString("\n")
-		fullDiff.WriteString(change.Diff)
+		fullDiff.WriteString(redact(change.NewPath, change.Diff))
 		fullDiff.WriteString("\n\n")
 	}
 	return fullDiff.String()
@@ -166,12 +377,17 @@ func buildDiffString(files []*model.FileDiff, totalDiffLength int64) string {
 // logProcessingResults logs the results of MR processing
 func (s *Reviewer) logProcessingResults(result model.ReviewResult, timer abstract.Timer, log logze.Logger) {
 	log = log.WithFields(
+		"review_id", result.CorrelationID,
 		"description", result.IsDescriptionCreated,
 		"changes_overview", result.IsChangesOverviewCreated,
 		"architecture_review", result.IsArchitectureReviewCreated,
 		"code_review", result.IsCodeReviewCreated,
 		"processed_files", result.ProcessedFiles,
 		"comments_created", result.CommentsCreated,
+		"out_of_scope_files", len(result.OutOfScopeFiles),
+		"suppressed_findings", result.SuppressedFindings,
+		"low_confidence_findings", result.LowConfidenceFindings,
+		"timed_out_stages", result.TimedOutStages,
 		"elapsed_time", timer.ElapsedTime().String(),
 	)
 	if result.IsSuccess {
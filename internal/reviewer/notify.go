@@ -0,0 +1,30 @@
+package reviewer
+
+import (
+	"context"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// notifyReviewComplete sends the report of the review after its findings are posted,
+// a failed notification is logged and doesn't fail the review
+func (s *Reviewer) notifyReviewComplete(ctx context.Context, bundle *reviewBundle) {
+	if s.notifier == nil {
+		return
+	}
+
+	report := model.ReviewReport{
+		CorrelationID:      bundle.result.CorrelationID,
+		ProjectID:          bundle.request.ProjectID,
+		MergeRequest:       bundle.request.MergeRequest,
+		ProcessedFiles:     bundle.result.ProcessedFiles,
+		CommentsCreated:    bundle.result.CommentsCreated,
+		FindingsByPriority: bundle.result.FindingsByPriority,
+		Findings:           bundle.reportedFindings,
+		TimedOutStages:     bundle.result.TimedOutStages,
+		IsSuccess:          bundle.result.IsSuccess,
+	}
+	if err := s.notifier.NotifyReviewComplete(ctx, report); err != nil {
+		bundle.log.Warn("failed to send review notification", "error", err)
+	}
+}
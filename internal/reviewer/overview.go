@@ -19,7 +19,7 @@ func (s *Reviewer) generateChangesOverview(ctx context.Context, bundle *reviewBu
 	}
 	bundle.log.Debug("generating changes overview")
 
-	err := s.createOrUpdateChangesOverview(ctx, bundle.request, bundle.fullDiffString)
+	overview, err := s.createOrUpdateChangesOverview(ctx, bundle.request, bundle.fullDiffString, bundle.skippedByExtension, bundle.tooLarge, bundle.configChanges)
 	if err != nil {
 		msg := "failed to generate changes overview"
 		bundle.log.Err(err, msg)
@@ -29,17 +29,25 @@ func (s *Reviewer) generateChangesOverview(ctx context.Context, bundle *reviewBu
 
 	bundle.log.InfoIf(s.cfg.Verbose, "generated and updated changes overview comment")
 
+	bundle.changesOverview = overview
 	bundle.result.IsChangesOverviewCreated = true
 }
 
-func (s *Reviewer) createOrUpdateChangesOverview(ctx context.Context, request model.ReviewRequest, fullDiff string) error {
+func (s *Reviewer) createOrUpdateChangesOverview(ctx context.Context, request model.ReviewRequest, fullDiff string, skipped, tooLarge []string,
+	configChanges []model.ConfigChangeInfo) (string, error) {
 	changes, err := s.agent.GenerateChangesOverview(ctx, fullDiff)
 	if err != nil {
-		return errm.Wrap(err, "failed to generate changes overview")
+		return "", errm.Wrap(err, "failed to generate changes overview")
 	}
 
 	// Create the new comment content
-	newComment := s.createCommentWithChangesOverview(changes, request.Changes)
+	newComment := s.createCommentWithChangesOverview(changes, request.Changes, skipped, tooLarge)
+	newComment.Body += configChangesSection(prompts.DefaultLanguages[s.cfg.Language].ListOfChangesHeaders.ConfigChangesHeader, configChanges)
+	if request.BaseMergeRequest != nil {
+		newComment.Body += stackedReviewNote(request.BaseMergeRequest)
+	}
+	newComment.Body += s.diffSourceNote(request)
+	overview := newComment.Body
 
 	// Wrap the overview content with markers
 	wrappedContent := s.wrapOverviewContent(newComment.Body)
@@ -47,25 +55,30 @@ func (s *Reviewer) createOrUpdateChangesOverview(ctx context.Context, request mo
 	// Check for existing changes overview comment
 	existingComment, err := s.findExistingChangesOverviewComment(ctx, request.ProjectID, request.MergeRequest.IID)
 	if err != nil {
-		return errm.Wrap(err, "failed to check for existing changes overview comment")
+		return "", errm.Wrap(err, "failed to check for existing changes overview comment")
 	}
 
 	if existingComment != nil {
 		// Update existing comment
 		err = s.provider.UpdateComment(ctx, request.ProjectID, request.MergeRequest.IID, existingComment.ID, wrappedContent)
 		if err != nil {
-			return errm.Wrap(err, "failed to update existing changes overview comment")
+			return "", errm.Wrap(err, "failed to update existing changes overview comment")
 		}
 	} else {
 		// Create new comment with wrapped content
 		newComment.Body = wrappedContent
 		err = s.provider.CreateComment(ctx, request.ProjectID, request.MergeRequest.IID, newComment)
 		if err != nil {
-			return errm.Wrap(err, "failed to create comment")
+			return "", errm.Wrap(err, "failed to create comment")
 		}
 	}
 
-	return nil
+	return overview, nil
+}
+
+// stackedReviewNote tells that changes of the base merge request are not a part of the review
+func stackedReviewNote(base *model.MergeRequest) string {
+	return fmt.Sprintf("\n\n> ℹ️ Stacked on #%d (%s): its changes are excluded from this review.\n", base.IID, base.Title)
 }
 
 // wrapOverviewContent wraps the overview content with markers
@@ -90,7 +103,7 @@ func (s *Reviewer) findExistingChangesOverviewComment(ctx context.Context, proje
 	}
 
 	for _, comment := range comments {
-		if s.isChangesOverviewComment(comment.Body) {
+		if s.isChangesOverviewComment(comment.Body) && s.isBotComment(comment) {
 			return comment, nil
 		}
 	}
@@ -103,7 +116,7 @@ func (s *Reviewer) isChangesOverviewComment(body string) bool {
 	return strings.Contains(body, startMarkerOverview) && strings.Contains(body, endMarkerOverview)
 }
 
-func (s *Reviewer) createCommentWithChangesOverview(files []model.FileChangeInfo, changes []*model.FileDiff) *model.Comment {
+func (s *Reviewer) createCommentWithChangesOverview(files []model.FileChangeInfo, changes []*model.FileDiff, skipped, tooLarge []string) *model.Comment {
 	reviewHeaders := prompts.DefaultLanguages[s.cfg.Language].ListOfChangesHeaders
 
 	slices.SortFunc(files, func(a, b model.FileChangeInfo) int {
@@ -138,6 +151,26 @@ func (s *Reviewer) createCommentWithChangesOverview(files []model.FileChangeInfo
 		comment.WriteString("|\n")
 	}
 
+	writePaths := func(header string, paths []string) {
+		if len(paths) == 0 {
+			return
+		}
+		comment.WriteString("\n**")
+		comment.WriteString(header)
+		comment.WriteString("**: ")
+		for i, path := range paths {
+			if i > 0 {
+				comment.WriteString(", ")
+			}
+			comment.WriteString("`")
+			comment.WriteString(path)
+			comment.WriteString("`")
+		}
+		comment.WriteString("\n")
+	}
+	writePaths(reviewHeaders.SkippedHeader, skipped)
+	writePaths(reviewHeaders.TooLargeHeader, tooLarge)
+
 	body := comment.String()
 
 	return &model.Comment{
@@ -0,0 +1,36 @@
+package reviewer
+
+import (
+	"slices"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// inOwnershipScope checks if findings of the file are posted by this instance, all files are in the scope if it is empty
+func (s *Reviewer) inOwnershipScope(filePath string) bool {
+	return len(s.cfg.OwnershipScope) == 0 || matchesPath(filePath, s.cfg.OwnershipScope)
+}
+
+// ownedFiles returns files of the ownership scope for the code review, files out of it are left to other instances:
+// they are not reviewed one by one, but they are still a part of the description, the overview and the context
+func (s *Reviewer) ownedFiles(files []*model.FileDiff) []*model.FileDiff {
+	if len(s.cfg.OwnershipScope) == 0 {
+		return files
+	}
+	return slices.DeleteFunc(slices.Clone(files), func(file *model.FileDiff) bool { return !s.inOwnershipScope(file.NewPath) })
+}
+
+// applyOwnershipScope returns files of the merge request in the ownership scope and records the other ones in the result
+func (s *Reviewer) applyOwnershipScope(bundle *reviewBundle) []*model.FileDiff {
+	owned := s.ownedFiles(bundle.filesToReview)
+	if len(owned) == len(bundle.filesToReview) {
+		return owned
+	}
+	for _, file := range bundle.filesToReview {
+		if !s.inOwnershipScope(file.NewPath) {
+			bundle.result.OutOfScopeFiles = append(bundle.result.OutOfScopeFiles, file.NewPath)
+		}
+	}
+	bundle.log.Info("files out of the ownership scope are not reviewed", "files", len(bundle.result.OutOfScopeFiles), "owned", len(owned))
+	return owned
+}
@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+var _ interfaces.FindingProcessor = (*CommandInjection)(nil)
+
+// CommandInjection flags shell commands built from non-literal values in added lines,
+// e.g. exec.Command("sh", "-c", "ls "+dir) or subprocess.run(f"ls {path}", shell=True)
+type CommandInjection struct {
+	scanner *analyze.SecurityScanner
+}
+
+// NewCommandInjection creates a processor for command injections found by the scanner
+func NewCommandInjection(scanner *analyze.SecurityScanner) *CommandInjection {
+	return &CommandInjection{scanner: scanner}
+}
+
+// Process appends a critical security finding for every added line that runs a shell command built from non-literal values
+func (p *CommandInjection) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted {
+		return findings, nil
+	}
+
+	for _, injection := range p.scanner.ScanDiff(fileDiff.NewPath, fileDiff.Diff) {
+		if injection.Type != analyze.SecurityFindingCommandInjection {
+			continue
+		}
+		findings = append(findings, &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        injection.Line,
+			IssueType:   model.IssueTypeSecurity,
+			Confidence:  model.ConfidenceHigh,
+			Priority:    model.ReviewPriorityCritical,
+			Title:       "Shell command is built from non-literal values",
+			Description: fmt.Sprintf("`%s`: %s.", injection.Code, injection.Description),
+			Suggestion:  "Validate values that come from users even without a shell, an allowlist of values is safer than escaping.",
+		})
+	}
+
+	return findings, nil
+}
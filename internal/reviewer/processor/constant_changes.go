@@ -0,0 +1,116 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*ConstantChanges)(nil)
+
+// ConstantChanges flags exported Go constants whose values changed, values shifted by a change of an iota
+// sequence are reported in one finding on the line that caused the shift
+type ConstantChanges struct {
+	provider interfaces.CodeProvider
+	log      logze.Logger
+}
+
+// NewConstantChanges creates a processor for changed values of exported constants
+func NewConstantChanges(provider interfaces.CodeProvider) *ConstantChanges {
+	return &ConstantChanges{
+		provider: provider,
+		log:      logze.With("component", "constant-changes-processor"),
+	}
+}
+
+// Process compares exported constants of the target branch and the head versions of the file
+func (p *ConstantChanges) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsNew || fileDiff.IsDeleted || !strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") || strings.HasSuffix(fileDiff.NewPath, "_test.go") {
+		return findings, nil
+	}
+
+	before, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.OldPath, request.MergeRequest.TargetBranch)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get old file content", "file", fileDiff.OldPath, "error", err)
+		return findings, nil
+	}
+	after, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+
+	changes, err := analyze.FindGoConstantChanges(before, after)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to find constant changes", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+
+	// Shifted values are grouped by the line that caused the shift, changes are sorted by line
+	var (
+		shifted     []analyze.ConstantChange
+		shiftedLine int
+	)
+	flushShifted := func() {
+		if len(shifted) > 0 {
+			findings = append(findings, shiftedConstantsFinding(fileDiff.NewPath, shiftedLine, shifted))
+		}
+		shifted = nil
+	}
+
+	for _, change := range changes {
+		if !change.Shifted {
+			findings = append(findings, &model.ReviewAIComment{
+				FilePath:    fileDiff.NewPath,
+				Line:        change.Line,
+				IssueType:   model.IssueTypeBug,
+				Confidence:  model.ConfidenceHigh,
+				Priority:    model.ReviewPriorityMedium,
+				Title:       fmt.Sprintf("Value of exported constant `%s` changed: %s → %s", change.Name, change.OldValue, change.NewValue),
+				Description: fmt.Sprintf("`%s` is part of the package API, code using it and values stored or sent to other services with the old value %s will silently behave differently.", change.Name, change.OldValue),
+				Suggestion:  "Make sure all users of the constant expect the new value, or add a new constant and deprecate the old one.",
+			})
+			continue
+		}
+
+		if change.CauseLine != shiftedLine {
+			flushShifted()
+			shiftedLine = change.CauseLine
+		}
+		shifted = append(shifted, change)
+	}
+	flushShifted()
+
+	return findings, nil
+}
+
+// shiftedConstantsFinding returns a finding for constants of an iota sequence whose values moved
+func shiftedConstantsFinding(filePath string, line int, changes []analyze.ConstantChange) *model.ReviewAIComment {
+	values := make([]string, 0, len(changes))
+	for _, change := range changes {
+		values = append(values, fmt.Sprintf("`%s` %s → %s", change.Name, change.OldValue, change.NewValue))
+	}
+
+	enum := "the enum"
+	if changes[0].Type != "" {
+		enum = fmt.Sprintf("`%s`", changes[0].Type)
+	}
+
+	return &model.ReviewAIComment{
+		FilePath:   filePath,
+		Line:       line,
+		IssueType:  model.IssueTypeBug,
+		Confidence: model.ConfidenceVeryHigh,
+		Priority:   model.ReviewPriorityHigh,
+		Title:      fmt.Sprintf("Change of the iota sequence shifts values of %d constants of %s", len(changes), enum),
+		Description: fmt.Sprintf("Values of unchanged constants moved: %s. Values stored in databases, sent over the network or compiled "+
+			"into other services will be interpreted as different constants.", strings.Join(values, ", ")),
+		Suggestion: "Add new values at the end of the sequence or assign explicit values to constants whose values must not change.",
+	}
+}
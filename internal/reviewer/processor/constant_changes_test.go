@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/provider/local"
+)
+
+func TestConstantChangesProcess(t *testing.T) {
+	const before = "package p\n\ntype Status int\n\nconst (\n\tStatusNew Status = iota\n\tStatusDone\n\tStatusFailed\n)\n\nconst Limit = 10\n"
+
+	// finding is a part of the finding checked by tests
+	type finding struct {
+		Line     int
+		Priority model.ReviewPriority
+		Title    string
+	}
+	tests := []struct {
+		name string
+		diff string
+		want []finding
+	}{
+		{
+			name: "enum gains a middle value",
+			diff: "--- a/status.go\n+++ b/status.go\n@@ -6,3 +6,4 @@\n \tStatusNew Status = iota\n+\tStatusRunning\n \tStatusDone\n \tStatusFailed\n",
+			want: []finding{
+				{7, model.ReviewPriorityHigh, "Change of the iota sequence shifts values of 2 constants of `Status`"},
+			},
+		},
+		{
+			name: "changed value",
+			diff: "--- a/status.go\n+++ b/status.go\n@@ -11 +11 @@\n-const Limit = 10\n+const Limit = 20\n",
+			want: []finding{
+				{11, model.ReviewPriorityMedium, "Value of exported constant `Limit` changed: 10 → 20"},
+			},
+		},
+		{
+			name: "enum gains a last value",
+			diff: "--- a/status.go\n+++ b/status.go\n@@ -8,2 +8,3 @@\n \tStatusFailed\n+\tStatusCanceled\n )\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := model.ParseUnifiedDiff(tt.diff)
+			provider := local.New("", diffs, map[string]string{"status.go": before})
+			request := model.ReviewRequest{ProjectID: "local", MergeRequest: provider.MergeRequest(), Changes: diffs}
+
+			findings, err := NewConstantChanges(provider).Process(context.Background(), request, diffs[0], nil)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			got := make([]finding, 0, len(findings))
+			for _, f := range findings {
+				got = append(got, finding{f.Line, f.Priority, f.Title})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Process() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*DebugArtifacts)(nil)
+
+// Names of debug artifact checks
+const (
+	DebugCheckTodo         = "todo"
+	DebugCheckPanic        = "panic"
+	DebugCheckExit         = "exit"
+	DebugCheckPrint        = "debug_print"
+	DebugCheckSkippedTests = "skipped_tests"
+)
+
+// DebugCheckNames are names of all debug artifact checks
+var DebugCheckNames = []string{DebugCheckTodo, DebugCheckPanic, DebugCheckExit, DebugCheckPrint, DebugCheckSkippedTests}
+
+var (
+	todoRegex = regexp.MustCompile(`(?://|#|/\*|^\s*\*|--)\s*(TODO|FIXME)\b(\S*)`)
+
+	jsDebugPrintRegex  = regexp.MustCompile(`\bconsole\.(?:log|debug|trace)\s*\(|\bdebugger\b`)
+	jsSkippedTestRegex = regexp.MustCompile(`\b(?:xit|xdescribe|xtest)\s*\(|\b(?:it|describe|test)\.skip\s*\(`)
+	jsFocusedTestRegex = regexp.MustCompile(`\b(?:fit|fdescribe)\s*\(|\b(?:it|describe|test)\.only\s*\(`)
+
+	pyDebugPrintRegex  = regexp.MustCompile(`^\s*print\s*\(|\bbreakpoint\s*\(\)|\bpdb\.set_trace\s*\(`)
+	pySkippedTestRegex = regexp.MustCompile(`^\s*@(?:pytest\.mark\.skip|unittest\.skip)\b`)
+)
+
+// DebugArtifacts flags leftovers of debugging in added lines: TODO and FIXME comments, panics and exits
+// in Go library code, debug prints, skipped and focused tests
+type DebugArtifacts struct {
+	provider interfaces.CodeProvider
+	style    *analyze.ProjectStyleAnalyzer
+	skip     []string
+	log      logze.Logger
+}
+
+// NewDebugArtifacts creates a processor for debug artifacts, skip are names of disabled checks
+func NewDebugArtifacts(provider interfaces.CodeProvider, skip []string) *DebugArtifacts {
+	return &DebugArtifacts{
+		provider: provider,
+		style:    analyze.NewProjectStyleAnalyzer(provider),
+		skip:     skip,
+		log:      logze.With("component", "debug-artifacts-processor"),
+	}
+}
+
+// Process appends low and medium priority findings for debug artifacts in added lines, Go code is checked
+// by its syntax tree, so calls in comments and strings are ignored
+func (p *DebugArtifacts) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted {
+		return findings, nil
+	}
+	lines := analyze.FileAddedLines(fileDiff)
+	if len(lines) == 0 {
+		return findings, nil
+	}
+
+	if p.enabled(DebugCheckTodo) {
+		findings = append(findings, p.todoFindings(ctx, request, fileDiff.NewPath, lines)...)
+	}
+
+	switch ext := strings.ToLower(path.Ext(fileDiff.NewPath)); ext {
+	case ".go":
+		findings = append(findings, p.goFindings(ctx, request, fileDiff.NewPath, lines)...)
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+		findings = append(findings, p.scriptFindings(fileDiff.NewPath, lines, jsDebugPrintRegex, jsSkippedTestRegex, jsFocusedTestRegex)...)
+	case ".py":
+		findings = append(findings, p.scriptFindings(fileDiff.NewPath, lines, pyDebugPrintRegex, pySkippedTestRegex, nil)...)
+	}
+
+	return findings, nil
+}
+
+func (p *DebugArtifacts) enabled(check string) bool {
+	return !slices.Contains(p.skip, check)
+}
+
+// todoFindings flags added TODO and FIXME comments, markers of Go files are compared with the TODO style
+// of the package, so the suggestion names the form the project uses
+func (p *DebugArtifacts) todoFindings(ctx context.Context, request model.ReviewRequest, filePath string, lines []analyze.AddedLine) []*model.ReviewAIComment {
+	var (
+		findings  []*model.ReviewAIComment
+		todoStyle string
+		styleRead bool
+	)
+	for _, line := range lines {
+		match := todoRegex.FindStringSubmatch(line.Content)
+		if match == nil {
+			continue
+		}
+
+		// Package files are fetched only for files with markers
+		if !styleRead && strings.EqualFold(path.Ext(filePath), ".go") {
+			styleRead = true
+			if commenting, err := p.style.AnalyzeCommentingStyle(ctx, request, filePath); err == nil {
+				todoStyle = commenting.TODOStyle
+			} else {
+				model.ContextLogger(ctx, p.log).Debug("failed to analyze commenting style", "file", filePath, "error", err)
+			}
+		}
+
+		suggestion := "Resolve it before merging or track it in an issue and reference the issue in the comment."
+		if marker := match[1] + match[2]; todoStyle != "" && !strings.HasPrefix(marker, todoStyle) {
+			suggestion = fmt.Sprintf("Resolve it before merging or track it in an issue, the project marks such comments as `%s`.", todoStyle)
+		}
+		findings = append(findings, &model.ReviewAIComment{
+			FilePath:    filePath,
+			Line:        line.Number,
+			IssueType:   model.IssueTypeOther,
+			Confidence:  model.ConfidenceHigh,
+			Priority:    model.ReviewPriorityBacklog,
+			Title:       fmt.Sprintf("New %s comment", match[1]),
+			Description: "Unfinished work is added with the change, such comments are easily forgotten after merge.",
+			Suggestion:  suggestion,
+		})
+	}
+	return findings
+}
+
+// goFindings flags panics outside init and main, os.Exit outside main, fmt and builtin prints
+// in library code and skipped tests in added lines of the Go file
+func (p *DebugArtifacts) goFindings(ctx context.Context, request model.ReviewRequest, filePath string, lines []analyze.AddedLine) []*model.ReviewAIComment {
+	if !p.enabled(DebugCheckPanic) && !p.enabled(DebugCheckExit) && !p.enabled(DebugCheckPrint) && !p.enabled(DebugCheckSkippedTests) {
+		return nil
+	}
+
+	content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, filePath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", filePath, "error", err)
+		return nil
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, content, parser.SkipObjectResolution)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to parse go file", "file", filePath, "error", err)
+		return nil
+	}
+
+	added := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		added[line.Number] = true
+	}
+	isMain := file.Name.Name == "main"
+	isTest := strings.HasSuffix(filePath, "_test.go")
+
+	var findings []*model.ReviewAIComment
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		funcName := fn.Name.Name
+		if fn.Recv != nil {
+			funcName = "" // methods are never init or main
+		}
+
+		ast.Inspect(fn.Body, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			line := fset.Position(call.Pos()).Line
+			if !added[line] {
+				return true
+			}
+			if finding := p.goCallFinding(call, funcName, isMain, isTest); finding != nil {
+				finding.FilePath = filePath
+				finding.Line = line
+				findings = append(findings, finding)
+			}
+			return true
+		})
+	}
+	return findings
+}
+
+// goCallFinding returns a finding for the call if it is a debug artifact in its function, nil otherwise
+func (p *DebugArtifacts) goCallFinding(call *ast.CallExpr, funcName string, isMain, isTest bool) *model.ReviewAIComment {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		switch {
+		case fun.Name == "panic" && p.enabled(DebugCheckPanic) && !isTest && funcName != "init" && !(isMain && funcName == "main"):
+			return &model.ReviewAIComment{
+				IssueType:   model.IssueTypeBug,
+				Confidence:  model.ConfidenceMedium,
+				Priority:    model.ReviewPriorityMedium,
+				Title:       "Panic in library code",
+				Description: "A panic outside of initialization crashes the whole process, callers can't handle it as an error.",
+				Suggestion:  "Return an error instead, keep panics for programming errors that must never happen.",
+			}
+		case (fun.Name == "println" || fun.Name == "print") && p.enabled(DebugCheckPrint) && !isTest:
+			return debugPrintFinding(fun.Name)
+		}
+
+	case *ast.SelectorExpr:
+		pkg, _ := fun.X.(*ast.Ident)
+		name := fun.Sel.Name
+		switch {
+		case pkg != nil && pkg.Name == "os" && name == "Exit" && p.enabled(DebugCheckExit) &&
+			!(isMain && funcName == "main") && !(isTest && funcName == "TestMain"):
+			return &model.ReviewAIComment{
+				IssueType:   model.IssueTypeBug,
+				Confidence:  model.ConfidenceMedium,
+				Priority:    model.ReviewPriorityMedium,
+				Title:       "os.Exit outside of main",
+				Description: "os.Exit stops the process without running deferred calls, the code can't be reused or tested.",
+				Suggestion:  "Return an error and exit in main.",
+			}
+		case pkg != nil && pkg.Name == "fmt" && (name == "Print" || name == "Println" || name == "Printf") &&
+			p.enabled(DebugCheckPrint) && !isMain && !isTest:
+			return debugPrintFinding("fmt." + name)
+		case isTest && (name == "Skip" || name == "Skipf" || name == "SkipNow") && p.enabled(DebugCheckSkippedTests):
+			return skippedTestFinding("Test is skipped")
+		}
+	}
+	return nil
+}
+
+// scriptFindings flags debug prints, skipped and focused tests in added lines by the patterns of the language,
+// commented out lines are ignored; nil patterns are not checked
+func (p *DebugArtifacts) scriptFindings(filePath string, lines []analyze.AddedLine, printRegex, skippedRegex, focusedRegex *regexp.Regexp) []*model.ReviewAIComment {
+	isTest := isTestFile(filePath)
+
+	var findings []*model.ReviewAIComment
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line.Content)
+		if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "*") {
+			continue
+		}
+
+		var finding *model.ReviewAIComment
+		switch {
+		case p.enabled(DebugCheckPrint) && !isTest && printRegex.MatchString(line.Content):
+			finding = debugPrintFinding(strings.TrimSpace(printRegex.FindString(line.Content)))
+		case p.enabled(DebugCheckSkippedTests) && focusedRegex != nil && focusedRegex.MatchString(line.Content):
+			finding = skippedTestFinding("Test is focused")
+			finding.Priority = model.ReviewPriorityMedium
+			finding.Description = "Focused tests make the runner skip all other tests of the suite, failures in them go unnoticed."
+			finding.Suggestion = "Remove the focus before merging."
+		case p.enabled(DebugCheckSkippedTests) && skippedRegex.MatchString(line.Content):
+			finding = skippedTestFinding("Test is skipped")
+		}
+		if finding != nil {
+			finding.FilePath = filePath
+			finding.Line = line.Number
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func debugPrintFinding(call string) *model.ReviewAIComment {
+	return &model.ReviewAIComment{
+		IssueType:   model.IssueTypeOther,
+		Confidence:  model.ConfidenceMedium,
+		Priority:    model.ReviewPriorityBacklog,
+		Title:       fmt.Sprintf("Debug output: `%s`", strings.TrimRight(call, "( ")),
+		Description: "Output left after debugging pollutes logs and stdout of the application.",
+		Suggestion:  "Remove it or use the logger of the project.",
+	}
+}
+
+func skippedTestFinding(title string) *model.ReviewAIComment {
+	return &model.ReviewAIComment{
+		IssueType:   model.IssueTypeOther,
+		Confidence:  model.ConfidenceMedium,
+		Priority:    model.ReviewPriorityBacklog,
+		Title:       title,
+		Description: "Skipped tests don't check the code anymore, regressions in it go unnoticed.",
+		Suggestion:  "Fix the test instead of skipping it or explain the reason and track it in an issue.",
+	}
+}
+
+// isTestFile checks if the file is a test by common naming conventions of JavaScript, TypeScript and Python
+func isTestFile(filePath string) bool {
+	name := strings.ToLower(path.Base(filePath))
+	return strings.Contains(name, ".test.") || strings.Contains(name, ".spec.") ||
+		strings.HasPrefix(name, "test_") || strings.HasSuffix(name, "_test.py") ||
+		strings.Contains(filePath, "__tests__/")
+}
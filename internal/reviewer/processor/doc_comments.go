@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*DocComments)(nil)
+
+// DocComments flags exported Go symbols added without a doc comment or with a doc comment
+// that doesn't follow the godoc convention, pre-existing symbols are not flagged
+type DocComments struct {
+	provider interfaces.CodeProvider
+	style    *analyze.ProjectStyleAnalyzer
+	log      logze.Logger
+}
+
+// NewDocComments creates a processor for doc comments of exported symbols,
+// the godoc form is required only if the package follows it
+func NewDocComments(provider interfaces.CodeProvider) *DocComments {
+	return &DocComments{
+		provider: provider,
+		style:    analyze.NewProjectStyleAnalyzer(provider),
+		log:      logze.With("component", "doc-comments-processor"),
+	}
+}
+
+// Process appends a low priority finding for every added exported symbol without a proper doc comment
+func (p *DocComments) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || !strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") || strings.HasSuffix(fileDiff.NewPath, "_test.go") {
+		return findings, nil
+	}
+
+	after, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+	var before string
+	if !fileDiff.IsNew {
+		oldPath := fileDiff.OldPath
+		if oldPath == "" {
+			oldPath = fileDiff.NewPath
+		}
+		// Without the previous version every changed symbol would look like an added one
+		if before, err = analyze.FetchFileContent(ctx, p.provider, request.ProjectID, oldPath, request.MergeRequest.TargetBranch); err != nil {
+			model.ContextLogger(ctx, p.log).Debug("failed to get previous file content", "file", oldPath, "error", err)
+			return findings, nil
+		}
+	}
+
+	// The strictest style is checked first, package files are fetched only if there is something to report
+	missing, err := analyze.FindMissingDocComments(before, after, fileDiff.Diff, fileDiff.IsNew, analyze.DocCommentStyleGodoc)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to parse go file", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+	if len(missing) == 0 {
+		return findings, nil
+	}
+
+	style := analyze.DocCommentStyleGodoc
+	if commenting, err := p.style.AnalyzeCommentingStyle(ctx, request, fileDiff.NewPath); err == nil {
+		style = commenting.DocCommentStyle
+	} else {
+		model.ContextLogger(ctx, p.log).Debug("failed to analyze commenting style", "file", fileDiff.NewPath, "error", err)
+	}
+	if style == analyze.DocCommentStyleNone {
+		return findings, nil
+	}
+
+	for _, item := range missing {
+		if item.Misnamed && style == analyze.DocCommentStyleStandard {
+			continue
+		}
+
+		entity := item.Entity
+		finding := &model.ReviewAIComment{
+			FilePath:   fileDiff.NewPath,
+			Line:       entity.StartLine,
+			IssueType:  model.IssueTypeOther,
+			Confidence: model.ConfidenceVeryHigh,
+			Priority:   model.ReviewPriorityBacklog,
+			Suggestion: fmt.Sprintf("Add a comment right before the declaration that starts with the name: `// %s ...`.", entity.Name),
+		}
+		if item.Misnamed {
+			finding.Title = fmt.Sprintf("Doc comment of exported %s `%s` doesn't start with its name", entity.Type, entity.Name)
+			finding.Description = "By the godoc convention a doc comment starts with the name of the symbol, so it reads well in the package documentation and search."
+		} else {
+			finding.Title = fmt.Sprintf("Exported %s `%s` has no doc comment", entity.Type, entity.Name)
+			finding.Description = "Exported symbols are a part of the package API and should be documented for its users."
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,334 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*DockerfileChecks)(nil)
+
+// Names of Dockerfile checks
+const (
+	DockerfileCheckLatestTag         = "latest_tag"
+	DockerfileCheckRootUser          = "root_user"
+	DockerfileCheckEnvSecrets        = "env_secrets"
+	DockerfileCheckInstallRecommends = "no_install_recommends"
+)
+
+// DockerfileCheckNames are names of all Dockerfile checks
+var DockerfileCheckNames = []string{
+	DockerfileCheckLatestTag, DockerfileCheckRootUser, DockerfileCheckEnvSecrets, DockerfileCheckInstallRecommends,
+}
+
+var (
+	dockerSecretNameRegex    = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api_?key|access_?key|private_?key|credential)`)
+	dockerNotSecretNameRegex = regexp.MustCompile(`(?i)(_file|_path|_dir|_url)$`)
+	aptInstallRegex          = regexp.MustCompile(`\bapt(?:-get)?\s+(?:-\S+\s+)*install\b`)
+)
+
+// dockerInstruction is an instruction of a Dockerfile with its continuation lines
+type dockerInstruction struct {
+	Keyword string
+	Args    string
+	Lines   []analyze.AddedLine
+}
+
+// DockerfileChecks flags added lines of Dockerfiles with base images without a pinned tag, secrets in ENV and ARG,
+// apt installs without --no-install-recommends and the final stage running as root
+type DockerfileChecks struct {
+	provider interfaces.CodeProvider
+	skip     []string
+	log      logze.Logger
+}
+
+// NewDockerfileChecks creates a processor for Dockerfiles, skip are names of disabled checks; provider may be nil,
+// then the root user check is not performed because it needs the whole file
+func NewDockerfileChecks(provider interfaces.CodeProvider, skip []string) *DockerfileChecks {
+	return &DockerfileChecks{
+		provider: provider,
+		skip:     skip,
+		log:      logze.With("component", "dockerfile-processor"),
+	}
+}
+
+// Process appends findings for instructions with added lines, files are detected by name like Dockerfile,
+// Dockerfile.dev or app.dockerfile
+func (p *DockerfileChecks) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || !isDockerfile(fileDiff.NewPath) {
+		return findings, nil
+	}
+
+	addedLines := analyze.FileAddedLines(fileDiff)
+	if len(addedLines) == 0 {
+		return findings, nil
+	}
+	added := make(map[int]struct{}, len(addedLines))
+	for _, line := range addedLines {
+		added[line.Number] = struct{}{}
+	}
+	isAdded := func(inst dockerInstruction) bool {
+		return slices.ContainsFunc(inst.Lines, func(line analyze.AddedLine) bool {
+			_, ok := added[line.Number]
+			return ok
+		})
+	}
+
+	lines, whole := p.fileLines(ctx, request, fileDiff, addedLines)
+	stages := make(map[string]struct{})
+
+	var (
+		finalFrom       dockerInstruction
+		finalUser       *dockerInstruction
+		finalStageAdded bool
+	)
+	for _, inst := range parseDockerfile(lines) {
+		switch inst.Keyword {
+		case "FROM":
+			image, stage := parseDockerFrom(inst.Args)
+			if isAdded(inst) && !p.skipped(DockerfileCheckLatestTag) && isUnpinnedImage(image, stages) {
+				findings = append(findings, &model.ReviewAIComment{
+					FilePath:    fileDiff.NewPath,
+					Line:        inst.Lines[0].Number,
+					IssueType:   model.IssueTypeBug,
+					Confidence:  model.ConfidenceVeryHigh,
+					Priority:    model.ReviewPriorityMedium,
+					Title:       fmt.Sprintf("Base image `%s` is not pinned to a version", image),
+					Description: "Images without a tag or with `latest` change silently between builds, builds become unreproducible and may break.",
+					Suggestion:  "Pin the image to a specific version tag or a digest.",
+				})
+			}
+			if stage != "" {
+				stages[stage] = struct{}{}
+			}
+			finalFrom, finalUser, finalStageAdded = inst, nil, false
+
+		case "USER":
+			finalUser = &inst
+
+		case "ENV", "ARG":
+			if !isAdded(inst) || p.skipped(DockerfileCheckEnvSecrets) {
+				break
+			}
+			for _, name := range dockerSecretVariables(inst.Keyword, inst.Args) {
+				findings = append(findings, &model.ReviewAIComment{
+					FilePath:    fileDiff.NewPath,
+					Line:        inst.Lines[0].Number,
+					IssueType:   model.IssueTypeSecurity,
+					Confidence:  model.ConfidenceHigh,
+					Priority:    model.ReviewPriorityHigh,
+					Title:       fmt.Sprintf("Secret `%s` is set in %s", name, inst.Keyword),
+					Description: fmt.Sprintf("Values of %s are stored in the image and visible in its history and `docker inspect`, anyone with the image can read the secret.", inst.Keyword),
+					Suggestion:  "Pass the secret at runtime or use build secrets with `RUN --mount=type=secret`.",
+				})
+			}
+
+		case "RUN":
+			if !isAdded(inst) || p.skipped(DockerfileCheckInstallRecommends) {
+				break
+			}
+			if aptInstallRegex.MatchString(inst.Args) && !strings.Contains(inst.Args, "--no-install-recommends") {
+				findings = append(findings, &model.ReviewAIComment{
+					FilePath:    fileDiff.NewPath,
+					Line:        dockerInstructionLine(inst, "install"),
+					IssueType:   model.IssueTypePerformance,
+					Confidence:  model.ConfidenceVeryHigh,
+					Priority:    model.ReviewPriorityBacklog,
+					Title:       "apt install without `--no-install-recommends`",
+					Description: "Recommended packages are installed too, they increase the image size and its attack surface.",
+					Suggestion:  "Add `--no-install-recommends` and install the needed packages explicitly.",
+				})
+			}
+		}
+		if isAdded(inst) {
+			finalStageAdded = true
+		}
+	}
+
+	if !whole || finalFrom.Keyword == "" || p.skipped(DockerfileCheckRootUser) {
+		return findings, nil
+	}
+
+	// Only the final stage matters because it is the image that runs
+	switch {
+	case finalUser == nil && finalStageAdded:
+		findings = append(findings, &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        finalFrom.Lines[0].Number,
+			IssueType:   model.IssueTypeSecurity,
+			Confidence:  model.ConfidenceMedium,
+			Priority:    model.ReviewPriorityMedium,
+			Title:       "Container runs as root",
+			Description: "The final stage has no `USER` instruction, so the container runs as root unless the base image sets another user.",
+			Suggestion:  "Create an unprivileged user and switch to it with `USER` at the end of the final stage.",
+		})
+
+	case finalUser != nil && isAdded(*finalUser) && isRootUser(finalUser.Args):
+		findings = append(findings, &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        finalUser.Lines[0].Number,
+			IssueType:   model.IssueTypeSecurity,
+			Confidence:  model.ConfidenceVeryHigh,
+			Priority:    model.ReviewPriorityMedium,
+			Title:       "Container runs as root",
+			Description: "The last `USER` instruction of the final stage is root, a compromised process gets root privileges in the container.",
+			Suggestion:  "Switch to an unprivileged user after the steps that need root.",
+		})
+	}
+
+	return findings, nil
+}
+
+// fileLines returns lines of the new file, added lines are returned with false if the content is unavailable
+func (p *DockerfileChecks) fileLines(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, addedLines []analyze.AddedLine) ([]analyze.AddedLine, bool) {
+	if p.provider == nil {
+		return addedLines, false
+	}
+
+	content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return addedLines, false
+	}
+
+	split := strings.Split(content, "\n")
+	lines := make([]analyze.AddedLine, 0, len(split))
+	for i, line := range split {
+		lines = append(lines, analyze.AddedLine{Number: i + 1, Content: line})
+	}
+	return lines, true
+}
+
+// skipped checks if the check is disabled
+func (p *DockerfileChecks) skipped(check string) bool {
+	return slices.Contains(p.skip, check)
+}
+
+// isDockerfile checks the file name: Dockerfile with any suffix or the .dockerfile extension
+func isDockerfile(filePath string) bool {
+	name := strings.ToLower(path.Base(filePath))
+	return strings.HasPrefix(name, "dockerfile") || strings.HasSuffix(name, ".dockerfile")
+}
+
+// parseDockerfile joins continuation lines into instructions, comments and empty lines are skipped
+func parseDockerfile(lines []analyze.AddedLine) []dockerInstruction {
+	var (
+		instructions []dockerInstruction
+		current      *dockerInstruction
+	)
+	for _, line := range lines {
+		text := strings.TrimSpace(line.Content)
+		if strings.HasPrefix(text, "#") || (current == nil && text == "") {
+			continue
+		}
+
+		if current == nil {
+			keyword, args := text, ""
+			if i := strings.IndexFunc(text, unicode.IsSpace); i > 0 {
+				keyword, args = text[:i], text[i:]
+			}
+			current = &dockerInstruction{Keyword: strings.ToUpper(keyword)}
+			text = strings.TrimSpace(args)
+		}
+
+		current.Lines = append(current.Lines, line)
+		continued := strings.HasSuffix(text, "\\")
+		current.Args = strings.TrimSpace(current.Args + " " + strings.TrimSuffix(text, "\\"))
+		if !continued {
+			instructions = append(instructions, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		instructions = append(instructions, *current)
+	}
+
+	return instructions
+}
+
+// parseDockerFrom returns the image of FROM arguments and the lowercase name of the stage
+func parseDockerFrom(args string) (string, string) {
+	var image, stage string
+	fields := strings.Fields(args)
+	for i, field := range fields {
+		switch {
+		case strings.HasPrefix(field, "--"):
+			continue // flags like --platform
+		case image == "":
+			image = field
+		case strings.EqualFold(field, "as") && i+1 < len(fields):
+			stage = strings.ToLower(fields[i+1])
+		}
+	}
+	return image, stage
+}
+
+// isUnpinnedImage checks if the image has no tag or the latest one, images from build arguments,
+// earlier stages, scratch and digests are considered pinned
+func isUnpinnedImage(image string, stages map[string]struct{}) bool {
+	lower := strings.ToLower(image)
+	if _, ok := stages[lower]; ok || lower == "" || lower == "scratch" || strings.Contains(image, "$") || strings.Contains(image, "@") {
+		return false
+	}
+	_, tag, hasTag := strings.Cut(path.Base(image), ":")
+	return !hasTag || tag == "latest"
+}
+
+// dockerSecretVariables returns names of ENV variables and ARG defaults that look like secrets and have a value,
+// ARG defaults referencing other variables are skipped because the value comes from the build
+func dockerSecretVariables(keyword, args string) []string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	type variable struct{ name, value string }
+	var variables []variable
+	if keyword == "ENV" && !strings.Contains(fields[0], "=") {
+		// Legacy "ENV NAME value" form
+		variables = append(variables, variable{name: fields[0], value: strings.Join(fields[1:], " ")})
+	} else {
+		for _, field := range fields {
+			name, value, _ := strings.Cut(field, "=")
+			variables = append(variables, variable{name: name, value: value})
+		}
+	}
+
+	var names []string
+	for _, v := range variables {
+		value := strings.Trim(v.value, `"'`)
+		if value == "" || !dockerSecretNameRegex.MatchString(v.name) || dockerNotSecretNameRegex.MatchString(v.name) {
+			continue
+		}
+		if keyword == "ARG" && strings.HasPrefix(value, "$") {
+			continue
+		}
+		names = append(names, v.name)
+	}
+	return names
+}
+
+// isRootUser checks USER arguments: root or uid 0 with an optional group
+func isRootUser(args string) bool {
+	user, _, _ := strings.Cut(strings.TrimSpace(args), ":")
+	return user == "root" || user == "0"
+}
+
+// dockerInstructionLine returns the number of the first line of the instruction containing the text
+func dockerInstructionLine(inst dockerInstruction, text string) int {
+	for _, line := range inst.Lines {
+		if strings.Contains(line.Content, text) {
+			return line.Number
+		}
+	}
+	return inst.Lines[0].Number
+}
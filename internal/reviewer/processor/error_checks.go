@@ -0,0 +1,320 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*ErrorChecks)(nil)
+
+// DefaultIgnoredErrorCalls are calls whose errors are usually safe to discard
+var DefaultIgnoredErrorCalls = []string{
+	"fmt.Print", "fmt.Printf", "fmt.Println",
+	"fmt.Fprint", "fmt.Fprintf", "fmt.Fprintln",
+}
+
+// errorCallNames are names of functions and methods that return error in the standard library and popular packages,
+// they are used when the type of the call cannot be resolved from the file alone
+var errorCallNames = []string{
+	"Close", "Flush", "Sync", "Shutdown", "Commit",
+	"Remove", "RemoveAll", "Rename", "Mkdir", "MkdirAll", "MkdirTemp", "WriteFile", "Chmod", "Chown", "Chdir",
+	"Setenv", "Unsetenv", "Symlink", "Link", "Truncate",
+	"Unmarshal", "Marshal", "Encode", "Decode", "Exec", "ExecContext", "Ping", "PingContext",
+	"Atoi", "ParseInt", "ParseUint", "ParseFloat", "ParseBool", "ParseDuration",
+	"ReadFile", "ReadAll", "Open", "OpenFile", "Create", "Stat", "Lstat", "Getwd", "Hostname",
+	"Listen", "Dial", "DialContext", "NewRequest", "NewRequestWithContext",
+}
+
+// nonErrorPairCalls are functions with the second result that is not an error, discarding it is not an issue
+var nonErrorPairCalls = []string{
+	"Cut", "CutPrefix", "CutSuffix", "Load", "LoadOrStore", "LoadAndDelete", "Swap",
+	"LookupEnv", "Lookup", "DecodeRune", "DecodeRuneInString", "DecodeLastRune", "DecodeLastRuneInString",
+	"Caller", "FromContext", "Deadline", "SetString", "Float64", "Int64", "Uint64", "Pull",
+	"WithCancel", "WithTimeout", "WithDeadline",
+}
+
+var (
+	discardedErrorRegex = regexp.MustCompile(`^\s*([\w.]+(?:\s*,\s*[\w.]+)*)\s*,\s*_\s*:?=\s*([\w.]+)\(`)
+	bareCallRegex       = regexp.MustCompile(`^\s*(defer\s+)?([\w.]+)\(.*\)\s*(?://.*)?$`)
+)
+
+// ErrorChecks flags added lines of Go files that discard errors: calls with ignored error result
+// and assignments of the error to the blank identifier
+type ErrorChecks struct {
+	provider           interfaces.CodeProvider
+	ignore             []string
+	checkDeferredClose bool
+	log                logze.Logger
+}
+
+// NewErrorChecks creates a processor for discarded errors, ignore are calls whose errors may be discarded
+// in "pkg.Func" format or method names; deferred Close calls are skipped if checkDeferredClose is false
+func NewErrorChecks(provider interfaces.CodeProvider, ignore []string, checkDeferredClose bool) *ErrorChecks {
+	return &ErrorChecks{
+		provider:           provider,
+		ignore:             ignore,
+		checkDeferredClose: checkDeferredClose,
+		log:                logze.With("component", "error-checks-processor"),
+	}
+}
+
+// discardedError is a call with discarded error on the line of the new file
+type discardedError struct {
+	Line   int
+	Callee string
+	Blank  bool // error is assigned to the blank identifier
+	Typed  bool // call is resolved with type information
+}
+
+// Process appends a finding for every added line that discards an error
+func (p *ErrorChecks) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || !strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") {
+		return findings, nil
+	}
+
+	lines := analyze.FileAddedLines(fileDiff)
+	added := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		added[line.Number] = true
+	}
+
+	discarded, ok := p.findInContent(ctx, request, fileDiff)
+	if !ok {
+		discarded = p.findInLines(lines)
+	}
+
+	for _, item := range discarded {
+		if !added[item.Line] {
+			continue
+		}
+
+		finding := &model.ReviewAIComment{
+			FilePath:   fileDiff.NewPath,
+			Line:       item.Line,
+			IssueType:  model.IssueTypeBug,
+			Confidence: model.ConfidenceHigh,
+			Priority:   model.ReviewPriorityHigh,
+			Title:      fmt.Sprintf("Unchecked error from `%s`", item.Callee),
+			Suggestion: "Handle the error or return it to the caller; if it is safe to ignore, explain why in a comment.",
+		}
+		if item.Typed {
+			finding.Confidence = model.ConfidenceVeryHigh
+		}
+		if item.Blank {
+			finding.Description = fmt.Sprintf("Error returned by `%s` is discarded with the blank identifier, failures will go unnoticed.", item.Callee)
+		} else {
+			finding.Description = fmt.Sprintf("`%s` returns an error that is not checked, failures will go unnoticed.", item.Callee)
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
+
+// findInContent parses the new file content and type-checks it, calls to other packages cannot be resolved
+// without the whole build, so they fall back to name heuristics; false is returned if the content is unavailable
+func (p *ErrorChecks) findInContent(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff) ([]discardedError, bool) {
+	if p.provider == nil {
+		return nil, false
+	}
+
+	content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return nil, false
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileDiff.NewPath, content, 0)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to parse go file", "file", fileDiff.NewPath, "error", err)
+		return nil, false
+	}
+
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	conf := types.Config{
+		Importer: emptyImporter{},
+		Error:    func(error) {}, // unresolved imports are expected, checking continues to collect local types
+	}
+	_, _ = conf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+
+	var discarded []discardedError
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch stmt := node.(type) {
+		case *ast.ExprStmt:
+			if call, ok := stmt.X.(*ast.CallExpr); ok {
+				discarded = p.appendBareCall(discarded, fset, info, call, false)
+			}
+
+		case *ast.DeferStmt:
+			discarded = p.appendBareCall(discarded, fset, info, stmt.Call, true)
+
+		case *ast.AssignStmt:
+			if len(stmt.Rhs) != 1 || len(stmt.Lhs) < 2 {
+				return true
+			}
+			call, ok := stmt.Rhs[0].(*ast.CallExpr)
+			if !ok || !isBlank(stmt.Lhs[len(stmt.Lhs)-1]) {
+				return true
+			}
+			if !slices.ContainsFunc(stmt.Lhs, isNotBlank) {
+				return true // all results are discarded deliberately
+			}
+			callee, method := calleeName(call.Fun)
+			if p.isIgnored(callee, method) {
+				return true
+			}
+			returnsError, typed := callReturnsError(info, call)
+			if !typed {
+				returnsError = !slices.Contains(nonErrorPairCalls, method)
+			}
+			if returnsError {
+				discarded = append(discarded, discardedError{
+					Line:   fset.Position(stmt.Pos()).Line,
+					Callee: callee,
+					Blank:  true,
+					Typed:  typed,
+				})
+			}
+		}
+		return true
+	})
+
+	return discarded, true
+}
+
+func (p *ErrorChecks) appendBareCall(discarded []discardedError, fset *token.FileSet, info *types.Info, call *ast.CallExpr, deferred bool) []discardedError {
+	callee, method := calleeName(call.Fun)
+	if callee == "" || p.isIgnored(callee, method) || (deferred && method == "Close" && !p.checkDeferredClose) {
+		return discarded
+	}
+
+	returnsError, typed := callReturnsError(info, call)
+	if !typed {
+		returnsError = slices.Contains(errorCallNames, method)
+	}
+	if !returnsError {
+		return discarded
+	}
+
+	return append(discarded, discardedError{
+		Line:   fset.Position(call.Pos()).Line,
+		Callee: callee,
+		Typed:  typed,
+	})
+}
+
+// findInLines matches added lines when the file cannot be parsed: blank error assignments and bare calls by name
+func (p *ErrorChecks) findInLines(lines []analyze.AddedLine) []discardedError {
+	var discarded []discardedError
+	for _, line := range lines {
+		if match := discardedErrorRegex.FindStringSubmatch(line.Content); match != nil {
+			callee, method := splitCallee(match[2])
+			deliberate := strings.Trim(match[1], "_, \t") == ""
+			if !deliberate && !p.isIgnored(callee, method) && !slices.Contains(nonErrorPairCalls, method) {
+				discarded = append(discarded, discardedError{Line: line.Number, Callee: callee, Blank: true})
+			}
+			continue
+		}
+
+		if match := bareCallRegex.FindStringSubmatch(line.Content); match != nil {
+			callee, method := splitCallee(match[2])
+			deferred := match[1] != ""
+			if p.isIgnored(callee, method) || (deferred && method == "Close" && !p.checkDeferredClose) {
+				continue
+			}
+			if slices.Contains(errorCallNames, method) {
+				discarded = append(discarded, discardedError{Line: line.Number, Callee: callee})
+			}
+		}
+	}
+	return discarded
+}
+
+// isIgnored checks the call against ignore rules: "pkg.Func" matches the full callee, a name matches the method
+func (p *ErrorChecks) isIgnored(callee, method string) bool {
+	for _, rule := range p.ignore {
+		if rule == callee || (!strings.Contains(rule, ".") && rule == method) {
+			return true
+		}
+	}
+	return false
+}
+
+// callReturnsError checks if the last result of the call is error, false second value means the type is unknown
+func callReturnsError(info *types.Info, call *ast.CallExpr) (bool, bool) {
+	tv, ok := info.Types[call]
+	if !ok || tv.Type == nil {
+		return false, false
+	}
+
+	result := tv.Type
+	if tuple, ok := result.(*types.Tuple); ok {
+		if tuple.Len() == 0 {
+			return false, true
+		}
+		result = tuple.At(tuple.Len() - 1).Type()
+	}
+	if basic, ok := result.(*types.Basic); ok && basic.Kind() == types.Invalid {
+		return false, false
+	}
+
+	return types.Identical(result, types.Universe.Lookup("error").Type()), true
+}
+
+// calleeName returns the printable name of the called function and its last part
+func calleeName(fun ast.Expr) (string, string) {
+	switch fn := fun.(type) {
+	case *ast.Ident:
+		return fn.Name, fn.Name
+	case *ast.SelectorExpr:
+		if x, ok := fn.X.(*ast.Ident); ok {
+			return x.Name + "." + fn.Sel.Name, fn.Sel.Name
+		}
+		return fn.Sel.Name, fn.Sel.Name
+	case *ast.IndexExpr: // generic function instantiation
+		return calleeName(fn.X)
+	}
+	return "", ""
+}
+
+func splitCallee(callee string) (string, string) {
+	parts := strings.Split(callee, ".")
+	if len(parts) > 2 {
+		callee = strings.Join(parts[len(parts)-2:], ".")
+	}
+	return callee, parts[len(parts)-1]
+}
+
+func isBlank(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
+
+func isNotBlank(expr ast.Expr) bool {
+	return !isBlank(expr)
+}
+
+// emptyImporter resolves every import to an empty package, so a single file can be type-checked
+// without the module and its dependencies
+type emptyImporter struct{}
+
+func (emptyImporter) Import(importPath string) (*types.Package, error) {
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
@@ -0,0 +1,208 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*ForbiddenImports)(nil)
+
+var (
+	goImportRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*import\s+(?:[\w.]+\s+)?"([^"]+)"`),
+		regexp.MustCompile(`^\s*(?:[\w.]+\s+)?"([^"]+)"\s*(?://.*)?$`), // line of the import block
+	}
+	jsImportRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\bfrom\s+['"]([^'"]+)['"]`),
+		regexp.MustCompile(`^\s*import\s+['"]([^'"]+)['"]`),
+		regexp.MustCompile(`\b(?:require|import)\(\s*['"]([^'"]+)['"]\s*\)`),
+	}
+)
+
+// importLine is an import path with its line number in the new file
+type importLine struct {
+	Path string
+	Line int
+}
+
+// ForbiddenImports flags imports added in Go and JS/TS files that match ImportStyle.ForbiddenImports,
+// rules of the config are extended with the ones from .codry.yml of the target branch
+type ForbiddenImports struct {
+	provider interfaces.CodeProvider
+	style    analyze.ImportStyle
+	log      logze.Logger
+
+	mu        sync.Mutex
+	cachedKey string
+	cached    analyze.ImportStyle
+}
+
+// NewForbiddenImports creates a processor for forbidden imports of the style, provider may be nil
+func NewForbiddenImports(provider interfaces.CodeProvider, style analyze.ImportStyle) *ForbiddenImports {
+	return &ForbiddenImports{
+		provider: provider,
+		style:    style,
+		log:      logze.With("component", "forbidden-imports-processor"),
+	}
+}
+
+// Process appends a finding for every added import that is forbidden
+func (p *ForbiddenImports) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	var patterns []*regexp.Regexp
+	switch strings.ToLower(filepath.Ext(fileDiff.NewPath)) {
+	case ".go":
+		patterns = goImportRegexes
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+		patterns = jsImportRegexes
+	default:
+		return findings, nil
+	}
+
+	style := p.requestStyle(ctx, request)
+	if len(style.ForbiddenImports) == 0 {
+		return findings, nil
+	}
+
+	added := make(map[int]bool)
+	for _, line := range analyze.FileAddedLines(fileDiff) {
+		added[line.Number] = true
+	}
+
+	for _, imp := range p.fileImports(ctx, request, fileDiff, patterns) {
+		if !added[imp.Line] {
+			continue
+		}
+		forbidden, ok := matchImport(imp.Path, style.ForbiddenImports)
+		if !ok {
+			continue
+		}
+
+		finding := &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        imp.Line,
+			IssueType:   model.IssueTypeRefactor,
+			Confidence:  model.ConfidenceVeryHigh,
+			Priority:    model.ReviewPriorityHigh,
+			Title:       fmt.Sprintf("Forbidden import %q", imp.Path),
+			Description: fmt.Sprintf("Import `%s` is forbidden in this project by the `%s` rule.", imp.Path, forbidden),
+		}
+		if preferred, ok := style.PreferredImport(forbidden); ok {
+			finding.Suggestion = fmt.Sprintf("Use `%s` instead.", preferred)
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
+
+// requestStyle returns style of the config merged with the repository config of the target branch,
+// the target branch is used so a merge request cannot relax rules it is checked against
+func (p *ForbiddenImports) requestStyle(ctx context.Context, request model.ReviewRequest) analyze.ImportStyle {
+	if p.provider == nil {
+		return p.style
+	}
+
+	key := request.ProjectID + "@" + request.MergeRequest.TargetBranch + "#" + request.MergeRequest.SHA
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cachedKey == key {
+		return p.cached
+	}
+
+	style := p.style
+	cfg, err := analyze.LoadRepoConfig(ctx, p.provider, request.ProjectID, request.MergeRequest.TargetBranch)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("repository config is not loaded", "project", request.ProjectID, "error", err)
+	} else {
+		style = style.Merge(cfg.Imports)
+	}
+
+	p.cachedKey, p.cached = key, style
+
+	return style
+}
+
+// fileImports returns imports of the new file content, Go imports are taken from the parsed file;
+// it falls back to matching added lines of the diff if the content is unavailable
+func (p *ForbiddenImports) fileImports(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, patterns []*regexp.Regexp) []importLine {
+	if p.provider != nil {
+		content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+		if err == nil {
+			if strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") {
+				imports, err := parseGoImports(fileDiff.NewPath, content)
+				if err == nil {
+					return imports
+				}
+				model.ContextLogger(ctx, p.log).Debug("failed to parse go imports", "file", fileDiff.NewPath, "error", err)
+			} else {
+				return matchImportLines(strings.Split(content, "\n"), 1, patterns)
+			}
+		} else {
+			model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		}
+	}
+
+	var imports []importLine
+	for _, line := range analyze.FileAddedLines(fileDiff) {
+		imports = append(imports, matchImportLines([]string{line.Content}, line.Number, patterns)...)
+	}
+	return imports
+}
+
+// parseGoImports returns the real import set of the Go file
+func parseGoImports(filePath, content string) ([]importLine, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, content, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := make([]importLine, 0, len(file.Imports))
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		imports = append(imports, importLine{Path: path, Line: fset.Position(spec.Path.Pos()).Line})
+	}
+
+	return imports, nil
+}
+
+// matchImportLines returns imports found in lines, first line has the number start
+func matchImportLines(lines []string, start int, patterns []*regexp.Regexp) []importLine {
+	var imports []importLine
+	for i, line := range lines {
+		for _, pattern := range patterns {
+			if match := pattern.FindStringSubmatch(line); match != nil {
+				imports = append(imports, importLine{Path: match[1], Line: start + i})
+				break
+			}
+		}
+	}
+	return imports
+}
+
+// matchImport returns the rule that forbids the import path: the same path or its parent package
+func matchImport(path string, rules []string) (string, bool) {
+	for _, rule := range rules {
+		if path == rule || strings.HasPrefix(path, strings.TrimSuffix(rule, "/")+"/") {
+			return rule, true
+		}
+	}
+	return "", false
+}
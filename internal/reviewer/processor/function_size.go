@@ -0,0 +1,146 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*FunctionSize)(nil)
+
+// DefaultFunctionLimits are limits of function length in lines and number of parameters
+// used if the project has no linter config with them
+var DefaultFunctionLimits = analyze.ComplexityLimits{FuncLength: 60, FuncParams: 5}
+
+// FunctionSize flags added or modified Go functions that are longer or take more parameters than
+// the limits of funlen and revive argument-limit in .golangci.yml of the target branch nearest to the file
+type FunctionSize struct {
+	provider interfaces.CodeProvider
+	style    *analyze.ProjectStyleAnalyzer
+	defaults analyze.ComplexityLimits
+	log      logze.Logger
+}
+
+// NewFunctionSize creates a processor for function size, defaults are used for limits missing in the linter config
+func NewFunctionSize(provider interfaces.CodeProvider, defaults analyze.ComplexityLimits) *FunctionSize {
+	return &FunctionSize{
+		provider: provider,
+		style:    analyze.NewProjectStyleAnalyzer(provider),
+		defaults: defaults,
+		log:      logze.With("component", "function-size-processor"),
+	}
+}
+
+// Process appends a medium priority finding for every changed function exceeding a limit
+func (p *FunctionSize) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || !strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") || strings.HasSuffix(fileDiff.NewPath, "_test.go") {
+		return findings, nil
+	}
+
+	added := make(map[int]struct{})
+	for _, line := range analyze.FileAddedLines(fileDiff) {
+		added[line.Number] = struct{}{}
+	}
+	if len(added) == 0 {
+		return findings, nil
+	}
+
+	content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileDiff.NewPath, content, parser.SkipObjectResolution)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to parse go file", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+
+	limits := p.fileLimits(ctx, request, fileDiff.NewPath)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start, end := fset.Position(fn.Pos()).Line, fset.Position(fn.End()).Line
+		if !hasAddedLine(added, start, end) {
+			continue
+		}
+
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			name = strings.TrimPrefix(types.ExprString(fn.Recv.List[0].Type), "*") + "." + name
+		}
+
+		// Lines between the braces like funlen counts them
+		length := fset.Position(fn.Body.Rbrace).Line - fset.Position(fn.Body.Lbrace).Line - 1
+		if limits.FuncLength > 0 && length > limits.FuncLength {
+			findings = append(findings, &model.ReviewAIComment{
+				FilePath:    fileDiff.NewPath,
+				Line:        start,
+				IssueType:   model.IssueTypeRefactor,
+				Confidence:  model.ConfidenceVeryHigh,
+				Priority:    model.ReviewPriorityMedium,
+				Title:       fmt.Sprintf("Function `%s` is too long: %d lines, the limit is %d", name, length, limits.FuncLength),
+				Description: fmt.Sprintf("The body of `%s` has %d lines while the project limit is %d, long functions are hard to read and test.", name, length, limits.FuncLength),
+				Suggestion:  "Extract independent steps into separate functions with descriptive names.",
+			})
+		}
+
+		params := fn.Type.Params.NumFields()
+		if limits.FuncParams > 0 && params > limits.FuncParams {
+			findings = append(findings, &model.ReviewAIComment{
+				FilePath:    fileDiff.NewPath,
+				Line:        start,
+				IssueType:   model.IssueTypeRefactor,
+				Confidence:  model.ConfidenceVeryHigh,
+				Priority:    model.ReviewPriorityMedium,
+				Title:       fmt.Sprintf("Function `%s` has too many parameters: %d, the limit is %d", name, params, limits.FuncParams),
+				Description: fmt.Sprintf("`%s` takes %d parameters while the project limit is %d, long parameter lists are easy to misuse.", name, params, limits.FuncParams),
+				Suggestion:  "Group related parameters into a struct or split the function.",
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// fileLimits returns limits of the linter config in the target branch nearest to the file, missing ones
+// are taken from defaults; configs are cached by the style analyzer
+func (p *FunctionSize) fileLimits(ctx context.Context, request model.ReviewRequest, filePath string) analyze.ComplexityLimits {
+	limits := p.defaults
+	linter, err := p.style.AnalyzeLinterConfig(ctx, request, filePath)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("linter config is not loaded, using default limits", "project", request.ProjectID, "error", err)
+	} else {
+		if linter.Complexity.FuncLength > 0 {
+			limits.FuncLength = linter.Complexity.FuncLength
+		}
+		if linter.Complexity.FuncParams > 0 {
+			limits.FuncParams = linter.Complexity.FuncParams
+		}
+	}
+
+	return limits
+}
+
+// hasAddedLine checks if any line in [start, end] is added
+func hasAddedLine(added map[int]struct{}, start, end int) bool {
+	for line := start; line <= end; line++ {
+		if _, ok := added[line]; ok {
+			return true
+		}
+	}
+	return false
+}
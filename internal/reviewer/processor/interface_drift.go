@@ -0,0 +1,222 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/lang"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*InterfaceDrift)(nil)
+
+// maxInterfaceDriftFiles limits the number of Go files fetched from one package to find assertions and interfaces
+const maxInterfaceDriftFiles = 30
+
+// InterfaceDrift flags Go methods removed or changed in a way that breaks assertions like var _ Iface = (*T)(nil)
+// of their types, it is an AST heuristic without type information: interfaces are found by name in the package
+// of the assertion if it is in the repository, embedded interfaces and aliases of types are not followed
+type InterfaceDrift struct {
+	provider interfaces.CodeProvider
+	log      logze.Logger
+}
+
+// NewInterfaceDrift creates a processor for implementations of interfaces broken by changed methods
+func NewInterfaceDrift(provider interfaces.CodeProvider) *InterfaceDrift {
+	return &InterfaceDrift{
+		provider: provider,
+		log:      logze.With("component", "interface-drift-processor"),
+	}
+}
+
+// Process appends a high priority finding for every asserted interface whose method was removed or changed
+func (p *InterfaceDrift) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsNew || fileDiff.IsDeleted || !strings.EqualFold(path.Ext(fileDiff.NewPath), ".go") || strings.HasSuffix(fileDiff.NewPath, "_test.go") {
+		return findings, nil
+	}
+
+	before, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.OldPath, request.MergeRequest.TargetBranch)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get previous file content", "file", fileDiff.OldPath, "error", err)
+		return findings, nil
+	}
+	after, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+	changes, err := analyze.FindMethodChanges(before, after, fileDiff.Diff)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to parse go file", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+	if len(changes) == 0 {
+		return findings, nil
+	}
+
+	// Package files are fetched only if methods changed, the reviewed file is taken at the head commit
+	dir := path.Dir(fileDiff.NewPath)
+	packageFiles := p.packageFiles(ctx, request, dir)
+	packageFiles[fileDiff.NewPath] = after
+	changes = dropMovedMethods(changes, packageFiles, fileDiff.NewPath)
+
+	var assertions []analyze.InterfaceAssertion
+	for filePath, content := range packageFiles {
+		assertions = append(assertions, analyze.ParseInterfaceAssertions(filePath, content)...)
+	}
+
+	changedTypes := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		changedTypes[change.Type] = true
+	}
+
+	var modulePath string
+	interfaceFiles := map[string]map[string]string{dir: packageFiles} // files of packages by directory
+	reported := make(map[string]bool)
+	for _, assertion := range assertions {
+		if !changedTypes[assertion.Type] {
+			continue
+		}
+
+		ifaceDir := dir
+		if assertion.ImportPath != "" {
+			if modulePath == "" {
+				goMod, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, "go.mod", request.MergeRequest.SHA)
+				if err != nil {
+					model.ContextLogger(ctx, p.log).Debug("failed to get go.mod", "error", err)
+				}
+				modulePath = lang.Check(analyze.ParseGoModulePath(goMod), "-") // not empty to fetch it once
+			}
+			relative, ok := strings.CutPrefix(assertion.ImportPath, modulePath+"/")
+			if !ok {
+				continue // interfaces of other modules need type information
+			}
+			ifaceDir = relative
+			if interfaceFiles[ifaceDir] == nil {
+				interfaceFiles[ifaceDir] = p.packageFiles(ctx, request, ifaceDir)
+			}
+		}
+
+		methods, ok := findInterfaceMethods(interfaceFiles[ifaceDir], assertion.Interface)
+		if !ok {
+			continue
+		}
+		for _, change := range changes {
+			expected, broken := analyze.BreaksInterface(change, assertion, methods)
+			key := assertion.ImportPath + "." + assertion.Interface + "." + change.Type + "." + change.Method
+			if !broken || reported[key] {
+				continue
+			}
+			reported[key] = true
+			findings = append(findings, interfaceDriftFinding(fileDiff.NewPath, change, assertion, expected))
+		}
+	}
+
+	return findings, nil
+}
+
+// packageFiles returns contents of non-test Go files of the directory at the head commit,
+// it returns nothing if the provider can't list files
+func (p *InterfaceDrift) packageFiles(ctx context.Context, request model.ReviewRequest, dir string) map[string]string {
+	files := make(map[string]string)
+	lister, ok := p.provider.(interfaces.FileLister)
+	if !ok {
+		return files
+	}
+
+	listDir := dir
+	if listDir == "." {
+		listDir = "" // repository root
+	}
+	paths, err := lister.ListFiles(ctx, request.ProjectID, listDir, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to list package files", "dir", dir, "error", err)
+		return files
+	}
+	for _, filePath := range paths {
+		if len(files) >= maxInterfaceDriftFiles {
+			break
+		}
+		if !strings.HasSuffix(filePath, ".go") || strings.HasSuffix(filePath, "_test.go") {
+			continue
+		}
+		content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, filePath, request.MergeRequest.SHA)
+		if err != nil {
+			continue
+		}
+		files[filePath] = content
+	}
+	return files
+}
+
+// dropMovedMethods removes changes of methods that were moved to another file of the package with the same signature,
+// a method moved with another signature is a changed one
+func dropMovedMethods(changes []analyze.MethodChange, packageFiles map[string]string, filePath string) []analyze.MethodChange {
+	result := changes[:0]
+	for _, change := range changes {
+		if change.After == "" {
+			for otherPath, content := range packageFiles {
+				if otherPath == filePath {
+					continue
+				}
+				methods, err := analyze.ParseGoMethods(content)
+				if err != nil {
+					continue
+				}
+				if method, ok := methods[change.Type][change.Method]; ok {
+					change.After, change.Pointer = method.Signature, method.Pointer
+					break
+				}
+			}
+			if change.After == change.Before && !change.Pointer {
+				continue
+			}
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+// findInterfaceMethods returns methods of the interface declared in one of the files
+func findInterfaceMethods(files map[string]string, name string) (map[string]string, bool) {
+	for _, content := range files {
+		if methods, ok := analyze.ParseInterfaceMethods(content, name); ok {
+			return methods, true
+		}
+	}
+	return nil, false
+}
+
+func interfaceDriftFinding(filePath string, change analyze.MethodChange, assertion analyze.InterfaceAssertion, expected string) *model.ReviewAIComment {
+	iface := assertion.Interface
+	if assertion.ImportPath != "" {
+		iface = path.Base(assertion.ImportPath) + "." + iface
+	}
+
+	var reason string
+	switch {
+	case change.After == "":
+		reason = fmt.Sprintf("Method `%s` is removed", change.Method)
+	case change.After != expected:
+		reason = fmt.Sprintf("Method `%s` now has signature `%s`", change.Method, change.After)
+	default:
+		reason = fmt.Sprintf("Method `%s` now has a pointer receiver, it is not in the method set of the value type", change.Method)
+	}
+
+	return &model.ReviewAIComment{
+		FilePath:   filePath,
+		Line:       change.Line,
+		IssueType:  model.IssueTypeBug,
+		Confidence: model.ConfidenceMedium,
+		Priority:   model.ReviewPriorityHigh,
+		Title:      fmt.Sprintf("`%s` may no longer implement `%s`", change.Type, iface),
+		Description: fmt.Sprintf("%s, but `%s` requires `%s%s`. The assertion at %s:%d will fail to compile.",
+			reason, iface, change.Method, expected, assertion.File, assertion.Line),
+		Suggestion: "Keep the method matching the interface or change the interface and all its implementations together.",
+	}
+}
@@ -0,0 +1,57 @@
+package processor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+// licenseHeaderLines is the number of first lines of the file where the license header is expected
+const licenseHeaderLines = 20
+
+var _ interfaces.FindingProcessor = (*LicenseHeader)(nil)
+
+// LicenseHeader flags new files that don't start with the license header
+type LicenseHeader struct {
+	header string
+}
+
+// NewLicenseHeader creates a processor for the license header, only the first line of header is matched,
+// case and comment prefixes are ignored
+func NewLicenseHeader(header string) *LicenseHeader {
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(header), "\n")
+	return &LicenseHeader{header: strings.ToLower(strings.TrimSpace(firstLine))}
+}
+
+// Process appends a finding if the new file has no license header in its first lines,
+// modified files are skipped because their header is not in the diff
+func (p *LicenseHeader) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if p.header == "" || !fileDiff.IsNew {
+		return findings, nil
+	}
+
+	lines := analyze.FileAddedLines(fileDiff)
+	if len(lines) == 0 {
+		return findings, nil
+	}
+	for _, line := range lines[:min(licenseHeaderLines, len(lines))] {
+		if strings.Contains(strings.ToLower(line.Content), p.header) {
+			return findings, nil
+		}
+	}
+
+	findings = append(findings, &model.ReviewAIComment{
+		FilePath:    fileDiff.NewPath,
+		Line:        lines[0].Number,
+		IssueType:   model.IssueTypeOther,
+		Confidence:  model.ConfidenceVeryHigh,
+		Priority:    model.ReviewPriorityMedium,
+		Title:       "Missing license header",
+		Description: "New file should start with the project license header.",
+	})
+
+	return findings, nil
+}
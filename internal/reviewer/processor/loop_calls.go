@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+var _ interfaces.FindingProcessor = (*LoopCalls)(nil)
+
+// LoopCalls flags network and database calls and JS awaits inside loop bodies of changed code,
+// they make a round trip per iteration (N+1 queries)
+type LoopCalls struct{}
+
+// NewLoopCalls creates a processor for calls inside loops, test files are skipped
+func NewLoopCalls() *LoopCalls {
+	return &LoopCalls{}
+}
+
+// Process appends a performance finding on the line of every call inside a loop if the call or the loop is added,
+// the description names the line of the loop
+func (p *LoopCalls) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || analyze.IsTestFile(fileDiff.NewPath, analyze.DefaultTestFileConventions) {
+		return findings, nil
+	}
+
+	for _, call := range analyze.FindLoopCalls(fileDiff.NewPath, fileDiff.Diff) {
+		finding := &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        call.Line,
+			IssueType:   model.IssueTypePerformance,
+			Confidence:  model.ConfidenceMedium,
+			Priority:    model.ReviewPriorityMedium,
+			Description: fmt.Sprintf("`%s` (line %d, loop at line %d): %s.", call.Code, call.Line, call.LoopLine, call.Description),
+		}
+		switch call.Type {
+		case analyze.PerformanceFindingAwaitInLoop:
+			finding.Title = "Sequential await inside a loop"
+			finding.Suggestion = "Start the operations together and await them with `Promise.all` if they are independent."
+		default:
+			finding.Title = fmt.Sprintf("`%s` is called inside a loop", call.Call)
+			finding.Suggestion = "Fetch the data in batch before the loop, or add `// codry:ignore performance` to the line if the loop is short."
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
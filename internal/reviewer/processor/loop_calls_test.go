@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+func TestLoopCallsProcess(t *testing.T) {
+	const loop = "@@ -9,0 +10,3 @@\n+for _, id := range ids {\n+\tuser, err := db.QueryRow(id)\n+}\n"
+
+	tests := []struct {
+		name      string
+		path      string
+		diff      string
+		wantTitle string
+		wantLine  int
+		// wantLines are the call line and the loop line in the description
+		wantLines []string
+	}{
+		{
+			name:      "database call",
+			path:      "users.go",
+			diff:      loop,
+			wantTitle: "`db.QueryRow` is called inside a loop",
+			wantLine:  11,
+			wantLines: []string{"line 11", "loop at line 10"},
+		},
+		{
+			name:      "await",
+			path:      "users.js",
+			diff:      "@@ -1,0 +1,3 @@\n+for (const id of ids) {\n+  await sleep(id);\n+}\n",
+			wantTitle: "Sequential await inside a loop",
+			wantLine:  2,
+			wantLines: []string{"line 2", "loop at line 1"},
+		},
+		{
+			name: "call outside of a loop",
+			path: "users.go",
+			diff: "@@ -9,0 +10,1 @@\n+user, err := db.QueryRow(id)\n",
+		},
+		{
+			name: "test file",
+			path: "users_test.go",
+			diff: loop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileDiff := &model.FileDiff{NewPath: tt.path, OldPath: tt.path, Diff: tt.diff}
+			findings, err := NewLoopCalls().Process(context.Background(), model.ReviewRequest{}, fileDiff, nil)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if tt.wantTitle == "" {
+				if len(findings) != 0 {
+					t.Errorf("Process() = %d findings, want none", len(findings))
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("Process() = %d findings, want 1", len(findings))
+			}
+			got := findings[0]
+			if got.Line != tt.wantLine || got.Title != tt.wantTitle || got.IssueType != model.IssueTypePerformance {
+				t.Errorf("Process() = line %d %q %s, want line %d %q %s", got.Line, got.Title, got.IssueType,
+					tt.wantLine, tt.wantTitle, model.IssueTypePerformance)
+			}
+			for _, line := range tt.wantLines {
+				if !strings.Contains(got.Description, line) {
+					t.Errorf("Description = %q, want %q in it", got.Description, line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+var _ interfaces.FindingProcessor = (*NamingChecks)(nil)
+
+// NamingChecks flags Go identifiers introduced in the diff that spell initialisms in mixed case,
+// e.g. userId instead of userID or HttpClient instead of HTTPClient
+type NamingChecks struct {
+	initialisms []string
+}
+
+// NewNamingChecks creates a processor for initialisms in names, nothing is reported without initialisms
+func NewNamingChecks(initialisms []string) *NamingChecks {
+	return &NamingChecks{initialisms: initialisms}
+}
+
+// Process appends a finding for every identifier declared in added lines with a mixed-case initialism,
+// identifiers that also exist in removed lines are not introduced by the change and are skipped
+func (p *NamingChecks) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || !strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") {
+		return findings, nil
+	}
+
+	violations := analyze.FindNamingViolations(fileDiff.Diff, p.initialisms)
+	if len(violations) == 0 {
+		return findings, nil
+	}
+	added := analyze.FileAddedLines(fileDiff)
+
+	for _, violation := range violations {
+		findings = append(findings, &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        declarationLine(added, violation.Line),
+			IssueType:   model.IssueTypeRefactor,
+			Confidence:  model.ConfidenceVeryHigh,
+			Priority:    model.ReviewPriorityBacklog,
+			Title:       fmt.Sprintf("Initialism %s in `%s` has mixed case", violation.Initialism, violation.Name),
+			Description: "By the Go convention initialisms keep a consistent case, so names read the same across the codebase.",
+			Suggestion:  fmt.Sprintf("Rename `%s` to `%s`.", violation.Name, violation.Suggestion),
+		})
+	}
+
+	return findings, nil
+}
+
+// declarationLine returns the number of the first added line with the code, 0 if it is not found
+func declarationLine(lines []analyze.AddedLine, code string) int {
+	for _, line := range lines {
+		if strings.TrimSpace(line.Content) == code {
+			return line.Number
+		}
+	}
+	return 0
+}
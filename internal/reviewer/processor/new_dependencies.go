@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*NewDependencies)(nil)
+
+// NewDependencies lists modules added to go.mod as direct dependencies, so reviewers check their license,
+// maintenance and popularity; modules with pseudo-versions and modules that may duplicate existing ones are marked
+type NewDependencies struct {
+	provider interfaces.CodeProvider
+	log      logze.Logger
+}
+
+// NewNewDependencies creates a processor for dependencies added to go.mod
+func NewNewDependencies(provider interfaces.CodeProvider) *NewDependencies {
+	return &NewDependencies{
+		provider: provider,
+		log:      logze.With("component", "new-dependencies-processor"),
+	}
+}
+
+// Process appends an informational finding listing new direct dependencies of the go.mod file
+func (p *NewDependencies) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || path.Base(fileDiff.NewPath) != "go.mod" {
+		return findings, nil
+	}
+
+	var before string
+	if !fileDiff.IsNew {
+		content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.OldPath, request.MergeRequest.TargetBranch)
+		if err != nil {
+			model.ContextLogger(ctx, p.log).Debug("failed to get old file content", "file", fileDiff.OldPath, "error", err)
+			return findings, nil
+		}
+		before = content
+	}
+	after, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+
+	added := analyze.FindAddedGoDependencies(before, after)
+	if len(added) == 0 {
+		return findings, nil
+	}
+
+	var description strings.Builder
+	description.WriteString("The merge request adds direct dependencies:\n\n")
+	for _, dependency := range added {
+		description.WriteString(fmt.Sprintf("- `%s` %s", dependency.Path, dependency.Version))
+		var notes []string
+		if dependency.WasIndirect {
+			notes = append(notes, "it was an indirect dependency already")
+		}
+		if dependency.PseudoVersion {
+			notes = append(notes, "pseudo-version of a commit without a release tag, the module may be unmaintained")
+		}
+		if len(dependency.Overlaps) > 0 {
+			notes = append(notes, fmt.Sprintf("%s like `%s`, it may duplicate them", dependency.Purpose, strings.Join(dependency.Overlaps, "`, `")))
+		}
+		if len(notes) > 0 {
+			description.WriteString(" — " + strings.Join(notes, "; "))
+		}
+		description.WriteString("\n")
+	}
+
+	title := fmt.Sprintf("New dependency `%s`", added[0].Path)
+	if len(added) > 1 {
+		title = fmt.Sprintf("%d new dependencies", len(added))
+	}
+	findings = append(findings, &model.ReviewAIComment{
+		FilePath:    fileDiff.NewPath,
+		Line:        added[0].Line,
+		IssueType:   model.IssueTypeOther,
+		Confidence:  model.ConfidenceHigh,
+		Priority:    model.ReviewPriorityBacklog,
+		Title:       title,
+		Description: strings.TrimSuffix(description.String(), "\n"),
+		Suggestion:  "Check licenses, maintenance and popularity of the new modules, prefer existing dependencies with the same purpose.",
+	})
+
+	return findings, nil
+}
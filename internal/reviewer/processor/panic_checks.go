@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*PanicChecks)(nil)
+
+// PanicChecks flags added Go expressions that may panic: type assertions without the comma ok form,
+// writes to nil maps and constant indexes of split results without a length check
+type PanicChecks struct {
+	provider interfaces.CodeProvider
+	log      logze.Logger
+}
+
+// NewPanicChecks creates a processor for panic-prone expressions
+func NewPanicChecks(provider interfaces.CodeProvider) *PanicChecks {
+	return &PanicChecks{
+		provider: provider,
+		log:      logze.With("component", "panic-checks-processor"),
+	}
+}
+
+// Process appends a bug finding for every panic hint in added lines, writes to nil maps are certain panics
+// if reached, so they get a higher priority than heuristics that need type information to confirm
+func (p *PanicChecks) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || !strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") || strings.HasSuffix(fileDiff.NewPath, "_test.go") {
+		return findings, nil
+	}
+
+	content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+
+	for _, hint := range analyze.FindPanicHints(fileDiff.NewPath, content, fileDiff.Diff) {
+		finding := &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        hint.Line,
+			IssueType:   model.IssueTypeBug,
+			Confidence:  model.ConfidenceMedium,
+			Priority:    model.ReviewPriorityMedium,
+			Description: hint.Description,
+			Suggestion:  "Guard the expression or add `//nolint:codry` to the line if it can't panic.",
+		}
+		switch hint.Type {
+		case analyze.PanicHintUncheckedAssertion:
+			finding.Title = fmt.Sprintf("Type assertion may panic: `%s`", hint.Code)
+		case analyze.PanicHintNilMapWrite:
+			finding.Title = "Write to a nil map"
+			finding.Confidence = model.ConfidenceHigh
+			finding.Priority = model.ReviewPriorityHigh
+		case analyze.PanicHintUncheckedIndex:
+			finding.Title = "Index may be out of range"
+			if hint.InLoop {
+				finding.Priority = model.ReviewPriorityHigh
+			}
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
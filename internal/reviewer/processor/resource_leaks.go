@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*ResourceLeaks)(nil)
+
+// ResourceLeaks flags added Go files, rows, response bodies and locks that are not released
+// in the function that acquires them
+type ResourceLeaks struct {
+	provider interfaces.CodeProvider
+	pairs    []analyze.ResourcePair
+	log      logze.Logger
+}
+
+// NewResourceLeaks creates a processor for resources of the pairs acquired without a release
+func NewResourceLeaks(provider interfaces.CodeProvider, pairs []analyze.ResourcePair) *ResourceLeaks {
+	return &ResourceLeaks{
+		provider: provider,
+		pairs:    pairs,
+		log:      logze.With("component", "resource-leaks-processor"),
+	}
+}
+
+// Process appends a high priority bug finding on the line of every leaked acquisition in added lines
+func (p *ResourceLeaks) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted || !strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") || strings.HasSuffix(fileDiff.NewPath, "_test.go") {
+		return findings, nil
+	}
+
+	content, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+
+	for _, leak := range analyze.FindResourceLeaks(fileDiff.NewPath, content, fileDiff.Diff, p.pairs) {
+		acquire := strings.TrimPrefix(leak.Pair.Acquire, ".")
+		finding := &model.ReviewAIComment{
+			FilePath:   fileDiff.NewPath,
+			Line:       leak.Line,
+			IssueType:  model.IssueTypeBug,
+			Confidence: model.ConfidenceMedium,
+			Priority:   model.ReviewPriorityHigh,
+		}
+		if leak.Resource == "" {
+			finding.Title = fmt.Sprintf("Result of `%s` is discarded", acquire)
+			finding.Description = fmt.Sprintf("The result of `%s` must be released with `%s`, it leaks when discarded: `%s`.",
+				acquire, leak.Pair.Release, leak.Code)
+			finding.Suggestion = fmt.Sprintf("Assign the result and call `%s` on it, or add `//nolint:codry` to the line.", leak.Pair.Release)
+		} else {
+			release := leak.Resource + "." + leak.Pair.Release + "()"
+			finding.Title = fmt.Sprintf("`%s` from `%s` is not released", leak.Resource, acquire)
+			finding.Description = fmt.Sprintf("`%s` is acquired by `%s` but the function never calls `%s`, "+
+				"it leaks on every call and on early returns.", leak.Resource, leak.Code, release)
+			finding.Suggestion = fmt.Sprintf("Add `defer %s` after the error check, or `//nolint:codry` to the line "+
+				"if the resource is released elsewhere.", release)
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+var _ interfaces.FindingProcessor = (*SecretsChecks)(nil)
+
+// SecretsChecks flags secrets hardcoded in added lines like cloud keys, tokens and private keys,
+// secret values are redacted in its findings and in findings of the model for the same file
+type SecretsChecks struct {
+	scanner *analyze.SecurityScanner
+}
+
+// NewSecretsChecks creates a processor for hardcoded secrets, files allowlisted by the scanner are skipped
+func NewSecretsChecks(scanner *analyze.SecurityScanner) *SecretsChecks {
+	return &SecretsChecks{scanner: scanner}
+}
+
+// Process appends a critical finding for every added line with a secret and redacts the secret
+// in the title, description, suggestion and code of all findings of the file
+func (p *SecretsChecks) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsDeleted {
+		return findings, nil
+	}
+
+	for _, secret := range p.scanner.ScanDiff(fileDiff.NewPath, fileDiff.Diff) {
+		if secret.Type != analyze.SecurityFindingHardcodedSecret {
+			continue
+		}
+		for _, finding := range findings {
+			finding.Title = secret.RedactSecret(finding.Title)
+			finding.Description = secret.RedactSecret(finding.Description)
+			finding.Suggestion = secret.RedactSecret(finding.Suggestion)
+			finding.CodeSnippet = secret.RedactSecret(finding.CodeSnippet)
+		}
+		findings = append(findings, &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        secret.Line,
+			IssueType:   model.IssueTypeSecurity,
+			Confidence:  model.ConfidenceVeryHigh,
+			Priority:    model.ReviewPriorityCritical,
+			Title:       "Hardcoded secret",
+			Description: fmt.Sprintf("`%s`: %s.", secret.Code, secret.Description),
+			Suggestion:  "The value stays in the history of the repository, so it must be rotated even after removal.",
+		})
+	}
+
+	return findings, nil
+}
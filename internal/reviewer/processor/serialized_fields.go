@@ -0,0 +1,89 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*SerializedFields)(nil)
+
+// SerializedFields flags removed fields, changed serialized names and changed types of fields of Go structs
+// with json, yaml or protobuf tags, they break consumers of the serialized data silently
+type SerializedFields struct {
+	provider interfaces.CodeProvider
+	log      logze.Logger
+}
+
+// NewSerializedFields creates a processor for breaking changes of serialized struct fields
+func NewSerializedFields(provider interfaces.CodeProvider) *SerializedFields {
+	return &SerializedFields{
+		provider: provider,
+		log:      logze.With("component", "serialized-fields-processor"),
+	}
+}
+
+// Process compares structs with serialization tags of the target branch and the head versions of the file
+func (p *SerializedFields) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	if fileDiff.IsNew || fileDiff.IsDeleted || !strings.EqualFold(filepath.Ext(fileDiff.NewPath), ".go") || strings.HasSuffix(fileDiff.NewPath, "_test.go") {
+		return findings, nil
+	}
+
+	before, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.OldPath, request.MergeRequest.TargetBranch)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get old file content", "file", fileDiff.OldPath, "error", err)
+		return findings, nil
+	}
+	after, err := analyze.FetchFileContent(ctx, p.provider, request.ProjectID, fileDiff.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to get file content", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+
+	changes, err := analyze.FindSerializedFieldChanges(before, after)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to find serialized field changes", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+
+	for _, change := range changes {
+		field := change.Struct + "." + change.Field
+		finding := &model.ReviewAIComment{
+			FilePath:   fileDiff.NewPath,
+			Line:       change.Line,
+			IssueType:  model.IssueTypeBug,
+			Confidence: model.ConfidenceHigh,
+			Priority:   model.ReviewPriorityHigh,
+		}
+		switch change.Type {
+		case analyze.SerializedFieldRemoved:
+			finding.Title = fmt.Sprintf("Serialized field `%s` is removed", field)
+			finding.Description = fmt.Sprintf("The field with `%s` is removed from `%s`: consumers of the serialized data stop getting it "+
+				"and stored data with it is ignored when decoded.", change.OldTag, change.Struct)
+			finding.Suggestion = "Keep the field deprecated until all consumers stop using it, or version the API."
+		case analyze.SerializedFieldRenamed:
+			newTag := change.NewTag
+			if newTag == "" {
+				newTag = "no serialization tag"
+			}
+			finding.Title = fmt.Sprintf("Serialized name of `%s` changed", field)
+			finding.Description = fmt.Sprintf("The tag of the field changed from `%s` to `%s`: consumers of the serialized data "+
+				"and stored data still use the old name, the field will be missing for them.", change.OldTag, newTag)
+			finding.Suggestion = "Keep the old name in the tag, or support both names until all consumers are migrated."
+		case analyze.SerializedFieldTypeChanged:
+			finding.Title = fmt.Sprintf("Type of serialized field `%s` changed: %s → %s", field, change.OldType, change.NewType)
+			finding.Description = fmt.Sprintf("The field with `%s` is encoded with another type now, consumers and stored data "+
+				"with the old type may fail to decode.", change.NewTag)
+			finding.Suggestion = "Add a new field with the new type and deprecate the old one, or make sure all consumers accept both types."
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/provider/local"
+)
+
+func TestSerializedFieldsProcess(t *testing.T) {
+	const before = "package api\n\ntype User struct {\n\tID   int    `json:\"id\"`\n\tName string `json:\"name\"`\n}\n"
+
+	tests := []struct {
+		name      string
+		diff      string
+		wantLine  int
+		wantTitle string
+		// wantTags are parts of the description
+		wantTags []string
+	}{
+		{
+			name:      "renamed tag",
+			diff:      "--- a/user.go\n+++ b/user.go\n@@ -4 +4 @@\n-\tID   int    `json:\"id\"`\n+\tID   int    `json:\"user_id\"`\n",
+			wantLine:  4,
+			wantTitle: "Serialized name of `User.ID` changed",
+			wantTags:  []string{"`json:\"id\"`", "`json:\"user_id\"`"},
+		},
+		{
+			name:      "removed tag",
+			diff:      "--- a/user.go\n+++ b/user.go\n@@ -5 +5 @@\n-\tName string `json:\"name\"`\n+\tName string\n",
+			wantLine:  5,
+			wantTitle: "Serialized name of `User.Name` changed",
+			wantTags:  []string{"`json:\"name\"`", "`no serialization tag`"},
+		},
+		{
+			name:      "removed field",
+			diff:      "--- a/user.go\n+++ b/user.go\n@@ -4,2 +4 @@\n \tID   int    `json:\"id\"`\n-\tName string `json:\"name\"`\n",
+			wantLine:  3,
+			wantTitle: "Serialized field `User.Name` is removed",
+			wantTags:  []string{"`json:\"name\"`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := model.ParseUnifiedDiff(tt.diff)
+			provider := local.New("", diffs, map[string]string{"user.go": before})
+			request := model.ReviewRequest{ProjectID: "local", MergeRequest: provider.MergeRequest(), Changes: diffs}
+
+			findings, err := NewSerializedFields(provider).Process(context.Background(), request, diffs[0], nil)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if len(findings) != 1 {
+				t.Fatalf("Process() = %d findings, want 1", len(findings))
+			}
+			got := findings[0]
+			if got.Line != tt.wantLine || got.Title != tt.wantTitle || got.Priority != model.ReviewPriorityHigh {
+				t.Errorf("Process() = line %d %q %s, want line %d %q %s", got.Line, got.Title, got.Priority,
+					tt.wantLine, tt.wantTitle, model.ReviewPriorityHigh)
+			}
+			for _, tag := range tt.wantTags {
+				if !strings.Contains(got.Description, tag) {
+					t.Errorf("description %q doesn't have %s", got.Description, tag)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+var _ interfaces.FindingProcessor = (*ShellScriptChecks)(nil)
+
+// Names of shell script checks
+const (
+	ShellCheckUnquotedVariables = "unquoted_variables"
+	ShellCheckStrictMode        = "strict_mode"
+)
+
+// ShellCheckNames are names of all shell script checks
+var ShellCheckNames = []string{ShellCheckUnquotedVariables, ShellCheckStrictMode}
+
+var heredocRegex = regexp.MustCompile(`(?:^|[^<])<<-?\s*['"]?(\w+)['"]?`) // here-strings <<< are not heredocs
+
+// ShellScriptChecks flags variables expanded without quotes in added lines of shell scripts
+// and new scripts without set -euo pipefail
+type ShellScriptChecks struct {
+	skip []string
+}
+
+// NewShellScriptChecks creates a processor for shell scripts, skip are names of disabled checks
+func NewShellScriptChecks(skip []string) *ShellScriptChecks {
+	return &ShellScriptChecks{skip: skip}
+}
+
+// Process appends findings for .sh and .bash files, strict mode is checked only in new scripts with a shebang
+// because the whole file is in the diff and scripts without it are usually sourced
+func (p *ShellScriptChecks) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	ext := strings.ToLower(path.Ext(fileDiff.NewPath))
+	if fileDiff.IsDeleted || (ext != ".sh" && ext != ".bash") {
+		return findings, nil
+	}
+
+	lines := analyze.FileAddedLines(fileDiff)
+	if len(lines) == 0 {
+		return findings, nil
+	}
+
+	if fileDiff.IsNew && !slices.Contains(p.skip, ShellCheckStrictMode) {
+		if missing := missingStrictOptions(lines); len(missing) > 0 {
+			findings = append(findings, &model.ReviewAIComment{
+				FilePath:    fileDiff.NewPath,
+				Line:        lines[0].Number,
+				IssueType:   model.IssueTypeBug,
+				Confidence:  model.ConfidenceHigh,
+				Priority:    model.ReviewPriorityMedium,
+				Title:       "Script doesn't enable strict mode",
+				Description: fmt.Sprintf("Options %s are not set, the script continues after failed commands, unset variables and failures inside pipelines.", strings.Join(missing, ", ")),
+				Suggestion:  "Add `set -euo pipefail` after the shebang.",
+			})
+		}
+	}
+
+	if slices.Contains(p.skip, ShellCheckUnquotedVariables) {
+		return findings, nil
+	}
+
+	// Heredoc bodies are text, lines are consecutive only if the whole heredoc is added
+	var heredocEnd string
+	for i, line := range lines {
+		if heredocEnd != "" && i > 0 && lines[i-1].Number+1 != line.Number {
+			heredocEnd = "" // the end of the heredoc is not in the diff
+		}
+		if heredocEnd != "" {
+			if strings.TrimSpace(line.Content) == heredocEnd {
+				heredocEnd = ""
+			}
+			continue
+		}
+		if match := heredocRegex.FindStringSubmatch(line.Content); match != nil {
+			heredocEnd = match[1]
+		}
+
+		variables := unquotedVariables(line.Content)
+		if len(variables) == 0 {
+			continue
+		}
+		findings = append(findings, &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        line.Number,
+			IssueType:   model.IssueTypeBug,
+			Confidence:  model.ConfidenceMedium,
+			Priority:    model.ReviewPriorityBacklog,
+			Title:       fmt.Sprintf("Unquoted variable expansion: `%s`", strings.Join(variables, "`, `")),
+			Description: "Unquoted expansions are split on whitespace and expanded as globs, values with spaces or wildcards break the command.",
+			Suggestion:  "Wrap the expansion in double quotes.",
+		})
+	}
+
+	return findings, nil
+}
+
+// missingStrictOptions returns options of set -euo pipefail that are not enabled by set commands or the shebang,
+// pipefail is not required for POSIX sh scripts; nothing is returned for scripts without a shebang
+func missingStrictOptions(lines []analyze.AddedLine) []string {
+	shebang := strings.TrimSpace(lines[0].Content)
+	if lines[0].Number != 1 || !strings.HasPrefix(shebang, "#!") {
+		return nil
+	}
+
+	interpreter := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(interpreter) == 0 {
+		return nil
+	}
+	if path.Base(interpreter[0]) == "env" {
+		interpreter = interpreter[1:]
+	}
+	if len(interpreter) == 0 {
+		return nil
+	}
+	shell := path.Base(interpreter[0])
+
+	enabled := make(map[string]bool)
+	setOptions(interpreter[1:], enabled)
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line.Content)
+		if len(fields) > 1 && fields[0] == "set" {
+			setOptions(fields[1:], enabled)
+		}
+	}
+
+	required := []string{"errexit", "nounset", "pipefail"}
+	if shell == "sh" || shell == "dash" {
+		required = required[:2]
+	}
+
+	var missing []string
+	for _, option := range required {
+		if !enabled[option] {
+			missing = append(missing, option)
+		}
+	}
+	return missing
+}
+
+// setOptions marks options enabled by arguments of set like -eu, -o pipefail or -euo pipefail
+func setOptions(args []string, enabled map[string]bool) {
+	shortOptions := map[rune]string{'e': "errexit", 'u': "nounset"}
+	expectName := false
+	for _, arg := range args {
+		if expectName {
+			enabled[arg] = true
+			expectName = false
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+			continue
+		}
+		for _, r := range arg[1:] {
+			if r == 'o' {
+				expectName = true
+			} else if option, ok := shortOptions[r]; ok {
+				enabled[option] = true
+			}
+		}
+	}
+}
+
+// unquotedVariables returns variable expansions of the line outside quotes like $name, ${name} or $1,
+// expansions in [[ ]], arithmetic, assignments and case words are skipped because they are not split
+func unquotedVariables(line string) []string {
+	var (
+		variables []string
+		quote     byte
+		inTest    bool
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\':
+			i++ // escaped character
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return variables // comment
+		case strings.HasPrefix(line[i:], "[["):
+			inTest = true
+			i++
+		case strings.HasPrefix(line[i:], "]]"):
+			inTest = false
+			i++
+		case strings.HasPrefix(line[i:], "$((") || strings.HasPrefix(line[i:], "(("):
+			end := strings.Index(line[i:], "))")
+			if end < 0 {
+				return variables
+			}
+			i += end + 1
+		case c == '$' && !inTest && !isShellAssignment(line[:i]) && !strings.HasSuffix(" "+strings.TrimRight(line[:i], " \t"), " case"):
+			if name := shellVariableName(line[i+1:]); name != "" {
+				variables = append(variables, "$"+name)
+			}
+		}
+	}
+	return variables
+}
+
+// shellVariableName returns the name of the expansion after $, special parameters with numbers and flags are skipped
+func shellVariableName(text string) string {
+	if strings.HasPrefix(text, "{") {
+		end := strings.IndexByte(text, '}')
+		if end < 0 || strings.HasPrefix(text, "{#") {
+			return ""
+		}
+		return text[:end+1]
+	}
+	if text == "" {
+		return ""
+	}
+	if c := text[0]; c == '@' || c == '*' || (c >= '0' && c <= '9') {
+		return text[:1]
+	}
+
+	end := 0
+	for end < len(text) && (text[end] == '_' || isASCIILetter(text[end]) || (end > 0 && text[end] >= '0' && text[end] <= '9')) {
+		end++
+	}
+	return text[:end]
+}
+
+// isShellAssignment checks if the text before an expansion ends with an assignment like name= or export name=
+func isShellAssignment(before string) bool {
+	if !strings.HasSuffix(before, "=") {
+		return false
+	}
+	name := before[:len(before)-1]
+	start := len(name)
+	for start > 0 && (name[start-1] == '_' || isASCIILetter(name[start-1]) || (name[start-1] >= '0' && name[start-1] <= '9')) {
+		start--
+	}
+	if start == len(name) || (name[start] >= '0' && name[start] <= '9') {
+		return false
+	}
+	return start == 0 || name[start-1] == ' ' || name[start-1] == '\t' || name[start-1] == ';'
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
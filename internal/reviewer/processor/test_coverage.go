@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+var _ interfaces.FindingProcessor = (*TestCoverage)(nil)
+
+// TestCoverage flags exported entities added or modified in a source file that are not referenced
+// in its test files, test files are found by name conventions of the file language
+type TestCoverage struct {
+	analyzer *analyze.TestImpactAnalyzer
+	log      logze.Logger
+}
+
+// NewTestCoverage creates a processor for test coverage of changed entities,
+// conventions are test file names by language in analyze.DefaultTestFileConventions format
+func NewTestCoverage(provider interfaces.CodeProvider, conventions map[string][]string) *TestCoverage {
+	return &TestCoverage{
+		analyzer: analyze.NewTestImpactAnalyzer(provider, conventions),
+		log:      logze.With("component", "test-coverage-processor"),
+	}
+}
+
+// Process appends a medium priority finding for every changed exported entity without a reference in tests
+func (p *TestCoverage) Process(ctx context.Context, request model.ReviewRequest, fileDiff *model.FileDiff, findings []*model.ReviewAIComment) ([]*model.ReviewAIComment, error) {
+	impact, untested, err := p.analyzer.Analyze(ctx, request, fileDiff)
+	if err != nil {
+		model.ContextLogger(ctx, p.log).Debug("failed to analyze test impact", "file", fileDiff.NewPath, "error", err)
+		return findings, nil
+	}
+	if !impact.RequiresNewTests {
+		return findings, nil
+	}
+
+	testFiles := "`" + strings.Join(impact.AffectedTestFiles, "`, `") + "`"
+	for _, entity := range untested {
+		findings = append(findings, &model.ReviewAIComment{
+			FilePath:    fileDiff.NewPath,
+			Line:        entity.StartLine,
+			IssueType:   model.IssueTypeOther,
+			Confidence:  model.ConfidenceMedium,
+			Priority:    model.ReviewPriorityMedium,
+			Title:       fmt.Sprintf("Exported %s `%s` is not covered by tests", entity.Type, entity.Name),
+			Description: fmt.Sprintf("Exported %s `%s` is %s, but it is not referenced in %s.", entity.Type, entity.Name, entity.ChangeType, testFiles),
+			Suggestion:  impact.TestingStrategy,
+		})
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,85 @@
+package reviewer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+// promptSecurityFindings returns SQL injections found by the static scanner in added lines of the file for its
+// review prompt, the model confirms them; secrets are reported by their processor and never get to the prompt
+func (s *Reviewer) promptSecurityFindings(bundle *reviewBundle, change *model.FileDiff) []prompts.SecurityFinding {
+	if change.IsDeleted {
+		return nil
+	}
+
+	var findings []prompts.SecurityFinding
+	for _, finding := range s.securityScanner.ScanDiff(change.NewPath, change.Diff) {
+		if finding.Type != analyze.SecurityFindingSQLInjection {
+			continue
+		}
+		findings = append(findings, prompts.SecurityFinding{
+			Type:        string(finding.Type),
+			Line:        finding.Line,
+			Severity:    finding.Severity,
+			Code:        bundle.redact(change.NewPath, finding.Code),
+			Description: finding.Description,
+		})
+	}
+	return findings
+}
+
+// promptConcurrencyHints returns suspicious concurrent patterns in added lines of the Go file for its review prompt,
+// loop variable captures are checked only if the go directive of the module is before 1.22
+func (s *Reviewer) promptConcurrencyHints(ctx context.Context, bundle *reviewBundle, request model.ReviewRequest, change *model.FileDiff) []prompts.ConcurrencyHint {
+	if change.IsDeleted || !strings.EqualFold(filepath.Ext(change.NewPath), ".go") {
+		return nil
+	}
+
+	content, err := analyze.FetchFileContent(ctx, s.provider, request.ProjectID, change.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		bundle.log.DebugIf(s.cfg.Verbose, "failed to get file content for concurrency hints", "file", change.NewPath, "error", err)
+		return nil
+	}
+	goVersion, err := s.style.GoVersion(ctx, request, change.NewPath)
+	if err != nil {
+		bundle.log.DebugIf(s.cfg.Verbose, "go version is unknown for concurrency hints", "file", change.NewPath, "error", err)
+	}
+
+	var hints []prompts.ConcurrencyHint
+	for _, hint := range analyze.FindConcurrencyHints(change.NewPath, content, change.Diff, goVersion) {
+		hints = append(hints, prompts.ConcurrencyHint{
+			Type:        string(hint.Type),
+			Line:        hint.Line,
+			Code:        bundle.redact(change.NewPath, hint.Code),
+			Description: hint.Description,
+		})
+	}
+	return hints
+}
+
+// promptCallers returns snippets of unchanged functions calling functions changed in the file for its review prompt,
+// their code is redacted like the diff
+func (s *Reviewer) promptCallers(ctx context.Context, bundle *reviewBundle, request model.ReviewRequest, change *model.FileDiff) []prompts.CallerSnippet {
+	snippets, err := s.semantic.FindCallerSnippets(ctx, request, change)
+	if err != nil {
+		bundle.log.DebugIf(s.cfg.Verbose, "failed to find callers", "file", change.NewPath, "error", err)
+		return nil
+	}
+
+	var callers []prompts.CallerSnippet
+	for _, snippet := range snippets {
+		callers = append(callers, prompts.CallerSnippet{
+			Label:     snippet.Relevance,
+			Name:      snippet.EntityName,
+			Path:      snippet.FilePath,
+			CallLines: snippet.LineNumbers,
+			Snippet:   bundle.redact(snippet.FilePath, snippet.CodeSnippet),
+		})
+	}
+	return callers
+}
@@ -0,0 +1,124 @@
+package reviewer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+)
+
+// rawFindingsEntry is a cached model result of one file, fields except Result are the fingerprint of the generation
+type rawFindingsEntry struct {
+	ProjectID       string                  `json:"project_id"`
+	SHA             string                  `json:"sha"`
+	File            string                  `json:"file"`
+	Model           string                  `json:"model"`
+	PromptVersion   string                  `json:"prompt_version"`
+	AnalysisVersion string                  `json:"analysis_version"`
+	GuidanceHash    string                  `json:"guidance_hash"`
+	DiffHash        string                  `json:"diff_hash"`
+	Result          *model.FileReviewResult `json:"result"`
+}
+
+// generateFindings returns findings of the model for the file, with the raw findings cache they are saved
+// after generation or taken from the cache in replay mode
+func (s *Reviewer) generateFindings(ctx context.Context, bundle *reviewBundle, request model.ReviewRequest, change *model.FileDiff, guidance prompts.ReviewGuidance) (*model.FileReviewResult, error) {
+	if s.cfg.RawFindings.Dir == "" {
+		return s.performBasicReview(ctx, bundle, request, change, guidance)
+	}
+
+	entry := rawFindingsEntry{
+		ProjectID:       request.ProjectID,
+		SHA:             request.MergeRequest.SHA,
+		File:            change.NewPath,
+		Model:           s.agent.ModelName(),
+		PromptVersion:   prompts.PromptVersion,
+		AnalysisVersion: model.AnalysisVersion,
+		GuidanceHash: hashKey(guidance.Rules, guidance.Instructions, string(guidance.Strictness), guidance.Intent,
+			fmt.Sprint(guidance.ConfigChanges), fmt.Sprint(guidance.SecurityFindings), fmt.Sprint(guidance.ConcurrencyHints),
+			fmt.Sprint(guidance.Callers)),
+		DiffHash: hashKey(change.Diff),
+	}
+	path := s.rawFindingsPath(entry)
+
+	if s.cfg.RawFindings.Replay {
+		result, err := loadRawFindings(path, entry)
+		if err != nil {
+			return nil, errm.Wrap(err, "failed to load cached raw findings", "file", change.NewPath)
+		}
+		bundle.log.DebugIf(s.cfg.Verbose, "using cached raw findings", "file", change.NewPath)
+		return result, nil
+	}
+
+	result, err := s.performBasicReview(ctx, bundle, request, change, guidance)
+	if err != nil {
+		return nil, err
+	}
+	entry.Result = result
+	if err := saveRawFindings(path, entry); err != nil {
+		bundle.log.Warn("failed to cache raw findings", "error", err, "file", change.NewPath)
+	}
+	return result, nil
+}
+
+// rawFindingsPath returns the cache file of the entry: a directory per project, head commit and versions
+// named by hash of them and a file named by hash of the file path, so any ID and path are safe names
+func (s *Reviewer) rawFindingsPath(entry rawFindingsEntry) string {
+	key := hashKey(entry.ProjectID, entry.SHA, entry.PromptVersion, entry.AnalysisVersion)
+	return filepath.Join(s.cfg.RawFindings.Dir, key, hashKey(entry.File)+".json")
+}
+
+// loadRawFindings reads the cached result, it returns error if there is no entry or it is stale
+func loadRawFindings(path string, want rawFindingsEntry) (*model.FileReviewResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry rawFindingsEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, errm.Wrap(err, "failed to parse cache entry")
+	}
+	if entry.Result == nil {
+		return nil, errm.New("cache entry has no result")
+	}
+
+	result := entry.Result
+	entry.Result = nil
+	if entry != want {
+		return nil, errm.Errorf("cache entry is stale, model %q prompt version %q analysis version %q", entry.Model, entry.PromptVersion, entry.AnalysisVersion)
+	}
+	return result, nil
+}
+
+// saveRawFindings writes the entry through a temporary file, so a concurrent replay never reads a partial entry
+func saveRawFindings(path string, entry rawFindingsEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errm.Wrap(err, "failed to marshal cache entry")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errm.Wrap(err, "failed to create cache directory")
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return errm.Wrap(err, "failed to write cache entry")
+	}
+	return os.Rename(tmp, path)
+}
+
+// hashKey returns hex SHA-256 of the parts separated by zero bytes, so different splits don't collide
+func hashKey(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
@@ -0,0 +1,50 @@
+package reviewer
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// forgetReviewedFiles removes files of the merge request reviewed at any head or commit,
+// so they are reviewed again; the review records them for its head as usual
+func (s *Reviewer) forgetReviewedFiles(request model.ReviewRequest) {
+	prefix := request.ProjectID + ":"
+	suffix := ":" + strconv.Itoa(request.MergeRequest.IID)
+	for _, key := range s.processedMRs.OuterKeys() {
+		if strings.HasPrefix(key, prefix) && strings.HasSuffix(key, suffix) {
+			s.processedMRs.DeleteMap(key)
+		}
+	}
+}
+
+// collectPostedLines remembers lines of unresolved inline findings posted by previous reviews for a full review,
+// e.g. findings of the draft stage, so findings of the same lines are reconciled instead of duplicated
+func (s *Reviewer) collectPostedLines(ctx context.Context, bundle *reviewBundle) {
+	if !bundle.fullReview {
+		return
+	}
+	request := bundle.request
+
+	comments, err := s.provider.GetComments(ctx, request.ProjectID, request.MergeRequest.IID)
+	if err != nil {
+		bundle.log.Warn("failed to get comments of previous reviews", "error", err)
+		return
+	}
+
+	bundle.postedLines = make(map[string]bool)
+	for _, comment := range comments {
+		// Findings are recognized by the comment footer and the author
+		if comment.Type != model.CommentTypeInline || comment.Resolved || comment.Outdated || comment.Line == 0 || !s.isOwnFinding(comment) {
+			continue
+		}
+		bundle.postedLines[postedLineKey(comment.FilePath, comment.Line)] = true
+	}
+	bundle.log.DebugIf(s.cfg.Verbose, "collected lines of previous findings", "count", len(bundle.postedLines))
+}
+
+func postedLineKey(filePath string, line int) string {
+	return filePath + ":" + strconv.Itoa(line)
+}
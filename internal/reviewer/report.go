@@ -0,0 +1,237 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// isExternalReportEnabled checks if huge reviews can be published as an external document
+func (s *Reviewer) isExternalReportEnabled() bool {
+	if !s.cfg.ExternalReport.Enable {
+		return false
+	}
+	_, ok := s.provider.(interfaces.DocumentPublisher)
+	return ok
+}
+
+// collectsFindings checks if findings are posted after all files are reviewed instead of right after each file
+func (s *Reviewer) collectsFindings() bool {
+	return s.isExternalReportEnabled() || s.cfg.CommentMode != CommentModeInline || !s.cfg.FindingClusters.Disable
+}
+
+// postCollectedFindings posts findings collected during code review: the same issue reported in many files is collapsed
+// into one finding, if there are more findings than the threshold, all of them are published as an external document
+// and only the most important ones are posted inline
+func (s *Reviewer) postCollectedFindings(ctx context.Context, bundle *reviewBundle) {
+	findings := bundle.findings
+	bundle.findings = nil
+	if !s.cfg.FindingClusters.Disable {
+		findings = clusterFindings(findings, s.cfg.FindingClusters.Threshold)
+	}
+	if s.cfg.CommentMode != CommentModeInline {
+		s.postConsolidatedFindings(ctx, bundle, findings)
+		return
+	}
+	if len(findings) == 0 {
+		return
+	}
+
+	if !s.isExternalReportEnabled() || len(findings) <= s.cfg.ExternalReport.Threshold {
+		bundle.result.CommentsCreated += s.postReviewComments(ctx, bundle, bundle.request, findings)
+		return
+	}
+
+	url, err := s.publishReport(ctx, bundle, findings)
+	if err != nil {
+		bundle.log.Warn("failed to publish external report, posting findings inline", "error", err)
+		bundle.result.CommentsCreated += s.postReviewComments(ctx, bundle, bundle.request, findings)
+		return
+	}
+
+	top := topFindings(findings, s.cfg.ExternalReport.TopInline)
+	// Findings of the report are posted, the top ones are counted when they are posted inline
+	for _, finding := range findings {
+		if !slices.Contains(top, finding) {
+			bundle.result.FindingsByPriority[finding.Priority]++
+		}
+	}
+	if err := s.createOrUpdateReportComment(ctx, bundle.request, s.buildReportLinkComment(url, len(findings), len(top))); err != nil {
+		msg := "failed to create external report comment"
+		bundle.log.Err(err, msg)
+		bundle.result.Errors = append(bundle.result.Errors, errm.Wrap(err, msg))
+	}
+	bundle.result.CommentsCreated += s.postReviewComments(ctx, bundle, bundle.request, top)
+
+	bundle.log.InfoIf(s.cfg.Verbose, "published external report", "url", url, "findings", len(findings), "inline", len(top))
+}
+
+// publishReport publishes all findings with the changes overview as a markdown document
+func (s *Reviewer) publishReport(ctx context.Context, bundle *reviewBundle, findings []*model.ReviewAIComment) (string, error) {
+	publisher, ok := s.provider.(interfaces.DocumentPublisher)
+	if !ok {
+		return "", errm.New("provider does not support external documents")
+	}
+
+	mr := bundle.request.MergeRequest
+	title := fmt.Sprintf("Codry review of %s #%d: %s", bundle.request.ProjectID, mr.IID, mr.Title)
+
+	url, err := publisher.PublishDocument(ctx, title, s.buildReportDocument(title, bundle, findings))
+	if err != nil {
+		return "", errm.Wrap(err, "failed to publish document")
+	}
+
+	return url, nil
+}
+
+// buildReportDocument renders findings grouped by file after the changes overview table
+func (s *Reviewer) buildReportDocument(title string, bundle *reviewBundle, findings []*model.ReviewAIComment) string {
+	var sb strings.Builder
+
+	sb.WriteString("# ")
+	sb.WriteString(title)
+	sb.WriteString("\n\n")
+	if bundle.request.MergeRequest.URL != "" {
+		sb.WriteString(bundle.request.MergeRequest.URL)
+		sb.WriteString("\n\n")
+	}
+
+	counts := make(map[model.ReviewPriority]int)
+	for _, finding := range findings {
+		counts[finding.Priority]++
+	}
+	sb.WriteString(s.buildPriorityTable(counts))
+	sb.WriteString("\n")
+
+	if bundle.changesOverview != "" {
+		sb.WriteString(bundle.changesOverview)
+		sb.WriteString("\n\n")
+	}
+
+	files, byFile := groupFindingsByFile(findings)
+	for _, file := range files {
+		sb.WriteString("## ")
+		sb.WriteString(findingsFileLabel(file))
+		sb.WriteString("\n\n")
+		for _, finding := range byFile[file] {
+			if finding.Line > 0 {
+				sb.WriteString(fmt.Sprintf("**Line %d**\n\n", finding.Line))
+			}
+			comment := s.reviewToComment(finding)
+			sb.WriteString(demoteHeadings(comment.Body))
+			sb.WriteString("\n\n---\n\n")
+		}
+	}
+	if out := bundle.result.OutOfScopeFiles; len(out) > 0 {
+		sb.WriteString("## Files out of the ownership scope\n\n")
+		sb.WriteString("These files are reviewed by other instances, their findings are not in this report.\n\n")
+		for _, file := range out {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", file))
+		}
+		sb.WriteString("\n")
+	}
+	if note := s.filteredFindingsNote(bundle); note != "" {
+		sb.WriteString(note)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("_Prompt version %s, analysis version %s_\n", prompts.PromptVersion, model.AnalysisVersion))
+
+	return sb.String()
+}
+
+// buildReportLinkComment renders the comment with the link to the external report
+func (s *Reviewer) buildReportLinkComment(url string, total, inline int) string {
+	var sb strings.Builder
+
+	sb.WriteString("## 📋 Full review report\n\n")
+	sb.WriteString(fmt.Sprintf("Review found %d issues, they are collected in the [report](%s) to keep the discussion readable.", total, url))
+	if inline > 0 {
+		sb.WriteString(fmt.Sprintf(" The %d most important of them are posted inline.", inline))
+	}
+
+	return sb.String()
+}
+
+// createOrUpdateReportComment creates the report link comment or updates the existing one from the previous review
+func (s *Reviewer) createOrUpdateReportComment(ctx context.Context, request model.ReviewRequest, body string) error {
+	return s.upsertMarkedComment(ctx, request, startMarkerReport, endMarkerReport, body, true)
+}
+
+// upsertMarkedComment updates the general comment wrapped with the markers by the previous review,
+// a new comment is created if there is no such comment and create is true
+func (s *Reviewer) upsertMarkedComment(ctx context.Context, request model.ReviewRequest, startMarker, endMarker, body string, create bool) error {
+	wrappedContent := startMarker + "\n" + body + "\n" + endMarker
+
+	comments, err := s.provider.GetComments(ctx, request.ProjectID, request.MergeRequest.IID)
+	if err != nil {
+		return errm.Wrap(err, "failed to get comments")
+	}
+
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, startMarker) && strings.Contains(comment.Body, endMarker) && s.isBotComment(comment) {
+			err = s.provider.UpdateComment(ctx, request.ProjectID, request.MergeRequest.IID, comment.ID, wrappedContent)
+			if err != nil {
+				return errm.Wrap(err, "failed to update existing comment")
+			}
+			return nil
+		}
+	}
+	if !create {
+		return nil
+	}
+
+	err = s.provider.CreateComment(ctx, request.ProjectID, request.MergeRequest.IID, &model.Comment{
+		Body: wrappedContent,
+		Type: model.CommentTypeGeneral,
+	})
+	if err != nil {
+		return errm.Wrap(err, "failed to create comment")
+	}
+
+	return nil
+}
+
+// topFindings returns up to limit findings with the highest priority, order of equal findings is kept
+func topFindings(findings []*model.ReviewAIComment, limit int) []*model.ReviewAIComment {
+	if limit <= 0 {
+		return nil
+	}
+
+	sorted := slices.Clone(findings)
+	slices.SortStableFunc(sorted, func(a, b *model.ReviewAIComment) int {
+		switch {
+		case a.Priority == b.Priority:
+			return 0
+		case a.Priority.IsAtLeast(b.Priority):
+			return -1
+		}
+		return 1
+	})
+
+	return sorted[:min(limit, len(sorted))]
+}
+
+// demoteHeadings adds one level to markdown headings outside of code blocks,
+// so comment sections are nested under file sections of the report
+func demoteHeadings(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if !inCode && strings.HasPrefix(line, "#") {
+			lines[i] = "#" + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,94 @@
+package reviewer
+
+import (
+	"context"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+)
+
+// resolveAddressedComments resolves threads of findings posted by previous reviews whose lines were changed
+// since, the author is considered to have addressed them; findings are recognized by the comment footer
+func (s *Reviewer) resolveAddressedComments(ctx context.Context, bundle *reviewBundle) {
+	resolver, ok := s.provider.(interfaces.CommentResolver)
+	if !s.cfg.ResolveAddressedComments || !ok {
+		return
+	}
+	request := bundle.request
+
+	comments, err := s.provider.GetComments(ctx, request.ProjectID, request.MergeRequest.IID)
+	if err != nil {
+		bundle.log.Warn("failed to get comments to resolve addressed findings", "error", err)
+		return
+	}
+
+	changedLines := make(map[string]map[string]map[int]bool) // by commit and old path
+	var resolved int
+	for _, comment := range comments {
+		if ctx.Err() != nil {
+			return
+		}
+		if comment.Type != model.CommentTypeInline || comment.Resolved || !s.isOwnFinding(comment) {
+			continue
+		}
+		if !comment.Outdated && !s.isLineChanged(ctx, bundle, comment, changedLines) {
+			continue
+		}
+
+		if err := resolver.ResolveComment(ctx, request.ProjectID, request.MergeRequest.IID, comment); err != nil {
+			if !errm.Is(err, model.ErrNotSupported) {
+				bundle.log.Warn("failed to resolve addressed finding", "error", err, "comment_id", comment.ID)
+			}
+			continue
+		}
+		resolved++
+	}
+
+	if resolved > 0 {
+		bundle.log.Info("resolved addressed findings", "count", resolved)
+	}
+}
+
+// isLineChanged checks if the commented line was changed between the commit of the comment and the head,
+// diffs are compared once per commit; it is false if the provider can't compare commits
+func (s *Reviewer) isLineChanged(ctx context.Context, bundle *reviewBundle, comment *model.Comment, changedLines map[string]map[string]map[int]bool) bool {
+	head := bundle.request.MergeRequest.SHA
+	comparer, ok := s.provider.(interfaces.CommitComparer)
+	if !ok || comment.CommitSHA == "" || comment.CommitSHA == head || comment.Line == 0 {
+		return false
+	}
+
+	byPath, ok := changedLines[comment.CommitSHA]
+	if !ok {
+		diffs, err := comparer.CompareCommits(ctx, bundle.request.ProjectID, comment.CommitSHA, head)
+		if err != nil {
+			bundle.log.DebugIf(s.cfg.Verbose, "failed to compare commit of the comment with head", "error", err, "commit_sha", comment.CommitSHA)
+		}
+		byPath = make(map[string]map[int]bool, len(diffs))
+		for _, diff := range diffs {
+			if diff.IsDeleted {
+				byPath[diff.OldPath] = nil // every line is changed
+				continue
+			}
+			byPath[diff.OldPath] = changedOldLines(diff)
+		}
+		changedLines[comment.CommitSHA] = byPath
+	}
+
+	lines, ok := byPath[comment.FilePath]
+	return ok && (lines == nil || lines[comment.Line])
+}
+
+// changedOldLines returns numbers of lines of the old file that were removed or replaced in the diff
+func changedOldLines(fileDiff *model.FileDiff) map[int]bool {
+	lines := make(map[int]bool)
+	for _, hunk := range fileDiff.DiffHunks() {
+		for _, line := range hunk.Lines {
+			if line.Kind == model.DiffLineRemoved {
+				lines[line.OldLine] = true
+			}
+		}
+	}
+	return lines
+}
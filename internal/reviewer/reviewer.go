@@ -2,42 +2,251 @@ package reviewer
 
 import (
 	"context"
+	"maps"
+	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 
 	"github.com/maxbolgarin/abstract"
 	"github.com/maxbolgarin/codry/internal/agent"
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/metrics"
 	"github.com/maxbolgarin/codry/internal/model"
 	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/codry/internal/reviewer/processor"
 	"github.com/maxbolgarin/errm"
 	"github.com/maxbolgarin/logze/v2"
-	"github.com/panjf2000/ants/v2"
 )
 
 // Reviewer implements the ReviewService interface
 type Reviewer struct {
 	provider interfaces.CodeProvider
 	agent    *agent.Agent
-	pool     *ants.Pool
 	parser   *diffParser
 
+	architectureInput *analyze.ArchitectureInputAssembler
+	semantic          *analyze.SemanticAnalyzer     // finds callers for review prompts and ranks files of large merge requests
+	securityScanner   *analyze.SecurityScanner      // finds security issues for review prompts and to rank files of large merge requests
+	style             *analyze.ProjectStyleAnalyzer // finds go.mod of changed files for review prompts
+	processors        []interfaces.FindingProcessor
+	redactionPatterns []*regexp.Regexp
+	suppression       *analyze.SuppressionSyntax // nil if inline suppression is disabled
+	rules             []analyze.RuleSection      // sections of configured rules documents
+	configAnalyzer    *analyze.ConfigAnalyzer
+	configUsages      *analyze.ConfigUsageFinder
+
+	commentTemplates        *commentTemplates
+	defaultCommentTemplates *commentTemplates // used if a custom template fails to render
+
 	cfg Config
 	log logze.Logger
 
-	// Track processed MRs and reviewed files
+	metrics         interfaces.MetricsRecorder
+	metricsProvider string
+	// notifier sends reports of finished reviews, it is nil if notifications are disabled
+	notifier interfaces.Notifier
+
+	// Track processed MRs and reviewed files, the key includes the head SHA, so a new head
+	// (including a force-pushed one) is always reviewed in full against the target branch
 	processedMRs *abstract.SafeMapOfMaps[string, string, string]
 }
 
 // New creates a new reviewer
 func New(cfg Config, provider interfaces.CodeProvider, agent *agent.Agent) (*Reviewer, error) {
-	pool, err := ants.NewPool(100)
+	if cfg.Language == "" {
+		cfg.Language = model.LanguageEnglish
+	}
+	if cfg.DiffContext.Lines == 0 {
+		cfg.DiffContext.Lines = defaultDiffContextLines
+	}
+	if cfg.CommentMode == "" {
+		cfg.CommentMode = CommentModeInline
+	} else if !slices.Contains(supportedCommentModes, cfg.CommentMode) {
+		return nil, errm.Errorf("invalid comment mode: %s", cfg.CommentMode)
+	}
+	if cfg.ReviewScope == "" {
+		cfg.ReviewScope = ReviewScopeMergeRequest
+	} else if !slices.Contains(supportedReviewScopes, cfg.ReviewScope) {
+		return nil, errm.Errorf("invalid review scope: %s", cfg.ReviewScope)
+	}
+	if cfg.DiffSource == "" {
+		cfg.DiffSource = model.DiffSourceMergeRequest
+	} else if !slices.Contains(supportedDiffSources, cfg.DiffSource) {
+		return nil, errm.Errorf("invalid diff source: %s", cfg.DiffSource)
+	}
+	if cfg.CheckRun.Name == "" {
+		cfg.CheckRun.Name = defaultCheckRunName
+	}
+	if cfg.CheckRun.FailurePriority == "" {
+		cfg.CheckRun.FailurePriority = model.ReviewPriorityCritical
+	} else if !cfg.CheckRun.FailurePriority.IsValid() {
+		return nil, errm.Errorf("invalid check run failure priority: %s", cfg.CheckRun.FailurePriority)
+	}
+	if cfg.Labels.Skip == "" {
+		cfg.Labels.Skip = defaultSkipLabel
+	}
+	if cfg.Labels.Outcome.ChangesRequested == "" {
+		cfg.Labels.Outcome.ChangesRequested = defaultChangesRequestedLabel
+	}
+	if cfg.Labels.Outcome.Approved == "" {
+		cfg.Labels.Outcome.Approved = defaultApprovedLabel
+	}
+	if cfg.Labels.Outcome.Priority == "" {
+		cfg.Labels.Outcome.Priority = model.ReviewPriorityHigh
+	}
+	if cfg.Footer.Template == "" {
+		cfg.Footer.Template = defaultFooterTemplate
+	}
+	if cfg.ExternalReport.Threshold <= 0 {
+		cfg.ExternalReport.Threshold = defaultExternalReportThreshold
+	}
+	if cfg.ExternalReport.TopInline < 0 {
+		cfg.ExternalReport.TopInline = 0
+	} else if cfg.ExternalReport.TopInline == 0 {
+		cfg.ExternalReport.TopInline = defaultExternalReportTopInline
+	}
+	if cfg.FindingClusters.Threshold <= 0 {
+		cfg.FindingClusters.Threshold = defaultFindingClustersThreshold
+	}
+	if cfg.Instructions.MaxLength == 0 {
+		cfg.Instructions.MaxLength = defaultInstructionsMaxLength
+	}
+	if cfg.Intent.MaxLength == 0 {
+		cfg.Intent.MaxLength = defaultIntentMaxLength
+	}
+	if cfg.Rules.MaxLength == 0 {
+		cfg.Rules.MaxLength = defaultRulesMaxLength
+	}
+	if cfg.Timeouts.Review == 0 {
+		cfg.Timeouts.Review = defaultReviewTimeout
+	}
+	if cfg.Timeouts.Description == 0 {
+		cfg.Timeouts.Description = defaultDescriptionTimeout
+	}
+	if cfg.Timeouts.ChangesOverview == 0 {
+		cfg.Timeouts.ChangesOverview = defaultChangesOverviewTimeout
+	}
+	if cfg.Timeouts.ArchitectureReview == 0 {
+		cfg.Timeouts.ArchitectureReview = defaultArchitectureReviewTimeout
+	}
+	if cfg.Timeouts.CodeReview == 0 {
+		cfg.Timeouts.CodeReview = defaultCodeReviewTimeout
+	}
+	if cfg.Timeouts.Verdict == 0 {
+		cfg.Timeouts.Verdict = defaultVerdictTimeout
+	}
+	if cfg.Severity.Badges == nil {
+		cfg.Severity.Badges = maps.Clone(defaultSeverityBadges)
+	}
+	cfg.FileFilter.AnalyzeExtensions = normalizeExtensions(cfg.FileFilter.AnalyzeExtensions)
+	cfg.FileFilter.IgnoreExtensions = normalizeExtensions(cfg.FileFilter.IgnoreExtensions)
+	if cfg.Processors.ErrorChecks.Ignore == nil {
+		cfg.Processors.ErrorChecks.Ignore = slices.Clone(processor.DefaultIgnoredErrorCalls)
+	}
+	testConventions := maps.Clone(analyze.DefaultTestFileConventions)
+	maps.Copy(testConventions, cfg.Processors.TestCoverage.Conventions)
+	cfg.Processors.TestCoverage.Conventions = testConventions
+	if cfg.Processors.Naming.Initialisms == nil {
+		cfg.Processors.Naming.Initialisms = slices.Clone(analyze.DefaultInitialisms)
+	}
+	if cfg.Processors.Secrets.Allowlist == nil {
+		cfg.Processors.Secrets.Allowlist = slices.Clone(analyze.DefaultSecretsAllowlist)
+	}
+	if cfg.Processors.FunctionSize.MaxLines == 0 {
+		cfg.Processors.FunctionSize.MaxLines = processor.DefaultFunctionLimits.FuncLength
+	}
+	if cfg.Processors.FunctionSize.MaxParams == 0 {
+		cfg.Processors.FunctionSize.MaxParams = processor.DefaultFunctionLimits.FuncParams
+	}
+	if cfg.Redaction.Allowlist == nil {
+		cfg.Redaction.Allowlist = slices.Clone(analyze.DefaultSecretsAllowlist)
+	}
+	redactionPatterns := make([]*regexp.Regexp, 0, len(cfg.Redaction.Patterns))
+	for _, pattern := range cfg.Redaction.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errm.Wrap(err, "invalid redaction pattern", "pattern", pattern)
+		}
+		redactionPatterns = append(redactionPatterns, re)
+	}
+
+	if cfg.Artifacts.MaxTextSize == 0 {
+		cfg.Artifacts.MaxTextSize = defaultArtifactsMaxTextSize
+	}
+	if cfg.Triage.MaxReviewTokens == 0 {
+		cfg.Triage.MaxReviewTokens = defaultTriageMaxReviewTokens
+	}
+	if cfg.Triage.Weights == (analyze.ChangeRiskWeights{}) {
+		cfg.Triage.Weights = analyze.DefaultChangeRiskWeights
+	}
+
+	if cfg.Search.ExcludeDirs == nil {
+		cfg.Search.ExcludeDirs = slices.Clone(analyze.DefaultSearchExcludeDirs)
+	}
+	// Analyzers and processors search the codebase through it, the reviewer itself uses the provider
+	searchProvider := analyze.NewSearchProvider(provider, cfg.Search.ExcludeDirs)
+
+	if cfg.Callers.MaxSnippets == 0 {
+		cfg.Callers.MaxSnippets = analyze.DefaultCallerSnippets
+	}
+	if cfg.Callers.MaxTokens == 0 {
+		cfg.Callers.MaxTokens = analyze.DefaultCallerSnippetsTokens
+	}
+	if cfg.ConfigChanges.MaxKeys == 0 {
+		cfg.ConfigChanges.MaxKeys = defaultConfigChangesMaxKeys
+	}
+	if cfg.ConfigChanges.MinDependents == 0 {
+		cfg.ConfigChanges.MinDependents = defaultConfigChangesMinDependents
+	}
+	if cfg.ConfigChanges.RiskRules == nil {
+		cfg.ConfigChanges.RiskRules = slices.Clone(analyze.DefaultConfigRiskRules)
+	}
+	configAnalyzer, err := analyze.NewConfigAnalyzer(cfg.ConfigChanges.RiskRules)
 	if err != nil {
-		return nil, errm.Wrap(err, "failed to create ants pool")
+		return nil, errm.Wrap(err, "invalid config risk rules")
 	}
 
-	if cfg.Language == "" {
-		cfg.Language = model.LanguageEnglish
+	if cfg.Strictness.Level == "" {
+		cfg.Strictness.Level = prompts.StrictnessBalanced
+	}
+	preset := strictnessPresets[cfg.Strictness.Level]
+	if cfg.Strictness.MinPriority == "" {
+		cfg.Strictness.MinPriority = preset.minPriority
+	}
+	if cfg.Strictness.MinConfidence == "" {
+		cfg.Strictness.MinConfidence = preset.minConfidence
+	}
+
+	if cfg.Suppression.Directive == "" {
+		cfg.Suppression.Directive = analyze.DefaultSuppressionDirective
+	}
+	if cfg.Suppression.CommentMarkers == nil {
+		cfg.Suppression.CommentMarkers = slices.Clone(analyze.DefaultSuppressionCommentMarkers)
+	}
+	var suppression *analyze.SuppressionSyntax
+	if !cfg.Suppression.Disable {
+		suppression = analyze.NewSuppressionSyntax(cfg.Suppression.Directive, cfg.Suppression.CommentMarkers)
+	}
+
+	var rules []analyze.RuleSection
+	for _, path := range cfg.Rules.Paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errm.Wrap(err, "failed to read rules document", "path", path)
+		}
+		rules = append(rules, analyze.ParseRules(string(content))...)
+	}
+
+	defaultTemplates, err := loadCommentTemplates("")
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to load default comment templates")
+	}
+	templates, err := loadCommentTemplates(cfg.CommentTemplates.Dir)
+	if err != nil {
+		return nil, errm.Wrap(err, "failed to load comment templates")
 	}
 
 	s := &Reviewer{
@@ -45,40 +254,141 @@ func New(cfg Config, provider interfaces.CodeProvider, agent *agent.Agent) (*Rev
 		agent:        agent,
 		cfg:          cfg,
 		log:          logze.With("component", "reviewer"),
-		pool:         pool,
 		parser:       newDiffParser(),
 		processedMRs: abstract.NewSafeMapOfMaps[string, string, string](),
+		metrics:      metrics.Nop{},
+
+		commentTemplates:        templates,
+		defaultCommentTemplates: defaultTemplates,
+
+		architectureInput: analyze.NewArchitectureInputAssembler(searchProvider, cfg.MaxArchitectureInputSize),
+		semantic:          analyze.NewSemanticAnalyzer(searchProvider),
+		securityScanner:   analyze.NewSecurityScanner(cfg.Processors.Secrets.Allowlist),
+		style:             analyze.NewProjectStyleAnalyzer(searchProvider),
+		redactionPatterns: redactionPatterns,
+		suppression:       suppression,
+		rules:             rules,
+		configAnalyzer:    configAnalyzer,
+		configUsages:      analyze.NewConfigUsageFinder(searchProvider),
+	}
+
+	s.semantic.SetCallerSnippetLimits(cfg.Callers.MaxSnippets, cfg.Callers.MaxTokens)
+
+	s.RegisterFindingProcessor(processor.NewForbiddenImports(searchProvider, analyze.ImportStyle{
+		ForbiddenImports: cfg.Processors.ForbiddenImports,
+		PreferredImports: cfg.Processors.PreferredImports,
+	}))
+	if cfg.Processors.LicenseHeader != "" {
+		s.RegisterFindingProcessor(processor.NewLicenseHeader(cfg.Processors.LicenseHeader))
+	}
+	if !cfg.Processors.ErrorChecks.Disable {
+		s.RegisterFindingProcessor(processor.NewErrorChecks(searchProvider, cfg.Processors.ErrorChecks.Ignore, cfg.Processors.ErrorChecks.CheckDeferredClose))
+	}
+	if !cfg.Processors.DocComments.Disable {
+		s.RegisterFindingProcessor(processor.NewDocComments(searchProvider))
+	}
+	if !cfg.Processors.TestCoverage.Disable {
+		s.RegisterFindingProcessor(processor.NewTestCoverage(searchProvider, cfg.Processors.TestCoverage.Conventions))
+	}
+	if !cfg.Processors.FunctionSize.Disable {
+		s.RegisterFindingProcessor(processor.NewFunctionSize(searchProvider, analyze.ComplexityLimits{
+			FuncLength: cfg.Processors.FunctionSize.MaxLines,
+			FuncParams: cfg.Processors.FunctionSize.MaxParams,
+		}))
+	}
+	if !cfg.Processors.ConstantChanges.Disable {
+		s.RegisterFindingProcessor(processor.NewConstantChanges(searchProvider))
+	}
+	if !cfg.Processors.Dockerfile.Disable {
+		s.RegisterFindingProcessor(processor.NewDockerfileChecks(searchProvider, cfg.Processors.Dockerfile.Skip))
+	}
+	if !cfg.Processors.ShellScripts.Disable {
+		s.RegisterFindingProcessor(processor.NewShellScriptChecks(cfg.Processors.ShellScripts.Skip))
+	}
+	if !cfg.Processors.DebugArtifacts.Disable {
+		s.RegisterFindingProcessor(processor.NewDebugArtifacts(searchProvider, cfg.Processors.DebugArtifacts.Skip))
+	}
+	if !cfg.Processors.InterfaceDrift.Disable {
+		s.RegisterFindingProcessor(processor.NewInterfaceDrift(searchProvider))
+	}
+	if !cfg.Processors.PanicChecks.Disable {
+		s.RegisterFindingProcessor(processor.NewPanicChecks(searchProvider))
+	}
+	if !cfg.Processors.ResourceLeaks.Disable {
+		pairs := slices.Clone(analyze.DefaultResourcePairs)
+		for _, entry := range cfg.Processors.ResourceLeaks.Pairs {
+			pair, err := analyze.ParseResourcePair(entry)
+			if err != nil {
+				return nil, errm.Wrap(err, "invalid resource pair")
+			}
+			pairs = append(pairs, pair)
+		}
+		s.RegisterFindingProcessor(processor.NewResourceLeaks(searchProvider, pairs))
+	}
+	if !cfg.Processors.LoopCalls.Disable {
+		s.RegisterFindingProcessor(processor.NewLoopCalls())
+	}
+	if !cfg.Processors.SerializedFields.Disable {
+		s.RegisterFindingProcessor(processor.NewSerializedFields(searchProvider))
+	}
+	if cfg.Processors.NewDependencies.Enable {
+		s.RegisterFindingProcessor(processor.NewNewDependencies(searchProvider))
+	}
+	if !cfg.Processors.Naming.Disable {
+		s.RegisterFindingProcessor(processor.NewNamingChecks(cfg.Processors.Naming.Initialisms))
+	}
+	if !cfg.Processors.CommandInjection.Disable {
+		s.RegisterFindingProcessor(processor.NewCommandInjection(s.securityScanner))
+	}
+	// Secrets go last to redact values in findings of the model and other processors
+	if !cfg.Processors.Secrets.Disable {
+		s.RegisterFindingProcessor(processor.NewSecretsChecks(s.securityScanner))
 	}
 
 	return s, nil
 }
 
-// HandleWebhook processes incoming webhook events and routes them appropriately
+// RegisterFindingProcessor adds processors that run after the model review of every file,
+// processors run in order of registration after the built-in ones
+func (s *Reviewer) RegisterFindingProcessor(processors ...interfaces.FindingProcessor) {
+	s.processors = append(s.processors, processors...)
+}
+
+// SetMetrics sets the recorder of reviews, provider is the label of recorded metrics
+func (s *Reviewer) SetMetrics(recorder interfaces.MetricsRecorder, provider string) {
+	s.metrics = recorder
+	s.metricsProvider = provider
+}
+
+// SetNotifier sets the notifier of finished merge request reviews
+func (s *Reviewer) SetNotifier(notifier interfaces.Notifier) {
+	s.notifier = notifier
+}
+
+// HandleEvent processes the event synchronously and routes it appropriately,
+// the caller is responsible for running it in background and limiting concurrency
 func (s *Reviewer) HandleEvent(ctx context.Context, event *model.CodeEvent) error {
 	log := s.log.WithFields(
 		"event_type", event.Type,
 		"action", event.Action,
 		"project_id", event.ProjectID,
-		"user", event.User.Username,
+		"user", event.Username(),
 	)
 
 	log.Info("processing event")
 
 	switch {
 	case s.provider.IsMergeRequestEvent(event):
-		return s.pool.Submit(func() {
-			// TODO: add error handling
-			err := s.ReviewMergeRequest(ctx, event.ProjectID, event.MergeRequest)
-			if err != nil {
-				log.Error("error processing merge request event", "error", err)
-			}
-		})
+		// Draft could be reviewed before, the author expects a complete review when it is ready
+		fullReview := event.Action == model.ActionReadyForReview
+		if err := s.reviewMergeRequest(ctx, event.ProjectID, event.MergeRequest, fullReview); err != nil {
+			return errm.Wrap(err, "failed to review merge request")
+		}
+		return nil
 
 	// case s.provider.IsCommentEvent(event):
-	// 	return s.pool.Submit(func() {
-	// 		// TODO: add error handling
-	// 		s.processCommentEvent(ctx, event, log)
-	// 	})
+	// 	s.processCommentEvent(ctx, event, log)
+	// 	return nil
 
 	default:
 		log.Debug("unhandled webhook event type")
@@ -86,6 +396,48 @@ func (s *Reviewer) HandleEvent(ctx context.Context, event *model.CodeEvent) erro
 	}
 }
 
+// isReviewAllowedByLabels checks skip and require labels of the merge request,
+// it returns the reason if the review is not allowed
+func (s *Reviewer) isReviewAllowedByLabels(mr *model.MergeRequest) (bool, string) {
+	if hasLabel(mr, s.cfg.Labels.Skip) {
+		return false, "merge request has skip label " + s.cfg.Labels.Skip
+	}
+	if s.cfg.Labels.Require != "" && !hasLabel(mr, s.cfg.Labels.Require) {
+		return false, "merge request doesn't have required label " + s.cfg.Labels.Require
+	}
+	return true, ""
+}
+
+// hasLabel checks labels of the merge request and falls back to a [label] marker in its description
+func hasLabel(mr *model.MergeRequest, label string) bool {
+	if label == "" {
+		return false
+	}
+	if mr.HasLabel(label) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(mr.Description), "["+strings.ToLower(label)+"]")
+}
+
+// isOwnFinding checks if the comment is a finding posted by the bot, findings are recognized by the footer
+func (s *Reviewer) isOwnFinding(comment *model.Comment) bool {
+	return comment.Footer != "" && s.isBotComment(comment)
+}
+
+// isBotComment checks the author of the comment by the bot account resolved by the provider,
+// the comment is considered the bot's one if the account or the author is unknown
+func (s *Reviewer) isBotComment(comment *model.Comment) bool {
+	identifier, ok := s.provider.(interfaces.BotIdentifier)
+	if !ok {
+		return true
+	}
+	bot, ok := identifier.BotUser()
+	if !ok || (comment.Author.ID == "" && comment.Author.Username == "") {
+		return true
+	}
+	return comment.Author.IsBot(&bot, "")
+}
+
 func (s *Reviewer) isCodeFile(filePath string) bool {
 	if s.cfg.FileFilter.IncludeOnlyCode {
 		ext := strings.ToLower(filepath.Ext(filePath))
@@ -94,8 +446,40 @@ func (s *Reviewer) isCodeFile(filePath string) bool {
 	return true
 }
 
+// isAnalyzedExtension checks the file against analyze and ignore extension lists, ignore list wins
+func (s *Reviewer) isAnalyzedExtension(filePath string) bool {
+	filePath = strings.ToLower(filePath)
+	hasExt := func(ext string) bool { return strings.HasSuffix(filePath, ext) }
+
+	if slices.ContainsFunc(s.cfg.FileFilter.IgnoreExtensions, hasExt) {
+		return false
+	}
+	return len(s.cfg.FileFilter.AnalyzeExtensions) == 0 || slices.ContainsFunc(s.cfg.FileFilter.AnalyzeExtensions, hasExt)
+}
+
+// normalizeExtensions lowercases extensions and adds the leading dot, so md and .MD are the same
+func normalizeExtensions(extensions []string) []string {
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		normalized = append(normalized, ext)
+	}
+	return normalized
+}
+
 func (s *Reviewer) isExcludedPath(filePath string) bool {
-	for _, pattern := range s.cfg.FileFilter.ExcludedPaths {
+	return matchesPath(filePath, s.cfg.FileFilter.ExcludedPaths)
+}
+
+// matchesPath checks if the path matches any glob pattern or contains any pattern as a substring
+func matchesPath(filePath string, patterns []string) bool {
+	for _, pattern := range patterns {
 		if matched, _ := filepath.Match(pattern, filePath); matched {
 			return true
 		}
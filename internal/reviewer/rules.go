@@ -0,0 +1,63 @@
+package reviewer
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+	"github.com/maxbolgarin/logze/v2"
+)
+
+// reviewRules returns sections of configured rules documents and docs/codry-rules.md of the target branch,
+// they are loaded once per review
+func (s *Reviewer) reviewRules(ctx context.Context, request model.ReviewRequest, log logze.Logger) []analyze.RuleSection {
+	rules := slices.Clone(s.rules)
+	if !s.cfg.Rules.DisableRepoRules {
+		repoRules, err := analyze.LoadRepoRules(ctx, s.provider, request.ProjectID, request.MergeRequest.TargetBranch)
+		if err != nil {
+			log.DebugIf(s.cfg.Verbose, "repository rules are not loaded", "error", err)
+		}
+		rules = append(rules, repoRules...)
+	}
+	if len(rules) > 0 {
+		log.InfoIf(s.cfg.Verbose, "using project rules", "sections", len(rules))
+	}
+	return rules
+}
+
+// fileRules returns rules of the review for the file, the text is cut to the configured length
+func (s *Reviewer) fileRules(bundle *reviewBundle, filePath string) string {
+	sections := analyze.SelectRules(bundle.rules, filePath, detectProgrammingLanguage(filePath))
+	if len(sections) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		parts = append(parts, section.Content)
+	}
+	rules := strings.Join(parts, "\n\n")
+	if utf8.RuneCountInString(rules) > s.cfg.Rules.MaxLength {
+		bundle.log.Warn("project rules are too long, cutting them", "file", filePath, "length", utf8.RuneCountInString(rules), "limit", s.cfg.Rules.MaxLength)
+		rules = string([]rune(rules)[:s.cfg.Rules.MaxLength])
+	}
+	return rules
+}
+
+// boostRuleFindings raises priorities of findings citing a known rule by one level, a violation of an agreed
+// standard is more relevant than a general suggestion; critical findings are not changed
+func boostRuleFindings(findings []*model.ReviewAIComment, rules []analyze.RuleSection) {
+	for _, finding := range findings {
+		if finding.Rule == "" || !slices.ContainsFunc(rules, func(section analyze.RuleSection) bool {
+			return strings.EqualFold(strings.TrimSpace(finding.Rule), section.Heading)
+		}) {
+			continue
+		}
+		if i := slices.Index(weightedPriorities, finding.Priority); i >= 0 {
+			finding.Priority = weightedPriorities[min(i+1, len(weightedPriorities)-1)]
+		}
+	}
+}
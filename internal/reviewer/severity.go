@@ -0,0 +1,121 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+var defaultSeverityBadges = map[model.ReviewPriority]string{
+	model.ReviewPriorityCritical: "🔴 Critical",
+	model.ReviewPriorityHigh:     "🟠 High",
+	model.ReviewPriorityMedium:   "🟡 Medium",
+	model.ReviewPriorityBacklog:  "⚪ Backlog",
+}
+
+// severityBadge returns configured badge of the priority, it is empty if badges are disabled
+func (s *Reviewer) severityBadge(priority model.ReviewPriority) string {
+	return s.cfg.Severity.Badges[priority]
+}
+
+// priorityLabel returns badge of the priority or the priority itself if there is no badge
+func (s *Reviewer) priorityLabel(priority model.ReviewPriority) string {
+	if badge := s.severityBadge(priority); badge != "" {
+		return badge
+	}
+	return string(priority)
+}
+
+// reportReviewOutcome submits the review verdict and sets the outcome label of the merge request
+func (s *Reviewer) reportReviewOutcome(ctx context.Context, bundle *reviewBundle) {
+	s.submitReviewVerdict(ctx, bundle)
+	s.applyOutcomeLabel(ctx, bundle)
+}
+
+// submitReviewVerdict requests changes on the merge request if any finding reaches the configured priority,
+// it is supported only by providers with review verdicts (GitHub)
+func (s *Reviewer) submitReviewVerdict(ctx context.Context, bundle *reviewBundle) {
+	threshold := s.cfg.Severity.RequestChangesPriority
+	if threshold == "" {
+		return
+	}
+	submitter, ok := s.provider.(interfaces.ReviewSubmitter)
+	if !ok {
+		s.log.DebugIf(s.cfg.Verbose, "provider does not support review verdicts, skipping")
+		return
+	}
+
+	var blocking int
+	for priority, count := range bundle.result.FindingsByPriority {
+		if priority.IsAtLeast(threshold) {
+			blocking += count
+		}
+	}
+	if blocking == 0 {
+		return
+	}
+
+	err := submitter.SubmitReview(ctx, bundle.request.ProjectID, bundle.request.MergeRequest.IID, &model.ReviewSubmission{
+		CommitSHA: bundle.request.MergeRequest.SHA,
+		Event:     model.ReviewEventRequestChanges,
+		Body:      fmt.Sprintf("%d blocking issues found\n\n%s", blocking, s.buildFindingsSummary(*bundle.result)),
+	})
+	if err != nil {
+		bundle.log.Warn("failed to submit review verdict", "error", err)
+		return
+	}
+
+	bundle.log.DebugIf(s.cfg.Verbose, "requested changes", "blocking_findings", blocking)
+}
+
+// applyOutcomeLabel sets the label of the code review outcome and removes the other outcome label first, a review
+// with errors or timed out stages gets no label; labels are changed only by providers that support them
+func (s *Reviewer) applyOutcomeLabel(ctx context.Context, bundle *reviewBundle) {
+	if !s.cfg.Labels.Outcome.Enable || !bundle.result.IsCodeReviewCreated {
+		return
+	}
+	manager, ok := s.provider.(interfaces.LabelManager)
+	if !ok {
+		bundle.log.DebugIf(s.cfg.Verbose, "provider does not support labels, skipping outcome label")
+		return
+	}
+
+	label, stale := s.outcomeLabels(*bundle.result)
+	request := bundle.request
+	for _, staleLabel := range stale {
+		if !slices.Contains(request.MergeRequest.Labels, staleLabel) {
+			continue
+		}
+		if err := manager.RemoveLabel(ctx, request.ProjectID, request.MergeRequest.IID, staleLabel); err != nil {
+			bundle.log.Warn("failed to remove stale outcome label", "error", err, "label", staleLabel)
+			return
+		}
+	}
+	if label == "" || slices.Contains(request.MergeRequest.Labels, label) {
+		return
+	}
+	if err := manager.AddLabel(ctx, request.ProjectID, request.MergeRequest.IID, label); err != nil {
+		bundle.log.Warn("failed to add outcome label", "error", err, "label", label)
+		return
+	}
+
+	bundle.log.DebugIf(s.cfg.Verbose, "added outcome label", "label", label)
+}
+
+// outcomeLabels returns the outcome label of the review result and outcome labels to remove,
+// the label is empty if the outcome is unknown because of errors
+func (s *Reviewer) outcomeLabels(result model.ReviewResult) (string, []string) {
+	cfg := s.cfg.Labels.Outcome
+	if len(result.Errors) > 0 || len(result.TimedOutStages) > 0 {
+		return "", []string{cfg.ChangesRequested, cfg.Approved}
+	}
+	for priority, count := range result.FindingsByPriority {
+		if count > 0 && priority.IsAtLeast(cfg.Priority) {
+			return cfg.ChangesRequested, []string{cfg.Approved}
+		}
+	}
+	return cfg.Approved, []string{cfg.ChangesRequested}
+}
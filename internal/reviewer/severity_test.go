@@ -0,0 +1,150 @@
+package reviewer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+)
+
+// labelProvider records label changes of the wrapped provider, removal of the failing label returns an error
+type labelProvider struct {
+	interfaces.CodeProvider
+	failRemove string
+
+	changes []string
+}
+
+func (p *labelProvider) AddLabel(_ context.Context, _ string, _ int, label string) error {
+	p.changes = append(p.changes, "+"+label)
+	return nil
+}
+
+func (p *labelProvider) RemoveLabel(_ context.Context, _ string, _ int, label string) error {
+	if label == p.failRemove {
+		return errors.New("remove failed")
+	}
+	p.changes = append(p.changes, "-"+label)
+	return nil
+}
+
+func TestApplyOutcomeLabel(t *testing.T) {
+	const (
+		changesRequested = defaultChangesRequestedLabel
+		approved         = defaultApprovedLabel
+	)
+
+	tests := []struct {
+		name        string
+		outcome     OutcomeLabelsConfig
+		result      model.ReviewResult
+		labels      []string
+		failRemove  string
+		wantChanges []string
+	}{
+		{
+			name:        "high finding",
+			outcome:     OutcomeLabelsConfig{Enable: true},
+			result:      model.ReviewResult{FindingsByPriority: map[model.ReviewPriority]int{model.ReviewPriorityHigh: 1}},
+			wantChanges: []string{"+" + changesRequested},
+		},
+		{
+			name:        "high finding removes stale approved label",
+			outcome:     OutcomeLabelsConfig{Enable: true},
+			result:      model.ReviewResult{FindingsByPriority: map[model.ReviewPriority]int{model.ReviewPriorityCritical: 2}},
+			labels:      []string{"bug", approved},
+			wantChanges: []string{"-" + approved, "+" + changesRequested},
+		},
+		{
+			name:    "medium finding is below the priority",
+			outcome: OutcomeLabelsConfig{Enable: true},
+			result: model.ReviewResult{FindingsByPriority: map[model.ReviewPriority]int{
+				model.ReviewPriorityMedium: 3, model.ReviewPriorityHigh: 0,
+			}},
+			labels:      []string{changesRequested},
+			wantChanges: []string{"-" + changesRequested, "+" + approved},
+		},
+		{
+			name:        "configured priority",
+			outcome:     OutcomeLabelsConfig{Enable: true, Priority: model.ReviewPriorityMedium},
+			result:      model.ReviewResult{FindingsByPriority: map[model.ReviewPriority]int{model.ReviewPriorityMedium: 1}},
+			wantChanges: []string{"+" + changesRequested},
+		},
+		{
+			name:        "configured label names",
+			outcome:     OutcomeLabelsConfig{Enable: true, ChangesRequested: "blocked", Approved: "lgtm"},
+			result:      model.ReviewResult{},
+			labels:      []string{"blocked"},
+			wantChanges: []string{"-blocked", "+lgtm"},
+		},
+		{
+			name:    "label is already set",
+			outcome: OutcomeLabelsConfig{Enable: true},
+			result:  model.ReviewResult{},
+			labels:  []string{approved},
+		},
+		{
+			name:        "review with errors only removes labels",
+			outcome:     OutcomeLabelsConfig{Enable: true},
+			result:      model.ReviewResult{Errors: []error{errors.New("failed")}},
+			labels:      []string{approved, changesRequested},
+			wantChanges: []string{"-" + changesRequested, "-" + approved},
+		},
+		{
+			name:        "timed out review only removes labels",
+			outcome:     OutcomeLabelsConfig{Enable: true},
+			result:      model.ReviewResult{TimedOutStages: []string{stageCodeReview}},
+			labels:      []string{approved},
+			wantChanges: []string{"-" + approved},
+		},
+		{
+			name:       "failed removal keeps labels",
+			outcome:    OutcomeLabelsConfig{Enable: true},
+			result:     model.ReviewResult{},
+			labels:     []string{changesRequested},
+			failRemove: changesRequested,
+		},
+		{
+			name:   "disabled",
+			result: model.ReviewResult{FindingsByPriority: map[model.ReviewPriority]int{model.ReviewPriorityHigh: 1}},
+			labels: []string{approved},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Labels.Outcome = tt.outcome
+			codeReviewer, provider, request := newTestReviewer(t, cfg, newFilesDiff("a.go"), &stubAPI{})
+			labels := &labelProvider{CodeProvider: provider, failRemove: tt.failRemove}
+			codeReviewer.provider = labels
+
+			request.MergeRequest.Labels = tt.labels
+			result := tt.result
+			result.IsCodeReviewCreated = true
+			bundle := &reviewBundle{result: &result, request: request, log: codeReviewer.log}
+
+			codeReviewer.applyOutcomeLabel(context.Background(), bundle)
+			if !slices.Equal(labels.changes, tt.wantChanges) {
+				t.Errorf("label changes = %v, want %v", labels.changes, tt.wantChanges)
+			}
+		})
+	}
+}
+
+func TestApplyOutcomeLabelWithoutCodeReview(t *testing.T) {
+	cfg := testConfig()
+	cfg.Labels.Outcome.Enable = true
+	codeReviewer, provider, request := newTestReviewer(t, cfg, newFilesDiff("a.go"), &stubAPI{})
+	labels := &labelProvider{CodeProvider: provider}
+	codeReviewer.provider = labels
+
+	bundle := &reviewBundle{result: &model.ReviewResult{}, request: request, log: codeReviewer.log}
+	codeReviewer.applyOutcomeLabel(context.Background(), bundle)
+	if len(labels.changes) != 0 {
+		t.Errorf("label changes = %v, want none", labels.changes)
+	}
+}
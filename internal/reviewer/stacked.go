@@ -0,0 +1,73 @@
+package reviewer
+
+import (
+	"context"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/model/interfaces"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/lang"
+)
+
+// excludeBaseMergeRequestChanges finds an open merge request whose source branch is the target branch
+// of the request and replaces its changes with the diff against the merge base of both heads,
+// so changes of the base merge request are not reviewed twice; the request is unchanged if there is no one
+func (s *Reviewer) excludeBaseMergeRequestChanges(ctx context.Context, request *model.ReviewRequest) error {
+	if !s.cfg.StackedReview.Enable {
+		return nil
+	}
+	comparer, ok := s.provider.(interfaces.CommitComparer)
+	if !ok {
+		s.log.DebugIf(s.cfg.Verbose, "provider does not support commit comparison, skipping stacked review")
+		return nil
+	}
+	mr := request.MergeRequest
+
+	candidates, err := s.provider.ListMergeRequests(ctx, request.ProjectID, &model.MergeRequestFilter{
+		State:        []string{"open", "opened"},
+		SourceBranch: mr.TargetBranch,
+	})
+	if err != nil {
+		return errm.Wrap(err, "failed to list merge requests")
+	}
+
+	var base *model.MergeRequest
+	for _, candidate := range candidates {
+		if candidate.IID != mr.IID && candidate.SourceBranch == mr.TargetBranch && candidate.SHA != "" {
+			base = candidate
+			break
+		}
+	}
+	if base == nil {
+		return nil
+	}
+
+	// The base merge request may have new commits that are not in this one yet
+	mergeBase, err := comparer.GetMergeBase(ctx, request.ProjectID, base.SHA, mr.SHA)
+	if err != nil {
+		return errm.Wrap(err, "failed to get merge base", "base_mr_iid", base.IID)
+	}
+
+	diffs, err := comparer.CompareCommits(ctx, request.ProjectID, mergeBase, mr.SHA)
+	if err != nil {
+		return errm.Wrap(err, "failed to compare commits", "base_mr_iid", base.IID)
+	}
+
+	s.log.Info("reviewing stacked merge request",
+		"mr_iid", mr.IID,
+		"base_mr_iid", base.IID,
+		"merge_base", lang.TruncateString(mergeBase, 8),
+		"files", len(diffs),
+		"files_without_exclusion", len(request.Changes),
+	)
+
+	// Target branch is used as the original version of files, it is the merge base now
+	stacked := *mr
+	stacked.TargetBranch = mergeBase
+
+	request.MergeRequest = &stacked
+	request.Changes = diffs
+	request.BaseMergeRequest = base
+
+	return nil
+}
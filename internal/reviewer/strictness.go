@@ -0,0 +1,56 @@
+package reviewer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maxbolgarin/codry/internal/agent/prompts"
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// strictnessPreset is the filter of findings of a strictness level, the prompt directive is in prompts
+type strictnessPreset struct {
+	minPriority   model.ReviewPriority
+	minConfidence model.ReviewConfidence
+}
+
+var strictnessPresets = map[prompts.Strictness]strictnessPreset{
+	prompts.StrictnessStrict:      {minPriority: model.ReviewPriorityBacklog, minConfidence: model.ConfidenceLow},
+	prompts.StrictnessBalanced:    {minPriority: model.ReviewPriorityBacklog, minConfidence: model.ConfidenceMedium},
+	prompts.StrictnessLightweight: {minPriority: model.ReviewPriorityHigh, minConfidence: model.ConfidenceHigh},
+}
+
+// filterByStrictness removes findings below the minimal priority or confidence of the strictness level,
+// both minimums must be passed; findings dropped by confidence are counted in the result, findings with
+// unknown priority or confidence are kept
+func (s *Reviewer) filterByStrictness(bundle *reviewBundle, findings []*model.ReviewAIComment) []*model.ReviewAIComment {
+	minPriority, minConfidence := s.cfg.Strictness.MinPriority, s.cfg.Strictness.MinConfidence
+	total, lowConfidence := len(findings), 0
+	findings = slices.DeleteFunc(findings, func(finding *model.ReviewAIComment) bool {
+		if finding.Confidence.IsValid() && !finding.Confidence.IsAtLeast(minConfidence) {
+			lowConfidence++
+			return true
+		}
+		return finding.Priority.IsValid() && !finding.Priority.IsAtLeast(minPriority)
+	})
+	bundle.result.LowConfidenceFindings += lowConfidence
+	if filtered := total - len(findings); filtered > 0 {
+		bundle.log.DebugIf(s.cfg.Verbose, "filtered findings by strictness", "level", s.cfg.Strictness.Level,
+			"filtered", filtered, "low_confidence", lowConfidence)
+	}
+	return findings
+}
+
+// filteredFindingsNote tells how many findings were suppressed by inline directives or dropped by confidence,
+// it is empty if none were
+func (s *Reviewer) filteredFindingsNote(bundle *reviewBundle) string {
+	var notes []string
+	if note := s.suppressedFindingsNote(bundle); note != "" {
+		notes = append(notes, note)
+	}
+	if count := bundle.result.LowConfidenceFindings; count > 0 {
+		notes = append(notes, fmt.Sprintf("_%d findings below %s confidence were not posted._", count, s.cfg.Strictness.MinConfidence))
+	}
+	return strings.Join(notes, "\n\n")
+}
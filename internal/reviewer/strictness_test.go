@@ -0,0 +1,89 @@
+package reviewer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/maxbolgarin/codry/internal/model"
+)
+
+// titles returns titles of the findings
+func titles(findings []*model.ReviewAIComment) []string {
+	result := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		result = append(result, finding.Title)
+	}
+	return result
+}
+
+func TestFilterByStrictnessConfidence(t *testing.T) {
+	confidences := []model.ReviewConfidence{
+		model.ConfidenceVeryHigh, model.ConfidenceHigh, model.ConfidenceMedium, model.ConfidenceLow, "",
+	}
+
+	tests := []struct {
+		name          string
+		minConfidence model.ReviewConfidence
+		want          []string
+		wantDropped   int
+	}{
+		{"default", "", []string{"very_high", "high", "medium", ""}, 1},
+		{"very high", model.ConfidenceVeryHigh, []string{"very_high", ""}, 3},
+		{"high", model.ConfidenceHigh, []string{"very_high", "high", ""}, 2},
+		{"medium", model.ConfidenceMedium, []string{"very_high", "high", "medium", ""}, 1},
+		{"low", model.ConfidenceLow, []string{"very_high", "high", "medium", "low", ""}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.Strictness.MinConfidence = tt.minConfidence
+			codeReviewer, _, _ := newTestReviewer(t, cfg, newFilesDiff("a.go"), &stubAPI{})
+			bundle := &reviewBundle{result: &model.ReviewResult{}, log: codeReviewer.log}
+
+			findings := make([]*model.ReviewAIComment, 0, len(confidences))
+			for _, confidence := range confidences {
+				// Findings with unknown confidence are kept
+				findings = append(findings, &model.ReviewAIComment{
+					Title: string(confidence), Confidence: confidence, Priority: model.ReviewPriorityMedium,
+				})
+			}
+
+			got := codeReviewer.filterByStrictness(bundle, findings)
+			if !slices.Equal(titles(got), tt.want) {
+				t.Errorf("filterByStrictness() = %v, want %v", titles(got), tt.want)
+			}
+			if bundle.result.LowConfidenceFindings != tt.wantDropped {
+				t.Errorf("LowConfidenceFindings = %d, want %d", bundle.result.LowConfidenceFindings, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestFilterByStrictnessComposes(t *testing.T) {
+	finding := func(title string, confidence model.ReviewConfidence, priority model.ReviewPriority) *model.ReviewAIComment {
+		return &model.ReviewAIComment{Title: title, Confidence: confidence, Priority: priority}
+	}
+	cfg := testConfig()
+	cfg.Strictness.MinConfidence = model.ConfidenceHigh
+	cfg.Strictness.MinPriority = model.ReviewPriorityHigh
+	codeReviewer, _, _ := newTestReviewer(t, cfg, newFilesDiff("a.go"), &stubAPI{})
+	bundle := &reviewBundle{result: &model.ReviewResult{}, log: codeReviewer.log}
+
+	got := codeReviewer.filterByStrictness(bundle, []*model.ReviewAIComment{
+		finding("both pass", model.ConfidenceVeryHigh, model.ReviewPriorityCritical),
+		finding("low priority", model.ConfidenceHigh, model.ReviewPriorityMedium),
+		finding("low confidence", model.ConfidenceMedium, model.ReviewPriorityCritical),
+		finding("both fail", model.ConfidenceLow, model.ReviewPriorityBacklog),
+	})
+	if want := []string{"both pass"}; !slices.Equal(titles(got), want) {
+		t.Errorf("filterByStrictness() = %v, want %v", titles(got), want)
+	}
+	// Only findings dropped by confidence are counted
+	if bundle.result.LowConfidenceFindings != 2 {
+		t.Errorf("LowConfidenceFindings = %d, want 2", bundle.result.LowConfidenceFindings)
+	}
+	if got, want := codeReviewer.filteredFindingsNote(bundle), "_2 findings below high confidence were not posted._"; got != want {
+		t.Errorf("filteredFindingsNote() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package reviewer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/codry/internal/reviewer/analyze"
+)
+
+// suppressFindings removes findings on lines of the file annotated with inline directives like `// codry:ignore performance`,
+// directives are read from the head version of the file and the number of suppressed findings is added to the result
+func (s *Reviewer) suppressFindings(ctx context.Context, bundle *reviewBundle, request model.ReviewRequest, change *model.FileDiff, findings []*model.ReviewAIComment) []*model.ReviewAIComment {
+	if s.suppression == nil || change.IsDeleted || len(findings) == 0 {
+		return findings
+	}
+
+	content, err := analyze.FetchFileContent(ctx, s.provider, request.ProjectID, change.NewPath, request.MergeRequest.SHA)
+	if err != nil {
+		bundle.log.DebugIf(s.cfg.Verbose, "failed to get file content for suppression directives", "file", change.NewPath, "error", err)
+		return findings
+	}
+	suppressions := s.suppression.Parse(content)
+	if len(suppressions) == 0 {
+		return findings
+	}
+
+	total := len(findings)
+	findings = slices.DeleteFunc(findings, func(finding *model.ReviewAIComment) bool {
+		// Findings without a path are findings of the reviewed file, the path is set later
+		if finding.Line <= 0 || (finding.FilePath != "" && finding.FilePath != change.NewPath) {
+			return false
+		}
+		suppression, ok := suppressions[finding.Line]
+		return ok && suppression.Matches(string(finding.IssueType))
+	})
+	if suppressed := total - len(findings); suppressed > 0 {
+		bundle.result.SuppressedFindings += suppressed
+		bundle.log.InfoIf(s.cfg.Verbose, "suppressed findings by inline directives", "file", change.NewPath, "suppressed", suppressed)
+	}
+
+	return findings
+}
+
+// suppressedFindingsNote tells how many findings were suppressed by inline directives, it is empty if none were
+func (s *Reviewer) suppressedFindingsNote(bundle *reviewBundle) string {
+	if bundle.result.SuppressedFindings == 0 {
+		return ""
+	}
+	return fmt.Sprintf("_%d findings were suppressed by inline `%s` comments._", bundle.result.SuppressedFindings, s.cfg.Suppression.Directive)
+}
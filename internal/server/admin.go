@@ -0,0 +1,205 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/maxbolgarin/codry/internal/model"
+	"github.com/maxbolgarin/errm"
+	"github.com/maxbolgarin/servex/v2"
+)
+
+// maxAdminJobs is the number of the latest admin jobs whose status is kept, older ones are forgotten
+const maxAdminJobs = 1000
+
+// jobState is the state of a review triggered by the admin endpoint
+type jobState string
+
+const (
+	jobStateQueued   jobState = "queued"   // waiting for the debounce window or a worker
+	jobStateRunning  jobState = "running"  // reviewed by a worker
+	jobStateDone     jobState = "done"     // reviewed or skipped by labels and filters of the reviewer
+	jobStateFailed   jobState = "failed"   // the review returned an error
+	jobStateCanceled jobState = "canceled" // superseded by a newer event of the merge request or stopped by shutdown
+)
+
+// adminReviewRequest is the body of the admin request to review a merge request
+type adminReviewRequest struct {
+	// Provider is the webhook name of the provider, it may be omitted if the server has one provider
+	Provider  string `json:"provider"`
+	ProjectID string `json:"project_id"`
+	MRIID     int    `json:"mr_iid"`
+}
+
+// adminJob is the status of a review triggered by the admin endpoint
+type adminJob struct {
+	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
+	ProjectID string    `json:"project_id"`
+	MRIID     int       `json:"mr_iid"`
+	State     jobState  `json:"state"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// adminJobs keeps statuses of the latest admin jobs, it is guarded by the mutex of the server
+type adminJobs struct {
+	byID  map[string]*adminJob
+	order []string // IDs from the oldest
+}
+
+func newAdminJobs() *adminJobs {
+	return &adminJobs{byID: make(map[string]*adminJob)}
+}
+
+// add tracks the job and forgets the oldest one if there are too many
+func (j *adminJobs) add(job *adminJob) {
+	j.byID[job.ID] = job
+	j.order = append(j.order, job.ID)
+	if len(j.order) > maxAdminJobs {
+		delete(j.byID, j.order[0])
+		j.order = j.order[1:]
+	}
+}
+
+// update sets the state of the jobs, forgotten jobs are skipped
+func (j *adminJobs) update(ids []string, state jobState, err error) {
+	for _, id := range ids {
+		job, ok := j.byID[id]
+		if !ok {
+			continue
+		}
+		job.State, job.UpdatedAt = state, time.Now()
+		if err != nil {
+			job.Error = err.Error()
+		}
+	}
+}
+
+// trackJobLocked starts tracking the admin job served by the review, webhook events are not tracked
+func (h *Server) trackJobLocked(review *pendingReview, job reviewJob) {
+	if job.id == "" {
+		return
+	}
+	now := time.Now()
+	review.jobIDs = append(review.jobIDs, job.id)
+	h.jobs.add(&adminJob{
+		ID:        job.id,
+		Provider:  job.webhook.Name,
+		ProjectID: job.event.ProjectID,
+		MRIID:     job.event.MergeRequest.IID,
+		State:     jobStateQueued,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// handleAdminReview queues a review of the merge request like its webhook event does and responds with the job,
+// the review respects labels and file filters of the reviewer
+func (h *Server) handleAdminReview(w http.ResponseWriter, r *http.Request) {
+	ctx := servex.NewContext(w, r)
+	if !h.isAdminAuthorized(r) {
+		ctx.Unauthorized(errm.New("invalid admin token"), "unauthorized")
+		return
+	}
+
+	var req adminReviewRequest
+	if err := ctx.ReadJSON(&req); err != nil {
+		ctx.BadRequest(err, "failed to read request")
+		return
+	}
+	if req.ProjectID == "" || req.MRIID <= 0 {
+		ctx.BadRequest(errm.New("project_id and mr_iid are required"), "invalid request")
+		return
+	}
+	webhook, ok := h.findWebhook(req.Provider)
+	if !ok {
+		ctx.BadRequest(errm.Errorf("unknown provider %q", req.Provider), "invalid request")
+		return
+	}
+
+	mr, err := webhook.Provider.GetMergeRequest(r.Context(), req.ProjectID, req.MRIID)
+	if errm.Is(err, model.ErrNotFound) {
+		ctx.NotFound(err, "merge request is not found")
+		return
+	}
+	if err != nil {
+		ctx.BadGateway(err, "failed to get merge request")
+		return
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		ctx.InternalServerError(err, "failed to create job")
+		return
+	}
+	event := &model.CodeEvent{Type: "admin", ProjectID: req.ProjectID, MergeRequest: mr, Timestamp: time.Now()}
+	if !h.enqueue(reviewJob{webhook: webhook, event: event, id: id}) {
+		ctx.ServiceUnavailable(errm.New("review queue is full or closed"), "cannot accept review")
+		return
+	}
+
+	h.log.Info("review is requested by admin", "job_id", id, "webhook", webhook.Name, "project_id", req.ProjectID, "mr_iid", req.MRIID)
+	ctx.Response(http.StatusAccepted, h.getJob(id))
+}
+
+// handleAdminJob responds with the status of the admin job
+func (h *Server) handleAdminJob(w http.ResponseWriter, r *http.Request) {
+	ctx := servex.NewContext(w, r)
+	if !h.isAdminAuthorized(r) {
+		ctx.Unauthorized(errm.New("invalid admin token"), "unauthorized")
+		return
+	}
+
+	job := h.getJob(ctx.Path("id"))
+	if job == nil {
+		ctx.NotFound(errm.New("job is not found"), "job is not found")
+		return
+	}
+	ctx.JSON(job)
+}
+
+// getJob returns a copy of the admin job, nil if it is unknown
+func (h *Server) getJob(id string) *adminJob {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	job, ok := h.jobs.byID[id]
+	if !ok {
+		return nil
+	}
+	jobCopy := *job
+	return &jobCopy
+}
+
+// findWebhook returns the webhook by its name, the name may be empty if there is only one webhook
+func (h *Server) findWebhook(name string) (Webhook, bool) {
+	if name == "" && len(h.webhooks) == 1 {
+		return h.webhooks[0], true
+	}
+	for _, webhook := range h.webhooks {
+		if webhook.Name == name {
+			return webhook, true
+		}
+	}
+	return Webhook{}, false
+}
+
+// isAdminAuthorized checks the bearer token of the request in constant time
+func (h *Server) isAdminAuthorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.config.Admin.Token)) == 1
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -12,6 +12,15 @@ const (
 	defaultAddress  = "0.0.0.0:8080"
 	defaultEndpoint = "/webhook"
 	defaultTimeout  = 30 * time.Second
+
+	defaultReadinessEndpoint = "/ready"
+	defaultAdminEndpoint     = "/review"
+
+	minAdminTokenLength = 16
+
+	defaultWorkers   = 4
+	defaultQueueSize = 100
+	defaultDebounce  = 10 * time.Second
 )
 
 // TODO: make configurable
@@ -26,10 +35,26 @@ var authHeaders = []string{
 
 // Config represents webhook server configuration
 type Config struct {
-	Address  string        `yaml:"address" env:"SERVER_ADDRESS"`
+	Address string `yaml:"address" env:"SERVER_ADDRESS"`
+	// Endpoint is the prefix of webhook paths, every provider is served on Endpoint/<name>,
+	// a single provider is also served on Endpoint itself
 	Endpoint string        `yaml:"endpoint" env:"SERVER_ENDPOINT"`
 	Timeout  time.Duration `yaml:"timeout" env:"SERVER_TIMEOUT"`
 
+	// Workers is the number of reviews processed concurrently
+	Workers int `yaml:"workers" env:"SERVER_WORKERS"`
+	// QueueSize is the number of accepted events waiting for a worker, webhooks are rejected with 503 if it is full
+	QueueSize int `yaml:"queue_size" env:"SERVER_QUEUE_SIZE"`
+	// Debounce is the delay before a merge request event is queued, events of the same merge request
+	// in this window are coalesced and only the latest one is reviewed; negative value disables the delay
+	Debounce time.Duration `yaml:"debounce" env:"SERVER_DEBOUNCE"`
+
+	// ReadinessEndpoint responds 200 only if the provider accepts the configured token
+	ReadinessEndpoint string `yaml:"readiness_endpoint" env:"SERVER_READINESS_ENDPOINT"`
+
+	// Admin is the endpoint to trigger reviews on demand, it is served only if its token is set
+	Admin AdminConfig `yaml:"admin"`
+
 	CertFilePath string `yaml:"cert_file_path" env:"CERT_FILE_PATH"`
 	KeyFilePath  string `yaml:"key_file_path" env:"KEY_FILE_PATH"`
 	EnableHTTPS  bool   `yaml:"enable_https" env:"SERVER_ENABLE_HTTPS"`
@@ -37,9 +62,40 @@ type Config struct {
 	Certificate tls.Certificate `yaml:"-"`
 }
 
+// AdminConfig represents the endpoint to trigger reviews of merge requests on demand
+type AdminConfig struct {
+	// Token is required in the "Authorization: Bearer <token>" header, the endpoint is disabled if it is empty
+	Token string `yaml:"token" env:"SERVER_ADMIN_TOKEN"`
+	// Endpoint accepts POST requests to review a merge request, GET Endpoint/{id} returns the status of the review
+	Endpoint string `yaml:"endpoint" env:"SERVER_ADMIN_ENDPOINT"`
+}
+
+// Validate checks the config and returns all found problems at once
+func (cfg Config) Validate() error {
+	errs := errm.NewList()
+	if cfg.Workers < 0 || cfg.QueueSize < 0 {
+		errs.New("workers and queue_size must not be negative")
+	}
+	if cfg.Timeout < 0 {
+		errs.New("timeout must not be negative")
+	}
+	if cfg.EnableHTTPS && (cfg.CertFilePath == "" || cfg.KeyFilePath == "") {
+		errs.New("cert_file_path and key_file_path must be set when enable_https is true")
+	}
+	if cfg.Admin.Token != "" && len(cfg.Admin.Token) < minAdminTokenLength {
+		errs.Errorf("admin.token must have at least %d characters", minAdminTokenLength)
+	}
+	return errs.Err()
+}
+
 func (cfg *Config) PrepareAndValidate() error {
 	cfg.Address = lang.Check(cfg.Address, defaultAddress)
 	cfg.Endpoint = lang.Check(cfg.Endpoint, defaultEndpoint)
+	cfg.ReadinessEndpoint = lang.Check(cfg.ReadinessEndpoint, defaultReadinessEndpoint)
+	cfg.Admin.Endpoint = lang.Check(cfg.Admin.Endpoint, defaultAdminEndpoint)
+	cfg.Workers = lang.Check(cfg.Workers, defaultWorkers)
+	cfg.QueueSize = lang.Check(cfg.QueueSize, defaultQueueSize)
+	cfg.Debounce = lang.Check(cfg.Debounce, defaultDebounce)
 
 	if cfg.EnableHTTPS {
 		if cfg.CertFilePath == "" || cfg.KeyFilePath == "" {
@@ -2,8 +2,14 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"path"
+	"sync"
+	"time"
 
+	"github.com/maxbolgarin/codry/internal/metrics"
+	"github.com/maxbolgarin/codry/internal/model"
 	"github.com/maxbolgarin/codry/internal/model/interfaces"
 	"github.com/maxbolgarin/codry/internal/reviewer"
 	"github.com/maxbolgarin/errm"
@@ -11,20 +17,62 @@ import (
 	"github.com/maxbolgarin/servex/v2"
 )
 
-// Server handles webhook requests from VCS providers
+// Webhook is a source of events served on its own path: the provider validates and parses events,
+// the reviewer processes them
+type Webhook struct {
+	Name     string // path suffix, e.g. github for /webhook/github
+	Provider interfaces.CodeProvider
+	Reviewer *reviewer.Reviewer
+}
+
+// reviewJob is an accepted event waiting for a worker
+type reviewJob struct {
+	webhook Webhook
+	event   *model.CodeEvent
+	// id is the ID of the job triggered by the admin endpoint, its merge request is reviewed without checking
+	// the event; it is empty for webhook events
+	id string
+}
+
+// pendingReview is the latest event of a merge request: it waits for the debounce window, for a worker or is running
+type pendingReview struct {
+	key     string
+	job     reviewJob
+	jobIDs  []string // admin jobs served by the review, coalesced jobs share it
+	timer   *time.Timer
+	queued  bool
+	running bool
+	cancel  context.CancelFunc
+}
+
+// Server handles webhook requests from VCS providers, events are processed asynchronously by workers;
+// events of the same merge request are coalesced, so only its latest head is reviewed
 type Server struct {
-	provider interfaces.CodeProvider
-	reviewer *reviewer.Reviewer
+	webhooks []Webhook
 	config   Config
 	log      logze.Logger
 	server   *servex.Server
+	metrics  interfaces.MetricsRecorder
+
+	queue   chan *pendingReview
+	reviews map[string]*pendingReview // by merge request key
+	pending int                       // reviews that are not running yet
+	jobs    *adminJobs
+	ready   readiness
+	mu      sync.Mutex
+	closed  bool
+	workers sync.WaitGroup
+	cancel  context.CancelFunc
 }
 
 // New creates a new webhook handler
-func New(cfg Config, provider interfaces.CodeProvider, reviewer *reviewer.Reviewer) (*Server, error) {
+func New(cfg Config, webhooks ...Webhook) (*Server, error) {
 	if err := cfg.PrepareAndValidate(); err != nil {
 		return nil, errm.Wrap(err, "validate config")
 	}
+	if len(webhooks) == 0 {
+		return nil, errm.New("at least one webhook is required")
+	}
 
 	log := logze.With("module", "server")
 
@@ -41,71 +89,335 @@ func New(cfg Config, provider interfaces.CodeProvider, reviewer *reviewer.Review
 	}
 
 	h := &Server{
-		provider: provider,
-		reviewer: reviewer,
+		webhooks: webhooks,
 		config:   cfg,
 		log:      log,
 		server:   server,
+		metrics:  metrics.Nop{},
+		queue:    make(chan *pendingReview, cfg.QueueSize),
+		reviews:  make(map[string]*pendingReview),
+		jobs:     newAdminJobs(),
 	}
 
-	server.HandleFunc(cfg.Endpoint, h.handleWebhook)
+	paths := make(map[string]bool, len(webhooks))
+	for _, webhook := range webhooks {
+		webhookPath := path.Join(cfg.Endpoint, webhook.Name)
+		if paths[webhookPath] {
+			return nil, errm.Errorf("duplicate webhook path %s, set unique provider names", webhookPath)
+		}
+		paths[webhookPath] = true
+
+		server.HandleFunc(webhookPath, h.webhookHandler(webhook))
+		log.Info("webhook is registered", "path", webhookPath)
+	}
+	if len(webhooks) == 1 && !paths[path.Clean(cfg.Endpoint)] {
+		server.HandleFunc(cfg.Endpoint, h.webhookHandler(webhooks[0]))
+	}
+	server.HandleFunc(cfg.ReadinessEndpoint, h.handleReadiness)
+	if cfg.Admin.Token != "" {
+		server.HandleFunc(cfg.Admin.Endpoint, h.handleAdminReview, http.MethodPost)
+		server.HandleFunc(path.Join(cfg.Admin.Endpoint, "{id}"), h.handleAdminJob, http.MethodGet)
+		log.Info("admin endpoint is registered", "path", cfg.Admin.Endpoint)
+	}
 
 	return h, nil
 }
 
-// Start starts the webhook server
+// SetMetrics serves metrics on the endpoint and records the queue depth, it should be called before Start
+func (h *Server) SetMetrics(endpoint string, recorder *metrics.Prometheus) {
+	h.metrics = recorder
+	h.server.HandleFunc(endpoint, recorder.ServeHTTP)
+	h.log.Info("metrics are registered", "path", endpoint)
+}
+
+// Start starts review workers and the webhook server, reviews are not canceled with ctx, they are drained in Stop
 func (h *Server) Start(ctx context.Context) error {
+	workerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	h.mu.Lock()
+	h.cancel = cancel
+	h.mu.Unlock()
+
+	for range h.config.Workers {
+		h.workers.Add(1)
+		go h.runWorker(workerCtx)
+	}
+
 	if h.config.EnableHTTPS {
 		return h.server.StartHTTPS(h.config.Address)
 	}
 	return h.server.StartHTTP(h.config.Address)
 }
 
-// Stop stops the webhook server
+// Stop stops accepting webhooks and waits for queued and in-flight reviews,
+// reviews are canceled if they don't finish before ctx is done
 func (h *Server) Stop(ctx context.Context) error {
-	return h.server.Shutdown(ctx)
+	shutdownErr := h.server.Shutdown(ctx)
+
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		for _, review := range h.reviews {
+			if !review.queued && !review.running {
+				review.timer.Stop()
+				h.queueReviewLocked(review) // don't wait for debounce, drain it with others
+			}
+		}
+		close(h.queue)
+	}
+	cancel := h.cancel
+	h.mu.Unlock()
+	if cancel == nil {
+		cancel = func() {} // server was not started
+	}
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return errm.Wrap(ctx.Err(), "reviews are not finished before shutdown")
+	}
+
+	if shutdownErr != nil {
+		return errm.Wrap(shutdownErr, "failed to shutdown server")
+	}
+	return nil
 }
 
-// handleWebhook handles incoming webhook requests
-func (h *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
-	ctx := servex.NewContext(w, r)
+// webhookHandler returns handler of incoming webhook requests of the provider,
+// it responds right after the event is queued to avoid webhook timeouts
+func (h *Server) webhookHandler(webhook Webhook) http.HandlerFunc {
+	log := h.log.WithFields("webhook", webhook.Name)
 
-	body, err := ctx.Read()
-	if err != nil {
-		ctx.BadRequest(err, "failed to read webhook body")
-		return
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := servex.NewContext(w, r)
+
+		body, err := ctx.Read()
+		if err != nil {
+			ctx.BadRequest(err, "failed to read webhook body")
+			return
+		}
+
+		// Get token from headers (provider-specific)
+		token := h.getAuthFromHeaders(r)
+
+		// Validate webhook signature
+		if err := webhook.Provider.ValidateWebhook(body, token); err != nil {
+			ctx.Unauthorized(err, "webhook validation failed")
+			return
+		}
+
+		// Parse webhook event
+		event, err := webhook.Provider.ParseWebhookEvent(body)
+		if errm.Is(err, model.ErrIgnoredEvent) {
+			log.Debug("ignoring webhook event", "reason", err)
+			ctx.Response(http.StatusOK)
+			return
+		}
+		if err != nil {
+			ctx.BadRequest(err, "failed to parse webhook event")
+			return
+		}
+
+		// Check if this is a merge request event that should be processed
+		if !webhook.Provider.IsMergeRequestEvent(event) {
+			log.Debug("ignoring non-merge request event")
+			ctx.Response(http.StatusOK)
+			return
+		}
+
+		log.Info("received merge request event", "mr_title", event.MergeRequest.Title, "action", event.Action)
+
+		if !h.enqueue(reviewJob{webhook: webhook, event: event}) {
+			ctx.ServiceUnavailable(errm.New("review queue is full or closed"), "cannot accept event")
+			return
+		}
+
+		ctx.Response(http.StatusAccepted)
 	}
+}
 
-	// Get token from headers (provider-specific)
-	token := h.getAuthFromHeaders(r)
+// enqueue adds the job to the queue without blocking, it returns false if the queue is full or closed;
+// a newer event replaces the waiting event of the same merge request and cancels its running review
+func (h *Server) enqueue(job reviewJob) bool {
+	key := reviewKey(job)
 
-	// Validate webhook signature
-	if err := h.provider.ValidateWebhook(body, token); err != nil {
-		ctx.Unauthorized(err, "webhook validation failed")
-		return
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
 	}
 
-	// Parse webhook event
-	event, err := h.provider.ParseWebhookEvent(body)
+	if review, ok := h.reviews[key]; ok && !review.running {
+		keepReadyForReview(review.job, job)
+		review.job = job
+		h.trackJobLocked(review, job)
+		if !review.queued {
+			review.timer.Reset(h.config.Debounce)
+		}
+		h.log.Debug("event is coalesced with the waiting one", "review", key)
+		return true
+	}
+
+	if h.pending >= h.config.QueueSize {
+		return false
+	}
+
+	if running, ok := h.reviews[key]; ok {
+		keepReadyForReview(running.job, job)
+		running.cancel()
+		h.log.Info("running review is superseded by a newer event", "review", key)
+	}
+
+	review := &pendingReview{key: key, job: job}
+	h.reviews[key] = review
+	h.trackJobLocked(review, job)
+	h.pending++
+	h.metrics.QueueDepth(h.pending)
+
+	if h.config.Debounce <= 0 {
+		h.queueReviewLocked(review)
+		return true
+	}
+	review.timer = time.AfterFunc(h.config.Debounce, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if !review.queued {
+			h.queueReviewLocked(review)
+		}
+	})
+
+	return true
+}
+
+// keepReadyForReview keeps the full review of the draft marked as ready if its event is replaced by a newer one
+func keepReadyForReview(replaced, job reviewJob) {
+	if replaced.event.Action == model.ActionReadyForReview {
+		job.event.Action = model.ActionReadyForReview
+	}
+}
+
+// queueReviewLocked sends the review to workers, the queue never blocks because its size limits pending reviews
+func (h *Server) queueReviewLocked(review *pendingReview) {
+	review.queued = true
+	h.queue <- review
+}
+
+// runWorker processes queued reviews until the queue is closed
+func (h *Server) runWorker(ctx context.Context) {
+	defer h.workers.Done()
+
+	for review := range h.queue {
+		reviewCtx, cancel := context.WithCancel(ctx)
+
+		h.mu.Lock()
+		review.running, review.cancel = true, cancel
+		h.pending--
+		h.metrics.QueueDepth(h.pending)
+		job := review.job
+		h.jobs.update(review.jobIDs, jobStateRunning, nil)
+		h.mu.Unlock()
+
+		err := h.processJob(reviewCtx, job)
+		state := jobStateDone
+		switch {
+		case reviewCtx.Err() != nil:
+			state, err = jobStateCanceled, reviewCtx.Err()
+		case err != nil:
+			state = jobStateFailed
+		}
+		cancel()
+
+		h.mu.Lock()
+		if h.reviews[review.key] == review {
+			delete(h.reviews, review.key)
+		}
+		h.jobs.update(review.jobIDs, state, err)
+		h.mu.Unlock()
+	}
+}
+
+func (h *Server) processJob(ctx context.Context, job reviewJob) (err error) {
+	log := h.log.WithFields("webhook", job.webhook.Name, "project_id", job.event.ProjectID)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("panic while processing event", "panic", r)
+			err = errm.Errorf("panic while processing event: %v", r)
+		}
+	}()
+
+	if job.id != "" {
+		err = job.webhook.Reviewer.ReviewMergeRequest(ctx, job.event.ProjectID, job.event.MergeRequest)
+	} else {
+		err = job.webhook.Reviewer.HandleEvent(ctx, job.event)
+	}
 	if err != nil {
-		ctx.BadRequest(err, "failed to parse webhook event")
-		return
+		log.Error("failed to handle event", "error", err)
 	}
+	return err
+}
 
-	// Check if this is a merge request event that should be processed
-	if !h.provider.IsMergeRequestEvent(event) {
-		h.log.Debug("ignoring non-merge request event")
-		ctx.Response(http.StatusOK)
-		return
+// reviewKey identifies the merge request of the job
+func reviewKey(job reviewJob) string {
+	var iid int
+	if job.event.MergeRequest != nil {
+		iid = job.event.MergeRequest.IID
 	}
+	return fmt.Sprintf("%s:%s#%d", job.webhook.Name, job.event.ProjectID, iid)
+}
 
-	h.log.Info("received merge request event", "mr_title", event.MergeRequest.Title, "action", event.Action)
+// handleReadiness reports if the service can work with all providers
+func (h *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	ctx := servex.NewContext(w, r)
 
-	// Pass event to review service - it will handle all the processing logic
-	if err := h.reviewer.HandleEvent(ctx, event); err != nil {
-		ctx.InternalServerError(err, "failed to handle event")
+	if name, err := h.checkReadiness(r.Context()); err != nil {
+		ctx.ServiceUnavailable(err, "provider "+name+" is not available")
 		return
 	}
+
+	ctx.Response(http.StatusOK)
+}
+
+// readinessTTL is how long the result of the credentials check is served to probes,
+// so frequent probes don't spend the API rate limits of providers
+const readinessTTL = 30 * time.Second
+
+// readiness is the cached result of the credentials check of providers
+type readiness struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	provider  string // name of the failed provider
+	err       error
+}
+
+// checkReadiness validates credentials of all providers, the result is cached for readinessTTL;
+// it returns the name of the first failed provider with its error
+func (h *Server) checkReadiness(ctx context.Context) (string, error) {
+	h.ready.mu.Lock()
+	defer h.ready.mu.Unlock()
+
+	if !h.ready.checkedAt.IsZero() && time.Since(h.ready.checkedAt) < readinessTTL {
+		return h.ready.provider, h.ready.err
+	}
+
+	h.ready.provider, h.ready.err = "", nil
+	for _, webhook := range h.webhooks {
+		if err := webhook.Provider.ValidateCredentials(ctx); err != nil {
+			h.ready.provider, h.ready.err = webhook.Name, err
+			break
+		}
+	}
+	if ctx.Err() == nil {
+		h.ready.checkedAt = time.Now()
+	}
+	return h.ready.provider, h.ready.err
 }
 
 // getAuthFromHeaders extracts auth token from request headers
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Store keeps files in a directory
+type Store struct {
+	dir string
+}
+
+// Path returns the path of the file in the store
+func (s *Store) Path(name string) string {
+	return filepath.Join(s.dir, name)
+}